@@ -0,0 +1,55 @@
+"use client";
+
+import { Suspense, useEffect } from "react";
+import { useRouter, useSearchParams } from "next/navigation";
+import { useAuthStore } from "@/lib/store/authStore";
+
+const linkErrors: Record<string, string> = {
+  identity_in_use: "이미 다른 계정에 연결된 로그인 정보입니다.",
+  provider_already_linked: "이 계정에는 이미 해당 로그인 방식이 연결되어 있습니다.",
+  link_failed: "계정 연결에 실패했습니다.",
+};
+
+function LinkHandler() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const fetchUser = useAuthStore((s) => s.fetchUser);
+  const error = searchParams.get("error");
+
+  useEffect(() => {
+    if (searchParams.get("status") === "linked") {
+      fetchUser().then(() => router.push("/play"));
+    }
+  }, [searchParams, fetchUser, router]);
+
+  if (error) {
+    return (
+      <div className="text-center">
+        <p className="text-[#FF6B6B] text-lg mb-4">{linkErrors[error] ?? linkErrors.link_failed}</p>
+        <button className="text-[#55EFC4] underline" onClick={() => router.push("/play")}>
+          돌아가기
+        </button>
+      </div>
+    );
+  }
+
+  return (
+    <p className="text-[#55EFC4] text-lg animate-pulse">계정 연결 처리 중...</p>
+  );
+}
+
+export default function AuthLinkPage() {
+  return (
+    <div className="min-h-screen bg-[#1a1a2e] flex items-center justify-center">
+      <Suspense
+        fallback={
+          <p className="text-[#55EFC4] text-lg animate-pulse">
+            계정 연결 처리 중...
+          </p>
+        }
+      >
+        <LinkHandler />
+      </Suspense>
+    </div>
+  );
+}
@@ -0,0 +1,133 @@
+"use client";
+
+import { Suspense, useState } from "react";
+import { useRouter, useSearchParams } from "next/navigation";
+import { api } from "@/lib/api/client";
+
+const resetErrors: Record<string, string> = {
+  token_expired: "재설정 링크가 만료되었습니다. 다시 요청해주세요.",
+  invalid_token: "유효하지 않거나 이미 사용된 링크입니다.",
+  too_many_attempts: "요청이 너무 많습니다. 잠시 후 다시 시도해주세요.",
+  failed: "요청에 실패했습니다.",
+};
+
+function errorCode(err: unknown) {
+  const e = err as { data?: { error?: string } };
+  return e?.data?.error || "failed";
+}
+
+// Without a token: ask for the account email and send a reset link.
+function RequestForm() {
+  const router = useRouter();
+  const [email, setEmail] = useState("");
+  const [sent, setSent] = useState(false);
+  const [error, setError] = useState("");
+  const [loading, setLoading] = useState(false);
+
+  const submit = async () => {
+    if (!email.trim()) return;
+    setLoading(true);
+    setError("");
+    try {
+      await api("/api/auth/forgot-password", { method: "POST", body: { email: email.trim() } });
+      setSent(true);
+    } catch (err) {
+      setError(errorCode(err));
+    }
+    setLoading(false);
+  };
+
+  if (sent) {
+    return (
+      <div className="text-center">
+        <p className="text-[#55EFC4] text-lg mb-4">
+          가입된 이메일이라면 비밀번호 재설정 링크를 보냈습니다.
+        </p>
+        <button className="text-[#55EFC4] underline" onClick={() => router.push("/login")}>
+          로그인으로 돌아가기
+        </button>
+      </div>
+    );
+  }
+
+  return (
+    <div className="w-full max-w-xs space-y-3">
+      <p className="text-white/60 text-sm font-bold text-center">비밀번호 재설정</p>
+      <input type="email" placeholder="이메일" value={email} onChange={(e) => setEmail(e.target.value)}
+        onKeyDown={(e) => e.key === "Enter" && submit()}
+        className="login-input" autoComplete="email" />
+      {error && <p className="text-[#FF6B6B] text-xs text-center">{resetErrors[error] ?? resetErrors.failed}</p>}
+      <button onClick={submit} disabled={loading || !email.trim()}
+        className="login-btn-email w-full py-3 rounded-2xl font-bold text-sm transition active:scale-[0.97] disabled:opacity-40">
+        재설정 링크 받기
+      </button>
+    </div>
+  );
+}
+
+// With a token from the mail: choose the new password.
+function ResetForm({ token }: { token: string }) {
+  const router = useRouter();
+  const [password, setPassword] = useState("");
+  const [confirm, setConfirm] = useState("");
+  const [done, setDone] = useState(false);
+  const [error, setError] = useState("");
+  const [loading, setLoading] = useState(false);
+
+  const submit = async () => {
+    if (password.length < 6) { setError("비밀번호는 6자 이상이어야 합니다."); return; }
+    if (password !== confirm) { setError("비밀번호가 일치하지 않습니다."); return; }
+    setLoading(true);
+    setError("");
+    try {
+      await api("/api/auth/reset-password", { method: "POST", body: { token, password } });
+      setDone(true);
+    } catch (err) {
+      const code = errorCode(err);
+      setError(resetErrors[code] ?? resetErrors.failed);
+    }
+    setLoading(false);
+  };
+
+  if (done) {
+    return (
+      <div className="text-center">
+        <p className="text-[#55EFC4] text-lg mb-4">비밀번호가 변경되었습니다. 다시 로그인해주세요.</p>
+        <button className="text-[#55EFC4] underline" onClick={() => router.push("/login")}>
+          로그인으로 이동
+        </button>
+      </div>
+    );
+  }
+
+  return (
+    <div className="w-full max-w-xs space-y-3">
+      <p className="text-white/60 text-sm font-bold text-center">새 비밀번호 설정</p>
+      <input type="password" placeholder="새 비밀번호 (6자 이상)" value={password} onChange={(e) => setPassword(e.target.value)}
+        className="login-input" autoComplete="new-password" />
+      <input type="password" placeholder="새 비밀번호 확인" value={confirm} onChange={(e) => setConfirm(e.target.value)}
+        onKeyDown={(e) => e.key === "Enter" && submit()}
+        className="login-input" autoComplete="new-password" />
+      {error && <p className="text-[#FF6B6B] text-xs text-center">{error}</p>}
+      <button onClick={submit} disabled={loading || !password || !confirm}
+        className="login-btn-email w-full py-3 rounded-2xl font-bold text-sm transition active:scale-[0.97] disabled:opacity-40">
+        비밀번호 변경
+      </button>
+    </div>
+  );
+}
+
+function ResetHandler() {
+  const token = useSearchParams().get("token");
+  return token ? <ResetForm token={token} /> : <RequestForm />;
+}
+
+export default function ResetPasswordPage() {
+  return (
+    <div className="min-h-screen bg-[#1a1a2e] flex items-center justify-center px-6">
+      <Suspense fallback={null}>
+        <ResetHandler />
+      </Suspense>
+    </div>
+  );
+}
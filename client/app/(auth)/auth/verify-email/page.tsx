@@ -0,0 +1,69 @@
+"use client";
+
+import { Suspense, useEffect, useRef, useState } from "react";
+import { useRouter, useSearchParams } from "next/navigation";
+import { api } from "@/lib/api/client";
+
+const verifyErrors: Record<string, string> = {
+  token_expired: "인증 링크가 만료되었습니다. 설정에서 인증 메일을 다시 받아주세요.",
+  invalid_token: "유효하지 않은 인증 링크입니다.",
+};
+
+function VerifyHandler() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const token = searchParams.get("token");
+  const [status, setStatus] = useState<"pending" | "done" | "error">("pending");
+  const [error, setError] = useState("");
+  const sent = useRef(false);
+
+  useEffect(() => {
+    if (!token) {
+      setStatus("error");
+      setError("invalid_token");
+      return;
+    }
+    // Strict mode runs effects twice; the token is only worth submitting once
+    if (sent.current) return;
+    sent.current = true;
+    api("/api/auth/verify-email", { method: "POST", body: { token } })
+      .then(() => setStatus("done"))
+      .catch((err: { data?: { error?: string } }) => {
+        setStatus("error");
+        setError(err?.data?.error || "invalid_token");
+      });
+  }, [token]);
+
+  if (status === "pending") {
+    return <p className="text-[#55EFC4] text-lg animate-pulse">이메일 인증 중...</p>;
+  }
+
+  return (
+    <div className="text-center">
+      {status === "done" ? (
+        <p className="text-[#55EFC4] text-lg mb-4">이메일 인증이 완료되었습니다!</p>
+      ) : (
+        <p className="text-[#FF6B6B] text-lg mb-4">{verifyErrors[error] ?? verifyErrors.invalid_token}</p>
+      )}
+      <button className="text-[#55EFC4] underline" onClick={() => router.push("/play")}>
+        돌아가기
+      </button>
+    </div>
+  );
+}
+
+export default function VerifyEmailPage() {
+  return (
+    <div className="min-h-screen bg-[#1a1a2e] flex items-center justify-center">
+      <Suspense
+        fallback={
+          <p className="text-[#55EFC4] text-lg animate-pulse">
+            이메일 인증 중...
+          </p>
+        }
+      >
+        <VerifyHandler />
+      </Suspense>
+    </div>
+  );
+}
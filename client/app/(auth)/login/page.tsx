@@ -99,6 +99,7 @@ export default function LoginPage() {
     const err = await emailLogin(email.trim(), password);
     if (err) {
       if (err === "invalid_credentials") setError(t("login_error_invalid_credentials"));
+      else if (err === "too_many_attempts") setError(t("login_error_too_many_attempts"));
       else setError(t("login_error_login_fail"));
     } else if (useAuthStore.getState().accessToken) {
       router.push("/play");
@@ -304,6 +305,9 @@ export default function LoginPage() {
                     <span className="flex items-center justify-center gap-2"><Spinner /> {t("login_logging_in")}</span>
                   ) : t("login_btn")}
                 </button>
+                <button onClick={() => router.push("/auth/reset-password")} className="text-white/30 text-xs hover:text-white/60 transition block ml-auto">
+                  {t("login_forgot_password")}
+                </button>
 
                 <div className="flex items-center justify-between pt-1">
                   <button onClick={() => switchMode("main")} className="text-white/30 text-xs hover:text-white/60 transition">
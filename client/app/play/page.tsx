@@ -14,6 +14,8 @@ import ShopPage from "@/components/ui/ShopPage";
 import CodexPage from "@/components/ui/CodexPage";
 import AchievementPage from "@/components/ui/AchievementPage";
 import LeaderboardPage from "@/components/ui/LeaderboardPage";
+import FriendsPage from "@/components/ui/FriendsPage";
+import GuildPage from "@/components/ui/GuildPage";
 import SlimeInfoPanel from "@/components/ui/SlimeInfoPanel";
 import MergeResultModal from "@/components/ui/MergeResultModal";
 import LevelUpModal from "@/components/ui/LevelUpModal";
@@ -36,6 +38,7 @@ import MoreMenuSheet from "@/components/ui/MoreMenuSheet";
 import SlimeDetailPage from "@/components/ui/SlimeDetailPage";
 import SplashScreen from "@/components/ui/SplashScreen";
 import { useAndroidBackButton } from "@/lib/useBackButton";
+import { authApi } from "@/lib/api/client";
 
 const GameCanvas = dynamic(() => import("@/components/game/GameCanvas"), {
   ssr: false,
@@ -116,6 +119,19 @@ export default function PlayPage() {
     fetchUser();
   }, [accessToken, fetchUser, router]);
 
+  // Presence heartbeat — friends see us as online while the game is open
+  useEffect(() => {
+    if (!accessToken) return;
+    const beat = () => {
+      if (document.visibilityState === "visible") {
+        authApi("/api/presence/heartbeat", accessToken, { method: "POST" }).catch(() => {});
+      }
+    };
+    beat();
+    const interval = setInterval(beat, 60000);
+    return () => clearInterval(interval);
+  }, [accessToken]);
+
   useEffect(() => {
     if (accessToken && user) {
       Promise.all([
@@ -168,6 +184,8 @@ export default function PlayPage() {
         {activePanel === "codex" && <CodexPage />}
         {activePanel === "achievements" && <AchievementPage />}
         {activePanel === "leaderboard" && <LeaderboardPage />}
+        {activePanel === "friends" && <FriendsPage />}
+        {activePanel === "guild" && <GuildPage />}
         {activePanel === "collection" && (
           <div className="absolute inset-0 z-50 bg-[#0a0a1a]" style={{ bottom: 76 }}>
             <CollectionPage onClose={() => setActivePanel("home")} />
@@ -7,6 +7,7 @@ import { authApi } from "@/lib/api/client";
 import { generateSlimeIconSvg } from "@/lib/slimeSvg";
 import { elementColors } from "@/lib/constants";
 import { toastReward } from "@/components/ui/Toast";
+import { RaceSim, RACE_TICK_RATE, type RaceAction, type RaceInput, type RaceObstacleKind, type RaceStats } from "@/lib/raceSim";
 
 interface Props {
   onClose: () => void;
@@ -14,12 +15,12 @@ interface Props {
 
 type RaceState = "select" | "countdown" | "playing" | "result";
 
-// Obstacle types with clear visual identities
+// On-screen view of a track obstacle with clear visual identities
 // Ground obstacles → JUMP | Aerial obstacles → DUCK
 interface Obstacle {
   x: number;
   y: number;
-  type: "rock" | "cactus" | "fire" | "spike" | "saw" | "laser";
+  type: RaceObstacleKind;
   width: number;
   height: number;
   hit: boolean;
@@ -28,20 +29,6 @@ interface Obstacle {
   aerial: boolean;
 }
 
-interface PowerUp {
-  x: number;
-  y: number;
-  type: "boost" | "shield" | "heart" | "double" | "magnet";
-  collected: boolean;
-}
-
-interface Coin {
-  x: number;
-  y: number;
-  collected: boolean;
-  value: number;
-}
-
 interface ScorePopup {
   x: number;
   y: number;
@@ -85,11 +72,9 @@ const TRACK_W = 440;
 const TRACK_H = 340;
 const SLIME_X = 80;
 const GROUND_Y = TRACK_H - 40;
-const MAX_HP = 3;
-const GRAVITY = 0.65;
-const JUMP_FORCE = -11.5;
-const DOUBLE_JUMP_FORCE = -10;
-const INVINCIBLE_MS = 1500;
+const TICK_MS = 1000 / RACE_TICK_RATE;
+const DUCK_TICKS = 27; // a duck releases itself after ~450ms
+const MAX_CATCHUP_TICKS = 4; // ticks run per frame at most when the loop falls behind
 
 export default function SlimeRace({ onClose }: Props) {
   const token = useAuthStore((s) => s.accessToken);
@@ -98,51 +83,40 @@ export default function SlimeRace({ onClose }: Props) {
   const [raceState, setRaceState] = useState<RaceState>("select");
   const [selectedSlimeId, setSelectedSlimeId] = useState<string | null>(null);
   const [score, setScore] = useState(0);
-  const [hp, setHp] = useState(MAX_HP);
+  const [hp, setHp] = useState(3);
+  const [maxHp, setMaxHp] = useState(3);
   const [combo, setCombo] = useState(0);
   const [maxCombo, setMaxCombo] = useState(0);
-  const [jumping, setJumping] = useState(false);
   const [result, setResult] = useState<{ score: number; gold: number; exp: number; maxCombo: number; distance: number; isNewBest: boolean; stars: number } | null>(null);
   const [remaining] = useState(999); // unlimited
   const [screenShake, setScreenShake] = useState(0);
-  const [activePowerUp, setActivePowerUp] = useState<{ type: string; timer: number } | null>(null);
-  const [hasShield, setHasShield] = useState(false);
   const [countdown, setCountdown] = useState(3);
   const [distance, setDistance] = useState(0);
-  const [fever, setFever] = useState(false);
   const [weather, setWeather] = useState<WeatherType>("clear");
 
-  // Game state refs
+  // The race itself runs in the same simulation the server replays; the refs below
+  // mirror it for rendering
+  const simRef = useRef<RaceSim | null>(null);
+  const obstacleViewsRef = useRef(new Map<number, Obstacle>());
   const obstaclesRef = useRef<Obstacle[]>([]);
-  const powerUpsRef = useRef<PowerUp[]>([]);
-  const coinsRef = useRef<Coin[]>([]);
   const popupsRef = useRef<ScorePopup[]>([]);
   const particlesRef = useRef<Particle[]>([]);
   const scoreRef = useRef(0);
   const comboRef = useRef(0);
   const maxComboRef = useRef(0);
-  const hpRef = useRef(MAX_HP);
-  const jumpVyRef = useRef(0); // velocity-based jump
+  const jumpVyRef = useRef(0);
   const jumpYRef = useRef(0);
   const jumpCountRef = useRef(0);
   const duckingRef = useRef(false);
-  const duckTimerRef = useRef(0);
+  const duckReleaseTickRef = useRef(-1);
   const speedRef = useRef(3.5);
   const elapsedRef = useRef(0);
-  const shieldRef = useRef(false);
-  const boostRef = useRef(false);
-  const magnetRef = useRef(false);
-  const doubleRef = useRef(false);
+  const startedAtRef = useRef(0);
   const bgOffsetRef = useRef(0);
   const distanceRef = useRef(0);
   const raceStateRef = useRef<RaceState>("select");
-  const nearMissRef = useRef(0);
   const invincibleRef = useRef(0);
   const hitFlashRef = useRef(0);
-  const milestoneRef = useRef(0); // next distance milestone
-  const feverRef = useRef(false);
-  const feverTimerRef = useRef(0);
-  const feverFlashRef = useRef(0);
   const weatherRef = useRef<WeatherType>("clear");
   const weatherTimerRef = useRef(0);
   const weatherDropsRef = useRef<WeatherDrop[]>([]);
@@ -158,6 +132,11 @@ export default function SlimeRace({ onClose }: Props) {
 
   const tokenRef = useRef(token);
   tokenRef.current = token;
+  // Server-issued race token + replayable input log (server recomputes the score).
+  // Actions wait here for the next simulation tick, which is the tick they're recorded at.
+  const raceTokenRef = useRef<string | null>(null);
+  const inputsRef = useRef<RaceInput[]>([]);
+  const pendingActionsRef = useRef<RaceAction[]>([]);
   const selectedSlimeIdRef = useRef(selectedSlimeId);
   selectedSlimeIdRef.current = selectedSlimeId;
 
@@ -171,10 +150,12 @@ export default function SlimeRace({ onClose }: Props) {
 
     const finalScore = scoreRef.current;
     const finalMaxCombo = maxComboRef.current;
-    const finalDistance = Math.floor(distanceRef.current);
+    const finalDistance = Math.floor(distanceRef.current * 0.05);
     const tk = tokenRef.current;
     const slimeId = selectedSlimeIdRef.current;
-    if (!tk || !slimeId) return;
+    const raceToken = raceTokenRef.current;
+    if (!tk || !slimeId || !raceToken) return;
+    raceTokenRef.current = null;
 
     // Personal best tracking
     const prevBest = parseInt(localStorage.getItem("slimerace_best_score") || "0", 10);
@@ -188,7 +169,7 @@ export default function SlimeRace({ onClose }: Props) {
       const res = await authApi<{ score: number; gold_reward: number; exp_reward: number }>(
         "/api/race/finish",
         tk,
-        { method: "POST", body: { slime_id: slimeId, score: finalScore } },
+        { method: "POST", body: { token: raceToken, inputs: inputsRef.current } },
       );
       setResult({ score: res.score, gold: res.gold_reward, exp: res.exp_reward, maxCombo: finalMaxCombo, distance: finalDistance, isNewBest, stars });
       if (res.gold_reward > 0) {
@@ -204,59 +185,49 @@ export default function SlimeRace({ onClose }: Props) {
   const startRace = async () => {
     if (!token || !selectedSlimeId) return;
     try {
-      await authApi<{ token: string }>("/api/race/start", token, {
+      const started = await authApi<{ token: string; seed: number; stats: RaceStats }>("/api/race/start", token, {
         method: "POST",
         body: { slime_id: selectedSlimeId },
       });
+      raceTokenRef.current = started.token;
+      simRef.current = new RaceSim(started.seed, started.stats);
+      obstacleViewsRef.current = new Map();
+      inputsRef.current = [];
+      pendingActionsRef.current = [];
       finishCalledRef.current = false;
 
       setRaceState("countdown");
       setCountdown(3);
       setScore(0);
-      setHp(MAX_HP);
+      setHp(started.stats.hp);
+      setMaxHp(started.stats.hp);
       setCombo(0);
       setMaxCombo(0);
-      setJumping(false);
       setScreenShake(0);
-      setActivePowerUp(null);
-      setHasShield(false);
       setDistance(0);
       setResult(null);
       scoreRef.current = 0;
       comboRef.current = 0;
       maxComboRef.current = 0;
-      hpRef.current = MAX_HP;
       jumpVyRef.current = 0;
       jumpYRef.current = 0;
       jumpCountRef.current = 0;
       duckingRef.current = false;
-      duckTimerRef.current = 0;
-      speedRef.current = 3.5;
+      duckReleaseTickRef.current = -1;
+      speedRef.current = started.stats.speed;
       elapsedRef.current = 0;
-      shieldRef.current = false;
-      boostRef.current = false;
-      magnetRef.current = false;
-      doubleRef.current = false;
       bgOffsetRef.current = 0;
       distanceRef.current = 0;
-      nearMissRef.current = 0;
       invincibleRef.current = 0;
       hitFlashRef.current = 0;
-      milestoneRef.current = 100;
-      feverRef.current = false;
-      feverTimerRef.current = 0;
-      feverFlashRef.current = 0;
       weatherRef.current = "clear";
       weatherTimerRef.current = 0;
       weatherDropsRef.current = [];
       shootingStarsRef.current = [];
       dustTrailRef.current = [];
       auroraPhaseRef.current = 0;
-      setFever(false);
       setWeather("clear");
       obstaclesRef.current = [];
-      powerUpsRef.current = [];
-      coinsRef.current = [];
       popupsRef.current = [];
       particlesRef.current = [];
 
@@ -265,6 +236,7 @@ export default function SlimeRace({ onClose }: Props) {
         c--;
         if (c <= 0) {
           clearInterval(cdInterval);
+          startedAtRef.current = performance.now();
           setRaceState("playing");
         } else {
           setCountdown(c);
@@ -275,65 +247,107 @@ export default function SlimeRace({ onClose }: Props) {
     }
   };
 
-  // Game loop
+  // Game loop: the race advances in fixed 60Hz ticks of wall-clock time, exactly as the
+  // server replays it; weather and effects are drawn on top once per frame
   useEffect(() => {
     if (raceState !== "playing") return;
 
-    let lastObSpawn = 0;
-    let lastPowerUpSpawn = 0;
-    let lastCoinSpawn = 0;
-    let patternCooldown = 0;
     const DT = 16; // ~60fps
 
     gameRef.current = setInterval(() => {
       if (raceStateRef.current !== "playing") return;
-      elapsedRef.current += DT;
+      const sim = simRef.current;
+      if (!sim) return;
+
+      // Catch up to wall-clock time, a few ticks at most per frame. Running behind is
+      // fine; the server only rejects input logs that are ahead of the clock.
+      const dueTick = Math.floor((performance.now() - startedAtRef.current) / TICK_MS);
+      const prevDistance = sim.distance;
+      for (let n = 0; n < MAX_CATCHUP_TICKS && sim.tick < dueTick && !sim.over; n++) {
+        const actions = pendingActionsRef.current;
+        pendingActionsRef.current = [];
+        if (sim.ducking && duckReleaseTickRef.current >= 0 && sim.tick >= duckReleaseTickRef.current) {
+          actions.push("release");
+          duckReleaseTickRef.current = -1;
+        }
+        const tick = sim.tick;
+        for (const action of actions) inputsRef.current.push({ tick, action });
+
+        const events = sim.step(actions);
+        if (actions.includes("duck") && sim.ducking) duckReleaseTickRef.current = tick + DUCK_TICKS;
+
+        for (const ev of events) {
+          const slimeTop = GROUND_Y - 28 - sim.y;
+          if (ev.type === "clear") {
+            popupsRef.current.push({
+              x: SLIME_X + 30, y: slimeTop - 5,
+              text: ev.crit ? `CRIT! +${ev.gain}` : `+${ev.gain}`,
+              life: 700, color: ev.crit ? "#FF9FF3" : "#FFEAA7", size: ev.crit ? 14 : 12,
+            });
+            if (ev.crit) spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - sim.y - 14, "#FF9FF3", 5);
+            // Every 10th clear in a row is worth another 10 points per clear
+            if (sim.combo % 10 === 0) {
+              popupsRef.current.push({ x: TRACK_W / 2, y: TRACK_H / 2 - 30, text: `x${sim.combo} COMBO!`, life: 1200, color: "#FFEAA7", size: 16 });
+            }
+          } else if (ev.damaged) {
+            hitFlashRef.current = 400;
+            setScreenShake(350);
+            setTimeout(() => setScreenShake(0), 350);
+            popupsRef.current.push({ x: SLIME_X, y: slimeTop - 10, text: `-${ev.penalty} HP!`, life: 900, color: "#FF6B6B", size: 14 });
+            spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - sim.y - 14, "#FF6B6B", 15);
+          }
+        }
+      }
+
+      // Mirror the simulation for rendering
+      elapsedRef.current = sim.tick * TICK_MS;
+      speedRef.current = sim.speed;
+      distanceRef.current = sim.distance;
+      jumpYRef.current = sim.y;
+      jumpVyRef.current = sim.vy;
+      jumpCountRef.current = sim.jumpCount;
+      duckingRef.current = sim.ducking;
+      invincibleRef.current = Math.max(0, (sim.invincibleUntil - sim.tick) * TICK_MS);
+      scoreRef.current = sim.score;
+      comboRef.current = sim.combo;
+      maxComboRef.current = sim.maxCombo;
+      setScore(sim.score);
+      setHp(sim.hp);
+      setCombo(sim.combo);
+      setMaxCombo(sim.maxCombo);
+      setDistance(Math.floor(sim.distance * 0.05));
 
-      // Difficulty: speed ramps gradually, never stops
       const elapsed_s = elapsedRef.current / 1000;
-      speedRef.current = 3.5 + elapsed_s * 0.06; // slowly accelerates forever
-      const spd = boostRef.current ? speedRef.current * 1.8 : speedRef.current;
+      const spd = sim.distance - prevDistance;
 
       // Background scroll
       bgOffsetRef.current = (bgOffsetRef.current + spd * 0.6) % 80;
-      distanceRef.current += spd * 0.05;
-      setDistance(Math.floor(distanceRef.current));
-
-      // Distance milestones
-      if (distanceRef.current >= milestoneRef.current) {
-        const bonus = milestoneRef.current * 2;
-        scoreRef.current += bonus;
-        setScore(scoreRef.current);
-        popupsRef.current.push({
-          x: TRACK_W / 2, y: TRACK_H / 2 - 30,
-          text: `${milestoneRef.current}m! +${bonus}`,
-          life: 1200, color: "#FFEAA7", size: 16,
-        });
-        milestoneRef.current += 100 + Math.floor(milestoneRef.current * 0.5);
-      }
 
-      // ===== FEVER MODE =====
-      if (feverRef.current) {
-        feverTimerRef.current -= DT;
-        feverFlashRef.current += DT;
-        if (feverTimerRef.current <= 0) {
-          feverRef.current = false;
-          setFever(false);
-          invincibleRef.current = 0;
+      // Obstacles on screen, placed by how far ahead of the slime they are on the track
+      const views = obstacleViewsRef.current;
+      const visible: Obstacle[] = [];
+      for (let i = Math.max(0, sim.next - 3); i < sim.track.length; i++) {
+        const ob = sim.track[i];
+        const x = SLIME_X + (ob.x - sim.distance);
+        if (x > TRACK_W + 50) break;
+        if (x + ob.width < -50) {
+          views.delete(i);
+          continue;
         }
+        let view = views.get(i);
+        if (!view) {
+          view = makeObstacle(ob.kind, ob.width);
+          views.set(i, view);
+        }
+        view.x = x;
+        view.hit = i < sim.next || sim.isHit(i);
+        if (view.type === "fire" && view.osc !== undefined) {
+          // fire pillar slight wobble
+          view.y = GROUND_Y - view.height + Math.sin(Date.now() * 0.005 + view.osc) * 3;
+        }
+        visible.push(view);
       }
-      // Trigger fever at combo 15 (only once per fever cycle)
-      if (!feverRef.current && comboRef.current >= 15 && comboRef.current % 15 === 0) {
-        feverRef.current = true;
-        feverTimerRef.current = 5000;
-        feverFlashRef.current = 0;
-        invincibleRef.current = 5500;
-        setFever(true);
-        popupsRef.current.push({
-          x: TRACK_W / 2, y: TRACK_H / 2 - 50,
-          text: "FEVER MODE!", life: 1500, color: "#FF6B6B", size: 22,
-        });
-      }
+      obstaclesRef.current = visible;
 
       // ===== WEATHER SYSTEM =====
       weatherTimerRef.current -= DT;
@@ -427,131 +441,7 @@ export default function SlimeRace({ onClose }: Props) {
       // Aurora phase
       auroraPhaseRef.current += DT * 0.001;
 
-      // Score — base + combo
-      const feverMul = feverRef.current ? 15 : 1;
-      const comboMul = comboRef.current >= 20 ? 10 : comboRef.current >= 15 ? 8 : comboRef.current >= 10 ? 5 : comboRef.current >= 5 ? 3 : comboRef.current >= 3 ? 2 : 1;
-      const doubleBonus = doubleRef.current ? 2 : 1;
-      scoreRef.current += comboMul * doubleBonus * feverMul;
-      setScore(scoreRef.current);
-
-      // Duck timer
-      if (duckingRef.current) {
-        duckTimerRef.current -= DT;
-        if (duckTimerRef.current <= 0) {
-          duckingRef.current = false;
-        }
-      }
-
-      // Invincibility timer
-      if (invincibleRef.current > 0) invincibleRef.current -= DT;
       if (hitFlashRef.current > 0) hitFlashRef.current -= DT;
-      if (nearMissRef.current > 0) nearMissRef.current -= DT;
-
-      // ===== PHYSICS-BASED JUMP =====
-      if (jumpYRef.current > 0 || jumpVyRef.current < 0) {
-        jumpVyRef.current += GRAVITY;
-        jumpYRef.current -= jumpVyRef.current;
-        if (jumpYRef.current <= 0) {
-          jumpYRef.current = 0;
-          jumpVyRef.current = 0;
-          jumpCountRef.current = 0;
-          setJumping(false);
-        }
-      }
-
-      // Spawn obstacles
-      lastObSpawn += DT;
-      patternCooldown -= DT;
-      const spawnInterval = Math.max(350, 1200 - elapsed_s * 8);
-      if (lastObSpawn > spawnInterval) {
-        lastObSpawn = 0;
-
-        // Patterns get more frequent as time progresses
-        const patternChance = Math.min(0.4, 0.1 + elapsed_s * 0.005);
-        if (patternCooldown <= 0 && elapsed_s > 6 && Math.random() < patternChance) {
-          patternCooldown = Math.max(1500, 3000 - elapsed_s * 20);
-          const pattern = Math.floor(Math.random() * 4);
-          if (pattern === 0) {
-            // Ground + aerial
-            obstaclesRef.current.push(makeObstacle("rock", TRACK_W + 20));
-            obstaclesRef.current.push(makeObstacle("saw", TRACK_W + 100));
-          } else if (pattern === 1) {
-            // Triple ground rush
-            obstaclesRef.current.push(makeObstacle("spike", TRACK_W + 20));
-            obstaclesRef.current.push(makeObstacle("cactus", TRACK_W + 80));
-            obstaclesRef.current.push(makeObstacle("rock", TRACK_W + 150));
-          } else if (pattern === 2) {
-            // Aerial then ground
-            obstaclesRef.current.push(makeObstacle("saw", TRACK_W + 20));
-            obstaclesRef.current.push(makeObstacle("fire", TRACK_W + 100));
-          } else {
-            // Double aerial
-            obstaclesRef.current.push(makeObstacle("laser", TRACK_W + 20));
-            obstaclesRef.current.push(makeObstacle("spike", TRACK_W + 100));
-          }
-        } else {
-          // Single obstacle
-          const groundTypes: Obstacle["type"][] = ["rock", "cactus", "fire", "spike"];
-          const aerialTypes: Obstacle["type"][] = elapsed_s > 5 ? ["saw", "laser"] : [];
-          const allTypes = [...groundTypes, ...aerialTypes];
-          // After 15s, add more aerial
-          if (elapsed_s > 15) allTypes.push("saw", "laser");
-          const t = allTypes[Math.floor(Math.random() * allTypes.length)];
-          obstaclesRef.current.push(makeObstacle(t, TRACK_W + 20));
-        }
-      }
-
-      // Spawn power-ups
-      lastPowerUpSpawn += DT;
-      if (lastPowerUpSpawn > 5000 + Math.random() * 4000) {
-        lastPowerUpSpawn = 0;
-        const types: PowerUp["type"][] = hpRef.current < MAX_HP ? ["boost", "shield", "heart", "double", "magnet"] : ["boost", "shield", "double", "magnet"];
-        const t = types[Math.floor(Math.random() * types.length)];
-        powerUpsRef.current.push({ x: TRACK_W + 20, y: GROUND_Y - 50 - Math.random() * 20, type: t, collected: false });
-      }
-
-      // Spawn coins
-      lastCoinSpawn += DT;
-      if (lastCoinSpawn > 350) {
-        lastCoinSpawn = 0;
-        if (Math.random() < 0.4) {
-          const isBig = Math.random() < 0.1;
-          coinsRef.current.push({
-            x: TRACK_W + 20,
-            y: GROUND_Y - 15 - Math.random() * 55,
-            collected: false,
-            value: isBig ? 30 : 10,
-          });
-        }
-      }
-
-      // Move obstacles
-      obstaclesRef.current = obstaclesRef.current.filter((ob) => {
-        ob.x -= spd;
-        if (ob.type === "fire" && ob.osc !== undefined) {
-          // fire pillar slight wobble
-          ob.y = GROUND_Y - ob.height + Math.sin(Date.now() * 0.005 + ob.osc) * 3;
-        }
-        return ob.x > -50;
-      });
-
-      // Move power-ups
-      powerUpsRef.current = powerUpsRef.current.filter((pu) => {
-        pu.x -= spd;
-        return pu.x > -20 && !pu.collected;
-      });
-
-      // Move coins
-      coinsRef.current = coinsRef.current.filter((c) => {
-        c.x -= spd;
-        if (magnetRef.current && !c.collected) {
-          const dx = SLIME_X - c.x;
-          const dy = (GROUND_Y - jumpYRef.current - 14) - c.y;
-          c.x += dx * 0.1;
-          c.y += dy * 0.1;
-        }
-        return c.x > -10 && !c.collected;
-      });
 
       // Update popups
       popupsRef.current = popupsRef.current.filter((p) => {
@@ -569,128 +459,9 @@ export default function SlimeRace({ onClose }: Props) {
         return p.life > 0;
       });
 
-      // Collision detection
-      const isDucking = duckingRef.current && jumpYRef.current === 0;
-      const slimeTop = isDucking ? GROUND_Y - 10 : GROUND_Y - 28 - jumpYRef.current;
-      const slimeBox = isDucking
-        ? { x: SLIME_X - 12, y: GROUND_Y - 10, w: 24, h: 10 }
-        : { x: SLIME_X - 12, y: GROUND_Y - 28 - jumpYRef.current, w: 24, h: 28 };
-
-      for (const ob of obstaclesRef.current) {
-        if (ob.hit) continue;
-        const obBox = { x: ob.x, y: ob.y, w: ob.width, h: ob.height };
-        if (checkAABB(slimeBox, obBox)) {
-          // HIT!
-          if (invincibleRef.current > 0) continue; // still invincible
-          if (shieldRef.current) {
-            shieldRef.current = false;
-            setHasShield(false);
-            ob.hit = true;
-            popupsRef.current.push({ x: SLIME_X, y: slimeTop - 10, text: "\uD83D\uDEE1\uFE0F", life: 800, color: "#74B9FF" });
-            spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - jumpYRef.current - 14, "#74B9FF", 8);
-          } else {
-            ob.hit = true;
-            hpRef.current -= 1;
-            setHp(hpRef.current);
-            comboRef.current = 0;
-            setCombo(0);
-            invincibleRef.current = INVINCIBLE_MS;
-            hitFlashRef.current = 400;
-            setScreenShake(350);
-            setTimeout(() => setScreenShake(0), 350);
-
-            const penalty = Math.min(scoreRef.current, 80);
-            scoreRef.current = Math.max(0, scoreRef.current - penalty);
-            setScore(scoreRef.current);
-            popupsRef.current.push({ x: SLIME_X, y: slimeTop - 10, text: `-${penalty} HP!`, life: 900, color: "#FF6B6B", size: 14 });
-            spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - jumpYRef.current - 14, "#FF6B6B", 15);
-
-            if (hpRef.current <= 0) {
-              finishRace();
-              return;
-            }
-          }
-        } else if (!ob.hit && ob.x + ob.width < SLIME_X - 10) {
-          // Dodged!
-          ob.hit = true;
-          comboRef.current += 1;
-          if (comboRef.current > maxComboRef.current) {
-            maxComboRef.current = comboRef.current;
-            setMaxCombo(maxComboRef.current);
-          }
-          setCombo(comboRef.current);
-
-          // Near-miss detection
-          const closeY = Math.abs((GROUND_Y - jumpYRef.current - 14) - (ob.y + ob.height / 2));
-          const closeX = Math.abs(SLIME_X - (ob.x + ob.width));
-          if ((closeY < 22 || closeX < 18) && nearMissRef.current <= 0) {
-            nearMissRef.current = 500;
-            const nearBonus = 30 * comboMul;
-            scoreRef.current += nearBonus;
-            setScore(scoreRef.current);
-            popupsRef.current.push({ x: SLIME_X + 30, y: slimeTop - 5, text: `CLOSE! +${nearBonus}`, life: 900, color: "#FF9FF3" });
-            spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - jumpYRef.current - 14, "#FF9FF3", 5);
-          }
-
-          // Combo milestone bonus
-          if (comboRef.current >= 5 && comboRef.current % 5 === 0) {
-            const bonus = comboRef.current * 10;
-            scoreRef.current += bonus;
-            setScore(scoreRef.current);
-            popupsRef.current.push({ x: SLIME_X + 35, y: slimeTop, text: `x${comboRef.current} +${bonus}!`, life: 1000, color: "#FFEAA7", size: 14 });
-          }
-        }
-      }
-
-      // Power-up collision
-      for (const pu of powerUpsRef.current) {
-        if (pu.collected) continue;
-        if (Math.abs(pu.x - SLIME_X) < 20 && Math.abs(pu.y - (GROUND_Y - jumpYRef.current - 14)) < 24) {
-          pu.collected = true;
-          spawnParticles(particlesRef.current, pu.x, pu.y, "#FFEAA7", 6);
-
-          if (pu.type === "boost") {
-            boostRef.current = true;
-            setActivePowerUp({ type: "boost", timer: 3000 });
-            popupsRef.current.push({ x: pu.x, y: pu.y - 10, text: "\uD83D\uDE80 BOOST!", life: 800, color: "#FFEAA7" });
-            setTimeout(() => { boostRef.current = false; setActivePowerUp(null); }, 3000);
-          } else if (pu.type === "shield") {
-            shieldRef.current = true;
-            setHasShield(true);
-            popupsRef.current.push({ x: pu.x, y: pu.y - 10, text: "\uD83D\uDEE1\uFE0F SHIELD!", life: 800, color: "#74B9FF" });
-          } else if (pu.type === "heart") {
-            if (hpRef.current < MAX_HP) {
-              hpRef.current += 1;
-              setHp(hpRef.current);
-              popupsRef.current.push({ x: pu.x, y: pu.y - 10, text: "\u2764\uFE0F +1 HP!", life: 800, color: "#FF6B6B" });
-            }
-          } else if (pu.type === "double") {
-            doubleRef.current = true;
-            setActivePowerUp({ type: "double", timer: 4000 });
-            popupsRef.current.push({ x: pu.x, y: pu.y - 10, text: "\u2728 DOUBLE!", life: 800, color: "#55EFC4" });
-            setTimeout(() => { doubleRef.current = false; setActivePowerUp(null); }, 4000);
-          } else if (pu.type === "magnet") {
-            magnetRef.current = true;
-            setActivePowerUp({ type: "magnet", timer: 5000 });
-            popupsRef.current.push({ x: pu.x, y: pu.y - 10, text: "🧲 MAGNET!", life: 800, color: "#A29BFE" });
-            setTimeout(() => { magnetRef.current = false; setActivePowerUp(null); }, 5000);
-          }
-        }
-      }
-
-      // Coin collision
-      for (const c of coinsRef.current) {
-        if (c.collected) continue;
-        if (Math.abs(c.x - SLIME_X) < 16 && Math.abs(c.y - (GROUND_Y - jumpYRef.current - 14)) < 20) {
-          c.collected = true;
-          const gain = c.value * comboMul * doubleBonus;
-          scoreRef.current += gain;
-          setScore(scoreRef.current);
-          popupsRef.current.push({ x: c.x, y: c.y - 10, text: `+${gain}`, life: 500, color: "#FFEAA7" });
-        }
-      }
-
       renderCanvas();
+
+      if (sim.over) finishRace();
     }, DT);
 
     return () => {
@@ -912,9 +683,9 @@ export default function SlimeRace({ onClose }: Props) {
     }
 
     // Speed lines
-    if (speedRef.current > 5 || boostRef.current) {
-      const lineCount = boostRef.current ? 14 : Math.floor((speedRef.current - 5) * 4);
-      const alpha = boostRef.current ? 0.15 : Math.min(0.1, (speedRef.current - 5) * 0.02);
+    if (speedRef.current > 5) {
+      const lineCount = Math.floor((speedRef.current - 5) * 4);
+      const alpha = Math.min(0.1, (speedRef.current - 5) * 0.02);
       ctx.strokeStyle = `rgba(255,234,167,${alpha})`;
       ctx.lineWidth = 1;
       for (let i = 0; i < lineCount; i++) {
@@ -936,56 +707,6 @@ export default function SlimeRace({ onClose }: Props) {
       ctx.globalAlpha = 1;
     }
 
-    // Coins
-    for (const c of coinsRef.current) {
-      if (c.collected) continue;
-      const shimmer = Math.sin(Date.now() * 0.008 + c.x * 0.1) * 0.2;
-      const r = c.value >= 30 ? 6 : 4;
-      // Glow
-      ctx.fillStyle = `rgba(255,200,100,${0.08 + shimmer * 0.05})`;
-      ctx.beginPath();
-      ctx.arc(c.x, c.y, r + 4, 0, Math.PI * 2);
-      ctx.fill();
-      // Outer
-      ctx.fillStyle = c.value >= 30 ? `rgba(255,200,80,${0.9 + shimmer})` : `rgba(255,234,167,${0.85 + shimmer})`;
-      ctx.beginPath();
-      ctx.arc(c.x, c.y, r, 0, Math.PI * 2);
-      ctx.fill();
-      // Inner
-      ctx.fillStyle = c.value >= 30 ? "#B8860B" : "#D4A700";
-      ctx.beginPath();
-      ctx.arc(c.x, c.y, r - 1.5, 0, Math.PI * 2);
-      ctx.fill();
-    }
-
-    // Power-ups
-    for (const pu of powerUpsRef.current) {
-      if (pu.collected) continue;
-      const puColors: Record<string, string> = { boost: "#FFEAA7", shield: "#74B9FF", heart: "#FF6B6B", double: "#55EFC4", magnet: "#A29BFE" };
-      const puIcons: Record<string, string> = { boost: "\u26A1", shield: "\uD83D\uDEE1", heart: "\u2764", double: "\u00D72", magnet: "M" };
-      const puFloat = Math.sin(Date.now() * 0.004 + pu.x * 0.05) * 3;
-      const py = pu.y + puFloat;
-      // Glow
-      ctx.fillStyle = `${puColors[pu.type]}18`;
-      ctx.beginPath();
-      ctx.arc(pu.x, py, 16, 0, Math.PI * 2);
-      ctx.fill();
-      // Circle
-      ctx.fillStyle = `${puColors[pu.type]}40`;
-      ctx.beginPath();
-      ctx.arc(pu.x, py, 11, 0, Math.PI * 2);
-      ctx.fill();
-      ctx.fillStyle = puColors[pu.type];
-      ctx.beginPath();
-      ctx.arc(pu.x, py, 8, 0, Math.PI * 2);
-      ctx.fill();
-      // Icon
-      ctx.fillStyle = "#0a0a1a";
-      ctx.font = "bold 9px Arial";
-      ctx.textAlign = "center";
-      ctx.fillText(puIcons[pu.type], pu.x, py + 3);
-    }
-
     // Particles
     for (const p of particlesRef.current) {
       ctx.globalAlpha = Math.max(0, p.life / 400);
@@ -1026,25 +747,14 @@ export default function SlimeRace({ onClose }: Props) {
       ctx.scale(stretchX, stretchY);
     }
 
-    // Body glow (rainbow during fever)
-    if (feverRef.current) {
-      const fhue = (feverFlashRef.current * 0.4) % 360;
-      ctx.fillStyle = `hsla(${fhue},100%,70%,0.15)`;
-      ctx.beginPath();
-      ctx.arc(0, 0, 25, 0, Math.PI * 2);
-      ctx.fill();
-      ctx.fillStyle = `hsla(${fhue},100%,70%,0.08)`;
-      ctx.beginPath();
-      ctx.arc(0, 0, 30, 0, Math.PI * 2);
-      ctx.fill();
-    }
+    // Body glow
     ctx.fillStyle = `${color}20`;
     ctx.beginPath();
     ctx.arc(0, 0, 20, 0, Math.PI * 2);
     ctx.fill();
 
     // Body
-    ctx.fillStyle = feverRef.current ? `hsl(${(feverFlashRef.current * 0.4) % 360}, 80%, 65%)` : color;
+    ctx.fillStyle = color;
     ctx.beginPath();
     ctx.arc(0, 0, 15, 0, Math.PI * 2);
     ctx.fill();
@@ -1135,30 +845,6 @@ export default function SlimeRace({ onClose }: Props) {
       ctx.globalAlpha = 1;
     }
 
-    // Shield bubble
-    if (shieldRef.current) {
-      const shieldPulse = Math.sin(Date.now() * 0.005) * 0.15;
-      ctx.strokeStyle = `rgba(116,185,255,${0.4 + shieldPulse})`;
-      ctx.lineWidth = 2;
-      ctx.beginPath();
-      ctx.arc(SLIME_X, slimeY - 15, 23, 0, Math.PI * 2);
-      ctx.stroke();
-      ctx.fillStyle = `rgba(116,185,255,0.04)`;
-      ctx.fill();
-    }
-
-    // Boost trail
-    if (boostRef.current) {
-      for (let i = 1; i <= 5; i++) {
-        ctx.globalAlpha = 0.1 / i;
-        ctx.fillStyle = color;
-        ctx.beginPath();
-        ctx.arc(SLIME_X - 10 * i, slimeY - 15, 15 - i * 1.5, 0, Math.PI * 2);
-        ctx.fill();
-      }
-      ctx.globalAlpha = 1;
-    }
-
     // Score popups
     for (const p of popupsRef.current) {
       ctx.globalAlpha = Math.max(0, p.life / 800);
@@ -1181,7 +867,7 @@ export default function SlimeRace({ onClose }: Props) {
     }
 
     // Danger vignette when speed is high
-    if (speedRef.current > 6 && !feverRef.current) {
+    if (speedRef.current > 6) {
       const vigAlpha = Math.min((speedRef.current - 6) * 0.015, 0.1);
       const gradient = ctx.createRadialGradient(TRACK_W / 2, TRACK_H / 2, TRACK_H * 0.35, TRACK_W / 2, TRACK_H / 2, TRACK_H * 0.75);
       gradient.addColorStop(0, "rgba(0,0,0,0)");
@@ -1190,58 +876,6 @@ export default function SlimeRace({ onClose }: Props) {
       ctx.fillRect(0, 0, TRACK_W, TRACK_H);
     }
 
-    // ===== FEVER MODE OVERLAY =====
-    if (feverRef.current) {
-      const ft = feverFlashRef.current;
-      // Rainbow border pulse
-      const hue = (ft * 0.3) % 360;
-      const feverAlpha = 0.06 + Math.sin(ft * 0.008) * 0.03;
-      // Edge glow
-      const edgeGrad = ctx.createRadialGradient(TRACK_W / 2, TRACK_H / 2, TRACK_H * 0.3, TRACK_W / 2, TRACK_H / 2, TRACK_H * 0.7);
-      edgeGrad.addColorStop(0, "rgba(0,0,0,0)");
-      edgeGrad.addColorStop(1, `hsla(${hue},100%,70%,${feverAlpha})`);
-      ctx.fillStyle = edgeGrad;
-      ctx.fillRect(0, 0, TRACK_W, TRACK_H);
-
-      // Rainbow particles
-      if (Math.random() < 0.5) {
-        const rainbowColors = ["#FF6B6B", "#FFEAA7", "#55EFC4", "#74B9FF", "#A29BFE", "#FF9FF3"];
-        const rc = rainbowColors[Math.floor(Math.random() * rainbowColors.length)];
-        particlesRef.current.push({
-          x: Math.random() * TRACK_W,
-          y: Math.random() * TRACK_H * 0.6,
-          vx: (Math.random() - 0.5) * 2,
-          vy: -Math.random() * 1.5,
-          life: 300 + Math.random() * 300,
-          color: rc,
-          size: 1 + Math.random() * 2,
-        });
-      }
-
-      // "FEVER" watermark
-      ctx.save();
-      ctx.globalAlpha = 0.06 + Math.sin(ft * 0.005) * 0.03;
-      ctx.fillStyle = `hsl(${hue}, 100%, 70%)`;
-      ctx.font = "bold 40px Arial";
-      ctx.textAlign = "center";
-      ctx.fillText("FEVER", TRACK_W / 2, TRACK_H / 2 + 15);
-      ctx.restore();
-
-      // Fever timer bar at top
-      const feverPct = feverTimerRef.current / 5000;
-      const barW = TRACK_W * 0.6;
-      const barX = (TRACK_W - barW) / 2;
-      ctx.fillStyle = "rgba(0,0,0,0.3)";
-      roundRect(ctx, barX, 4, barW, 5, 2.5);
-      ctx.fill();
-      const feverGrad = ctx.createLinearGradient(barX, 0, barX + barW * feverPct, 0);
-      feverGrad.addColorStop(0, `hsl(${hue}, 100%, 60%)`);
-      feverGrad.addColorStop(1, `hsl(${(hue + 60) % 360}, 100%, 60%)`);
-      ctx.fillStyle = feverGrad;
-      roundRect(ctx, barX, 4, barW * feverPct, 5, 2.5);
-      ctx.fill();
-    }
-
     // Weather icon overlay
     if (curWeather !== "clear") {
       ctx.globalAlpha = 0.4;
@@ -1253,36 +887,24 @@ export default function SlimeRace({ onClose }: Props) {
     }
   };
 
+  // Inputs are queued for the next simulation tick; the particles are just feedback
   const handleJump = () => {
-    if (raceState !== "playing") return;
-    // Cancel duck if ducking
-    if (duckingRef.current) {
-      duckingRef.current = false;
-      duckTimerRef.current = 0;
-    }
-    // First jump — from ground
-    if (jumpYRef.current === 0 && jumpCountRef.current === 0) {
-      jumpVyRef.current = JUMP_FORCE;
-      jumpCountRef.current = 1;
-      setJumping(true);
+    const sim = simRef.current;
+    if (raceState !== "playing" || !sim) return;
+    pendingActionsRef.current.push("jump");
+    if (sim.jumpCount === 0) {
       // Dust particles
       spawnParticles(particlesRef.current, SLIME_X, GROUND_Y, "rgba(180,180,150,0.4)", 4);
-    }
-    // Double jump — from air, must have used first jump
-    else if (jumpCountRef.current === 1 && jumpYRef.current > 3) {
-      jumpVyRef.current = DOUBLE_JUMP_FORCE;
-      jumpCountRef.current = 2;
+    } else if (sim.jumpCount === 1) {
       // Air puff particles
-      spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - jumpYRef.current, "rgba(255,255,255,0.3)", 5);
+      spawnParticles(particlesRef.current, SLIME_X, GROUND_Y - sim.y, "rgba(255,255,255,0.3)", 5);
     }
   };
 
   const handleDuck = () => {
-    if (raceState !== "playing") return;
-    if (jumpYRef.current === 0) {
-      duckingRef.current = true;
-      duckTimerRef.current = 450;
-    }
+    const sim = simRef.current;
+    if (raceState !== "playing" || !sim) return;
+    if (sim.y === 0) pendingActionsRef.current.push("duck");
   };
 
   // Keyboard support
@@ -1353,10 +975,10 @@ export default function SlimeRace({ onClose }: Props) {
             <div id="race-guide" className="minigame-guide-content mt-2" style={{ display: "none" }}>
               <p><strong>조작:</strong> 탭/↑ 점프 | ↑↑ 더블점프 | ↓/스와이프 엎드리기</p>
               <p><strong>장애물:</strong> 바위·선인장·불꽃·가시 = 점프 | 톱날·레이저 = 엎드리기</p>
-              <p><strong>콤보:</strong> 장애물 회피 시 콤보 +1, 콤보가 높을수록 점수 배율 증가</p>
-              <p><strong>FEVER:</strong> 콤보 15+ 달성 시 FEVER MODE (x15 점수, 무적 5초)</p>
-              <p><strong>날씨:</strong> 비·바람·눈이 번갈아 나타나며 시야와 속도에 영향</p>
-              <p><strong>파워업:</strong> 부스트(가속) / 쉴드(보호) / 하트(HP회복) / 더블(2x점수)</p>
+              <p><strong>콤보:</strong> 장애물 회피 시 콤보 +1, 콤보 10마다 회피 점수 +10</p>
+              <p><strong>치명타:</strong> 레이스 스킬이 있으면 회피 점수가 확률적으로 2배</p>
+              <p><strong>피격:</strong> HP -1, 점수 -80, 1.5초간 무적</p>
+              <p><strong>날씨:</strong> 비·바람·눈이 번갈아 나타나며 시야를 가림</p>
             </div>
           </div>
 
@@ -1421,7 +1043,7 @@ export default function SlimeRace({ onClose }: Props) {
               <div className="flex items-center gap-3">
                 {/* HP Hearts */}
                 <div className="flex items-center gap-0.5">
-                  {Array.from({ length: MAX_HP }, (_, i) => (
+                  {Array.from({ length: maxHp }, (_, i) => (
                     <span key={i} className="text-base" style={{
                       filter: i < hp ? "none" : "grayscale(1) opacity(0.3)",
                       transform: i < hp && hitFlashRef.current > 0 && i === hp ? "scale(1.3)" : "scale(1)",
@@ -1436,11 +1058,11 @@ export default function SlimeRace({ onClose }: Props) {
               <div className="flex items-center gap-3">
                 {/* Combo */}
                 <span className="text-sm font-black tabular-nums" style={{
-                  color: combo >= 20 ? "#FF6B6B" : combo >= 15 ? "#FF9FF3" : combo >= 10 ? "#FFEAA7" : combo >= 5 ? "#55EFC4" : combo >= 3 ? "#74B9FF" : "#636e72",
+                  color: combo >= 30 ? "#FF6B6B" : combo >= 20 ? "#FF9FF3" : combo >= 10 ? "#FFEAA7" : combo >= 5 ? "#55EFC4" : combo >= 3 ? "#74B9FF" : "#636e72",
                 }}>
                   {combo >= 3 && (
                     <>
-                      x{combo >= 20 ? 10 : combo >= 15 ? 8 : combo >= 10 ? 5 : combo >= 5 ? 3 : 2}
+                      x{1 + Math.floor(combo / 10)}
                       <span className="text-[9px] text-[#B2BEC3] ml-0.5">{combo}</span>
                     </>
                   )}
@@ -1449,46 +1071,12 @@ export default function SlimeRace({ onClose }: Props) {
               </div>
             </div>
 
-            {/* Active power-up badge */}
-            <div className="h-5 flex justify-center gap-2 mb-1">
-              {fever && (
-                <div className="px-3 py-0.5 rounded-full text-[10px] font-black animate-pulse"
-                  style={{
-                    background: "linear-gradient(90deg, rgba(255,107,107,0.2), rgba(255,234,167,0.2), rgba(85,239,196,0.2), rgba(116,185,255,0.2), rgba(162,155,254,0.2))",
-                    color: "#FFEAA7",
-                    border: "2px solid rgba(201,168,76,0.5)",
-                    boxShadow: "0 0 16px rgba(255,234,167,0.3), 0 0 30px rgba(201,168,76,0.15)",
-                  }}>
-                  {"FEVER x15!"}
-                </div>
-              )}
-              {activePowerUp && !fever && (
-                <div className="px-3 py-0.5 rounded-full text-[10px] font-bold animate-pulse" style={{
-                  background: activePowerUp.type === "boost" ? "rgba(255,234,167,0.15)"
-                    : activePowerUp.type === "double" ? "rgba(85,239,196,0.15)"
-                      : "rgba(162,155,254,0.15)",
-                  color: activePowerUp.type === "boost" ? "#FFEAA7"
-                    : activePowerUp.type === "double" ? "#55EFC4"
-                      : "#A29BFE",
-                }}>
-                  {activePowerUp.type === "boost" ? "\uD83D\uDE80 \uBD80\uC2A4\uD2B8!" : activePowerUp.type === "double" ? "\u2728 \uB354\uBE14!" : "\uD83E\uDDF2 \uC790\uC11D!"}
-                </div>
-              )}
-              {hasShield && !activePowerUp && !fever && (
-                <div className="px-3 py-0.5 rounded-full bg-[#74B9FF]/10 border border-[#74B9FF]/20">
-                  <span className="text-[10px] text-[#74B9FF] font-bold">{"\uD83D\uDEE1\uFE0F \uC270\uB4DC"}</span>
-                </div>
-              )}
-            </div>
-
             {/* Race canvas */}
             <div className="rounded-2xl overflow-hidden border mx-auto"
               style={{
-                borderColor: fever ? `hsla(${(Date.now() * 0.3) % 360},100%,70%,0.3)` : "rgba(139,105,20,0.2)",
+                borderColor: "rgba(139,105,20,0.2)",
                 transform: screenShake > 0 ? `translate(${(Math.random() - 0.5) * 10}px, ${(Math.random() - 0.5) * 10}px)` : undefined,
-                boxShadow: fever
-                  ? `0 0 30px hsla(${(Date.now() * 0.3) % 360},100%,70%,0.15), 0 0 60px hsla(${(Date.now() * 0.3 + 120) % 360},100%,70%,0.08)`
-                  : `0 0 40px rgba(0,0,0,0.6), inset 0 0 60px rgba(0,0,0,0.15)${speedRef.current > 6 ? `, 0 0 ${(speedRef.current - 6) * 8}px rgba(255,100,50,0.1)` : ""}`,
+                boxShadow: `0 0 40px rgba(0,0,0,0.6), inset 0 0 60px rgba(0,0,0,0.15)${speedRef.current > 6 ? `, 0 0 ${(speedRef.current - 6) * 8}px rgba(255,100,50,0.1)` : ""}`,
               }}>
               <canvas
                 ref={canvasRef}
@@ -1866,27 +1454,29 @@ function drawObstacle(ctx: CanvasRenderingContext2D, ob: Obstacle) {
   }
 }
 
-function makeObstacle(type: Obstacle["type"], x: number): Obstacle {
+// Ground obstacles stay within the height a jump has to clear; aerial ones hang low
+// enough to hit a standing slime but not a ducking one, as the simulation scores them.
+// x is placed every frame from the track.
+function makeObstacle(type: RaceObstacleKind, width: number): Obstacle {
+  const x = TRACK_W + 50;
   switch (type) {
-    case "rock":
-      return { x, y: GROUND_Y - 18, type, width: 20, height: 18, hit: false, aerial: false };
+    case "rock": {
+      const height = Math.min(width, 30);
+      return { x, y: GROUND_Y - height, type, width, height, hit: false, aerial: false };
+    }
     case "cactus":
-      return { x, y: GROUND_Y - 30, type, width: 18, height: 30, hit: false, aerial: false };
+      return { x, y: GROUND_Y - 30, type, width, height: 30, hit: false, aerial: false };
     case "fire":
-      return { x, y: GROUND_Y - 35, type, width: 14, height: 35, hit: false, osc: Math.random() * Math.PI * 2, aerial: false };
+      return { x, y: GROUND_Y - 30, type, width, height: 30, hit: false, osc: Math.random() * Math.PI * 2, aerial: false };
     case "spike":
-      return { x, y: GROUND_Y - 20, type, width: 24, height: 20, hit: false, aerial: false };
+      return { x, y: GROUND_Y - 20, type, width, height: 20, hit: false, aerial: false };
     case "saw":
-      return { x, y: GROUND_Y - 58, type, width: 22, height: 22, hit: false, aerial: true };
+      return { x, y: GROUND_Y - 34, type, width, height: 22, hit: false, aerial: true };
     case "laser":
-      return { x, y: GROUND_Y - 50, type, width: 40, height: 8, hit: false, aerial: true };
+      return { x, y: GROUND_Y - 24, type, width, height: 8, hit: false, aerial: true };
   }
 }
 
-function checkAABB(a: { x: number; y: number; w: number; h: number }, b: { x: number; y: number; w: number; h: number }) {
-  return a.x < b.x + b.w && a.x + a.w > b.x && a.y < b.y + b.h && a.y + a.h > b.y;
-}
-
 function roundRect(ctx: CanvasRenderingContext2D, x: number, y: number, w: number, h: number, r: number) {
   ctx.beginPath();
   ctx.moveTo(x + r, y);
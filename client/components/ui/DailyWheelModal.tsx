@@ -2,7 +2,7 @@
 
 import { useEffect, useState, useRef, useMemo } from "react";
 import { useAuthStore } from "@/lib/store/authStore";
-import { authApi } from "@/lib/api/client";
+import { authApi, newIdempotencyKey } from "@/lib/api/client";
 import { toastReward } from "./Toast";
 
 interface WheelReward {
@@ -203,7 +203,7 @@ export default function DailyWheelModal({ onClose }: Props) {
       const res = await authApi<{ reward_id: number; reward: WheelReward; free_spins: number }>(
         "/api/wheel/spin",
         token,
-        { method: "POST", body: { use_gems: useGems } }
+        { method: "POST", body: { use_gems: useGems }, idempotencyKey: newIdempotencyKey() }
       );
 
       const segments = rewards.length;
@@ -0,0 +1,241 @@
+"use client";
+
+import { useEffect, useState, useCallback } from "react";
+import { useAuthStore } from "@/lib/store/authStore";
+import { authApi, ApiError } from "@/lib/api/client";
+import { toastSuccess, toastError } from "@/components/ui/Toast";
+import PageLayout from "./PageLayout";
+import GiftModal from "./GiftModal";
+
+interface Friend {
+  user_id: string;
+  nickname: string;
+  level: number;
+  profile_image_url: string;
+  since: string;
+  online: boolean;
+  last_seen: string | null;
+}
+
+interface FriendRequest {
+  id: string;
+  user_id: string;
+  nickname: string;
+  level: number;
+  incoming: boolean;
+  created_at: string;
+}
+
+const ERROR_TEXT: Record<string, string> = {
+  user_not_found: "해당 유저를 찾을 수 없어요",
+  cannot_add_self: "자기 자신은 추가할 수 없어요",
+  already_friends: "이미 친구예요",
+  request_already_sent: "이미 요청을 보냈어요",
+  friend_limit: "친구 수가 최대에 도달했어요",
+  friend_limit_other: "상대방의 친구 수가 최대예요",
+  blocked: "친구 요청을 보낼 수 없는 유저예요",
+  request_not_found: "요청을 찾을 수 없어요",
+};
+
+function errorText(err: unknown, fallback: string) {
+  if (err instanceof ApiError) {
+    const code = err.data.error as string;
+    return ERROR_TEXT[code] || fallback;
+  }
+  return fallback;
+}
+
+function lastSeenText(f: Friend) {
+  if (f.online) return "온라인";
+  if (!f.last_seen) return "오프라인";
+  const mins = Math.floor((Date.now() - new Date(f.last_seen).getTime()) / 60000);
+  if (mins < 60) return `${Math.max(mins, 1)}분 전 접속`;
+  if (mins < 60 * 24) return `${Math.floor(mins / 60)}시간 전 접속`;
+  return `${Math.floor(mins / (60 * 24))}일 전 접속`;
+}
+
+export default function FriendsPage() {
+  const token = useAuthStore((s) => s.accessToken);
+  const [friends, setFriends] = useState<Friend[]>([]);
+  const [requests, setRequests] = useState<FriendRequest[]>([]);
+  const [friendCode, setFriendCode] = useState("");
+  const [limit, setLimit] = useState(50);
+  const [online, setOnline] = useState(0);
+  const [input, setInput] = useState("");
+  const [loading, setLoading] = useState(false);
+  const [giftTo, setGiftTo] = useState<string | null>(null);
+
+  const fetchData = useCallback(async () => {
+    if (!token) return;
+    setLoading(true);
+    try {
+      const [fRes, rRes] = await Promise.all([
+        authApi<{ friends: Friend[]; online: number; limit: number; friend_code: string }>("/api/friends", token),
+        authApi<{ requests: FriendRequest[] }>("/api/friends/requests", token),
+      ]);
+      setFriends(fRes.friends || []);
+      setOnline(fRes.online || 0);
+      setLimit(fRes.limit || 50);
+      setFriendCode(fRes.friend_code || "");
+      setRequests(rRes.requests || []);
+    } catch {
+      // ignore
+    }
+    setLoading(false);
+  }, [token]);
+
+  useEffect(() => { fetchData(); }, [fetchData]);
+
+  const sendRequest = async () => {
+    const value = input.trim();
+    if (!token || !value) return;
+    // Friend codes are 8 hex characters; anything else is treated as a nickname
+    const body = /^[0-9a-fA-F]{8}$/.test(value) ? { friend_code: value } : { nickname: value };
+    try {
+      const res = await authApi<{ accepted: boolean }>("/api/friends/requests", token, { method: "POST", body });
+      toastSuccess(res.accepted ? "친구가 되었어요!" : "친구 요청을 보냈어요");
+      setInput("");
+      fetchData();
+    } catch (err) {
+      toastError(errorText(err, "친구 요청 실패"));
+    }
+  };
+
+  const respond = async (req: FriendRequest, action: "accept" | "decline" | "cancel") => {
+    if (!token) return;
+    try {
+      if (action === "cancel") {
+        await authApi(`/api/friends/requests/${req.id}`, token, { method: "DELETE" });
+      } else {
+        await authApi(`/api/friends/requests/${req.id}/${action}`, token, { method: "POST" });
+      }
+      if (action === "accept") toastSuccess(`${req.nickname}님과 친구가 되었어요!`);
+      fetchData();
+    } catch (err) {
+      toastError(errorText(err, "요청 처리 실패"));
+    }
+  };
+
+  const removeFriend = async (f: Friend) => {
+    if (!token || !confirm(`${f.nickname}님을 친구에서 삭제할까요?`)) return;
+    try {
+      await authApi(`/api/friends/${f.user_id}`, token, { method: "DELETE" });
+      fetchData();
+    } catch (err) {
+      toastError(errorText(err, "삭제 실패"));
+    }
+  };
+
+  const incoming = requests.filter((r) => r.incoming);
+  const outgoing = requests.filter((r) => !r.incoming);
+
+  const card = {
+    background: "linear-gradient(135deg, rgba(44,24,16,0.6), rgba(26,14,8,0.5))",
+    border: "1px solid #3D2017",
+    borderRadius: 12,
+  };
+
+  return (
+    <PageLayout title="친구" icon="/assets/icons/collect.png"
+      badge={<span className="text-[10px] font-bold" style={{ color: "#C9A84C" }}>{friends.length}/{limit}</span>}>
+      {/* My friend code + add */}
+      <div className="p-3 mb-3" style={card}>
+        <div className="flex items-center justify-between mb-2">
+          <span className="text-[10px]" style={{ color: "#8B6914" }}>내 친구 코드</span>
+          <button
+            onClick={() => { navigator.clipboard?.writeText(friendCode); toastSuccess("복사했어요"); }}
+            className="text-sm font-extrabold tracking-widest tabular-nums"
+            style={{ color: "#C9A84C", fontFamily: "Georgia, 'Times New Roman', serif" }}>
+            {friendCode || "--------"}
+          </button>
+        </div>
+        <div className="flex gap-2">
+          <input
+            type="text"
+            value={input}
+            onChange={(e) => setInput(e.target.value)}
+            onKeyDown={(e) => e.key === "Enter" && sendRequest()}
+            placeholder="친구 코드 또는 닉네임"
+            className="flex-1 bg-black/20 rounded-lg px-3 py-2 text-xs text-[#F5E6C8] placeholder:text-[#6B3A2A] focus:outline-none"
+            style={{ border: "1px solid #3D2017" }}
+          />
+          <button onClick={sendRequest} disabled={!input.trim()}
+            className="px-3 py-2 rounded-lg text-[11px] font-bold"
+            style={{ background: "linear-gradient(135deg, #C9A84C, #8B6914)", color: "#1A0E08" }}>
+            추가
+          </button>
+        </div>
+      </div>
+
+      {/* Requests */}
+      {(incoming.length > 0 || outgoing.length > 0) && (
+        <div className="mb-3 space-y-1.5">
+          {incoming.map((r) => (
+            <div key={r.id} className="flex items-center gap-2 px-3 py-2" style={card}>
+              <span className="flex-1 text-[11px] font-bold truncate" style={{ color: "#F5E6C8" }}>
+                {r.nickname} <span className="text-[9px]" style={{ color: "#8B6914" }}>Lv.{r.level}</span>
+              </span>
+              <button onClick={() => respond(r, "accept")} className="px-2 py-1 rounded text-[10px] font-bold"
+                style={{ background: "#55EFC420", color: "#55EFC4", border: "1px solid #55EFC440" }}>수락</button>
+              <button onClick={() => respond(r, "decline")} className="px-2 py-1 rounded text-[10px] font-bold"
+                style={{ background: "#FF767520", color: "#FF7675", border: "1px solid #FF767540" }}>거절</button>
+            </div>
+          ))}
+          {outgoing.map((r) => (
+            <div key={r.id} className="flex items-center gap-2 px-3 py-2" style={card}>
+              <span className="flex-1 text-[11px] truncate" style={{ color: "#8B6914" }}>
+                {r.nickname}님에게 요청 보냄
+              </span>
+              <button onClick={() => respond(r, "cancel")} className="px-2 py-1 rounded text-[10px]"
+                style={{ color: "#6B3A2A", border: "1px solid #3D2017" }}>취소</button>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {/* Friend list */}
+      <div className="text-[10px] mb-1.5" style={{ color: "#8B6914" }}>
+        접속 중 {online}명
+      </div>
+      {loading && friends.length === 0 ? (
+        <div className="space-y-1.5">
+          {Array.from({ length: 4 }).map((_, i) => (
+            <div key={i} className="h-12 skeleton" style={{ ...card, background: "#3D2017" }} />
+          ))}
+        </div>
+      ) : friends.length === 0 ? (
+        <div className="empty-state py-10" style={card}>
+          <span className="empty-state-icon text-3xl">{"🤝"}</span>
+          <span className="empty-state-text" style={{ color: "#8B6914" }}>친구 코드로 친구를 추가해보세요</span>
+        </div>
+      ) : (
+        <div className="space-y-1.5">
+          {friends.map((f) => (
+            <div key={f.user_id} className="flex items-center gap-2.5 px-3 py-2.5" style={card}>
+              <div className="relative w-8 h-8 rounded-full flex items-center justify-center text-[11px] font-bold shrink-0"
+                style={{ background: "rgba(61,32,23,0.8)", color: "#C9A84C", border: "1px solid #3D201780" }}>
+                {f.nickname[0]}
+                <span className="absolute -bottom-0.5 -right-0.5 w-2.5 h-2.5 rounded-full"
+                  style={{ background: f.online ? "#55EFC4" : "#636e72", border: "2px solid #1A0E08" }} />
+              </div>
+              <div className="flex-1 min-w-0">
+                <div className="text-[11px] font-bold truncate" style={{ color: "#E8D5B0" }}>
+                  {f.nickname} <span className="text-[9px]" style={{ color: "#8B6914" }}>Lv.{f.level}</span>
+                </div>
+                <div className="text-[9px]" style={{ color: f.online ? "#55EFC4" : "#6B3A2A" }}>{lastSeenText(f)}</div>
+              </div>
+              <button onClick={() => setGiftTo(f.nickname)} className="px-2 py-1 rounded text-[10px] font-bold"
+                style={{ background: "#FF9FF320", color: "#FF9FF3", border: "1px solid #FF9FF340" }}>
+                {"🎁"}
+              </button>
+              <button onClick={() => removeFriend(f)} className="px-2 py-1 rounded text-[10px]"
+                style={{ color: "#6B3A2A", border: "1px solid #3D2017" }}>삭제</button>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {giftTo && <GiftModal onClose={() => setGiftTo(null)} defaultNickname={giftTo} />}
+    </PageLayout>
+  );
+}
@@ -5,7 +5,7 @@ import { useAuthStore } from "@/lib/store/authStore";
 import { useGameStore, type ShopItem } from "@/lib/store/gameStore";
 import { gradeColors, gradeNames } from "@/lib/constants";
 import { elementColors, elementNames } from "@/lib/constants";
-import { authApi } from "@/lib/api/client";
+import { api, authApi } from "@/lib/api/client";
 import GachaRevealModal from "./GachaRevealModal";
 import { toastError } from "./Toast";
 
@@ -130,6 +130,11 @@ interface SlimeResult {
   species: { id: number; name: string; name_en: string; element: string; grade: string; description: string };
 }
 
+// Published odds from /api/shop/rates/:egg_type
+interface RateDisclosure {
+  grades: { grade: string; base_rate: number; effective_rate: number }[];
+}
+
 const MULTI_PULL_MAP: Record<number, number> = { 1: 17, 2: 18, 6: 19 };
 
 export default function GachaPage() {
@@ -142,6 +147,7 @@ export default function GachaPage() {
   const [pity, setPity] = useState<Record<string, PityEntry>>({});
   const [expandedBanner, setExpandedBanner] = useState<number | null>(null);
   const [pulling, setPulling] = useState(false);
+  const [liveRates, setLiveRates] = useState<Record<number, EggBanner["rates"]>>({});
   const bannerScrollRef = useRef<HTMLDivElement>(null);
 
   useEffect(() => {
@@ -158,9 +164,32 @@ export default function GachaPage() {
 
   useEffect(() => { fetchPity(); }, [fetchPity]);
 
-  const filteredBanners = activeTab === "all"
+  // Show the server's live odds instead of the built-in defaults once they load
+  useEffect(() => {
+    const eggs = ALL_BANNERS
+      .map((b) => ({ itemId: b.itemId, eggType: shopItems.find((i) => i.id === b.itemId)?.egg_type }))
+      .filter((e): e is { itemId: number; eggType: string } => !!e.eggType);
+    if (eggs.length === 0) return;
+    Promise.all(eggs.map(async ({ itemId, eggType }) => {
+      try {
+        const res = await api<RateDisclosure>(`/api/shop/rates/${eggType}`);
+        return [itemId, res.grades.map((g) => ({ grade: g.grade, pct: Math.round(g.base_rate * 10000) / 100 }))] as const;
+      } catch {
+        return null;
+      }
+    })).then((entries) => {
+      const next: Record<number, EggBanner["rates"]> = {};
+      for (const e of entries) {
+        if (e) next[e[0]] = e[1];
+      }
+      setLiveRates(next);
+    });
+  }, [shopItems]);
+
+  const filteredBanners = (activeTab === "all"
     ? ALL_BANNERS
-    : ALL_BANNERS.filter(b => b.tabGroup === activeTab);
+    : ALL_BANNERS.filter(b => b.tabGroup === activeTab)
+  ).map((b) => (liveRates[b.itemId] ? { ...b, rates: liveRates[b.itemId] } : b));
 
   const handleEggPull = async (itemId: number, quantity: number) => {
     const item = shopItems.find((i) => i.id === itemId);
@@ -529,11 +558,11 @@ function FeaturedBannerCard({ banner, item, idx, expanded, onToggle, onPull, can
 
           {/* Pull buttons */}
           <div className="flex gap-2">
-            {[1, 6, 18].map((qty) => {
+            {(multiItemId ? [1] : [1, 10]).map((qty) => {
               const totalGold = cost.gold * qty;
               const totalGems = cost.gems * qty;
               const affordable = canAffordMulti(qty);
-              const isHighlight = qty === 6;
+              const isHighlight = qty === 10;
               return (
                 <button
                   key={qty}
@@ -562,7 +591,7 @@ function FeaturedBannerCard({ banner, item, idx, expanded, onToggle, onPull, can
                         background: "linear-gradient(135deg, #D4AF37, #C9A84C)",
                         color: "#3D2017",
                       }}>
-                      HOT
+                      레어+ 확정
                     </div>
                   )}
                   <div className="text-[11px] font-bold" style={{
@@ -616,7 +645,7 @@ function FeaturedBannerCard({ banner, item, idx, expanded, onToggle, onPull, can
                     background: "linear-gradient(135deg, #55EFC4, #00B894)",
                     color: "#1A0E08",
                   }}>
-                  -10%
+                  -10% · 레어+ 확정
                 </div>
                 <div className="text-[11px] font-bold" style={{
                   color: multiAffordable ? "#F5E6C8" : "rgba(139,105,20,0.3)",
@@ -727,7 +756,7 @@ function ElementEggCard({ banner, item, idx, onPull, canAffordMulti, pulling }:
 
       {showPulls && (
         <div className="px-3 pb-3 space-y-1.5 animate-fade-in-up">
-          {[1, 6, 18].map((qty) => {
+          {[1, 10].map((qty) => {
             const totalGold = cost.gold * qty;
             const totalGems = cost.gems * qty;
             const affordable = canAffordMulti(qty);
@@ -750,7 +779,7 @@ function ElementEggCard({ banner, item, idx, onPull, canAffordMulti, pulling }:
                   {!affordable && !pulling ? (
                     <span>{qty}회 <span style={{ color: "#E74C3C", fontSize: "9px" }}>부족</span></span>
                   ) : (
-                    `${qty}회 뽑기`
+                    `${qty}회 뽑기${qty === 10 ? " (레어+ 확정)" : ""}`
                   )}
                 </span>
                 <span className="text-[9px] font-bold" style={{
@@ -0,0 +1,600 @@
+"use client";
+
+import { useEffect, useState, useCallback, useRef } from "react";
+import { useAuthStore } from "@/lib/store/authStore";
+import { useGameStore } from "@/lib/store/gameStore";
+import { authApi, ApiError } from "@/lib/api/client";
+import { toastSuccess, toastError } from "@/components/ui/Toast";
+import PageLayout from "./PageLayout";
+
+interface Guild {
+  id: string;
+  name: string;
+  description: string;
+  join_policy: "open" | "apply" | "invite";
+  level: number;
+  xp: number;
+  next_level_xp: number;
+  member_count: number;
+  max_members: number;
+}
+
+interface Member {
+  user_id: string;
+  nickname: string;
+  level: number;
+  role: "leader" | "officer" | "member";
+  contributed_xp: number;
+  online: boolean;
+}
+
+interface GuildRequest {
+  id: string;
+  guild_id: string;
+  guild_name: string;
+  user_id: string;
+  nickname: string;
+  kind: "invite" | "apply";
+}
+
+interface ChatMessage {
+  id: number;
+  user_id: string;
+  nickname: string;
+  message: string;
+  created_at: string;
+}
+
+interface GuildBoss {
+  name: string;
+  element: string;
+  stage: number;
+  max_hp: number;
+  current_hp: number;
+  expires_at: string;
+  reward_gold: number;
+  reward_gems: number;
+}
+
+interface BossState {
+  boss: GuildBoss;
+  damage_table: { user_id: string; nickname: string; damage: number }[];
+  my_attacks: number;
+  max_attacks: number;
+}
+
+const ERROR_TEXT: Record<string, string> = {
+  guild_not_found: "길드를 찾을 수 없어요",
+  not_in_guild: "길드에 가입되어 있지 않아요",
+  already_in_guild: "이미 길드에 가입되어 있어요",
+  name_taken: "이미 사용 중인 길드 이름이에요",
+  guild_full: "길드 인원이 가득 찼어요",
+  insufficient_role: "권한이 없어요",
+  invite_only: "초대로만 가입할 수 있는 길드예요",
+  request_pending: "이미 처리 대기 중인 요청이 있어요",
+  request_not_found: "요청을 찾을 수 없어요",
+  insufficient_funds: "골드가 부족해요",
+  user_not_found: "해당 유저를 찾을 수 없어요",
+  too_fast: "조금 천천히 보내주세요",
+};
+
+const ROLE_TEXT: Record<Member["role"], string> = {
+  leader: "길드장",
+  officer: "부길드장",
+  member: "길드원",
+};
+
+const POLICY_TEXT: Record<Guild["join_policy"], string> = {
+  open: "자유 가입",
+  apply: "가입 신청",
+  invite: "초대 전용",
+};
+
+function errorText(err: unknown, fallback: string) {
+  if (err instanceof ApiError) {
+    const code = err.data.error as string;
+    return ERROR_TEXT[code] || fallback;
+  }
+  return fallback;
+}
+
+const card = {
+  background: "linear-gradient(135deg, rgba(44,24,16,0.6), rgba(26,14,8,0.5))",
+  border: "1px solid #3D2017",
+  borderRadius: 12,
+};
+
+const goldButton = { background: "linear-gradient(135deg, #C9A84C, #8B6914)", color: "#1A0E08" };
+
+export default function GuildPage() {
+  const token = useAuthStore((s) => s.accessToken);
+  const user = useAuthStore((s) => s.user);
+  const [guild, setGuild] = useState<Guild | null>(null);
+  const [myRole, setMyRole] = useState<Member["role"]>("member");
+  const [members, setMembers] = useState<Member[]>([]);
+  const [requests, setRequests] = useState<GuildRequest[]>([]);
+  const [invites, setInvites] = useState<GuildRequest[]>([]);
+  const [createCost, setCreateCost] = useState(0);
+  const [loaded, setLoaded] = useState(false);
+  const [tab, setTab] = useState<"members" | "chat" | "boss">("members");
+
+  const fetchGuild = useCallback(async () => {
+    if (!token) return;
+    try {
+      const res = await authApi<{
+        guild: Guild | null; my_role?: Member["role"]; members?: Member[];
+        requests?: GuildRequest[]; invites?: GuildRequest[]; create_cost?: number;
+      }>("/api/guild", token);
+      setGuild(res.guild);
+      setMyRole(res.my_role || "member");
+      setMembers(res.members || []);
+      setRequests(res.requests || []);
+      setInvites(res.invites || []);
+      setCreateCost(res.create_cost || 0);
+    } catch {
+      // ignore
+    }
+    setLoaded(true);
+  }, [token]);
+
+  useEffect(() => { fetchGuild(); }, [fetchGuild]);
+
+  if (!loaded) {
+    return (
+      <PageLayout title="길드" icon="/assets/icons/collect.png">
+        <div className="h-24 skeleton" style={{ ...card, background: "#3D2017" }} />
+      </PageLayout>
+    );
+  }
+
+  if (!guild) {
+    return (
+      <PageLayout title="길드" icon="/assets/icons/collect.png">
+        <NoGuild token={token} invites={invites} createCost={createCost} onChange={fetchGuild} />
+      </PageLayout>
+    );
+  }
+
+  const canManage = myRole !== "member";
+
+  const leave = async () => {
+    if (!token || !confirm(myRole === "leader" ? "길드를 떠나면 다른 멤버에게 길드장이 넘어가요. 떠날까요?" : "길드를 떠날까요?")) return;
+    try {
+      await authApi("/api/guild/leave", token, { method: "POST" });
+      fetchGuild();
+    } catch (err) {
+      toastError(errorText(err, "길드 탈퇴 실패"));
+    }
+  };
+
+  const respondApplication = async (r: GuildRequest, accept: boolean) => {
+    if (!token) return;
+    try {
+      await authApi(`/api/guild/applications/${r.id}/${accept ? "accept" : "decline"}`, token, { method: "POST" });
+      if (accept) toastSuccess(`${r.nickname}님이 길드에 가입했어요`);
+      fetchGuild();
+    } catch (err) {
+      toastError(errorText(err, "요청 처리 실패"));
+    }
+  };
+
+  const memberAction = async (m: Member, action: "promote" | "demote" | "kick") => {
+    if (!token) return;
+    if (action === "kick" && !confirm(`${m.nickname}님을 추방할까요?`)) return;
+    try {
+      if (action === "kick") {
+        await authApi(`/api/guild/members/${m.user_id}`, token, { method: "DELETE" });
+      } else {
+        const role = action === "promote" ? "officer" : "member";
+        await authApi(`/api/guild/members/${m.user_id}/role`, token, { method: "POST", body: { role } });
+      }
+      fetchGuild();
+    } catch (err) {
+      toastError(errorText(err, "처리 실패"));
+    }
+  };
+
+  const xpPct = guild.next_level_xp > 0 ? Math.min(100, (guild.xp / guild.next_level_xp) * 100) : 100;
+
+  return (
+    <PageLayout title={guild.name} icon="/assets/icons/collect.png"
+      badge={<span className="text-[10px] font-bold" style={{ color: "#C9A84C" }}>Lv.{guild.level}</span>}>
+      {/* Guild info */}
+      <div className="p-3 mb-3" style={card}>
+        {guild.description && (
+          <div className="text-[11px] mb-2" style={{ color: "#E8D5B0" }}>{guild.description}</div>
+        )}
+        <div className="flex items-center justify-between text-[10px] mb-1" style={{ color: "#8B6914" }}>
+          <span>{POLICY_TEXT[guild.join_policy]} · {guild.member_count}/{guild.max_members}명</span>
+          <span>{guild.next_level_xp > 0 ? `${guild.xp} / ${guild.next_level_xp} XP` : "MAX"}</span>
+        </div>
+        <div className="h-1.5 rounded-full overflow-hidden" style={{ background: "rgba(0,0,0,0.3)" }}>
+          <div className="h-full rounded-full" style={{ width: `${xpPct}%`, background: "linear-gradient(90deg, #C9A84C, #FFEAA7)" }} />
+        </div>
+      </div>
+
+      {/* Tabs */}
+      <div className="flex gap-1.5 mb-3">
+        {(["members", "chat", "boss"] as const).map((t) => (
+          <button key={t} onClick={() => setTab(t)} className="flex-1 py-1.5 rounded-lg text-[11px] font-bold"
+            style={tab === t ? goldButton : { color: "#8B6914", border: "1px solid #3D2017" }}>
+            {t === "members" ? "멤버" : t === "chat" ? "채팅" : "길드 보스"}
+          </button>
+        ))}
+      </div>
+
+      {tab === "members" && (
+        <>
+          {canManage && <InviteBox token={token} onInvited={fetchGuild} />}
+          {canManage && requests.filter((r) => r.kind === "apply").length > 0 && (
+            <div className="mb-3 space-y-1.5">
+              {requests.filter((r) => r.kind === "apply").map((r) => (
+                <div key={r.id} className="flex items-center gap-2 px-3 py-2" style={card}>
+                  <span className="flex-1 text-[11px] font-bold truncate" style={{ color: "#F5E6C8" }}>
+                    {r.nickname} <span className="text-[9px]" style={{ color: "#8B6914" }}>가입 신청</span>
+                  </span>
+                  <button onClick={() => respondApplication(r, true)} className="px-2 py-1 rounded text-[10px] font-bold"
+                    style={{ background: "#55EFC420", color: "#55EFC4", border: "1px solid #55EFC440" }}>수락</button>
+                  <button onClick={() => respondApplication(r, false)} className="px-2 py-1 rounded text-[10px] font-bold"
+                    style={{ background: "#FF767520", color: "#FF7675", border: "1px solid #FF767540" }}>거절</button>
+                </div>
+              ))}
+            </div>
+          )}
+          <div className="space-y-1.5">
+            {members.map((m) => (
+              <div key={m.user_id} className="flex items-center gap-2.5 px-3 py-2.5" style={card}>
+                <div className="relative w-8 h-8 rounded-full flex items-center justify-center text-[11px] font-bold shrink-0"
+                  style={{ background: "rgba(61,32,23,0.8)", color: "#C9A84C", border: "1px solid #3D201780" }}>
+                  {m.nickname[0]}
+                  <span className="absolute -bottom-0.5 -right-0.5 w-2.5 h-2.5 rounded-full"
+                    style={{ background: m.online ? "#55EFC4" : "#636e72", border: "2px solid #1A0E08" }} />
+                </div>
+                <div className="flex-1 min-w-0">
+                  <div className="text-[11px] font-bold truncate" style={{ color: "#E8D5B0" }}>
+                    {m.nickname} <span className="text-[9px]" style={{ color: "#8B6914" }}>Lv.{m.level}</span>
+                  </div>
+                  <div className="text-[9px]" style={{ color: m.role === "member" ? "#6B3A2A" : "#C9A84C" }}>
+                    {ROLE_TEXT[m.role]} · 기여 {m.contributed_xp}
+                  </div>
+                </div>
+                {myRole === "leader" && m.role === "member" && (
+                  <button onClick={() => memberAction(m, "promote")} className="px-2 py-1 rounded text-[10px]"
+                    style={{ color: "#C9A84C", border: "1px solid #3D2017" }}>임명</button>
+                )}
+                {myRole === "leader" && m.role === "officer" && (
+                  <button onClick={() => memberAction(m, "demote")} className="px-2 py-1 rounded text-[10px]"
+                    style={{ color: "#8B6914", border: "1px solid #3D2017" }}>해임</button>
+                )}
+                {m.user_id !== user?.id && canManage && m.role !== "leader" && (myRole === "leader" || m.role === "member") && (
+                  <button onClick={() => memberAction(m, "kick")} className="px-2 py-1 rounded text-[10px]"
+                    style={{ color: "#6B3A2A", border: "1px solid #3D2017" }}>추방</button>
+                )}
+              </div>
+            ))}
+          </div>
+          <button onClick={leave} className="w-full mt-3 py-2 rounded-lg text-[11px]"
+            style={{ color: "#6B3A2A", border: "1px solid #3D2017" }}>
+            길드 떠나기
+          </button>
+        </>
+      )}
+
+      {tab === "chat" && <GuildChat token={token} myId={user?.id} />}
+      {tab === "boss" && <GuildBossPanel token={token} />}
+    </PageLayout>
+  );
+}
+
+function NoGuild({ token, invites, createCost, onChange }: {
+  token: string | null; invites: GuildRequest[]; createCost: number; onChange: () => void;
+}) {
+  const [query, setQuery] = useState("");
+  const [results, setResults] = useState<Guild[]>([]);
+  const [name, setName] = useState("");
+  const [policy, setPolicy] = useState<Guild["join_policy"]>("apply");
+
+  const search = useCallback(async (q: string) => {
+    if (!token) return;
+    try {
+      const res = await authApi<{ guilds: Guild[] }>(`/api/guild/search?q=${encodeURIComponent(q)}`, token);
+      setResults(res.guilds || []);
+    } catch {
+      // ignore
+    }
+  }, [token]);
+
+  useEffect(() => { search(""); }, [search]);
+
+  const join = async (g: Guild) => {
+    if (!token) return;
+    try {
+      const res = await authApi<{ joined: boolean }>(`/api/guild/${g.id}/join`, token, { method: "POST" });
+      toastSuccess(res.joined ? `${g.name}에 가입했어요!` : "가입 신청을 보냈어요");
+      onChange();
+    } catch (err) {
+      toastError(errorText(err, "가입 실패"));
+    }
+  };
+
+  const respondInvite = async (r: GuildRequest, accept: boolean) => {
+    if (!token) return;
+    try {
+      await authApi(`/api/guild/invites/${r.id}/${accept ? "accept" : "decline"}`, token, { method: "POST" });
+      onChange();
+    } catch (err) {
+      toastError(errorText(err, "요청 처리 실패"));
+    }
+  };
+
+  const create = async () => {
+    if (!token || !name.trim()) return;
+    if (!confirm(`${createCost.toLocaleString()} 골드로 길드를 만들까요?`)) return;
+    try {
+      await authApi("/api/guild", token, { method: "POST", body: { name: name.trim(), join_policy: policy } });
+      toastSuccess("길드를 만들었어요!");
+      useAuthStore.getState().fetchUser();
+      onChange();
+    } catch (err) {
+      toastError(errorText(err, "길드 생성 실패"));
+    }
+  };
+
+  return (
+    <>
+      {invites.length > 0 && (
+        <div className="mb-3 space-y-1.5">
+          {invites.map((r) => (
+            <div key={r.id} className="flex items-center gap-2 px-3 py-2" style={card}>
+              <span className="flex-1 text-[11px] font-bold truncate" style={{ color: "#F5E6C8" }}>
+                {r.guild_name} <span className="text-[9px]" style={{ color: "#8B6914" }}>초대</span>
+              </span>
+              <button onClick={() => respondInvite(r, true)} className="px-2 py-1 rounded text-[10px] font-bold"
+                style={{ background: "#55EFC420", color: "#55EFC4", border: "1px solid #55EFC440" }}>수락</button>
+              <button onClick={() => respondInvite(r, false)} className="px-2 py-1 rounded text-[10px] font-bold"
+                style={{ background: "#FF767520", color: "#FF7675", border: "1px solid #FF767540" }}>거절</button>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {/* Create */}
+      <div className="p-3 mb-3" style={card}>
+        <div className="text-[10px] mb-2" style={{ color: "#8B6914" }}>
+          길드 만들기 · {createCost.toLocaleString()} 골드
+        </div>
+        <div className="flex gap-2">
+          <input type="text" value={name} maxLength={12} onChange={(e) => setName(e.target.value)}
+            placeholder="길드 이름 (2~12자)"
+            className="flex-1 bg-black/20 rounded-lg px-3 py-2 text-xs text-[#F5E6C8] placeholder:text-[#6B3A2A] focus:outline-none"
+            style={{ border: "1px solid #3D2017" }} />
+          <select value={policy} onChange={(e) => setPolicy(e.target.value as Guild["join_policy"])}
+            className="bg-black/20 rounded-lg px-2 text-[10px] text-[#F5E6C8]" style={{ border: "1px solid #3D2017" }}>
+            {(Object.keys(POLICY_TEXT) as Guild["join_policy"][]).map((p) => (
+              <option key={p} value={p}>{POLICY_TEXT[p]}</option>
+            ))}
+          </select>
+          <button onClick={create} disabled={name.trim().length < 2}
+            className="px-3 py-2 rounded-lg text-[11px] font-bold" style={goldButton}>
+            생성
+          </button>
+        </div>
+      </div>
+
+      {/* Search */}
+      <div className="flex gap-2 mb-2">
+        <input type="text" value={query} onChange={(e) => setQuery(e.target.value)}
+          onKeyDown={(e) => e.key === "Enter" && search(query.trim())}
+          placeholder="길드 검색"
+          className="flex-1 bg-black/20 rounded-lg px-3 py-2 text-xs text-[#F5E6C8] placeholder:text-[#6B3A2A] focus:outline-none"
+          style={{ border: "1px solid #3D2017" }} />
+        <button onClick={() => search(query.trim())} className="px-3 py-2 rounded-lg text-[11px] font-bold" style={goldButton}>
+          검색
+        </button>
+      </div>
+      {results.length === 0 ? (
+        <div className="empty-state py-10" style={card}>
+          <span className="empty-state-icon text-3xl">{"🏰"}</span>
+          <span className="empty-state-text" style={{ color: "#8B6914" }}>길드가 없어요. 직접 만들어보세요!</span>
+        </div>
+      ) : (
+        <div className="space-y-1.5">
+          {results.map((g) => (
+            <div key={g.id} className="flex items-center gap-2.5 px-3 py-2.5" style={card}>
+              <div className="flex-1 min-w-0">
+                <div className="text-[11px] font-bold truncate" style={{ color: "#E8D5B0" }}>
+                  {g.name} <span className="text-[9px]" style={{ color: "#8B6914" }}>Lv.{g.level}</span>
+                </div>
+                <div className="text-[9px]" style={{ color: "#6B3A2A" }}>
+                  {POLICY_TEXT[g.join_policy]} · {g.member_count}/{g.max_members}명
+                </div>
+              </div>
+              {g.join_policy !== "invite" && (
+                <button onClick={() => join(g)} className="px-2 py-1 rounded text-[10px] font-bold"
+                  style={{ background: "#55EFC420", color: "#55EFC4", border: "1px solid #55EFC440" }}>
+                  {g.join_policy === "open" ? "가입" : "신청"}
+                </button>
+              )}
+            </div>
+          ))}
+        </div>
+      )}
+    </>
+  );
+}
+
+function InviteBox({ token, onInvited }: { token: string | null; onInvited: () => void }) {
+  const [nickname, setNickname] = useState("");
+
+  const invite = async () => {
+    if (!token || !nickname.trim()) return;
+    try {
+      const res = await authApi<{ joined: boolean }>("/api/guild/invites", token, {
+        method: "POST", body: { nickname: nickname.trim() },
+      });
+      toastSuccess(res.joined ? "길드에 가입했어요" : "초대를 보냈어요");
+      setNickname("");
+      onInvited();
+    } catch (err) {
+      toastError(errorText(err, "초대 실패"));
+    }
+  };
+
+  return (
+    <div className="flex gap-2 mb-3">
+      <input type="text" value={nickname} onChange={(e) => setNickname(e.target.value)}
+        onKeyDown={(e) => e.key === "Enter" && invite()}
+        placeholder="초대할 닉네임"
+        className="flex-1 bg-black/20 rounded-lg px-3 py-2 text-xs text-[#F5E6C8] placeholder:text-[#6B3A2A] focus:outline-none"
+        style={{ border: "1px solid #3D2017" }} />
+      <button onClick={invite} disabled={!nickname.trim()} className="px-3 py-2 rounded-lg text-[11px] font-bold" style={goldButton}>
+        초대
+      </button>
+    </div>
+  );
+}
+
+function GuildChat({ token, myId }: { token: string | null; myId?: string }) {
+  const [messages, setMessages] = useState<ChatMessage[]>([]);
+  const [input, setInput] = useState("");
+  const lastId = useRef(0);
+  const bottomRef = useRef<HTMLDivElement>(null);
+
+  const poll = useCallback(async () => {
+    if (!token) return;
+    try {
+      const res = await authApi<{ messages: ChatMessage[] }>(`/api/guild/chat?after=${lastId.current}`, token);
+      const fresh = res.messages || [];
+      if (fresh.length > 0) {
+        lastId.current = fresh[fresh.length - 1].id;
+        setMessages((prev) => [...prev, ...fresh].slice(-200));
+      }
+    } catch {
+      // ignore
+    }
+  }, [token]);
+
+  // Poll every 5s while the chat tab is open
+  useEffect(() => {
+    poll();
+    const id = setInterval(poll, 5000);
+    return () => clearInterval(id);
+  }, [poll]);
+
+  useEffect(() => { bottomRef.current?.scrollIntoView({ behavior: "smooth" }); }, [messages.length]);
+
+  const send = async () => {
+    const message = input.trim();
+    if (!token || !message) return;
+    try {
+      await authApi("/api/guild/chat", token, { method: "POST", body: { message } });
+      setInput("");
+      poll();
+    } catch (err) {
+      toastError(errorText(err, "전송 실패"));
+    }
+  };
+
+  return (
+    <div className="flex flex-col" style={{ ...card, height: 360 }}>
+      <div className="flex-1 overflow-y-auto p-3 space-y-1.5">
+        {messages.length === 0 && (
+          <div className="text-center text-[10px] py-10" style={{ color: "#6B3A2A" }}>첫 메시지를 남겨보세요</div>
+        )}
+        {messages.map((m) => (
+          <div key={m.id} className={`text-[11px] ${m.user_id === myId ? "text-right" : ""}`}>
+            {m.user_id !== myId && <span className="font-bold mr-1" style={{ color: "#C9A84C" }}>{m.nickname}</span>}
+            <span style={{ color: "#E8D5B0" }}>{m.message}</span>
+          </div>
+        ))}
+        <div ref={bottomRef} />
+      </div>
+      <div className="flex gap-2 p-2" style={{ borderTop: "1px solid #3D2017" }}>
+        <input type="text" value={input} maxLength={300} onChange={(e) => setInput(e.target.value)}
+          onKeyDown={(e) => e.key === "Enter" && send()}
+          placeholder="메시지 입력"
+          className="flex-1 bg-black/20 rounded-lg px-3 py-2 text-xs text-[#F5E6C8] placeholder:text-[#6B3A2A] focus:outline-none"
+          style={{ border: "1px solid #3D2017" }} />
+        <button onClick={send} disabled={!input.trim()} className="px-3 py-2 rounded-lg text-[11px] font-bold" style={goldButton}>
+          전송
+        </button>
+      </div>
+    </div>
+  );
+}
+
+function GuildBossPanel({ token }: { token: string | null }) {
+  const slimes = useGameStore((s) => s.slimes);
+  const [state, setState] = useState<BossState | null>(null);
+  const [attacking, setAttacking] = useState(false);
+
+  const fetchBoss = useCallback(async () => {
+    if (!token) return;
+    try {
+      setState(await authApi<BossState>("/api/guild/boss", token));
+    } catch {
+      // ignore
+    }
+  }, [token]);
+
+  useEffect(() => { fetchBoss(); }, [fetchBoss]);
+
+  // Attack with the five highest-level slimes
+  const party = [...slimes].sort((a, b) => b.level - a.level).slice(0, 5).map((s) => s.id);
+
+  const attack = async () => {
+    if (!token || party.length === 0 || attacking) return;
+    setAttacking(true);
+    try {
+      const res = await authApi<{ damage: number; defeated: boolean; participation_gold: number }>(
+        "/api/guild/boss/attack", token, { method: "POST", body: { slime_ids: party } });
+      toastSuccess(res.defeated
+        ? `${res.damage.toLocaleString()} 데미지! 보스를 처치했어요!`
+        : `${res.damage.toLocaleString()} 데미지! (+${res.participation_gold}G)`);
+      useAuthStore.getState().fetchUser();
+      fetchBoss();
+    } catch (err) {
+      toastError(errorText(err, "공격 실패"));
+    }
+    setAttacking(false);
+  };
+
+  if (!state) {
+    return <div className="h-32 skeleton" style={{ ...card, background: "#3D2017" }} />;
+  }
+
+  const { boss } = state;
+  const hpPct = boss.max_hp > 0 ? (boss.current_hp / boss.max_hp) * 100 : 0;
+  const remaining = state.max_attacks - state.my_attacks;
+
+  return (
+    <>
+      <div className="p-3 mb-3" style={card}>
+        <div className="flex items-center justify-between mb-1">
+          <span className="text-sm font-extrabold" style={{ color: "#FF7675" }}>{boss.name}</span>
+          <span className="text-[10px]" style={{ color: "#8B6914" }}>Stage {boss.stage}</span>
+        </div>
+        <div className="h-2 rounded-full overflow-hidden mb-1" style={{ background: "rgba(0,0,0,0.3)" }}>
+          <div className="h-full rounded-full" style={{ width: `${hpPct}%`, background: "linear-gradient(90deg, #FF7675, #E17055)" }} />
+        </div>
+        <div className="flex justify-between text-[10px] mb-2" style={{ color: "#8B6914" }}>
+          <span>{boss.current_hp.toLocaleString()} / {boss.max_hp.toLocaleString()}</span>
+          <span>보상 {boss.reward_gold.toLocaleString()}G + {boss.reward_gems}젬</span>
+        </div>
+        <button onClick={attack} disabled={attacking || remaining <= 0 || party.length === 0}
+          className="w-full py-2 rounded-lg text-[11px] font-bold" style={goldButton}>
+          {remaining > 0 ? `공격하기 (${remaining}/${state.max_attacks})` : "오늘의 공격 완료"}
+        </button>
+      </div>
+      <div className="space-y-1">
+        {state.damage_table.map((d, i) => (
+          <div key={d.user_id} className="flex items-center gap-2 px-3 py-1.5 text-[11px]" style={card}>
+            <span className="w-5 font-bold" style={{ color: i < 3 ? "#FFEAA7" : "#6B3A2A" }}>{i + 1}</span>
+            <span className="flex-1 truncate" style={{ color: "#E8D5B0" }}>{d.nickname}</span>
+            <span className="font-bold tabular-nums" style={{ color: "#FF7675" }}>{d.damage.toLocaleString()}</span>
+          </div>
+        ))}
+      </div>
+    </>
+  );
+}
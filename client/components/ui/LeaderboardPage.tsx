@@ -12,6 +12,7 @@ interface LeaderboardEntry {
 }
 
 type LBType = "collection" | "level" | "race" | "gold";
+type LBScope = "global" | "friends";
 
 const TABS: { id: LBType; label: string; icon: string; unit: string; color: string }[] = [
   { id: "collection", label: "수집", icon: "📚", unit: "점", color: "#A29BFE" },
@@ -32,6 +33,7 @@ export default function LeaderboardPage() {
   const token = useAuthStore((s) => s.accessToken);
   const user = useAuthStore((s) => s.user);
   const [activeTab, setActiveTab] = useState<LBType>("collection");
+  const [scope, setScope] = useState<LBScope>("global");
   const [entries, setEntries] = useState<LeaderboardEntry[]>([]);
   const [myRank, setMyRank] = useState<number>(0);
   const [loading, setLoading] = useState(false);
@@ -41,7 +43,7 @@ export default function LeaderboardPage() {
     setLoading(true);
     try {
       const [lbRes, rankRes] = await Promise.all([
-        authApi<{ entries: LeaderboardEntry[] }>(`/api/leaderboard?type=${activeTab}`, token),
+        authApi<{ entries: LeaderboardEntry[] }>(`/api/leaderboard?type=${activeTab}&scope=${scope}`, token),
         authApi<{ rank: number }>("/api/leaderboard/my-rank", token),
       ]);
       setEntries(lbRes.entries || []);
@@ -50,7 +52,7 @@ export default function LeaderboardPage() {
       setEntries([]);
     }
     setLoading(false);
-  }, [token, activeTab]);
+  }, [token, activeTab, scope]);
 
   useEffect(() => { fetchData(); }, [fetchData]);
 
@@ -138,6 +140,22 @@ export default function LeaderboardPage() {
         marginTop: -4,
       }} />
 
+      {/* Scope toggle */}
+      <div className="flex justify-end gap-1 mb-3">
+        {(["global", "friends"] as LBScope[]).map((sc) => (
+          <button key={sc} onClick={() => setScope(sc)}
+            className="px-2.5 py-1 text-[10px] font-bold transition"
+            style={{
+              borderRadius: 8,
+              background: scope === sc ? "#3D2017" : "transparent",
+              color: scope === sc ? "#C9A84C" : "#6B3A2A",
+              border: scope === sc ? "1px solid #C9A84C80" : "1px solid #3D2017",
+            }}>
+            {sc === "global" ? "전체" : "친구"}
+          </button>
+        ))}
+      </div>
+
       {loading ? (
         <div className="space-y-2">
           {Array.from({ length: 8 }).map((_, i) => (
@@ -304,7 +322,7 @@ export default function LeaderboardPage() {
             color: "#6B3A2A",
             fontFamily: "Georgia, 'Times New Roman', serif",
           }}>
-            상위 {entries.length}명 표시
+            {scope === "friends" ? `친구 ${entries.length}명` : `상위 ${entries.length}명 표시`}
           </div>
         </div>
       )}
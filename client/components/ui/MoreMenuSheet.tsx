@@ -41,6 +41,8 @@ export default function MoreMenuSheet({ onClose }: MoreMenuSheetProps) {
       title: t("more_section_social"),
       items: [
         { icon: "community", labelKey: "more_community", action: () => { setShowCommunity(true); onClose(); } },
+        { icon: "community", labelKey: "more_friends", action: () => go("friends") },
+        { icon: "community", labelKey: "more_guild", action: () => go("guild") },
         { icon: "leaderboard", labelKey: "more_leaderboard", action: () => go("leaderboard") },
         { icon: "achievements", labelKey: "more_achievements", action: () => go("achievements") },
         { icon: "codex", labelKey: "more_codex", action: () => go("codex") },
@@ -5,7 +5,7 @@ import { useAuthStore } from "@/lib/store/authStore";
 import { useGameStore } from "@/lib/store/gameStore";
 import { useLocaleStore } from "@/lib/store/localeStore";
 import { type Locale } from "@/lib/i18n/translations";
-import { authApi, uploadApi, resolveMediaUrl } from "@/lib/api/client";
+import { authApi, uploadApi, downloadApi, resolveMediaUrl } from "@/lib/api/client";
 import { generateSlimeIconSvg } from "@/lib/slimeSvg";
 import { toastSuccess, toastError } from "@/components/ui/Toast";
 import { useShortsStore } from "@/lib/store/shortsStore";
@@ -950,7 +950,7 @@ function maskEmail(email: string): string {
 }
 
 function AccountView({ user, accessToken, fetchUser, t }: {
-  user: { id?: string; nickname: string; email?: string } | null;
+  user: { id?: string; nickname: string; email?: string; deletion_scheduled_at?: string | null } | null;
   accessToken: string | null;
   fetchUser: () => Promise<void>;
   t: (key: string) => string;
@@ -961,6 +961,10 @@ function AccountView({ user, accessToken, fetchUser, t }: {
   const [newPw, setNewPw] = useState("");
   const [confirmPw, setConfirmPw] = useState("");
   const [pwLoading, setPwLoading] = useState(false);
+  const [exporting, setExporting] = useState(false);
+  const [showDeleteForm, setShowDeleteForm] = useState(false);
+  const [deletePw, setDeletePw] = useState("");
+  const [deleteLoading, setDeleteLoading] = useState(false);
 
   const handleCopyUuid = () => {
     if (!user?.id) return;
@@ -1002,6 +1006,53 @@ function AccountView({ user, accessToken, fetchUser, t }: {
     setPwLoading(false);
   };
 
+  const handleExport = async () => {
+    if (!accessToken || exporting) return;
+    setExporting(true);
+    try {
+      await downloadApi("/api/user/export?format=zip", accessToken, "slimetopia-export.zip");
+    } catch {
+      toastError("데이터 내보내기에 실패했습니다");
+    }
+    setExporting(false);
+  };
+
+  const handleRequestDeletion = async () => {
+    if (!accessToken) return;
+    setDeleteLoading(true);
+    try {
+      await authApi("/api/user/delete", accessToken, {
+        method: "POST",
+        body: { confirm: true, password: deletePw },
+      });
+      toastSuccess("계정 삭제가 예약되었습니다");
+      setShowDeleteForm(false);
+      setDeletePw("");
+      await fetchUser();
+    } catch (err: unknown) {
+      const e = err as { data?: { error?: string } };
+      if (e?.data?.error === "incorrect password") {
+        toastError("비밀번호가 올바르지 않습니다");
+      } else {
+        toastError("계정 삭제 요청에 실패했습니다");
+      }
+    }
+    setDeleteLoading(false);
+  };
+
+  const handleCancelDeletion = async () => {
+    if (!accessToken) return;
+    setDeleteLoading(true);
+    try {
+      await authApi("/api/user/delete/cancel", accessToken, { method: "POST" });
+      toastSuccess("계정 삭제가 취소되었습니다");
+      await fetchUser();
+    } catch {
+      toastError("계정 삭제 취소에 실패했습니다");
+    }
+    setDeleteLoading(false);
+  };
+
   const inputStyle = {
     background: "rgba(245,230,200,0.06)",
     border: "1px solid rgba(139,105,20,0.2)",
@@ -1106,6 +1157,91 @@ function AccountView({ user, accessToken, fetchUser, t }: {
           )}
         </div>
       </div>
+
+      {/* Privacy — data export and account deletion */}
+      <div className="rounded-2xl overflow-hidden relative"
+        style={{
+          background: PARCHMENT.parchment,
+          border: `1px solid ${PARCHMENT.goldPrimary}`,
+          boxShadow: "4px 4px 12px rgba(0,0,0,0.4), inset 0 0 40px rgba(139,105,20,0.08)",
+        }}>
+        <CornerDecorations />
+
+        <div className="px-4 py-3.5">
+          <div className="flex items-center justify-between">
+            <div>
+              <p className="text-[10px] font-bold" style={{ color: PARCHMENT.goldDark, fontFamily: PARCHMENT.font }}>
+                내 데이터 다운로드
+              </p>
+              <p className="text-[10px] mt-0.5" style={{ color: PARCHMENT.inkLight, fontFamily: PARCHMENT.font }}>
+                슬라임, 도감, 우편, 게시글, 쇼츠 등 모든 기록 (ZIP)
+              </p>
+            </div>
+            <button onClick={handleExport} disabled={exporting}
+              className="text-[10px] font-bold transition disabled:opacity-40"
+              style={{ color: PARCHMENT.goldPrimary, fontFamily: PARCHMENT.font }}>
+              {exporting ? "준비 중..." : "다운로드"}
+            </button>
+          </div>
+        </div>
+        <div className="h-px mx-4" style={{ background: "linear-gradient(90deg, transparent, rgba(139,105,20,0.2), transparent)" }} />
+
+        <div className="px-4 py-3.5">
+          {user?.deletion_scheduled_at ? (
+            <div className="flex items-center justify-between">
+              <div>
+                <p className="text-[10px] font-bold" style={{ color: "#A0522D", fontFamily: PARCHMENT.font }}>
+                  계정 삭제 예정
+                </p>
+                <p className="text-[10px] mt-0.5" style={{ color: PARCHMENT.inkLight, fontFamily: PARCHMENT.font }}>
+                  {new Date(user.deletion_scheduled_at).toLocaleDateString("ko-KR")}에 모든 데이터가 삭제됩니다
+                </p>
+              </div>
+              <button onClick={handleCancelDeletion} disabled={deleteLoading}
+                className="text-[10px] font-bold transition disabled:opacity-40"
+                style={{ color: PARCHMENT.goldPrimary, fontFamily: PARCHMENT.font }}>
+                삭제 취소
+              </button>
+            </div>
+          ) : (
+            <>
+              <div className="flex items-center justify-between">
+                <p className="text-[10px] font-bold" style={{ color: PARCHMENT.goldDark, fontFamily: PARCHMENT.font }}>
+                  계정 삭제
+                </p>
+                <button onClick={() => setShowDeleteForm(!showDeleteForm)}
+                  className="text-[10px] font-bold transition"
+                  style={{ color: "#A0522D", fontFamily: PARCHMENT.font }}>
+                  {showDeleteForm ? "취소" : "삭제하기"}
+                </button>
+              </div>
+
+              {showDeleteForm && (
+                <div className="mt-3 space-y-2">
+                  <p className="text-[10px]" style={{ color: PARCHMENT.inkLight, fontFamily: PARCHMENT.font }}>
+                    14일 후 계정과 모든 게임 데이터가 영구 삭제됩니다. 그 전까지는 언제든 취소할 수 있어요.
+                  </p>
+                  {user?.email && (
+                    <input type="password" value={deletePw} onChange={(e) => setDeletePw(e.target.value)}
+                      placeholder="비밀번호 확인"
+                      className="w-full px-3 py-2 rounded-xl text-sm outline-none transition"
+                      style={inputStyle} />
+                  )}
+                  <button onClick={handleRequestDeletion} disabled={deleteLoading || (!!user?.email && !deletePw)}
+                    className="w-full py-2.5 rounded-xl font-bold text-sm transition active:scale-[0.98] disabled:opacity-40"
+                    style={{
+                      background: "linear-gradient(135deg, #A0522D, #7A3B1E)",
+                      color: "#FFF8EC",
+                      fontFamily: PARCHMENT.font,
+                    }}>
+                    {deleteLoading ? "처리 중..." : "계정 삭제 요청"}
+                  </button>
+                </div>
+              )}
+            </>
+          )}
+        </div>
+      </div>
     </div>
   );
 }
@@ -10,6 +10,15 @@ interface ApiOptions {
   method?: string;
   body?: unknown;
   headers?: Record<string, string>;
+  /** Sent as Idempotency-Key. Create one per user action with newIdempotencyKey() and
+   *  send the same key with every attempt, so the server replays instead of re-running. */
+  idempotencyKey?: string;
+}
+
+/** A fresh key for one reward or purchase action */
+export function newIdempotencyKey(): string {
+  if (typeof crypto !== "undefined" && "randomUUID" in crypto) return crypto.randomUUID();
+  return `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}${Math.random().toString(36).slice(2)}`;
 }
 
 export class ApiError extends Error {
@@ -40,7 +49,12 @@ async function tryRefreshToken(): Promise<string | null> {
       body: JSON.stringify({ refresh_token: refreshToken }),
     });
 
-    if (!res.ok) return null;
+    if (!res.ok) {
+      // Another tab may have rotated the refresh token first; use its result
+      const latest = localStorage.getItem("refresh_token");
+      if (latest && latest !== refreshToken) return localStorage.getItem("access_token");
+      return null;
+    }
 
     const data = await res.json();
     if (data.access_token && data.refresh_token) {
@@ -67,12 +81,13 @@ export async function api<T>(
   endpoint: string,
   options: ApiOptions = {}
 ): Promise<T> {
-  const { method = "GET", body, headers = {} } = options;
+  const { method = "GET", body, headers = {}, idempotencyKey } = options;
 
   const res = await fetch(`${API_BASE}${endpoint}`, {
     method,
     headers: {
       "Content-Type": "application/json",
+      ...(idempotencyKey ? { "Idempotency-Key": idempotencyKey } : {}),
       ...headers,
     },
     body: body ? JSON.stringify(body) : undefined,
@@ -125,6 +140,16 @@ export async function authApi<T>(
 
       // Refresh failed — redirect to login
       handleAuthFailure();
+    } else if (!(err instanceof ApiError) && options.idempotencyKey) {
+      // The connection dropped, maybe after the server ran the request. Retrying with the
+      // same key gets the first result back instead of granting or charging twice.
+      return api<T>(endpoint, {
+        ...options,
+        headers: {
+          Authorization: `Bearer ${token}`,
+          ...options.headers,
+        },
+      });
     }
     throw err;
   }
@@ -171,6 +196,23 @@ export async function uploadApi<T>(
   });
 }
 
+// Download API helper: fetches a file (e.g. the data export) and saves it under filename
+export async function downloadApi(endpoint: string, token: string, filename: string): Promise<void> {
+  const res = await fetch(`${API_BASE}${endpoint}`, {
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  if (!res.ok) {
+    const data = await res.json().catch(() => ({ message: "Download failed" }));
+    throw new ApiError(res.status, data);
+  }
+  const url = URL.createObjectURL(await res.blob());
+  const a = document.createElement("a");
+  a.href = url;
+  a.download = filename;
+  a.click();
+  URL.revokeObjectURL(url);
+}
+
 export async function checkHealth() {
   return api<{ status: string; service: string; version: string }>(
     "/api/health"
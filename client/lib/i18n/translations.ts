@@ -139,6 +139,8 @@ const ko: TranslationMap = {
   "login_error_register_fail": "회원가입에 실패했습니다.",
   "login_error_invalid_credentials": "이메일 또는 비밀번호가 틀립니다.",
   "login_error_login_fail": "로그인에 실패했습니다.",
+  "login_error_too_many_attempts": "로그인 시도가 너무 많습니다. 잠시 후 다시 시도해주세요.",
+  "login_forgot_password": "비밀번호를 잊으셨나요?",
 
   // Splash screen
   "splash_loading": "로딩 중...",
@@ -271,6 +273,8 @@ const ko: TranslationMap = {
   "more_mini": "미니게임",
   "more_community": "커뮤니티",
   "more_leaderboard": "리더보드",
+  "more_friends": "친구",
+  "more_guild": "길드",
   "more_achievements": "업적",
   "more_codex": "도감",
   "more_profile": "프로필",
@@ -426,6 +430,8 @@ Article 5 (Member Obligations)
   "login_error_register_fail": "Registration failed.",
   "login_error_invalid_credentials": "Invalid email or password.",
   "login_error_login_fail": "Login failed.",
+  "login_error_too_many_attempts": "Too many attempts. Please try again later.",
+  "login_forgot_password": "Forgot your password?",
 
   // Splash screen
   "splash_loading": "Loading...",
@@ -558,6 +564,8 @@ Article 5 (Member Obligations)
   "more_mini": "Mini Games",
   "more_community": "Community",
   "more_leaderboard": "Leaderboard",
+  "more_friends": "Friends",
+  "more_guild": "Guild",
   "more_achievements": "Achievements",
   "more_codex": "Codex",
   "more_profile": "Profile",
@@ -713,6 +721,8 @@ const ja: TranslationMap = {
   "login_error_register_fail": "登録に失敗しました。",
   "login_error_invalid_credentials": "メールまたはパスワードが正しくありません。",
   "login_error_login_fail": "ログインに失敗しました。",
+  "login_error_too_many_attempts": "試行回数が多すぎます。しばらくしてから再度お試しください。",
+  "login_forgot_password": "パスワードをお忘れですか？",
 
   // Splash screen
   "splash_loading": "読み込み中...",
@@ -845,6 +855,8 @@ const ja: TranslationMap = {
   "more_mini": "ミニゲーム",
   "more_community": "コミュニティ",
   "more_leaderboard": "ランキング",
+  "more_friends": "フレンド",
+  "more_guild": "ギルド",
   "more_achievements": "実績",
   "more_codex": "図鑑",
   "more_profile": "プロフィール",
@@ -1000,6 +1012,8 @@ const zhTW: TranslationMap = {
   "login_error_register_fail": "註冊失敗。",
   "login_error_invalid_credentials": "電子郵件或密碼錯誤。",
   "login_error_login_fail": "登入失敗。",
+  "login_error_too_many_attempts": "嘗試次數過多，請稍後再試。",
+  "login_forgot_password": "忘記密碼？",
 
   // Splash screen
   "splash_loading": "載入中...",
@@ -1132,6 +1146,8 @@ const zhTW: TranslationMap = {
   "more_mini": "小遊戲",
   "more_community": "社群",
   "more_leaderboard": "排行榜",
+  "more_friends": "好友",
+  "more_guild": "公会",
   "more_achievements": "成就",
   "more_codex": "圖鑑",
   "more_profile": "個人",
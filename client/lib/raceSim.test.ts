@@ -0,0 +1,30 @@
+// Checks the client race simulation against the server's fixture.
+// Run with `pnpm test` (Node 22.18+, which strips the types itself).
+import { test } from "node:test";
+import assert from "node:assert/strict";
+import { readFileSync } from "node:fs";
+import { generateRaceTrack, simulateRace, type RaceInput, type RaceObstacle, type RaceResult, type RaceStats } from "./raceSim.ts";
+
+interface RaceFixture {
+  tracks: { seed: number; obstacles: RaceObstacle[] }[];
+  replays: { seed: number; stats: RaceStats; inputs: RaceInput[]; result: RaceResult }[];
+}
+
+// Written by the server test: cd server && go test ./internal/game -run TestRaceFixture -update
+const fixture: RaceFixture = JSON.parse(
+  readFileSync(new URL("../../shared/race-fixture.json", import.meta.url), "utf8"),
+);
+
+test("tracks match the server's obstacle sequence", () => {
+  assert.ok(fixture.tracks.length > 0);
+  for (const { seed, obstacles } of fixture.tracks) {
+    assert.deepEqual(generateRaceTrack(seed).slice(0, obstacles.length), obstacles, `seed ${seed}`);
+  }
+});
+
+test("replays score the same as the server", () => {
+  assert.ok(fixture.replays.length > 0);
+  for (const { seed, stats, inputs, result } of fixture.replays) {
+    assert.deepEqual(simulateRace(seed, stats, inputs), result, `seed ${seed}`);
+  }
+});
@@ -0,0 +1,264 @@
+// Slime race simulation — a port of server/internal/game/race_sim.go.
+//
+// The server scores a race by replaying the recorded input log against the track it
+// generates from the same seed, so this has to agree with it tick for tick. Any change
+// to the track, the physics or the scoring goes into both files;
+// shared/race-fixture.json pins them together (see raceSim.test.ts).
+
+export const RACE_TICK_RATE = 60;
+export const RACE_MAX_TICKS = RACE_TICK_RATE * 180;
+export const RACE_GRAVITY = 0.65;
+export const RACE_DOUBLE_JUMP_FORCE = 10;
+export const RACE_ACCEL_PER_TICK = 0.001; // +0.06 speed per second
+export const RACE_INVINCIBLE_TICKS = 90;
+export const RACE_SLIME_HALF_WIDTH = 12;
+export const RACE_OBSTACLE_HEIGHT = 30;
+export const RACE_HIT_PENALTY = 80;
+export const RACE_CLEAR_POINTS = 10;
+export const RACE_DISTANCE_PER_PT = 50;
+const RACE_CRIT_STREAM = 0x5eed;
+// Fastest possible speed, doubled; the server folds the same constant expression
+const RACE_TRACK_MAX_SPEED = 32.46;
+
+const GROUND_KINDS = ["rock", "cactus", "fire", "spike"] as const;
+const AERIAL_KINDS = ["saw", "laser"] as const;
+
+export type RaceObstacleKind = (typeof GROUND_KINDS)[number] | (typeof AERIAL_KINDS)[number];
+export type RaceAction = "jump" | "duck" | "release";
+
+export interface RaceInput {
+  tick: number;
+  action: RaceAction;
+}
+
+/** Slime-derived race parameters, as returned by /api/race/start */
+export interface RaceStats {
+  speed: number;
+  jump_force: number;
+  hp: number;
+  crit_chance: number;
+}
+
+export interface RaceObstacle {
+  x: number;
+  width: number;
+  /** true = aerial (duck to avoid), false = ground (jump to avoid) */
+  aerial: boolean;
+  kind: RaceObstacleKind;
+}
+
+export interface RaceResult {
+  score: number;
+  distance: number;
+  cleared: number;
+  max_combo: number;
+  crits: number;
+  hits: number;
+  ticks: number;
+  timed_out: boolean;
+}
+
+/** What happened to an obstacle during a step, for the renderer */
+export type RaceEvent =
+  | { type: "clear"; index: number; gain: number; crit: boolean }
+  | { type: "hit"; index: number; penalty: number; damaged: boolean };
+
+/** mulberry32, seeded the way the server's raceRand is */
+export class RaceRand {
+  private state: number;
+
+  constructor(seed: number, stream = 0) {
+    // Seeds are non-negative and below 2^53, so both halves are exact
+    const lo = seed >>> 0;
+    const hi = Math.floor(seed / 0x100000000) >>> 0;
+    this.state = (lo ^ hi ^ stream) >>> 0;
+  }
+
+  private next(): number {
+    this.state = (this.state + 0x6d2b79f5) >>> 0;
+    let t = this.state;
+    t = Math.imul(t ^ (t >>> 15), t | 1);
+    t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+    return (t ^ (t >>> 14)) >>> 0;
+  }
+
+  /** A value in [0, n) */
+  intn(n: number): number {
+    return this.next() % n;
+  }
+
+  /** A value in [0, 1) */
+  float64(): number {
+    return this.next() / 0x100000000;
+  }
+}
+
+/** Lays out obstacles from the seed exactly like the server's generateRaceTrack */
+export function generateRaceTrack(seed: number): RaceObstacle[] {
+  const rng = new RaceRand(seed);
+  const trackEnd = RACE_TRACK_MAX_SPEED * RACE_MAX_TICKS;
+
+  const track: RaceObstacle[] = [];
+  let x = 600;
+  while (x < trackEnd) {
+    const width = 20 + rng.intn(21);
+    const aerial = rng.float64() < 0.3;
+    const kinds = aerial ? AERIAL_KINDS : GROUND_KINDS;
+    track.push({ x, width, aerial, kind: kinds[rng.intn(kinds.length)] });
+    x += 180 + rng.intn(220);
+  }
+  return track;
+}
+
+/** One race, advanced a tick at a time as the player plays it */
+export class RaceSim {
+  readonly track: RaceObstacle[];
+  readonly stats: RaceStats;
+  tick = 0;
+  distance = 0;
+  y = 0;
+  vy = 0;
+  jumpCount = 0;
+  ducking = false;
+  hp: number;
+  invincibleUntil = -1;
+  combo = 0;
+  maxCombo = 0;
+  points = 0;
+  cleared = 0;
+  crits = 0;
+  hits = 0;
+  /** First obstacle not yet passed */
+  next = 0;
+  private hitObstacle = new Set<number>();
+  private critRng: RaceRand;
+
+  constructor(seed: number, stats: RaceStats) {
+    this.track = generateRaceTrack(seed);
+    this.stats = stats;
+    this.hp = stats.hp;
+    this.critRng = new RaceRand(seed, RACE_CRIT_STREAM);
+  }
+
+  get over(): boolean {
+    return this.hp <= 0 || this.tick >= RACE_MAX_TICKS;
+  }
+
+  /** Current running speed in track units per tick */
+  get speed(): number {
+    return this.stats.speed + RACE_ACCEL_PER_TICK * this.tick;
+  }
+
+  get score(): number {
+    return this.points + Math.trunc(this.distance / RACE_DISTANCE_PER_PT);
+  }
+
+  isHit(index: number): boolean {
+    return this.hitObstacle.has(index);
+  }
+
+  /** Applies this tick's actions and advances one tick */
+  step(actions: RaceAction[]): RaceEvent[] {
+    const events: RaceEvent[] = [];
+    const { stats, track } = this;
+
+    // 1. Apply inputs for this tick
+    for (const action of actions) {
+      if (action === "jump") {
+        if (this.jumpCount === 0) {
+          this.vy = -stats.jump_force;
+          this.jumpCount = 1;
+          this.ducking = false;
+        } else if (this.jumpCount === 1) {
+          this.vy = -RACE_DOUBLE_JUMP_FORCE;
+          this.jumpCount = 2;
+        }
+      } else if (action === "duck") {
+        if (this.y === 0) this.ducking = true;
+      } else {
+        this.ducking = false;
+      }
+    }
+
+    // 2. Vertical physics
+    if (this.y > 0 || this.vy < 0) {
+      this.vy += RACE_GRAVITY;
+      this.y -= this.vy;
+      if (this.y <= 0) {
+        this.y = 0;
+        this.vy = 0;
+        this.jumpCount = 0;
+      }
+    }
+
+    // 3. Advance along the track
+    this.distance += this.speed;
+
+    // 4. Collisions and clears
+    for (let i = this.next; i < track.length; i++) {
+      const ob = track[i];
+      if (ob.x > this.distance + RACE_SLIME_HALF_WIDTH) break;
+      if (ob.x + ob.width < this.distance - RACE_SLIME_HALF_WIDTH) {
+        // Passed this obstacle
+        if (!this.hitObstacle.has(i)) {
+          this.combo++;
+          if (this.combo > this.maxCombo) this.maxCombo = this.combo;
+          let gain = RACE_CLEAR_POINTS * (1 + Math.floor(this.combo / 10));
+          const crit = stats.crit_chance > 0 && this.critRng.float64() < stats.crit_chance;
+          if (crit) {
+            gain *= 2;
+            this.crits++;
+          }
+          this.points += gain;
+          this.cleared++;
+          events.push({ type: "clear", index: i, gain, crit });
+        }
+        this.next = i + 1;
+        continue;
+      }
+      if (this.hitObstacle.has(i)) continue;
+      const collides = ob.aerial ? !(this.ducking && this.y === 0) : this.y < RACE_OBSTACLE_HEIGHT;
+      if (!collides) continue;
+      this.hitObstacle.add(i);
+      const damaged = this.tick >= this.invincibleUntil;
+      let penalty = 0;
+      if (damaged) {
+        this.hp--;
+        this.hits++;
+        this.combo = 0;
+        penalty = Math.min(RACE_HIT_PENALTY, this.points);
+        this.points -= penalty;
+        this.invincibleUntil = this.tick + RACE_INVINCIBLE_TICKS;
+      }
+      events.push({ type: "hit", index: i, penalty, damaged });
+    }
+
+    this.tick++;
+    return events;
+  }
+
+  result(): RaceResult {
+    return {
+      score: this.score,
+      distance: Math.trunc(this.distance),
+      cleared: this.cleared,
+      max_combo: this.maxCombo,
+      crits: this.crits,
+      hits: this.hits,
+      ticks: this.tick,
+      timed_out: this.hp > 0,
+    };
+  }
+}
+
+/** Replays a whole input log, as FinishRace does on the server */
+export function simulateRace(seed: number, stats: RaceStats, inputs: RaceInput[]): RaceResult {
+  const sim = new RaceSim(seed, stats);
+  let i = 0;
+  while (!sim.over) {
+    const actions: RaceAction[] = [];
+    while (i < inputs.length && inputs[i].tick === sim.tick) actions.push(inputs[i++].action);
+    sim.step(actions);
+  }
+  return sim.result();
+}
@@ -10,6 +10,8 @@ interface User {
   level: number;
   email?: string;
   profile_image_url?: string;
+  email_verified?: boolean;
+  deletion_scheduled_at?: string | null;
 }
 
 interface AuthState {
@@ -1,5 +1,5 @@
 import { create } from "zustand";
-import { authApi, api, ApiError } from "@/lib/api/client";
+import { authApi, api, ApiError, newIdempotencyKey } from "@/lib/api/client";
 import { useAuthStore } from "./authStore";
 import { toastReward, toastSuccess, toastLevelUp, toastError } from "@/components/ui/Toast";
 
@@ -128,6 +128,8 @@ export interface ShopItem {
   cost: { gold: number; gems: number };
   icon: string;
   description: string;
+  quantity?: number;
+  egg_type?: string;
 }
 
 interface ShopBuyResult {
@@ -335,7 +337,7 @@ interface GameState {
   mergeSlotA: string | null;
   mergeSlotB: string | null;
   showMergeResult: MergeResult | null;
-  activePanel: "home" | "inventory" | "codex" | "merge" | "explore" | "discovery" | "shop" | "gacha" | "achievements" | "leaderboard" | "slimes" | "collection";
+  activePanel: "home" | "inventory" | "codex" | "merge" | "explore" | "discovery" | "shop" | "gacha" | "achievements" | "leaderboard" | "friends" | "guild" | "slimes" | "collection";
   cooldowns: CooldownMap;
   reactionMessage: { slimeId: string; text: string } | null;
   levelUpInfo: LevelUpInfo | null;
@@ -829,7 +831,7 @@ export const useGameStore = create<GameState>((set, get) => ({
       const res = await authApi<{ gold_reward: number; gems_reward: number; exp_gain: number; element_bonus: boolean; material_drops?: { material_id: number; quantity: number; name: string; icon: string }[] }>(
         `/api/explorations/${explorationId}/claim`,
         token,
-        { method: "POST" }
+        { method: "POST", idempotencyKey: newIdempotencyKey() }
       );
       await get().fetchExplorations(token);
       await get().fetchSlimes(token);
@@ -859,6 +861,7 @@ export const useGameStore = create<GameState>((set, get) => ({
     const res = await authApi<ShopBuyResult>("/api/shop/buy", token, {
       method: "POST",
       body: { item_id: itemId, slime_id: slimeId, quantity: quantity || 0 },
+      idempotencyKey: newIdempotencyKey(),
     });
     // Refresh slimes after purchase
     await get().fetchSlimes(token);
@@ -911,7 +914,7 @@ export const useGameStore = create<GameState>((set, get) => ({
 
   claimMission: async (token, missionId) => {
     try {
-      await authApi("/api/missions/" + missionId + "/claim", token, { method: "POST" });
+      await authApi("/api/missions/" + missionId + "/claim", token, { method: "POST", idempotencyKey: newIdempotencyKey() });
       const mission = get().dailyMissions.find((m) => m.id === missionId);
       await get().fetchDailyMissions(token);
       useAuthStore.getState().fetchUser();
@@ -937,7 +940,7 @@ export const useGameStore = create<GameState>((set, get) => ({
 
   claimAttendance: async (token) => {
     try {
-      await authApi("/api/attendance/claim", token, { method: "POST" });
+      await authApi("/api/attendance/claim", token, { method: "POST", idempotencyKey: newIdempotencyKey() });
       await get().fetchAttendance(token);
       useAuthStore.getState().fetchUser();
       toastSuccess("출석 체크 완료!", "📅");
@@ -1069,7 +1072,7 @@ export const useGameStore = create<GameState>((set, get) => ({
 
   claimMail: async (token, mailId) => {
     try {
-      const res = await authApi<{ reward_gold: number; reward_gems: number }>("/api/mailbox/" + mailId + "/claim", token, { method: "POST" });
+      const res = await authApi<{ reward_gold: number; reward_gems: number }>("/api/mailbox/" + mailId + "/claim", token, { method: "POST", idempotencyKey: newIdempotencyKey() });
       set((s) => ({
         mails: s.mails.map((m) => m.id === mailId ? { ...m, claimed: true, read: true } : m),
       }));
@@ -1250,7 +1253,7 @@ export const useGameStore = create<GameState>((set, get) => ({
 
   collectIdleReward: async (token) => {
     try {
-      const res = await authApi<{ collected: boolean; total_gold: number }>("/api/idle/collect", token, { method: "POST" });
+      const res = await authApi<{ collected: boolean; total_gold: number }>("/api/idle/collect", token, { method: "POST", idempotencyKey: newIdempotencyKey() });
       if (res.collected) {
         toastReward(`${res.total_gold.toLocaleString()}G 수령!`, "\uD83C\uDF19");
         useAuthStore.getState().fetchUser();
@@ -1304,7 +1307,8 @@ export const useGameStore = create<GameState>((set, get) => ({
       return true;
     } catch (err) {
       if (err instanceof ApiError) {
-        toastError((err.data.error as string) || "선물 실패");
+        const code = err.data.error as string;
+        toastError(code === "not_friends" ? "친구에게만 선물할 수 있어요" : code || "선물 실패");
       }
       return false;
     }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+
+	"github.com/slimetopia/server/internal/admin"
+	"github.com/slimetopia/server/internal/auth"
+	"github.com/slimetopia/server/internal/game"
+	"github.com/slimetopia/server/internal/jobs"
+	"github.com/slimetopia/server/internal/mail"
+	"github.com/slimetopia/server/internal/middleware"
+	"github.com/slimetopia/server/internal/repository"
+	"github.com/slimetopia/server/pkg/config"
+)
+
+// jobLockKey is the advisory lock replicas contend for to run background jobs.
+const jobLockKey = 0x736c696d656a6f62 // "slimejob"
+
+// newServer wires repositories, handlers and routes into a Fiber app. The job
+// scheduler is returned unstarted.
+func newServer(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, rdb *redis.Client, mailer mail.Mailer) (*fiber.App, *jobs.Scheduler) {
+	// Repositories
+	userRepo := repository.NewUserRepository(pool)
+	slimeRepo := repository.NewSlimeRepository(pool)
+	explorationRepo := repository.NewExplorationRepository(pool)
+	missionRepo := repository.NewMissionRepository(pool)
+	villageRepo := repository.NewVillageRepository(pool)
+	gameDataRepo := repository.NewGameDataRepository(pool)
+	ledgerRepo := repository.NewLedgerRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+	accountRepo := repository.NewAccountRepository(pool)
+	friendRepo := repository.NewFriendRepository(pool)
+	guildRepo := repository.NewGuildRepository(pool)
+	communityRepo := repository.NewCommunityRepository(pool)
+	achievementRepo := repository.NewAchievementRepository(pool)
+	accessoryRepo := repository.NewAccessoryRepository(pool)
+	arenaRepo := repository.NewArenaRepository(pool)
+	breedingRepo := repository.NewBreedingRepository(pool)
+	gameLogRepo := repository.NewGameLogRepository(pool)
+	mailboxRepo := repository.NewMailboxRepository(pool)
+	shortsRepo := repository.NewShortsRepository(pool)
+	botRepo := repository.NewBotRepository(pool)
+	collectionRepo := repository.NewCollectionRepository(pool)
+	trainingRepo := repository.NewTrainingRepository(pool)
+	raceRepo := repository.NewRaceRepository(pool)
+	leaderboardRepo := repository.NewLeaderboardRepository(pool)
+	supportRepo := repository.NewSupportRepository(pool)
+	idleRepo := repository.NewIdleRepository(pool)
+	giftRepo := repository.NewGiftRepository(pool)
+	gachaRepo := repository.NewGachaRepository(pool)
+	guildBossRepo := repository.NewGuildBossRepository(pool)
+	worldBossRepo := repository.NewWorldBossRepository(pool)
+
+	// Auth
+	jwtManager := auth.NewJWTManager(cfg.JWTSecret)
+	denylist := auth.NewDenylist(rdb, userRepo)
+
+	authHandler := auth.NewHandler(userRepo, slimeRepo, sessionRepo, jwtManager, rdb, denylist, mailer)
+
+	// Register OAuth providers (only if credentials are configured)
+	if cfg.GoogleClientID != "" {
+		authHandler.RegisterProvider(auth.NewGoogleProvider(
+			cfg.GoogleClientID, cfg.GoogleSecret,
+			cfg.OAuthRedirectBase+"/api/auth/callback/google",
+		))
+	}
+	if cfg.KakaoClientID != "" {
+		authHandler.RegisterProvider(auth.NewKakaoProvider(
+			cfg.KakaoClientID, cfg.KakaoSecret,
+			cfg.OAuthRedirectBase+"/api/auth/callback/kakao",
+		))
+	}
+
+	for _, oc := range cfg.OIDCProviders {
+		provider, err := auth.NewOIDCProvider(ctx, auth.OIDCConfig{
+			Name:         oc.Name,
+			Issuer:       oc.Issuer,
+			ClientID:     oc.ClientID,
+			ClientSecret: oc.ClientSecret,
+			RedirectURL:  cfg.OAuthRedirectBase + "/api/auth/callback/" + oc.Name,
+			Scopes:       oc.Scopes,
+			ResponseMode: oc.ResponseMode,
+		})
+		if err != nil {
+			// A provider outage at boot shouldn't take the API down with it
+			log.Error().Err(err).Str("provider", oc.Name).Msg("OIDC provider unavailable, skipping")
+			continue
+		}
+		authHandler.RegisterProvider(provider)
+		log.Info().Str("provider", oc.Name).Msg("OIDC provider registered")
+	}
+
+	userHandler := auth.NewUserHandler(userRepo, sessionRepo, accountRepo, jwtManager, denylist)
+	gameHandler := game.NewHandler(game.Stores{
+		Slimes:       slimeRepo,
+		Users:        userRepo,
+		Explorations: explorationRepo,
+		Missions:     missionRepo,
+		Villages:     villageRepo,
+		GameData:     gameDataRepo,
+		Friends:      friendRepo,
+		Guilds:       guildRepo,
+		Community:    communityRepo,
+		Achievements: achievementRepo,
+		Accessories:  accessoryRepo,
+		Arena:        arenaRepo,
+		Breeding:     breedingRepo,
+		GameLogs:     gameLogRepo,
+		Mailbox:      mailboxRepo,
+		Shorts:       shortsRepo,
+		Bots:         botRepo,
+		Collections:  collectionRepo,
+		Training:     trainingRepo,
+		Races:        raceRepo,
+		Leaderboards: leaderboardRepo,
+		Support:      supportRepo,
+		Idle:         idleRepo,
+		Gifts:        giftRepo,
+		Gacha:        gachaRepo,
+		GuildBosses:  guildBossRepo,
+		WorldBosses:  worldBossRepo,
+	}, rdb, cfg.JWTSecret)
+	adminHandler := admin.NewAdminHandler(pool, cfg.JWTSecret, gameDataRepo, slimeRepo, denylist)
+
+	// Fiber app
+	app := fiber.New(fiber.Config{
+		AppName:      "SlimeTopia API",
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		BodyLimit:    50 * 1024 * 1024, // 50MB for video uploads
+	})
+
+	// Middleware
+	app.Use(logger.New())
+	corsOrigins := "http://localhost:3000, http://localhost:3001, https://localhost, capacitor://localhost"
+	if cfg.AllowedOrigins != "" {
+		corsOrigins = corsOrigins + ", " + cfg.AllowedOrigins
+	}
+	app.Use(cors.New(cors.Config{
+		AllowOrigins:     corsOrigins,
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, Idempotency-Key, X-Device-Label",
+		AllowMethods:     "GET, POST, PATCH, DELETE, OPTIONS",
+		AllowCredentials: true,
+	}))
+
+	// Admin panel (server-rendered HTML)
+	admin.RegisterAdminRoutes(app, adminHandler, gameHandler)
+
+	// Static file serving for uploads (video, thumbnails)
+	app.Static("/uploads", "./uploads", fiber.Static{
+		Compress: true,
+		MaxAge:   86400,
+	})
+
+	// Health check (public)
+	app.Get("/api/health", func(c *fiber.Ctx) error {
+		if err := pool.Ping(ctx); err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "error",
+				"error":  "database unavailable",
+			})
+		}
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "error",
+				"error":  "redis unavailable",
+			})
+		}
+		return c.JSON(fiber.Map{
+			"status":  "ok",
+			"service": "slimetopia-api",
+			"version": "0.1.0",
+		})
+	})
+
+	// Public routes
+	api := app.Group("/api")
+	auth.RegisterRoutes(api, authHandler)
+	game.RegisterPublicRoutes(api, gameHandler)
+
+	// Protected routes
+	protected := api.Use(middleware.AuthRequired(jwtManager, denylist))
+	auth.RegisterUserRoutes(protected, userHandler)
+	auth.RegisterLinkRoutes(protected, authHandler)
+	game.RegisterRoutes(protected, gameHandler, middleware.Idempotency(rdb, 24*time.Hour))
+
+	// Background jobs run on whichever replica holds leadership
+	var leader jobs.Leader = jobs.NewPGLock(pool, jobLockKey)
+	if cfg.JobLeader == "redis" {
+		leader = jobs.NewRedisLease(rdb, "jobs:leader", jobs.InstanceID(), 30*time.Second)
+	}
+	scheduler := jobs.New(leader, repository.NewJobRunRepository(pool))
+	scheduler.Add(jobs.Job{
+		Name:     "bot_activity",
+		Schedule: jobs.Every(5 * time.Minute),
+		Timeout:  time.Minute,
+		Run:      game.NewBotActivityManager(botRepo, userRepo, slimeRepo).Tick,
+	})
+	scheduler.Add(jobs.Job{
+		// Balances vs. currency_ledger
+		Name:     "ledger_reconcile",
+		Schedule: jobs.MustParseCron("0 * * * *"),
+		Timeout:  5 * time.Minute,
+		Run:      game.NewLedgerReconciler(ledgerRepo).Reconcile,
+	})
+	scheduler.Add(jobs.Job{
+		// Accounts whose deletion grace period has ended
+		Name:     "account_purge",
+		Schedule: jobs.MustParseCron("15 * * * *"),
+		Timeout:  10 * time.Minute,
+		Run:      auth.NewAccountPurger(accountRepo, denylist).PurgeDue,
+	})
+	scheduler.Add(jobs.Job{
+		// Settles defeated and expired bosses and keeps one up
+		Name:     "world_boss_rotation",
+		Schedule: jobs.Every(time.Minute),
+		Timeout:  time.Minute,
+		Run:      gameHandler.RotateWorldBoss,
+	})
+	scheduler.Add(jobs.Job{
+		// Pays out guild bosses whose settlement didn't finish when they fell
+		Name:     "guild_boss_settlement",
+		Schedule: jobs.Every(time.Minute),
+		Timeout:  time.Minute,
+		Run:      gameHandler.SettleGuildBosses,
+	})
+	scheduler.Add(jobs.Job{
+		// Mails rewards for ended arena seasons and starts the next
+		Name:     "arena_season_rotation",
+		Schedule: jobs.Every(5 * time.Minute),
+		Timeout:  5 * time.Minute,
+		Run:      gameHandler.RotateArenaSeason,
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "season_rollover",
+		Schedule: jobs.MustParseCron("5 * * * *"),
+		Timeout:  time.Minute,
+		Run:      gameHandler.RolloverSeasons,
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "mail_cleanup",
+		Schedule: jobs.MustParseCron("30 4 * * *"),
+		Timeout:  10 * time.Minute,
+		Run:      gameHandler.CleanExpiredMail,
+	})
+
+	return app, scheduler
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/slimetopia/server/internal/auth"
+	"github.com/slimetopia/server/internal/mail"
+	"github.com/slimetopia/server/internal/repository"
+	"github.com/slimetopia/server/internal/testenv"
+	"github.com/slimetopia/server/pkg/config"
+)
+
+type testServer struct {
+	t    *testing.T
+	app  *fiber.App
+	pool *pgxpool.Pool
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	pool := testenv.Postgres(t)
+	rdb := testenv.Redis(t)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	app, _ := newServer(context.Background(), cfg, pool, rdb, mail.NewLogMailer("", "test@slimetopia.local"))
+	return &testServer{t: t, app: app, pool: pool}
+}
+
+// do sends a JSON request and decodes the JSON response. headers alternate name, value.
+func (s *testServer) do(method, path, token string, body interface{}, headers ...string) (int, map[string]interface{}) {
+	s.t.Helper()
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("marshal: %v", err)
+		}
+		r = bytes.NewReader(b)
+	}
+	req := httptest.NewRequest(method, path, r)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := s.app.Test(req, -1)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	var out map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		s.t.Fatalf("%s %s: decode response: %v", method, path, err)
+	}
+	return resp.StatusCode, out
+}
+
+// login creates a guest account and returns its id and access token.
+func (s *testServer) login() (userID, token string) {
+	s.t.Helper()
+	status, body := s.do(http.MethodPost, "/api/auth/guest-login", "", nil)
+	if status != http.StatusOK {
+		s.t.Fatalf("guest-login: %d %v", status, body)
+	}
+	userID = body["user"].(map[string]interface{})["id"].(string)
+	token = body["tokens"].(map[string]interface{})["access_token"].(string)
+	return userID, token
+}
+
+func (s *testServer) slimes(token string) []map[string]interface{} {
+	s.t.Helper()
+	status, body := s.do(http.MethodGet, "/api/slimes", token, nil)
+	if status != http.StatusOK {
+		s.t.Fatalf("list slimes: %d %v", status, body)
+	}
+	var out []map[string]interface{}
+	for _, v := range body["slimes"].([]interface{}) {
+		out = append(out, v.(map[string]interface{}))
+	}
+	return out
+}
+
+func TestGuestLoginGrantsStarterPack(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.login()
+
+	if n := len(s.slimes(token)); n != 3 {
+		t.Errorf("got %d starter slimes, want 3", n)
+	}
+	var gold int64
+	var food int
+	s.pool.QueryRow(context.Background(), `SELECT gold FROM users WHERE id = $1`, userID).Scan(&gold)
+	s.pool.QueryRow(context.Background(),
+		`SELECT COALESCE(SUM(quantity), 0) FROM food_inventory WHERE user_id = $1`, userID).Scan(&food)
+	if gold < 3000 {
+		t.Errorf("gold = %d, want at least the 3000 starter gold", gold)
+	}
+	if food != 10 {
+		t.Errorf("food = %d, want 10", food)
+	}
+
+	if status, _ := s.do(http.MethodGet, "/api/slimes", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("without a token: %d, want 401", status)
+	}
+}
+
+func TestHatchEgg(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.login()
+
+	status, body := s.do(http.MethodPost, "/api/shop/buy", token, map[string]interface{}{"item_id": 1})
+	if status != http.StatusOK {
+		t.Fatalf("buy egg: %d %v", status, body)
+	}
+	if body["type"] != "egg" {
+		t.Errorf("type = %v, want egg", body["type"])
+	}
+	if n := len(s.slimes(token)); n != 4 {
+		t.Errorf("got %d slimes after hatching, want 4", n)
+	}
+	if _, ok := body["pity"].(map[string]interface{}); !ok {
+		t.Error("response has no pity status")
+	}
+}
+
+func TestMergeUpgrade(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.login()
+
+	// A second common water slime makes a same-species upgrade possible
+	if _, err := repository.NewSlimeRepository(s.pool).Create(context.Background(), userID, 1, "water", "gentle"); err != nil {
+		t.Fatalf("create slime: %v", err)
+	}
+	var ids []string
+	for _, sl := range s.slimes(token) {
+		if sl["species_id"].(float64) == 1 {
+			ids = append(ids, sl["id"].(string))
+		}
+	}
+	if len(ids) != 2 {
+		t.Fatalf("got %d species 1 slimes, want 2", len(ids))
+	}
+
+	status, body := s.do(http.MethodPost, "/api/slimes/merge", token, map[string]interface{}{
+		"slime_id_a": ids[0], "slime_id_b": ids[1],
+	})
+	if status != http.StatusOK {
+		t.Fatalf("merge: %d %v", status, body)
+	}
+	if body["merge_type"] != "upgrade" {
+		t.Errorf("merge_type = %v, want upgrade", body["merge_type"])
+	}
+	if n := len(s.slimes(token)); n != 3 {
+		t.Errorf("got %d slimes after merging, want 3", n)
+	}
+
+	status, _ = s.do(http.MethodPost, "/api/slimes/merge", token, map[string]interface{}{
+		"slime_id_a": ids[0], "slime_id_b": ids[1],
+	})
+	if status != http.StatusNotFound {
+		t.Errorf("merging consumed slimes: %d, want 404", status)
+	}
+}
+
+func TestExploreAndClaim(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.login()
+	slimeID := s.slimes(token)[0]["id"].(string)
+
+	status, body := s.do(http.MethodPost, "/api/explorations/start", token, map[string]interface{}{
+		"destination_id": 1, "slime_ids": []string{slimeID},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("start exploration: %d %v", status, body)
+	}
+	expID := body["exploration"].(map[string]interface{})["id"].(string)
+	claimPath := "/api/explorations/" + expID + "/claim"
+
+	if status, _ := s.do(http.MethodPost, claimPath, token, nil); status != http.StatusBadRequest {
+		t.Errorf("claim before the end: %d, want 400", status)
+	}
+
+	// Skip to the end of the exploration
+	if _, err := s.pool.Exec(context.Background(),
+		`UPDATE explorations SET ends_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, expID); err != nil {
+		t.Fatalf("end exploration: %v", err)
+	}
+	var before int64
+	s.pool.QueryRow(context.Background(), `SELECT gold FROM users WHERE id = $1`, userID).Scan(&before)
+
+	status, body = s.do(http.MethodPost, claimPath, token, nil, "Idempotency-Key", "claim-1")
+	if status != http.StatusOK {
+		t.Fatalf("claim: %d %v", status, body)
+	}
+	reward := int64(body["gold_reward"].(float64))
+	if reward <= 0 {
+		t.Errorf("gold_reward = %d, want > 0", reward)
+	}
+	var after int64
+	s.pool.QueryRow(context.Background(), `SELECT gold FROM users WHERE id = $1`, userID).Scan(&after)
+	if after != before+reward {
+		t.Errorf("gold = %d, want %d + %d", after, before, reward)
+	}
+
+	// A retry with the same key replays the first response instead of paying twice
+	status, replay := s.do(http.MethodPost, claimPath, token, nil, "Idempotency-Key", "claim-1")
+	if status != http.StatusOK || replay["gold_reward"] != body["gold_reward"] {
+		t.Errorf("retry: %d %v, want the first response replayed", status, replay)
+	}
+	if status, _ := s.do(http.MethodPost, claimPath, token, nil); status != http.StatusBadRequest {
+		t.Errorf("second claim: %d, want 400", status)
+	}
+}
+
+func TestWorldBossKillSettles(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, tokenA := s.login()
+	_, tokenB := s.login()
+
+	status, body := s.do(http.MethodGet, "/api/boss", tokenA, nil)
+	if status != http.StatusOK {
+		t.Fatalf("get boss: %d %v", status, body)
+	}
+	boss := body["boss"].(map[string]interface{})
+	bossID := int(boss["id"].(float64))
+	if boss["stage"].(float64) != 1 {
+		t.Fatalf("stage = %v, want 1", boss["stage"])
+	}
+
+	attack := func(token string) map[string]interface{} {
+		t.Helper()
+		party := []string{s.slimes(token)[0]["id"].(string)}
+		status, body := s.do(http.MethodPost, "/api/boss/attack", token, map[string]interface{}{"slime_ids": party})
+		if status != http.StatusOK {
+			t.Fatalf("attack: %d %v", status, body)
+		}
+		return body
+	}
+	attack(tokenB)
+	// Leave the boss one hit from death
+	if _, err := s.pool.Exec(ctx, `UPDATE world_boss SET current_hp = 1 WHERE id = $1`, bossID); err != nil {
+		t.Fatalf("wound boss: %v", err)
+	}
+	if res := attack(tokenA); res["defeated"] != true || res["next_stage"] != true {
+		t.Fatalf("killing blow: %v", res)
+	}
+
+	var bossStatus string
+	var rewards, mails int
+	s.pool.QueryRow(ctx, `SELECT status FROM world_boss WHERE id = $1`, bossID).Scan(&bossStatus)
+	s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM world_boss_rewards WHERE boss_id = $1`, bossID).Scan(&rewards)
+	s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM mailbox WHERE mail_type = 'reward' AND user_id IS NOT NULL`).Scan(&mails)
+	if bossStatus != "settled" || rewards != 2 || mails != 2 {
+		t.Errorf("status %q, %d rewards, %d mails; want settled, 2, 2", bossStatus, rewards, mails)
+	}
+
+	status, body = s.do(http.MethodGet, "/api/boss", tokenA, nil)
+	if status != http.StatusOK || body["boss"].(map[string]interface{})["stage"].(float64) != 2 {
+		t.Errorf("after the kill: %d %v, want the stage 2 boss", status, body)
+	}
+}
+
+func TestArenaBattle(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	attackerID, tokenA := s.login()
+	defenderID, tokenB := s.login()
+
+	defense := []string{s.slimes(tokenB)[0]["id"].(string)}
+	if status, body := s.do(http.MethodPost, "/api/arena/defense", tokenB, map[string]interface{}{"slime_ids": defense}); status != http.StatusOK {
+		t.Fatalf("set defense: %d %v", status, body)
+	}
+	// Someone else's slime can't defend
+	if status, _ := s.do(http.MethodPost, "/api/arena/defense", tokenA, map[string]interface{}{"slime_ids": defense}); status != http.StatusBadRequest {
+		t.Errorf("defending with another user's slime: %d, want 400", status)
+	}
+
+	party := []string{s.slimes(tokenA)[0]["id"].(string)}
+	attack := map[string]interface{}{"opponent_id": defenderID, "slime_ids": party}
+	if status, _ := s.do(http.MethodPost, "/api/arena/attack", tokenA, attack); status != http.StatusBadRequest {
+		t.Errorf("attacking before matchmaking: %d, want 400", status)
+	}
+
+	status, body := s.do(http.MethodGet, "/api/arena/opponents", tokenA, nil)
+	if status != http.StatusOK {
+		t.Fatalf("opponents: %d %v", status, body)
+	}
+	opponents := body["opponents"].([]interface{})
+	if len(opponents) != 1 || opponents[0].(map[string]interface{})["user_id"] != defenderID {
+		t.Fatalf("opponents = %v, want only the defender", opponents)
+	}
+
+	// A rejected attack gives back the offer and the day's attack
+	borrowed := map[string]interface{}{"opponent_id": defenderID, "slime_ids": defense}
+	if status, _ := s.do(http.MethodPost, "/api/arena/attack", tokenA, borrowed); status != http.StatusBadRequest {
+		t.Errorf("attacking with another user's slime: %d, want 400", status)
+	}
+
+	status, body = s.do(http.MethodPost, "/api/arena/attack", tokenA, attack)
+	if status != http.StatusOK {
+		t.Fatalf("attack: %d %v", status, body)
+	}
+	if remaining := body["remaining_attacks"].(float64); remaining != 9 {
+		t.Errorf("remaining_attacks = %v, want 9", remaining)
+	}
+	battleID := int64(body["battle_id"].(float64))
+	won := body["won"].(bool)
+	delta := int(body["rating_delta"].(float64))
+	if (won && delta <= 0) || (!won && delta >= 0) {
+		t.Errorf("won = %v with rating delta %d", won, delta)
+	}
+
+	var ratings, battles int
+	s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM arena_ratings WHERE user_id IN ($1, $2) AND wins + losses = 1`, attackerID, defenderID).Scan(&ratings)
+	s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM arena_battles WHERE attacker_id = $1`, attackerID).Scan(&battles)
+	if ratings != 2 || battles != 1 {
+		t.Errorf("%d ratings and %d battles recorded, want 2 and 1", ratings, battles)
+	}
+
+	// The defender can watch the replay; the offer is used up
+	status, body = s.do(http.MethodGet, fmt.Sprintf("/api/arena/battles/%d", battleID), tokenB, nil)
+	if status != http.StatusOK || body["replay"].(map[string]interface{})["events"] == nil {
+		t.Errorf("defender replay: %d %v", status, body)
+	}
+	if status, _ := s.do(http.MethodPost, "/api/arena/attack", tokenA, attack); status != http.StatusBadRequest {
+		t.Errorf("second attack on one offer: %d, want 400", status)
+	}
+}
+
+func TestRaceTokenSingleUse(t *testing.T) {
+	s := newTestServer(t)
+	_, tokenA := s.login()
+	_, tokenB := s.login()
+
+	slimeID := s.slimes(tokenA)[0]["id"].(string)
+	status, body := s.do(http.MethodPost, "/api/race/start", tokenA, map[string]interface{}{"slime_id": slimeID})
+	if status != http.StatusOK {
+		t.Fatalf("start race: %d %v", status, body)
+	}
+	finish := map[string]interface{}{"token": body["token"], "inputs": []interface{}{}}
+
+	// Another player holding the token can't use it, nor burn it
+	if status, _ := s.do(http.MethodPost, "/api/race/finish", tokenB, finish); status != http.StatusForbidden {
+		t.Errorf("finish with another user's token: %d, want 403", status)
+	}
+	if status, body := s.do(http.MethodPost, "/api/race/finish", tokenA, finish); status != http.StatusOK {
+		t.Errorf("owner finish: %d %v", status, body)
+	}
+	if status, _ := s.do(http.MethodPost, "/api/race/finish", tokenA, finish); status != http.StatusConflict {
+		t.Errorf("second finish: %d, want 409", status)
+	}
+}
+
+func TestRefreshRejectsAccessToken(t *testing.T) {
+	s := newTestServer(t)
+	status, body := s.do(http.MethodPost, "/api/auth/guest-login", "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("guest-login: %d %v", status, body)
+	}
+	tokens := body["tokens"].(map[string]interface{})
+	access, refresh := tokens["access_token"].(string), tokens["refresh_token"].(string)
+
+	// An access token at /refresh is just invalid; it must not count as reuse and end the session
+	if status, _ := s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": access}); status != http.StatusUnauthorized {
+		t.Errorf("refresh with the access token: %d, want 401", status)
+	}
+	if status, _ := s.do(http.MethodGet, "/api/slimes", refresh, nil); status != http.StatusUnauthorized {
+		t.Errorf("API call with the refresh token: %d, want 401", status)
+	}
+	if status, body := s.do(http.MethodPost, "/api/auth/refresh", "", map[string]string{"refresh_token": refresh}); status != http.StatusOK {
+		t.Errorf("refresh after the rejected attempt: %d %v", status, body)
+	}
+}
+
+func TestRefreshLegacyTokenSingleUse(t *testing.T) {
+	s := newTestServer(t)
+	userID, _ := s.login()
+
+	// Refresh tokens from before sessions were tracked carry no sid, typ or jti
+	now := time.Now()
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "slimetopia",
+		},
+	}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refresh := map[string]string{"refresh_token": legacy}
+	if status, body := s.do(http.MethodPost, "/api/auth/refresh", "", refresh); status != http.StatusOK {
+		t.Fatalf("first legacy refresh: %d %v", status, body)
+	}
+	if status, _ := s.do(http.MethodPost, "/api/auth/refresh", "", refresh); status != http.StatusUnauthorized {
+		t.Errorf("legacy token replayed: %d, want 401", status)
+	}
+}
+
+func TestGuildBossAttackLimit(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.login()
+	s.pool.Exec(context.Background(), `UPDATE users SET gold = gold + 10000 WHERE id = $1`, userID)
+	if status, body := s.do(http.MethodPost, "/api/guild", token, map[string]string{"name": "Slimers"}); status != http.StatusOK {
+		t.Fatalf("create guild: %d %v", status, body)
+	}
+	_, other := s.login()
+
+	// A rejected attack doesn't use up one of the day's attacks
+	borrowed := map[string]interface{}{"slime_ids": []string{s.slimes(other)[0]["id"].(string)}}
+	if status, _ := s.do(http.MethodPost, "/api/guild/boss/attack", token, borrowed); status != http.StatusBadRequest {
+		t.Errorf("attacking with another user's slime: %d, want 400", status)
+	}
+
+	party := map[string]interface{}{"slime_ids": []string{s.slimes(token)[0]["id"].(string)}}
+	for i := 0; i < 3; i++ {
+		status, body := s.do(http.MethodPost, "/api/guild/boss/attack", token, party)
+		if status != http.StatusOK {
+			t.Fatalf("attack %d: %d %v", i+1, status, body)
+		}
+		if remaining := body["remaining_attacks"].(float64); remaining != float64(2-i) {
+			t.Errorf("attack %d: remaining_attacks = %v, want %d", i+1, remaining, 2-i)
+		}
+	}
+	if status, _ := s.do(http.MethodPost, "/api/guild/boss/attack", token, party); status != http.StatusTooManyRequests {
+		t.Errorf("fourth attack: %d, want 429", status)
+	}
+}
+
+func TestGuildBossKillSettlesOnce(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	userID, token := s.login()
+	s.pool.Exec(ctx, `UPDATE users SET gold = gold + 10000 WHERE id = $1`, userID)
+	if status, body := s.do(http.MethodPost, "/api/guild", token, map[string]string{"name": "Slimers"}); status != http.StatusOK {
+		t.Fatalf("create guild: %d %v", status, body)
+	}
+	status, body := s.do(http.MethodGet, "/api/guild/boss", token, nil)
+	if status != http.StatusOK {
+		t.Fatalf("get guild boss: %d %v", status, body)
+	}
+	bossID := int(body["boss"].(map[string]interface{})["id"].(float64))
+
+	// A killing blow whose request died before settlement
+	if _, err := s.pool.Exec(ctx, `UPDATE guild_boss SET current_hp = 0, defeated_at = NOW() WHERE id = $1`, bossID); err != nil {
+		t.Fatalf("kill boss: %v", err)
+	}
+	if _, err := s.pool.Exec(ctx, `INSERT INTO guild_boss_attacks (boss_id, user_id, damage) VALUES ($1, $2, 100)`, bossID, userID); err != nil {
+		t.Fatalf("record attack: %v", err)
+	}
+
+	// The next look at the boss settles it and brings up stage 2
+	status, body = s.do(http.MethodGet, "/api/guild/boss", token, nil)
+	if status != http.StatusOK || body["boss"].(map[string]interface{})["stage"].(float64) != 2 {
+		t.Fatalf("after the kill: %d %v, want the stage 2 boss", status, body)
+	}
+	// and looking again pays nothing twice
+	s.do(http.MethodGet, "/api/guild/boss", token, nil)
+
+	var rewards, bosses int
+	s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM guild_boss_rewards WHERE boss_id = $1`, bossID).Scan(&rewards)
+	s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM guild_boss WHERE id > $1`, bossID).Scan(&bosses)
+	if rewards != 1 || bosses != 1 {
+		t.Errorf("%d rewards, %d new bosses; want 1, 1", rewards, bosses)
+	}
+}
+
+func TestBreedRejectsMalformedIDs(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.login()
+
+	slimeID := s.slimes(token)[0]["id"].(string)
+	status, _ := s.do(http.MethodPost, "/api/breeding", token, map[string]string{"slime_id_a": slimeID, "slime_id_b": "not-a-uuid"})
+	if status != http.StatusBadRequest {
+		t.Errorf("breeding with a malformed id: %d, want 400", status)
+	}
+}
+
+func TestCommunityPost(t *testing.T) {
+	s := newTestServer(t)
+	_, tokenA := s.login()
+	userB, tokenB := s.login()
+
+	status, body := s.do(http.MethodPost, "/api/community/posts", tokenA, map[string]interface{}{
+		"content": "hello #slimes", "poll_options": []string{"yes", "no"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("create post: %d %v", status, body)
+	}
+	post := "/api/community/posts/" + body["id"].(string)
+
+	if status, body := s.do(http.MethodPost, post+"/like", tokenB, nil); status != http.StatusOK || body["likes"] != float64(1) {
+		t.Errorf("like: %d %v", status, body)
+	}
+	if status, body := s.do(http.MethodPost, post+"/vote", tokenB, map[string]int{"option_index": 1}); status != http.StatusOK {
+		t.Errorf("vote: %d %v", status, body)
+	}
+	status, body = s.do(http.MethodPost, post+"/replies", tokenB, map[string]string{"content": "hi"})
+	if status != http.StatusOK {
+		t.Fatalf("reply: %d %v", status, body)
+	}
+	reply := body["id"].(string)
+	status, body = s.do(http.MethodPost, post+"/replies", tokenA, map[string]string{"content": "hey", "parent_id": reply})
+	if status != http.StatusOK {
+		t.Fatalf("nested reply: %d %v", status, body)
+	}
+	if status, _ := s.do(http.MethodPost, post+"/replies", tokenB, map[string]string{"content": "deeper", "parent_id": body["id"].(string)}); status != http.StatusBadRequest {
+		t.Errorf("reply two levels deep: %d, want 400", status)
+	}
+
+	status, body = s.do(http.MethodGet, "/api/community/posts", tokenB, nil)
+	if status != http.StatusOK {
+		t.Fatalf("list posts: %d %v", status, body)
+	}
+	posts := body["posts"].([]interface{})
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	p := posts[0].(map[string]interface{})
+	if p["liked"] != true || p["reply_count"] != float64(2) || p["is_mine"] != false {
+		t.Errorf("post as seen by the liker = %v", p)
+	}
+	status, body = s.do(http.MethodGet, post+"/poll", tokenB, nil)
+	if status != http.StatusOK || body["poll"].(map[string]interface{})["my_vote"] != float64(1) {
+		t.Errorf("poll: %d %v", status, body)
+	}
+
+	// Blocking the author hides the post
+	if status, _ := s.do(http.MethodPost, "/api/community/users/"+userB+"/block", tokenA, nil); status != http.StatusOK {
+		t.Errorf("block: %d", status)
+	}
+	_, body = s.do(http.MethodGet, "/api/community/posts", tokenA, nil)
+	if posts := body["posts"].([]interface{}); len(posts) != 1 {
+		t.Errorf("author's own feed has %d posts, want 1", len(posts))
+	}
+	_, body = s.do(http.MethodGet, post+"/replies", tokenA, nil)
+	if replies := body["replies"].([]interface{}); len(replies) != 1 {
+		t.Errorf("after blocking the replier, %d replies shown, want 1", len(replies))
+	}
+}
@@ -5,24 +5,15 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"path/filepath"
-	"sort"
 	"syscall"
-	"time"
 
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
-	"github.com/slimetopia/server/internal/admin"
-	"github.com/slimetopia/server/internal/auth"
-	"github.com/slimetopia/server/internal/game"
-	"github.com/slimetopia/server/internal/middleware"
-	"github.com/slimetopia/server/internal/repository"
+	"github.com/slimetopia/server/internal/mail"
+	"github.com/slimetopia/server/internal/migrate"
 	"github.com/slimetopia/server/pkg/config"
 )
 
@@ -42,9 +33,9 @@ func main() {
 	defer pool.Close()
 	log.Info().Msg("Connected to PostgreSQL")
 
-	// Auto-run migrations
+	// Auto-run migrations; a failed or dirty migration keeps the API down
 	if err := runMigrations(ctx, pool); err != nil {
-		log.Warn().Err(err).Msg("Migration warning (may be already applied)")
+		log.Fatal().Err(err).Msg("Database schema is not usable")
 	}
 
 	// Redis connection
@@ -66,99 +57,23 @@ func main() {
 	defer rdb.Close()
 	log.Info().Msg("Connected to Redis")
 
-	// Repositories
-	userRepo := repository.NewUserRepository(pool)
-	slimeRepo := repository.NewSlimeRepository(pool)
-	explorationRepo := repository.NewExplorationRepository(pool)
-	missionRepo := repository.NewMissionRepository(pool)
-	villageRepo := repository.NewVillageRepository(pool)
-	gameDataRepo := repository.NewGameDataRepository(pool)
-
-	// Auth
-	jwtManager := auth.NewJWTManager(cfg.JWTSecret)
-	authHandler := auth.NewHandler(userRepo, slimeRepo, jwtManager, rdb)
-
-	// Register OAuth providers (only if credentials are configured)
-	if cfg.GoogleClientID != "" {
-		authHandler.RegisterProvider(auth.NewGoogleProvider(
-			cfg.GoogleClientID, cfg.GoogleSecret,
-			cfg.OAuthRedirectBase+"/api/auth/callback/google",
-		))
-	}
-	if cfg.KakaoClientID != "" {
-		authHandler.RegisterProvider(auth.NewKakaoProvider(
-			cfg.KakaoClientID, cfg.KakaoSecret,
-			cfg.OAuthRedirectBase+"/api/auth/callback/kakao",
-		))
-	}
-
-	userHandler := auth.NewUserHandler(userRepo)
-	gameHandler := game.NewHandler(slimeRepo, userRepo, explorationRepo, missionRepo, villageRepo, gameDataRepo, rdb)
-	adminHandler := admin.NewAdminHandler(pool, cfg.JWTSecret, gameDataRepo)
-
-	// Fiber app
-	app := fiber.New(fiber.Config{
-		AppName:      "SlimeTopia API",
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		BodyLimit:    50 * 1024 * 1024, // 50MB for video uploads
-	})
-
-	// Middleware
-	app.Use(logger.New())
-	corsOrigins := "http://localhost:3000, http://localhost:3001, https://localhost, capacitor://localhost"
-	if cfg.AllowedOrigins != "" {
-		corsOrigins = corsOrigins + ", " + cfg.AllowedOrigins
-	}
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     corsOrigins,
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		AllowMethods:     "GET, POST, PATCH, DELETE, OPTIONS",
-		AllowCredentials: true,
-	}))
-
-	// Admin panel (server-rendered HTML)
-	admin.RegisterAdminRoutes(app, adminHandler)
-
-	// Static file serving for uploads (video, thumbnails)
-	app.Static("/uploads", "./uploads", fiber.Static{
-		Compress: true,
-		MaxAge:   86400,
-	})
-
-	// Health check (public)
-	app.Get("/api/health", func(c *fiber.Ctx) error {
-		if err := pool.Ping(ctx); err != nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"status": "error",
-				"error":  "database unavailable",
-			})
-		}
-		if err := rdb.Ping(ctx).Err(); err != nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"status": "error",
-				"error":  "redis unavailable",
-			})
-		}
-		return c.JSON(fiber.Map{
-			"status":  "ok",
-			"service": "slimetopia-api",
-			"version": "0.1.0",
+	// Mail (verification and password reset links)
+	var mailer mail.Mailer
+	if cfg.SMTPHost != "" {
+		mailer = mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
 		})
-	})
-
-	// Public routes
-	api := app.Group("/api")
-	auth.RegisterRoutes(api, authHandler)
-
-	// Protected routes
-	protected := api.Use(middleware.AuthRequired(jwtManager))
-	auth.RegisterUserRoutes(protected, userHandler)
-	game.RegisterRoutes(protected, gameHandler)
+	} else {
+		log.Warn().Msg("SMTP_HOST not set, outgoing mail will only be logged")
+		mailer = mail.NewLogMailer(cfg.MailDir, cfg.MailFrom)
+	}
 
-	// Start bot activity background goroutine (runs every 5 minutes)
-	botActivityMgr := game.NewBotActivityManager(pool, 5*time.Minute)
-	botActivityMgr.Start()
+	app, scheduler := newServer(ctx, cfg, pool, rdb, mailer)
+	scheduler.Start()
 
 	// Graceful shutdown
 	go func() {
@@ -174,68 +89,33 @@ func main() {
 	<-quit
 
 	log.Info().Msg("Shutting down server...")
-	botActivityMgr.Stop()
+	scheduler.Stop()
 	if err := app.Shutdown(); err != nil {
 		log.Error().Err(err).Msg("Server shutdown error")
 	}
 }
 
+// runMigrations applies pending migrations and refuses a schema whose applied
+// migrations no longer match the files.
 func runMigrations(ctx context.Context, pool *pgxpool.Pool) error {
-	// Create migration tracking table
-	_, err := pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
-		version VARCHAR(255) PRIMARY KEY,
-		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-	)`)
+	dir, err := migrate.FindDir()
 	if err != nil {
-		return fmt.Errorf("create migration table: %w", err)
+		log.Warn().Msg("No migrations directory found, skipping")
+		return nil
 	}
-
-	// Find migration files
-	migrationPaths := []string{"migrations", "server/migrations", "/app/migrations"}
-	var files []string
-	for _, dir := range migrationPaths {
-		matches, _ := filepath.Glob(filepath.Join(dir, "*.up.sql"))
-		if len(matches) > 0 {
-			files = matches
-			break
-		}
+	migrations, err := migrate.Load(dir)
+	if err != nil {
+		return err
 	}
-	if len(files) == 0 {
-		log.Info().Msg("No migration files found, skipping")
-		return nil
+	applied, err := migrate.New(pool, migrations).Up(ctx)
+	for _, m := range applied {
+		log.Info().Str("migration", m.ID()).Msg("Applied migration")
 	}
-	sort.Strings(files)
-
-	applied := 0
-	for _, f := range files {
-		version := filepath.Base(f)
-		// Check if already applied
-		var exists bool
-		err := pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version=$1)", version).Scan(&exists)
-		if err != nil {
-			return fmt.Errorf("check migration %s: %w", version, err)
-		}
-		if exists {
-			continue
-		}
-
-		// Read and execute migration
-		sql, err := os.ReadFile(f)
-		if err != nil {
-			return fmt.Errorf("read migration %s: %w", version, err)
-		}
-		if _, err := pool.Exec(ctx, string(sql)); err != nil {
-			return fmt.Errorf("apply migration %s: %w", version, err)
-		}
-		// Record as applied
-		if _, err := pool.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version); err != nil {
-			return fmt.Errorf("record migration %s: %w", version, err)
-		}
-		applied++
-		log.Info().Str("file", version).Msg("Applied migration")
+	if err != nil {
+		return err
 	}
-	if applied > 0 {
-		log.Info().Int("count", applied).Msg("Migrations completed")
+	if len(applied) > 0 {
+		log.Info().Int("count", len(applied)).Msg("Migrations completed")
 	} else {
 		log.Info().Msg("All migrations already applied")
 	}
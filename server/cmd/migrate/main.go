@@ -0,0 +1,139 @@
+// Command migrate manages the database schema.
+//
+//	go run ./cmd/migrate up        apply every pending migration
+//	go run ./cmd/migrate down N    revert the N newest applied migrations
+//	go run ./cmd/migrate status    list migrations and when they were applied
+//	go run ./cmd/migrate verify    fail if applied migrations were edited or removed
+//
+// It connects with the same DB_* / DATABASE_URL settings as the API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/migrate"
+	"github.com/slimetopia/server/pkg/config"
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [-dir DIR] up | down N | status | verify\n\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	dir := flag.String("dir", "", "migrations directory (default: migrations, server/migrations or /app/migrations)")
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	if *dir == "" {
+		found, err := migrate.FindDir()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Pass -dir")
+		}
+		*dir = found
+	}
+	migrations, err := migrate.Load(*dir)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", *dir).Msg("Failed to load migrations")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, config.Load().DatabaseURL())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to PostgreSQL")
+	}
+	defer pool.Close()
+	m := migrate.New(pool, migrations)
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mig := range done {
+			fmt.Println("applied ", mig.ID())
+		}
+		if err != nil {
+			fail(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("already up to date")
+		}
+
+	case "down":
+		if len(args) != 2 {
+			usage()
+			os.Exit(2)
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			log.Fatal().Str("n", args[1]).Msg("down needs a positive count")
+		}
+		done, err := m.Down(ctx, n)
+		for _, mig := range done {
+			fmt.Println("reverted", mig.ID())
+		}
+		if err != nil {
+			fail(err)
+		}
+
+	case "status":
+		states, err := m.Status(ctx)
+		if err != nil {
+			fail(err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MIGRATION\tSTATE\tAPPLIED AT")
+		pending := 0
+		for _, s := range states {
+			state, at := "pending", ""
+			if s.Applied {
+				state, at = "applied", s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			switch {
+			case s.Missing:
+				state = "missing file"
+			case s.Modified:
+				state = "modified"
+			case !s.Applied:
+				pending++
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.ID(), state, at)
+		}
+		w.Flush()
+		fmt.Printf("\n%d migrations, %d pending\n", len(migrations), pending)
+
+	case "verify":
+		if err := m.Verify(ctx); err != nil {
+			fail(err)
+		}
+		fmt.Println("schema matches the migration files")
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func fail(err error) {
+	var dirty *migrate.DirtyError
+	if errors.As(err, &dirty) {
+		log.Error().Strs("modified", dirty.Modified).Strs("missing", dirty.Missing).Msg("Schema is dirty")
+		os.Exit(1)
+	}
+	log.Fatal().Err(err).Msg("Migration failed")
+}
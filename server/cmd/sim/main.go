@@ -0,0 +1,196 @@
+// Command sim runs Monte Carlo simulations of gacha pulls and slime synthesis against
+// live game data (or the shared/*.json files), using the same rules the server plays by.
+//
+//	go run ./cmd/sim -pulls 100000 -banner premium
+//	go run ./cmd/sim -data ../shared -mutations mutations.json -merges 50000 -material 7
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/game"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+	"github.com/slimetopia/server/pkg/config"
+)
+
+// simData is the game data a simulation runs on.
+type simData struct {
+	species   []models.SlimeSpecies
+	recipes   []repository.GameRecipe
+	mutations []repository.GameMutationRecipe
+	materials []repository.GameMaterial
+	banners   []repository.GameGachaBanner
+	shop      []repository.GameShopItem
+}
+
+func main() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	dataDir := flag.String("data", "", "load species, recipes, materials and shop items from this shared/ directory instead of the database")
+	bannersFile := flag.String("banners", "", "JSON file with gacha banners, replacing the loaded ones")
+	mutationsFile := flag.String("mutations", "", "JSON file with mutation recipes, replacing the loaded ones")
+	pulls := flag.Int("pulls", 100000, "simulated pulls per banner (0 to skip gacha)")
+	bannerID := flag.String("banner", "", "simulate only this banner (default: every active banner)")
+	luck := flag.Bool("luck", false, "pull with the luck booster active")
+	multi := flag.Bool("multi", false, "pull in 10-pull batches")
+	merges := flag.Int("merges", 100000, "simulated merges per material (0 to skip synthesis)")
+	materialID := flag.Int("material", -1, "merge with this material only; 0 for none, -1 for none and every material")
+	grade := flag.String("grade", "", "only pick parents of this grade")
+	score := flag.Int("score", 0, "collection score used for mutation requirements")
+	seed := flag.Int64("seed", 0, "random seed (default: time based)")
+	flag.Parse()
+
+	var data *simData
+	var err error
+	if *dataDir != "" {
+		data, err = loadFromJSON(*dataDir)
+	} else {
+		data, err = loadFromDB(context.Background(), config.Load())
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load game data")
+	}
+	if *bannersFile != "" {
+		data.banners = nil
+		if err := readJSON(*bannersFile, &data.banners); err != nil {
+			log.Fatal().Err(err).Msg("Failed to read banners")
+		}
+	}
+	if *mutationsFile != "" {
+		data.mutations = nil
+		if err := readJSON(*mutationsFile, &data.mutations); err != nil {
+			log.Fatal().Err(err).Msg("Failed to read mutation recipes")
+		}
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	rng := game.NewRand(*seed)
+	fmt.Printf("seed %d · %d species · %d recipes · %d mutations · %d materials · %d banners\n\n",
+		*seed, len(data.species), len(data.recipes), len(data.mutations), len(data.materials), len(data.banners))
+
+	if *pulls > 0 {
+		runGacha(os.Stdout, rng, data, *bannerID, *pulls, *luck, *multi)
+	}
+	if *merges > 0 {
+		runSynthesis(os.Stdout, rng, data, *materialID, *grade, *merges, *score)
+	}
+}
+
+func loadFromDB(ctx context.Context, cfg *config.Config) (*simData, error) {
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL())
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	defer pool.Close()
+
+	repo := repository.NewGameDataRepository(pool)
+	d := &simData{}
+	if d.species, err = repository.NewSlimeRepository(pool).GetAllSpecies(ctx); err != nil {
+		return nil, fmt.Errorf("species: %w", err)
+	}
+	if d.recipes, err = repo.GetAllRecipes(ctx); err != nil {
+		return nil, fmt.Errorf("recipes: %w", err)
+	}
+	if d.mutations, err = repo.GetAllMutationRecipes(ctx); err != nil {
+		return nil, fmt.Errorf("mutation recipes: %w", err)
+	}
+	if d.materials, err = repo.GetAllMaterials(ctx); err != nil {
+		return nil, fmt.Errorf("materials: %w", err)
+	}
+	if d.banners, err = repo.GetAllGachaBanners(ctx); err != nil {
+		return nil, fmt.Errorf("banners: %w", err)
+	}
+	if d.shop, err = repo.GetAllShopItems(ctx); err != nil {
+		return nil, fmt.Errorf("shop items: %w", err)
+	}
+	return d, nil
+}
+
+// loadFromJSON reads the shared/ data files. They carry no banners or mutation
+// recipes; pass those with -banners and -mutations.
+func loadFromJSON(dir string) (*simData, error) {
+	d := &simData{}
+
+	var slimes struct {
+		Slimes []struct {
+			SpeciesID   int    `json:"species_id"`
+			Name        string `json:"name"`
+			NameEN      string `json:"name_en"`
+			Element     string `json:"element"`
+			Grade       string `json:"grade"`
+			Description string `json:"description"`
+		} `json:"slimes"`
+	}
+	if err := readJSON(filepath.Join(dir, "slime-data.json"), &slimes); err != nil {
+		return nil, err
+	}
+	for _, s := range slimes.Slimes {
+		d.species = append(d.species, models.SlimeSpecies{
+			ID: s.SpeciesID, Name: s.Name, NameEN: s.NameEN,
+			Element: s.Element, Grade: s.Grade, Description: s.Description,
+		})
+	}
+
+	var recipes struct {
+		Recipes []repository.GameRecipe `json:"recipes"`
+	}
+	if err := readJSON(filepath.Join(dir, "recipes.json"), &recipes); err != nil {
+		return nil, err
+	}
+	d.recipes = recipes.Recipes
+
+	var materials struct {
+		Materials []repository.GameMaterial `json:"materials"`
+	}
+	if err := readJSON(filepath.Join(dir, "materials.json"), &materials); err != nil {
+		return nil, err
+	}
+	d.materials = materials.Materials
+
+	var shop struct {
+		Items []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Type string `json:"type"`
+			Cost struct {
+				Gold int64 `json:"gold"`
+				Gems int   `json:"gems"`
+			} `json:"cost"`
+			Quantity int    `json:"quantity"`
+			EggType  string `json:"egg_type"`
+		} `json:"items"`
+	}
+	if err := readJSON(filepath.Join(dir, "shop.json"), &shop); err != nil {
+		return nil, err
+	}
+	for _, it := range shop.Items {
+		d.shop = append(d.shop, repository.GameShopItem{
+			ID: it.ID, Name: it.Name, Type: it.Type, CostGold: it.Cost.Gold, CostGems: it.Cost.Gems,
+			Quantity: it.Quantity, EggType: it.EggType, IsActive: true,
+		})
+	}
+	return d, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/slimetopia/server/internal/game"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+var grades = []string{"common", "uncommon", "rare", "epic", "legendary", "mythic"}
+
+func pct(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// ===== Gacha =====
+
+// eggCost is what one pull costs, averaged over a bundle for multi-pulls.
+type eggCost struct {
+	gold  float64
+	gems  float64
+	known bool
+}
+
+func (c eggCost) times(n float64) string {
+	switch {
+	case !c.known:
+		return "unknown (no shop item)"
+	case c.gems > 0 && c.gold > 0:
+		return fmt.Sprintf("%.0f gold + %.0f gems", c.gold*n, c.gems*n)
+	case c.gems > 0:
+		return fmt.Sprintf("%.0f gems", c.gems*n)
+	default:
+		return fmt.Sprintf("%.0f gold", c.gold*n)
+	}
+}
+
+func pullCost(shop []repository.GameShopItem, bannerID string, multi bool) eggCost {
+	var single eggCost
+	for _, it := range shop {
+		if it.EggType != bannerID {
+			continue
+		}
+		switch {
+		case multi && it.Type == "multi_egg":
+			n := it.Quantity
+			if n <= 0 {
+				n = game.MultiPullSize
+			}
+			return eggCost{gold: float64(it.CostGold) / float64(n), gems: float64(it.CostGems) / float64(n), known: true}
+		case it.Type == "egg":
+			single = eggCost{gold: float64(it.CostGold), gems: float64(it.CostGems), known: true}
+		}
+	}
+	return single
+}
+
+func runGacha(w io.Writer, rng game.Rand, d *simData, bannerID string, pulls int, luck, multi bool) {
+	ran := 0
+	for i := range d.banners {
+		b := &d.banners[i]
+		if (bannerID != "" && b.ID != bannerID) || (bannerID == "" && !b.IsActive) {
+			continue
+		}
+		simulateBanner(w, rng, b, d, pulls, luck, multi)
+		ran++
+	}
+	if ran == 0 {
+		fmt.Fprintln(w, "no banners to simulate (use -banners with -data)")
+		fmt.Fprintln(w)
+	}
+}
+
+// simulateBanner pulls from one banner, carrying pity across pulls like one player
+// would, and compares the outcome with the published odds.
+func simulateBanner(w io.Writer, rng game.Rand, b *repository.GameGachaBanner, d *simData, pulls int, luck, multi bool) {
+	batch, mode := 1, "single pulls"
+	if multi {
+		batch, mode = game.MultiPullSize, fmt.Sprintf("%d-pulls", game.MultiPullSize)
+	}
+	if luck {
+		mode += " · luck booster"
+	}
+	fmt.Fprintf(w, "== banner %s (%s) · %d pulls · %s\n", b.ID, b.Name, pulls, mode)
+
+	counts := map[string]int{}
+	total, legendaryPlus, drought, longest, pity := 0, 0, 0, 0, 0
+	for total < pulls {
+		var hatched []models.SlimeSpecies
+		hatched, pity = game.RollEggs(rng, b, d.species, luck, pity, batch)
+		if len(hatched) == 0 {
+			fmt.Fprintln(w, "banner pool is empty")
+			fmt.Fprintln(w)
+			return
+		}
+		for _, sp := range hatched {
+			counts[sp.Grade]++
+			total++
+			if game.GradeRank(sp.Grade) >= game.GradeRank("legendary") {
+				legendaryPlus++
+				drought = 0
+			} else if drought++; drought > longest {
+				longest = drought
+			}
+		}
+	}
+
+	published := map[string]game.GradeRate{}
+	for _, g := range game.BannerRates(b, d.species, luck).Grades {
+		published[g.Grade] = g
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "grade\tcount\tobserved\tpublished\twith pity\t")
+	for _, g := range grades {
+		if counts[g] == 0 && published[g].BaseRate == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.3f%%\t%.3f%%\t%.3f%%\t\n",
+			g, counts[g], pct(counts[g], total), published[g].BaseRate*100, published[g].EffectiveRate*100)
+	}
+	tw.Flush()
+
+	cost := pullCost(d.shop, b.ID, multi)
+	if legendaryPlus > 0 {
+		per := float64(total) / float64(legendaryPlus)
+		fmt.Fprintf(w, "legendary+: 1 per %.1f pulls · cost per legendary+: %s · longest drought: %d pulls\n",
+			per, cost.times(per), longest)
+	} else {
+		fmt.Fprintf(w, "legendary+: none in %d pulls · cost per pull: %s\n", total, cost.times(1))
+	}
+	fmt.Fprintln(w)
+}
+
+// ===== Synthesis =====
+
+func runSynthesis(w io.Writer, rng game.Rand, d *simData, materialID int, grade string, merges, score int) {
+	sd := game.NewSynthesisData(d.species, d.recipes, d.mutations)
+
+	var parents []models.SlimeSpecies
+	for _, sp := range d.species {
+		if sp.ID < game.HiddenSpeciesFrom && (grade == "" || sp.Grade == grade) {
+			parents = append(parents, sp)
+		}
+	}
+	if len(parents) == 0 {
+		fmt.Fprintln(w, "no parent species to merge")
+		return
+	}
+
+	materials := map[int]*game.Material{}
+	for i := range d.materials {
+		materials[d.materials[i].ID] = game.NewMaterial(&d.materials[i])
+	}
+
+	var runs []*game.Material
+	if materialID <= 0 {
+		runs = append(runs, nil)
+	}
+	for i := range d.materials {
+		if id := d.materials[i].ID; materialID == -1 || id == materialID {
+			runs = append(runs, materials[id])
+		}
+	}
+	for _, m := range runs {
+		simulateMerges(w, rng, sd, parents, m, merges, score)
+	}
+
+	simulateMutations(w, rng, sd, parents, materials, merges, score)
+}
+
+// simulateMerges merges random parent pairs with one material (or none).
+func simulateMerges(w io.Writer, rng game.Rand, sd *game.SynthesisData, parents []models.SlimeSpecies, m *game.Material, merges, score int) {
+	label := "no material"
+	if m != nil {
+		label = fmt.Sprintf("material %d (%s)", m.ID, m.Name)
+	}
+	fmt.Fprintf(w, "== merges · %s · %d random pairs\n", label, merges)
+
+	types := map[string]int{}
+	resultGrades := map[string]int{}
+	valid, great, mutations := 0, 0, 0
+	grade := map[int]string{}
+	for _, sp := range sd.Species {
+		grade[sp.ID] = sp.Grade
+	}
+
+	for i := 0; i < merges; i++ {
+		a := parents[rng.Intn(len(parents))]
+		b := parents[rng.Intn(len(parents))]
+		res := game.SynthesizeWith(rng, sd,
+			models.Slime{SpeciesID: a.ID, Element: a.Element},
+			models.Slime{SpeciesID: b.ID, Element: b.Element},
+			m, score)
+		if res.Error != "" {
+			types["invalid"]++
+			continue
+		}
+		valid++
+		types[res.MergeType]++
+		resultGrades[grade[res.SpeciesID]]++
+		if res.IsGreatSuccess {
+			great++
+		}
+		if res.IsMutation {
+			mutations++
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "merge type\tcount\tshare\t")
+	for _, t := range []string{"combination", "upgrade", "catalyzed", "mutation", "invalid"} {
+		if types[t] > 0 {
+			fmt.Fprintf(tw, "%s\t%d\t%.2f%%\t\n", t, types[t], pct(types[t], merges))
+		}
+	}
+	tw.Flush()
+
+	if valid > 0 {
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "result grade\tcount\tshare\t")
+		for _, g := range grades {
+			if resultGrades[g] > 0 {
+				fmt.Fprintf(tw, "%s\t%d\t%.2f%%\t\n", g, resultGrades[g], pct(resultGrades[g], valid))
+			}
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "great success: %.2f%% · mutation: %.2f%% of valid merges\n", pct(great, valid), pct(mutations, valid))
+	}
+	fmt.Fprintln(w)
+}
+
+// simulateMutations measures each mutation recipe's hit rate: parents of the required
+// elements merged with the required material.
+func simulateMutations(w io.Writer, rng game.Rand, sd *game.SynthesisData, parents []models.SlimeSpecies, materials map[int]*game.Material, merges, score int) {
+	if len(sd.Mutations) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "== mutation hit rates · %d merges each · collection score %d\n", merges, score)
+
+	byElement := map[string][]models.SlimeSpecies{}
+	for _, sp := range parents {
+		byElement[sp.Element] = append(byElement[sp.Element], sp)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "elements\tmaterial\tresult\tnominal\thit rate\t")
+	for _, mr := range sd.Mutations {
+		pair := mr.RequiredElementA + "+" + mr.RequiredElementB
+		m := materials[mr.RequiredMaterial]
+		as, bs := byElement[mr.RequiredElementA], byElement[mr.RequiredElementB]
+		switch {
+		case m == nil:
+			fmt.Fprintf(tw, "%s\t%d\t%d\t-\tmaterial missing\t\n", pair, mr.RequiredMaterial, mr.ResultSpeciesID)
+			continue
+		case len(as) == 0 || len(bs) == 0:
+			fmt.Fprintf(tw, "%s\t%d\t%d\t-\tno parents\t\n", pair, mr.RequiredMaterial, mr.ResultSpeciesID)
+			continue
+		case mr.MinCollectionScore > score:
+			fmt.Fprintf(tw, "%s\t%d\t%d\t-\tlocked (score %d)\t\n", pair, mr.RequiredMaterial, mr.ResultSpeciesID, mr.MinCollectionScore)
+			continue
+		}
+
+		hits := 0
+		for i := 0; i < merges; i++ {
+			a, b := as[rng.Intn(len(as))], bs[rng.Intn(len(bs))]
+			res := game.SynthesizeWith(rng, sd,
+				models.Slime{SpeciesID: a.ID, Element: a.Element},
+				models.Slime{SpeciesID: b.ID, Element: b.Element},
+				m, score)
+			if res.IsMutation && res.SpeciesID == mr.ResultSpeciesID {
+				hits++
+			}
+		}
+		nominal := game.BaseMutationChance + m.Effects.MutationBoost
+		if nominal > 1 {
+			nominal = 1
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%.2f%%\t\n", pair, mr.RequiredMaterial, mr.ResultSpeciesID, nominal*100, pct(hits, merges))
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/auth"
+	"github.com/slimetopia/server/internal/game"
 	"github.com/slimetopia/server/internal/repository"
 )
 
@@ -20,10 +22,12 @@ type AdminHandler struct {
 	jwtSecret    []byte
 	templates    map[string]*template.Template
 	gameDataRepo *repository.GameDataRepository
+	slimeRepo    *repository.SlimeRepository
+	denylist     *auth.Denylist
 }
 
-func NewAdminHandler(pool *pgxpool.Pool, jwtSecret string, gameDataRepo *repository.GameDataRepository) *AdminHandler {
-	h := &AdminHandler{pool: pool, jwtSecret: []byte(jwtSecret), gameDataRepo: gameDataRepo}
+func NewAdminHandler(pool *pgxpool.Pool, jwtSecret string, gameDataRepo *repository.GameDataRepository, slimeRepo *repository.SlimeRepository, denylist *auth.Denylist) *AdminHandler {
+	h := &AdminHandler{pool: pool, jwtSecret: []byte(jwtSecret), gameDataRepo: gameDataRepo, slimeRepo: slimeRepo, denylist: denylist}
 	h.loadTemplates()
 	return h
 }
@@ -169,11 +173,19 @@ func (h *AdminHandler) render(c *fiber.Ctx, name string, data fiber.Map) error {
 	if !ok {
 		return c.Status(fiber.StatusInternalServerError).SendString("Template not found: " + name)
 	}
+	if data == nil {
+		data = fiber.Map{}
+	}
+	role, _ := c.Locals("admin_role").(string)
+	data["AdminRole"] = roleLabels[role]
+	data["Perms"] = grantedPerms(role)
 	c.Set("Content-Type", "text/html; charset=utf-8")
 	return tmpl.ExecuteTemplate(c.Response().BodyWriter(), name, data)
 }
 
-func RegisterAdminRoutes(app *fiber.App, h *AdminHandler) {
+// RegisterAdminRoutes mounts the admin panel. Every route behind the session carries a
+// permission check; gameHandler supplies the bot and seed tools.
+func RegisterAdminRoutes(app *fiber.App, h *AdminHandler, gameHandler *game.Handler) {
 	admin := app.Group("/admin")
 
 	// Public routes (no auth)
@@ -185,148 +197,174 @@ func RegisterAdminRoutes(app *fiber.App, h *AdminHandler) {
 	protected.Post("/logout", h.Logout)
 
 	// Dashboard
-	protected.Get("/", h.Dashboard)
+	protected.Get("/", h.Require(PermDashboard), h.Dashboard)
 
 	// User management
-	protected.Get("/users", h.UserList)
-	protected.Get("/users/:id", h.UserDetailEnhanced)
-	protected.Post("/users/:id/edit", h.EditUser)
-	protected.Post("/users/:id/give", h.GiveUserCurrency)
-	protected.Post("/users/:id/ban", h.BanUser)
-	protected.Post("/users/:id/unban", h.UnbanUser)
+	protected.Get("/users", h.Require(PermUsersView), h.UserList)
+	protected.Get("/users/:id", h.Require(PermUsersView), h.UserDetailEnhanced)
+	protected.Post("/users/:id/edit", h.Require(PermUsersEdit), h.EditUser)
+	protected.Post("/users/:id/give", h.Require(PermUsersEdit), h.GiveUserCurrency)
+	protected.Post("/users/:id/ban", h.Require(PermUsersBan), h.BanUser)
+	protected.Post("/users/:id/unban", h.Require(PermUsersBan), h.UnbanUser)
 
 	// Slime species data
-	protected.Get("/slimes", h.SlimeList)
-	protected.Get("/slime-icon/:id", h.SlimeIcon)
-	protected.Get("/slimes/:id", h.SlimeDetail)
+	protected.Get("/slimes", h.Require(PermUsersView), h.SlimeList)
+	protected.Get("/slime-icon/:id", h.Require(PermUsersView), h.SlimeIcon)
+	protected.Get("/slimes/:id", h.Require(PermUsersView), h.SlimeDetail)
 
 	// Slime instance management (admin give/edit)
-	protected.Get("/slime-instances/create", h.SlimeCreatePage)
-	protected.Post("/slime-instances/create", h.SlimeCreate)
-	protected.Post("/slime-instances/:id/edit", h.SlimeEdit)
-	protected.Post("/slime-instances/:id/delete", h.SlimeDelete)
+	protected.Get("/slime-instances/create", h.Require(PermSlimesEdit), h.SlimeCreatePage)
+	protected.Post("/slime-instances/create", h.Require(PermSlimesEdit), h.SlimeCreate)
+	protected.Post("/slime-instances/:id/edit", h.Require(PermSlimesEdit), h.SlimeEdit)
+	protected.Post("/slime-instances/:id/delete", h.Require(PermSlimesEdit), h.SlimeDelete)
 
 	// Game data viewers + CRUD
-	protected.Get("/gamedata/species", h.SpeciesViewer)
-	protected.Get("/gamedata/gacha", h.GachaRateViewer)
+	protected.Get("/gamedata/species", h.Require(PermGameDataView), h.SpeciesViewer)
+	protected.Get("/gamedata/gacha", h.Require(PermGameDataView), h.GachaBannerViewer)
+	protected.Get("/gamedata/gacha/audit", h.Require(PermGameDataView), h.GachaAuditReport)
+	protected.Post("/gamedata/gacha/create", h.Require(PermGameDataEdit), h.GachaBannerCreate)
+	protected.Post("/gamedata/gacha/:id/update", h.Require(PermGameDataEdit), h.GachaBannerUpdate)
+	protected.Post("/gamedata/gacha/:id/delete", h.Require(PermGameDataEdit), h.GachaBannerDelete)
 
 	// Recipes CRUD
-	protected.Get("/gamedata/recipes", h.RecipeViewer)
-	protected.Post("/gamedata/recipes/create", h.RecipeCreate)
-	protected.Post("/gamedata/recipes/:id/update", h.RecipeUpdate)
-	protected.Post("/gamedata/recipes/:id/delete", h.RecipeDelete)
+	protected.Get("/gamedata/recipes", h.Require(PermGameDataView), h.RecipeViewer)
+	protected.Post("/gamedata/recipes/create", h.Require(PermGameDataEdit), h.RecipeCreate)
+	protected.Post("/gamedata/recipes/:id/update", h.Require(PermGameDataEdit), h.RecipeUpdate)
+	protected.Post("/gamedata/recipes/:id/delete", h.Require(PermGameDataEdit), h.RecipeDelete)
 
 	// Materials CRUD
-	protected.Get("/gamedata/materials", h.MaterialViewer)
-	protected.Post("/gamedata/materials/create", h.MaterialCreate)
-	protected.Post("/gamedata/materials/:id/update", h.MaterialUpdate)
-	protected.Post("/gamedata/materials/:id/delete", h.MaterialDelete)
+	protected.Get("/gamedata/materials", h.Require(PermGameDataView), h.MaterialViewer)
+	protected.Post("/gamedata/materials/create", h.Require(PermGameDataEdit), h.MaterialCreate)
+	protected.Post("/gamedata/materials/:id/update", h.Require(PermGameDataEdit), h.MaterialUpdate)
+	protected.Post("/gamedata/materials/:id/delete", h.Require(PermGameDataEdit), h.MaterialDelete)
 
 	// Explorations CRUD
-	protected.Get("/gamedata/explorations", h.ExplorationViewer)
-	protected.Post("/gamedata/explorations/create", h.ExplorationCreate)
-	protected.Post("/gamedata/explorations/:id/update", h.ExplorationUpdate)
-	protected.Post("/gamedata/explorations/:id/delete", h.ExplorationDelete)
+	protected.Get("/gamedata/explorations", h.Require(PermGameDataView), h.ExplorationViewer)
+	protected.Post("/gamedata/explorations/create", h.Require(PermGameDataEdit), h.ExplorationCreate)
+	protected.Post("/gamedata/explorations/:id/update", h.Require(PermGameDataEdit), h.ExplorationUpdate)
+	protected.Post("/gamedata/explorations/:id/delete", h.Require(PermGameDataEdit), h.ExplorationDelete)
 
 	// Achievements CRUD
-	protected.Get("/gamedata/achievements", h.AchievementViewer)
-	protected.Post("/gamedata/achievements/create", h.AchievementCreate)
-	protected.Post("/gamedata/achievements/:id/update", h.AchievementUpdate)
-	protected.Post("/gamedata/achievements/:id/delete", h.AchievementDelete)
+	protected.Get("/gamedata/achievements", h.Require(PermGameDataView), h.AchievementViewer)
+	protected.Post("/gamedata/achievements/create", h.Require(PermGameDataEdit), h.AchievementCreate)
+	protected.Post("/gamedata/achievements/:id/update", h.Require(PermGameDataEdit), h.AchievementUpdate)
+	protected.Post("/gamedata/achievements/:id/delete", h.Require(PermGameDataEdit), h.AchievementDelete)
 
 	// Accessories CRUD
-	protected.Get("/gamedata/accessories", h.AccessoryViewer)
-	protected.Post("/gamedata/accessories/create", h.AccessoryCreate)
-	protected.Post("/gamedata/accessories/:id/update", h.AccessoryUpdate)
-	protected.Post("/gamedata/accessories/:id/delete", h.AccessoryDelete)
+	protected.Get("/gamedata/accessories", h.Require(PermGameDataView), h.AccessoryViewer)
+	protected.Post("/gamedata/accessories/create", h.Require(PermGameDataEdit), h.AccessoryCreate)
+	protected.Post("/gamedata/accessories/:id/update", h.Require(PermGameDataEdit), h.AccessoryUpdate)
+	protected.Post("/gamedata/accessories/:id/delete", h.Require(PermGameDataEdit), h.AccessoryDelete)
 
 	// Missions CRUD
-	protected.Get("/gamedata/missions", h.MissionViewer)
-	protected.Post("/gamedata/missions/create", h.MissionCreate)
-	protected.Post("/gamedata/missions/:id/update", h.MissionUpdate)
-	protected.Post("/gamedata/missions/:id/delete", h.MissionDelete)
+	protected.Get("/gamedata/missions", h.Require(PermGameDataView), h.MissionViewer)
+	protected.Post("/gamedata/missions/create", h.Require(PermGameDataEdit), h.MissionCreate)
+	protected.Post("/gamedata/missions/:id/update", h.Require(PermGameDataEdit), h.MissionUpdate)
+	protected.Post("/gamedata/missions/:id/delete", h.Require(PermGameDataEdit), h.MissionDelete)
 
 	// Seasons CRUD
-	protected.Get("/gamedata/seasons", h.SeasonViewer)
-	protected.Post("/gamedata/seasons/create", h.SeasonCreate)
-	protected.Post("/gamedata/seasons/:id/update", h.SeasonUpdate)
-	protected.Post("/gamedata/seasons/:id/delete", h.SeasonDelete)
+	protected.Get("/gamedata/seasons", h.Require(PermGameDataView), h.SeasonViewer)
+	protected.Post("/gamedata/seasons/create", h.Require(PermGameDataEdit), h.SeasonCreate)
+	protected.Post("/gamedata/seasons/:id/update", h.Require(PermGameDataEdit), h.SeasonUpdate)
+	protected.Post("/gamedata/seasons/:id/delete", h.Require(PermGameDataEdit), h.SeasonDelete)
 
 	// Sets CRUD
-	protected.Get("/gamedata/sets", h.SetViewer)
-	protected.Post("/gamedata/sets/create", h.SetCreate)
-	protected.Post("/gamedata/sets/:id/update", h.SetUpdate)
-	protected.Post("/gamedata/sets/:id/delete", h.SetDelete)
+	protected.Get("/gamedata/sets", h.Require(PermGameDataView), h.SetViewer)
+	protected.Post("/gamedata/sets/create", h.Require(PermGameDataEdit), h.SetCreate)
+	protected.Post("/gamedata/sets/:id/update", h.Require(PermGameDataEdit), h.SetUpdate)
+	protected.Post("/gamedata/sets/:id/delete", h.Require(PermGameDataEdit), h.SetDelete)
 
 	// Mutations CRUD
-	protected.Get("/gamedata/mutations", h.MutationViewer)
-	protected.Post("/gamedata/mutations/create", h.MutationCreate)
-	protected.Post("/gamedata/mutations/:id/update", h.MutationUpdate)
-	protected.Post("/gamedata/mutations/:id/delete", h.MutationDelete)
+	protected.Get("/gamedata/mutations", h.Require(PermGameDataView), h.MutationViewer)
+	protected.Post("/gamedata/mutations/create", h.Require(PermGameDataEdit), h.MutationCreate)
+	protected.Post("/gamedata/mutations/:id/update", h.Require(PermGameDataEdit), h.MutationUpdate)
+	protected.Post("/gamedata/mutations/:id/delete", h.Require(PermGameDataEdit), h.MutationDelete)
 
 	// Evolutions CRUD
-	protected.Get("/gamedata/evolutions", h.EvolutionViewer)
-	protected.Get("/gamedata/evolutions/:species_id", h.EvolutionDetail)
-	protected.Post("/gamedata/evolutions/:species_id/node", h.EvolutionNodeUpsert)
-	protected.Post("/gamedata/evolutions/:species_id/node/:node_id/delete", h.EvolutionNodeDelete)
+	protected.Get("/gamedata/evolutions", h.Require(PermGameDataView), h.EvolutionViewer)
+	protected.Get("/gamedata/evolutions/:species_id", h.Require(PermGameDataView), h.EvolutionDetail)
+	protected.Post("/gamedata/evolutions/:species_id/node", h.Require(PermGameDataEdit), h.EvolutionNodeUpsert)
+	protected.Post("/gamedata/evolutions/:species_id/node/:node_id/delete", h.Require(PermGameDataEdit), h.EvolutionNodeDelete)
 
 	// Shop CRUD
-	protected.Get("/shop", h.ShopList)
-	protected.Post("/shop/create", h.ShopCreate)
-	protected.Post("/shop/:id/update", h.ShopUpdate)
-	protected.Post("/shop/:id/delete", h.ShopDelete)
+	protected.Get("/shop", h.Require(PermGameDataView), h.ShopList)
+	protected.Post("/shop/create", h.Require(PermShopEdit), h.ShopCreate)
+	protected.Post("/shop/:id/update", h.Require(PermShopEdit), h.ShopUpdate)
+	protected.Post("/shop/:id/delete", h.Require(PermShopEdit), h.ShopDelete)
 
 	// Announcements
-	protected.Get("/announcements", h.AnnouncementList)
-	protected.Post("/announcements", h.CreateAnnouncement)
-	protected.Post("/announcements/:id/toggle", h.ToggleAnnouncement)
-	protected.Post("/announcements/:id/delete", h.DeleteAnnouncement)
+	protected.Get("/announcements", h.Require(PermAnnouncements), h.AnnouncementList)
+	protected.Post("/announcements", h.Require(PermAnnouncements), h.CreateAnnouncement)
+	protected.Post("/announcements/:id/toggle", h.Require(PermAnnouncements), h.ToggleAnnouncement)
+	protected.Post("/announcements/:id/delete", h.Require(PermAnnouncements), h.DeleteAnnouncement)
 
 	// Mail broadcast
-	protected.Get("/mail", h.MailBroadcastPage)
-	protected.Post("/mail/send", h.SendBroadcast)
+	protected.Get("/mail", h.Require(PermMailSend), h.MailBroadcastPage)
+	protected.Post("/mail/send", h.Require(PermMailSend), h.SendBroadcast)
 
 	// World boss
-	protected.Get("/boss", h.WorldBossStatus)
-	protected.Post("/boss/create", h.CreateWorldBoss)
+	protected.Get("/boss", h.Require(PermBossManage), h.WorldBossStatus)
+	protected.Post("/boss/create", h.Require(PermBossManage), h.CreateWorldBoss)
+
+	// Guilds
+	protected.Get("/guilds", h.Require(PermGuildsManage), h.GuildList)
+	protected.Get("/guilds/:id", h.Require(PermGuildsManage), h.GuildDetail)
+	protected.Post("/guilds/:id/update", h.Require(PermGuildsManage), h.GuildEdit)
+	protected.Post("/guilds/:id/disband", h.Require(PermGuildsManage), h.GuildDisband)
+	protected.Post("/guilds/:id/members/:user_id/kick", h.Require(PermGuildsManage), h.GuildKickMember)
+	protected.Post("/guilds/:id/messages/:msg_id/delete", h.Require(PermGuildsManage), h.GuildDeleteMessage)
 
 	// Community moderation
-	protected.Get("/moderation/reports", h.CommunityReports)
-	protected.Post("/moderation/reports/:id/process", h.ProcessReport)
-	protected.Get("/moderation/posts", h.CommunityPostList)
-	protected.Post("/moderation/posts/:id/delete", h.DeleteCommunityPost)
-	protected.Post("/moderation/replies/:id/delete", h.DeleteCommunityReply)
+	protected.Get("/moderation/reports", h.Require(PermModeration), h.CommunityReports)
+	protected.Post("/moderation/reports/:id/process", h.Require(PermModeration), h.ProcessReport)
+	protected.Get("/moderation/posts", h.Require(PermModeration), h.CommunityPostList)
+	protected.Post("/moderation/posts/:id/delete", h.Require(PermModeration), h.DeleteCommunityPost)
+	protected.Post("/moderation/replies/:id/delete", h.Require(PermModeration), h.DeleteCommunityReply)
 
 	// Log viewers
-	protected.Get("/logs/currency", h.CurrencyLogs)
-	protected.Get("/logs/items", h.ItemLogs)
-	protected.Get("/logs/collection", h.CollectionLogs)
-	protected.Get("/logs/gacha", h.GachaLogs)
-	protected.Get("/logs/shop", h.ShopPurchaseLogs)
-	protected.Get("/logs/community", h.CommunityLogs)
-	protected.Get("/logs/users", h.UserLogs)
+	protected.Get("/logs/currency", h.Require(PermLogsView), h.CurrencyLogs)
+	protected.Get("/logs/items", h.Require(PermLogsView), h.ItemLogs)
+	protected.Get("/logs/collection", h.Require(PermLogsView), h.CollectionLogs)
+	protected.Get("/logs/gacha", h.Require(PermLogsView), h.GachaLogs)
+	protected.Get("/logs/shop", h.Require(PermLogsView), h.ShopPurchaseLogs)
+	protected.Get("/logs/community", h.Require(PermLogsView), h.CommunityLogs)
+	protected.Get("/logs/users", h.Require(PermLogsView), h.UserLogs)
 
 	// Support / Customer service
-	protected.Get("/support", h.SupportTicketList)
-	protected.Get("/support/:id", h.SupportTicketDetail)
-	protected.Post("/support/:id/reply", h.SupportTicketReply)
-	protected.Post("/support/:id/status", h.SupportTicketUpdateStatus)
-	protected.Post("/support/:id/assign", h.SupportTicketAssign)
+	protected.Get("/support", h.Require(PermSupport), h.SupportTicketList)
+	protected.Get("/support/:id", h.Require(PermSupport), h.SupportTicketDetail)
+	protected.Post("/support/:id/reply", h.Require(PermSupport), h.SupportTicketReply)
+	protected.Post("/support/:id/status", h.Require(PermSupport), h.SupportTicketUpdateStatus)
+	protected.Post("/support/:id/assign", h.Require(PermSupport), h.SupportTicketAssign)
 
 	// Revenue
-	protected.Get("/revenue", h.RevenueDashboard)
+	protected.Get("/revenue", h.Require(PermRevenueView), h.RevenueDashboard)
 
 	// Shorts moderation
-	protected.Get("/shorts", h.ShortsModList)
-	protected.Get("/shorts/stats", h.ShortsStats)
-	protected.Get("/shorts/:id", h.ShortsModDetail)
-	protected.Post("/shorts/:id/hide", h.ShortsHide)
-	protected.Post("/shorts/:id/delete", h.ShortsDelete)
+	protected.Get("/shorts", h.Require(PermModeration), h.ShortsModList)
+	protected.Get("/shorts/stats", h.Require(PermModeration), h.ShortsStats)
+	protected.Get("/shorts/:id", h.Require(PermModeration), h.ShortsModDetail)
+	protected.Post("/shorts/:id/hide", h.Require(PermModeration), h.ShortsHide)
+	protected.Post("/shorts/:id/delete", h.Require(PermModeration), h.ShortsDelete)
 
 	// Bulk report processing
-	protected.Post("/moderation/reports/bulk", h.BulkProcessReports)
+	protected.Post("/moderation/reports/bulk", h.Require(PermModeration), h.BulkProcessReports)
 
 	// System / logs (legacy)
-	protected.Get("/logs", h.LogsPage)
-	protected.Get("/audit", h.AuditLog)
+	protected.Get("/logs", h.Require(PermLogsView), h.LogsPage)
+	protected.Get("/audit", h.Require(PermAuditView), h.AuditLog)
+
+	// Admin accounts
+	protected.Get("/admins", h.Require(PermAdminsManage), h.AdminAccountList)
+	protected.Post("/admins/create", h.Require(PermAdminsManage), h.AdminAccountCreate)
+	protected.Post("/admins/:id/role", h.Require(PermAdminsManage), h.AdminAccountUpdateRole)
+	protected.Post("/admins/:id/delete", h.Require(PermAdminsManage), h.AdminAccountDelete)
+
+	// Bot / seed tools (formerly on the player API)
+	protected.Get("/tools", h.Require(PermBotsManage), h.ToolsPage)
+	protected.Post("/bots/seed", h.Require(PermBotsManage), h.toolAction("seed_bots", gameHandler.SeedBots))
+	protected.Post("/bots/delete", h.Require(PermBotsManage), h.toolAction("delete_bots", gameHandler.DeleteBots))
+	protected.Post("/activity-bots/seed", h.Require(PermBotsManage), h.toolAction("seed_activity_bots", gameHandler.SeedActivityBots))
+	protected.Post("/activity-bots/delete", h.Require(PermBotsManage), h.toolAction("delete_activity_bots", gameHandler.DeleteActivityBots))
+	protected.Post("/shorts/seed", h.Require(PermBotsManage), h.toolAction("seed_shorts", gameHandler.SeedShorts))
 }
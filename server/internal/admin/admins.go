@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AdminAccountRow struct {
+	ID        int
+	Username  string
+	Role      string
+	RoleLabel string
+	CreatedAt time.Time
+}
+
+type roleOption struct {
+	Value string
+	Label string
+}
+
+func roleOptions() []roleOption {
+	opts := make([]roleOption, 0, len(AdminRoles))
+	for _, r := range AdminRoles {
+		opts = append(opts, roleOption{Value: r, Label: roleLabels[r]})
+	}
+	return opts
+}
+
+// GET /admin/admins
+func (h *AdminHandler) AdminAccountList(c *fiber.Ctx) error {
+	ctx := c.Context()
+	username := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+
+	rows, err := h.pool.Query(ctx,
+		`SELECT id, username, role, created_at FROM admin_users ORDER BY id`,
+	)
+	if err != nil {
+		return h.render(c, "admins.html", fiber.Map{
+			"Title": "관리자 계정", "Username": username,
+			"Error": "Failed to fetch admin accounts", "Message": "",
+		})
+	}
+	defer rows.Close()
+
+	accounts := make([]AdminAccountRow, 0)
+	for rows.Next() {
+		var a AdminAccountRow
+		if rows.Scan(&a.ID, &a.Username, &a.Role, &a.CreatedAt) == nil {
+			a.RoleLabel = roleLabels[a.Role]
+			accounts = append(accounts, a)
+		}
+	}
+
+	return h.render(c, "admins.html", fiber.Map{
+		"Title":    "관리자 계정",
+		"Username": username,
+		"SelfID":   adminID,
+		"Accounts": accounts,
+		"Roles":    roleOptions(),
+		"Message":  c.Query("msg"),
+	})
+}
+
+// POST /admin/admins/create
+func (h *AdminHandler) AdminAccountCreate(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+
+	newUsername := c.FormValue("username")
+	password := c.FormValue("password")
+	role := c.FormValue("role")
+	if newUsername == "" || len(password) < 8 {
+		return c.Redirect("/admin/admins?msg=required")
+	}
+	if !validRole(role) {
+		return c.Redirect("/admin/admins?msg=invalid_role")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Redirect("/admin/admins?msg=error")
+	}
+
+	var newID int
+	err = h.pool.QueryRow(ctx,
+		`INSERT INTO admin_users (username, password_hash, role) VALUES ($1, $2, $3)
+		 ON CONFLICT (username) DO NOTHING RETURNING id`,
+		newUsername, string(hash), role,
+	).Scan(&newID)
+	if err != nil {
+		return c.Redirect("/admin/admins?msg=duplicate")
+	}
+
+	logAdminAction(h.pool, ctx, adminID, adminUsername, "create_admin", "admin", strconv.Itoa(newID),
+		fmt.Sprintf("username:%s role:%s", newUsername, role))
+	return c.Redirect("/admin/admins?msg=created")
+}
+
+// POST /admin/admins/:id/role
+func (h *AdminHandler) AdminAccountUpdateRole(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+
+	targetID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Redirect("/admin/admins?msg=error")
+	}
+	role := c.FormValue("role")
+	if !validRole(role) {
+		return c.Redirect("/admin/admins?msg=invalid_role")
+	}
+	// Demoting yourself could leave nobody able to manage accounts
+	if targetID == adminID && role != RoleSuperAdmin {
+		return c.Redirect("/admin/admins?msg=self")
+	}
+
+	var oldRole string
+	err = h.pool.QueryRow(ctx,
+		`UPDATE admin_users a SET role = $1 FROM admin_users old
+		 WHERE a.id = $2 AND old.id = a.id RETURNING old.role`,
+		role, targetID,
+	).Scan(&oldRole)
+	if err != nil {
+		return c.Redirect("/admin/admins?msg=error")
+	}
+
+	logAdminAction(h.pool, ctx, adminID, adminUsername, "update_admin_role", "admin", strconv.Itoa(targetID),
+		fmt.Sprintf("%s -> %s", oldRole, role))
+	return c.Redirect("/admin/admins?msg=updated")
+}
+
+// POST /admin/admins/:id/delete
+func (h *AdminHandler) AdminAccountDelete(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+
+	targetID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Redirect("/admin/admins?msg=error")
+	}
+	if targetID == adminID {
+		return c.Redirect("/admin/admins?msg=self")
+	}
+
+	var deleted string
+	err = h.pool.QueryRow(ctx,
+		`DELETE FROM admin_users WHERE id = $1 RETURNING username`, targetID,
+	).Scan(&deleted)
+	if err != nil {
+		return c.Redirect("/admin/admins?msg=error")
+	}
+
+	logAdminAction(h.pool, ctx, adminID, adminUsername, "delete_admin", "admin", strconv.Itoa(targetID),
+		fmt.Sprintf("username:%s", deleted))
+	return c.Redirect("/admin/admins?msg=deleted")
+}
+
+// GET /admin/tools
+func (h *AdminHandler) ToolsPage(c *fiber.Ctx) error {
+	ctx := c.Context()
+	username := c.Locals("admin_username").(string)
+
+	var botCount, activityBotCount, shortsCount int
+	h.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email LIKE '%@slimetopia.bot' AND email NOT LIKE 'actbot%'`).Scan(&botCount)
+	h.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email LIKE 'actbot%@slimetopia.bot'`).Scan(&activityBotCount)
+	h.pool.QueryRow(ctx, `SELECT COUNT(*) FROM shorts`).Scan(&shortsCount)
+
+	return h.render(c, "tools.html", fiber.Map{
+		"Title":            "개발 도구",
+		"Username":         username,
+		"BotCount":         botCount,
+		"ActivityBotCount": activityBotCount,
+		"ShortsCount":      shortsCount,
+		"Message":          c.Query("msg"),
+		"Action":           c.Query("action"),
+	})
+}
@@ -71,6 +71,21 @@ func (h *AdminHandler) AuthMiddleware(c *fiber.Ctx) error {
 		return c.Redirect("/admin/login")
 	}
 
+	// The role claim is only trusted while it still matches the account, so demoted
+	// or deleted admins lose access immediately instead of when the session expires.
+	var role string
+	err = h.pool.QueryRow(c.Context(), `SELECT role FROM admin_users WHERE id = $1`, claims.AdminID).Scan(&role)
+	if err != nil || role != claims.Role {
+		c.Cookie(&fiber.Cookie{
+			Name:     sessionCookieName,
+			Value:    "",
+			Path:     "/admin",
+			MaxAge:   -1,
+			HTTPOnly: true,
+		})
+		return c.Redirect("/admin/login")
+	}
+
 	c.Locals("admin_id", claims.AdminID)
 	c.Locals("admin_username", claims.Username)
 	c.Locals("admin_role", claims.Role)
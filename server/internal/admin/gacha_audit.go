@@ -0,0 +1,227 @@
+package admin
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/game"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// gachaAuditAlpha is the significance level below which observed outcomes are flagged
+// as deviating from the published rates.
+const gachaAuditAlpha = 0.01
+
+// gachaAuditMinExpected is the smallest expected count per grade for the chi-square
+// approximation to be trusted.
+const gachaAuditMinExpected = 5.0
+
+type GachaAuditGrade struct {
+	Grade     string
+	Published float64 // %
+	Observed  float64 // %
+	Count     int
+	Expected  float64
+}
+
+type GachaAuditRow struct {
+	ID        string
+	Name      string
+	Samples   int
+	Grades    []GachaAuditGrade
+	ChiSquare float64
+	DF        int
+	PValue    float64
+	Status    string // ok, deviation, insufficient, no_data
+}
+
+// gachaAuditCountsQuery counts pulls per banner and grade that rolled on the base odds: no
+// pity or multi-pull floor and no luck booster. Only logs that record the floor are
+// used, since earlier ones can't tell pity pulls apart.
+const gachaAuditCountsQuery = `
+	SELECT l.detail->>'egg_type', l.detail->>'grade', COUNT(*)
+	FROM game_logs l
+	WHERE l.action = 'gacha_single' AND l.created_at >= $1
+	  AND l.detail->>'floor' = ''
+	  AND NOT COALESCE((l.detail->>'luck')::boolean, false)
+	GROUP BY 1, 2
+	UNION ALL
+	SELECT l.detail->>'egg_type', g.grade, COUNT(*)
+	FROM game_logs l
+	CROSS JOIN LATERAL jsonb_array_elements_text(l.detail->'grades') WITH ORDINALITY AS g(grade, i)
+	WHERE l.action = 'gacha_multi' AND l.created_at >= $1
+	  AND l.detail->'floors' IS NOT NULL
+	  AND l.detail->'floors'->>((g.i - 1)::int) = ''
+	  AND NOT COALESCE((l.detail->>'luck')::boolean, false)
+	GROUP BY 1, 2`
+
+// GachaAuditReport compares published base rates with outcomes logged in game_logs
+func (h *AdminHandler) GachaAuditReport(c *fiber.Ctx) error {
+	ctx := c.Context()
+	username := c.Locals("admin_username").(string)
+
+	days, _ := strconv.Atoi(c.Query("days", "30"))
+	if days < 1 || days > 365 {
+		days = 30
+	}
+	data := fiber.Map{"Title": "가챠 확률 검증", "Username": username, "Days": days, "Alpha": gachaAuditAlpha}
+
+	banners, err := h.gameDataRepo.GetAllGachaBanners(ctx)
+	if err != nil {
+		data["Error"] = err.Error()
+		return h.render(c, "gacha_audit.html", data)
+	}
+	species, err := h.slimeRepo.GetAllSpecies(ctx)
+	if err != nil {
+		data["Error"] = err.Error()
+		return h.render(c, "gacha_audit.html", data)
+	}
+
+	counts := map[string]map[string]int{}
+	rows, err := h.pool.Query(ctx, gachaAuditCountsQuery, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		data["Error"] = err.Error()
+		return h.render(c, "gacha_audit.html", data)
+	}
+	for rows.Next() {
+		var eggType, grade string
+		var n int
+		if rows.Scan(&eggType, &grade, &n) == nil {
+			if counts[eggType] == nil {
+				counts[eggType] = map[string]int{}
+			}
+			counts[eggType][grade] += n
+		}
+	}
+	rows.Close()
+
+	report := make([]GachaAuditRow, 0, len(banners))
+	flagged := 0
+	for i := range banners {
+		row := auditBanner(&banners[i], species, counts[banners[i].ID])
+		if row.Status == "deviation" {
+			flagged++
+		}
+		report = append(report, row)
+	}
+
+	data["Report"] = report
+	data["Flagged"] = flagged
+	return h.render(c, "gacha_audit.html", data)
+}
+
+// auditBanner runs a chi-square goodness-of-fit test of observed grade counts against
+// the banner's base odds. A grade that came up despite zero published odds is always
+// flagged.
+func auditBanner(b *repository.GameGachaBanner, species []models.SlimeSpecies, observed map[string]int) GachaAuditRow {
+	row := GachaAuditRow{ID: b.ID, Name: b.Name}
+	published := map[string]float64{}
+	for _, g := range game.BannerRates(b, species, false).Grades {
+		published[g.Grade] = g.BaseRate
+	}
+	for _, n := range observed {
+		row.Samples += n
+	}
+	if row.Samples == 0 {
+		row.Status = "no_data"
+	}
+
+	impossible, sparse := false, false
+	cells := 0
+	for _, grade := range gachaGrades {
+		p, n := published[grade], observed[grade]
+		if p == 0 && n == 0 {
+			continue
+		}
+		g := GachaAuditGrade{Grade: grade, Published: math.Round(p*10000) / 100, Count: n, Expected: p * float64(row.Samples)}
+		if row.Samples > 0 {
+			g.Observed = math.Round(float64(n)/float64(row.Samples)*10000) / 100
+		}
+		row.Grades = append(row.Grades, g)
+
+		if p == 0 {
+			impossible = true
+			continue
+		}
+		cells++
+		if g.Expected < gachaAuditMinExpected {
+			sparse = true
+		}
+		if g.Expected > 0 {
+			d := float64(n) - g.Expected
+			row.ChiSquare += d * d / g.Expected
+		}
+	}
+	if row.Samples == 0 {
+		return row
+	}
+
+	row.DF = cells - 1
+	row.PValue = chiSquareSF(row.ChiSquare, row.DF)
+	row.ChiSquare = math.Round(row.ChiSquare*100) / 100
+	switch {
+	case impossible || (!sparse && row.PValue < gachaAuditAlpha):
+		row.Status = "deviation"
+	case sparse:
+		row.Status = "insufficient"
+	default:
+		row.Status = "ok"
+	}
+	return row
+}
+
+// chiSquareSF is P(X >= x) for a chi-square variable with df degrees of freedom.
+func chiSquareSF(x float64, df int) float64 {
+	if df <= 0 || x <= 0 {
+		return 1
+	}
+	return gammaQ(float64(df)/2, x/2)
+}
+
+// gammaQ is the regularized upper incomplete gamma function, by series for x < a+1 and
+// by continued fraction otherwise.
+func gammaQ(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-15 {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	f := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		f *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return prefix * f
+}
@@ -2,8 +2,11 @@ package admin
 
 import (
 	"encoding/json"
+	"math"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/slimetopia/server/internal/repository"
@@ -468,7 +471,7 @@ func (h *AdminHandler) AccessoryCreate(c *fiber.Ctx) error {
 		ID: id, Name: c.FormValue("name"), NameEN: c.FormValue("name_en"),
 		Slot: c.FormValue("slot"), Icon: c.FormValue("icon"),
 		CostGold: cg, CostGems: cgems,
-		SvgOverlay: c.FormValue("svg_overlay"), IsActive: true,
+		SvgOverlay: c.FormValue("svg_overlay"), Effect: ensureJSON(c.FormValue("effect")), IsActive: true,
 	}
 	if err := h.gameDataRepo.CreateAccessory(ctx, a); err != nil {
 		return c.Redirect("/admin/gamedata/accessories?msg=error")
@@ -486,6 +489,7 @@ func (h *AdminHandler) AccessoryUpdate(c *fiber.Ctx) error {
 		Slot: c.FormValue("slot"), Icon: c.FormValue("icon"),
 		CostGold: cg, CostGems: cgems,
 		SvgOverlay: c.FormValue("svg_overlay"),
+		Effect:     ensureJSON(c.FormValue("effect")),
 		IsActive:   c.FormValue("is_active") == "on",
 	}
 	if err := h.gameDataRepo.UpdateAccessory(ctx, a); err != nil {
@@ -920,46 +924,194 @@ func (h *AdminHandler) EvolutionNodeDelete(c *fiber.Ctx) error {
 	return c.Redirect("/admin/gamedata/evolutions/" + strconv.Itoa(speciesID) + "?msg=deleted")
 }
 
-// ===== Gacha Rate Viewer (static data, no DB) =====
+// ===== Gacha Banners CRUD =====
+
+var gachaGrades = []string{"common", "uncommon", "rare", "epic", "legendary", "mythic"}
+
+const bannerTimeLayout = "2006-01-02T15:04"
 
 type GachaRate struct {
 	Grade  string
+	Weight float64
 	Rate   float64
-	Pity   int
-	Detail string
 }
 
-func (h *AdminHandler) GachaRateViewer(c *fiber.Ctx) error {
+// GachaBannerView is a banner with its weights turned into published percentages
+type GachaBannerView struct {
+	repository.GameGachaBanner
+	Rates       []GachaRate
+	LuckRates   []GachaRate
+	Inputs      []GachaRate // every grade, for the edit form
+	LuckInputs  []GachaRate
+	ElementsStr string
+	StartsInput string
+	EndsInput   string
+	Live        bool
+}
+
+func gachaRates(weights map[string]float64) []GachaRate {
+	var total float64
+	for _, g := range gachaGrades {
+		total += weights[g]
+	}
+	var rates []GachaRate
+	for _, g := range gachaGrades {
+		if w := weights[g]; w > 0 {
+			rates = append(rates, GachaRate{Grade: g, Weight: w, Rate: math.Round(w/total*10000) / 100})
+		}
+	}
+	return rates
+}
+
+func gachaInputs(weights map[string]float64) []GachaRate {
+	inputs := make([]GachaRate, 0, len(gachaGrades))
+	for _, g := range gachaGrades {
+		inputs = append(inputs, GachaRate{Grade: g, Weight: weights[g]})
+	}
+	return inputs
+}
+
+func newGachaBannerView(b repository.GameGachaBanner, now time.Time) GachaBannerView {
+	v := GachaBannerView{
+		GameGachaBanner: b,
+		Rates:           gachaRates(b.GradeWeights),
+		LuckRates:       gachaRates(b.LuckGradeWeights),
+		Inputs:          gachaInputs(b.GradeWeights),
+		LuckInputs:      gachaInputs(b.LuckGradeWeights),
+		ElementsStr:     strings.Join(b.Elements, ","),
+		Live:            b.IsActive && (b.StartsAt == nil || !now.Before(*b.StartsAt)) && (b.EndsAt == nil || now.Before(*b.EndsAt)),
+	}
+	if b.StartsAt != nil {
+		v.StartsInput = b.StartsAt.Local().Format(bannerTimeLayout)
+	}
+	if b.EndsAt != nil {
+		v.EndsInput = b.EndsAt.Local().Format(bannerTimeLayout)
+	}
+	return v
+}
+
+func (h *AdminHandler) GachaBannerViewer(c *fiber.Ctx) error {
+	ctx := c.Context()
 	username := c.Locals("admin_username").(string)
+	msg := c.Query("msg")
 
-	normalRates := []GachaRate{
-		{"common", 50.0, 0, "기본 등급"},
-		{"uncommon", 30.0, 0, "약간 희귀"},
-		{"rare", 15.0, 0, "희귀"},
-		{"epic", 4.0, 0, "에픽"},
-		{"legendary", 0.9, 50, "50회 피티"},
-		{"mythic", 0.1, 0, "매우 희귀"},
-	}
-	premiumRates := []GachaRate{
-		{"common", 30.0, 0, "기본 등급"},
-		{"uncommon", 35.0, 0, "약간 희귀"},
-		{"rare", 22.0, 0, "희귀"},
-		{"epic", 10.0, 0, "에픽"},
-		{"legendary", 2.5, 30, "30회 피티"},
-		{"mythic", 0.5, 0, "매우 희귀"},
-	}
-	legendaryRates := []GachaRate{
-		{"rare", 40.0, 0, "최소 레어"},
-		{"epic", 35.0, 0, "에픽"},
-		{"legendary", 20.0, 20, "20회 피티"},
-		{"mythic", 5.0, 0, "미씩"},
-	}
-
-	return h.render(c, "gacha.html", fiber.Map{
-		"Title":          "가챠 확률표",
-		"Username":       username,
-		"NormalRates":    normalRates,
-		"PremiumRates":   premiumRates,
-		"LegendaryRates": legendaryRates,
-	})
+	banners, err := h.gameDataRepo.GetAllGachaBanners(ctx)
+	if err != nil {
+		return h.render(c, "gacha.html", fiber.Map{
+			"Title": "가챠 배너", "Username": username, "Error": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	views := make([]GachaBannerView, 0, len(banners))
+	for _, b := range banners {
+		views = append(views, newGachaBannerView(b, now))
+	}
+
+	data := fiber.Map{
+		"Title": "가챠 배너", "Username": username,
+		"Banners": views, "Total": len(views), "Message": msg,
+	}
+
+	if editID := c.Query("edit"); editID != "" {
+		for _, v := range views {
+			if v.ID == editID {
+				data["EditItem"] = v
+				break
+			}
+		}
+	}
+	if c.Query("create") == "1" {
+		data["ShowCreate"] = true
+		data["NewItem"] = newGachaBannerView(repository.GameGachaBanner{RateUpShare: 0.5, IsActive: true}, now)
+	}
+
+	return h.render(c, "gacha.html", data)
+}
+
+// parseGachaBannerForm reads the banner form; the id comes from the form on create
+// and from the route on update.
+func parseGachaBannerForm(c *fiber.Ctx, id string) *repository.GameGachaBanner {
+	b := &repository.GameGachaBanner{
+		ID:               strings.TrimSpace(id),
+		Name:             c.FormValue("name"),
+		NameEN:           c.FormValue("name_en"),
+		Description:      c.FormValue("description"),
+		GradeWeights:     map[string]float64{},
+		LuckGradeWeights: map[string]float64{},
+		SpeciesPool:      parseIntSlice(c.FormValue("species_pool")),
+		RateUpSpecies:    parseIntSlice(c.FormValue("rate_up_species")),
+		IsActive:         c.FormValue("is_active") == "on",
+	}
+	for _, g := range gachaGrades {
+		if w, err := strconv.ParseFloat(c.FormValue("w_"+g), 64); err == nil && w > 0 {
+			b.GradeWeights[g] = w
+		}
+		if w, err := strconv.ParseFloat(c.FormValue("luck_w_"+g), 64); err == nil && w > 0 {
+			b.LuckGradeWeights[g] = w
+		}
+	}
+	b.Elements = []string{}
+	for _, e := range strings.Split(c.FormValue("elements"), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			b.Elements = append(b.Elements, e)
+		}
+	}
+	b.RateUpShare, _ = strconv.ParseFloat(c.FormValue("rate_up_share"), 64)
+	b.PityRare, _ = strconv.Atoi(c.FormValue("pity_rare"))
+	b.PityEpic, _ = strconv.Atoi(c.FormValue("pity_epic"))
+	b.PityLegendary, _ = strconv.Atoi(c.FormValue("pity_legendary"))
+	b.SortOrder, _ = strconv.Atoi(c.FormValue("sort_order"))
+	if t, err := time.ParseInLocation(bannerTimeLayout, c.FormValue("starts_at"), time.Local); err == nil {
+		b.StartsAt = &t
+	}
+	if t, err := time.ParseInLocation(bannerTimeLayout, c.FormValue("ends_at"), time.Local); err == nil {
+		b.EndsAt = &t
+	}
+	return b
+}
+
+func validGachaBanner(b *repository.GameGachaBanner) bool {
+	if b.ID == "" || b.Name == "" || len(b.GradeWeights) == 0 {
+		return false
+	}
+	if b.RateUpShare < 0 || b.RateUpShare > 1 || b.PityRare < 0 || b.PityEpic < 0 || b.PityLegendary < 0 {
+		return false
+	}
+	return b.StartsAt == nil || b.EndsAt == nil || b.StartsAt.Before(*b.EndsAt)
+}
+
+func (h *AdminHandler) GachaBannerCreate(c *fiber.Ctx) error {
+	ctx := c.Context()
+	b := parseGachaBannerForm(c, c.FormValue("id"))
+	b.IsActive = true
+	if !validGachaBanner(b) {
+		return c.Redirect("/admin/gamedata/gacha?msg=invalid")
+	}
+	if err := h.gameDataRepo.CreateGachaBanner(ctx, b); err != nil {
+		return c.Redirect("/admin/gamedata/gacha?msg=error")
+	}
+	return c.Redirect("/admin/gamedata/gacha?msg=created")
+}
+
+func (h *AdminHandler) GachaBannerUpdate(c *fiber.Ctx) error {
+	ctx := c.Context()
+	b := parseGachaBannerForm(c, c.Params("id"))
+	if !validGachaBanner(b) {
+		return c.Redirect("/admin/gamedata/gacha?edit=" + url.QueryEscape(b.ID) + "&msg=invalid")
+	}
+	if err := h.gameDataRepo.UpdateGachaBanner(ctx, b); err != nil {
+		return c.Redirect("/admin/gamedata/gacha?msg=error")
+	}
+	return c.Redirect("/admin/gamedata/gacha?msg=updated")
+}
+
+func (h *AdminHandler) GachaBannerDelete(c *fiber.Ctx) error {
+	ctx := c.Context()
+	// "normal" is the fallback for eggs without a banner of their own
+	if c.Params("id") == "normal" {
+		return c.Redirect("/admin/gamedata/gacha?msg=protected")
+	}
+	h.gameDataRepo.DeleteGachaBanner(ctx, c.Params("id"))
+	return c.Redirect("/admin/gamedata/gacha?msg=deleted")
 }
@@ -0,0 +1,314 @@
+package admin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+type GuildRow struct {
+	ID          string
+	Name        string
+	Description string
+	JoinPolicy  string
+	Level       int
+	XP          int64
+	MemberCount int
+	Leader      string
+	CreatedAt   time.Time
+}
+
+type GuildMemberRow struct {
+	UserID        string
+	Nickname      string
+	Role          string
+	ContributedXP int64
+	JoinedAt      time.Time
+}
+
+type GuildMessageRow struct {
+	ID        int64
+	Nickname  string
+	Message   string
+	CreatedAt time.Time
+}
+
+type GuildBossRow struct {
+	ID        int
+	Stage     int
+	Name      string
+	Element   string
+	MaxHP     int64
+	CurrentHP int64
+	ExpiresAt time.Time
+	Defeated  bool
+}
+
+const guildSelect = `SELECT g.id::text, g.name, g.description, g.join_policy, g.level, g.xp,
+	(SELECT COUNT(*) FROM guild_members WHERE guild_id = g.id),
+	COALESCE((SELECT u.nickname FROM guild_members gm JOIN users u ON u.id = gm.user_id
+	          WHERE gm.guild_id = g.id AND gm.role = 'leader'), ''),
+	g.created_at
+	FROM guilds g`
+
+// GuildList lists guilds, optionally filtered by name
+func (h *AdminHandler) GuildList(c *fiber.Ctx) error {
+	ctx := c.Context()
+	username := c.Locals("admin_username").(string)
+	search := c.Query("q")
+	message := c.Query("msg")
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit := 30
+	offset := (page - 1) * limit
+
+	where := `TRUE`
+	args := []interface{}{}
+	if search != "" {
+		where = `g.name ILIKE $1`
+		args = append(args, "%"+search+"%")
+	}
+
+	var totalCount int
+	h.pool.QueryRow(ctx, `SELECT COUNT(*) FROM guilds g WHERE `+where, args...).Scan(&totalCount)
+
+	query := fmt.Sprintf(`%s WHERE %s ORDER BY g.level DESC, g.xp DESC LIMIT $%d OFFSET $%d`,
+		guildSelect, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	var guilds []GuildRow
+	rows, err := h.pool.Query(ctx, query, args...)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var g GuildRow
+			if rows.Scan(&g.ID, &g.Name, &g.Description, &g.JoinPolicy, &g.Level, &g.XP,
+				&g.MemberCount, &g.Leader, &g.CreatedAt) == nil {
+				guilds = append(guilds, g)
+			}
+		}
+	}
+
+	totalPages := (totalCount + limit - 1) / limit
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	return h.render(c, "guilds.html", fiber.Map{
+		"Title":      "길드 관리",
+		"Username":   username,
+		"Guilds":     guilds,
+		"TotalCount": totalCount,
+		"Search":     search,
+		"Page":       page,
+		"TotalPages": totalPages,
+		"HasPrev":    page > 1,
+		"HasNext":    page < totalPages,
+		"PrevPage":   page - 1,
+		"NextPage":   page + 1,
+		"Message":    message,
+	})
+}
+
+// GuildDetail shows a guild's members, boss, damage table and recent chat
+func (h *AdminHandler) GuildDetail(c *fiber.Ctx) error {
+	ctx := c.Context()
+	username := c.Locals("admin_username").(string)
+	guildID := c.Params("id")
+	message := c.Query("msg")
+
+	var g GuildRow
+	err := h.pool.QueryRow(ctx, guildSelect+` WHERE g.id = $1`, guildID).Scan(
+		&g.ID, &g.Name, &g.Description, &g.JoinPolicy, &g.Level, &g.XP,
+		&g.MemberCount, &g.Leader, &g.CreatedAt)
+	if err != nil {
+		return c.Redirect("/admin/guilds?msg=not_found")
+	}
+
+	var members []GuildMemberRow
+	memRows, err := h.pool.Query(ctx,
+		`SELECT gm.user_id::text, u.nickname, gm.role, gm.contributed_xp, gm.joined_at
+		 FROM guild_members gm JOIN users u ON u.id = gm.user_id
+		 WHERE gm.guild_id = $1
+		 ORDER BY CASE gm.role WHEN 'leader' THEN 0 WHEN 'officer' THEN 1 ELSE 2 END, gm.contributed_xp DESC`,
+		guildID,
+	)
+	if err == nil {
+		defer memRows.Close()
+		for memRows.Next() {
+			var m GuildMemberRow
+			if memRows.Scan(&m.UserID, &m.Nickname, &m.Role, &m.ContributedXP, &m.JoinedAt) == nil {
+				members = append(members, m)
+			}
+		}
+	}
+
+	var boss *GuildBossRow
+	var attackRanking []BossAttackRank
+	var b GuildBossRow
+	err = h.pool.QueryRow(ctx,
+		`SELECT id, stage, name, element, max_hp, current_hp, expires_at, defeated_at IS NOT NULL
+		 FROM guild_boss WHERE guild_id = $1 ORDER BY id DESC LIMIT 1`,
+		guildID,
+	).Scan(&b.ID, &b.Stage, &b.Name, &b.Element, &b.MaxHP, &b.CurrentHP, &b.ExpiresAt, &b.Defeated)
+	if err == nil {
+		boss = &b
+		rankRows, err := h.pool.Query(ctx,
+			`SELECT u.nickname, SUM(a.damage) as total_dmg
+			 FROM guild_boss_attacks a JOIN users u ON u.id = a.user_id
+			 WHERE a.boss_id = $1
+			 GROUP BY u.nickname ORDER BY total_dmg DESC`,
+			b.ID,
+		)
+		if err == nil {
+			defer rankRows.Close()
+			rank := 1
+			for rankRows.Next() {
+				var r BossAttackRank
+				r.Rank = rank
+				if rankRows.Scan(&r.Nickname, &r.Damage) == nil {
+					attackRanking = append(attackRanking, r)
+					rank++
+				}
+			}
+		}
+	}
+
+	var messages []GuildMessageRow
+	msgRows, err := h.pool.Query(ctx,
+		`SELECT m.id, u.nickname, m.message, m.created_at
+		 FROM guild_messages m JOIN users u ON u.id = m.user_id
+		 WHERE m.guild_id = $1 ORDER BY m.id DESC LIMIT 50`,
+		guildID,
+	)
+	if err == nil {
+		defer msgRows.Close()
+		for msgRows.Next() {
+			var m GuildMessageRow
+			if msgRows.Scan(&m.ID, &m.Nickname, &m.Message, &m.CreatedAt) == nil {
+				messages = append(messages, m)
+			}
+		}
+	}
+
+	return h.render(c, "guild_detail.html", fiber.Map{
+		"Title":         "길드 상세",
+		"Username":      username,
+		"Guild":         g,
+		"Members":       members,
+		"Boss":          boss,
+		"AttackRanking": attackRanking,
+		"Messages":      messages,
+		"Message":       message,
+	})
+}
+
+// GuildEdit renames a guild or changes its description and join policy
+func (h *AdminHandler) GuildEdit(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+	guildID := c.Params("id")
+
+	name := strings.TrimSpace(c.FormValue("name"))
+	description := strings.TrimSpace(c.FormValue("description"))
+	joinPolicy := c.FormValue("join_policy")
+
+	if n := utf8.RuneCountInString(name); n < 2 || n > 12 || utf8.RuneCountInString(description) > 200 {
+		return c.Redirect("/admin/guilds/" + guildID + "?msg=invalid")
+	}
+	switch joinPolicy {
+	case "open", "apply", "invite":
+	default:
+		return c.Redirect("/admin/guilds/" + guildID + "?msg=invalid")
+	}
+
+	_, err := h.pool.Exec(ctx,
+		`UPDATE guilds SET name = $2, description = $3, join_policy = $4, updated_at = NOW() WHERE id = $1`,
+		guildID, name, description, joinPolicy,
+	)
+	if repository.IsUniqueViolation(err) {
+		return c.Redirect("/admin/guilds/" + guildID + "?msg=name_taken")
+	}
+	if err != nil {
+		log.Error().Err(err).Str("guild_id", guildID).Msg("Failed to edit guild")
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to update guild")
+	}
+
+	detail := fmt.Sprintf("name:%s policy:%s", name, joinPolicy)
+	logAdminAction(h.pool, ctx, adminID, adminUsername, "edit_guild", "guild", guildID, detail)
+
+	return c.Redirect("/admin/guilds/" + guildID + "?msg=updated")
+}
+
+// GuildDisband deletes a guild with all its members, requests, chat and bosses
+func (h *AdminHandler) GuildDisband(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+	guildID := c.Params("id")
+
+	var name string
+	err := h.pool.QueryRow(ctx, `DELETE FROM guilds WHERE id = $1 RETURNING name`, guildID).Scan(&name)
+	if err != nil {
+		return c.Redirect("/admin/guilds?msg=not_found")
+	}
+	logAdminAction(h.pool, ctx, adminID, adminUsername, "disband_guild", "guild", guildID, "name:"+name)
+
+	return c.Redirect("/admin/guilds?msg=disbanded")
+}
+
+// GuildKickMember removes a member. Kicking the leader hands leadership over the same
+// way as leaving does.
+func (h *AdminHandler) GuildKickMember(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+	guildID := c.Params("id")
+	userID := c.Params("user_id")
+
+	var memberGuild string
+	if err := h.pool.QueryRow(ctx, `SELECT guild_id::text FROM guild_members WHERE user_id = $1`, userID).Scan(&memberGuild); err != nil || memberGuild != guildID {
+		return c.Redirect("/admin/guilds/" + guildID + "?msg=not_member")
+	}
+
+	disbanded, err := repository.NewGuildRepository(h.pool).Leave(ctx, userID)
+	if err != nil {
+		return c.Redirect("/admin/guilds/" + guildID + "?msg=error")
+	}
+	logAdminAction(h.pool, ctx, adminID, adminUsername, "kick_guild_member", "guild", guildID, "user:"+userID)
+
+	if disbanded {
+		return c.Redirect("/admin/guilds?msg=disbanded")
+	}
+	return c.Redirect("/admin/guilds/" + guildID + "?msg=kicked")
+}
+
+// GuildDeleteMessage removes a guild chat message
+func (h *AdminHandler) GuildDeleteMessage(c *fiber.Ctx) error {
+	ctx := c.Context()
+	adminUsername := c.Locals("admin_username").(string)
+	adminID := c.Locals("admin_id").(int)
+	guildID := c.Params("id")
+	msgID := c.Params("msg_id")
+
+	var text string
+	err := h.pool.QueryRow(ctx,
+		`DELETE FROM guild_messages WHERE id = $1 AND guild_id = $2 RETURNING message`,
+		msgID, guildID,
+	).Scan(&text)
+	if err == nil {
+		logAdminAction(h.pool, ctx, adminID, adminUsername, "delete_guild_message", "guild", guildID, "message:"+text)
+	}
+
+	return c.Redirect("/admin/guilds/" + guildID + "?msg=message_deleted")
+}
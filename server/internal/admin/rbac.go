@@ -0,0 +1,159 @@
+package admin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Admin roles stored in admin_users.role
+const (
+	RoleSuperAdmin = "superadmin"
+	RoleOperator   = "operator"
+	RoleModerator  = "moderator"
+	RoleCS         = "cs"
+)
+
+// AdminRoles lists the assignable roles in display order.
+var AdminRoles = []string{RoleSuperAdmin, RoleOperator, RoleModerator, RoleCS}
+
+var roleLabels = map[string]string{
+	RoleSuperAdmin: "슈퍼 관리자",
+	RoleOperator:   "운영자",
+	RoleModerator:  "모더레이터",
+	RoleCS:         "고객지원",
+}
+
+// Permission is checked per route by Require.
+type Permission string
+
+const (
+	PermDashboard     Permission = "dashboard.view"
+	PermUsersView     Permission = "users.view"
+	PermUsersEdit     Permission = "users.edit"
+	PermUsersBan      Permission = "users.ban"
+	PermSlimesEdit    Permission = "slimes.edit"
+	PermGameDataView  Permission = "gamedata.view"
+	PermGameDataEdit  Permission = "gamedata.edit"
+	PermShopEdit      Permission = "shop.edit"
+	PermAnnouncements Permission = "announcements.manage"
+	PermMailSend      Permission = "mail.send"
+	PermBossManage    Permission = "boss.manage"
+	PermGuildsManage  Permission = "guilds.manage"
+	PermModeration    Permission = "moderation.manage"
+	PermLogsView      Permission = "logs.view"
+	PermSupport       Permission = "support.manage"
+	PermRevenueView   Permission = "revenue.view"
+	PermAuditView     Permission = "audit.view"
+	PermBotsManage    Permission = "bots.manage"
+	PermAdminsManage  Permission = "admins.manage"
+)
+
+var allPermissions = []Permission{
+	PermDashboard, PermUsersView, PermUsersEdit, PermUsersBan, PermSlimesEdit,
+	PermGameDataView, PermGameDataEdit, PermShopEdit, PermAnnouncements, PermMailSend,
+	PermBossManage, PermGuildsManage, PermModeration, PermLogsView, PermSupport,
+	PermRevenueView, PermAuditView, PermBotsManage, PermAdminsManage,
+}
+
+func permSet(perms ...Permission) map[Permission]bool {
+	m := make(map[Permission]bool, len(perms))
+	for _, p := range perms {
+		m[p] = true
+	}
+	return m
+}
+
+// rolePermissions is the role → permission matrix. Super admins bypass it entirely.
+var rolePermissions = map[string]map[Permission]bool{
+	RoleOperator: permSet(
+		PermDashboard, PermUsersView, PermUsersEdit, PermUsersBan, PermSlimesEdit,
+		PermGameDataView, PermGameDataEdit, PermShopEdit, PermAnnouncements, PermMailSend,
+		PermBossManage, PermGuildsManage, PermModeration, PermLogsView, PermSupport,
+		PermRevenueView, PermAuditView, PermBotsManage,
+	),
+	RoleModerator: permSet(
+		PermDashboard, PermUsersView, PermUsersBan, PermGameDataView,
+		PermAnnouncements, PermGuildsManage, PermModeration, PermLogsView,
+	),
+	RoleCS: permSet(
+		PermDashboard, PermUsersView, PermGameDataView, PermMailSend,
+		PermLogsView, PermSupport,
+	),
+}
+
+// HasPermission reports whether role grants perm.
+func HasPermission(role string, perm Permission) bool {
+	if role == RoleSuperAdmin {
+		return true
+	}
+	return rolePermissions[role][perm]
+}
+
+func validRole(role string) bool {
+	_, ok := roleLabels[role]
+	return ok
+}
+
+// Require rejects the request unless the session's role grants perm.
+// Denied attempts are written to the audit log.
+func (h *AdminHandler) Require(perm Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("admin_role").(string)
+		if HasPermission(role, perm) {
+			return c.Next()
+		}
+		adminID, _ := c.Locals("admin_id").(int)
+		username, _ := c.Locals("admin_username").(string)
+		logAdminAction(h.pool, c.Context(), adminID, username, "permission_denied", "route", c.Method()+" "+c.Path(),
+			fmt.Sprintf("role:%s perm:%s", role, perm))
+		return c.Status(fiber.StatusForbidden).SendString("권한이 없습니다 (" + string(perm) + ")")
+	}
+}
+
+// grantedPerms returns the session's permissions keyed by name, for the layout to hide
+// navigation the role cannot use.
+func grantedPerms(role string) map[string]bool {
+	granted := make(map[string]bool)
+	for _, perm := range allPermissions {
+		if HasPermission(role, perm) {
+			granted[string(perm)] = true
+		}
+	}
+	return granted
+}
+
+// toolAction wraps a game-package JSON handler mounted under /admin. Every call is written
+// to the audit log with its status and response. Form posts from the tools page are
+// redirected back to it; other callers get the handler's JSON unchanged.
+func (h *AdminHandler) toolAction(action string, next fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := next(c)
+		adminID, _ := c.Locals("admin_id").(int)
+		username, _ := c.Locals("admin_username").(string)
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		detail := fmt.Sprintf("status:%d %s", status, truncateBytes(c.Response().Body(), 200))
+		logAdminAction(h.pool, c.Context(), adminID, username, action, "tool", c.FormValue("user_id"), detail)
+
+		if strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMETextHTML) {
+			c.Response().ResetBody()
+			msg := "done"
+			if err != nil || status >= fiber.StatusBadRequest {
+				msg = "error"
+			}
+			return c.Redirect("/admin/tools?msg=" + msg + "&action=" + action)
+		}
+		return err
+	}
+}
+
+func truncateBytes(b []byte, n int) string {
+	if len(b) > n {
+		return string(b[:n]) + "..."
+	}
+	return string(b)
+}
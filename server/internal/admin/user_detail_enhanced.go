@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 type UserDetailSlime struct {
@@ -175,22 +178,16 @@ func (h *AdminHandler) EditUser(c *fiber.Ctx) error {
 		return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=no_change", userID))
 	}
 
-	if level > 0 {
-		_, err := h.pool.Exec(ctx,
-			`UPDATE users SET gold = gold + $1, gems = gems + $2, stardust = stardust + $3, level = $4, updated_at = NOW() WHERE id = $5`,
-			gold, gems, stardust, level, userID,
-		)
-		if err != nil {
-			return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=error", userID))
-		}
-	} else {
-		_, err := h.pool.Exec(ctx,
-			`UPDATE users SET gold = gold + $1, gems = gems + $2, stardust = stardust + $3, updated_at = NOW() WHERE id = $4`,
-			gold, gems, stardust, userID,
-		)
-		if err != nil {
-			return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=error", userID))
+	err := pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		if level > 0 {
+			if _, err := tx.Exec(ctx, `UPDATE users SET level = $1, updated_at = NOW() WHERE id = $2`, level, userID); err != nil {
+				return err
+			}
 		}
+		return repository.ApplyCurrencyTx(ctx, tx, userID, gold, gems, stardust, repository.ReasonAdminGrant, adminUsername, false)
+	})
+	if err != nil {
+		return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=error", userID))
 	}
 
 	detail := fmt.Sprintf("gold:%+d gems:%+d stardust:%+d level:%d", gold, gems, stardust, level)
@@ -235,6 +232,11 @@ func (h *AdminHandler) BanUser(c *fiber.Ctx) error {
 		return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=ban_error", userID))
 	}
 
+	// Kick the player out now rather than when their access token expires
+	if err := h.denylist.Ban(ctx, userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Failed to revoke tokens for banned user")
+	}
+
 	detail := fmt.Sprintf("reason:%s duration:%s", reason, duration)
 	logAdminAction(h.pool, ctx, adminID, adminUsername, "ban_user", "user", userID, detail)
 
@@ -255,6 +257,7 @@ func (h *AdminHandler) UnbanUser(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=unban_error", userID))
 	}
+	h.denylist.Unban(ctx, userID)
 
 	logAdminAction(h.pool, ctx, adminID, adminUsername, "unban_user", "user", userID, "")
 
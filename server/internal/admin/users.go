@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 type UserRow struct {
@@ -154,10 +156,10 @@ func (h *AdminHandler) GiveUserCurrency(c *fiber.Ctx) error {
 		return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=no_amount", userID))
 	}
 
-	_, err := h.pool.Exec(ctx,
-		`UPDATE users SET gold = gold + $1, gems = gems + $2, updated_at = NOW() WHERE id = $3`,
-		gold, gems, userID,
-	)
+	adminUsername, _ := c.Locals("admin_username").(string)
+	err := pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		return repository.ApplyCurrencyTx(ctx, tx, userID, gold, gems, 0, repository.ReasonAdminGrant, adminUsername, false)
+	})
 	if err != nil {
 		return c.Redirect(fmt.Sprintf("/admin/users/%s?msg=error", userID))
 	}
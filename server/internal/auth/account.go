@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// accountDeletionGrace is how long a deletion request can be cancelled before the
+// account is purged.
+const accountDeletionGrace = 14 * 24 * time.Hour
+
+// uploadsRoot is where /uploads/... URLs are served from (see app.Static in main).
+const uploadsRoot = "./uploads"
+
+// GET /api/user/export — everything stored about the current user. ?format=zip returns
+// an archive with one JSON file per section plus the user's uploaded files.
+func (h *UserHandler) ExportData(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	sections, err := h.accountRepo.Export(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+		}
+		log.Error().Err(err).Str("user_id", userID).Msg("Data export failed")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to export data"})
+	}
+	exportedAt := time.Now().UTC()
+	log.Info().Str("user_id", userID).Str("format", c.Query("format", "json")).Msg("Personal data exported")
+
+	if c.Query("format") != "zip" {
+		out := make(map[string]interface{}, len(sections)+2)
+		out["user_id"] = userID
+		out["exported_at"] = exportedAt
+		for _, s := range sections {
+			out[s.Name] = s.Data
+		}
+		c.Set(fiber.HeaderContentDisposition, `attachment; filename="slimetopia-export.json"`)
+		return c.JSON(out)
+	}
+
+	files, err := h.accountRepo.UploadedFiles(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Listing uploads for export failed")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to export data"})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="slimetopia-export.zip"`)
+	// Videos can be large; stream the archive instead of building it in memory
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := writeExportZip(w, userID, exportedAt, sections, files); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Writing export archive failed")
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+func writeExportZip(w io.Writer, userID string, exportedAt time.Time, sections []repository.ExportSection, files []string) error {
+	zw := zip.NewWriter(w)
+
+	manifest, _ := json.MarshalIndent(fiber.Map{"user_id": userID, "exported_at": exportedAt, "uploads": files}, "", "  ")
+	if err := writeZipFile(zw, "manifest.json", exportedAt, manifest); err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if err := writeZipFile(zw, s.Name+".json", exportedAt, s.Data); err != nil {
+			return err
+		}
+	}
+	for _, url := range files {
+		p, ok := uploadPath(url)
+		if !ok {
+			continue
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			// Already removed (e.g. moderated); the manifest still lists it
+			continue
+		}
+		dst, err := zw.CreateHeader(&zip.FileHeader{Name: strings.TrimPrefix(url, "/"), Method: zip.Store, Modified: exportedAt})
+		if err == nil {
+			_, err = io.Copy(dst, f)
+		}
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, modified time.Time, data []byte) error {
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(data)
+	return err
+}
+
+// uploadPath maps an /uploads/... URL to its file under uploadsRoot, refusing anything
+// that would escape it.
+func uploadPath(url string) (string, bool) {
+	rel := path.Clean(strings.TrimPrefix(url, "/uploads/"))
+	if !strings.HasPrefix(url, "/uploads/") || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return uploadsRoot + "/" + rel, true
+}
+
+// POST /api/user/delete — schedules the account for deletion after the grace period.
+// Accounts with a password must confirm it.
+func (h *UserHandler) RequestDeletion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	var body struct {
+		Password string `json:"password"`
+		Confirm  bool   `json:"confirm"`
+	}
+	if err := c.BodyParser(&body); err != nil || !body.Confirm {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "confirm required"})
+	}
+
+	hash, err := h.userRepo.GetPasswordHash(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to schedule deletion"})
+	}
+	if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(body.Password)) != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "incorrect password"})
+	}
+
+	scheduled, err := h.accountRepo.ScheduleDeletion(ctx, userID, time.Now().Add(accountDeletionGrace))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to schedule deletion"})
+	}
+
+	log.Info().Str("user_id", userID).Time("deletion_scheduled_at", scheduled).Msg("Account deletion requested")
+	return c.JSON(fiber.Map{"success": true, "deletion_scheduled_at": scheduled})
+}
+
+// POST /api/user/delete/cancel
+func (h *UserHandler) CancelDeletion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if err := h.accountRepo.CancelDeletion(c.Context(), userID); err != nil {
+		if errors.Is(err, repository.ErrNoDeletionScheduled) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no deletion scheduled"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to cancel deletion"})
+	}
+
+	log.Info().Str("user_id", userID).Msg("Account deletion cancelled")
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// AccountPurger deletes accounts whose deletion grace period has ended, along with
+// their uploaded files. The job scheduler runs PurgeDue.
+type AccountPurger struct {
+	accountRepo *repository.AccountRepository
+	denylist    *Denylist
+}
+
+// NewAccountPurger creates a purger.
+func NewAccountPurger(accountRepo *repository.AccountRepository, denylist *Denylist) *AccountPurger {
+	return &AccountPurger{accountRepo: accountRepo, denylist: denylist}
+}
+
+// PurgeDue deletes every account that is due, a batch at a time.
+func (p *AccountPurger) PurgeDue(ctx context.Context) error {
+	ids, err := p.accountRepo.DueDeletions(ctx, 100)
+	if err != nil {
+		return fmt.Errorf("list due deletions: %w", err)
+	}
+	purged := 0
+	for _, id := range ids {
+		if err := p.purge(ctx, id); err != nil {
+			if !errors.Is(err, repository.ErrNoDeletionScheduled) {
+				log.Error().Err(err).Str("user_id", id).Msg("[AccountPurger] purge failed")
+			}
+			continue
+		}
+		purged++
+	}
+	if len(ids) > 0 {
+		log.Info().Int("purged", purged).Int("due", len(ids)).Msg("[AccountPurger] pass complete")
+	}
+	return nil
+}
+
+func (p *AccountPurger) purge(ctx context.Context, userID string) error {
+	files, err := p.accountRepo.UploadedFiles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := p.accountRepo.Purge(ctx, userID); err != nil {
+		return err
+	}
+	// Tokens outlive the row; make sure none of them work any more
+	if err := p.denylist.RevokeUser(ctx, userID); err != nil {
+		log.Warn().Err(err).Str("user_id", userID).Msg("[AccountPurger] failed to revoke tokens")
+	}
+	for _, url := range files {
+		if fp, ok := uploadPath(url); ok {
+			if err := os.Remove(fp); err != nil && !os.IsNotExist(err) {
+				log.Warn().Err(err).Str("file", fp).Msg("[AccountPurger] failed to remove upload")
+			}
+		}
+	}
+	log.Info().Str("user_id", userID).Int("files", len(files)).Msg("[AccountPurger] account purged")
+	return nil
+}
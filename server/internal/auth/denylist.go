@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// banCacheTTL bounds how long a ban (or its absence) is served from Redis before
+// the users row is consulted again. Ban/unban from the admin panel invalidates it directly.
+const banCacheTTL = 5 * time.Minute
+
+// Denylist tracks revoked tokens and cached ban state in Redis.
+//
+//	revoked_jti:<jti>      single revoked token, expires with the token
+//	revoked_legacy:<hash>  pre-session refresh token (no jti), by SHA-256 of the raw token
+//	revoked_user:<userID>  unix time; every token issued at or before it is revoked
+//	revoked_session:<sid>  every access token of a revoked session, until they have all expired
+//	ban:<userID>           cached ban state ("none" or BanInfo JSON)
+type Denylist struct {
+	rdb      *redis.Client
+	userRepo *repository.UserRepository
+}
+
+func NewDenylist(rdb *redis.Client, userRepo *repository.UserRepository) *Denylist {
+	return &Denylist{rdb: rdb, userRepo: userRepo}
+}
+
+// RevokeToken denylists a single token by jti until it would have expired anyway.
+func (d *Denylist) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.rdb.Set(ctx, "revoked_jti:"+jti, 1, ttl).Err()
+}
+
+// RetireLegacyToken denylists a refresh token from before sessions were tracked.
+// Those carry no jti, so the token is keyed by its hash. Returns false if it had
+// already been retired, so each one is exchanged at most once.
+func (d *Denylist) RetireLegacyToken(ctx context.Context, token string, expiresAt time.Time) (bool, error) {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+	sum := sha256.Sum256([]byte(token))
+	return d.rdb.SetNX(ctx, "revoked_legacy:"+hex.EncodeToString(sum[:]), 1, ttl).Result()
+}
+
+// RevokeUser invalidates every access and refresh token issued to the user so far.
+func (d *Denylist) RevokeUser(ctx context.Context, userID string) error {
+	return d.rdb.Set(ctx, "revoked_user:"+userID, time.Now().Unix(), refreshTokenExpiry).Err()
+}
+
+// RevokeSession invalidates the outstanding access tokens of a session. Its refresh
+// token is already dead once the user_sessions row is revoked.
+func (d *Denylist) RevokeSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return d.rdb.Set(ctx, "revoked_session:"+sessionID, 1, accessTokenExpiry).Err()
+}
+
+// IsRevoked reports whether the token was revoked individually, with its session,
+// or by a user-wide revocation.
+func (d *Denylist) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if claims.ID != "" {
+		n, err := d.rdb.Exists(ctx, "revoked_jti:"+claims.ID).Result()
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+
+	if claims.SessionID != "" {
+		n, err := d.rdb.Exists(ctx, "revoked_session:"+claims.SessionID).Result()
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+
+	cutoff, err := d.rdb.Get(ctx, "revoked_user:"+claims.UserID).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	cutoffUnix, _ := strconv.ParseInt(cutoff, 10, 64)
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= cutoffUnix, nil
+}
+
+// ActiveBan returns the user's ban if one is in force, or nil.
+func (d *Denylist) ActiveBan(ctx context.Context, userID string) (*models.BanInfo, error) {
+	key := "ban:" + userID
+	cached, err := d.rdb.Get(ctx, key).Result()
+	if err == nil {
+		if cached == "none" {
+			return nil, nil
+		}
+		var ban models.BanInfo
+		if json.Unmarshal([]byte(cached), &ban) == nil {
+			if ban.ExpiresAt != nil && ban.ExpiresAt.Before(time.Now()) {
+				return nil, nil
+			}
+			return &ban, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		log.Warn().Err(err).Msg("Ban cache unavailable, reading from DB")
+	}
+
+	ban, err := d.userRepo.GetActiveBan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	val := "none"
+	if ban != nil {
+		b, _ := json.Marshal(ban)
+		val = string(b)
+	}
+	d.rdb.Set(ctx, key, val, banCacheTTL)
+	return ban, nil
+}
+
+// Ban revokes all of the user's tokens and drops the cached ban state so the
+// next request sees the ban immediately.
+func (d *Denylist) Ban(ctx context.Context, userID string) error {
+	if err := d.RevokeUser(ctx, userID); err != nil {
+		return err
+	}
+	return d.rdb.Del(ctx, "ban:"+userID).Err()
+}
+
+// Unban drops the cached ban state. Tokens revoked by the ban stay revoked; the
+// player simply logs in again.
+func (d *Denylist) Unban(ctx context.Context, userID string) error {
+	return d.rdb.Del(ctx, "ban:"+userID).Err()
+}
+
+// BanResponse writes the structured 403 payload clients use to show the ban screen.
+func BanResponse(c *fiber.Ctx, ban *models.BanInfo) error {
+	message := "영구 정지된 계정입니다"
+	if !ban.Permanent {
+		message = fmt.Sprintf("%s까지 정지된 계정입니다", ban.ExpiresAt.Format("2006-01-02 15:04"))
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":   "account_banned",
+		"message": message,
+		"ban":     ban,
+	})
+}
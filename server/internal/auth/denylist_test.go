@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/slimetopia/server/internal/testenv"
+)
+
+func TestRetireLegacyTokenOnce(t *testing.T) {
+	d := NewDenylist(testenv.Redis(t), nil)
+	ctx := context.Background()
+	exp := time.Now().Add(time.Hour)
+
+	for i, want := range []bool{true, false, false} {
+		got, err := d.RetireLegacyToken(ctx, "legacy-token", exp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("exchange %d = %v, want %v", i+1, got, want)
+		}
+	}
+	if got, _ := d.RetireLegacyToken(ctx, "other-token", exp); !got {
+		t.Error("another legacy token was refused")
+	}
+	if got, _ := d.RetireLegacyToken(ctx, "expired-token", time.Now().Add(-time.Minute)); got {
+		t.Error("an expired token was exchanged")
+	}
+}
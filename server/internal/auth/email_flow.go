@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/mail"
+	"github.com/slimetopia/server/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const mailSendTimeout = 30 * time.Second
+
+// sendVerificationEmail mails a verification link in the background so a slow relay
+// doesn't hold up the request.
+func sendVerificationEmail(mailer mail.Mailer, jwtManager *JWTManager, userID, email string) {
+	token, err := jwtManager.GenerateEmailToken(PurposeVerifyEmail, userID, email, "")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to sign verification token")
+		return
+	}
+	link := frontendBaseURL() + "/auth/verify-email?token=" + url.QueryEscape(token)
+	sendAsync(mailer, mail.Message{
+		To:      email,
+		Subject: "[SlimeTopia] 이메일 인증",
+		Body: fmt.Sprintf("아래 링크를 눌러 이메일 인증을 완료해주세요. (24시간 동안 유효)\n\n%s\n\n"+
+			"본인이 요청하지 않았다면 이 메일을 무시하세요.\n", link),
+	})
+}
+
+func sendPasswordResetEmail(mailer mail.Mailer, jwtManager *JWTManager, userID, email, passwordHash string) {
+	token, err := jwtManager.GenerateEmailToken(PurposeResetPassword, userID, email, passwordBinding(passwordHash))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to sign password reset token")
+		return
+	}
+	link := frontendBaseURL() + "/auth/reset-password?token=" + url.QueryEscape(token)
+	sendAsync(mailer, mail.Message{
+		To:      email,
+		Subject: "[SlimeTopia] 비밀번호 재설정",
+		Body: fmt.Sprintf("아래 링크에서 새 비밀번호를 설정해주세요. (30분 동안 유효)\n\n%s\n\n"+
+			"본인이 요청하지 않았다면 이 메일을 무시하세요. 비밀번호는 변경되지 않습니다.\n", link),
+	})
+}
+
+func sendAsync(mailer mail.Mailer, msg mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := mailer.Send(ctx, msg); err != nil {
+			log.Error().Err(err).Str("subject", msg.Subject).Msg("Failed to send mail")
+		}
+	}()
+}
+
+// POST /api/auth/verify-email
+func (h *Handler) VerifyEmail(c *fiber.Ctx) error {
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token required"})
+	}
+
+	claims, err := h.jwtManager.ValidateEmailToken(body.Token, PurposeVerifyEmail)
+	if err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token_expired"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_token"})
+	}
+
+	if err := h.userRepo.MarkEmailVerified(c.Context(), claims.Subject, claims.Email); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			// The account's email changed after this link was sent
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_token"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to verify email"})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "email": claims.Email})
+}
+
+// POST /api/auth/forgot-password — always answers success so it can't be used to
+// probe which emails have accounts
+func (h *Handler) ForgotPassword(c *fiber.Ctx) error {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+	}
+	body.Email = strings.TrimSpace(strings.ToLower(body.Email))
+	if body.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email required"})
+	}
+
+	ctx := c.Context()
+	if wait := h.limiter.hit(ctx, "rl:recovery:ip:"+c.IP(), maxRecoveryMailsPerIP, recoveryWindow); wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+	if wait := h.limiter.hit(ctx, "rl:recovery:email:"+body.Email, maxRecoveryMailsPerAddr, recoveryWindow); wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
+	user, err := h.userRepo.FindByEmail(ctx, body.Email)
+	if err == nil && user.PasswordHash != "" {
+		sendPasswordResetEmail(h.mailer, h.jwtManager, uuidToString(user.ID), user.Email, user.PasswordHash)
+	} else if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		log.Error().Err(err).Msg("Password reset lookup failed")
+	}
+
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// POST /api/auth/reset-password — sets a new password from a reset link and signs out
+// every session
+func (h *Handler) ResetPassword(c *fiber.Ctx) error {
+	var body struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token required"})
+	}
+	if len(body.Password) < 6 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "password too short (min 6)"})
+	}
+
+	claims, err := h.jwtManager.ValidateEmailToken(body.Token, PurposeResetPassword)
+	if err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token_expired"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_token"})
+	}
+
+	ctx := c.Context()
+	user, err := h.userRepo.FindByEmail(ctx, claims.Email)
+	if err != nil || uuidToString(user.ID) != claims.Subject || passwordBinding(user.PasswordHash) != claims.Binding {
+		// Account changed, or the password was already reset with this link
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid_token"})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to hash password"})
+	}
+	if err := h.userRepo.ChangePassword(ctx, claims.Subject, string(hash)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to reset password"})
+	}
+	// Following the emailed link proves the address too
+	h.userRepo.MarkEmailVerified(ctx, claims.Subject, claims.Email)
+
+	if ids, err := h.sessionRepo.RevokeAll(ctx, claims.Subject, "", repository.SessionRevokedPasswordReset); err == nil {
+		for _, id := range ids {
+			h.denylist.RevokeSession(ctx, id)
+		}
+	}
+	if err := h.denylist.RevokeUser(ctx, claims.Subject); err != nil {
+		log.Warn().Err(err).Str("user_id", claims.Subject).Msg("Failed to revoke tokens after password reset")
+	}
+	h.limiter.reset(ctx, "rl:login:email:"+claims.Email)
+
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// POST /api/user/email/verify — resend the verification mail for the current account
+func (h *Handler) ResendVerification(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+	}
+	if user.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no email on this account"})
+	}
+	if user.EmailVerified {
+		return c.JSON(fiber.Map{"success": true, "already_verified": true})
+	}
+	if wait := h.limiter.hit(ctx, "rl:recovery:email:"+user.Email, maxRecoveryMailsPerAddr, recoveryWindow); wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
+	sendVerificationEmail(h.mailer, h.jwtManager, userID, user.Email)
+	return c.JSON(fiber.Map{"success": true})
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Email token purposes. A token for one purpose is never accepted for the other.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+const (
+	verifyEmailTokenExpiry   = 24 * time.Hour
+	resetPasswordTokenExpiry = 30 * time.Minute
+)
+
+// EmailClaims are carried by the links sent in verification and reset mails.
+type EmailClaims struct {
+	Purpose string `json:"purpose"`
+	Email   string `json:"email"`
+	// Binding is a fingerprint of the state the token acts on (the current password
+	// hash for resets), so a token stops working once it has been used.
+	Binding string `json:"bnd,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// emailKey derives a separate signing key so email tokens can never pass as
+// access or refresh tokens, which are signed with the raw secret.
+func (j *JWTManager) emailKey() []byte {
+	sum := sha256.Sum256(append([]byte("email-token:"), j.secret...))
+	return sum[:]
+}
+
+// GenerateEmailToken signs a token for purpose. binding may be empty.
+func (j *JWTManager) GenerateEmailToken(purpose, userID, email, binding string) (string, error) {
+	expiry := verifyEmailTokenExpiry
+	if purpose == PurposeResetPassword {
+		expiry = resetPasswordTokenExpiry
+	}
+	now := time.Now()
+	claims := &EmailClaims{
+		Purpose: purpose,
+		Email:   email,
+		Binding: binding,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "slimetopia",
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.emailKey())
+}
+
+// ValidateEmailToken verifies signature, expiry and purpose.
+func (j *JWTManager) ValidateEmailToken(tokenStr, purpose string) (*EmailClaims, error) {
+	claims := &EmailClaims{}
+	_, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		return j.emailKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+	if claims.Purpose != purpose || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// passwordBinding fingerprints a password hash for EmailClaims.Binding.
+func passwordBinding(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
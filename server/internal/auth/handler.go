@@ -1,8 +1,11 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -14,41 +17,54 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	"github.com/slimetopia/server/internal/game"
+	"github.com/slimetopia/server/internal/mail"
 	"github.com/slimetopia/server/internal/repository"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Handler struct {
-	userRepo   *repository.UserRepository
-	slimeRepo  *repository.SlimeRepository
-	jwtManager *JWTManager
-	rdb        *redis.Client
-	providers  map[string]*OAuthProvider
+	userRepo    *repository.UserRepository
+	slimeRepo   *repository.SlimeRepository
+	sessionRepo *repository.SessionRepository
+	jwtManager  *JWTManager
+	rdb         *redis.Client
+	denylist    *Denylist
+	providers   map[string]LoginProvider
+	mailer      mail.Mailer
+	limiter     *rateLimiter
 }
 
-func NewHandler(userRepo *repository.UserRepository, slimeRepo *repository.SlimeRepository, jwtManager *JWTManager, rdb *redis.Client) *Handler {
+func NewHandler(userRepo *repository.UserRepository, slimeRepo *repository.SlimeRepository, sessionRepo *repository.SessionRepository, jwtManager *JWTManager, rdb *redis.Client, denylist *Denylist, mailer mail.Mailer) *Handler {
 	return &Handler{
-		userRepo:   userRepo,
-		slimeRepo:  slimeRepo,
-		jwtManager: jwtManager,
-		rdb:        rdb,
-		providers:  make(map[string]*OAuthProvider),
+		userRepo:    userRepo,
+		slimeRepo:   slimeRepo,
+		sessionRepo: sessionRepo,
+		jwtManager:  jwtManager,
+		rdb:         rdb,
+		denylist:    denylist,
+		providers:   make(map[string]LoginProvider),
+		mailer:      mailer,
+		limiter:     &rateLimiter{rdb: rdb},
 	}
 }
 
-func (h *Handler) RegisterProvider(provider *OAuthProvider) {
-	h.providers[provider.Name] = provider
+func (h *Handler) RegisterProvider(provider LoginProvider) {
+	h.providers[provider.ProviderName()] = provider
 }
 
 func RegisterRoutes(router fiber.Router, handler *Handler) {
 	auth := router.Group("/auth")
 	auth.Get("/login/:provider", handler.Login)
 	auth.Get("/callback/:provider", handler.Callback)
+	auth.Post("/callback/:provider", handler.Callback) // response_mode=form_post (Apple)
 	auth.Post("/refresh", handler.Refresh)
 	auth.Post("/dev-login", handler.DevLogin) // Development only
 	auth.Post("/guest-login", handler.GuestLogin)
 	auth.Post("/register", handler.Register)
 	auth.Post("/email-login", handler.EmailLogin)
+	auth.Post("/verify-email", handler.VerifyEmail)
+	auth.Post("/forgot-password", handler.ForgotPassword)
+	auth.Post("/reset-password", handler.ResetPassword)
 }
 
 // Login redirects to OAuth provider
@@ -61,12 +77,14 @@ func (h *Handler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	state := generateState()
-	// Store state in Redis (5 min expiry)
-	ctx := c.Context()
-	h.rdb.Set(ctx, "oauth_state:"+state, providerName, 5*time.Minute)
+	state, params, err := h.saveOAuthState(c.Context(), oauthState{Provider: providerName})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to start login",
+		})
+	}
 
-	return c.Redirect(provider.GetAuthURL(state))
+	return c.Redirect(provider.AuthCodeURL(state, params))
 }
 
 // Callback handles OAuth callback
@@ -74,6 +92,11 @@ func (h *Handler) Callback(c *fiber.Ctx) error {
 	providerName := c.Params("provider")
 	code := c.Query("code")
 	state := c.Query("state")
+	if code == "" && state == "" {
+		// response_mode=form_post
+		code = c.FormValue("code")
+		state = c.FormValue("state")
+	}
 
 	if code == "" || state == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -83,12 +106,13 @@ func (h *Handler) Callback(c *fiber.Ctx) error {
 
 	// Verify state
 	ctx := c.Context()
-	storedProvider, err := h.rdb.GetDel(ctx, "oauth_state:"+state).Result()
-	if err != nil || storedProvider != providerName {
+	stored, err := h.loadOAuthState(ctx, state)
+	if err != nil || stored.Provider != providerName {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "invalid state",
 		})
 	}
+	linkUserID := stored.LinkUserID
 
 	provider, ok := h.providers[providerName]
 	if !ok {
@@ -97,22 +121,17 @@ func (h *Handler) Callback(c *fiber.Ctx) error {
 		})
 	}
 
-	// Exchange code for token
-	token, err := provider.ExchangeCode(ctx, code)
+	// Exchange code and identify the user
+	userInfo, err := provider.Identify(ctx, code, stored.Params)
 	if err != nil {
-		log.Error().Err(err).Msg("OAuth token exchange failed")
+		log.Error().Err(err).Str("provider", providerName).Msg("OAuth authentication failed")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "authentication failed",
 		})
 	}
 
-	// Get user info
-	userInfo, err := provider.GetUserInfo(ctx, token.AccessToken)
-	if err != nil {
-		log.Error().Err(err).Msg("OAuth user info fetch failed")
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get user info",
-		})
+	if linkUserID != "" {
+		return h.finishLink(c, linkUserID, providerName, userInfo)
 	}
 
 	// Find or create user
@@ -126,7 +145,7 @@ func (h *Handler) Callback(c *fiber.Ctx) error {
 		// Create new user
 		nickname := userInfo.Nickname
 		if nickname == "" {
-			nickname = fmt.Sprintf("Slimer_%s", userInfo.ID[:6])
+			nickname = fmt.Sprintf("Slimer_%s", truncate(userInfo.ID, 6))
 		}
 		user, err = h.userRepo.Create(ctx, nickname, providerName, userInfo.ID)
 		if err != nil {
@@ -142,7 +161,7 @@ func (h *Handler) Callback(c *fiber.Ctx) error {
 
 	// Generate JWT
 	userIDStr := uuidToString(user.ID)
-	tokenPair, err := h.jwtManager.GenerateTokenPair(userIDStr, user.Nickname)
+	tokenPair, err := h.issueTokens(c, userIDStr, user.Nickname)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to generate token",
@@ -150,20 +169,14 @@ func (h *Handler) Callback(c *fiber.Ctx) error {
 	}
 
 	// Redirect to frontend with token
-	frontendBase := os.Getenv("FRONTEND_URL")
-	if frontendBase == "" {
-		frontendBase = os.Getenv("FRONTEND_CALLBACK_URL")
-	}
-	if frontendBase == "" {
-		frontendBase = "http://localhost:3000"
-	}
 	redirectURL := fmt.Sprintf("%s/auth/callback?access_token=%s&refresh_token=%s",
-		frontendBase, tokenPair.AccessToken, tokenPair.RefreshToken)
+		frontendBaseURL(), tokenPair.AccessToken, tokenPair.RefreshToken)
 
 	return c.Redirect(redirectURL)
 }
 
-// Refresh exchanges a refresh token for a new token pair
+// Refresh rotates the session's refresh token and returns a new token pair.
+// Presenting a refresh token that was already rotated away revokes the whole session.
 func (h *Handler) Refresh(c *fiber.Ctx) error {
 	var body struct {
 		RefreshToken string `json:"refresh_token"`
@@ -175,14 +188,22 @@ func (h *Handler) Refresh(c *fiber.Ctx) error {
 	}
 
 	claims, err := h.jwtManager.ValidateToken(body.RefreshToken)
-	if err != nil {
+	if err != nil || claims.TokenType() != TokenTypeRefresh {
+		// An access token never matches the session's refresh jti, so letting one through
+		// would look like reuse and revoke the session
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "invalid refresh token",
 		})
 	}
 
-	// Verify user still exists
 	ctx := c.Context()
+	if revoked, err := h.denylist.IsRevoked(ctx, claims); err == nil && revoked {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "refresh token revoked",
+		})
+	}
+
+	// Verify user still exists
 	user, err := h.userRepo.FindByID(ctx, claims.UserID)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -190,9 +211,62 @@ func (h *Handler) Refresh(c *fiber.Ctx) error {
 		})
 	}
 
-	userIDStr := uuidToString(user.ID)
-	tokenPair, err := h.jwtManager.GenerateTokenPair(userIDStr, user.Nickname)
+	// Banned users cannot mint new tokens
+	ban, err := h.denylist.ActiveBan(ctx, claims.UserID)
 	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to check account status",
+		})
+	}
+	if ban != nil {
+		return BanResponse(c, ban)
+	}
+
+	userIDStr := uuidToString(user.ID)
+	if claims.SessionID == "" {
+		// Refresh token from before sessions were tracked: retire it and start a session
+		expiresAt := time.Now().Add(refreshTokenExpiry)
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+		fresh, err := h.denylist.RetireLegacyToken(ctx, body.RefreshToken, expiresAt)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to check refresh token",
+			})
+		}
+		if !fresh {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "refresh token revoked",
+			})
+		}
+		tokenPair, err := h.issueTokens(c, userIDStr, user.Nickname)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to generate token",
+			})
+		}
+		return c.JSON(tokenPair)
+	}
+
+	tokenPair, err := h.rotateTokens(c, claims, user.Nickname)
+	switch {
+	case errors.Is(err, repository.ErrRefreshTokenReused):
+		log.Warn().Str("user_id", userIDStr).Str("session_id", claims.SessionID).Str("ip", c.IP()).
+			Msg("Refresh token reuse detected, session revoked")
+		h.denylist.RevokeSession(ctx, claims.SessionID)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "refresh token reused",
+		})
+	case errors.Is(err, repository.ErrRefreshTokenStale):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "refresh token already rotated",
+		})
+	case errors.Is(err, repository.ErrSessionNotFound):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "session expired",
+		})
+	case err != nil:
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to generate token",
 		})
@@ -234,7 +308,7 @@ func (h *Handler) DevLogin(c *fiber.Ctx) error {
 	}
 
 	userIDStr := uuidToString(user.ID)
-	tokenPair, err := h.jwtManager.GenerateTokenPair(userIDStr, user.Nickname)
+	tokenPair, err := h.issueTokens(c, userIDStr, user.Nickname)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to generate token",
@@ -280,7 +354,7 @@ func (h *Handler) GuestLogin(c *fiber.Ctx) error {
 	// Grant starter pack
 	game.GrantStarterPack(ctx, h.slimeRepo, h.userRepo, userIDStr)
 
-	tokenPair, err := h.jwtManager.GenerateTokenPair(userIDStr, user.Nickname)
+	tokenPair, err := h.issueTokens(c, userIDStr, user.Nickname)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate tokens for guest")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -353,20 +427,22 @@ func (h *Handler) Register(c *fiber.Ctx) error {
 	game.GrantStarterPack(ctx, h.slimeRepo, h.userRepo, userIDStr)
 
 	log.Info().Str("email", body.Email).Str("nickname", nickname).Msg("New user registered via email")
+	sendVerificationEmail(h.mailer, h.jwtManager, userIDStr, body.Email)
 
-	tokenPair, err := h.jwtManager.GenerateTokenPair(userIDStr, user.Nickname)
+	tokenPair, err := h.issueTokens(c, userIDStr, user.Nickname)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to generate token"})
 	}
 
 	return c.JSON(fiber.Map{
 		"user": fiber.Map{
-			"id":       userIDStr,
-			"nickname": nickname,
-			"gold":     user.Gold,
-			"gems":     user.Gems,
-			"stardust": user.Stardust,
-			"level":    user.Level,
+			"id":             userIDStr,
+			"nickname":       nickname,
+			"gold":           user.Gold,
+			"gems":           user.Gems,
+			"stardust":       user.Stardust,
+			"level":          user.Level,
+			"email_verified": false,
 		},
 		"tokens": tokenPair,
 	})
@@ -389,41 +465,107 @@ func (h *Handler) EmailLogin(c *fiber.Ctx) error {
 
 	ctx := c.Context()
 
+	// Every attempt counts against the IP; only failures count against the email, so
+	// someone guessing at an address can't lock its owner out for long
+	emailKey := "rl:login:email:" + body.Email
+	if wait := h.limiter.hit(ctx, "rl:login:ip:"+c.IP(), maxLoginAttemptsPerIP, loginWindow); wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+	if wait := h.limiter.blocked(ctx, emailKey, maxLoginFailuresPerEmail, loginWindow); wait > 0 {
+		return tooManyAttempts(c, wait)
+	}
+
 	user, err := h.userRepo.FindByEmail(ctx, body.Email)
 	if err != nil {
+		h.limiter.hit(ctx, emailKey, maxLoginFailuresPerEmail, loginWindow)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_credentials"})
 	}
 
 	// Compare password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(body.Password)); err != nil {
+		h.limiter.hit(ctx, emailKey, maxLoginFailuresPerEmail, loginWindow)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid_credentials"})
 	}
+	h.limiter.reset(ctx, emailKey)
 
 	userIDStr := uuidToString(user.ID)
-	tokenPair, err := h.jwtManager.GenerateTokenPair(userIDStr, user.Nickname)
+	if ban, err := h.denylist.ActiveBan(ctx, userIDStr); err == nil && ban != nil {
+		return BanResponse(c, ban)
+	}
+	tokenPair, err := h.issueTokens(c, userIDStr, user.Nickname)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to generate token"})
 	}
 
 	return c.JSON(fiber.Map{
 		"user": fiber.Map{
-			"id":       userIDStr,
-			"nickname": user.Nickname,
-			"gold":     user.Gold,
-			"gems":     user.Gems,
-			"stardust": user.Stardust,
-			"level":    user.Level,
+			"id":             userIDStr,
+			"nickname":       user.Nickname,
+			"gold":           user.Gold,
+			"gems":           user.Gems,
+			"stardust":       user.Stardust,
+			"level":          user.Level,
+			"email_verified": user.EmailVerified,
 		},
 		"tokens": tokenPair,
 	})
 }
 
+func frontendBaseURL() string {
+	frontendBase := os.Getenv("FRONTEND_URL")
+	if frontendBase == "" {
+		frontendBase = os.Getenv("FRONTEND_CALLBACK_URL")
+	}
+	if frontendBase == "" {
+		frontendBase = "http://localhost:3000"
+	}
+	return frontendBase
+}
+
 func generateState() string {
 	b := make([]byte, 16)
 	rand.Read(b)
 	return hex.EncodeToString(b)
 }
 
+// oauthState is kept in Redis under oauth_state:<state> between the redirect and the callback.
+type oauthState struct {
+	Provider   string     `json:"provider"`
+	LinkUserID string     `json:"link_user_id,omitempty"` // set when linking to an existing account
+	Params     AuthParams `json:"params"`
+}
+
+// saveOAuthState generates the state, nonce and PKCE verifier for a new authorization
+// request and stores them for 5 minutes.
+func (h *Handler) saveOAuthState(ctx context.Context, st oauthState) (string, AuthParams, error) {
+	verifier := make([]byte, 32)
+	rand.Read(verifier)
+	st.Params = AuthParams{
+		Nonce:        generateState(),
+		CodeVerifier: base64.RawURLEncoding.EncodeToString(verifier),
+	}
+
+	state := generateState()
+	raw, _ := json.Marshal(st)
+	if err := h.rdb.Set(ctx, "oauth_state:"+state, raw, 5*time.Minute).Err(); err != nil {
+		return "", AuthParams{}, err
+	}
+	return state, st.Params, nil
+}
+
+// loadOAuthState consumes a stored state; each state is usable once.
+func (h *Handler) loadOAuthState(ctx context.Context, state string) (*oauthState, error) {
+	raw, err := h.rdb.GetDel(ctx, "oauth_state:"+state).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var st oauthState
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
 func uuidToString(id pgtype.UUID) string {
 	if !id.Valid {
 		return ""
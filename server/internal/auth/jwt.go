@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -15,9 +16,38 @@ var (
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Nickname string `json:"nickname"`
+	// SessionID ties access and refresh tokens to their user_sessions row.
+	// Empty on tokens issued before sessions were tracked.
+	SessionID string `json:"sid,omitempty"`
+	// Type is TokenTypeAccess or TokenTypeRefresh. Empty on tokens issued before it was set.
+	Type string `json:"typ,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Token types, so that an access token can't be used as a refresh token or the other way round.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenType reports what the token was issued as. Tokens from before the typ claim are
+// told apart by the nickname, which only access tokens carry.
+func (c *Claims) TokenType() string {
+	switch {
+	case c.Type != "":
+		return c.Type
+	case c.Nickname != "":
+		return TokenTypeAccess
+	default:
+		return TokenTypeRefresh
+	}
+}
+
+const (
+	accessTokenExpiry  = 24 * time.Hour
+	refreshTokenExpiry = 90 * 24 * time.Hour
+)
+
 type JWTManager struct {
 	secret        []byte
 	accessExpiry  time.Duration
@@ -27,8 +57,8 @@ type JWTManager struct {
 func NewJWTManager(secret string) *JWTManager {
 	return &JWTManager{
 		secret:        []byte(secret),
-		accessExpiry:  24 * time.Hour,
-		refreshExpiry: 90 * 24 * time.Hour,
+		accessExpiry:  accessTokenExpiry,
+		refreshExpiry: refreshTokenExpiry,
 	}
 }
 
@@ -38,14 +68,19 @@ type TokenPair struct {
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
-func (j *JWTManager) GenerateTokenPair(userID, nickname string) (*TokenPair, error) {
+// GenerateTokenPair signs an access/refresh pair for a session. refreshID becomes the
+// refresh token's jti, which the session stores to detect reuse.
+func (j *JWTManager) GenerateTokenPair(userID, nickname, sessionID, refreshID string) (*TokenPair, error) {
 	now := time.Now()
 
 	// Access token
 	accessClaims := &Claims{
-		UserID:   userID,
-		Nickname: nickname,
+		UserID:    userID,
+		Nickname:  nickname,
+		SessionID: sessionID,
+		Type:      TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(j.accessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "slimetopia",
@@ -59,8 +94,11 @@ func (j *JWTManager) GenerateTokenPair(userID, nickname string) (*TokenPair, err
 
 	// Refresh token
 	refreshClaims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
+		Type:      TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        refreshID,
 			ExpiresAt: jwt.NewNumericDate(now.Add(j.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "slimetopia",
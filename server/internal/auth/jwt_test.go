@@ -0,0 +1,35 @@
+package auth
+
+import "testing"
+
+func TestTokenPairTypes(t *testing.T) {
+	j := NewJWTManager("test-secret")
+	pair, err := j.GenerateTokenPair("user-1", "Slimey", "session-1", "refresh-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	access, err := j.ValidateToken(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := access.TokenType(); got != TokenTypeAccess {
+		t.Errorf("access token type = %q, want %q", got, TokenTypeAccess)
+	}
+	refresh, err := j.ValidateToken(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := refresh.TokenType(); got != TokenTypeRefresh {
+		t.Errorf("refresh token type = %q, want %q", got, TokenTypeRefresh)
+	}
+}
+
+func TestLegacyTokenType(t *testing.T) {
+	if got := (&Claims{UserID: "u", Nickname: "Slimey"}).TokenType(); got != TokenTypeAccess {
+		t.Errorf("legacy token with a nickname = %q, want access", got)
+	}
+	if got := (&Claims{UserID: "u"}).TokenType(); got != TokenTypeRefresh {
+		t.Errorf("legacy token without a nickname = %q, want refresh", got)
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RegisterLinkRoutes mounts the account-linking endpoints. router must require auth.
+func RegisterLinkRoutes(router fiber.Router, handler *Handler) {
+	user := router.Group("/user")
+	user.Get("/identities", handler.ListIdentities)
+	user.Post("/link/email", handler.LinkEmail)
+	user.Post("/email/verify", handler.ResendVerification)
+	user.Post("/link/:provider", handler.LinkProvider)
+}
+
+// GET /api/user/identities
+func (h *Handler) ListIdentities(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	identities, err := h.userRepo.ListIdentities(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list identities"})
+	}
+	return c.JSON(fiber.Map{"identities": identities})
+}
+
+// POST /api/user/link/email — attach email/password login to the current account
+func (h *Handler) LinkEmail(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var body struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	body.Email = strings.TrimSpace(strings.ToLower(body.Email))
+	if body.Email == "" || !strings.Contains(body.Email, "@") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "valid email required"})
+	}
+	if len(body.Password) < 4 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "password must be at least 4 characters"})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to hash password"})
+	}
+
+	if err := h.userRepo.LinkEmail(c.Context(), userID, body.Email, string(hash)); err != nil {
+		return linkErrorResponse(c, "email", err)
+	}
+
+	log.Info().Str("user_id", userID).Msg("Email credentials linked")
+	sendVerificationEmail(h.mailer, h.jwtManager, userID, body.Email)
+	return c.JSON(fiber.Map{"success": true, "provider": "email"})
+}
+
+// POST /api/user/link/:provider — start an OAuth flow that links the provider to the
+// current account. Returns the authorization URL; the callback redirects to
+// {frontend}/auth/link with ?status=linked or ?error=<code>.
+func (h *Handler) LinkProvider(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	providerName := c.Params("provider")
+	provider, ok := h.providers[providerName]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "unsupported provider"})
+	}
+
+	state, params, err := h.saveOAuthState(c.Context(), oauthState{Provider: providerName, LinkUserID: userID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start linking"})
+	}
+
+	return c.JSON(fiber.Map{"url": provider.AuthCodeURL(state, params)})
+}
+
+// finishLink completes a LinkProvider flow from the OAuth callback.
+func (h *Handler) finishLink(c *fiber.Ctx, userID, providerName string, userInfo *OAuthUserInfo) error {
+	redirect := frontendBaseURL() + "/auth/link?provider=" + url.QueryEscape(providerName)
+
+	err := h.userRepo.LinkIdentity(c.Context(), userID, providerName, userInfo.ID, userInfo.Email)
+	if err != nil {
+		code := linkErrorCode(err)
+		if code == "link_failed" {
+			log.Error().Err(err).Str("user_id", userID).Str("provider", providerName).Msg("Account link failed")
+		}
+		return c.Redirect(redirect + "&error=" + code)
+	}
+
+	log.Info().Str("user_id", userID).Str("provider", providerName).Msg("OAuth identity linked")
+	return c.Redirect(redirect + "&status=linked")
+}
+
+func linkErrorCode(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrIdentityInUse):
+		return "identity_in_use"
+	case errors.Is(err, repository.ErrProviderAlreadyLinked):
+		return "provider_already_linked"
+	default:
+		return "link_failed"
+	}
+}
+
+func linkErrorResponse(c *fiber.Ctx, provider string, err error) error {
+	code := linkErrorCode(err)
+	if code == "link_failed" {
+		log.Error().Err(err).Str("provider", provider).Msg("Account link failed")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": code})
+	}
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": code, "provider": provider})
+}
@@ -10,7 +10,24 @@ import (
 	"strings"
 )
 
-// OAuthProvider represents a generic OAuth 2.0 provider
+// LoginProvider is an external identity provider used for login and account linking.
+type LoginProvider interface {
+	ProviderName() string
+	// AuthCodeURL returns the URL the browser is sent to for authorization.
+	AuthCodeURL(state string, params AuthParams) string
+	// Identify exchanges the authorization code and returns the user's identity.
+	Identify(ctx context.Context, code string, params AuthParams) (*OAuthUserInfo, error)
+}
+
+// AuthParams are the per-login secrets kept server-side between the redirect and
+// the callback. Plain OAuth providers ignore them.
+type AuthParams struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// OAuthProvider is a plain OAuth 2.0 provider with a provider-specific user info
+// endpoint. Providers that speak OpenID Connect should use OIDCProvider instead.
 type OAuthProvider struct {
 	Name         string
 	ClientID     string
@@ -59,6 +76,22 @@ func NewKakaoProvider(clientID, clientSecret, redirectURL string) *OAuthProvider
 	}
 }
 
+func (p *OAuthProvider) ProviderName() string {
+	return p.Name
+}
+
+func (p *OAuthProvider) AuthCodeURL(state string, _ AuthParams) string {
+	return p.GetAuthURL(state)
+}
+
+func (p *OAuthProvider) Identify(ctx context.Context, code string, _ AuthParams) (*OAuthUserInfo, error) {
+	token, err := p.ExchangeCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return p.GetUserInfo(ctx, token.AccessToken)
+}
+
 // GetAuthURL returns the OAuth authorization URL
 func (p *OAuthProvider) GetAuthURL(state string) string {
 	params := url.Values{
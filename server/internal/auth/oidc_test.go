@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/slimetopia/server/internal/auth/oidctest"
+)
+
+const testClientID = "slimetopia-test"
+
+func newTestProvider(t *testing.T, srv *oidctest.Server) *OIDCProvider {
+	t.Helper()
+	p, err := NewOIDCProvider(context.Background(), OIDCConfig{
+		Name:        "mock",
+		Issuer:      srv.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost:8080/api/auth/callback/mock",
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+	return p
+}
+
+// authorize follows AuthCodeURL to the mock server and returns the code it redirects back with.
+func authorize(t *testing.T, p *OIDCProvider, state string, params AuthParams) string {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(p.AuthCodeURL(state, params))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", resp.StatusCode)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: bad redirect: %v", err)
+	}
+	if got := loc.Query().Get("state"); got != state {
+		t.Fatalf("state = %q, want %q", got, state)
+	}
+	return loc.Query().Get("code")
+}
+
+func testParams() AuthParams {
+	return AuthParams{Nonce: "nonce-1", CodeVerifier: strings.Repeat("v", 43)}
+}
+
+func TestOIDCLoginFlow(t *testing.T) {
+	srv := oidctest.NewServer(testClientID, oidctest.User{
+		Subject: "user-123", Email: "slime@example.com", EmailVerified: true, Name: "Slimer",
+	})
+	defer srv.Close()
+	p := newTestProvider(t, srv)
+
+	params := testParams()
+	code := authorize(t, p, "state-1", params)
+
+	info, err := p.Identify(context.Background(), code, params)
+	if err != nil {
+		t.Fatalf("Identify: %v", err)
+	}
+	if info.ID != "user-123" || info.Email != "slime@example.com" || info.Nickname != "Slimer" {
+		t.Errorf("Identify = %+v", info)
+	}
+}
+
+func TestOIDCUnverifiedEmailIsDropped(t *testing.T) {
+	srv := oidctest.NewServer(testClientID, oidctest.User{Subject: "user-123", Email: "slime@example.com"})
+	defer srv.Close()
+	p := newTestProvider(t, srv)
+
+	params := testParams()
+	info, err := p.Identify(context.Background(), authorize(t, p, "s", params), params)
+	if err != nil {
+		t.Fatalf("Identify: %v", err)
+	}
+	if info.Email != "" {
+		t.Errorf("unverified email was kept: %q", info.Email)
+	}
+}
+
+func TestOIDCRejectsWrongVerifier(t *testing.T) {
+	srv := oidctest.NewServer(testClientID, oidctest.User{Subject: "user-123"})
+	defer srv.Close()
+	p := newTestProvider(t, srv)
+
+	params := testParams()
+	code := authorize(t, p, "s", params)
+	params.CodeVerifier = strings.Repeat("x", 43)
+	if _, err := p.Identify(context.Background(), code, params); err == nil {
+		t.Fatal("Identify succeeded with the wrong PKCE verifier")
+	}
+}
+
+func TestOIDCRejectsWrongNonce(t *testing.T) {
+	srv := oidctest.NewServer(testClientID, oidctest.User{Subject: "user-123"})
+	defer srv.Close()
+	p := newTestProvider(t, srv)
+
+	params := testParams()
+	code := authorize(t, p, "s", params)
+	params.Nonce = "someone-elses-nonce"
+	if _, err := p.Identify(context.Background(), code, params); err == nil {
+		t.Fatal("Identify succeeded with a mismatched nonce")
+	}
+}
+
+func TestOIDCVerifyIDToken(t *testing.T) {
+	srv := oidctest.NewServer(testClientID, oidctest.User{Subject: "user-123"})
+	defer srv.Close()
+	p := newTestProvider(t, srv)
+	ctx := context.Background()
+	now := time.Now()
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": srv.URL, "aud": testClientID, "sub": "user-123", "nonce": "n",
+			"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+		}
+	}
+	if _, err := p.VerifyIDToken(ctx, srv.SignIDToken(valid()), "n"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	cases := map[string]func(jwt.MapClaims){
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "another-client" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() },
+		"missing exp":    func(c jwt.MapClaims) { delete(c, "exp") },
+		"missing sub":    func(c jwt.MapClaims) { delete(c, "sub") },
+	}
+	for name, mutate := range cases {
+		claims := valid()
+		mutate(claims)
+		if _, err := p.VerifyIDToken(ctx, srv.SignIDToken(claims), "n"); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+
+	// A token signed with a key the JWKS doesn't publish must fail
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, valid())
+	forgedStr, _ := forged.SignedString([]byte("secret"))
+	if _, err := p.VerifyIDToken(ctx, forgedStr, "n"); err == nil {
+		t.Error("HS256 token accepted")
+	}
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	srv := oidctest.NewServer(testClientID, oidctest.User{Subject: "user-123"})
+	defer srv.Close()
+	p := newTestProvider(t, srv)
+
+	srv.RotateKey()
+	// Pretend the cached JWKS is old enough to be refetched
+	p.mu.Lock()
+	p.keysFetched = time.Now().Add(-2 * jwksMinRefresh)
+	p.mu.Unlock()
+
+	params := testParams()
+	if _, err := p.Identify(context.Background(), authorize(t, p, "s", params), params); err != nil {
+		t.Fatalf("Identify after key rotation: %v", err)
+	}
+}
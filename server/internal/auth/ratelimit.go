@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// Login and recovery limits, per fixed window.
+const (
+	loginWindow              = 15 * time.Minute
+	maxLoginFailuresPerEmail = 5
+	maxLoginAttemptsPerIP    = 30
+
+	recoveryWindow          = time.Hour
+	maxRecoveryMailsPerAddr = 3
+	maxRecoveryMailsPerIP   = 10
+)
+
+// rateLimiter counts attempts in Redis (rl:<scope>:<key>). When Redis is unavailable
+// it fails open: logins keep working, the protection is lost.
+type rateLimiter struct {
+	rdb *redis.Client
+}
+
+// hit counts one attempt and returns how long the caller must wait once max is
+// exceeded within window, or 0 if the attempt is allowed.
+func (l *rateLimiter) hit(ctx context.Context, key string, max int, window time.Duration) time.Duration {
+	n, err := l.rdb.Incr(ctx, key).Result()
+	if err != nil {
+		log.Warn().Err(err).Msg("Rate limiter unavailable")
+		return 0
+	}
+	if n == 1 {
+		l.rdb.Expire(ctx, key, window)
+	}
+	if n <= int64(max) {
+		return 0
+	}
+	return l.ttl(ctx, key, window)
+}
+
+// blocked reports how long key stays blocked once it has reached max, without counting.
+func (l *rateLimiter) blocked(ctx context.Context, key string, max int, window time.Duration) time.Duration {
+	n, err := l.rdb.Get(ctx, key).Int64()
+	if err != nil || n < int64(max) {
+		return 0
+	}
+	return l.ttl(ctx, key, window)
+}
+
+func (l *rateLimiter) ttl(ctx context.Context, key string, window time.Duration) time.Duration {
+	ttl, err := l.rdb.TTL(ctx, key).Result()
+	if err != nil || ttl <= 0 {
+		// Counter lost its expiry somehow; don't lock the key forever
+		l.rdb.Expire(ctx, key, window)
+		return window
+	}
+	return ttl
+}
+
+func (l *rateLimiter) reset(ctx context.Context, key string) {
+	l.rdb.Del(ctx, key)
+}
+
+func tooManyAttempts(c *fiber.Ctx, wait time.Duration) error {
+	secs := int(wait.Seconds()) + 1
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(secs))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":       "too_many_attempts",
+		"retry_after": secs,
+	})
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+const (
+	deviceLabelHeader = "X-Device-Label"
+	maxDeviceLabelLen = 100
+	maxUserAgentLen   = 512
+)
+
+// issueTokens starts a new session for the request's device and signs its first token pair.
+func (h *Handler) issueTokens(c *fiber.Ctx, userID, nickname string) (*TokenPair, error) {
+	sessionID := uuid.NewString()
+	refreshID := uuid.NewString()
+
+	tokenPair, err := h.jwtManager.GenerateTokenPair(userID, nickname, sessionID, refreshID)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := truncate(c.Get(fiber.HeaderUserAgent), maxUserAgentLen)
+	err = h.sessionRepo.Create(c.Context(), &models.Session{
+		ID:          sessionID,
+		UserID:      userID,
+		CurrentJTI:  refreshID,
+		DeviceLabel: deviceLabel(c.Get(deviceLabelHeader), userAgent),
+		UserAgent:   userAgent,
+		IP:          c.IP(),
+		ExpiresAt:   time.Now().Add(h.jwtManager.refreshExpiry),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokenPair, nil
+}
+
+// rotateTokens exchanges a session's current refresh token for a new pair.
+func (h *Handler) rotateTokens(c *fiber.Ctx, claims *Claims, nickname string) (*TokenPair, error) {
+	refreshID := uuid.NewString()
+	_, err := h.sessionRepo.Rotate(c.Context(), claims.SessionID, claims.ID, refreshID,
+		c.IP(), truncate(c.Get(fiber.HeaderUserAgent), maxUserAgentLen),
+		time.Now().Add(h.jwtManager.refreshExpiry))
+	if err != nil {
+		return nil, err
+	}
+	return h.jwtManager.GenerateTokenPair(claims.UserID, nickname, claims.SessionID, refreshID)
+}
+
+// deviceLabel prefers the label the client sends and otherwise derives a coarse one
+// from the user agent.
+func deviceLabel(label, userAgent string) string {
+	if label = strings.TrimSpace(label); label != "" {
+		return truncate(label, maxDeviceLabelLen)
+	}
+	ua := strings.ToLower(userAgent)
+	var device string
+	switch {
+	case strings.Contains(ua, "iphone"):
+		device = "iPhone"
+	case strings.Contains(ua, "ipad"):
+		device = "iPad"
+	case strings.Contains(ua, "android"):
+		device = "Android"
+	case strings.Contains(ua, "windows"):
+		device = "Windows"
+	case strings.Contains(ua, "mac os"):
+		device = "Mac"
+	case strings.Contains(ua, "linux"):
+		device = "Linux"
+	default:
+		return "Unknown device"
+	}
+	switch {
+	case strings.Contains(ua, "edg/"):
+		return device + " · Edge"
+	case strings.Contains(ua, "chrome/"):
+		return device + " · Chrome"
+	case strings.Contains(ua, "firefox/"):
+		return device + " · Firefox"
+	case strings.Contains(ua, "safari/"):
+		return device + " · Safari"
+	}
+	return device
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// GET /api/user/sessions — the caller's active sessions, current one flagged
+func (h *UserHandler) ListSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	currentID, _ := c.Locals("session_id").(string)
+
+	sessions, err := h.sessionRepo.ListActive(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list sessions"})
+	}
+
+	result := make([]fiber.Map, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, fiber.Map{
+			"id":           s.ID,
+			"device_label": s.DeviceLabel,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt,
+			"last_used_at": s.LastUsedAt,
+			"expires_at":   s.ExpiresAt,
+			"current":      s.ID == currentID,
+		})
+	}
+	return c.JSON(fiber.Map{"sessions": result})
+}
+
+// DELETE /api/user/sessions/:id — log out one device
+func (h *UserHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	sessionID := c.Params("id")
+	ctx := c.Context()
+
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid session id"})
+	}
+
+	if err := h.sessionRepo.Revoke(ctx, userID, sessionID, repository.SessionRevokedByUser); err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "session not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to revoke session"})
+	}
+	if err := h.denylist.RevokeSession(ctx, sessionID); err != nil {
+		log.Warn().Err(err).Str("session_id", sessionID).Msg("Failed to denylist session access tokens")
+	}
+
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// DELETE /api/user/sessions — log out every device; ?keep_current=true spares this one
+func (h *UserHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	exceptID := ""
+	if c.QueryBool("keep_current") {
+		exceptID, _ = c.Locals("session_id").(string)
+	}
+
+	revoked, err := h.sessionRepo.RevokeAll(ctx, userID, exceptID, repository.SessionRevokedByUser)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to revoke sessions"})
+	}
+	for _, id := range revoked {
+		if err := h.denylist.RevokeSession(ctx, id); err != nil {
+			log.Warn().Err(err).Str("session_id", id).Msg("Failed to denylist session access tokens")
+		}
+	}
+	if exceptID == "" {
+		// Also covers tokens minted before sessions were tracked
+		if err := h.denylist.RevokeUser(ctx, userID); err != nil {
+			log.Warn().Err(err).Str("user_id", userID).Msg("Failed to revoke user tokens")
+		}
+	}
+
+	return c.JSON(fiber.Map{"success": true, "revoked": len(revoked)})
+}
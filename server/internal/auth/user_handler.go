@@ -3,6 +3,7 @@ package auth
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/slimetopia/server/internal/repository"
@@ -12,11 +13,15 @@ import (
 const nicknameCostGold = 500
 
 type UserHandler struct {
-	userRepo *repository.UserRepository
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	accountRepo *repository.AccountRepository
+	jwtManager  *JWTManager
+	denylist    *Denylist
 }
 
-func NewUserHandler(userRepo *repository.UserRepository) *UserHandler {
-	return &UserHandler{userRepo: userRepo}
+func NewUserHandler(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, accountRepo *repository.AccountRepository, jwtManager *JWTManager, denylist *Denylist) *UserHandler {
+	return &UserHandler{userRepo: userRepo, sessionRepo: sessionRepo, accountRepo: accountRepo, jwtManager: jwtManager, denylist: denylist}
 }
 
 func RegisterUserRoutes(router fiber.Router, handler *UserHandler) {
@@ -25,6 +30,47 @@ func RegisterUserRoutes(router fiber.Router, handler *UserHandler) {
 	user.Patch("/me/nickname", handler.UpdateNickname)
 	user.Patch("/me/password", handler.ChangePassword)
 	user.Get("/me/community-stats", handler.GetCommunityStats)
+	user.Post("/logout", handler.Logout)
+	user.Get("/sessions", handler.ListSessions)
+	user.Delete("/sessions", handler.RevokeAllSessions)
+	user.Delete("/sessions/:id", handler.RevokeSession)
+	user.Get("/export", handler.ExportData)
+	user.Post("/delete", handler.RequestDeletion)
+	user.Post("/delete/cancel", handler.CancelDeletion)
+}
+
+// POST /api/user/logout — ends the current session, revoking its access and refresh tokens
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	if sessionID, _ := c.Locals("session_id").(string); sessionID != "" {
+		err := h.sessionRepo.Revoke(ctx, userID, sessionID, repository.SessionRevokedLogout)
+		if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to logout"})
+		}
+		h.denylist.RevokeSession(ctx, sessionID)
+	}
+
+	if jti, _ := c.Locals("jti").(string); jti != "" {
+		expiresAt, _ := c.Locals("token_expires_at").(time.Time)
+		if err := h.denylist.RevokeToken(ctx, jti, expiresAt); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to logout"})
+		}
+	}
+
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	c.BodyParser(&body)
+	if body.RefreshToken != "" {
+		claims, err := h.jwtManager.ValidateToken(body.RefreshToken)
+		if err == nil && claims.TokenType() == TokenTypeRefresh && claims.UserID == userID && claims.ExpiresAt != nil {
+			h.denylist.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time)
+		}
+	}
+
+	return c.JSON(fiber.Map{"success": true})
 }
 
 func (h *UserHandler) GetMe(c *fiber.Ctx) error {
@@ -51,14 +97,16 @@ func (h *UserHandler) GetMe(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"id":                uuidToString(user.ID),
-		"nickname":          user.Nickname,
-		"gold":              user.Gold,
-		"gems":              user.Gems,
-		"stardust":          user.Stardust,
-		"level":             user.Level,
-		"email":             maskedEmail,
-		"profile_image_url": user.ProfileImageURL,
+		"id":                    uuidToString(user.ID),
+		"nickname":              user.Nickname,
+		"gold":                  user.Gold,
+		"gems":                  user.Gems,
+		"stardust":              user.Stardust,
+		"level":                 user.Level,
+		"email":                 maskedEmail,
+		"email_verified":        user.EmailVerified,
+		"profile_image_url":     user.ProfileImageURL,
+		"deletion_scheduled_at": user.DeletionScheduledAt,
 	})
 }
 
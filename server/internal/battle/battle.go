@@ -0,0 +1,91 @@
+// Package battle simulates team fights between slimes. A battle is a pure function of
+// its seed and the two teams' snapshots, and it records everything that happens in a
+// versioned Log that clients replay and the server can re-run to check.
+//
+// Damage, HP and turn order come from package combat; this package decides who acts
+// when and at whom.
+package battle
+
+import (
+	"fmt"
+
+	"github.com/slimetopia/server/internal/combat"
+	"github.com/slimetopia/server/internal/models"
+)
+
+// Side is one of the two teams of a battle.
+type Side int
+
+const (
+	Attack Side = iota
+	Defend
+)
+
+func (s Side) String() string {
+	if s == Attack {
+		return "attack"
+	}
+	return "defend"
+}
+
+// MarshalText writes a side as "attack" or "defend".
+func (s Side) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads a side written by MarshalText.
+func (s *Side) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "attack":
+		*s = Attack
+	case "defend":
+		*s = Defend
+	default:
+		return fmt.Errorf("battle: unknown side %q", b)
+	}
+	return nil
+}
+
+// Slot names the i-th (0-based) member of a side in the log: a1..a3 and d1..d3.
+func Slot(side Side, i int) string {
+	return fmt.Sprintf("%c%d", "ad"[side], i+1)
+}
+
+// Unit is a slime as it enters a battle. Stats holds everything the fight is
+// computed from, so a log can be replayed after the slime itself has changed.
+type Unit struct {
+	Slot      string         `json:"slot"`
+	SlimeID   string         `json:"slime_id,omitempty"`
+	SpeciesID int            `json:"species_id,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	MaxHP     int            `json:"max_hp"`
+	Stats     combat.Fighter `json:"stats"`
+}
+
+// Team is one side's units in slot order.
+type Team []Unit
+
+// FromSlime snapshots a slime given the summed effects of its learned skills and its
+// equipped accessories. The slot is filled in when the battle starts.
+func FromSlime(s *models.Slime, skills, gear combat.Effects) Unit {
+	f := combat.Fighter{
+		Element: s.Element,
+		Level:   s.Level,
+		Stars:   s.StarLevel,
+		Talents: combat.Talents{
+			Str: s.TalentStr, Vit: s.TalentVit, Spd: s.TalentSpd,
+			Int: s.TalentInt, Cha: s.TalentCha, Lck: s.TalentLck,
+		},
+		Skills: skills,
+		Gear:   gear,
+	}
+	u := Unit{SpeciesID: s.SpeciesID, MaxHP: f.MaxHP(), Stats: f}
+	if s.ID.Valid {
+		b := s.ID.Bytes
+		u.SlimeID = fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+	}
+	if s.Name != nil {
+		u.Name = *s.Name
+	}
+	return u
+}
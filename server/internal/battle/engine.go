@@ -0,0 +1,125 @@
+package battle
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/slimetopia/server/internal/combat"
+)
+
+// DefaultMaxRounds is the round cap when Options leaves it unset.
+const DefaultMaxRounds = 30
+
+// Options tune a battle.
+type Options struct {
+	MaxRounds int
+}
+
+type combatant struct {
+	side  Side
+	index int
+	unit  *Unit
+	hp    int
+}
+
+// Run fights attack against defend. Every round each standing unit acts once, fastest
+// first (the attacking side wins ties, then the earlier slot), and strikes the
+// standing enemy with the least HP. A battle still undecided after MaxRounds goes to
+// the side with the larger share of its HP left; an even split goes to the defender.
+//
+// The teams are copied into the log with their slots filled in; the arguments are
+// not modified.
+func Run(seed int64, attack, defend Team, opts Options) *Log {
+	if opts.MaxRounds <= 0 {
+		opts.MaxRounds = DefaultMaxRounds
+	}
+	l := &Log{Version: Version, Seed: seed, MaxRounds: opts.MaxRounds, Events: []Event{}}
+	var units []*combatant
+	for side, team := range [2]Team{attack, defend} {
+		l.Teams[side] = make(Team, len(team))
+		for i, u := range team {
+			u.Slot = Slot(Side(side), i)
+			u.MaxHP = u.Stats.MaxHP()
+			l.Teams[side][i] = u
+		}
+		for i := range l.Teams[side] {
+			u := &l.Teams[side][i]
+			units = append(units, &combatant{side: Side(side), index: i, unit: u, hp: u.MaxHP})
+		}
+	}
+	order := append([]*combatant(nil), units...)
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].unit.Stats.Initiative() > order[j].unit.Stats.Initiative()
+	})
+
+	rng := rand.New(rand.NewSource(seed))
+	for round := 1; round <= opts.MaxRounds; round++ {
+		l.Rounds = round
+		l.Events = append(l.Events, Event{Type: EventRound, Round: round})
+		for _, c := range order {
+			if c.hp <= 0 {
+				continue
+			}
+			target := weakest(units, 1-c.side)
+			if target == nil {
+				break
+			}
+			hit := combat.Strike(rng, c.unit.Stats, target.unit.Stats)
+			target.hp = max(target.hp-hit.Damage, 0)
+			hp := target.hp
+			l.Events = append(l.Events, Event{
+				Type:          EventAttack,
+				Round:         round,
+				Actor:         c.unit.Slot,
+				Target:        target.unit.Slot,
+				Damage:        hit.Damage,
+				Effectiveness: hit.Effectiveness,
+				Crit:          hit.Crit,
+				DoubleHit:     hit.DoubleHit,
+				HP:            &hp,
+			})
+			if hp == 0 {
+				l.Events = append(l.Events, Event{Type: EventKO, Round: round, Target: target.unit.Slot})
+			}
+		}
+		for _, loser := range []Side{Defend, Attack} {
+			if weakest(units, loser) == nil {
+				l.end(1-loser, ReasonWipeout)
+				return l
+			}
+		}
+	}
+
+	// Out of rounds: compare HP shares without dividing
+	var hp, maxHP [2]int
+	for _, c := range units {
+		hp[c.side] += c.hp
+		maxHP[c.side] += c.unit.MaxHP
+	}
+	winner := Defend
+	if hp[Attack]*maxHP[Defend] > hp[Defend]*maxHP[Attack] {
+		winner = Attack
+	}
+	l.end(winner, ReasonRoundCap)
+	return l
+}
+
+func (l *Log) end(winner Side, reason string) {
+	l.Winner = winner
+	l.Events = append(l.Events, Event{Type: EventEnd, Round: l.Rounds, Winner: &winner, Reason: reason})
+}
+
+// weakest returns the standing member of side with the least HP, the earliest slot
+// on a tie, or nil when the whole side is down.
+func weakest(units []*combatant, side Side) *combatant {
+	var best *combatant
+	for _, c := range units {
+		if c.side != side || c.hp <= 0 {
+			continue
+		}
+		if best == nil || c.hp < best.hp {
+			best = c
+		}
+	}
+	return best
+}
@@ -0,0 +1,214 @@
+package battle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/slimetopia/server/internal/combat"
+	"github.com/slimetopia/server/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func slime(element string, level, stars int, t [6]int) *models.Slime {
+	return &models.Slime{
+		Element: element, Level: level, StarLevel: stars,
+		TalentStr: t[0], TalentVit: t[1], TalentSpd: t[2], TalentInt: t[3], TalentCha: t[4], TalentLck: t[5],
+	}
+}
+
+func unit(element string, level int, str, vit, spd int) Unit {
+	return FromSlime(slime(element, level, 0, [6]int{str, vit, spd, 0, 0, 10}), combat.Effects{}, combat.Effects{})
+}
+
+var goldenBattles = []struct {
+	name           string
+	seed           int64
+	attack, defend Team
+	opts           Options
+}{
+	{
+		name:   "duel",
+		seed:   1,
+		attack: Team{unit("water", 10, 12, 10, 10)},
+		defend: Team{unit("fire", 10, 12, 10, 8)},
+	},
+	{
+		name: "three_on_three",
+		seed: 42,
+		attack: Team{
+			FromSlime(slime("electric", 25, 1, [6]int{20, 12, 25, 18, 10, 15}), combat.Effects{Attack: 0.1}, combat.Effects{}),
+			FromSlime(slime("grass", 22, 0, [6]int{15, 28, 6, 10, 20, 8}), combat.Effects{}, combat.Effects{AllStats: 2}),
+			FromSlime(slime("light", 30, 2, [6]int{28, 10, 18, 25, 5, 30}), combat.Effects{CritChance: 0.05}, combat.Effects{Attack: 0.15}),
+		},
+		defend: Team{
+			FromSlime(slime("water", 26, 1, [6]int{22, 20, 20, 15, 15, 15}), combat.Effects{}, combat.Effects{}),
+			FromSlime(slime("dark", 28, 1, [6]int{25, 15, 28, 12, 12, 20}), combat.Effects{Attack: 0.05}, combat.Effects{}),
+			FromSlime(slime("earth", 24, 0, [6]int{18, 31, 4, 8, 8, 10}), combat.Effects{}, combat.Effects{AllStats: 3}),
+		},
+	},
+	{
+		name:   "round_cap",
+		seed:   7,
+		attack: Team{FromSlime(slime("water", 1, 0, [6]int{0, 31, 0, 0, 0, 0}), combat.Effects{}, combat.Effects{Attack: -0.999})},
+		defend: Team{FromSlime(slime("wind", 1, 0, [6]int{0, 31, 0, 0, 0, 0}), combat.Effects{}, combat.Effects{Attack: -0.999})},
+		opts:   Options{MaxRounds: 5},
+	},
+}
+
+// TestGolden pins the log format and the rules: a diff here means old replays would
+// play differently, which needs a new Version. Run with -update to accept a change.
+func TestGolden(t *testing.T) {
+	for _, tt := range goldenBattles {
+		t.Run(tt.name, func(t *testing.T) {
+			l := Run(tt.seed, tt.attack, tt.defend, tt.opts)
+			got, err := json.MarshalIndent(l, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			path := filepath.Join("testdata", tt.name+".golden.json")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("log differs from %s; run with -update if the change is intended\ngot:\n%s", path, got)
+			}
+
+			parsed, err := Parse(want)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if err := Verify(parsed); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestRunIsConsistent(t *testing.T) {
+	attack, defend := goldenBattles[1].attack, goldenBattles[1].defend
+	for seed := int64(0); seed < 50; seed++ {
+		l := Run(seed, attack, defend, Options{})
+		hp := map[string]int{}
+		for _, team := range l.Teams {
+			for _, u := range team {
+				hp[u.Slot] = u.MaxHP
+			}
+		}
+		var last Event
+		for _, ev := range l.Events {
+			switch ev.Type {
+			case EventAttack:
+				if hp[ev.Actor] <= 0 {
+					t.Fatalf("seed %d: %s acted after being knocked out", seed, ev.Actor)
+				}
+				hp[ev.Target] = max(hp[ev.Target]-ev.Damage, 0)
+				if *ev.HP != hp[ev.Target] {
+					t.Fatalf("seed %d: %+v doesn't match tracked HP %d", seed, ev, hp[ev.Target])
+				}
+			case EventKO:
+				if last.Type != EventAttack || last.Target != ev.Target || *last.HP != 0 {
+					t.Fatalf("seed %d: KO of %s not right after the blow that did it", seed, ev.Target)
+				}
+			}
+			last = ev
+		}
+		if last.Type != EventEnd || *last.Winner != l.Winner {
+			t.Fatalf("seed %d: log ends with %+v, winner %v", seed, last, l.Winner)
+		}
+		if last.Reason == ReasonWipeout {
+			loser := "ad"[1-l.Winner]
+			for slot, left := range hp {
+				if slot[0] == loser && left > 0 {
+					t.Errorf("seed %d: %s still has %d HP but its side lost", seed, slot, left)
+				}
+			}
+		}
+	}
+}
+
+func TestRunDoesNotModifyTeams(t *testing.T) {
+	attack := Team{unit("water", 10, 10, 10, 10)}
+	Run(1, attack, Team{unit("fire", 10, 10, 10, 10)}, Options{})
+	if attack[0].Slot != "" {
+		t.Errorf("Run wrote slot %q into the caller's team", attack[0].Slot)
+	}
+}
+
+func TestStrongerTeamWins(t *testing.T) {
+	strong := Team{unit("water", 50, 31, 31, 31), unit("water", 50, 31, 31, 31), unit("water", 50, 31, 31, 31)}
+	weak := Team{unit("fire", 1, 0, 0, 0)}
+	for seed := int64(0); seed < 20; seed++ {
+		if l := Run(seed, strong, weak, Options{}); !l.AttackerWon() {
+			t.Fatalf("seed %d: strong attackers lost", seed)
+		}
+		if l := Run(seed, weak, strong, Options{}); l.AttackerWon() {
+			t.Fatalf("seed %d: weak attacker won", seed)
+		}
+	}
+}
+
+func TestTurnOrder(t *testing.T) {
+	slow := unit("water", 10, 10, 10, 0)
+	fast := unit("water", 10, 10, 10, 30)
+	if l := Run(1, Team{slow}, Team{fast}, Options{}); l.Events[1].Actor != "d1" {
+		t.Errorf("first actor %s, want the faster defender d1", l.Events[1].Actor)
+	}
+	if l := Run(1, Team{slow}, Team{slow}, Options{}); l.Events[1].Actor != "a1" {
+		t.Errorf("first actor %s, want a1 on a speed tie", l.Events[1].Actor)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	tt := goldenBattles[0]
+	l := Run(tt.seed, tt.attack, tt.defend, tt.opts)
+	l.Teams[Attack][0].Stats.Talents.Str = 31
+	if err := Verify(l); err == nil {
+		t.Error("Verify accepted a log with edited stats")
+	}
+
+	l = Run(tt.seed, tt.attack, tt.defend, tt.opts)
+	l.Version = Version + 1
+	if err := Verify(l); !errors.Is(err, ErrVersion) {
+		t.Errorf("Verify of a future version: %v, want ErrVersion", err)
+	}
+	if _, err := Parse([]byte(`{"attackers": [], "events": []}`)); !errors.Is(err, ErrVersion) {
+		t.Errorf("Parse of an unversioned log: %v, want ErrVersion", err)
+	}
+}
+
+func TestFromSlime(t *testing.T) {
+	s := slime("ice", 12, 2, [6]int{1, 2, 3, 4, 5, 6})
+	s.SpeciesID = 7
+	name := "Frosty"
+	s.Name = &name
+	s.ID = pgtype.UUID{Bytes: [16]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 1, 2, 3, 4, 5, 6, 7, 8}, Valid: true}
+
+	u := FromSlime(s, combat.Effects{Attack: 0.1}, combat.Effects{AllStats: 1})
+	want := combat.Fighter{
+		Element: "ice", Level: 12, Stars: 2,
+		Talents: combat.Talents{Str: 1, Vit: 2, Spd: 3, Int: 4, Cha: 5, Lck: 6},
+		Skills:  combat.Effects{Attack: 0.1},
+		Gear:    combat.Effects{AllStats: 1},
+	}
+	if u.Stats != want {
+		t.Errorf("stats = %+v, want %+v", u.Stats, want)
+	}
+	if u.SlimeID != "12345678-9abc-def0-0102-030405060708" || u.SpeciesID != 7 || u.Name != "Frosty" || u.MaxHP != want.MaxHP() {
+		t.Errorf("unit = %+v", u)
+	}
+}
@@ -0,0 +1,124 @@
+package combat
+
+// Phase is a stage of a boss fight that starts once the boss's HP falls to a share
+// of its maximum. A phase changes how much damage each element deals to the boss.
+type Phase struct {
+	Name  string
+	Below float64 // HP share at or under which the phase begins; the first phase uses 1
+	// Resist multiplies damage by attacker element; missing elements take Default
+	Resist  map[string]float64
+	Default float64
+}
+
+func (p Phase) resist(element string) float64 {
+	if r, ok := p.Resist[element]; ok {
+		return r
+	}
+	if p.Default == 0 {
+		return 1
+	}
+	return p.Default
+}
+
+// StandardPhases is the three-phase fight every boss of the given element follows:
+//
+//   - normal, from full HP: no change.
+//   - guarded, from 60%: the boss shields its weaknesses, cutting super effective
+//     damage to 70% and its own element to half.
+//   - frenzy, from 25%: the shield breaks and the boss lays itself open; weaknesses
+//     take 130% and everything else 110%.
+func StandardPhases(element string) []Phase {
+	guarded := map[string]float64{element: 0.5}
+	frenzy := map[string]float64{}
+	for _, e := range Elements {
+		if SuperEffective(e, element) {
+			guarded[e] = 0.7
+			frenzy[e] = 1.3
+		}
+	}
+	return []Phase{
+		{Name: "normal", Below: 1},
+		{Name: "guarded", Below: 0.6, Resist: guarded},
+		{Name: "frenzy", Below: 0.25, Resist: frenzy, Default: 1.1},
+	}
+}
+
+// Boss is the target of a boss fight.
+type Boss struct {
+	Element   string
+	HP, MaxHP int64
+	Phases    []Phase // ordered by falling Below; none means a single neutral phase
+}
+
+// Phase returns the current phase and its index.
+func (b Boss) Phase() (int, Phase) {
+	if len(b.Phases) == 0 {
+		return 0, Phase{Name: "normal", Below: 1}
+	}
+	share := 1.0
+	if b.MaxHP > 0 {
+		share = float64(b.HP) / float64(b.MaxHP)
+	}
+	idx := 0
+	for i, p := range b.Phases {
+		if share <= p.Below {
+			idx = i
+		}
+	}
+	return idx, b.Phases[idx]
+}
+
+// Hit is one fighter's attack on a boss.
+type Hit struct {
+	Damage int
+	// Effectiveness is the element multiplier after the phase's resistances
+	Effectiveness float64
+	Crit          bool
+	DoubleHit     bool
+}
+
+// BossHit rolls one attack on b. It draws from rng in a fixed order (variance, crit,
+// double hit) so a seed replays it exactly.
+func BossHit(rng Rand, f Fighter, b Boss) Hit {
+	_, phase := b.Phase()
+	eff := Effectiveness(f.Element, b.Element) * phase.resist(f.Element)
+
+	dmg := f.Power() + f.variance(rng)
+	dmg *= eff
+	dmg *= 1 + f.Skills.BossDamage*f.skillAmp() + f.Gear.BossDamage*f.gearAmp()
+	return f.land(rng, dmg, eff)
+}
+
+// land rolls crit and double hit on dmg and rounds it down to a Hit of at least 1.
+func (f Fighter) land(rng Rand, dmg, eff float64) Hit {
+	hit := Hit{Effectiveness: eff}
+	if rng.Float64() < f.CritChance() {
+		hit.Crit = true
+		dmg *= critDamage
+	}
+	if rng.Float64() < f.DoubleHitChance() {
+		hit.DoubleHit = true
+		dmg *= 2
+	}
+	hit.Damage = int(dmg)
+	if hit.Damage < 1 {
+		hit.Damage = 1
+	}
+	return hit
+}
+
+// PartyCombo is the damage multiplier for attacking with n slimes at once.
+func PartyCombo(n int) float64 {
+	switch {
+	case n >= 5:
+		return 1.5
+	case n == 4:
+		return 1.35
+	case n == 3:
+		return 1.2
+	case n == 2:
+		return 1.1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,205 @@
+package combat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// scriptedRand returns queued values in order, then zeros.
+type scriptedRand struct {
+	floats []float64
+	ints   []int
+}
+
+func (r *scriptedRand) Float64() float64 {
+	if len(r.floats) == 0 {
+		return 0
+	}
+	v := r.floats[0]
+	r.floats = r.floats[1:]
+	return v
+}
+
+func (r *scriptedRand) Intn(n int) int {
+	if len(r.ints) == 0 {
+		return 0
+	}
+	v := r.ints[0]
+	r.ints = r.ints[1:]
+	return v % n
+}
+
+func TestBossPhase(t *testing.T) {
+	tests := []struct {
+		hp   int64
+		want string
+	}{
+		{1000, "normal"},
+		{601, "normal"},
+		{600, "guarded"},
+		{251, "guarded"},
+		{250, "frenzy"},
+		{0, "frenzy"},
+	}
+	for _, tt := range tests {
+		b := Boss{Element: "fire", HP: tt.hp, MaxHP: 1000, Phases: StandardPhases("fire")}
+		if _, p := b.Phase(); p.Name != tt.want {
+			t.Errorf("HP %d: phase %q, want %q", tt.hp, p.Name, tt.want)
+		}
+	}
+	if _, p := (Boss{HP: 1, MaxHP: 1000}).Phase(); p.Name != "normal" {
+		t.Errorf("boss without phases: phase %q, want normal", p.Name)
+	}
+}
+
+func TestBossHitPhaseResistances(t *testing.T) {
+	tests := []struct {
+		attacker string
+		hp       int64
+		want     float64
+	}{
+		{"water", 1000, 1.5},
+		{"water", 500, 1.5 * 0.7},
+		{"fire", 500, 0.5},
+		{"grass", 500, 0.7},
+		{"wind", 500, 1},
+		{"water", 100, 1.5 * 1.3},
+		{"wind", 100, 1.1},
+	}
+	for _, tt := range tests {
+		b := Boss{Element: "fire", HP: tt.hp, MaxHP: 1000, Phases: StandardPhases("fire")}
+		hit := BossHit(&scriptedRand{floats: []float64{1, 1}}, Fighter{Element: tt.attacker, Level: 1}, b)
+		if diff := hit.Effectiveness - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s at HP %d: effectiveness %v, want %v", tt.attacker, tt.hp, hit.Effectiveness, tt.want)
+		}
+	}
+}
+
+func TestBossHit(t *testing.T) {
+	f := Fighter{Element: "wind", Level: 10, Talents: Talents{Str: 10, Spd: MaxTalent}}
+	b := Boss{Element: "wind", HP: 100, MaxHP: 100}
+	// Power is 50 + 100 + 30 = 180; variance adds 10
+	tests := []struct {
+		name   string
+		floats []float64
+		want   Hit
+	}{
+		{"plain", []float64{0.99, 0.99}, Hit{Damage: 190, Effectiveness: 1}},
+		{"crit", []float64{0, 0.99}, Hit{Damage: 285, Effectiveness: 1, Crit: true}},
+		{"double hit", []float64{0.99, 0.1}, Hit{Damage: 380, Effectiveness: 1, DoubleHit: true}},
+		{"both", []float64{0, 0.1}, Hit{Damage: 570, Effectiveness: 1, Crit: true, DoubleHit: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BossHit(&scriptedRand{floats: tt.floats, ints: []int{10}}, f, b)
+			if got != tt.want {
+				t.Errorf("BossHit = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBossHitReplays(t *testing.T) {
+	f := Fighter{Element: "water", Level: 25, Stars: 2, Talents: Talents{20, 20, 20, 20, 20, 20},
+		Skills: Effects{Attack: 0.1}, Gear: Effects{BossDamage: 0.2, CritChance: 0.05}}
+	b := Boss{Element: "fire", HP: 5000, MaxHP: 10000, Phases: StandardPhases("fire")}
+	r1, r2 := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+	for i := 0; i < 100; i++ {
+		if a, b := BossHit(r1, f, b), BossHit(r2, f, b); a != b {
+			t.Fatalf("hit %d: %+v != %+v", i, a, b)
+		}
+	}
+}
+
+func TestFighterBonuses(t *testing.T) {
+	base := Fighter{Level: 10, Talents: Talents{Str: 10}}
+	if got := base.Power(); got != 180 {
+		t.Fatalf("base power = %v, want 180", got)
+	}
+	starred := base
+	starred.Stars = 2
+	if got := starred.Power(); got != 216 {
+		t.Errorf("2-star power = %v, want 216", got)
+	}
+	// all_stats raises every talent: +5 Str and +5 Int is 20 more power
+	boosted := base
+	boosted.Gear = Effects{AllStats: 5}
+	if got := boosted.Power(); got != 200 {
+		t.Errorf("all_stats power = %v, want 200", got)
+	}
+	// Intelligence amplifies skill effects; without it they apply as written
+	skilled := base
+	skilled.Skills = Effects{Attack: 0.5}
+	if got := skilled.Power(); got != 270 {
+		t.Errorf("skilled power = %v, want 270", got)
+	}
+	skilled.Talents.Int = MaxTalent
+	if got, want := skilled.Power(), (180.0+31)*1.75; got != want {
+		t.Errorf("skilled power with max Int = %v, want %v", got, want)
+	}
+
+	lucky := Fighter{Talents: Talents{Lck: MaxTalent}, Gear: Effects{CritChance: 1}}
+	if got := lucky.CritChance(); got != maxCrit {
+		t.Errorf("crit chance = %v, want cap %v", got, maxCrit)
+	}
+	fast := Fighter{Talents: Talents{Spd: MaxTalent}, Skills: Effects{AllStats: 10}}
+	if got := fast.DoubleHitChance(); got != maxDoubleHit {
+		t.Errorf("double hit chance = %v, want cap %v", got, maxDoubleHit)
+	}
+}
+
+func TestParseEffects(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Effects
+	}{
+		{`{"attack": 0.1, "crit_chance": 0.05}`, Effects{Attack: 0.1, CritChance: 0.05}},
+		{`{"boss_damage": 0.2, "race_speed": 3}`, Effects{BossDamage: 0.2}},
+		{`{"all_stats": 2}`, Effects{AllStats: 2}},
+		{``, Effects{}},
+		{`not json`, Effects{}},
+	}
+	for _, tt := range tests {
+		if got := ParseEffects([]byte(tt.raw)); got != tt.want {
+			t.Errorf("ParseEffects(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestPartyCombo(t *testing.T) {
+	want := []float64{1, 1, 1.1, 1.2, 1.35, 1.5, 1.5}
+	for n, w := range want {
+		if got := PartyCombo(n); got != w {
+			t.Errorf("PartyCombo(%d) = %v, want %v", n, got, w)
+		}
+	}
+}
+
+func TestStrike(t *testing.T) {
+	f := Fighter{Element: "water", Level: 10, Talents: Talents{Str: 10}}
+	// Power 180 + variance 10 = 190, x1.5 against fire
+	tests := []struct {
+		name   string
+		target Fighter
+		floats []float64
+		want   Hit
+	}{
+		{"neutral", Fighter{Element: "wind"}, []float64{0.99, 0.99}, Hit{Damage: 190, Effectiveness: 1}},
+		{"super effective", Fighter{Element: "fire"}, []float64{0.99, 0.99}, Hit{Damage: 285, Effectiveness: 1.5}},
+		{"maxed guard", Fighter{Element: "wind", Talents: Talents{Vit: MaxTalent}}, []float64{0.99, 0.99}, Hit{Damage: 142, Effectiveness: 1}},
+		{"crit", Fighter{Element: "wind"}, []float64{0, 0.99}, Hit{Damage: 285, Effectiveness: 1, Crit: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Strike(&scriptedRand{floats: tt.floats, ints: []int{10}}, f, tt.target)
+			if got != tt.want {
+				t.Errorf("Strike = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	tank := Fighter{Talents: Talents{Vit: MaxTalent}, Gear: Effects{AllStats: 30}}
+	if got := tank.Guard(); got != maxGuard {
+		t.Errorf("guard = %v, want cap %v", got, maxGuard)
+	}
+}
@@ -0,0 +1,27 @@
+package combat
+
+// Strike rolls one attack of f on another fighter. It draws from rng in the same
+// order as BossHit, and the target's Guard takes its share off before crits.
+func Strike(rng Rand, f, target Fighter) Hit {
+	eff := Effectiveness(f.Element, target.Element)
+
+	dmg := f.Power() + f.variance(rng)
+	dmg *= eff
+	dmg *= 1 - target.Guard()
+	return f.land(rng, dmg, eff)
+}
+
+// Guard is the share of a fighter's incoming damage that vitality blocks: a quarter
+// at a maxed talent, up to 40% with all_stats bonuses.
+func (f Fighter) Guard() float64 {
+	g := 0.25 * float64(f.talents().Vit) / MaxTalent
+	if g > maxGuard {
+		g = maxGuard
+	}
+	return g
+}
+
+// Initiative orders fighters within a round: faster slimes act first.
+func (f Fighter) Initiative() int {
+	return f.talents().Spd
+}
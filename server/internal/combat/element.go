@@ -0,0 +1,46 @@
+package combat
+
+// Elements lists every slime element, in the order of the effectiveness matrix.
+var Elements = []string{"water", "fire", "grass", "light", "dark", "ice", "electric", "poison", "earth", "wind", "celestial"}
+
+var elementIndex = func() map[string]int {
+	m := make(map[string]int, len(Elements))
+	for i, e := range Elements {
+		m[e] = i
+	}
+	return m
+}()
+
+// effectiveness[attacker][defender] multiplies damage: 1.5 super effective, 0.7
+// resisted. Every advantage is mirrored by a resistance the other way, except light
+// and dark, which are both super effective against each other.
+var effectiveness = [11][11]float64{
+	//           wat  fir  gra  lig  drk  ice  ele  poi  ear  win  cel
+	/* wat */ {1.0, 1.5, 0.7, 1.0, 1.0, 1.0, 0.7, 1.0, 1.5, 1.0, 1.0},
+	/* fir */ {0.7, 1.0, 1.5, 1.0, 1.0, 1.5, 1.0, 1.0, 0.7, 1.0, 1.0},
+	/* gra */ {1.5, 0.7, 1.0, 1.0, 1.0, 0.7, 1.0, 0.7, 1.5, 1.0, 1.0},
+	/* lig */ {1.0, 1.0, 1.0, 1.0, 1.5, 1.0, 1.0, 1.0, 1.0, 1.0, 0.7},
+	/* drk */ {1.0, 1.0, 1.0, 1.5, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 0.7},
+	/* ice */ {1.0, 0.7, 1.5, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.5, 1.0},
+	/* ele */ {1.5, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 0.7, 1.5, 1.0},
+	/* poi */ {1.0, 1.0, 1.5, 1.0, 1.0, 1.0, 1.0, 1.0, 0.7, 0.7, 1.0},
+	/* ear */ {0.7, 1.5, 0.7, 1.0, 1.0, 1.0, 1.5, 1.5, 1.0, 1.0, 1.0},
+	/* win */ {1.0, 1.0, 1.0, 1.0, 1.0, 0.7, 0.7, 1.5, 1.0, 1.0, 1.0},
+	/* cel */ {1.0, 1.0, 1.0, 1.5, 1.5, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0},
+}
+
+// Effectiveness returns the damage multiplier of an attack of one element against a
+// defender of another. Unknown elements are neutral.
+func Effectiveness(attacker, defender string) float64 {
+	a, okA := elementIndex[attacker]
+	d, okD := elementIndex[defender]
+	if !okA || !okD {
+		return 1
+	}
+	return effectiveness[a][d]
+}
+
+// SuperEffective reports whether attacker has the advantage over defender.
+func SuperEffective(attacker, defender string) bool {
+	return Effectiveness(attacker, defender) > 1
+}
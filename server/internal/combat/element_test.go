@@ -0,0 +1,38 @@
+package combat
+
+import "testing"
+
+func TestEffectivenessMirrored(t *testing.T) {
+	for _, a := range Elements {
+		for _, d := range Elements {
+			ad, da := Effectiveness(a, d), Effectiveness(d, a)
+			switch {
+			case (a == "light" && d == "dark") || (a == "dark" && d == "light"):
+				if ad != 1.5 || da != 1.5 {
+					t.Errorf("%s vs %s = %v/%v, want 1.5 both ways", a, d, ad, da)
+				}
+			case ad == 1.5 && da != 0.7, ad == 0.7 && da != 1.5, ad == 1 && da != 1:
+				t.Errorf("%s->%s = %v but %s->%s = %v", a, d, ad, d, a, da)
+			}
+		}
+	}
+}
+
+func TestEffectiveness(t *testing.T) {
+	tests := []struct {
+		attacker, defender string
+		want               float64
+	}{
+		{"water", "fire", 1.5},
+		{"fire", "water", 0.7},
+		{"celestial", "dark", 1.5},
+		{"water", "water", 1},
+		{"water", "plasma", 1},
+		{"", "fire", 1},
+	}
+	for _, tt := range tests {
+		if got := Effectiveness(tt.attacker, tt.defender); got != tt.want {
+			t.Errorf("Effectiveness(%q, %q) = %v, want %v", tt.attacker, tt.defender, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package combat is the damage formula shared by every fight: world and guild bosses,
+// and the team battles of package battle. It is pure arithmetic over a Fighter's stats and a seeded Rand, so the same
+// seed always replays the same fight.
+package combat
+
+import "encoding/json"
+
+// Rand is the randomness a fight draws on. game.Rand satisfies it.
+type Rand interface {
+	Float64() float64
+	Intn(n int) int
+}
+
+const (
+	// MaxTalent is the highest value of a single talent.
+	MaxTalent = 31
+
+	baseDamage   = 50
+	baseCrit     = 0.05
+	maxCrit      = 0.5
+	critDamage   = 1.5
+	maxDoubleHit = 0.15
+	maxGuard     = 0.4
+)
+
+// Talents are a slime's six inborn stats, each 0 to MaxTalent.
+type Talents struct {
+	Str int `json:"str"`
+	Vit int `json:"vit"`
+	Spd int `json:"spd"`
+	Int int `json:"int"`
+	Cha int `json:"cha"`
+	Lck int `json:"lck"`
+}
+
+// Effects are the combat bonuses of a learned skill or an equipped accessory, decoded
+// from its effect JSON. Keys that other systems use (race_speed, bath_bonus, ...)
+// are ignored.
+type Effects struct {
+	Attack     float64 `json:"attack"`      // +0.1 = 10% more damage
+	BossDamage float64 `json:"boss_damage"` // like Attack, but only against bosses
+	CritChance float64 `json:"crit_chance"`
+	AllStats   int     `json:"all_stats"` // added to every talent
+}
+
+// ParseEffects decodes an effect JSON object. Malformed JSON has no effect.
+func ParseEffects(raw []byte) Effects {
+	var e Effects
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return Effects{}
+		}
+	}
+	return e
+}
+
+// Plus returns the sum of two sets of effects.
+func (e Effects) Plus(o Effects) Effects {
+	return Effects{
+		Attack:     e.Attack + o.Attack,
+		BossDamage: e.BossDamage + o.BossDamage,
+		CritChance: e.CritChance + o.CritChance,
+		AllStats:   e.AllStats + o.AllStats,
+	}
+}
+
+// Fighter is one slime as combat sees it.
+type Fighter struct {
+	Element string  `json:"element"`
+	Level   int     `json:"level"`
+	Stars   int     `json:"stars"`
+	Talents Talents `json:"talents"`
+	Skills  Effects `json:"skills"` // summed over learned skills
+	Gear    Effects `json:"gear"`   // summed over equipped accessories
+}
+
+// talents applies all_stats bonuses; they may push a talent past MaxTalent.
+func (f Fighter) talents() Talents {
+	bonus := f.Skills.AllStats + f.Gear.AllStats
+	t := f.Talents
+	return Talents{t.Str + bonus, t.Vit + bonus, t.Spd + bonus, t.Int + bonus, t.Cha + bonus, t.Lck + bonus}
+}
+
+// skillAmp scales skill effects with intelligence: a maxed talent makes them 50% stronger.
+func (f Fighter) skillAmp() float64 {
+	return 1 + float64(f.talents().Int)/(2*MaxTalent)
+}
+
+// gearAmp scales accessory effects with charm, the same way.
+func (f Fighter) gearAmp() float64 {
+	return 1 + float64(f.talents().Cha)/(2*MaxTalent)
+}
+
+// Power is the fighter's damage before variance, elements and crits. Strength counts
+// most, and high levels curve upwards; each awakening star adds 10%.
+func (f Fighter) Power() float64 {
+	t := f.talents()
+	p := float64(baseDamage + f.Level*10 + t.Str*3 + t.Int)
+	if f.Level >= 20 {
+		p += float64((f.Level - 20) * 5)
+	}
+	if f.Level >= 30 {
+		p += float64((f.Level - 30) * 8)
+	}
+	p *= 1 + 0.1*float64(f.Stars)
+	p *= 1 + f.Skills.Attack*f.skillAmp() + f.Gear.Attack*f.gearAmp()
+	return p
+}
+
+// CritChance grows with luck, skills and accessories, up to 50%.
+func (f Fighter) CritChance() float64 {
+	c := baseCrit + float64(f.talents().Lck)*0.005 + f.Skills.CritChance*f.skillAmp() + f.Gear.CritChance*f.gearAmp()
+	if c > maxCrit {
+		c = maxCrit
+	}
+	return c
+}
+
+// DoubleHitChance is the chance that speed lands a second blow, up to 15% at a maxed
+// talent.
+func (f Fighter) DoubleHitChance() float64 {
+	c := maxDoubleHit * float64(f.talents().Spd) / MaxTalent
+	if c > maxDoubleHit {
+		c = maxDoubleHit
+	}
+	return c
+}
+
+// MaxHP is how much damage the fighter can take, driven by vitality.
+func (f Fighter) MaxHP() int {
+	t := f.talents()
+	hp := float64(200 + f.Level*20 + t.Vit*10)
+	return int(hp * (1 + 0.1*float64(f.Stars)))
+}
+
+// variance is the random part of a hit, growing with level.
+func (f Fighter) variance(rng Rand) float64 {
+	return float64(rng.Intn(f.Level*5 + 20))
+}
@@ -1,6 +1,9 @@
 package game
 
 import (
+	"encoding/json"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/slimetopia/server/internal/repository"
 )
@@ -15,6 +18,8 @@ type AccessoryDef struct {
 	CostGold int    `json:"cost_gold"`
 	CostGems int    `json:"cost_gems"`
 	SvgOvl   string `json:"svg_overlay"`
+	// Combat bonuses while equipped; empty for purely cosmetic accessories
+	Effect json.RawMessage `json:"effect,omitempty"`
 }
 
 // convertAccessories converts GameAccessory slice to AccessoryDef slice
@@ -30,6 +35,7 @@ func convertAccessories(gameAccs []repository.GameAccessory) []AccessoryDef {
 			CostGold: ga.CostGold,
 			CostGems: ga.CostGems,
 			SvgOvl:   ga.SvgOverlay,
+			Effect:   ga.Effect,
 		}
 	}
 	return defs
@@ -48,20 +54,13 @@ func (h *Handler) GetAccessories(c *fiber.Ctx) error {
 	accessoryDefs := convertAccessories(gameAccs)
 
 	// Get owned accessories
-	rows, err := h.slimeRepo.Pool().Query(ctx,
-		`SELECT accessory_id FROM slime_accessories WHERE user_id = (
-			SELECT id FROM users WHERE id::text = $1 OR provider_id = $1 LIMIT 1
-		)`, userID)
-
-	owned := make(map[int]bool)
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var accID int
-			if rows.Scan(&accID) == nil {
-				owned[accID] = true
-			}
-		}
+	ownedIDs, err := h.accessoryRepo.Owned(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load accessories"})
+	}
+	owned := make(map[int]bool, len(ownedIDs))
+	for _, id := range ownedIDs {
+		owned[id] = true
 	}
 
 	result := make([]fiber.Map, 0, len(accessoryDefs))
@@ -75,6 +74,7 @@ func (h *Handler) GetAccessories(c *fiber.Ctx) error {
 			"cost_gold": def.CostGold,
 			"cost_gems": def.CostGems,
 			"svg_overlay": def.SvgOvl,
+			"effect":    def.Effect,
 			"owned":     owned[def.ID],
 		})
 	}
@@ -110,50 +110,26 @@ func (h *Handler) BuyAccessory(c *fiber.Ctx) error {
 		SvgOvl:   gameAcc.SvgOverlay,
 	}
 
-	// Get user UUID
-	var userUUID string
-	err = h.slimeRepo.Pool().QueryRow(ctx,
-		`SELECT id::text FROM users WHERE id::text = $1 OR provider_id = $1 LIMIT 1`, userID).Scan(&userUUID)
+	// Check if already owned
+	exists, err := h.accessoryRepo.Owns(ctx, userID, def.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user not found"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check accessories"})
 	}
-
-	// Check if already owned
-	var exists bool
-	h.slimeRepo.Pool().QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM slime_accessories WHERE user_id = $1::uuid AND accessory_id = $2)`,
-		userUUID, def.ID).Scan(&exists)
 	if exists {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "already owned"})
 	}
 
 	// Deduct currency
-	if def.CostGold > 0 {
-		tag, err := h.slimeRepo.Pool().Exec(ctx,
-			`UPDATE users SET gold = gold - $1 WHERE id = $2::uuid AND gold >= $1`,
-			def.CostGold, userUUID)
-		if err != nil || tag.RowsAffected() == 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient gold"})
-		}
-	}
+	if err := h.userRepo.SpendCurrency(ctx, userID, int64(def.CostGold), def.CostGems, 0, repository.ReasonAccessory, strconv.Itoa(def.ID)); err != nil {
 	if def.CostGems > 0 {
-		tag, err := h.slimeRepo.Pool().Exec(ctx,
-			`UPDATE users SET gems = gems - $1 WHERE id = $2::uuid AND gems >= $1`,
-			def.CostGems, userUUID)
-		if err != nil || tag.RowsAffected() == 0 {
-			// Refund gold if gems fail
-			if def.CostGold > 0 {
-				h.slimeRepo.Pool().Exec(ctx, `UPDATE users SET gold = gold + $1 WHERE id = $2::uuid`, def.CostGold, userUUID)
-			}
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient gems"})
 		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient gold"})
 	}
 
 	// Grant accessory
-	_, err = h.slimeRepo.Pool().Exec(ctx,
-		`INSERT INTO slime_accessories (user_id, accessory_id) VALUES ($1::uuid, $2) ON CONFLICT DO NOTHING`,
-		userUUID, def.ID)
-	if err != nil {
+	if err := h.accessoryRepo.Grant(ctx, userID, def.ID); err != nil {
+		h.userRepo.AddCurrency(ctx, userID, int64(def.CostGold), def.CostGems, 0, repository.ReasonAccessoryRefund, strconv.Itoa(def.ID))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to save"})
 	}
 
@@ -181,14 +157,6 @@ func (h *Handler) EquipAccessory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
 	}
 
-	// Get user UUID
-	var userUUID string
-	err := h.slimeRepo.Pool().QueryRow(ctx,
-		`SELECT id::text FROM users WHERE id::text = $1 OR provider_id = $1 LIMIT 1`, userID).Scan(&userUUID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user not found"})
-	}
-
 	// Load accessory def from DB
 	gameAcc, err := h.gameDataRepo.GetAccessoryByID(ctx, body.AccessoryID)
 	if err != nil {
@@ -207,38 +175,24 @@ func (h *Handler) EquipAccessory(c *fiber.Ctx) error {
 
 	if body.Unequip {
 		if def.Slot != "" {
-			h.slimeRepo.Pool().Exec(ctx,
-				`DELETE FROM equipped_accessories WHERE slime_id = $1::uuid AND slot = $2`,
-				body.SlimeID, def.Slot)
+			h.accessoryRepo.Unequip(ctx, userID, body.SlimeID, def.Slot)
 		}
 		return c.JSON(fiber.Map{"success": true})
 	}
 
 	// Verify ownership
-	var owned bool
-	h.slimeRepo.Pool().QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM slime_accessories WHERE user_id = $1::uuid AND accessory_id = $2)`,
-		userUUID, def.ID).Scan(&owned)
-	if !owned {
+	if owned, _ := h.accessoryRepo.Owns(ctx, userID, def.ID); !owned {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not owned"})
 	}
 
 	// Verify slime ownership
-	var slimeOwned bool
-	h.slimeRepo.Pool().QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM slimes WHERE id = $1::uuid AND user_id = $2::uuid)`,
-		body.SlimeID, userUUID).Scan(&slimeOwned)
-	if !slimeOwned {
+	slime, err := h.slimeRepo.FindByID(ctx, body.SlimeID)
+	if err != nil || uuidToString(slime.UserID) != userID {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not your slime"})
 	}
 
 	// Equip (upsert by slot)
-	_, err = h.slimeRepo.Pool().Exec(ctx,
-		`INSERT INTO equipped_accessories (slime_id, slot, accessory_id)
-		 VALUES ($1::uuid, $2, $3)
-		 ON CONFLICT (slime_id, slot) DO UPDATE SET accessory_id = EXCLUDED.accessory_id`,
-		body.SlimeID, def.Slot, def.ID)
-	if err != nil {
+	if err := h.accessoryRepo.Equip(ctx, body.SlimeID, def.Slot, def.ID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to equip"})
 	}
 
@@ -266,31 +220,21 @@ func (h *Handler) GetAllEquippedAccessories(c *fiber.Ctx) error {
 		accMap[def.ID] = def
 	}
 
-	rows, err := h.slimeRepo.Pool().Query(ctx,
-		`SELECT ea.slime_id::text, ea.slot, ea.accessory_id
-		 FROM equipped_accessories ea
-		 JOIN slimes s ON s.id = ea.slime_id
-		 JOIN users u ON u.id = s.user_id
-		 WHERE u.id::text = $1 OR u.provider_id = $1`, userID)
+	equipped, err := h.accessoryRepo.EquippedByUser(ctx, userID)
 	if err != nil {
 		return c.JSON(fiber.Map{"equipped_map": fiber.Map{}})
 	}
-	defer rows.Close()
 
 	result := make(map[string][]fiber.Map)
-	for rows.Next() {
-		var slimeID, slot string
-		var accID int
-		if rows.Scan(&slimeID, &slot, &accID) == nil {
-			if def, ok := accMap[accID]; ok {
-				result[slimeID] = append(result[slimeID], fiber.Map{
-					"slot":         slot,
-					"accessory_id": accID,
-					"name":         def.Name,
-					"icon":         def.Icon,
-					"svg_overlay":  def.SvgOvl,
-				})
-			}
+	for _, e := range equipped {
+		if def, ok := accMap[e.AccessoryID]; ok {
+			result[e.SlimeID] = append(result[e.SlimeID], fiber.Map{
+				"slot":         e.Slot,
+				"accessory_id": e.AccessoryID,
+				"name":         def.Name,
+				"icon":         def.Icon,
+				"svg_overlay":  def.SvgOvl,
+			})
 		}
 	}
 
@@ -315,27 +259,21 @@ func (h *Handler) GetSlimeAccessories(c *fiber.Ctx) error {
 		accMap[def.ID] = def
 	}
 
-	rows, err := h.slimeRepo.Pool().Query(ctx,
-		`SELECT slot, accessory_id FROM equipped_accessories WHERE slime_id = $1::uuid`, slimeID)
+	worn, err := h.accessoryRepo.EquippedOn(ctx, slimeID)
 	if err != nil {
 		return c.JSON(fiber.Map{"equipped": []fiber.Map{}})
 	}
-	defer rows.Close()
 
 	equipped := make([]fiber.Map, 0)
-	for rows.Next() {
-		var slot string
-		var accID int
-		if rows.Scan(&slot, &accID) == nil {
-			if def, ok := accMap[accID]; ok {
-				equipped = append(equipped, fiber.Map{
-					"slot":        slot,
-					"accessory_id": accID,
-					"name":        def.Name,
-					"icon":        def.Icon,
-					"svg_overlay": def.SvgOvl,
-				})
-			}
+	for _, e := range worn {
+		if def, ok := accMap[e.AccessoryID]; ok {
+			equipped = append(equipped, fiber.Map{
+				"slot":         e.Slot,
+				"accessory_id": e.AccessoryID,
+				"name":         def.Name,
+				"icon":         def.Icon,
+				"svg_overlay":  def.SvgOvl,
+			})
 		}
 	}
 
@@ -2,6 +2,7 @@ package game
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 // AchievementDef from shared/achievements.json
@@ -38,26 +39,14 @@ func (h *Handler) GetAchievements(c *fiber.Ctx) error {
 		}
 	}
 
-	// Get unlocked achievements from DB
-	rows, err := h.slimeRepo.Pool().Query(ctx,
-		`SELECT achievement_key, unlocked_at FROM achievements WHERE user_id = (
-			SELECT id FROM users WHERE id::text = $1 OR provider_id = $1 LIMIT 1
-		)`, userID)
-
-	unlockedMap := make(map[string]string) // key -> unlocked_at
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var key string
-			var unlockedAt string
-			if err := rows.Scan(&key, &unlockedAt); err == nil {
-				unlockedMap[key] = unlockedAt
-			}
-		}
+	unlockedAt, err := h.achievementRepo.Unlocked(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load achievements"})
 	}
 
 	result := make([]fiber.Map, 0, len(defs))
 	for _, def := range defs {
+		at, unlocked := unlockedAt[def.Key]
 		entry := fiber.Map{
 			"key":         def.Key,
 			"name":        def.Name,
@@ -65,10 +54,10 @@ func (h *Handler) GetAchievements(c *fiber.Ctx) error {
 			"icon":        def.Icon,
 			"reward_gold": def.RewardGold,
 			"reward_gems": def.RewardGems,
-			"unlocked":    unlockedMap[def.Key] != "",
+			"unlocked":    unlocked,
 		}
-		if unlockedMap[def.Key] != "" {
-			entry["unlocked_at"] = unlockedMap[def.Key]
+		if unlocked {
+			entry["unlocked_at"] = at
 		}
 		result = append(result, entry)
 	}
@@ -100,56 +89,33 @@ func (h *Handler) CheckAchievements(c *fiber.Ctx) error {
 		}
 	}
 
-	// Get user's UUID
-	var userUUID string
-	err = h.slimeRepo.Pool().QueryRow(ctx,
-		`SELECT id::text FROM users WHERE id::text = $1 OR provider_id = $1 LIMIT 1`, userID).Scan(&userUUID)
+	unlocked, err := h.achievementRepo.Unlocked(ctx, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user not found"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check achievements"})
 	}
-
-	// Already unlocked
-	rows, err := h.slimeRepo.Pool().Query(ctx,
-		`SELECT achievement_key FROM achievements WHERE user_id = $1::uuid`, userUUID)
+	progress, err := h.achievementRepo.Progress(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check achievements"})
 	}
-	defer rows.Close()
-
-	unlocked := make(map[string]bool)
-	for rows.Next() {
-		var key string
-		rows.Scan(&key)
-		unlocked[key] = true
-	}
 
 	newlyUnlocked := []string{}
 
 	// Check each achievement condition
 	for _, def := range achievementDefs {
-		if unlocked[def.Key] {
+		if _, ok := unlocked[def.Key]; ok {
 			continue
 		}
 
 		met := false
 		switch def.Key {
 		case "first_slime":
-			var cnt int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COUNT(*) FROM slimes WHERE user_id::text = $1`, userID).Scan(&cnt)
-			met = cnt >= 1
+			met = progress.Slimes >= 1
 
 		case "collector_10":
-			var cnt int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COUNT(*) FROM codex_entries WHERE user_id::text = $1`, userID).Scan(&cnt)
-			met = cnt >= 10
+			met = progress.CodexEntries >= 10
 
 		case "collector_30":
-			var cnt int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COUNT(*) FROM codex_entries WHERE user_id::text = $1`, userID).Scan(&cnt)
-			met = cnt >= 30
+			met = progress.CodexEntries >= 30
 
 		case "merge_master":
 			// Check merge count via mission progress or custom counter
@@ -157,33 +123,20 @@ func (h *Handler) CheckAchievements(c *fiber.Ctx) error {
 			met = val >= 10
 
 		case "race_champion":
-			var maxScore int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COALESCE(MAX(score), 0) FROM race_results WHERE user_id = $1`, userID).Scan(&maxScore)
-			met = maxScore >= 5000
+			met = progress.BestRaceScore >= 5000
 
 		case "fishing_pro":
 			val, _ := h.rdb.Get(ctx, "ach:fishing_pro:"+userID).Result()
 			met = val == "1"
 
 		case "max_level":
-			var maxLvl int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COALESCE(MAX(level), 0) FROM slimes WHERE user_id::text = $1`, userID).Scan(&maxLvl)
-			met = maxLvl >= 30
+			met = progress.MaxSlimeLevel >= 30
 
 		case "attendance_28":
-			var cnt int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COALESCE(MAX(day_number), 0) FROM attendance WHERE user_id::text = $1`, userID).Scan(&cnt)
-			met = cnt >= 28
+			met = progress.AttendanceDays >= 28
 
 		case "mythic_owner":
-			var cnt int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COUNT(*) FROM slimes s JOIN slime_species sp ON s.species_id = sp.id
-				 WHERE s.user_id::text = $1 AND sp.grade = 'mythic'`, userID).Scan(&cnt)
-			met = cnt >= 1
+			met = progress.MythicSlimes >= 1
 
 		case "social_butterfly":
 			val, _ := h.rdb.Get(ctx, "village_visits:"+userID).Int()
@@ -196,22 +149,16 @@ func (h *Handler) CheckAchievements(c *fiber.Ctx) error {
 			}
 
 		case "explorer":
-			var cnt int
-			h.slimeRepo.Pool().QueryRow(ctx,
-				`SELECT COUNT(*) FROM explorations WHERE user_id::text = $1 AND claimed = true`, userID).Scan(&cnt)
-			met = cnt >= 20
+			met = progress.ExplorationsRun >= 20
 		}
 
 		if met {
-			// Unlock achievement
-			_, err := h.slimeRepo.Pool().Exec(ctx,
-				`INSERT INTO achievements (user_id, achievement_key) VALUES ($1::uuid, $2) ON CONFLICT DO NOTHING`,
-				userUUID, def.Key)
-			if err == nil {
+			// Rewards go to whichever request unlocks it first
+			if ok, err := h.achievementRepo.Unlock(ctx, userID, def.Key); err == nil && ok {
 				newlyUnlocked = append(newlyUnlocked, def.Key)
 				// Grant rewards
 				if def.RewardGold > 0 || def.RewardGems > 0 {
-					h.userRepo.AddCurrency(ctx, userID, int64(def.RewardGold), def.RewardGems, 0)
+					h.userRepo.AddCurrency(ctx, userID, int64(def.RewardGold), def.RewardGems, 0, repository.ReasonAchievement, def.Key)
 				}
 			}
 		}
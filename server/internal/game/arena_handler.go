@@ -0,0 +1,364 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/battle"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// ===== PvP Arena =====
+//
+// Players register a defense team of up to three slimes. Attackers pick one of the
+// opponents matchmaking offers them and the server fights the battle against that
+// team, whether or not its owner is online.
+
+const (
+	arenaTeamSize        = 3
+	arenaAttacksPerDay   = 10
+	arenaOpponentChoices = 3
+	arenaOpponentTTL     = 10 * time.Minute
+)
+
+// parseArenaTeam reads slime_ids: one to arenaTeamSize distinct slimes.
+func parseArenaTeam(ids []string) ([]string, error) {
+	if len(ids) == 0 || len(ids) > arenaTeamSize {
+		return nil, fmt.Errorf("a team is 1 to %d slimes", arenaTeamSize)
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, errors.New("duplicate slime in team")
+		}
+		seen[id] = true
+	}
+	return ids, nil
+}
+
+func arenaOpponentsKey(seasonID int, userID string) string {
+	return fmt.Sprintf("arena_opponents:%d:%s", seasonID, userID)
+}
+
+func arenaAttacksKey(userID string) string {
+	return fmt.Sprintf("arena_attacks:%s:%s", userID, time.Now().Format("2006-01-02"))
+}
+
+// arenaStanding is a player's rating in a season; players who haven't fought yet
+// stand at arenaStartRating.
+func (h *Handler) arenaStanding(ctx context.Context, seasonID int, userID string) (rating, wins, losses int, err error) {
+	s, err := h.arenaRepo.Standing(ctx, seasonID, userID)
+	if errors.Is(err, repository.ErrArenaUnrated) {
+		return arenaStartRating, 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return s.Rating, s.Wins, s.Losses, nil
+}
+
+// GET /api/arena — the current season, my rating and my defense team
+func (h *Handler) GetArena(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.UserContext()
+
+	season, err := h.currentArenaSeason(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("[Arena] failed to load season")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load arena"})
+	}
+	rating, wins, losses, err := h.arenaStanding(ctx, season.ID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load arena"})
+	}
+	var rank *int
+	if wins+losses > 0 {
+		r, _ := h.arenaRepo.Rank(ctx, season.ID, rating)
+		rank = &r
+	}
+
+	defense, _ := h.arenaRepo.Defense(ctx, userID)
+	if defense == nil {
+		defense = []string{}
+	}
+
+	attacks, _ := h.rdb.Get(c.Context(), arenaAttacksKey(userID)).Int()
+	return c.JSON(fiber.Map{
+		"season": fiber.Map{
+			"id":      season.ID,
+			"ends_at": season.EndsAt,
+		},
+		"rating":            rating,
+		"wins":              wins,
+		"losses":            losses,
+		"rank":              rank,
+		"defense":           defense,
+		"remaining_attacks": max(arenaAttacksPerDay-attacks, 0),
+	})
+}
+
+// POST /api/arena/defense — registers the team other players fight against
+func (h *Handler) SetArenaDefense(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.UserContext()
+
+	var body struct {
+		SlimeIDs []string `json:"slime_ids"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+	}
+	ids, err := parseArenaTeam(body.SlimeIDs)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	party, err := h.loadParty(ctx, userID, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load slimes"})
+	}
+	if len(party) != len(ids) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "not your slime"})
+	}
+
+	if err := h.arenaRepo.SetDefense(ctx, userID, ids); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to save defense team"})
+	}
+	return c.JSON(fiber.Map{"success": true, "defense": partyTeam(party)})
+}
+
+// GET /api/arena/opponents — opponents near my rating. The band widens until enough
+// are found; only the opponents offered here can be attacked for a while.
+func (h *Handler) GetArenaOpponents(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.UserContext()
+
+	season, err := h.currentArenaSeason(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load arena"})
+	}
+	rating, _, _, err := h.arenaStanding(ctx, season.ID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load arena"})
+	}
+
+	var opponents []repository.ArenaOpponent
+	for _, band := range arenaRatingBands {
+		opponents, err = h.arenaRepo.Opponents(ctx, season.ID, userID, rating, arenaStartRating, band, arenaOpponentChoices)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to find opponents"})
+		}
+		if len(opponents) >= arenaOpponentChoices {
+			break
+		}
+	}
+
+	key := arenaOpponentsKey(season.ID, userID)
+	pipe := h.rdb.TxPipeline()
+	pipe.Del(c.Context(), key)
+	for _, o := range opponents {
+		pipe.SAdd(c.Context(), key, o.UserID)
+	}
+	pipe.Expire(c.Context(), key, arenaOpponentTTL)
+	if _, err := pipe.Exec(c.Context()); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to find opponents"})
+	}
+
+	if opponents == nil {
+		opponents = []repository.ArenaOpponent{}
+	}
+	return c.JSON(fiber.Map{"rating": rating, "opponents": opponents})
+}
+
+// POST /api/arena/attack — fights an offered opponent's defense team
+func (h *Handler) AttackArena(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.UserContext()
+
+	var body struct {
+		OpponentID string   `json:"opponent_id"`
+		SlimeIDs   []string `json:"slime_ids"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.OpponentID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "opponent_id and slime_ids required"})
+	}
+	ids, err := parseArenaTeam(body.SlimeIDs)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	season, err := h.currentArenaSeason(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load arena"})
+	}
+
+	// Reserve the attack and claim the offer before fighting, so concurrent requests
+	// can't both pass the checks. Both are given back if the battle isn't recorded.
+	attackKey := arenaAttacksKey(userID)
+	attackCount, err := h.rdb.Incr(c.Context(), attackKey).Result()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start battle"})
+	}
+	h.rdb.ExpireAt(c.Context(), attackKey, time.Now().Add(24*time.Hour))
+	if attackCount > arenaAttacksPerDay {
+		h.rdb.Decr(c.Context(), attackKey)
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "daily attack limit reached", "remaining": 0})
+	}
+	offersKey := arenaOpponentsKey(season.ID, userID)
+	claimed, err := h.rdb.SRem(c.Context(), offersKey, body.OpponentID).Result()
+	if err != nil || claimed != 1 {
+		h.rdb.Decr(c.Context(), attackKey)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "opponent not offered; refresh opponents"})
+	}
+	recorded := false
+	defer func() {
+		if recorded {
+			return
+		}
+		ctx := context.Background()
+		h.rdb.Decr(ctx, attackKey)
+		pipe := h.rdb.TxPipeline()
+		pipe.SAdd(ctx, offersKey, body.OpponentID)
+		pipe.Expire(ctx, offersKey, arenaOpponentTTL)
+		pipe.Exec(ctx)
+	}()
+
+	attackParty, err := h.loadParty(ctx, userID, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load slimes"})
+	}
+	if len(attackParty) != len(ids) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "not your slime"})
+	}
+
+	defenseIDs, err := h.arenaRepo.Defense(ctx, body.OpponentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "opponent has no defense team"})
+	}
+	// Slimes merged or released since registering drop out of the team
+	defenseParty, err := h.loadParty(ctx, body.OpponentID, defenseIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load opponent"})
+	}
+	if len(defenseParty) == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "opponent has no defense team"})
+	}
+
+	// The battle log carries the seed and both teams, so it replays by itself
+	seed := h.seeds.Seed()
+	replay := battle.Run(seed, partyTeam(attackParty), partyTeam(defenseParty), battle.Options{})
+	won := replay.AttackerWon()
+	replayJSON, _ := json.Marshal(replay)
+
+	b := &repository.ArenaBattle{
+		SeasonID:    season.ID,
+		AttackerID:  userID,
+		DefenderID:  body.OpponentID,
+		Seed:        seed,
+		AttackerWon: won,
+		Replay:      replayJSON,
+	}
+	err = h.arenaRepo.RecordBattle(ctx, b, arenaStartRating, func(ratings, games [2]int) [2]int {
+		return [2]int{
+			eloDelta(ratings[0], ratings[1], games[0], won),
+			eloDelta(ratings[1], ratings[0], games[1], !won),
+		}
+	})
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("[Arena] failed to record battle")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to record battle"})
+	}
+
+	// One battle per offer; the attacker refreshes for the next one
+	recorded = true
+
+	LogGameAction(h.gameLogRepo, userID, "arena_battle", "arena", 0, 0, 0, map[string]interface{}{
+		"battle_id": b.ID, "opponent_id": body.OpponentID, "won": won, "rating_delta": b.Deltas[0], "seed": seed,
+	})
+
+	return c.JSON(fiber.Map{
+		"battle_id":         b.ID,
+		"won":               won,
+		"rating":            b.Ratings[0] + b.Deltas[0],
+		"rating_delta":      b.Deltas[0],
+		"replay":            replay,
+		"remaining_attacks": arenaAttacksPerDay - int(attackCount),
+	})
+}
+
+// GET /api/arena/battles — my recent battles, attacking and defending
+func (h *Handler) GetArenaBattles(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	summaries, err := h.arenaRepo.Battles(c.UserContext(), userID, 30)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch battles"})
+	}
+
+	battles := make([]fiber.Map, 0, len(summaries))
+	for _, b := range summaries {
+		battles = append(battles, fiber.Map{
+			"id":           b.ID,
+			"attacking":    b.Attacking,
+			"opponent":     b.Opponent,
+			"won":          b.AttackerWon == b.Attacking,
+			"rating_delta": b.Delta,
+			"created_at":   b.CreatedAt,
+		})
+	}
+	return c.JSON(fiber.Map{"battles": battles})
+}
+
+// GET /api/arena/battles/:id — a battle's replay, for either side of it
+func (h *Handler) GetArenaBattle(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	battleID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid battle id"})
+	}
+
+	b, err := h.arenaRepo.Battle(c.UserContext(), battleID)
+	if err != nil || (userID != b.AttackerID && userID != b.DefenderID) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "battle not found"})
+	}
+	return c.JSON(fiber.Map{
+		"id":          b.ID,
+		"attacker_id": b.AttackerID,
+		"defender_id": b.DefenderID,
+		"seed":        b.Seed,
+		"replay":      b.Replay,
+		"created_at":  b.CreatedAt,
+	})
+}
+
+// GET /api/arena/ranking — the current season's top 100
+func (h *Handler) GetArenaRanking(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	season, err := h.currentArenaSeason(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load arena"})
+	}
+
+	standings, err := h.arenaRepo.Ranking(ctx, season.ID, 100)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch ranking"})
+	}
+
+	ranking := make([]fiber.Map, 0, len(standings))
+	for i, st := range standings {
+		ranking = append(ranking, fiber.Map{
+			"rank":     i + 1,
+			"user_id":  st.UserID,
+			"nickname": st.Nickname,
+			"rating":   st.Rating,
+			"wins":     st.Wins,
+			"losses":   st.Losses,
+		})
+	}
+	return c.JSON(fiber.Map{"season_id": season.ID, "ends_at": season.EndsAt, "ranking": ranking})
+}
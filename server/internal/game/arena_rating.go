@@ -0,0 +1,74 @@
+package game
+
+import "math"
+
+// ===== Arena Ratings =====
+//
+// Ratings are Elo, kept per arena season. New players move faster until they have
+// played enough games for their rating to mean something.
+
+const (
+	arenaStartRating      = 1000
+	arenaProvisionalGames = 10
+	arenaProvisionalK     = 40
+	arenaK                = 24
+)
+
+// arenaRatingBands are how far from the attacker's rating matchmaking looks, widest
+// last; 0 means any rating.
+var arenaRatingBands = []int{100, 200, 400, 0}
+
+// eloExpected is the chance a player rated r beats one rated opp.
+func eloExpected(r, opp int) float64 {
+	return 1 / (1 + math.Pow(10, float64(opp-r)/400))
+}
+
+// eloDelta is the rating change of a player rated r after a game against one rated
+// opp, having played games before it. A game always moves the rating by at least 1.
+func eloDelta(r, opp, games int, won bool) int {
+	k := arenaK
+	if games < arenaProvisionalGames {
+		k = arenaProvisionalK
+	}
+	score := 0.0
+	if won {
+		score = 1
+	}
+	d := int(math.Round(float64(k) * (score - eloExpected(r, opp))))
+	switch {
+	case won && d < 1:
+		d = 1
+	case !won && d > -1:
+		d = -1
+	}
+	// Ratings never drop below zero
+	if r+d < 0 {
+		d = -r
+	}
+	return d
+}
+
+// arenaRewardTier is one band of the final season ranking.
+type arenaRewardTier struct {
+	Name    string
+	MaxRank int // 0 = everyone else who played
+	Gold    int64
+	Gems    int
+}
+
+var arenaRewardTiers = []arenaRewardTier{
+	{Name: "top1", MaxRank: 1, Gold: 50000, Gems: 500},
+	{Name: "top10", MaxRank: 10, Gold: 20000, Gems: 200},
+	{Name: "top100", MaxRank: 100, Gold: 10000, Gems: 100},
+	{Name: "participation", Gold: 3000, Gems: 20},
+}
+
+// arenaReward returns the reward tier for a final season rank.
+func arenaReward(rank int) arenaRewardTier {
+	for _, t := range arenaRewardTiers {
+		if t.MaxRank > 0 && rank <= t.MaxRank {
+			return t
+		}
+	}
+	return arenaRewardTiers[len(arenaRewardTiers)-1]
+}
@@ -0,0 +1,56 @@
+package game
+
+import "testing"
+
+func TestEloDelta(t *testing.T) {
+	tests := []struct {
+		name         string
+		r, opp, game int
+		won          bool
+		want         int
+	}{
+		{"even, provisional win", 1000, 1000, 0, true, 20},
+		{"even, provisional loss", 1000, 1000, 0, false, -20},
+		{"even, settled win", 1000, 1000, 10, true, 12},
+		{"upset win", 1000, 1400, 10, true, 22},
+		{"expected win", 1400, 1000, 10, true, 2},
+		{"expected win floors at 1", 2400, 1000, 10, true, 1},
+		{"expected loss floors at -1", 1000, 2400, 10, false, -1},
+		{"never below zero", 10, 1000, 0, false, -1},
+		{"zero stays zero", 0, 1000, 0, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eloDelta(tt.r, tt.opp, tt.game, tt.won); got != tt.want {
+				t.Errorf("eloDelta(%d, %d, %d, %v) = %d, want %d", tt.r, tt.opp, tt.game, tt.won, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEloZeroSumBetweenEquals(t *testing.T) {
+	for _, r := range []int{800, 1000, 1500} {
+		if w, l := eloDelta(r, r, 20, true), eloDelta(r, r, 20, false); w+l != 0 {
+			t.Errorf("rating %d: win %d, loss %d don't cancel", r, w, l)
+		}
+	}
+}
+
+func TestArenaReward(t *testing.T) {
+	tests := []struct {
+		rank int
+		want string
+	}{
+		{1, "top1"},
+		{2, "top10"},
+		{10, "top10"},
+		{11, "top100"},
+		{100, "top100"},
+		{101, "participation"},
+	}
+	for _, tt := range tests {
+		if got := arenaReward(tt.rank).Name; got != tt.want {
+			t.Errorf("arenaReward(%d) = %q, want %q", tt.rank, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// ===== Arena Seasons =====
+//
+// Ratings reset every season. When a season ends the job ranks everyone who played,
+// mails their rewards and marks it settled; the next season starts as soon as one is
+// needed.
+
+const arenaSeasonLength = 14 * 24 * time.Hour
+
+// arenaRewardMailTTL is how long season reward mail waits in the mailbox.
+const arenaRewardMailTTL = 14 * 24 * time.Hour
+
+// currentArenaSeason returns the running season, starting one if there is none.
+func (h *Handler) currentArenaSeason(ctx context.Context) (*repository.ArenaSeason, error) {
+	s, started, err := h.arenaRepo.CurrentSeason(ctx, arenaSeasonLength)
+	if err != nil {
+		return nil, err
+	}
+	if started {
+		log.Info().Int("season_id", s.ID).Time("ends_at", s.EndsAt).Msg("[Arena] season started")
+	}
+	return s, nil
+}
+
+// settleArenaSeason mails the final ranking rewards of an ended season. It is a no-op
+// for a season that is still running or already settled.
+func (h *Handler) settleArenaSeason(ctx context.Context, seasonID int) error {
+	title := fmt.Sprintf("아레나 시즌 %d 보상", seasonID)
+	settled, rewarded, err := h.arenaRepo.SettleSeason(ctx, seasonID, time.Now().Add(arenaRewardMailTTL),
+		func(rank, rating int) repository.ArenaSeasonReward {
+			tier := arenaReward(rank)
+			return repository.ArenaSeasonReward{
+				Tier:      tier.Name,
+				Gold:      tier.Gold,
+				Gems:      tier.Gems,
+				MailTitle: title,
+				MailBody:  fmt.Sprintf("아레나 시즌 %d이 종료되었습니다. 최종 순위 %d위 (레이팅 %d)", seasonID, rank, rating),
+			}
+		})
+	if err != nil {
+		return err
+	}
+	if settled {
+		log.Info().Int("season_id", seasonID).Int("rewarded", rewarded).Msg("[Arena] season settled")
+	}
+	return nil
+}
+
+// RotateArenaSeason settles every ended arena season as a scheduled job, then makes
+// sure a new one is running. A failed settlement is retried on the next run.
+func (h *Handler) RotateArenaSeason(ctx context.Context) error {
+	ended, err := h.arenaRepo.EndedSeasons(ctx)
+	if err != nil {
+		return fmt.Errorf("list ended seasons: %w", err)
+	}
+
+	var errs []error
+	for _, id := range ended {
+		if err := h.settleArenaSeason(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("settle season %d: %w", id, err))
+		}
+	}
+	if _, err := h.currentArenaSeason(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("start season: %w", err))
+	}
+	return errors.Join(errs...)
+}
@@ -3,78 +3,53 @@ package game
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
 )
 
-// BotActivityManager runs periodic background tasks that simulate real player behavior.
-// It selects random bot accounts and performs community and gameplay actions.
+// BotActivityManager simulates real player behavior. Each Tick selects random bot
+// accounts and performs community and gameplay actions; the job scheduler runs it.
 type BotActivityManager struct {
-	pool     *pgxpool.Pool
-	interval time.Duration
-	stopCh   chan struct{}
+	bots   repository.BotStore
+	users  repository.UserStore
+	slimes repository.SlimeStore
+	seeds  Seeder
+	rng    Rand // seeded per tick; the scheduler never overlaps ticks
 }
 
-// NewBotActivityManager creates a new manager with the given DB pool and tick interval.
-func NewBotActivityManager(pool *pgxpool.Pool, interval time.Duration) *BotActivityManager {
+// NewBotActivityManager creates a new manager acting through the given stores.
+func NewBotActivityManager(bots repository.BotStore, users repository.UserStore, slimes repository.SlimeStore) *BotActivityManager {
 	return &BotActivityManager{
-		pool:     pool,
-		interval: interval,
-		stopCh:   make(chan struct{}),
+		bots:   bots,
+		users:  users,
+		slimes: slimes,
+		seeds:  cryptoSeeder{},
 	}
 }
 
-// Start launches the background goroutine. Call Stop() to terminate it.
-func (m *BotActivityManager) Start() {
-	go m.run()
-	log.Info().Dur("interval", m.interval).Msg("BotActivityManager started")
-}
-
-// Stop signals the background goroutine to stop.
-func (m *BotActivityManager) Stop() {
-	close(m.stopCh)
-	log.Info().Msg("BotActivityManager stopped")
-}
-
-func (m *BotActivityManager) run() {
-	// Run once shortly after boot so the community feels alive quickly.
-	time.Sleep(30 * time.Second)
-	m.tick()
-
-	ticker := time.NewTicker(m.interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			m.tick()
-		case <-m.stopCh:
-			return
-		}
-	}
-}
-
-// tick is the main loop body executed every interval.
-func (m *BotActivityManager) tick() {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
+// Tick runs one round of bot activity.
+func (m *BotActivityManager) Tick(ctx context.Context) error {
 	// Load all bot user IDs.
-	botUsers, err := m.loadBotUsers(ctx)
-	if err != nil || len(botUsers) == 0 {
-		log.Warn().Err(err).Msg("[BotActivity] no bot users found, skipping tick")
-		return
+	botUsers, err := m.bots.IDs(ctx, botEmailLike)
+	if err != nil {
+		return fmt.Errorf("load bot users: %w", err)
+	}
+	if len(botUsers) == 0 {
+		log.Warn().Msg("[BotActivity] no bot users found, skipping tick")
+		return nil
 	}
 
+	seed := m.seeds.Seed()
+	m.rng = NewRand(seed)
+
 	// Select 5-15 active bots for this tick.
-	activeCount := randBetween(5, 15)
+	activeCount := rollBetween(m.rng, 5, 15)
 	if activeCount > len(botUsers) {
 		activeCount = len(botUsers)
 	}
-	rand.Shuffle(len(botUsers), func(i, j int) { botUsers[i], botUsers[j] = botUsers[j], botUsers[i] })
+	m.rng.Shuffle(len(botUsers), func(i, j int) { botUsers[i], botUsers[j] = botUsers[j], botUsers[i] })
 	activeBots := botUsers[:activeCount]
 
 	totalPosts := 0
@@ -84,28 +59,28 @@ func (m *BotActivityManager) tick() {
 
 	for _, bot := range activeBots {
 		// Each bot does 1-3 random actions.
-		actions := randBetween(1, 3)
+		actions := rollBetween(m.rng, 1, 3)
 		for a := 0; a < actions; a++ {
-			roll := rand.Intn(100)
+			roll := m.rng.Intn(100)
 			switch {
 			case roll < 25:
 				// 25% — write a community post
-				if m.botWritePost(ctx, bot.ID) {
+				if m.botWritePost(ctx, bot) {
 					totalPosts++
 				}
 			case roll < 55:
 				// 30% — write a comment on an existing post
-				if m.botWriteReply(ctx, bot.ID) {
+				if m.botWriteReply(ctx, bot) {
 					totalReplies++
 				}
 			case roll < 80:
 				// 25% — like a random post
-				if m.botLikePost(ctx, bot.ID) {
+				if m.botLikePost(ctx, bot) {
 					totalLikes++
 				}
 			default:
 				// 20% — simulate gameplay (gold, gems, level, slime updates)
-				if m.botSimulateGameplay(ctx, bot.ID) {
+				if m.botSimulateGameplay(ctx, bot) {
 					totalGameplay++
 				}
 			}
@@ -113,48 +88,21 @@ func (m *BotActivityManager) tick() {
 	}
 
 	log.Info().
+		Int64("seed", seed).
 		Int("active_bots", len(activeBots)).
 		Int("posts", totalPosts).
 		Int("replies", totalReplies).
 		Int("likes", totalLikes).
 		Int("gameplay", totalGameplay).
 		Msg("[BotActivity] tick completed")
-}
-
-// --- data helpers ---
-
-type botUser struct {
-	ID string
-}
-
-func (m *BotActivityManager) loadBotUsers(ctx context.Context) ([]botUser, error) {
-	rows, err := m.pool.Query(ctx, `SELECT id FROM users WHERE email LIKE '%@slimetopia.bot'`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var users []botUser
-	for rows.Next() {
-		var u botUser
-		if err := rows.Scan(&u.ID); err != nil {
-			continue
-		}
-		users = append(users, u)
-	}
-	return users, nil
+	return nil
 }
 
 // --- community actions ---
 
 func (m *BotActivityManager) botWritePost(ctx context.Context, userID string) bool {
-	post := activityPostTemplates[rand.Intn(len(activityPostTemplates))]
-	_, err := m.pool.Exec(ctx,
-		`INSERT INTO community_posts (user_id, content, post_type, image_urls)
-		 VALUES ($1, $2, $3, '{}')`,
-		userID, post.Content, post.PostType,
-	)
-	if err != nil {
+	post := activityPostTemplates[m.rng.Intn(len(activityPostTemplates))]
+	if _, err := m.bots.CreatePost(ctx, userID, post.Content, post.PostType, 0, 0, time.Now()); err != nil {
 		log.Debug().Err(err).Str("user", userID).Msg("[BotActivity] failed to write post")
 		return false
 	}
@@ -163,99 +111,52 @@ func (m *BotActivityManager) botWritePost(ctx context.Context, userID string) bo
 
 func (m *BotActivityManager) botWriteReply(ctx context.Context, userID string) bool {
 	// Pick a random recent post (last 7 days).
-	var postID string
-	err := m.pool.QueryRow(ctx,
-		`SELECT id FROM community_posts
-		 WHERE created_at > NOW() - INTERVAL '7 days'
-		 ORDER BY RANDOM() LIMIT 1`,
-	).Scan(&postID)
+	postID, err := m.bots.RecentPost(ctx)
 	if err != nil {
 		return false
 	}
 
-	reply := activityReplyTemplates[rand.Intn(len(activityReplyTemplates))]
-	_, err = m.pool.Exec(ctx,
-		`INSERT INTO community_replies (post_id, user_id, content)
-		 VALUES ($1, $2, $3)`,
-		postID, userID, reply,
-	)
-	if err != nil {
+	reply := activityReplyTemplates[m.rng.Intn(len(activityReplyTemplates))]
+	if _, err := m.bots.CreateReply(ctx, postID, userID, reply, "", time.Now()); err != nil {
 		return false
 	}
 
 	// Update reply count on the post.
-	m.pool.Exec(ctx,
-		`UPDATE community_posts SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
+	m.bots.RecountPosts(ctx, []string{postID})
 	return true
 }
 
 func (m *BotActivityManager) botLikePost(ctx context.Context, userID string) bool {
-	var postID string
-	err := m.pool.QueryRow(ctx,
-		`SELECT id FROM community_posts
-		 WHERE created_at > NOW() - INTERVAL '7 days'
-		 ORDER BY RANDOM() LIMIT 1`,
-	).Scan(&postID)
+	postID, err := m.bots.RecentPost(ctx)
 	if err != nil {
 		return false
 	}
-
-	_, err = m.pool.Exec(ctx,
-		`INSERT INTO community_post_likes (post_id, user_id)
-		 VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		postID, userID,
-	)
-	if err != nil {
+	if err := m.bots.LikePost(ctx, postID, userID); err != nil {
 		return false
 	}
 
-	m.pool.Exec(ctx,
-		`UPDATE community_posts SET likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
+	m.bots.RecountPosts(ctx, []string{postID})
 	return true
 }
 
 // --- gameplay simulation ---
 
 func (m *BotActivityManager) botSimulateGameplay(ctx context.Context, userID string) bool {
-	roll := rand.Intn(100)
+	roll := m.rng.Intn(100)
 	switch {
 	case roll < 40:
 		// 40% — gain some gold and gems
-		goldGain := int64(randBetween(50, 500))
-		gemGain := randBetween(0, 5)
-		_, err := m.pool.Exec(ctx,
-			`UPDATE users SET gold = gold + $1, gems = gems + $2 WHERE id = $3`,
-			goldGain, gemGain, userID,
-		)
-		return err == nil
+		goldGain := int64(rollBetween(m.rng, 50, 500))
+		gemGain := rollBetween(m.rng, 0, 5)
+		return m.users.AddCurrency(ctx, userID, goldGain, gemGain, 0, repository.ReasonBotActivity, "") == nil
 
 	case roll < 65:
 		// 25% — level up a random slime by 1
-		var slimeID string
-		err := m.pool.QueryRow(ctx,
-			`SELECT id FROM slimes WHERE user_id = $1 ORDER BY RANDOM() LIMIT 1`,
-			userID,
-		).Scan(&slimeID)
-		if err != nil {
-			return false
-		}
-		_, err = m.pool.Exec(ctx,
-			`UPDATE slimes SET level = LEAST(level + 1, 50), exp = 0, updated_at = NOW() WHERE id = $1`,
-			slimeID,
-		)
-		return err == nil
+		return m.bots.LevelUpSlime(ctx, userID) == nil
 
 	case roll < 85:
 		// 20% — gain user level (small bump)
-		_, err := m.pool.Exec(ctx,
-			`UPDATE users SET level = LEAST(level + 1, 50), stardust = stardust + $1 WHERE id = $2`,
-			randBetween(5, 30), userID,
-		)
-		return err == nil
+		return m.bots.LevelUp(ctx, userID, rollBetween(m.rng, 5, 30)) == nil
 
 	default:
 		// 15% — get a new slime (if under 10 slimes)
@@ -265,42 +166,25 @@ func (m *BotActivityManager) botSimulateGameplay(ctx context.Context, userID str
 
 func (m *BotActivityManager) botGetNewSlime(ctx context.Context, userID string) bool {
 	// Check slime count.
-	var count int
-	m.pool.QueryRow(ctx, `SELECT COUNT(*) FROM slimes WHERE user_id = $1`, userID).Scan(&count)
+	count, _ := m.slimes.CountByUser(ctx, userID)
 	if count >= 10 {
 		return false
 	}
 
-	element := allBotElements[rand.Intn(len(allBotElements))]
-	personality := allBotPersonalities[rand.Intn(len(allBotPersonalities))]
+	element := allBotElements[m.rng.Intn(len(allBotElements))]
+	personality := allBotPersonalities[m.rng.Intn(len(allBotPersonalities))]
 
 	// Pick a species matching the element, falling back to ID 1.
-	var speciesID int
-	err := m.pool.QueryRow(ctx,
-		`SELECT id FROM slime_species WHERE element = $1 ORDER BY RANDOM() LIMIT 1`,
-		element,
-	).Scan(&speciesID)
+	speciesID, err := m.bots.RandomSpecies(ctx, element)
 	if err != nil {
 		speciesID = 1
 	}
 
-	level := randBetween(1, 10)
-	_, err = m.pool.Exec(ctx,
-		`INSERT INTO slimes (user_id, species_id, element, personality, level, exp, affection, hunger, condition)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		userID, speciesID, element, personality, level, 0,
-		randBetween(30, 80), randBetween(40, 100), randBetween(50, 100),
-	)
-	if err != nil {
-		return false
-	}
-
-	// Also add to codex.
-	m.pool.Exec(ctx,
-		`INSERT INTO codex_entries (user_id, species_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		userID, speciesID,
-	)
-	return true
+	// CreateSlime also adds the species to the codex.
+	level := rollBetween(m.rng, 1, 10)
+	_, err = m.bots.CreateSlime(ctx, userID, speciesID, element, personality, level, 0,
+		rollBetween(m.rng, 30, 80), rollBetween(m.rng, 40, 100), rollBetween(m.rng, 50, 100))
+	return err == nil
 }
 
 // =====================================================================
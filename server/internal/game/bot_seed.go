@@ -3,11 +3,9 @@ package game
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -115,19 +113,19 @@ var botReplyContents = []string{
 // allBotElements and allBotPersonalities used for random slime generation
 var allBotElements = []string{"water", "fire", "grass", "light", "dark", "ice", "electric", "poison", "earth", "wind"}
 var allBotPersonalities = []string{"energetic", "chill", "foodie", "curious", "tsundere", "gentle"}
+
+// botEmailLike matches the email of every seeded bot, activity bots included.
+const botEmailLike = "%@slimetopia.bot"
+
 var allBotGrades = []string{"common", "uncommon", "rare", "epic", "legendary", "mythic"}
 
 // SeedBots creates 100 bot users with slimes, community posts, and collection entries.
 // It is idempotent: if bot users already exist, it skips creation.
 func (h *Handler) SeedBots(c *fiber.Ctx) error {
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Check if bots already exist
-	var botCount int
-	err := pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM users WHERE email LIKE '%@slimetopia.bot'`,
-	).Scan(&botCount)
+	botCount, err := h.botRepo.Count(ctx, botEmailLike)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check existing bots"})
 	}
@@ -163,6 +161,8 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 		speciesElementMap[sp.ID] = sp.Element
 	}
 
+	rng, seed := h.newRand()
+
 	createdBots := 0
 	totalSlimes := 0
 	totalPosts := 0
@@ -179,35 +179,23 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 		nickname := botNicknames[i]
 
 		// Check if this specific bot already exists
-		var exists bool
-		pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, email).Scan(&exists)
-		if exists {
-			// Still get the user ID for cross-interactions
-			var uid string
-			pool.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, email).Scan(&uid)
-			if uid != "" {
-				botUserIDs = append(botUserIDs, uid)
-			}
+		if uid, _ := h.botRepo.ID(ctx, email); uid != "" {
+			// Still use it for cross-interactions
+			botUserIDs = append(botUserIDs, uid)
 			continue
 		}
 
 		// Determine bot tier for realistic variety
 		tier := getBotTier(i)
 
-		gold := randBetween(tier.minGold, tier.maxGold)
-		gems := randBetween(tier.minGems, tier.maxGems)
-		level := randBetween(tier.minLevel, tier.maxLevel)
-		stardust := randBetween(0, level*10)
+		gold := randBetween(rng, tier.minGold, tier.maxGold)
+		gems := randBetween(rng, tier.minGems, tier.maxGems)
+		level := randBetween(rng, tier.minLevel, tier.maxLevel)
+		stardust := randBetween(rng, 0, level*10)
 
 		// Create user
-		var userID string
-		err := pool.QueryRow(ctx,
-			`INSERT INTO users (nickname, provider, provider_id, gold, gems, stardust, level, email, password_hash)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-			 RETURNING id`,
-			nickname, "bot", fmt.Sprintf("bot_%d", i+1),
-			int64(gold), gems, stardust, level, email, string(passwordHash),
-		).Scan(&userID)
+		userID, err := h.botRepo.Create(ctx, nickname, fmt.Sprintf("bot_%d", i+1), email, string(passwordHash),
+			level, int64(gold), gems, stardust)
 		if err != nil {
 			log.Error().Err(err).Str("nickname", nickname).Msg("Failed to create bot user")
 			continue
@@ -216,61 +204,43 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 		createdBots++
 
 		// Create village for the bot
-		seedBotVillage(ctx, pool, userID, nickname)
+		h.seedBotVillage(ctx, rng, userID, nickname)
 
 		// Create slimes (3-10 based on tier)
-		slimeCount := randBetween(tier.minSlimes, tier.maxSlimes)
+		slimeCount := randBetween(rng, tier.minSlimes, tier.maxSlimes)
 		var createdSlimeIDs []string
 		for j := 0; j < slimeCount; j++ {
-			speciesID, element := pickBotSpecies(tier, speciesByGrade, speciesElementMap)
-			personality := allBotPersonalities[rand.Intn(len(allBotPersonalities))]
-			slimeLevel := randBetween(1, level)
+			speciesID, element := pickBotSpecies(rng, tier, speciesByGrade, speciesElementMap)
+			personality := allBotPersonalities[rng.Intn(len(allBotPersonalities))]
+			slimeLevel := randBetween(rng, 1, level)
 			if slimeLevel < 1 {
 				slimeLevel = 1
 			}
-			slimeExp := rand.Intn(50)
-			affection := randBetween(20, 90)
-			hunger := randBetween(30, 100)
-			condition := randBetween(40, 100)
-
-			var slimeID string
-			err := pool.QueryRow(ctx,
-				`INSERT INTO slimes (user_id, species_id, element, personality, level, exp, affection, hunger, condition)
-				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-				 RETURNING id`,
-				userID, speciesID, element, personality,
-				slimeLevel, slimeExp, affection, hunger, condition,
-			).Scan(&slimeID)
+			slimeExp := rng.Intn(50)
+			affection := randBetween(rng, 20, 90)
+			hunger := randBetween(rng, 30, 100)
+			condition := randBetween(rng, 40, 100)
+
+			slimeID, err := h.botRepo.CreateSlime(ctx, userID, speciesID, element, personality,
+				slimeLevel, slimeExp, affection, hunger, condition)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to create bot slime")
 				continue
 			}
 			createdSlimeIDs = append(createdSlimeIDs, slimeID)
 			totalSlimes++
-
-			// Add to codex
-			pool.Exec(ctx,
-				`INSERT INTO codex_entries (user_id, species_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-				userID, speciesID,
-			)
 		}
 
 		// Create community posts (0-5 based on tier)
-		postCount := randBetween(tier.minPosts, tier.maxPosts)
+		postCount := randBetween(rng, tier.minPosts, tier.maxPosts)
 		for j := 0; j < postCount; j++ {
-			post := botPostContents[rand.Intn(len(botPostContents))]
+			post := botPostContents[rng.Intn(len(botPostContents))]
 			// Randomize creation time within last 30 days
-			createdAt := time.Now().Add(-time.Duration(rand.Intn(30*24)) * time.Hour)
-			likes := randBetween(0, 20)
-			viewCount := likes + randBetween(0, 50)
-
-			var postID string
-			err := pool.QueryRow(ctx,
-				`INSERT INTO community_posts (user_id, content, post_type, likes, view_count, created_at)
-				 VALUES ($1, $2, $3, $4, $5, $6)
-				 RETURNING id`,
-				userID, post.Content, post.PostType, likes, viewCount, createdAt,
-			).Scan(&postID)
+			createdAt := time.Now().Add(-time.Duration(rng.Intn(30*24)) * time.Hour)
+			likes := randBetween(rng, 0, 20)
+			viewCount := likes + randBetween(rng, 0, 50)
+
+			postID, err := h.botRepo.CreatePost(ctx, userID, post.Content, post.PostType, likes, viewCount, createdAt)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to create bot community post")
 				continue
@@ -280,42 +250,33 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 		}
 
 		// Create collection entries (0-5 based on tier)
-		collectionCount := randBetween(tier.minCollection, tier.maxCollection)
+		collectionCount := randBetween(rng, tier.minCollection, tier.maxCollection)
 		submittedPairs := make(map[string]bool)
 		for j := 0; j < collectionCount; j++ {
-			speciesID, _ := pickBotSpecies(tier, speciesByGrade, speciesElementMap)
-			personality := allBotPersonalities[rand.Intn(len(allBotPersonalities))]
+			speciesID, _ := pickBotSpecies(rng, tier, speciesByGrade, speciesElementMap)
+			personality := allBotPersonalities[rng.Intn(len(allBotPersonalities))]
 			pairKey := fmt.Sprintf("%d_%s", speciesID, personality)
 			if submittedPairs[pairKey] {
 				continue
 			}
 			submittedPairs[pairKey] = true
 
-			_, err := pool.Exec(ctx,
-				`INSERT INTO collection_entries (user_id, species_id, personality) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
-				userID, speciesID, personality,
-			)
-			if err == nil {
+			if h.botRepo.AddCollectionEntry(ctx, userID, speciesID, personality) == nil {
 				totalCollections++
 			}
 		}
 
 		// Create race results for some bots (adds to leaderboard)
-		if rand.Float64() < 0.6 && len(createdSlimeIDs) > 0 {
-			raceCount := randBetween(1, 5)
+		if rng.Float64() < 0.6 && len(createdSlimeIDs) > 0 {
+			raceCount := randBetween(rng, 1, 5)
 			for r := 0; r < raceCount; r++ {
-				score := randBetween(50, 300+level*10)
-				goldReward := randBetween(10, 100)
-				expReward := randBetween(5, 30)
-				slimeID := createdSlimeIDs[rand.Intn(len(createdSlimeIDs))]
-				playedAt := time.Now().Add(-time.Duration(rand.Intn(14*24)) * time.Hour)
-
-				_, err := pool.Exec(ctx,
-					`INSERT INTO race_results (user_id, slime_id, score, gold_reward, exp_reward, played_at)
-					 VALUES ($1, $2, $3, $4, $5, $6)`,
-					userID, slimeID, score, goldReward, expReward, playedAt,
-				)
-				if err == nil {
+				score := randBetween(rng, 50, 300+level*10)
+				goldReward := randBetween(rng, 10, 100)
+				expReward := randBetween(rng, 5, 30)
+				slimeID := createdSlimeIDs[rng.Intn(len(createdSlimeIDs))]
+				playedAt := time.Now().Add(-time.Duration(rng.Intn(14*24)) * time.Hour)
+
+				if h.botRepo.AddRaceResult(ctx, userID, slimeID, score, goldReward, expReward, playedAt) == nil {
 					totalRaceResults++
 				}
 			}
@@ -331,56 +292,32 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 			maxReplies = 200
 		}
 		for i := 0; i < maxReplies; i++ {
-			postID := botPostIDs[rand.Intn(len(botPostIDs))]
-			replyUserID := botUserIDs[rand.Intn(len(botUserIDs))]
-			replyContent := botReplyContents[rand.Intn(len(botReplyContents))]
-			createdAt := time.Now().Add(-time.Duration(rand.Intn(29*24)) * time.Hour)
-
-			var replyID string
-			err := pool.QueryRow(ctx,
-				`INSERT INTO community_replies (post_id, user_id, content, created_at)
-				 VALUES ($1, $2, $3, $4)
-				 RETURNING id`,
-				postID, replyUserID, replyContent, createdAt,
-			).Scan(&replyID)
-			if err == nil {
+			postID := botPostIDs[rng.Intn(len(botPostIDs))]
+			replyUserID := botUserIDs[rng.Intn(len(botUserIDs))]
+			replyContent := botReplyContents[rng.Intn(len(botReplyContents))]
+			createdAt := time.Now().Add(-time.Duration(rng.Intn(29*24)) * time.Hour)
+
+			if _, err := h.botRepo.CreateReply(ctx, postID, replyUserID, replyContent, "", createdAt); err == nil {
 				totalReplies++
 			}
 		}
 
-		// Update reply counts on posts
-		for _, postID := range botPostIDs {
-			pool.Exec(ctx,
-				`UPDATE community_posts SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = $1) WHERE id = $1`,
-				postID,
-			)
-		}
-
 		// Add likes from bots to posts
 		maxLikes := len(botPostIDs) * 3
 		if maxLikes > 300 {
 			maxLikes = 300
 		}
 		for i := 0; i < maxLikes; i++ {
-			postID := botPostIDs[rand.Intn(len(botPostIDs))]
-			likerID := botUserIDs[rand.Intn(len(botUserIDs))]
-
-			_, err := pool.Exec(ctx,
-				`INSERT INTO community_post_likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-				postID, likerID,
-			)
-			if err == nil {
+			postID := botPostIDs[rng.Intn(len(botPostIDs))]
+			likerID := botUserIDs[rng.Intn(len(botUserIDs))]
+
+			if h.botRepo.LikePost(ctx, postID, likerID) == nil {
 				totalLikes++
 			}
 		}
 
-		// Update like counts on posts
-		for _, postID := range botPostIDs {
-			pool.Exec(ctx,
-				`UPDATE community_posts SET likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = $1) WHERE id = $1`,
-				postID,
-			)
-		}
+		// Update reply and like counts on posts
+		h.botRepo.RecountPosts(ctx, botPostIDs)
 	}
 
 	log.Info().
@@ -391,6 +328,7 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 		Int("likes", totalLikes).
 		Int("collections", totalCollections).
 		Int("race_results", totalRaceResults).
+		Int64("seed", seed).
 		Msg("Bot seeding completed")
 
 	return c.JSON(fiber.Map{
@@ -408,16 +346,12 @@ func (h *Handler) SeedBots(c *fiber.Ctx) error {
 // DeleteBots removes all bot users and their associated data (cascading)
 func (h *Handler) DeleteBots(c *fiber.Ctx) error {
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
-	tag, err := pool.Exec(ctx,
-		`DELETE FROM users WHERE email LIKE '%@slimetopia.bot'`,
-	)
+	deleted, err := h.botRepo.Delete(ctx, botEmailLike)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to delete bots"})
 	}
 
-	deleted := tag.RowsAffected()
 	log.Info().Int64("deleted", deleted).Msg("Bot users deleted")
 
 	return c.JSON(fiber.Map{
@@ -498,13 +432,13 @@ func getBotTier(index int) botTier {
 }
 
 // pickBotSpecies selects a random species based on tier grade weights
-func pickBotSpecies(tier botTier, speciesByGrade map[string][]int, speciesElementMap map[int]string) (int, string) {
+func pickBotSpecies(rng Rand, tier botTier, speciesByGrade map[string][]int, speciesElementMap map[int]string) (int, string) {
 	// Weighted random grade selection
 	totalWeight := 0
 	for _, w := range tier.gradeWeights {
 		totalWeight += w
 	}
-	r := rand.Intn(totalWeight)
+	r := rng.Intn(totalWeight)
 
 	cumulative := 0
 	selectedGrade := "common"
@@ -525,7 +459,7 @@ func pickBotSpecies(tier botTier, speciesByGrade map[string][]int, speciesElemen
 		return 1, "water"
 	}
 
-	speciesID := species[rand.Intn(len(species))]
+	speciesID := species[rng.Intn(len(species))]
 	element := speciesElementMap[speciesID]
 	if element == "" {
 		element = "water"
@@ -533,29 +467,24 @@ func pickBotSpecies(tier botTier, speciesByGrade map[string][]int, speciesElemen
 	return speciesID, element
 }
 
-func randBetween(lo, hi int) int {
+func randBetween(rng Rand, lo, hi int) int {
 	if lo >= hi {
 		return lo
 	}
-	return lo + rand.Intn(hi-lo+1)
+	return lo + rng.Intn(hi-lo+1)
 }
 
-func seedBotVillage(ctx context.Context, pool *pgxpool.Pool, userID, nickname string) {
+func (h *Handler) seedBotVillage(ctx context.Context, rng Rand, userID, nickname string) {
 	terrains := []string{"grass", "sand", "snow", "lava"}
-	terrain := terrains[rand.Intn(len(terrains))]
+	terrain := terrains[rng.Intn(len(terrains))]
 	villageName := nickname + "의 마을"
 	// Truncate if too long (max 30 chars)
 	nameRunes := []rune(villageName)
 	if len(nameRunes) > 30 {
 		villageName = "슬라임 마을"
 	}
-	visitCount := rand.Intn(50)
-	likes := rand.Intn(20)
-
-	pool.Exec(ctx,
-		`INSERT INTO villages (user_id, name, terrain, visit_count, likes)
-		 VALUES ($1, $2, $3, $4, $5)
-		 ON CONFLICT (user_id) DO NOTHING`,
-		userID, villageName, terrain, visitCount, likes,
-	)
+	visitCount := rng.Intn(50)
+	likes := rng.Intn(20)
+
+	h.botRepo.CreateVillage(ctx, userID, villageName, terrain, visitCount, likes)
 }
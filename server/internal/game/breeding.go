@@ -0,0 +1,328 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// ===== Breeding =====
+//
+// Unlike a merge, breeding keeps both parents: they lay an egg that hatches after
+// breedHatchTime. Each bred slime gets a slime_lineage row with its parents and
+// genes, which family trees are built from.
+
+const (
+	familyTreeDefaultDepth = 3
+	familyTreeMaxDepth     = 6
+)
+
+// breedParentOf loads a slime as a parent, with the genes its lineage recorded.
+func (h *Handler) breedParentOf(ctx context.Context, slimeID string, l *repository.BreedLineage) (breedParent, Genes, error) {
+	slime, err := h.slimeRepo.FindByID(ctx, slimeID)
+	if err != nil {
+		return breedParent{}, Genes{}, err
+	}
+	p := breedParent{Slime: *slime, ParentIDs: l.ParentIDs, LastBred: l.LastBred}
+	genes := founderGenes(*slime)
+	if l.Genes != nil {
+		if err := json.Unmarshal(l.Genes, &genes); err != nil {
+			return p, Genes{}, err
+		}
+	}
+	return p, genes, nil
+}
+
+// POST /api/breeding — two parents lay an egg
+func (h *Handler) BreedSlimes(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var body struct {
+		SlimeIDA string `json:"slime_id_a"`
+		SlimeIDB string `json:"slime_id_b"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.SlimeIDA == "" || body.SlimeIDB == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "slime_id_a and slime_id_b required"})
+	}
+	for _, id := range []string{body.SlimeIDA, body.SlimeIDB} {
+		if _, err := uuid.Parse(id); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid slime id"})
+		}
+	}
+	if body.SlimeIDA == body.SlimeIDB {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": ErrBreedSame.Error()})
+	}
+	ctx := c.Context()
+	parentIDs := []string{body.SlimeIDA, body.SlimeIDB}
+
+	onExp, err := h.explorationRepo.IsSlimeOnExploration(ctx, userID, parentIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check exploration status"})
+	}
+	if onExp {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "one or more slimes are on exploration"})
+	}
+	inTraining, err := h.slimeRepo.IsSlimeInTraining(ctx, userID, parentIDs)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check training status"})
+	}
+	if inTraining {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "one or more slimes are in training"})
+	}
+
+	rng, seed := h.newRand()
+	var breedErr error
+	egg, err := h.breedingRepo.LayEgg(ctx, userID, body.SlimeIDA, body.SlimeIDB, func(la, lb *repository.BreedLineage) (*repository.SlimeEgg, error) {
+		a, genesA, err := h.breedParentOf(ctx, body.SlimeIDA, la)
+		if err != nil {
+			return nil, err
+		}
+		b, genesB, err := h.breedParentOf(ctx, body.SlimeIDB, lb)
+		if err != nil {
+			return nil, err
+		}
+		if breedErr = checkBreedable(a, b, time.Now()); breedErr != nil {
+			return nil, nil
+		}
+
+		// The egg takes after one parent's species and element
+		like := a.Slime
+		if rng.Intn(2) == 1 {
+			like = b.Slime
+		}
+		genes := BreedGenes(rng, genesA, genesB)
+		talents := BreedTalents(rng, a.Slime, b.Slime, genes)
+		genesJSON, _ := json.Marshal(genes)
+		return &repository.SlimeEgg{
+			ParentASpecies: a.Slime.SpeciesID,
+			ParentBSpecies: b.Slime.SpeciesID,
+			SpeciesID:      like.SpeciesID,
+			Element:        like.Element,
+			Personality:    genes.ExpressedPersonality(),
+			Talents:        talents[:],
+			Genes:          genesJSON,
+			Generation:     max(la.Generation, lb.Generation) + 1,
+			HatchesAt:      time.Now().Add(breedHatchTime),
+		}, nil
+	})
+	if errors.Is(err, repository.ErrBreedNotOwned) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("[Breeding] failed to lay egg")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "breeding failed"})
+	}
+	if breedErr != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(breedErr, ErrBreedCooldown) {
+			status = fiber.StatusTooManyRequests
+		}
+		return c.Status(status).JSON(fiber.Map{"error": breedErr.Error()})
+	}
+
+	LogGameAction(h.gameLogRepo, userID, "breed", "item", 0, 0, 0, map[string]interface{}{
+		"parent_a": body.SlimeIDA, "parent_b": body.SlimeIDB, "egg_id": egg.ID, "species_id": egg.SpeciesID, "seed": seed,
+	})
+
+	return c.JSON(fiber.Map{
+		"egg": fiber.Map{
+			"id":         egg.ID,
+			"species_id": egg.SpeciesID,
+			"hatches_at": egg.HatchesAt,
+		},
+		"cooldown_seconds": int(breedCooldown.Seconds()),
+	})
+}
+
+// GET /api/breeding/eggs — eggs waiting to hatch
+func (h *Handler) GetBreedingEggs(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	waiting, err := h.breedingRepo.Eggs(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch eggs"})
+	}
+
+	now := time.Now()
+	eggs := make([]fiber.Map, 0, len(waiting))
+	for _, e := range waiting {
+		eggs = append(eggs, fiber.Map{
+			"id":         e.ID,
+			"species_id": e.SpeciesID,
+			"parent_a":   e.ParentA,
+			"parent_b":   e.ParentB,
+			"hatches_at": e.HatchesAt,
+			"ready":      !now.Before(e.HatchesAt),
+			"created_at": e.CreatedAt,
+		})
+	}
+	return c.JSON(fiber.Map{"eggs": eggs})
+}
+
+// POST /api/breeding/eggs/:id/hatch
+func (h *Handler) HatchBredEgg(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	eggID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid egg id"})
+	}
+	ctx := c.Context()
+
+	egg, child, err := h.breedingRepo.HatchEgg(ctx, userID, eggID)
+	switch {
+	case errors.Is(err, repository.ErrEggNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, repository.ErrEggNotReady):
+		left := time.Until(egg.HatchesAt)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "remaining_seconds": int(left.Seconds()) + 1})
+	case errors.Is(err, repository.ErrSlimeBoxFull):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Error().Err(err).Int64("egg_id", eggID).Msg("[Breeding] failed to hatch egg")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to hatch egg"})
+	}
+	var genes Genes
+	json.Unmarshal(egg.Genes, &genes)
+
+	LogGameAction(h.gameLogRepo, userID, "hatch_bred_egg", "item", 0, 0, 0, map[string]interface{}{
+		"egg_id": eggID, "slime_id": uuidToString(child.ID), "species_id": egg.SpeciesID, "color_variant": genes.ColorVariant(),
+	})
+
+	return c.JSON(fiber.Map{
+		"slime":         slimeToMap(*child),
+		"color_variant": genes.ColorVariant(),
+		"genes":         genes,
+	})
+}
+
+// FamilyNode is one slime of a family tree. Ancestors that have since been merged,
+// released or given away are still listed, with what their descendants' lineage recorded of them.
+type FamilyNode struct {
+	ID           string        `json:"id"`
+	SpeciesID    int           `json:"species_id"`
+	Name         *string       `json:"name,omitempty"`
+	Element      string        `json:"element,omitempty"`
+	Personality  string        `json:"personality,omitempty"`
+	Level        int           `json:"level,omitempty"`
+	Generation   int           `json:"generation"`
+	ColorVariant string        `json:"color_variant"`
+	Alive        bool          `json:"alive"`
+	Parents      []*FamilyNode `json:"parents,omitempty"`
+}
+
+// lineageRecord is a slime_lineage row.
+type lineageRecord struct {
+	SlimeID                        string
+	ParentA, ParentB               string
+	ParentASpecies, ParentBSpecies int
+	SpeciesID                      int
+	Generation                     int
+	Genes                          Genes
+}
+
+// familySlime is a living slime that appears in a tree.
+type familySlime struct {
+	SpeciesID   int
+	Name        *string
+	Element     string
+	Personality string
+	Level       int
+}
+
+// buildFamilyTree assembles the tree of id down to depth generations (the slime
+// itself is the first). speciesID is used when id is neither alive nor bred.
+func buildFamilyTree(id string, speciesID, depth int, lineage map[string]lineageRecord, living map[string]familySlime) *FamilyNode {
+	node := &FamilyNode{ID: id, SpeciesID: speciesID, ColorVariant: colorNormal}
+	rec, bred := lineage[id]
+	if bred {
+		node.SpeciesID = rec.SpeciesID
+		node.Generation = rec.Generation
+		node.ColorVariant = rec.Genes.ColorVariant()
+	}
+	if s, ok := living[id]; ok {
+		node.Alive = true
+		node.SpeciesID = s.SpeciesID
+		node.Name = s.Name
+		node.Element = s.Element
+		node.Personality = s.Personality
+		node.Level = s.Level
+	}
+	if bred && depth > 1 {
+		node.Parents = []*FamilyNode{
+			buildFamilyTree(rec.ParentA, rec.ParentASpecies, depth-1, lineage, living),
+			buildFamilyTree(rec.ParentB, rec.ParentBSpecies, depth-1, lineage, living),
+		}
+	}
+	return node
+}
+
+// GET /api/slimes/:id/family-tree?depth=3
+func (h *Handler) GetFamilyTree(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	slimeID := c.Params("id")
+	depth := c.QueryInt("depth", familyTreeDefaultDepth)
+	if depth < 1 {
+		depth = 1
+	}
+	if depth > familyTreeMaxDepth {
+		depth = familyTreeMaxDepth
+	}
+	ctx := c.Context()
+
+	root, err := h.slimeRepo.FindByID(ctx, slimeID)
+	if err != nil || uuidToString(root.UserID) != userID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "slime not found"})
+	}
+
+	records, err := h.breedingRepo.Lineage(ctx, slimeID, depth)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load family tree"})
+	}
+
+	lineage := make(map[string]lineageRecord, len(records))
+	ids := []string{slimeID}
+	for _, r := range records {
+		rec := lineageRecord{
+			SlimeID:        r.SlimeID,
+			ParentA:        r.ParentA,
+			ParentB:        r.ParentB,
+			ParentASpecies: r.ParentASpecies,
+			ParentBSpecies: r.ParentBSpecies,
+			SpeciesID:      r.SpeciesID,
+			Generation:     r.Generation,
+		}
+		if err := json.Unmarshal(r.Genes, &rec.Genes); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load family tree"})
+		}
+		lineage[r.SlimeID] = rec
+		ids = append(ids, r.ParentA, r.ParentB)
+	}
+
+	// Only the player's own slimes count as alive; the rest show what lineage recorded
+	relatives, err := h.breedingRepo.FamilySlimes(ctx, userID, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load family tree"})
+	}
+	living := make(map[string]familySlime, len(relatives))
+	for _, s := range relatives {
+		living[s.ID] = familySlime{
+			SpeciesID:   s.SpeciesID,
+			Name:        s.Name,
+			Element:     s.Element,
+			Personality: s.Personality,
+			Level:       s.Level,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"depth": depth,
+		"tree":  buildFamilyTree(slimeID, root.SpeciesID, depth, lineage, living),
+	})
+}
@@ -1,7 +1,10 @@
 package game
 
 import (
+	"fmt"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 // Grade -> instant rewards for collection submission
@@ -69,25 +72,16 @@ func (h *Handler) SubmitToCollection(c *fiber.Ctx) error {
 	}
 
 	// 4. Check if already submitted (species x personality)
-	pool := h.slimeRepo.Pool()
-	var existing int
-	err = pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM collection_entries WHERE user_id = $1 AND species_id = $2 AND personality = $3`,
-		userID, slime.SpeciesID, slime.Personality,
-	).Scan(&existing)
+	existing, err := h.collectionRepo.Has(ctx, userID, slime.SpeciesID, slime.Personality)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check collection"})
 	}
-	if existing > 0 {
+	if existing {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "already_submitted"})
 	}
 
 	// 4b. Check if this is the first entry for this species (for 2x reward)
-	var speciesEntryCount int
-	pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM collection_entries WHERE user_id = $1 AND species_id = $2`,
-		userID, slime.SpeciesID,
-	).Scan(&speciesEntryCount)
+	speciesEntryCount, _ := h.collectionRepo.SpeciesCount(ctx, userID, slime.SpeciesID)
 	isFirstOfSpecies := speciesEntryCount == 0
 
 	// 5. Check if slime is on exploration
@@ -100,13 +94,13 @@ func (h *Handler) SubmitToCollection(c *fiber.Ctx) error {
 	}
 
 	// 6. Insert into collection_entries
-	_, err = pool.Exec(ctx,
-		`INSERT INTO collection_entries (user_id, species_id, personality) VALUES ($1, $2, $3)`,
-		userID, slime.SpeciesID, slime.Personality,
-	)
+	submitted, err := h.collectionRepo.Submit(ctx, userID, slime.SpeciesID, slime.Personality)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to submit"})
 	}
+	if !submitted {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "already_submitted"})
+	}
 
 	// 7. Delete slime
 	if err := h.slimeRepo.Delete(ctx, body.SlimeID); err != nil {
@@ -117,7 +111,7 @@ func (h *Handler) SubmitToCollection(c *fiber.Ctx) error {
 	h.missionRepo.IncrementProgress(ctx, userID, "submit")
 
 	// Log collection submit
-	LogGameAction(pool, userID, "collection_submit", "collection", 0, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "collection_submit", "collection", 0, 0, 0, map[string]interface{}{
 		"slime_id": body.SlimeID, "species_id": slime.SpeciesID, "grade": species.Grade, "personality": slime.Personality, "level": slime.Level,
 	})
 
@@ -130,12 +124,11 @@ func (h *Handler) SubmitToCollection(c *fiber.Ctx) error {
 		gemReward *= 2
 	}
 	if goldReward > 0 || gemReward > 0 {
-		h.userRepo.AddCurrency(ctx, userID, goldReward, gemReward, 0)
+		h.userRepo.AddCurrency(ctx, userID, goldReward, gemReward, 0, repository.ReasonCollection, body.SlimeID)
 	}
 
 	// 10. Return updated collection count + rewards
-	var count int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM collection_entries WHERE user_id = $1`, userID).Scan(&count)
+	count, _ := h.collectionRepo.Count(ctx, userID)
 
 	return c.JSON(fiber.Map{
 		"success":             true,
@@ -152,28 +145,19 @@ func (h *Handler) SubmitToCollection(c *fiber.Ctx) error {
 func (h *Handler) GetCollectionEntries(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
-	rows, err := pool.Query(ctx,
-		`SELECT species_id, personality FROM collection_entries WHERE user_id = $1 ORDER BY species_id, personality`,
-		userID,
-	)
+	rows, err := h.collectionRepo.Entries(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch entries"})
 	}
-	defer rows.Close()
 
 	type Entry struct {
 		SpeciesID   int    `json:"species_id"`
 		Personality string `json:"personality"`
 	}
-	entries := make([]Entry, 0)
-	for rows.Next() {
-		var e Entry
-		if err := rows.Scan(&e.SpeciesID, &e.Personality); err != nil {
-			continue
-		}
-		entries = append(entries, e)
+	entries := make([]Entry, 0, len(rows))
+	for _, e := range rows {
+		entries = append(entries, Entry{SpeciesID: e.SpeciesID, Personality: e.Personality})
 	}
 
 	return c.JSON(fiber.Map{"entries": entries})
@@ -204,25 +188,19 @@ var collectionMilestones = []struct {
 func (h *Handler) GetCollectionMilestones(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Get collection count
-	var count int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM collection_entries WHERE user_id = $1`, userID).Scan(&count)
+	count, _ := h.collectionRepo.Count(ctx, userID)
 
 	// Get claimed milestones
-	rows, err := pool.Query(ctx, `SELECT milestone FROM collection_milestones WHERE user_id = $1`, userID)
+	milestones, err := h.collectionRepo.ClaimedMilestones(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch milestones"})
 	}
-	defer rows.Close()
 
 	claimed := make(map[int]bool)
-	for rows.Next() {
-		var m int
-		if rows.Scan(&m) == nil {
-			claimed[m] = true
-		}
+	for _, m := range milestones {
+		claimed[m] = true
 	}
 
 	type MilestoneStatus struct {
@@ -274,33 +252,27 @@ func (h *Handler) ClaimCollectionMilestone(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Check collection count
-	var count int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM collection_entries WHERE user_id = $1`, userID).Scan(&count)
+	count, _ := h.collectionRepo.Count(ctx, userID)
 	if count < body.Milestone {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "milestone_not_reached"})
 	}
 
-	// Check if already claimed
-	var exists bool
-	pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM collection_milestones WHERE user_id = $1 AND milestone = $2)`, userID, body.Milestone).Scan(&exists)
-	if exists {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "already_claimed"})
-	}
-
 	// Claim: insert record + grant rewards
-	_, err := pool.Exec(ctx, `INSERT INTO collection_milestones (user_id, milestone) VALUES ($1, $2)`, userID, body.Milestone)
+	claimedNow, err := h.collectionRepo.ClaimMilestone(ctx, userID, body.Milestone)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to claim"})
 	}
+	if !claimedNow {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "already_claimed"})
+	}
 
 	// Grant gold + gems
-	pool.Exec(ctx, `UPDATE users SET gold = gold + $1, gems = gems + $2 WHERE id = $3`, gold, gems, userID)
+	h.userRepo.AddCurrency(ctx, userID, int64(gold), gems, 0, repository.ReasonCollection, fmt.Sprintf("milestone:%d", body.Milestone))
 
 	// Log
-	LogGameAction(pool, userID, "milestone_claim", "collection", int64(gold), gems, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "milestone_claim", "collection", int64(gold), gems, 0, map[string]interface{}{
 		"milestone": body.Milestone,
 	})
 
@@ -2,6 +2,7 @@ package game
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -11,7 +12,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 const (
@@ -39,139 +40,51 @@ func (h *Handler) GetCommunityPosts(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	postType := c.Query("type", "")
 	page := c.QueryInt("page", 0)
-	offset := page * postsPerPage
 	searchQuery := c.Query("q", "")
 
-	ctx := c.UserContext()
-	pool := h.slimeRepo.Pool()
-
-	// Get blocked user IDs for filtering
-	blockedIDs := h.getBlockedUserIDs(c, userID)
-
-	type scannable interface {
-		Close()
-		Next() bool
-		Scan(...interface{}) error
-	}
-
-	var rows scannable
-	var err error
-
-	if searchQuery != "" {
-		// Hybrid search: tsvector + ILIKE
-		r, e := pool.Query(ctx,
-			`SELECT p.id, p.user_id, u.nickname, p.content, p.post_type, p.likes, p.reply_count, p.created_at,
-			        p.image_urls, p.view_count,
-			        EXISTS(SELECT 1 FROM community_post_likes l WHERE l.post_id = p.id AND l.user_id = $1) as liked,
-			        COALESCE(u.profile_image_url, ''),
-			        p.reaction_counts, p.bookmark_count,
-			        EXISTS(SELECT 1 FROM community_bookmarks b WHERE b.post_id = p.id AND b.user_id = $1) as bookmarked,
-			        (SELECT emoji FROM community_post_reactions WHERE post_id = p.id AND user_id = $1) as my_reaction
-			 FROM community_posts p
-			 JOIN users u ON u.id = p.user_id
-			 WHERE (p.search_vector @@ plainto_tsquery('simple', $2) OR p.content ILIKE '%' || $2 || '%')
-			   AND p.user_id NOT IN (SELECT blocked_id FROM community_blocks WHERE blocker_id = $1::uuid)
-			 ORDER BY p.created_at DESC
-			 LIMIT $3 OFFSET $4`,
-			userID, searchQuery, postsPerPage, offset,
-		)
-		if e != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to search posts"})
-		}
-		rows = r
-	} else if postType != "" {
-		r, e := pool.Query(ctx,
-			`SELECT p.id, p.user_id, u.nickname, p.content, p.post_type, p.likes, p.reply_count, p.created_at,
-			        p.image_urls, p.view_count,
-			        EXISTS(SELECT 1 FROM community_post_likes l WHERE l.post_id = p.id AND l.user_id = $1) as liked,
-			        COALESCE(u.profile_image_url, ''),
-			        p.reaction_counts, p.bookmark_count,
-			        EXISTS(SELECT 1 FROM community_bookmarks b WHERE b.post_id = p.id AND b.user_id = $1) as bookmarked,
-			        (SELECT emoji FROM community_post_reactions WHERE post_id = p.id AND user_id = $1) as my_reaction
-			 FROM community_posts p
-			 JOIN users u ON u.id = p.user_id
-			 WHERE p.post_type = $2
-			   AND p.user_id NOT IN (SELECT blocked_id FROM community_blocks WHERE blocker_id = $1::uuid)
-			 ORDER BY p.created_at DESC
-			 LIMIT $3 OFFSET $4`,
-			userID, postType, postsPerPage, offset,
-		)
-		if e != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch posts"})
-		}
-		rows = r
-	} else {
-		r, e := pool.Query(ctx,
-			`SELECT p.id, p.user_id, u.nickname, p.content, p.post_type, p.likes, p.reply_count, p.created_at,
-			        p.image_urls, p.view_count,
-			        EXISTS(SELECT 1 FROM community_post_likes l WHERE l.post_id = p.id AND l.user_id = $1) as liked,
-			        COALESCE(u.profile_image_url, ''),
-			        p.reaction_counts, p.bookmark_count,
-			        EXISTS(SELECT 1 FROM community_bookmarks b WHERE b.post_id = p.id AND b.user_id = $1) as bookmarked,
-			        (SELECT emoji FROM community_post_reactions WHERE post_id = p.id AND user_id = $1) as my_reaction
-			 FROM community_posts p
-			 JOIN users u ON u.id = p.user_id
-			 WHERE p.user_id NOT IN (SELECT blocked_id FROM community_blocks WHERE blocker_id = $1::uuid)
-			 ORDER BY p.created_at DESC
-			 LIMIT $2 OFFSET $3`,
-			userID, postsPerPage, offset,
-		)
-		if e != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch posts"})
-		}
-		rows = r
-	}
-	_ = err
-	_ = blockedIDs
-
-	defer rows.Close()
-
-	posts := make([]fiber.Map, 0)
-	for rows.Next() {
-		var id, uid, nickname, content, ptype string
-		var likes, replyCount, viewCount, bookmarkCount int
-		var createdAt time.Time
-		var liked, bookmarked bool
-		var imageURLs []string
-		var profileImageURL string
-		var reactionCountsJSON []byte
-		var myReaction *string
-		if err := rows.Scan(&id, &uid, &nickname, &content, &ptype, &likes, &replyCount, &createdAt, &imageURLs, &viewCount, &liked, &profileImageURL, &reactionCountsJSON, &bookmarkCount, &bookmarked, &myReaction); err != nil {
-			continue
-		}
-		if imageURLs == nil {
-			imageURLs = []string{}
-		}
-		var reactionCounts map[string]int
-		json.Unmarshal(reactionCountsJSON, &reactionCounts)
-		if reactionCounts == nil {
-			reactionCounts = map[string]int{}
-		}
-		post := fiber.Map{
-			"id":                id,
-			"user_id":           uid,
-			"nickname":          nickname,
-			"content":           content,
-			"post_type":         ptype,
-			"likes":             likes,
-			"reply_count":       replyCount,
-			"view_count":        viewCount,
-			"image_urls":        imageURLs,
-			"created_at":        createdAt,
-			"liked":             liked,
-			"is_mine":           uid == userID,
-			"profile_image_url": profileImageURL,
-			"reaction_counts":   reactionCounts,
-			"bookmark_count":    bookmarkCount,
-			"bookmarked":        bookmarked,
-		}
-		if myReaction != nil {
-			post["my_reaction"] = *myReaction
+	posts, err := h.communityRepo.Posts(c.UserContext(), userID, postType, searchQuery, postsPerPage, page*postsPerPage)
+	if err != nil {
+		msg := "failed to fetch posts"
+		if searchQuery != "" {
+			msg = "failed to search posts"
 		}
-		posts = append(posts, post)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": msg})
 	}
+	return c.JSON(fiber.Map{"posts": communityPostsJSON(posts, userID), "page": page})
+}
 
-	return c.JSON(fiber.Map{"posts": posts, "page": page})
+// communityPostJSON renders a post for the viewer it was loaded for.
+func communityPostJSON(p repository.CommunityPost, viewerID string) fiber.Map {
+	post := fiber.Map{
+		"id":                p.ID,
+		"user_id":           p.UserID,
+		"nickname":          p.Nickname,
+		"content":           p.Content,
+		"post_type":         p.PostType,
+		"likes":             p.Likes,
+		"reply_count":       p.ReplyCount,
+		"view_count":        p.ViewCount,
+		"image_urls":        p.ImageURLs,
+		"created_at":        p.CreatedAt,
+		"liked":             p.Liked,
+		"is_mine":           p.UserID == viewerID,
+		"profile_image_url": p.ProfileImageURL,
+		"reaction_counts":   p.ReactionCounts,
+		"bookmark_count":    p.BookmarkCount,
+		"bookmarked":        p.Bookmarked,
+	}
+	if p.MyReaction != nil {
+		post["my_reaction"] = *p.MyReaction
+	}
+	return post
+}
+
+func communityPostsJSON(posts []repository.CommunityPost, viewerID string) []fiber.Map {
+	out := make([]fiber.Map, 0, len(posts))
+	for _, p := range posts {
+		out = append(out, communityPostJSON(p, viewerID))
+	}
+	return out
 }
 
 // POST /api/community/posts (multipart/form-data for images)
@@ -249,12 +162,7 @@ func (h *Handler) CreateCommunityPost(c *fiber.Ctx) error {
 		}
 	}
 
-	pool := h.slimeRepo.Pool()
-	var postID string
-	err = pool.QueryRow(c.UserContext(),
-		`INSERT INTO community_posts (user_id, content, post_type, image_urls) VALUES ($1, $2, $3, $4) RETURNING id`,
-		userID, content, postType, imageURLs,
-	).Scan(&postID)
+	postID, err := h.communityRepo.CreatePost(c.UserContext(), userID, content, postType, imageURLs)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to create post"})
 	}
@@ -263,12 +171,7 @@ func (h *Handler) CreateCommunityPost(c *fiber.Ctx) error {
 	if pollOptionsRaw != "" {
 		var pollOptions []string
 		if err := json.Unmarshal([]byte(pollOptionsRaw), &pollOptions); err == nil && len(pollOptions) >= 2 && len(pollOptions) <= 4 {
-			expiresAt := time.Now().Add(24 * time.Hour)
-			optionsJSON, _ := json.Marshal(pollOptions)
-			pool.Exec(c.UserContext(),
-				`INSERT INTO community_polls (post_id, options, expires_at) VALUES ($1, $2, $3)`,
-				postID, optionsJSON, expiresAt,
-			)
+			h.communityRepo.CreatePoll(c.UserContext(), postID, pollOptions, time.Now().Add(24*time.Hour))
 		}
 	}
 
@@ -284,147 +187,60 @@ func (h *Handler) CreateCommunityPost(c *fiber.Ctx) error {
 // POST /api/community/posts/:id/like — Bug fix #1: transaction + atomic + return new count
 func (h *Handler) LikeCommunityPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to like"})
-	}
-	defer tx.Rollback(ctx)
-
-	_, err = tx.Exec(ctx,
-		`INSERT INTO community_post_likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		postID, userID,
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to like"})
-	}
-
-	var newLikes int
-	err = tx.QueryRow(ctx,
-		`UPDATE community_posts SET likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = $1) WHERE id = $1 RETURNING likes`,
-		postID,
-	).Scan(&newLikes)
+	newLikes, err := h.communityRepo.LikePost(c.UserContext(), c.Params("id"), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to like"})
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to like"})
-	}
-
 	return c.JSON(fiber.Map{"success": true, "likes": newLikes})
 }
 
 // POST /api/community/posts/:id/unlike — Bug fix #1: transaction + atomic + return new count
 func (h *Handler) UnlikeCommunityPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
-
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unlike"})
-	}
-	defer tx.Rollback(ctx)
-
-	tx.Exec(ctx, `DELETE FROM community_post_likes WHERE post_id = $1 AND user_id = $2`, postID, userID)
 
-	var newLikes int
-	err = tx.QueryRow(ctx,
-		`UPDATE community_posts SET likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = $1) WHERE id = $1 RETURNING likes`,
-		postID,
-	).Scan(&newLikes)
+	newLikes, err := h.communityRepo.UnlikePost(c.UserContext(), c.Params("id"), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unlike"})
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unlike"})
-	}
-
 	return c.JSON(fiber.Map{"success": true, "likes": newLikes})
 }
 
 // POST /api/community/posts/:id/view
 func (h *Handler) ViewCommunityPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	// Upsert view (unique per user)
-	_, err := pool.Exec(ctx,
-		`INSERT INTO community_post_views (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		postID, userID,
-	)
-	if err != nil {
+	if err := h.communityRepo.RecordView(c.UserContext(), c.Params("id"), userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to record view"})
 	}
-
-	// Update view count
-	pool.Exec(ctx,
-		`UPDATE community_posts SET view_count = (SELECT COUNT(*) FROM community_post_views WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
-
 	return c.JSON(fiber.Map{"success": true})
 }
 
 // GET /api/community/posts/:id/replies — supports hierarchical (parent_id filter)
 func (h *Handler) GetCommunityReplies(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT r.id, r.user_id, u.nickname, r.content, r.likes, r.created_at, r.parent_id, r.reply_count,
-		        EXISTS(SELECT 1 FROM community_reply_likes l WHERE l.reply_id = r.id AND l.user_id = $1) as liked,
-		        COALESCE(u.profile_image_url, '')
-		 FROM community_replies r
-		 JOIN users u ON u.id = r.user_id
-		 WHERE r.post_id = $2
-		   AND r.user_id NOT IN (SELECT blocked_id FROM community_blocks WHERE blocker_id = $1::uuid)
-		 ORDER BY r.created_at ASC
-		 LIMIT 200`,
-		userID, postID,
-	)
+	list, err := h.communityRepo.Replies(c.UserContext(), userID, c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch replies"})
 	}
-	defer rows.Close()
-
-	replies := make([]fiber.Map, 0)
-	for rows.Next() {
-		var id, uid, nickname, content string
-		var likes, replyCount int
-		var createdAt time.Time
-		var liked bool
-		var parentID *string
-		var profileImageURL string
-		if err := rows.Scan(&id, &uid, &nickname, &content, &likes, &createdAt, &parentID, &replyCount, &liked, &profileImageURL); err != nil {
-			continue
-		}
+
+	replies := make([]fiber.Map, 0, len(list))
+	for _, r := range list {
 		reply := fiber.Map{
-			"id":                id,
-			"user_id":           uid,
-			"nickname":          nickname,
-			"content":           content,
-			"likes":             likes,
-			"reply_count":       replyCount,
-			"created_at":        createdAt,
-			"liked":             liked,
-			"is_mine":           uid == userID,
-			"profile_image_url": profileImageURL,
-		}
-		if parentID != nil {
-			reply["parent_id"] = *parentID
+			"id":                r.ID,
+			"user_id":           r.UserID,
+			"nickname":          r.Nickname,
+			"content":           r.Content,
+			"likes":             r.Likes,
+			"reply_count":       r.ReplyCount,
+			"created_at":        r.CreatedAt,
+			"liked":             r.Liked,
+			"is_mine":           r.UserID == userID,
+			"profile_image_url": r.ProfileImageURL,
+		}
+		if r.ParentID != nil {
+			reply["parent_id"] = *r.ParentID
 		}
 		replies = append(replies, reply)
 	}
@@ -449,52 +265,17 @@ func (h *Handler) CreateCommunityReply(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reply too long"})
 	}
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	var replyID string
-	if body.ParentID != nil && *body.ParentID != "" {
-		// Nested reply (1-level only — validate parent has no parent)
-		var parentParentID *string
-		err := pool.QueryRow(ctx,
-			`SELECT parent_id FROM community_replies WHERE id = $1 AND post_id = $2`,
-			*body.ParentID, postID,
-		).Scan(&parentParentID)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "parent reply not found"})
-		}
-		if parentParentID != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot nest replies deeper than 1 level"})
-		}
-
-		err = pool.QueryRow(ctx,
-			`INSERT INTO community_replies (post_id, user_id, content, parent_id) VALUES ($1, $2, $3, $4) RETURNING id`,
-			postID, userID, body.Content, *body.ParentID,
-		).Scan(&replyID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to create reply"})
-		}
-
-		// Update parent reply_count
-		pool.Exec(ctx,
-			`UPDATE community_replies SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE parent_id = $1) WHERE id = $1`,
-			*body.ParentID,
-		)
-	} else {
-		err := pool.QueryRow(ctx,
-			`INSERT INTO community_replies (post_id, user_id, content) VALUES ($1, $2, $3) RETURNING id`,
-			postID, userID, body.Content,
-		).Scan(&replyID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to create reply"})
-		}
+	parentID := ""
+	if body.ParentID != nil {
+		parentID = *body.ParentID
+	}
+	replyID, err := h.communityRepo.CreateReply(c.UserContext(), postID, userID, body.Content, parentID)
+	if errors.Is(err, repository.ErrReplyParentNotFound) || errors.Is(err, repository.ErrReplyTooDeep) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to create reply"})
 	}
-
-	// Update post reply count
-	pool.Exec(ctx,
-		`UPDATE community_posts SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
 
 	return c.JSON(fiber.Map{"id": replyID})
 }
@@ -502,14 +283,9 @@ func (h *Handler) CreateCommunityReply(c *fiber.Ctx) error {
 // DELETE /api/community/posts/:id
 func (h *Handler) DeleteCommunityPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	tag, err := pool.Exec(c.UserContext(),
-		`DELETE FROM community_posts WHERE id = $1 AND user_id = $2`,
-		postID, userID,
-	)
-	if err != nil || tag.RowsAffected() == 0 {
+	deleted, err := h.communityRepo.DeletePost(c.UserContext(), c.Params("id"), userID)
+	if err != nil || !deleted {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "cannot delete"})
 	}
 
@@ -519,44 +295,12 @@ func (h *Handler) DeleteCommunityPost(c *fiber.Ctx) error {
 // Bug fix #4: DELETE /api/community/replies/:id
 func (h *Handler) DeleteCommunityReply(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	replyID := c.Params("id")
-
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	// Get post_id + parent_id before delete
-	var postID string
-	var parentID *string
-	err := pool.QueryRow(ctx,
-		`SELECT post_id, parent_id FROM community_replies WHERE id = $1 AND user_id = $2`,
-		replyID, userID,
-	).Scan(&postID, &parentID)
-	if err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "cannot delete"})
-	}
 
-	tag, err := pool.Exec(ctx,
-		`DELETE FROM community_replies WHERE id = $1 AND user_id = $2`,
-		replyID, userID,
-	)
-	if err != nil || tag.RowsAffected() == 0 {
+	deleted, err := h.communityRepo.DeleteReply(c.UserContext(), c.Params("id"), userID)
+	if err != nil || !deleted {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "cannot delete"})
 	}
 
-	// Update parent reply_count if nested
-	if parentID != nil {
-		pool.Exec(ctx,
-			`UPDATE community_replies SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE parent_id = $1) WHERE id = $1`,
-			*parentID,
-		)
-	}
-
-	// Update post reply count
-	pool.Exec(ctx,
-		`UPDATE community_posts SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
-
 	return c.JSON(fiber.Map{"success": true})
 }
 
@@ -593,22 +337,10 @@ func (h *Handler) handleReport(c *fiber.Ctx, targetType, targetID string) error
 		body.Detail = string(runes[:maxReportDetail])
 	}
 
-	pool := h.slimeRepo.Pool()
-
-	// Prevent duplicate reports
-	var exists bool
-	pool.QueryRow(c.UserContext(),
-		`SELECT EXISTS(SELECT 1 FROM community_reports WHERE reporter_id = $1 AND target_type = $2 AND target_id = $3)`,
-		userID, targetType, targetID,
-	).Scan(&exists)
-	if exists {
+	err := h.communityRepo.Report(c.UserContext(), userID, targetType, targetID, body.Reason, body.Detail)
+	if errors.Is(err, repository.ErrAlreadyReported) {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "already reported"})
 	}
-
-	_, err := pool.Exec(c.UserContext(),
-		`INSERT INTO community_reports (reporter_id, target_type, target_id, reason, detail) VALUES ($1, $2, $3, $4, $5)`,
-		userID, targetType, targetID, body.Reason, body.Detail,
-	)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to report"})
 	}
@@ -625,14 +357,11 @@ func (h *Handler) BlockUser(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot block yourself"})
 	}
 
-	pool := h.slimeRepo.Pool()
-	_, err := pool.Exec(c.UserContext(),
-		`INSERT INTO community_blocks (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		userID, blockedID,
-	)
-	if err != nil {
+	if err := h.communityRepo.Block(c.UserContext(), userID, blockedID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to block"})
 	}
+	// Blocking someone also ends the friendship and any pending request
+	h.friendRepo.Remove(c.UserContext(), userID, blockedID)
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -642,11 +371,7 @@ func (h *Handler) UnblockUser(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	blockedID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	pool.Exec(c.UserContext(),
-		`DELETE FROM community_blocks WHERE blocker_id = $1 AND blocked_id = $2`,
-		userID, blockedID,
-	)
+	h.communityRepo.Unblock(c.UserContext(), userID, blockedID)
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -655,28 +380,16 @@ func (h *Handler) UnblockUser(c *fiber.Ctx) error {
 func (h *Handler) GetBlockedUsers(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT b.blocked_id, u.nickname FROM community_blocks b
-		 JOIN users u ON u.id = b.blocked_id
-		 WHERE b.blocker_id = $1
-		 ORDER BY b.created_at DESC`,
-		userID,
-	)
+	list, err := h.communityRepo.BlockedUsers(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch blocked users"})
 	}
-	defer rows.Close()
 
-	blocks := make([]fiber.Map, 0)
-	for rows.Next() {
-		var blockedID, nickname string
-		if err := rows.Scan(&blockedID, &nickname); err != nil {
-			continue
-		}
+	blocks := make([]fiber.Map, 0, len(list))
+	for _, b := range list {
 		blocks = append(blocks, fiber.Map{
-			"user_id":  blockedID,
-			"nickname": nickname,
+			"user_id":  b.UserID,
+			"nickname": b.Nickname,
 		})
 	}
 
@@ -686,41 +399,18 @@ func (h *Handler) GetBlockedUsers(c *fiber.Ctx) error {
 // POST /api/community/replies/:id/like
 func (h *Handler) LikeCommunityReply(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	replyID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	_, err := pool.Exec(ctx,
-		`INSERT INTO community_reply_likes (reply_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		replyID, userID,
-	)
-	if err != nil {
+	if err := h.communityRepo.LikeReply(c.UserContext(), c.Params("id"), userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to like reply"})
 	}
-
-	pool.Exec(ctx,
-		`UPDATE community_replies SET likes = (SELECT COUNT(*) FROM community_reply_likes WHERE reply_id = $1) WHERE id = $1`,
-		replyID,
-	)
-
 	return c.JSON(fiber.Map{"success": true})
 }
 
 // POST /api/community/replies/:id/unlike
 func (h *Handler) UnlikeCommunityReply(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	replyID := c.Params("id")
-
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	pool.Exec(ctx, `DELETE FROM community_reply_likes WHERE reply_id = $1 AND user_id = $2`, replyID, userID)
-	pool.Exec(ctx,
-		`UPDATE community_replies SET likes = (SELECT COUNT(*) FROM community_reply_likes WHERE reply_id = $1) WHERE id = $1`,
-		replyID,
-	)
 
+	h.communityRepo.UnlikeReply(c.UserContext(), c.Params("id"), userID)
 	return c.JSON(fiber.Map{"success": true})
 }
 
@@ -729,7 +419,6 @@ func (h *Handler) UnlikeCommunityReply(c *fiber.Ctx) error {
 // POST /api/community/posts/:id/react
 func (h *Handler) ReactCommunityPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
 
 	var body struct {
 		Emoji string `json:"emoji"`
@@ -741,86 +430,21 @@ func (h *Handler) ReactCommunityPost(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid emoji"})
 	}
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to react"})
-	}
-	defer tx.Rollback(ctx)
-
-	// Upsert reaction (change if already exists)
-	_, err = tx.Exec(ctx,
-		`INSERT INTO community_post_reactions (post_id, user_id, emoji)
-		 VALUES ($1, $2, $3)
-		 ON CONFLICT (post_id, user_id)
-		 DO UPDATE SET emoji = $3`,
-		postID, userID, body.Emoji,
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to react"})
-	}
-
-	// Rebuild reaction_counts
-	var countsJSON []byte
-	err = tx.QueryRow(ctx,
-		`UPDATE community_posts SET reaction_counts = (
-			SELECT COALESCE(jsonb_object_agg(emoji, cnt), '{}')
-			FROM (SELECT emoji, COUNT(*)::int as cnt FROM community_post_reactions WHERE post_id = $1 GROUP BY emoji) sub
-		 ) WHERE id = $1 RETURNING reaction_counts`,
-		postID,
-	).Scan(&countsJSON)
+	counts, err := h.communityRepo.React(c.UserContext(), c.Params("id"), userID, body.Emoji)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to react"})
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to react"})
-	}
-
-	var counts map[string]int
-	json.Unmarshal(countsJSON, &counts)
-
 	return c.JSON(fiber.Map{"success": true, "reaction_counts": counts, "my_reaction": body.Emoji})
 }
 
 // DELETE /api/community/posts/:id/react
 func (h *Handler) UnreactCommunityPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
-
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
 
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unreact"})
-	}
-	defer tx.Rollback(ctx)
-
-	tx.Exec(ctx, `DELETE FROM community_post_reactions WHERE post_id = $1 AND user_id = $2`, postID, userID)
-
-	// Rebuild reaction_counts
-	var countsJSON []byte
-	err = tx.QueryRow(ctx,
-		`UPDATE community_posts SET reaction_counts = (
-			SELECT COALESCE(jsonb_object_agg(emoji, cnt), '{}')
-			FROM (SELECT emoji, COUNT(*)::int as cnt FROM community_post_reactions WHERE post_id = $1 GROUP BY emoji) sub
-		 ) WHERE id = $1 RETURNING reaction_counts`,
-		postID,
-	).Scan(&countsJSON)
+	counts, err := h.communityRepo.Unreact(c.UserContext(), c.Params("id"), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unreact"})
 	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unreact"})
-	}
-
-	var counts map[string]int
-	json.Unmarshal(countsJSON, &counts)
-
 	return c.JSON(fiber.Map{"success": true, "reaction_counts": counts})
 }
 
@@ -829,41 +453,18 @@ func (h *Handler) UnreactCommunityPost(c *fiber.Ctx) error {
 // POST /api/community/posts/:id/bookmark
 func (h *Handler) BookmarkPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	_, err := pool.Exec(ctx,
-		`INSERT INTO community_bookmarks (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		postID, userID,
-	)
-	if err != nil {
+	if err := h.communityRepo.Bookmark(c.UserContext(), c.Params("id"), userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to bookmark"})
 	}
-
-	pool.Exec(ctx,
-		`UPDATE community_posts SET bookmark_count = (SELECT COUNT(*) FROM community_bookmarks WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
-
 	return c.JSON(fiber.Map{"success": true})
 }
 
 // DELETE /api/community/posts/:id/bookmark
 func (h *Handler) UnbookmarkPost(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	postID := c.Params("id")
-
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	pool.Exec(ctx, `DELETE FROM community_bookmarks WHERE post_id = $1 AND user_id = $2`, postID, userID)
-	pool.Exec(ctx,
-		`UPDATE community_posts SET bookmark_count = (SELECT COUNT(*) FROM community_bookmarks WHERE post_id = $1) WHERE id = $1`,
-		postID,
-	)
 
+	h.communityRepo.Unbookmark(c.UserContext(), c.Params("id"), userID)
 	return c.JSON(fiber.Map{"success": true})
 }
 
@@ -871,64 +472,12 @@ func (h *Handler) UnbookmarkPost(c *fiber.Ctx) error {
 func (h *Handler) GetBookmarks(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	page := c.QueryInt("page", 0)
-	offset := page * postsPerPage
-
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT p.id, p.user_id, u.nickname, p.content, p.post_type, p.likes, p.reply_count, p.created_at,
-		        p.image_urls, p.view_count,
-		        EXISTS(SELECT 1 FROM community_post_likes l WHERE l.post_id = p.id AND l.user_id = $1) as liked,
-		        COALESCE(u.profile_image_url, ''),
-		        p.reaction_counts, p.bookmark_count, true as bookmarked,
-		        (SELECT emoji FROM community_post_reactions WHERE post_id = p.id AND user_id = $1) as my_reaction
-		 FROM community_bookmarks bm
-		 JOIN community_posts p ON p.id = bm.post_id
-		 JOIN users u ON u.id = p.user_id
-		 WHERE bm.user_id = $1
-		 ORDER BY bm.created_at DESC
-		 LIMIT $2 OFFSET $3`,
-		userID, postsPerPage, offset,
-	)
+
+	posts, err := h.communityRepo.Bookmarks(c.UserContext(), userID, postsPerPage, page*postsPerPage)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch bookmarks"})
 	}
-	defer rows.Close()
-
-	posts := make([]fiber.Map, 0)
-	for rows.Next() {
-		var id, uid, nickname, content, ptype string
-		var likes, replyCount, viewCount, bookmarkCount int
-		var createdAt time.Time
-		var liked, bookmarked bool
-		var imageURLs []string
-		var profileImageURL string
-		var reactionCountsJSON []byte
-		var myReaction *string
-		if err := rows.Scan(&id, &uid, &nickname, &content, &ptype, &likes, &replyCount, &createdAt, &imageURLs, &viewCount, &liked, &profileImageURL, &reactionCountsJSON, &bookmarkCount, &bookmarked, &myReaction); err != nil {
-			continue
-		}
-		if imageURLs == nil {
-			imageURLs = []string{}
-		}
-		var reactionCounts map[string]int
-		json.Unmarshal(reactionCountsJSON, &reactionCounts)
-		if reactionCounts == nil {
-			reactionCounts = map[string]int{}
-		}
-		post := fiber.Map{
-			"id": id, "user_id": uid, "nickname": nickname, "content": content,
-			"post_type": ptype, "likes": likes, "reply_count": replyCount,
-			"view_count": viewCount, "image_urls": imageURLs, "created_at": createdAt,
-			"liked": liked, "is_mine": uid == userID, "profile_image_url": profileImageURL,
-			"reaction_counts": reactionCounts, "bookmark_count": bookmarkCount, "bookmarked": bookmarked,
-		}
-		if myReaction != nil {
-			post["my_reaction"] = *myReaction
-		}
-		posts = append(posts, post)
-	}
-
-	return c.JSON(fiber.Map{"posts": posts, "page": page})
+	return c.JSON(fiber.Map{"posts": communityPostsJSON(posts, userID), "page": page})
 }
 
 // ===== NEW: Trending Posts =====
@@ -941,70 +490,17 @@ func (h *Handler) GetTrendingPosts(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT p.id, p.user_id, u.nickname, p.content, p.post_type, p.likes, p.reply_count, p.created_at,
-		        p.image_urls, p.view_count,
-		        EXISTS(SELECT 1 FROM community_post_likes l WHERE l.post_id = p.id AND l.user_id = $1) as liked,
-		        COALESCE(u.profile_image_url, ''),
-		        p.reaction_counts, p.bookmark_count,
-		        EXISTS(SELECT 1 FROM community_bookmarks b WHERE b.post_id = p.id AND b.user_id = $1) as bookmarked,
-		        (SELECT emoji FROM community_post_reactions WHERE post_id = p.id AND user_id = $1) as my_reaction,
-		        (
-		          (SELECT COALESCE(COUNT(*), 0) FROM community_post_reactions WHERE post_id = p.id) * 3
-		          + p.reply_count * 2
-		          + p.view_count * 0.5
-		          + p.bookmark_count * 2
-		        ) / SQRT(EXTRACT(EPOCH FROM (now() - p.created_at)) / 3600 + 2) AS score
-		 FROM community_posts p
-		 JOIN users u ON u.id = p.user_id
-		 WHERE p.created_at > now() - INTERVAL '7 days'
-		   AND p.user_id NOT IN (SELECT blocked_id FROM community_blocks WHERE blocker_id = $1::uuid)
-		 ORDER BY score DESC
-		 LIMIT $2`,
-		userID, limit,
-	)
+	list, err := h.communityRepo.TrendingPosts(c.UserContext(), userID, limit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch trending"})
 	}
-	defer rows.Close()
-
-	posts := make([]fiber.Map, 0)
-	rank := 1
-	for rows.Next() {
-		var id, uid, nickname, content, ptype string
-		var likes, replyCount, viewCount, bookmarkCount int
-		var createdAt time.Time
-		var liked, bookmarked bool
-		var imageURLs []string
-		var profileImageURL string
-		var reactionCountsJSON []byte
-		var myReaction *string
-		var score float64
-		if err := rows.Scan(&id, &uid, &nickname, &content, &ptype, &likes, &replyCount, &createdAt, &imageURLs, &viewCount, &liked, &profileImageURL, &reactionCountsJSON, &bookmarkCount, &bookmarked, &myReaction, &score); err != nil {
-			continue
-		}
-		if imageURLs == nil {
-			imageURLs = []string{}
-		}
-		var reactionCounts map[string]int
-		json.Unmarshal(reactionCountsJSON, &reactionCounts)
-		if reactionCounts == nil {
-			reactionCounts = map[string]int{}
-		}
-		post := fiber.Map{
-			"id": id, "user_id": uid, "nickname": nickname, "content": content,
-			"post_type": ptype, "likes": likes, "reply_count": replyCount,
-			"view_count": viewCount, "image_urls": imageURLs, "created_at": createdAt,
-			"liked": liked, "is_mine": uid == userID, "profile_image_url": profileImageURL,
-			"reaction_counts": reactionCounts, "bookmark_count": bookmarkCount,
-			"bookmarked": bookmarked, "rank": rank, "score": math.Round(score*10) / 10,
-		}
-		if myReaction != nil {
-			post["my_reaction"] = *myReaction
-		}
+
+	posts := make([]fiber.Map, 0, len(list))
+	for i, p := range list {
+		post := communityPostJSON(p, userID)
+		post["rank"] = i + 1
+		post["score"] = math.Round(p.Score*10) / 10
 		posts = append(posts, post)
-		rank++
 	}
 
 	return c.JSON(fiber.Map{"posts": posts})
@@ -1014,28 +510,14 @@ func (h *Handler) GetTrendingPosts(c *fiber.Ctx) error {
 
 // GET /api/community/tags/trending
 func (h *Handler) GetTrendingTags(c *fiber.Ctx) error {
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT tag, COUNT(*) as cnt FROM (
-		   SELECT DISTINCT unnest(regexp_matches(content, '#([^\s#]+)', 'g')) as tag, id
-		   FROM community_posts
-		   WHERE created_at > now() - INTERVAL '7 days'
-		 ) sub
-		 GROUP BY tag ORDER BY cnt DESC LIMIT 10`,
-	)
+	list, err := h.communityRepo.TrendingTags(c.UserContext(), 10)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch tags"})
 	}
-	defer rows.Close()
-
-	tags := make([]fiber.Map, 0)
-	for rows.Next() {
-		var tag string
-		var cnt int
-		if err := rows.Scan(&tag, &cnt); err != nil {
-			continue
-		}
-		tags = append(tags, fiber.Map{"tag": tag, "count": cnt})
+
+	tags := make([]fiber.Map, 0, len(list))
+	for _, t := range list {
+		tags = append(tags, fiber.Map{"tag": t.Tag, "count": t.Count})
 	}
 
 	return c.JSON(fiber.Map{"tags": tags})
@@ -1048,60 +530,28 @@ func (h *Handler) GetPoll(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	postID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
-	var pollID string
-	var optionsJSON []byte
-	var expiresAt time.Time
-	err := pool.QueryRow(ctx,
-		`SELECT id, options, expires_at FROM community_polls WHERE post_id = $1`,
-		postID,
-	).Scan(&pollID, &optionsJSON, &expiresAt)
+	poll, err := h.communityRepo.Poll(ctx, postID)
+	if errors.Is(err, repository.ErrPollNotFound) {
+		return c.JSON(fiber.Map{"poll": nil})
+	}
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return c.JSON(fiber.Map{"poll": nil})
-		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch poll"})
 	}
 
-	var options []string
-	json.Unmarshal(optionsJSON, &options)
-
-	// Get vote counts per option
-	voteRows, err := pool.Query(ctx,
-		`SELECT option_index, COUNT(*) FROM community_poll_votes WHERE poll_id = $1 GROUP BY option_index`,
-		pollID,
-	)
+	voteCounts, myVote, err := h.communityRepo.PollVotes(ctx, poll.ID, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch votes"})
 	}
-	defer voteRows.Close()
-
-	voteCounts := make(map[int]int)
 	totalVotes := 0
-	for voteRows.Next() {
-		var idx, cnt int
-		if err := voteRows.Scan(&idx, &cnt); err == nil {
-			voteCounts[idx] = cnt
-			totalVotes += cnt
-		}
-	}
-
-	// Get user's vote
-	var myVote *int
-	var optIdx int
-	err = pool.QueryRow(ctx,
-		`SELECT option_index FROM community_poll_votes WHERE poll_id = $1 AND user_id = $2`,
-		pollID, userID,
-	).Scan(&optIdx)
-	if err == nil {
-		myVote = &optIdx
+	for _, cnt := range voteCounts {
+		totalVotes += cnt
 	}
 
 	// Build results
-	results := make([]fiber.Map, len(options))
-	for i, opt := range options {
+	results := make([]fiber.Map, len(poll.Options))
+	for i, opt := range poll.Options {
 		cnt := voteCounts[i]
 		pct := 0.0
 		if totalVotes > 0 {
@@ -1116,12 +566,12 @@ func (h *Handler) GetPoll(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"poll": fiber.Map{
-			"id":          pollID,
+			"id":          poll.ID,
 			"options":     results,
 			"total_votes": totalVotes,
 			"my_vote":     myVote,
-			"expires_at":  expiresAt,
-			"expired":     time.Now().After(expiresAt),
+			"expires_at":  poll.ExpiresAt,
+			"expired":     time.Now().After(poll.ExpiresAt),
 		},
 	})
 }
@@ -1138,65 +588,23 @@ func (h *Handler) VotePoll(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "option_index required"})
 	}
 
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
-	// Get poll
-	var pollID string
-	var optionsJSON []byte
-	var expiresAt time.Time
-	err := pool.QueryRow(ctx,
-		`SELECT id, options, expires_at FROM community_polls WHERE post_id = $1`,
-		postID,
-	).Scan(&pollID, &optionsJSON, &expiresAt)
+	poll, err := h.communityRepo.Poll(ctx, postID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "poll not found"})
 	}
-
-	// Check expiry
-	if time.Now().After(expiresAt) {
+	if time.Now().After(poll.ExpiresAt) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "poll expired"})
 	}
-
-	var options []string
-	json.Unmarshal(optionsJSON, &options)
-	if body.OptionIndex < 0 || body.OptionIndex >= len(options) {
+	if body.OptionIndex < 0 || body.OptionIndex >= len(poll.Options) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid option"})
 	}
 
 	// Upsert vote (allows changing vote)
-	_, err = pool.Exec(ctx,
-		`INSERT INTO community_poll_votes (poll_id, user_id, option_index)
-		 VALUES ($1, $2, $3)
-		 ON CONFLICT (poll_id, user_id)
-		 DO UPDATE SET option_index = $3`,
-		pollID, userID, body.OptionIndex,
-	)
-	if err != nil {
+	if err := h.communityRepo.Vote(ctx, poll.ID, userID, body.OptionIndex); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to vote"})
 	}
 
 	return c.JSON(fiber.Map{"success": true})
 }
-
-// Helper: get list of blocked user IDs
-func (h *Handler) getBlockedUserIDs(c *fiber.Ctx, userID string) []string {
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT blocked_id FROM community_blocks WHERE blocker_id = $1`,
-		userID,
-	)
-	if err != nil {
-		return nil
-	}
-	defer rows.Close()
-
-	var ids []string
-	for rows.Next() {
-		var id string
-		if err := rows.Scan(&id); err == nil {
-			ids = append(ids, id)
-		}
-	}
-	return ids
-}
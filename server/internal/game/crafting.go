@@ -2,11 +2,11 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 type CraftingIngredient struct {
@@ -24,84 +24,55 @@ type CraftingRecipe struct {
 	CanCraft    bool                 `json:"can_craft"`
 }
 
-func loadCraftingRecipes(ctx context.Context, pool *pgxpool.Pool, userID string) ([]CraftingRecipe, error) {
-	rows, err := pool.Query(ctx, `SELECT id, name, result_type, result_id, result_qty FROM crafting_recipes ORDER BY id`)
+// craftingRecipes loads every crafting recipe and whether userID has the materials for it.
+func (h *Handler) craftingRecipes(ctx context.Context, userID string) ([]CraftingRecipe, error) {
+	dbRecipes, err := h.gameDataRepo.GetAllCraftingRecipes(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var recipes []CraftingRecipe
-	for rows.Next() {
-		var r CraftingRecipe
-		if err := rows.Scan(&r.ID, &r.Name, &r.ResultType, &r.ResultID, &r.ResultQty); err != nil {
-			continue
-		}
-		recipes = append(recipes, r)
-	}
-
-	// Load ingredients for each recipe
-	for i := range recipes {
-		ingRows, err := pool.Query(ctx,
-			`SELECT material_id, quantity FROM crafting_ingredients WHERE recipe_id = $1`,
-			recipes[i].ID,
-		)
-		if err != nil {
-			continue
-		}
-
-		for ingRows.Next() {
-			var ing CraftingIngredient
-			if ingRows.Scan(&ing.MaterialID, &ing.Quantity) == nil {
-				recipes[i].Ingredients = append(recipes[i].Ingredients, ing)
-			}
-		}
-		ingRows.Close()
-	}
 
 	// Check craftability against user's inventory
 	userMats := make(map[int]int)
-	matRows, err := pool.Query(ctx,
-		`SELECT material_id, quantity FROM user_materials WHERE user_id = $1 AND quantity > 0`,
-		userID,
-	)
-	if err == nil {
-		for matRows.Next() {
-			var mid, qty int
-			if matRows.Scan(&mid, &qty) == nil {
-				userMats[mid] = qty
-			}
+	if stacks, err := h.userRepo.Materials(ctx, userID); err == nil {
+		for _, m := range stacks {
+			userMats[m.MaterialID] = m.Quantity
 		}
-		matRows.Close()
 	}
 
-	for i := range recipes {
-		canCraft := true
-		for _, ing := range recipes[i].Ingredients {
+	recipes := make([]CraftingRecipe, 0, len(dbRecipes))
+	for _, r := range dbRecipes {
+		recipe := newCraftingRecipe(&r)
+		recipe.CanCraft = true
+		for _, ing := range recipe.Ingredients {
 			if userMats[ing.MaterialID] < ing.Quantity {
-				canCraft = false
+				recipe.CanCraft = false
 				break
 			}
 		}
-		recipes[i].CanCraft = canCraft
+		recipes = append(recipes, recipe)
 	}
-
 	return recipes, nil
 }
 
+func newCraftingRecipe(r *repository.GameCraftingRecipe) CraftingRecipe {
+	recipe := CraftingRecipe{
+		ID: r.ID, Name: r.Name, ResultType: r.ResultType, ResultID: r.ResultID, ResultQty: r.ResultQty,
+	}
+	for _, ing := range r.Ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, CraftingIngredient{MaterialID: ing.MaterialID, Quantity: ing.Quantity})
+	}
+	return recipe
+}
+
 // GET /api/crafting/recipes
 func (h *Handler) GetCraftingRecipes(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.userRepo.Pool()
 
-	recipes, err := loadCraftingRecipes(ctx, pool, userID)
+	recipes, err := h.craftingRecipes(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load recipes"})
 	}
-	if recipes == nil {
-		recipes = []CraftingRecipe{}
-	}
 
 	return c.JSON(fiber.Map{"recipes": recipes})
 }
@@ -110,7 +81,6 @@ func (h *Handler) GetCraftingRecipes(c *fiber.Ctx) error {
 func (h *Handler) CraftItem(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.userRepo.Pool()
 
 	var body struct {
 		RecipeID int `json:"recipe_id"`
@@ -119,57 +89,31 @@ func (h *Handler) CraftItem(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "recipe_id required"})
 	}
 
-	// Load the specific recipe
-	var recipe CraftingRecipe
-	err := pool.QueryRow(ctx,
-		`SELECT id, name, result_type, result_id, result_qty FROM crafting_recipes WHERE id = $1`,
-		body.RecipeID,
-	).Scan(&recipe.ID, &recipe.Name, &recipe.ResultType, &recipe.ResultID, &recipe.ResultQty)
+	// Load the specific recipe with its ingredients
+	dbRecipe, err := h.gameDataRepo.GetCraftingRecipe(ctx, body.RecipeID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "recipe not found"})
 	}
+	recipe := newCraftingRecipe(dbRecipe)
 
-	// Load ingredients
-	ingRows, err := pool.Query(ctx,
-		`SELECT material_id, quantity FROM crafting_ingredients WHERE recipe_id = $1`,
-		recipe.ID,
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load ingredients"})
+	// Deduct materials, if the user has enough of all of them
+	costs := make([]repository.MaterialStack, len(recipe.Ingredients))
+	for i, ing := range recipe.Ingredients {
+		costs[i] = repository.MaterialStack{MaterialID: ing.MaterialID, Quantity: ing.Quantity}
 	}
-	for ingRows.Next() {
-		var ing CraftingIngredient
-		if ingRows.Scan(&ing.MaterialID, &ing.Quantity) == nil {
-			recipe.Ingredients = append(recipe.Ingredients, ing)
-		}
-	}
-	ingRows.Close()
-
-	// Check user has enough materials
-	for _, ing := range recipe.Ingredients {
-		var qty int
-		err := pool.QueryRow(ctx,
-			`SELECT COALESCE(quantity, 0) FROM user_materials WHERE user_id = $1 AND material_id = $2`,
-			userID, ing.MaterialID,
-		).Scan(&qty)
-		if err != nil || qty < ing.Quantity {
+	if err := h.userRepo.ConsumeMaterials(ctx, userID, costs); err != nil {
+		if errors.Is(err, repository.ErrNotEnoughMaterials) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_materials"})
 		}
-	}
-
-	// Deduct materials
-	for _, ing := range recipe.Ingredients {
-		pool.Exec(ctx,
-			`UPDATE user_materials SET quantity = quantity - $1 WHERE user_id = $2 AND material_id = $3`,
-			ing.Quantity, userID, ing.MaterialID,
-		)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to use materials"})
 	}
 
 	// Grant result based on type
+	rng, seed := h.newRand()
 	var resultMsg string
 	switch recipe.ResultType {
 	case "material":
-		AddMaterial(ctx, pool, userID, recipe.ResultID, recipe.ResultQty)
+		h.userRepo.AddMaterial(ctx, userID, recipe.ResultID, recipe.ResultQty)
 		mat := h.FindMaterial(recipe.ResultID)
 		name := fmt.Sprintf("재료 #%d", recipe.ResultID)
 		if mat != nil {
@@ -182,7 +126,7 @@ func (h *Handler) CraftItem(c *fiber.Ctx) error {
 		// For simplicity, grant a random slime of the egg's type
 		resultMsg = fmt.Sprintf("%s 제작 완료! 상점에서 확인하세요.", recipe.Name)
 		// Grant the egg equivalent by running the shop's hatchEgg logic
-		h.craftEgg(ctx, userID, recipe.ResultID)
+		h.craftEgg(ctx, rng, userID, recipe.ResultID)
 
 	case "booster":
 		// Activate the booster
@@ -191,18 +135,15 @@ func (h *Handler) CraftItem(c *fiber.Ctx) error {
 
 	case "accessory":
 		// Grant the accessory
-		pool.Exec(ctx,
-			`INSERT INTO user_accessories (user_id, accessory_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-			userID, recipe.ResultID,
-		)
+		h.accessoryRepo.Grant(ctx, userID, recipe.ResultID)
 		resultMsg = fmt.Sprintf("%s 획득!", recipe.Name)
 
 	default:
 		resultMsg = fmt.Sprintf("%s 제작 완료!", recipe.Name)
 	}
 
-	LogGameAction(pool, userID, "craft", "item", 0, 0, 0, map[string]interface{}{
-		"recipe_id": recipe.ID, "result_type": recipe.ResultType, "result_id": recipe.ResultID,
+	LogGameAction(h.gameLogRepo, userID, "craft", "item", 0, 0, 0, map[string]interface{}{
+		"recipe_id": recipe.ID, "result_type": recipe.ResultType, "result_id": recipe.ResultID, "seed": seed,
 	})
 
 	return c.JSON(fiber.Map{
@@ -212,13 +153,16 @@ func (h *Handler) CraftItem(c *fiber.Ctx) error {
 }
 
 // craftEgg handles egg crafting - creates a slime from the given shop egg item ID
-func (h *Handler) craftEgg(ctx context.Context, userID string, eggItemID int) {
-	// Find the shop item to determine egg type
-	eggType := getEggType(eggItemID)
-	speciesID, element := h.hatchEggFromDB(ctx, eggType)
+func (h *Handler) craftEgg(ctx context.Context, rng Rand, userID string, eggItemID int) {
+	// Find the shop item to determine the banner it hatches from
+	eggType := defaultBannerID
+	if item := h.findShopItem(eggItemID); item != nil {
+		eggType = eggBannerID(item)
+	}
+	speciesID, element := h.hatchEggFromDB(ctx, rng, eggType, h.IsBoosterActive(userID, BoosterLuck))
 
 	personalities := []string{"energetic", "chill", "foodie", "curious", "tsundere", "gentle"}
-	personality := personalities[rand.Intn(len(personalities))]
+	personality := personalities[rng.Intn(len(personalities))]
 
 	newSlime, err := h.slimeRepo.Create(ctx, userID, speciesID, element, personality)
 	if err != nil {
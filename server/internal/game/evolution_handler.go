@@ -1,11 +1,11 @@
 package game
 
 import (
-	"context"
 	"encoding/json"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 type EvolutionNode struct {
@@ -52,7 +52,7 @@ func (h *Handler) GetEvolutionTree(c *fiber.Ctx) error {
 		}
 	}
 
-	unlockedNodes, err := h.getUnlockedEvolutionNodes(c.UserContext(), userID, sid)
+	unlockedNodes, err := h.slimeRepo.EvolutionUnlocks(c.UserContext(), userID, sid)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch unlocks"})
 	}
@@ -133,7 +133,7 @@ func (h *Handler) UnlockEvolutionNode(c *fiber.Ctx) error {
 	}
 
 	ctx := c.UserContext()
-	unlockedNodes, _ := h.getUnlockedEvolutionNodes(ctx, userID, sid)
+	unlockedNodes, _ := h.slimeRepo.EvolutionUnlocks(ctx, userID, sid)
 	unlockedSet := make(map[int]bool)
 	for _, nid := range unlockedNodes {
 		unlockedSet[nid] = true
@@ -147,16 +147,12 @@ func (h *Handler) UnlockEvolutionNode(c *fiber.Ctx) error {
 		}
 	}
 
-	if err := h.userRepo.SpendCurrency(ctx, userID, 0, 0, targetNode.Cost); err != nil {
+	if err := h.userRepo.SpendCurrency(ctx, userID, 0, 0, targetNode.Cost, repository.ReasonEvolution, strconv.Itoa(body.NodeID)); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient stardust"})
 	}
 
-	_, err = h.slimeRepo.Pool().Exec(ctx,
-		`INSERT INTO evolution_unlocks (user_id, species_id, node_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
-		userID, sid, body.NodeID,
-	)
-	if err != nil {
-		h.userRepo.AddCurrency(ctx, userID, 0, 0, targetNode.Cost)
+	if err := h.slimeRepo.UnlockEvolution(ctx, userID, sid, body.NodeID); err != nil {
+		h.userRepo.AddCurrency(ctx, userID, 0, 0, targetNode.Cost, repository.ReasonEvolutionRefund, strconv.Itoa(body.NodeID))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to unlock"})
 	}
 
@@ -169,24 +165,3 @@ func (h *Handler) UnlockEvolutionNode(c *fiber.Ctx) error {
 		},
 	})
 }
-
-func (h *Handler) getUnlockedEvolutionNodes(ctx context.Context, userID string, speciesID int) ([]int, error) {
-	rows, err := h.slimeRepo.Pool().Query(ctx,
-		`SELECT node_id FROM evolution_unlocks WHERE user_id = $1 AND species_id = $2`,
-		userID, speciesID,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var nodes []int
-	for rows.Next() {
-		var nid int
-		if err := rows.Scan(&nid); err != nil {
-			return nil, err
-		}
-		nodes = append(nodes, nid)
-	}
-	return nodes, nil
-}
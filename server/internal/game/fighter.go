@@ -0,0 +1,71 @@
+package game
+
+import (
+	"context"
+
+	"github.com/slimetopia/server/internal/battle"
+	"github.com/slimetopia/server/internal/combat"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// slimeUnit snapshots a slime for battle with the effects of its skills and
+// accessories.
+func slimeUnit(s *models.Slime, effects []repository.SlimeEffect) battle.Unit {
+	var skills, gear combat.Effects
+	for _, e := range effects {
+		switch e.Source {
+		case "skill":
+			skills = skills.Plus(combat.ParseEffects(e.Effect))
+		case "accessory":
+			gear = gear.Plus(combat.ParseEffects(e.Effect))
+		}
+	}
+	return battle.FromSlime(s, skills, gear)
+}
+
+// partySlime is a party member ready to fight.
+type partySlime struct {
+	Slime *models.Slime
+	Unit  battle.Unit
+}
+
+// loadParty loads the slimes of a party with their skills and accessories. Slimes
+// that don't exist or that userID doesn't own are left out.
+func (h *Handler) loadParty(ctx context.Context, userID string, slimeIDs []string) ([]partySlime, error) {
+	var party []partySlime
+	var owned []string
+	for _, sid := range slimeIDs {
+		slime, err := h.slimeRepo.FindByID(ctx, sid)
+		if err != nil || uuidToString(slime.UserID) != userID {
+			continue
+		}
+		party = append(party, partySlime{Slime: slime})
+		owned = append(owned, uuidToString(slime.ID))
+	}
+	if len(party) == 0 {
+		return nil, nil
+	}
+
+	effects, err := h.slimeRepo.EffectsOf(ctx, owned)
+	if err != nil {
+		return nil, err
+	}
+	bySlime := make(map[string][]repository.SlimeEffect)
+	for _, e := range effects {
+		bySlime[e.SlimeID] = append(bySlime[e.SlimeID], e)
+	}
+	for i := range party {
+		party[i].Unit = slimeUnit(party[i].Slime, bySlime[owned[i]])
+	}
+	return party, nil
+}
+
+// partyTeam is a party as a battle team.
+func partyTeam(party []partySlime) battle.Team {
+	team := make(battle.Team, len(party))
+	for i, p := range party {
+		team[i] = p.Unit
+	}
+	return team
+}
@@ -2,10 +2,10 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 const maxDailyFishing = 10
@@ -70,7 +70,8 @@ func (h *Handler) CatchFish(c *fiber.Ctx) error {
 	}
 
 	// Determine catch rarity
-	roll := rand.Intn(100)
+	rng, seed := h.newRand()
+	roll := rng.Intn(100)
 	var catchType, catchName, rarity string
 	var goldReward int64
 	var gemsReward int
@@ -104,13 +105,12 @@ func (h *Handler) CatchFish(c *fiber.Ctx) error {
 
 	// Grant rewards
 	if goldReward > 0 || gemsReward > 0 {
-		h.userRepo.AddCurrency(ctx, userID, goldReward, gemsReward, 0)
+		h.userRepo.AddCurrency(ctx, userID, goldReward, gemsReward, 0, repository.ReasonFishingReward, catchType)
 	}
 
 	// Log fishing catch
-	pool := h.slimeRepo.Pool()
-	LogGameAction(pool, userID, "fishing_catch", "minigame", goldReward, gemsReward, 0, map[string]interface{}{
-		"rarity": rarity, "gold": goldReward, "gems": gemsReward,
+	LogGameAction(h.gameLogRepo, userID, "fishing_catch", "minigame", goldReward, gemsReward, 0, map[string]interface{}{
+		"rarity": rarity, "gold": goldReward, "gems": gemsReward, "seed": seed,
 	})
 
 	// Track achievement for legendary catch
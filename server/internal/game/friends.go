@@ -0,0 +1,180 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// friendErrorResponse maps friend repository errors to API errors.
+func friendErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrAlreadyFriends):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "already_friends"})
+	case errors.Is(err, repository.ErrFriendRequestExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "request_already_sent"})
+	case errors.Is(err, repository.ErrFriendLimit):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "friend_limit", "limit": repository.MaxFriends})
+	case errors.Is(err, repository.ErrFriendLimitOther):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "friend_limit_other", "limit": repository.MaxFriends})
+	case errors.Is(err, repository.ErrFriendBlocked):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "blocked"})
+	case errors.Is(err, repository.ErrFriendRequestNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "request_not_found"})
+	case errors.Is(err, repository.ErrNotFriends):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not_friends"})
+	case errors.Is(err, repository.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "friend operation failed"})
+}
+
+// GET /api/friends — friend list with online / last seen status
+func (h *Handler) GetFriends(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	friends, err := h.friendRepo.ListFriends(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch friends"})
+	}
+	ids := make([]string, len(friends))
+	for i, f := range friends {
+		ids[i] = f.UserID
+	}
+	presence := h.lookupPresence(ctx, ids)
+
+	result := make([]fiber.Map, 0, len(friends))
+	online := 0
+	for _, f := range friends {
+		p := presence[f.UserID]
+		if p.Online {
+			online++
+		}
+		result = append(result, fiber.Map{
+			"user_id":           f.UserID,
+			"nickname":          f.Nickname,
+			"level":             f.Level,
+			"profile_image_url": f.ProfileImageURL,
+			"since":             f.Since,
+			"online":            p.Online,
+			"last_seen":         p.LastSeen,
+		})
+	}
+
+	code, _ := h.friendRepo.FriendCode(ctx, userID)
+	return c.JSON(fiber.Map{
+		"friends":     result,
+		"online":      online,
+		"limit":       repository.MaxFriends,
+		"friend_code": code,
+	})
+}
+
+// GET /api/friends/requests — pending requests, incoming and outgoing
+func (h *Handler) GetFriendRequests(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	requests, err := h.friendRepo.ListRequests(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch requests"})
+	}
+	return c.JSON(fiber.Map{"requests": requests})
+}
+
+// POST /api/friends/requests — by friend_code, nickname or user_id
+func (h *Handler) SendFriendRequest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	var body struct {
+		FriendCode string `json:"friend_code"`
+		Nickname   string `json:"nickname"`
+		UserID     string `json:"user_id"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	var targetID string
+	var err error
+	switch {
+	case body.FriendCode != "":
+		targetID, err = h.friendRepo.FindByFriendCode(ctx, body.FriendCode)
+	case body.Nickname != "":
+		targetID, err = h.userRepo.IDByNickname(ctx, body.Nickname)
+	case body.UserID != "":
+		if _, perr := uuid.Parse(body.UserID); perr == nil {
+			targetID = body.UserID
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "friend_code, nickname or user_id required"})
+	}
+	if err != nil || targetID == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+	}
+	if targetID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_add_self"})
+	}
+
+	accepted, err := h.friendRepo.SendRequest(ctx, userID, targetID)
+	if err != nil {
+		return friendErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "accepted": accepted, "user_id": targetID})
+}
+
+// POST /api/friends/requests/:id/accept
+func (h *Handler) AcceptFriendRequest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return friendErrorResponse(c, repository.ErrFriendRequestNotFound)
+	}
+	friendID, err := h.friendRepo.Accept(c.Context(), userID, c.Params("id"))
+	if err != nil {
+		return friendErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "user_id": friendID})
+}
+
+// POST /api/friends/requests/:id/decline
+func (h *Handler) DeclineFriendRequest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return friendErrorResponse(c, repository.ErrFriendRequestNotFound)
+	}
+	if err := h.friendRepo.Decline(c.Context(), userID, c.Params("id")); err != nil {
+		return friendErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// DELETE /api/friends/requests/:id — withdraw a request you sent
+func (h *Handler) CancelFriendRequest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return friendErrorResponse(c, repository.ErrFriendRequestNotFound)
+	}
+	if err := h.friendRepo.Cancel(c.Context(), userID, c.Params("id")); err != nil {
+		return friendErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// DELETE /api/friends/:id
+func (h *Handler) RemoveFriend(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return friendErrorResponse(c, repository.ErrNotFriends)
+	}
+	if err := h.friendRepo.Remove(c.Context(), userID, c.Params("id")); err != nil {
+		return friendErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
@@ -0,0 +1,203 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// defaultBannerID is used for eggs whose shop item names no banner.
+const defaultBannerID = "normal"
+
+// luckBoostMultiplier scales rare+ grade weights while the luck booster is active, for
+// banners that don't define their own luck_grade_weights.
+const luckBoostMultiplier = 2.0
+
+// eggBannerID returns the banner a shop egg item pulls from.
+func eggBannerID(item *ShopItem) string {
+	if item.EggType != "" {
+		return item.EggType
+	}
+	return defaultBannerID
+}
+
+// bannerForEgg loads a banner by egg type, falling back to the default banner for
+// unknown types so older items keep hatching.
+func (h *Handler) bannerForEgg(ctx context.Context, eggType string) (*repository.GameGachaBanner, error) {
+	b, err := h.gameDataRepo.GetGachaBanner(ctx, eggType)
+	if errors.Is(err, pgx.ErrNoRows) && eggType != defaultBannerID {
+		return h.gameDataRepo.GetGachaBanner(ctx, defaultBannerID)
+	}
+	return b, err
+}
+
+// bannerAvailable reports whether the banner can be pulled at t.
+func bannerAvailable(b *repository.GameGachaBanner, t time.Time) bool {
+	if !b.IsActive {
+		return false
+	}
+	if b.StartsAt != nil && t.Before(*b.StartsAt) {
+		return false
+	}
+	if b.EndsAt != nil && !t.Before(*b.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// bannerGradeWeights returns the grade weights for a pull, applying the luck booster.
+func bannerGradeWeights(b *repository.GameGachaBanner, luck bool) map[string]float64 {
+	if !luck {
+		return b.GradeWeights
+	}
+	if len(b.LuckGradeWeights) > 0 {
+		return b.LuckGradeWeights
+	}
+	boosted := make(map[string]float64, len(b.GradeWeights))
+	for grade, w := range b.GradeWeights {
+		if gradeRank[grade] >= gradeRank["rare"] {
+			w *= luckBoostMultiplier
+		}
+		boosted[grade] = w
+	}
+	return boosted
+}
+
+// bannerPool groups the species a banner can produce by grade. An explicit species
+// pool wins over the element filter.
+func bannerPool(b *repository.GameGachaBanner, species []models.SlimeSpecies) map[string][]models.SlimeSpecies {
+	inPool := make(map[int]bool, len(b.SpeciesPool))
+	for _, id := range b.SpeciesPool {
+		inPool[id] = true
+	}
+	inElements := make(map[string]bool, len(b.Elements))
+	for _, e := range b.Elements {
+		inElements[e] = true
+	}
+
+	pool := map[string][]models.SlimeSpecies{}
+	for _, sp := range species {
+		switch {
+		case len(inPool) > 0:
+			if !inPool[sp.ID] {
+				continue
+			}
+		case len(inElements) > 0:
+			if !inElements[sp.Element] {
+				continue
+			}
+		}
+		pool[sp.Grade] = append(pool[sp.Grade], sp)
+	}
+	return pool
+}
+
+// bannerGradeOdds returns the effective probability of each grade: weights for grades
+// the pool can't produce are dropped and the rest renormalized. With minGrade set, only
+// grades at or above it count; if none of those carry weight they split evenly.
+func bannerGradeOdds(weights map[string]float64, pool map[string][]models.SlimeSpecies, minGrade string) map[string]float64 {
+	odds := map[string]float64{}
+	var total float64
+	for _, grade := range gradeByRank {
+		if len(pool[grade]) == 0 || (minGrade != "" && gradeRank[grade] < gradeRank[minGrade]) {
+			continue
+		}
+		if w := weights[grade]; w > 0 {
+			odds[grade] = w
+			total += w
+		}
+	}
+	if total == 0 && minGrade != "" {
+		for _, grade := range gradeByRank {
+			if len(pool[grade]) > 0 && gradeRank[grade] >= gradeRank[minGrade] {
+				odds[grade] = 1
+				total++
+			}
+		}
+	}
+	for grade := range odds {
+		odds[grade] /= total
+	}
+	return odds
+}
+
+// rollBannerSpecies performs one pull. minGrade is the pity floor ("" for none); it is
+// ignored if the pool has nothing that high. ok is false only for an empty pool.
+func rollBannerSpecies(rng Rand, b *repository.GameGachaBanner, species []models.SlimeSpecies, luck bool, minGrade string) (models.SlimeSpecies, bool) {
+	pool := bannerPool(b, species)
+	weights := bannerGradeWeights(b, luck)
+	odds := bannerGradeOdds(weights, pool, minGrade)
+	if len(odds) == 0 && minGrade != "" {
+		odds = bannerGradeOdds(weights, pool, "")
+	}
+	if len(odds) == 0 {
+		// Pool exists but every grade has zero weight: pick uniformly
+		var all []models.SlimeSpecies
+		for _, grade := range gradeByRank {
+			all = append(all, pool[grade]...)
+		}
+		if len(all) == 0 {
+			return models.SlimeSpecies{}, false
+		}
+		return all[rng.Intn(len(all))], true
+	}
+
+	grade := ""
+	r := rng.Float64()
+	for _, g := range gradeByRank {
+		p, ok := odds[g]
+		if !ok {
+			continue
+		}
+		grade = g
+		if r < p {
+			break
+		}
+		r -= p
+	}
+
+	return pickRateUp(rng, b, pool[grade]), true
+}
+
+// pickRateUp picks a species of the rolled grade. If the grade has rate-up species,
+// one of them is chosen with probability RateUpShare, otherwise one of the rest.
+func pickRateUp(rng Rand, b *repository.GameGachaBanner, candidates []models.SlimeSpecies) models.SlimeSpecies {
+	rateUp, rest := splitRateUp(b, candidates)
+	if len(rateUp) > 0 && (len(rest) == 0 || rng.Float64() < b.RateUpShare) {
+		return rateUp[rng.Intn(len(rateUp))]
+	}
+	return rest[rng.Intn(len(rest))]
+}
+
+func splitRateUp(b *repository.GameGachaBanner, candidates []models.SlimeSpecies) (rateUp, rest []models.SlimeSpecies) {
+	featured := make(map[int]bool, len(b.RateUpSpecies))
+	for _, id := range b.RateUpSpecies {
+		featured[id] = true
+	}
+	for _, sp := range candidates {
+		if featured[sp.ID] {
+			rateUp = append(rateUp, sp)
+		} else {
+			rest = append(rest, sp)
+		}
+	}
+	return rateUp, rest
+}
+
+// hatchFromBanner rolls a species from the banner against the live species table.
+func (h *Handler) hatchFromBanner(ctx context.Context, rng Rand, b *repository.GameGachaBanner, luck bool, minGrade string) (speciesID int, element string) {
+	allSpecies, err := h.slimeRepo.GetAllSpecies(ctx)
+	if err != nil || len(allSpecies) == 0 {
+		// Fallback
+		return 1, "water"
+	}
+	chosen, ok := rollBannerSpecies(rng, b, allSpecies, luck, minGrade)
+	if !ok {
+		return 1, "water"
+	}
+	return chosen.ID, chosen.Element
+}
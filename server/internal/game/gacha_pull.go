@@ -0,0 +1,205 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// MultiPullSize is the number of eggs in a multi-pull. A full batch guarantees at least
+// one MultiPullGuarantee-or-better result.
+const (
+	MultiPullSize      = 10
+	MultiPullGuarantee = "rare"
+)
+
+var eggPersonalities = []string{
+	models.PersonalityEnergetic, models.PersonalityChill,
+	models.PersonalityFoodie, models.PersonalityCurious,
+	models.PersonalityTsundere, models.PersonalityGentle,
+}
+
+// batchPull is one result of a batch and the grade floor that applied to it.
+type batchPull struct {
+	Species models.SlimeSpecies
+	Floor   string
+}
+
+// rollBatch performs n pulls starting from the stored pity counter. Pity advances and
+// resets pull by pull, exactly as n single pulls would; a full multi-pull additionally
+// floors its last pull at MultiPullGuarantee if nothing reached it yet. Returns the
+// pulls, the pity counter afterwards and whether any pull reset it.
+func rollBatch(rng Rand, b *repository.GameGachaBanner, species []models.SlimeSpecies, luck bool, pity, n int) (pulls []batchPull, newPity int, reset bool) {
+	guaranteed := false
+	for i := 0; i < n; i++ {
+		pity++
+		floor := ApplyPityGuarantee(b, pity)
+		if n >= MultiPullSize && i == n-1 && !guaranteed && !gradeAtLeast(floor, MultiPullGuarantee) {
+			floor = MultiPullGuarantee
+		}
+
+		sp, ok := rollBannerSpecies(rng, b, species, luck, floor)
+		if !ok {
+			return nil, pity, reset
+		}
+		if gradeAtLeast(sp.Grade, MultiPullGuarantee) {
+			guaranteed = true
+		}
+		if ShouldResetPity(b, sp.Grade) {
+			pity = 0
+			reset = true
+		}
+		pulls = append(pulls, batchPull{Species: sp, Floor: floor})
+	}
+	return pulls, pity, reset
+}
+
+// RollEggs runs n pulls on a banner exactly as a purchase would, starting from the
+// given pity counter, and returns the hatched species and the pity counter afterwards.
+func RollEggs(rng Rand, b *repository.GameGachaBanner, species []models.SlimeSpecies, luck bool, pity, n int) ([]models.SlimeSpecies, int) {
+	pulls, newPity, _ := rollBatch(rng, b, species, luck, pity, n)
+	hatched := make([]models.SlimeSpecies, len(pulls))
+	for i, p := range pulls {
+		hatched[i] = p.Species
+	}
+	return hatched, newPity
+}
+
+// eggPullResult is everything a committed egg purchase produced.
+type eggPullResult struct {
+	Pulls          []batchPull
+	Slimes         []models.Slime
+	FirstDiscovery []bool
+	Pity           int
+}
+
+// pullEggs charges the purchase, rolls n eggs from the banner and writes the slimes,
+// codex entries, first discoveries and pity counter in one transaction. Capacity is
+// checked before anything is rolled; on repository.ErrSlimeBoxFull, available
+// reports the free slots.
+func (h *Handler) pullEggs(ctx context.Context, rng Rand, userID, nickname string, item *ShopItem, b *repository.GameGachaBanner, n int, gold int64, gems int, luck bool) (res *eggPullResult, available int, err error) {
+	species, err := h.slimeRepo.GetAllSpecies(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(species) == 0 {
+		return nil, 0, errors.New("no species")
+	}
+
+	var pulls []batchPull
+	var newPity int
+	pulled, available, err := h.gachaRepo.Pull(ctx, userID, nickname, b.ID, n, gold, gems, strconv.Itoa(item.ID),
+		func(pity int) ([]repository.GachaHatch, int, bool, error) {
+			var reset bool
+			pulls, newPity, reset = rollBatch(rng, b, species, luck, pity, n)
+			if len(pulls) != n {
+				return nil, 0, false, errors.New("banner pool is empty")
+			}
+			hatches := make([]repository.GachaHatch, len(pulls))
+			for i, p := range pulls {
+				hatches[i] = repository.GachaHatch{
+					SpeciesID:   p.Species.ID,
+					Element:     p.Species.Element,
+					Personality: eggPersonalities[rng.Intn(len(eggPersonalities))],
+				}
+			}
+			return hatches, newPity, reset, nil
+		})
+	if err != nil {
+		return nil, available, err
+	}
+	return &eggPullResult{Pulls: pulls, Slimes: pulled.Slimes, FirstDiscovery: pulled.FirstDiscovery, Pity: newPity}, available, nil
+}
+
+// buyEggs handles egg purchases from BuyItem: a single egg, an egg bought
+// MultiPullSize times at once, or a multi_egg bundle priced as a whole.
+func (h *Handler) buyEggs(c *fiber.Ctx, userID string, item *ShopItem, b *repository.GameGachaBanner, quantity int) error {
+	ctx := c.Context()
+
+	n, gold, gems := 1, item.Cost.Gold, item.Cost.Gems
+	switch {
+	case item.Type == "multi_egg":
+		n = item.Quantity
+		if n <= 0 {
+			n = MultiPullSize
+		}
+	case quantity == MultiPullSize:
+		n = MultiPullSize
+		gold *= int64(n)
+		gems *= n
+	case quantity > 1:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "quantity must be 1 or 10"})
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+	}
+
+	luck := h.IsBoosterActive(userID, BoosterLuck)
+	rng, seed := h.newRand()
+	res, available, err := h.pullEggs(ctx, rng, userID, user.Nickname, item, b, n, gold, gems, luck)
+	switch {
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient funds"})
+	case errors.Is(err, repository.ErrSlimeBoxFull):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "슬라임 보유 한도에 도달했습니다", "available": available})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to hatch egg"})
+	}
+
+	h.missionRepo.IncrementProgress(ctx, userID, "buy")
+	user, _ = h.userRepo.FindByID(ctx, userID)
+	userMap := fiber.Map{"gold": user.Gold, "gems": user.Gems}
+
+	if n == 1 {
+		p := res.Pulls[0]
+		LogGameAction(h.gameLogRepo, userID, "gacha_single", "gacha", -gold, -gems, 0, map[string]interface{}{
+			"egg_type": b.ID, "species_id": p.Species.ID, "grade": p.Species.Grade,
+			"personality": res.Slimes[0].Personality, "floor": p.Floor, "luck": luck, "seed": seed,
+		})
+		return c.JSON(fiber.Map{
+			"type": "egg",
+			"result": fiber.Map{
+				"slime":              slimeToMap(res.Slimes[0]),
+				"species":            p.Species,
+				"is_first_discovery": res.FirstDiscovery[0],
+			},
+			"user": userMap,
+			"pity": pityStatus(b, res.Pity),
+		})
+	}
+
+	results := make([]fiber.Map, 0, n)
+	speciesIDs := make([]int, 0, n)
+	grades := make([]string, 0, n)
+	floors := make([]string, 0, n)
+	for i, p := range res.Pulls {
+		sp := p.Species
+		results = append(results, fiber.Map{
+			"slime": slimeToMap(res.Slimes[i]),
+			"species": fiber.Map{
+				"id": sp.ID, "name": sp.Name, "name_en": sp.NameEN,
+				"element": sp.Element, "grade": sp.Grade, "description": sp.Description,
+			},
+			"is_first_discovery": res.FirstDiscovery[i],
+		})
+		speciesIDs = append(speciesIDs, sp.ID)
+		grades = append(grades, sp.Grade)
+		floors = append(floors, p.Floor)
+	}
+	LogGameAction(h.gameLogRepo, userID, "gacha_multi", "gacha", -gold, -gems, 0, map[string]interface{}{
+		"egg_type": b.ID, "count": n, "species_ids": speciesIDs, "grades": grades,
+		"floors": floors, "luck": luck, "seed": seed,
+	})
+	return c.JSON(fiber.Map{
+		"type":   "multi_egg",
+		"result": fiber.Map{"results": results, "count": n},
+		"user":   userMap,
+		"pity":   pityStatus(b, res.Pity),
+	})
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// GradeRate is the chance of a grade per pull. BaseRate ignores pity; EffectiveRate is
+// the long-run share of pulls once pity guarantees are taken into account.
+type GradeRate struct {
+	Grade         string  `json:"grade"`
+	BaseRate      float64 `json:"base_rate"`
+	EffectiveRate float64 `json:"effective_rate"`
+}
+
+// SpeciesRate is the chance of one species per pull.
+type SpeciesRate struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	NameEN        string  `json:"name_en"`
+	Element       string  `json:"element"`
+	Grade         string  `json:"grade"`
+	RateUp        bool    `json:"rate_up"`
+	BaseRate      float64 `json:"base_rate"`
+	EffectiveRate float64 `json:"effective_rate"`
+}
+
+// RateTable is the published odds of a banner, computed from the same weights, pool
+// and pity rules the pulls use. Rates are probabilities in [0, 1].
+type RateTable struct {
+	Grades  []GradeRate   `json:"grades"`
+	Species []SpeciesRate `json:"species"`
+}
+
+// BannerRates computes the grade and species odds of a banner against the species
+// table, with or without the luck booster.
+func BannerRates(b *repository.GameGachaBanner, species []models.SlimeSpecies, luck bool) RateTable {
+	pool := bannerPool(b, species)
+	weights := bannerGradeWeights(b, luck)
+	base := bannerGradeOdds(weights, pool, "")
+	effective := pityAdjustedOdds(b, weights, pool)
+
+	var t RateTable
+	for _, grade := range gradeByRank {
+		if base[grade] == 0 && effective[grade] == 0 {
+			continue
+		}
+		t.Grades = append(t.Grades, GradeRate{Grade: grade, BaseRate: base[grade], EffectiveRate: effective[grade]})
+
+		rateUp, rest := splitRateUp(b, pool[grade])
+		rateUpShare, restShare := speciesShares(b, len(rateUp), len(rest))
+		for i, sp := range append(rateUp, rest...) {
+			s := restShare
+			if i < len(rateUp) {
+				s = rateUpShare
+			}
+			t.Species = append(t.Species, SpeciesRate{
+				ID: sp.ID, Name: sp.Name, NameEN: sp.NameEN, Element: sp.Element, Grade: sp.Grade,
+				RateUp:        i < len(rateUp),
+				BaseRate:      base[grade] * s,
+				EffectiveRate: effective[grade] * s,
+			})
+		}
+	}
+	return t
+}
+
+// speciesShares is the chance of each rate-up and each other species within a grade,
+// mirroring pickRateUp.
+func speciesShares(b *repository.GameGachaBanner, nRateUp, nRest int) (rateUp, rest float64) {
+	switch {
+	case nRateUp > 0 && nRest == 0:
+		rateUp = 1 / float64(nRateUp)
+	case nRateUp > 0:
+		rateUp = b.RateUpShare / float64(nRateUp)
+		rest = (1 - b.RateUpShare) / float64(nRest)
+	case nRest > 0:
+		rest = 1 / float64(nRest)
+	}
+	return rateUp, rest
+}
+
+// pityAdjustedOdds is the stationary grade distribution of single pulls under the
+// banner's pity rules. The pity counter renews to 0 on every reset, so the chance of
+// standing at count k is the chance of k pulls in a row without a reset; past the
+// highest threshold the floor no longer changes and the tail is geometric.
+func pityAdjustedOdds(b *repository.GameGachaBanner, weights map[string]float64, pool map[string][]models.SlimeSpecies) map[string]float64 {
+	last := 0
+	for _, s := range pitySteps(b) {
+		if s.pulls > last {
+			last = s.pulls
+		}
+	}
+
+	effective := map[string]float64{}
+	var total float64
+	survive := 1.0
+	for k := 0; k < last && survive > 0; k++ {
+		odds := pullOdds(b, weights, pool, k+1)
+		addOdds(effective, odds, survive)
+		total += survive
+		survive *= 1 - resetChance(b, odds)
+	}
+	if survive > 0 {
+		// Counts at or past the last threshold all share the same floor
+		odds := pullOdds(b, weights, pool, last+1)
+		weight := survive
+		if r := resetChance(b, odds); r > 0 {
+			weight = survive / r
+		}
+		addOdds(effective, odds, weight)
+		total += weight
+	}
+	for grade := range effective {
+		effective[grade] /= total
+	}
+	return effective
+}
+
+// pullOdds is the grade distribution of the pull that brings the counter to count,
+// matching rollBannerSpecies.
+func pullOdds(b *repository.GameGachaBanner, weights map[string]float64, pool map[string][]models.SlimeSpecies, count int) map[string]float64 {
+	odds := bannerGradeOdds(weights, pool, ApplyPityGuarantee(b, count))
+	if len(odds) == 0 {
+		odds = bannerGradeOdds(weights, pool, "")
+	}
+	return odds
+}
+
+func resetChance(b *repository.GameGachaBanner, odds map[string]float64) float64 {
+	var r float64
+	for grade, p := range odds {
+		if ShouldResetPity(b, grade) {
+			r += p
+		}
+	}
+	return r
+}
+
+func addOdds(dst, odds map[string]float64, weight float64) {
+	for grade, p := range odds {
+		dst[grade] += p * weight
+	}
+}
+
+// GetGachaRates handles GET /api/shop/rates/:egg_type — the probability disclosure for
+// a banner. ?luck=1 shows the odds with the luck booster active.
+func (h *Handler) GetGachaRates(c *fiber.Ctx) error {
+	ctx := c.Context()
+	b, err := h.gameDataRepo.GetGachaBanner(ctx, c.Params("egg_type"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "banner not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load banner"})
+	}
+	species, err := h.slimeRepo.GetAllSpecies(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load species"})
+	}
+
+	luck := c.QueryBool("luck")
+	rates := BannerRates(b, species, luck)
+
+	return c.JSON(fiber.Map{
+		"egg_type":  b.ID,
+		"name":      b.Name,
+		"name_en":   b.NameEN,
+		"available": bannerAvailable(b, time.Now()),
+		"starts_at": b.StartsAt,
+		"ends_at":   b.EndsAt,
+		"luck":      luck,
+		"grades":    rates.Grades,
+		"species":   rates.Species,
+		"pity": fiber.Map{
+			"rare":      b.PityRare,
+			"epic":      b.PityEpic,
+			"legendary": b.PityLegendary,
+		},
+		"rate_up_share": b.RateUpShare,
+		"multi_pull": fiber.Map{
+			"size":      MultiPullSize,
+			"guarantee": MultiPullGuarantee,
+		},
+	})
+}
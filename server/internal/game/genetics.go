@@ -0,0 +1,191 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"github.com/slimetopia/server/internal/models"
+)
+
+// ===== Genetics =====
+//
+// A bred slime carries two alleles per gene, one passed down from each parent. The
+// more dominant allele is the one that shows:
+//
+//   - color: normal > pastel > shiny, so a shiny slime needs two shiny alleles.
+//   - personality: energetic, curious and tsundere dominate chill, foodie and gentle;
+//     between two of the same kind, the first allele shows.
+//   - talent potential, per talent: a slime with two high alleles never rolls that
+//     talent below talentHighFloor; normal is dominant.
+//
+// Slimes that were never bred (founders) have no stored genes; founderGenes derives
+// them from what the slime shows.
+
+const (
+	colorNormal = "normal"
+	colorPastel = "pastel"
+	colorShiny  = "shiny"
+
+	potentialNormal = "normal"
+	potentialHigh   = "high"
+
+	// talentHighFloor is the lowest a talent with two high alleles can roll.
+	talentHighFloor = 15
+	// founderHighTalent is the talent from which a founder carries one high allele.
+	founderHighTalent = 24
+	// colorMutationChance is the chance, per egg, that one allele turns pastel or shiny.
+	colorMutationChance = 0.02
+)
+
+var colorDominance = map[string]int{colorNormal: 0, colorPastel: 1, colorShiny: 2}
+
+var dominantPersonalities = map[string]bool{"energetic": true, "curious": true, "tsundere": true}
+
+// Genes is a slime's genotype.
+type Genes struct {
+	Color       [2]string    `json:"color"`
+	Personality [2]string    `json:"personality"`
+	Potential   [6][2]string `json:"potential"` // str, vit, spd, int, cha, lck
+}
+
+// ColorVariant is the color the slime shows.
+func (g Genes) ColorVariant() string {
+	if colorDominance[g.Color[1]] < colorDominance[g.Color[0]] {
+		return g.Color[1]
+	}
+	return g.Color[0]
+}
+
+// ExpressedPersonality is the personality the slime is born with.
+func (g Genes) ExpressedPersonality() string {
+	if !dominantPersonalities[g.Personality[0]] && dominantPersonalities[g.Personality[1]] {
+		return g.Personality[1]
+	}
+	return g.Personality[0]
+}
+
+// TalentFloors are the lowest value each talent can roll at birth.
+func (g Genes) TalentFloors() [6]int {
+	var floors [6]int
+	for i, p := range g.Potential {
+		if p[0] == potentialHigh && p[1] == potentialHigh {
+			floors[i] = talentHighFloor
+		}
+	}
+	return floors
+}
+
+// founderGenes is the genotype of a slime with no recorded genes: pure normal color,
+// its own personality twice, and a hidden high allele for each strong talent.
+func founderGenes(s models.Slime) Genes {
+	g := Genes{
+		Color:       [2]string{colorNormal, colorNormal},
+		Personality: [2]string{s.Personality, s.Personality},
+	}
+	talents := [6]int{s.TalentStr, s.TalentVit, s.TalentSpd, s.TalentInt, s.TalentCha, s.TalentLck}
+	for i, t := range talents {
+		g.Potential[i] = [2]string{potentialNormal, potentialNormal}
+		if t >= founderHighTalent {
+			g.Potential[i][0] = potentialHigh
+		}
+	}
+	return g
+}
+
+// passAlleles gives a child one random allele of each parent's pair, in random order.
+func passAlleles(rng Rand, a, b [2]string) [2]string {
+	fromA, fromB := a[rng.Intn(2)], b[rng.Intn(2)]
+	if rng.Intn(2) == 0 {
+		return [2]string{fromA, fromB}
+	}
+	return [2]string{fromB, fromA}
+}
+
+// BreedGenes draws a child's genes from its parents'. Draws happen in a fixed order
+// (color, personality, each talent, then the color mutation) so a seed replays them.
+func BreedGenes(rng Rand, a, b Genes) Genes {
+	var child Genes
+	child.Color = passAlleles(rng, a.Color, b.Color)
+	child.Personality = passAlleles(rng, a.Personality, b.Personality)
+	for i := range child.Potential {
+		child.Potential[i] = passAlleles(rng, a.Potential[i], b.Potential[i])
+	}
+	if rng.Float64() < colorMutationChance {
+		mutant := colorPastel
+		if rng.Intn(3) == 0 {
+			mutant = colorShiny
+		}
+		child.Color[rng.Intn(2)] = mutant
+	}
+	return child
+}
+
+// BreedTalents rolls a child's talents like a merge does, then lifts each to the
+// floor its genes allow.
+func BreedTalents(rng Rand, a, b models.Slime, genes Genes) [6]int {
+	talents := InheritTalents(rng, a, b)
+	for i, floor := range genes.TalentFloors() {
+		talents[i] = max(talents[i], floor)
+	}
+	return talents
+}
+
+// ===== Breeding Rules =====
+
+const (
+	breedMinLevel     = 10
+	breedMinAffection = 80
+	breedCooldown     = 24 * time.Hour
+	breedHatchTime    = time.Hour
+)
+
+var (
+	ErrBreedSame      = errors.New("two different slimes required")
+	ErrBreedLevel     = errors.New("both parents must be level 10 or higher")
+	ErrBreedAffection = errors.New("both parents need affection of 80 or more")
+	ErrBreedSick      = errors.New("a sick slime can't breed")
+	ErrBreedCooldown  = errors.New("a parent bred too recently")
+	ErrBreedKin       = errors.New("parents are too closely related")
+)
+
+// breedParent is a would-be parent with what breeding needs to know about it.
+type breedParent struct {
+	Slime     models.Slime
+	ParentIDs []string  // its own parents; empty for a founder
+	LastBred  time.Time // zero if it never has
+}
+
+// checkBreedable reports why two slimes can't breed at now, or nil if they can.
+// Parents and children can't breed with each other, and neither can siblings or
+// half-siblings.
+func checkBreedable(a, b breedParent, now time.Time) error {
+	idA, idB := uuidToString(a.Slime.ID), uuidToString(b.Slime.ID)
+	switch {
+	case idA == idB:
+		return ErrBreedSame
+	case a.Slime.Level < breedMinLevel || b.Slime.Level < breedMinLevel:
+		return ErrBreedLevel
+	case a.Slime.Affection < breedMinAffection || b.Slime.Affection < breedMinAffection:
+		return ErrBreedAffection
+	case a.Slime.IsSick || b.Slime.IsSick:
+		return ErrBreedSick
+	case now.Sub(a.LastBred) < breedCooldown || now.Sub(b.LastBred) < breedCooldown:
+		return ErrBreedCooldown
+	}
+	for _, p := range a.ParentIDs {
+		if p == idB {
+			return ErrBreedKin
+		}
+		for _, q := range b.ParentIDs {
+			if p == q {
+				return ErrBreedKin
+			}
+		}
+	}
+	for _, q := range b.ParentIDs {
+		if q == idA {
+			return ErrBreedKin
+		}
+	}
+	return nil
+}
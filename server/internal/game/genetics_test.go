@@ -0,0 +1,201 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/slimetopia/server/internal/models"
+)
+
+func TestColorVariant(t *testing.T) {
+	tests := []struct {
+		alleles [2]string
+		want    string
+	}{
+		{[2]string{colorNormal, colorNormal}, colorNormal},
+		{[2]string{colorShiny, colorNormal}, colorNormal},
+		{[2]string{colorPastel, colorShiny}, colorPastel},
+		{[2]string{colorShiny, colorPastel}, colorPastel},
+		{[2]string{colorShiny, colorShiny}, colorShiny},
+	}
+	for _, tt := range tests {
+		if got := (Genes{Color: tt.alleles}).ColorVariant(); got != tt.want {
+			t.Errorf("ColorVariant(%v) = %q, want %q", tt.alleles, got, tt.want)
+		}
+	}
+}
+
+func TestExpressedPersonality(t *testing.T) {
+	tests := []struct {
+		alleles [2]string
+		want    string
+	}{
+		{[2]string{"chill", "energetic"}, "energetic"},
+		{[2]string{"tsundere", "gentle"}, "tsundere"},
+		{[2]string{"curious", "energetic"}, "curious"}, // both dominant: first shows
+		{[2]string{"foodie", "chill"}, "foodie"},       // both recessive: first shows
+	}
+	for _, tt := range tests {
+		if got := (Genes{Personality: tt.alleles}).ExpressedPersonality(); got != tt.want {
+			t.Errorf("ExpressedPersonality(%v) = %q, want %q", tt.alleles, got, tt.want)
+		}
+	}
+}
+
+func TestFounderGenes(t *testing.T) {
+	s := models.Slime{Personality: "gentle", TalentStr: 24, TalentVit: 23, TalentSpd: 31}
+	g := founderGenes(s)
+	if g.Color != [2]string{colorNormal, colorNormal} || g.Personality != [2]string{"gentle", "gentle"} {
+		t.Errorf("founder genes = %+v", g)
+	}
+	want := [6][2]string{
+		{potentialHigh, potentialNormal},
+		{potentialNormal, potentialNormal},
+		{potentialHigh, potentialNormal},
+		{potentialNormal, potentialNormal},
+		{potentialNormal, potentialNormal},
+		{potentialNormal, potentialNormal},
+	}
+	if g.Potential != want {
+		t.Errorf("potential = %v, want %v", g.Potential, want)
+	}
+	// A single high allele is recessive and raises nothing
+	if g.TalentFloors() != [6]int{} {
+		t.Errorf("founder talent floors = %v, want none", g.TalentFloors())
+	}
+}
+
+func TestBreedGenes(t *testing.T) {
+	a := Genes{
+		Color:       [2]string{colorNormal, colorShiny},
+		Personality: [2]string{"chill", "energetic"},
+	}
+	b := Genes{
+		Color:       [2]string{colorShiny, colorNormal},
+		Personality: [2]string{"foodie", "gentle"},
+	}
+	for i := range a.Potential {
+		a.Potential[i] = [2]string{potentialNormal, potentialHigh}
+		b.Potential[i] = [2]string{potentialHigh, potentialNormal}
+	}
+
+	// Each gene draws A's allele, B's allele, then the order (0 keeps A first)
+	ints := []int{1, 0, 0, 0, 1, 1}
+	for range a.Potential {
+		ints = append(ints, 1, 0, 0)
+	}
+	child := BreedGenes(&scriptedRand{ints: ints, floats: []float64{0.5}}, a, b)
+	if child.Color != [2]string{colorShiny, colorShiny} || child.ColorVariant() != colorShiny {
+		t.Errorf("color = %v, want shiny/shiny", child.Color)
+	}
+	if child.Personality != [2]string{"gentle", "chill"} || child.ExpressedPersonality() != "gentle" {
+		t.Errorf("personality = %v", child.Personality)
+	}
+	if floors := child.TalentFloors(); floors != [6]int{15, 15, 15, 15, 15, 15} {
+		t.Errorf("talent floors = %v, want all %d", floors, talentHighFloor)
+	}
+
+	// A color mutation turns one allele pastel (or, one time in three, shiny)
+	ints = append(make([]int, 3+3+18), 1, 1)
+	child = BreedGenes(&scriptedRand{ints: ints, floats: []float64{0.01}}, a, b)
+	if child.Color != [2]string{colorNormal, colorPastel} {
+		t.Errorf("mutated color = %v, want normal/pastel", child.Color)
+	}
+}
+
+func TestBreedGenesReplays(t *testing.T) {
+	a := founderGenes(models.Slime{Personality: "curious", TalentStr: 30})
+	b := founderGenes(models.Slime{Personality: "chill", TalentStr: 28})
+	for seed := int64(0); seed < 20; seed++ {
+		if x, y := BreedGenes(NewRand(seed), a, b), BreedGenes(NewRand(seed), a, b); x != y {
+			t.Fatalf("seed %d: %+v != %+v", seed, x, y)
+		}
+	}
+}
+
+func TestBreedTalents(t *testing.T) {
+	a := talentSlime([6]int{2, 2, 2, 2, 2, 2})
+	b := talentSlime([6]int{4, 4, 4, 4, 4, 4})
+	var genes Genes
+	genes.Potential[0] = [2]string{potentialHigh, potentialHigh}
+	got := BreedTalents(&scriptedRand{floats: repeatFloat(0.1, 6), ints: repeatInt(3, 6)}, a, b, genes)
+	if want := [6]int{15, 2, 2, 2, 2, 2}; got != want {
+		t.Errorf("BreedTalents = %v, want %v", got, want)
+	}
+}
+
+func breedTestParent(id byte, parents ...string) breedParent {
+	return breedParent{
+		Slime:     models.Slime{ID: pgtype.UUID{Bytes: [16]byte{id}, Valid: true}, Level: 10, Affection: 80},
+		ParentIDs: parents,
+	}
+}
+
+func TestCheckBreedable(t *testing.T) {
+	now := time.Now()
+	idOf := func(p breedParent) string { return uuidToString(p.Slime.ID) }
+	grandpa := idOf(breedTestParent(9))
+	a := breedTestParent(1)
+	b := breedTestParent(2)
+
+	tests := []struct {
+		name string
+		a, b func() breedParent
+		want error
+	}{
+		{"eligible", func() breedParent { return a }, func() breedParent { return b }, nil},
+		{"same slime", func() breedParent { return a }, func() breedParent { return a }, ErrBreedSame},
+		{"low level", func() breedParent { p := a; p.Slime.Level = 9; return p }, func() breedParent { return b }, ErrBreedLevel},
+		{"low affection", func() breedParent { return a }, func() breedParent { p := b; p.Slime.Affection = 79; return p }, ErrBreedAffection},
+		{"sick", func() breedParent { p := a; p.Slime.IsSick = true; return p }, func() breedParent { return b }, ErrBreedSick},
+		{"cooldown", func() breedParent { p := a; p.LastBred = now.Add(-23 * time.Hour); return p }, func() breedParent { return b }, ErrBreedCooldown},
+		{"cooldown over", func() breedParent { p := a; p.LastBred = now.Add(-25 * time.Hour); return p }, func() breedParent { return b }, nil},
+		{"parent and child", func() breedParent { return a }, func() breedParent { return breedTestParent(2, idOf(a), grandpa) }, ErrBreedKin},
+		{"child and parent", func() breedParent { return breedTestParent(1, grandpa, idOf(b)) }, func() breedParent { return b }, ErrBreedKin},
+		{"half siblings", func() breedParent { return breedTestParent(1, grandpa, "x") }, func() breedParent { return breedTestParent(2, "y", grandpa) }, ErrBreedKin},
+		{"unrelated bred slimes", func() breedParent { return breedTestParent(1, "w", "x") }, func() breedParent { return breedTestParent(2, "y", "z") }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkBreedable(tt.a(), tt.b(), now); !errors.Is(got, tt.want) {
+				t.Errorf("checkBreedable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFamilyTree(t *testing.T) {
+	name := "Junior"
+	shiny := Genes{Color: [2]string{colorShiny, colorShiny}}
+	lineage := map[string]lineageRecord{
+		"child": {SlimeID: "child", ParentA: "mom", ParentB: "dad", ParentASpecies: 1, ParentBSpecies: 2, SpeciesID: 1, Generation: 2, Genes: shiny},
+		"mom":   {SlimeID: "mom", ParentA: "gm", ParentB: "gp", ParentASpecies: 3, ParentBSpecies: 4, SpeciesID: 1, Generation: 1},
+	}
+	living := map[string]familySlime{
+		"child": {SpeciesID: 1, Name: &name, Element: "water", Personality: "chill", Level: 3},
+		"dad":   {SpeciesID: 2, Element: "fire", Personality: "curious", Level: 20},
+	}
+
+	tree := buildFamilyTree("child", 1, 3, lineage, living)
+	if !tree.Alive || tree.Name != &name || tree.ColorVariant != colorShiny || tree.Generation != 2 || len(tree.Parents) != 2 {
+		t.Fatalf("root = %+v", tree)
+	}
+	mom, dad := tree.Parents[0], tree.Parents[1]
+	// Mom was merged away but her lineage row keeps her in the tree
+	if mom.ID != "mom" || mom.Alive || mom.SpeciesID != 1 || mom.Generation != 1 || len(mom.Parents) != 2 {
+		t.Errorf("mom = %+v", mom)
+	}
+	if gm := mom.Parents[0]; gm.ID != "gm" || gm.SpeciesID != 3 || gm.Alive || gm.Parents != nil {
+		t.Errorf("grandma = %+v", gm)
+	}
+	// Dad is a living founder
+	if dad.ID != "dad" || !dad.Alive || dad.Level != 20 || dad.Generation != 0 || dad.ColorVariant != colorNormal || dad.Parents != nil {
+		t.Errorf("dad = %+v", dad)
+	}
+
+	if tree := buildFamilyTree("child", 1, 1, lineage, living); tree.Parents != nil {
+		t.Errorf("depth 1 listed parents: %+v", tree.Parents)
+	}
+}
@@ -1,9 +1,12 @@
 package game
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 const (
@@ -15,9 +18,9 @@ const (
 func (h *Handler) SendGift(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.userRepo.Pool()
 
 	var body struct {
+		ReceiverID       string `json:"receiver_id"`
 		ReceiverNickname string `json:"receiver_nickname"`
 		Type             string `json:"type"`     // "gold" or "gems"
 		Amount           int    `json:"amount"`
@@ -27,19 +30,26 @@ func (h *Handler) SendGift(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
 	}
 
-	if body.ReceiverNickname == "" || body.Amount <= 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "receiver_nickname, type, and amount required"})
+	if (body.ReceiverID == "" && body.ReceiverNickname == "") || body.Amount <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "receiver_id or receiver_nickname, type, and amount required"})
 	}
 	if body.Type != "gold" && body.Type != "gems" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "type must be 'gold' or 'gems'"})
 	}
 
-	// Find receiver by nickname
+	// Find receiver by ID or nickname
 	var receiverID string
-	err := pool.QueryRow(ctx,
-		`SELECT id FROM users WHERE nickname = $1`,
-		body.ReceiverNickname,
-	).Scan(&receiverID)
+	var err error
+	if body.ReceiverID != "" {
+		if _, perr := uuid.Parse(body.ReceiverID); perr != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+		}
+		if _, err = h.userRepo.FindByID(ctx, body.ReceiverID); err == nil {
+			receiverID = body.ReceiverID
+		}
+	} else {
+		receiverID, err = h.userRepo.IDByNickname(ctx, body.ReceiverNickname)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
 	}
@@ -49,14 +59,18 @@ func (h *Handler) SendGift(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot_send_to_self"})
 	}
 
+	// Gifts can only go to friends
+	isFriend, err := h.friendRepo.AreFriends(ctx, userID, receiverID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to send gift"})
+	}
+	if !isFriend {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not_friends"})
+	}
+
 	// Check daily limit
 	today := time.Now().Truncate(24 * time.Hour)
-	var dailyTotal int
-	pool.QueryRow(ctx,
-		`SELECT COALESCE(SUM(amount), 0) FROM gift_logs
-		 WHERE sender_id = $1 AND gift_type = $2 AND created_at >= $3`,
-		userID, body.Type, today,
-	).Scan(&dailyTotal)
+	dailyTotal, _ := h.giftRepo.SentSince(ctx, userID, body.Type, today)
 
 	limit := dailyGoldLimit
 	if body.Type == "gems" {
@@ -71,33 +85,34 @@ func (h *Handler) SendGift(c *fiber.Ctx) error {
 		})
 	}
 
-	// Deduct from sender
+	// Move the gift from sender to receiver in one transaction
+	var giftGold int64
+	var giftGems int
 	if body.Type == "gold" {
-		if err := h.userRepo.SpendCurrency(ctx, userID, int64(body.Amount), 0, 0); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_funds"})
-		}
-		// Grant to receiver
-		h.userRepo.AddCurrency(ctx, receiverID, int64(body.Amount), 0, 0)
+		giftGold = int64(body.Amount)
 	} else {
-		if err := h.userRepo.SpendCurrency(ctx, userID, 0, body.Amount, 0); err != nil {
+		giftGems = body.Amount
+	}
+	if err := h.userRepo.TransferCurrency(ctx, userID, receiverID, giftGold, giftGems, repository.ReasonGift, ""); err != nil {
+		if errors.Is(err, repository.ErrInsufficientFunds) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_funds"})
 		}
-		h.userRepo.AddCurrency(ctx, receiverID, 0, body.Amount, 0)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to send gift"})
 	}
 
 	// Log game action for gift
 	if body.Type == "gold" {
-		LogGameAction(pool, userID, "gift_send", "gift", -int64(body.Amount), 0, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "gift_send", "gift", -int64(body.Amount), 0, 0, map[string]interface{}{
 			"receiver": receiverID, "type": body.Type, "amount": body.Amount,
 		})
-		LogGameAction(pool, receiverID, "gift_receive", "gift", int64(body.Amount), 0, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, receiverID, "gift_receive", "gift", int64(body.Amount), 0, 0, map[string]interface{}{
 			"sender": userID, "type": body.Type, "amount": body.Amount,
 		})
 	} else {
-		LogGameAction(pool, userID, "gift_send", "gift", 0, -body.Amount, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "gift_send", "gift", 0, -body.Amount, 0, map[string]interface{}{
 			"receiver": receiverID, "type": body.Type, "amount": body.Amount,
 		})
-		LogGameAction(pool, receiverID, "gift_receive", "gift", 0, body.Amount, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, receiverID, "gift_receive", "gift", 0, body.Amount, 0, map[string]interface{}{
 			"sender": userID, "type": body.Type, "amount": body.Amount,
 		})
 	}
@@ -107,11 +122,7 @@ func (h *Handler) SendGift(c *fiber.Ctx) error {
 	if len(msg) > 200 {
 		msg = msg[:200]
 	}
-	pool.Exec(ctx,
-		`INSERT INTO gift_logs (sender_id, receiver_id, gift_type, amount, message)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		userID, receiverID, body.Type, body.Amount, msg,
-	)
+	h.giftRepo.Log(ctx, userID, receiverID, body.Type, body.Amount, msg)
 
 	// Send mailbox notification to receiver
 	senderUser, _ := h.userRepo.FindByID(ctx, userID)
@@ -142,11 +153,7 @@ func (h *Handler) SendGift(c *fiber.Ctx) error {
 	}
 
 	// We already gave the currency directly, so mailbox is just a notification (reward=0)
-	pool.Exec(ctx, `
-		INSERT INTO mailbox (user_id, title, body, mail_type, reward_gold, reward_gems)
-		VALUES ($1, $2, $3, 'gift', 0, 0)`,
-		receiverID, title, mailBody,
-	)
+	h.mailboxRepo.Send(ctx, receiverID, title, mailBody, "gift", 0, 0, nil)
 	_ = rewardGold
 	_ = rewardGems
 
@@ -169,22 +176,11 @@ func intToStr(n int) string {
 func (h *Handler) GetGiftHistory(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.userRepo.Pool()
-
-	rows, err := pool.Query(ctx, `
-		SELECT gl.id, su.nickname, ru.nickname, gl.gift_type, gl.amount, COALESCE(gl.message, ''), gl.created_at,
-			CASE WHEN gl.sender_id = $1 THEN 'sent' ELSE 'received' END as direction
-		FROM gift_logs gl
-		JOIN users su ON su.id = gl.sender_id
-		JOIN users ru ON ru.id = gl.receiver_id
-		WHERE gl.sender_id = $1 OR gl.receiver_id = $1
-		ORDER BY gl.created_at DESC
-		LIMIT 50
-	`, userID)
+
+	rows, err := h.giftRepo.History(ctx, userID, 50)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch gift history"})
 	}
-	defer rows.Close()
 
 	type GiftEntry struct {
 		ID               string `json:"id"`
@@ -197,14 +193,18 @@ func (h *Handler) GetGiftHistory(c *fiber.Ctx) error {
 		Direction        string `json:"direction"`
 	}
 
-	history := make([]GiftEntry, 0)
-	for rows.Next() {
-		var g GiftEntry
-		var createdAt time.Time
-		if rows.Scan(&g.ID, &g.SenderNickname, &g.ReceiverNickname, &g.GiftType, &g.Amount, &g.Message, &createdAt, &g.Direction) == nil {
-			g.CreatedAt = createdAt.Format(time.RFC3339)
-			history = append(history, g)
-		}
+	history := make([]GiftEntry, 0, len(rows))
+	for _, g := range rows {
+		history = append(history, GiftEntry{
+			ID:               g.ID,
+			SenderNickname:   g.SenderNickname,
+			ReceiverNickname: g.ReceiverNickname,
+			GiftType:         g.GiftType,
+			Amount:           g.Amount,
+			Message:          g.Message,
+			CreatedAt:        g.CreatedAt.Format(time.RFC3339),
+			Direction:        g.Direction,
+		})
 	}
 
 	return c.JSON(fiber.Map{"history": history})
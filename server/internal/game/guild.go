@@ -0,0 +1,457 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+const (
+	guildCreateCost      = 10000 // gold
+	guildXPPerMission    = 20
+	guildChatPageSize    = 50
+	guildChatCooldown    = 2 * time.Second
+	guildNameMinLen      = 2
+	guildNameMaxLen      = 12
+	guildDescriptionMax  = 200
+	guildChatMessageMax  = 300
+	guildSearchPageLimit = 30
+)
+
+// guildErrorResponse maps guild repository errors to API errors.
+func guildErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrGuildNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "guild_not_found"})
+	case errors.Is(err, repository.ErrNotInGuild):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not_in_guild"})
+	case errors.Is(err, repository.ErrAlreadyInGuild):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "already_in_guild"})
+	case errors.Is(err, repository.ErrGuildNameTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "name_taken"})
+	case errors.Is(err, repository.ErrGuildFull):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "guild_full"})
+	case errors.Is(err, repository.ErrGuildForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "insufficient_role"})
+	case errors.Is(err, repository.ErrGuildInviteOnly):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "invite_only"})
+	case errors.Is(err, repository.ErrGuildRequestExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "request_pending"})
+	case errors.Is(err, repository.ErrGuildRequestNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "request_not_found"})
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_funds"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "guild operation failed"})
+}
+
+func validJoinPolicy(p string) bool {
+	return p == models.GuildJoinOpen || p == models.GuildJoinApply || p == models.GuildJoinInvite
+}
+
+// guildInfo is the guild as shown in responses, with XP progress to the next level.
+func guildInfo(g *models.Guild) fiber.Map {
+	return fiber.Map{
+		"id":            g.ID,
+		"name":          g.Name,
+		"description":   g.Description,
+		"join_policy":   g.JoinPolicy,
+		"level":         g.Level,
+		"xp":            g.XP,
+		"next_level_xp": repository.GuildNextLevelXP(g.Level),
+		"member_count":  g.MemberCount,
+		"max_members":   g.MaxMembers,
+		"created_at":    g.CreatedAt,
+	}
+}
+
+// GET /api/guild — the current user's guild with members, or null
+func (h *Handler) GetMyGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	m, err := h.guildRepo.Membership(ctx, userID)
+	if errors.Is(err, repository.ErrNotInGuild) {
+		invites, _ := h.guildRepo.InvitesFor(ctx, userID)
+		return c.JSON(fiber.Map{"guild": nil, "invites": invites, "create_cost": guildCreateCost})
+	}
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+
+	g, err := h.guildRepo.Get(ctx, m.GuildID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	members, err := h.guildRepo.Members(ctx, m.GuildID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	ids := make([]string, len(members))
+	for i, mem := range members {
+		ids[i] = mem.UserID
+	}
+	presence := h.lookupPresence(ctx, ids)
+
+	memberList := make([]fiber.Map, 0, len(members))
+	for _, mem := range members {
+		memberList = append(memberList, fiber.Map{
+			"user_id":        mem.UserID,
+			"nickname":       mem.Nickname,
+			"level":          mem.Level,
+			"role":           mem.Role,
+			"contributed_xp": mem.ContributedXP,
+			"joined_at":      mem.JoinedAt,
+			"online":         presence[mem.UserID].Online,
+		})
+	}
+
+	resp := fiber.Map{
+		"guild":   guildInfo(g),
+		"my_role": m.Role,
+		"members": memberList,
+	}
+	if m.Role != models.GuildRoleMember {
+		requests, _ := h.guildRepo.PendingRequests(ctx, m.GuildID)
+		resp["requests"] = requests
+	}
+	return c.JSON(resp)
+}
+
+// GET /api/guild/search?q=
+func (h *Handler) SearchGuilds(c *fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	guilds, err := h.guildRepo.Search(c.Context(), q, guildSearchPageLimit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to search guilds"})
+	}
+	result := make([]fiber.Map, 0, len(guilds))
+	for i := range guilds {
+		result = append(result, guildInfo(&guilds[i]))
+	}
+	return c.JSON(fiber.Map{"guilds": result})
+}
+
+// GET /api/guild/:id — public view of a guild
+func (h *Handler) GetGuild(c *fiber.Ctx) error {
+	ctx := c.Context()
+	guildID := c.Params("id")
+	if _, err := uuid.Parse(guildID); err != nil {
+		return guildErrorResponse(c, repository.ErrGuildNotFound)
+	}
+
+	g, err := h.guildRepo.Get(ctx, guildID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	members, err := h.guildRepo.Members(ctx, guildID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	memberList := make([]fiber.Map, 0, len(members))
+	for _, mem := range members {
+		memberList = append(memberList, fiber.Map{
+			"nickname": mem.Nickname,
+			"level":    mem.Level,
+			"role":     mem.Role,
+		})
+	}
+	return c.JSON(fiber.Map{"guild": guildInfo(g), "members": memberList})
+}
+
+// POST /api/guild — found a guild (costs guildCreateCost gold)
+func (h *Handler) CreateGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		JoinPolicy  string `json:"join_policy"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	body.Description = strings.TrimSpace(body.Description)
+	if body.JoinPolicy == "" {
+		body.JoinPolicy = models.GuildJoinApply
+	}
+	if n := utf8.RuneCountInString(body.Name); n < guildNameMinLen || n > guildNameMaxLen {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "name must be 2-12 characters"})
+	}
+	if utf8.RuneCountInString(body.Description) > guildDescriptionMax || !validJoinPolicy(body.JoinPolicy) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid description or join_policy"})
+	}
+
+	guildID, err := h.guildRepo.Create(c.Context(), userID, body.Name, body.Description, body.JoinPolicy, guildCreateCost)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+
+	LogGameAction(h.gameLogRepo, userID, "guild_create", "guild", -guildCreateCost, 0, 0, map[string]interface{}{
+		"guild_id": guildID, "name": body.Name,
+	})
+	return c.JSON(fiber.Map{"success": true, "guild_id": guildID})
+}
+
+// PATCH /api/guild — leader edits description and join policy
+func (h *Handler) UpdateGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var body struct {
+		Description string `json:"description"`
+		JoinPolicy  string `json:"join_policy"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	body.Description = strings.TrimSpace(body.Description)
+	if utf8.RuneCountInString(body.Description) > guildDescriptionMax || !validJoinPolicy(body.JoinPolicy) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid description or join_policy"})
+	}
+
+	if err := h.guildRepo.Update(c.Context(), userID, body.Description, body.JoinPolicy); err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// DELETE /api/guild — leader disbands the guild
+func (h *Handler) DisbandGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	guildID, err := h.guildRepo.Disband(c.Context(), userID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	log.Info().Str("user_id", userID).Str("guild_id", guildID).Msg("Guild disbanded")
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// POST /api/guild/:id/join — join, accept an invite, or apply depending on join policy
+func (h *Handler) JoinGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	guildID := c.Params("id")
+	if _, err := uuid.Parse(guildID); err != nil {
+		return guildErrorResponse(c, repository.ErrGuildNotFound)
+	}
+
+	joined, err := h.guildRepo.Join(c.Context(), userID, guildID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "joined": joined, "applied": !joined})
+}
+
+// POST /api/guild/leave
+func (h *Handler) LeaveGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	disbanded, err := h.guildRepo.Leave(c.Context(), userID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "disbanded": disbanded})
+}
+
+// POST /api/guild/invites — officer invites a player by user_id or nickname
+func (h *Handler) InviteToGuild(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	var body struct {
+		UserID   string `json:"user_id"`
+		Nickname string `json:"nickname"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	targetID := ""
+	switch {
+	case body.UserID != "":
+		if _, err := uuid.Parse(body.UserID); err == nil {
+			if _, err := h.userRepo.FindByID(ctx, body.UserID); err == nil {
+				targetID = body.UserID
+			}
+		}
+	case body.Nickname != "":
+		targetID, _ = h.userRepo.IDByNickname(ctx, body.Nickname)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "user_id or nickname required"})
+	}
+	if targetID == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user_not_found"})
+	}
+
+	joined, err := h.guildRepo.Invite(ctx, userID, targetID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "joined": joined})
+}
+
+// POST /api/guild/invites/:id/accept
+func (h *Handler) AcceptGuildInvite(c *fiber.Ctx) error {
+	return h.respondGuildInvite(c, true)
+}
+
+// POST /api/guild/invites/:id/decline
+func (h *Handler) DeclineGuildInvite(c *fiber.Ctx) error {
+	return h.respondGuildInvite(c, false)
+}
+
+func (h *Handler) respondGuildInvite(c *fiber.Ctx, accept bool) error {
+	userID := c.Locals("user_id").(string)
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return guildErrorResponse(c, repository.ErrGuildRequestNotFound)
+	}
+	guildID, err := h.guildRepo.RespondInvite(c.Context(), userID, c.Params("id"), accept)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "guild_id": guildID})
+}
+
+// POST /api/guild/applications/:id/accept
+func (h *Handler) AcceptGuildApplication(c *fiber.Ctx) error {
+	return h.respondGuildApplication(c, true)
+}
+
+// POST /api/guild/applications/:id/decline
+func (h *Handler) DeclineGuildApplication(c *fiber.Ctx) error {
+	return h.respondGuildApplication(c, false)
+}
+
+func (h *Handler) respondGuildApplication(c *fiber.Ctx, accept bool) error {
+	userID := c.Locals("user_id").(string)
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return guildErrorResponse(c, repository.ErrGuildRequestNotFound)
+	}
+	applicantID, err := h.guildRepo.RespondApplication(c.Context(), userID, c.Params("id"), accept)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true, "user_id": applicantID})
+}
+
+// DELETE /api/guild/applications/:id — withdraw your own application
+func (h *Handler) WithdrawGuildApplication(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return guildErrorResponse(c, repository.ErrGuildRequestNotFound)
+	}
+	if err := h.guildRepo.WithdrawApplication(c.Context(), userID, c.Params("id")); err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// POST /api/guild/members/:id/role — leader sets officer/member, or hands over leadership
+func (h *Handler) SetGuildRole(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if body.Role != models.GuildRoleLeader && body.Role != models.GuildRoleOfficer && body.Role != models.GuildRoleMember {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid role"})
+	}
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return guildErrorResponse(c, repository.ErrNotInGuild)
+	}
+
+	if err := h.guildRepo.SetRole(c.Context(), userID, c.Params("id"), body.Role); err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// DELETE /api/guild/members/:id — kick a member of lower rank
+func (h *Handler) KickGuildMember(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	if _, err := uuid.Parse(c.Params("id")); err != nil {
+		return guildErrorResponse(c, repository.ErrNotInGuild)
+	}
+	if err := h.guildRepo.Kick(c.Context(), userID, c.Params("id")); err != nil {
+		return guildErrorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// GET /api/guild/chat?after=<id> — clients poll with the last id they saw
+func (h *Handler) GetGuildChat(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	m, err := h.guildRepo.Membership(ctx, userID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	after := int64(c.QueryInt("after", 0))
+	if after < 0 {
+		after = 0
+	}
+	messages, err := h.guildRepo.Messages(ctx, m.GuildID, after, guildChatPageSize)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch chat"})
+	}
+	return c.JSON(fiber.Map{"messages": messages})
+}
+
+// POST /api/guild/chat
+func (h *Handler) PostGuildChat(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	body.Message = strings.TrimSpace(body.Message)
+	if body.Message == "" || utf8.RuneCountInString(body.Message) > guildChatMessageMax {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "message must be 1-300 characters"})
+	}
+
+	m, err := h.guildRepo.Membership(ctx, userID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	if ok, err := h.rdb.SetNX(ctx, "guild_chat_cd:"+userID, 1, guildChatCooldown).Result(); err == nil && !ok {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too_fast"})
+	}
+
+	msg, err := h.guildRepo.PostMessage(ctx, m.GuildID, userID, body.Message)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to send message"})
+	}
+	return c.JSON(fiber.Map{"message": msg})
+}
+
+// addMissionGuildXP credits guild XP for a claimed daily mission. Players without a
+// guild are skipped; returns the XP granted.
+func (h *Handler) addMissionGuildXP(ctx context.Context, userID string) int {
+	level, leveledUp, err := h.guildRepo.AddXP(ctx, userID, guildXPPerMission)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotInGuild) {
+			log.Warn().Err(err).Str("user_id", userID).Msg("Failed to add guild XP")
+		}
+		return 0
+	}
+	if leveledUp {
+		log.Info().Str("user_id", userID).Int("level", level).Msg("Guild leveled up")
+	}
+	return guildXPPerMission
+}
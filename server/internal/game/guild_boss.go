@@ -0,0 +1,264 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// Guild bosses walk the same stageBosses ladder as the world boss, with HP sized for a
+// guild instead of the whole server. After the last stage the final boss repeats.
+const (
+	guildBossHPDivisor       = 20
+	guildBossDuration        = 72 * time.Hour
+	guildBossAttacksPerDay   = 3
+	guildBossParticipationGo = 100 // gold per attack
+	guildBossXPPerStage      = 100 // guild XP per stage cleared, times the stage
+)
+
+// guildBossRankGems are bonus gems for the top 3 damage dealers when the boss falls.
+var guildBossRankGems = []int{10, 5, 3}
+
+func guildBossStageIdx(stage int) int {
+	idx := stage - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(stageBosses) {
+		idx = len(stageBosses) - 1
+	}
+	return idx
+}
+
+// currentGuildBoss returns the guild's latest boss, spawning a fresh one when there is
+// none or the last one ran out of time. A boss that fell without being settled is
+// settled first.
+func (h *Handler) currentGuildBoss(ctx context.Context, guildID string) (*repository.GuildBoss, error) {
+	spawn := func(stage int) repository.GuildBoss {
+		rng, _ := h.newRand()
+		return newGuildBoss(rng, stage)
+	}
+	boss, err := h.guildBossRepo.Current(ctx, guildID, spawn)
+	if err != nil || !boss.Defeated {
+		return boss, err
+	}
+	if err := h.settleGuildBoss(context.WithoutCancel(ctx), boss.ID); err != nil {
+		log.Error().Err(err).Int("boss_id", boss.ID).Msg("Guild boss settlement retry failed")
+		return boss, nil
+	}
+	return h.guildBossRepo.Current(ctx, guildID, spawn)
+}
+
+// newGuildBoss rolls the boss for a stage.
+func newGuildBoss(rng Rand, stage int) repository.GuildBoss {
+	def := stageBosses[guildBossStageIdx(stage)]
+	return repository.GuildBoss{
+		Stage:     stage,
+		Name:      def.Name,
+		Element:   def.Element,
+		MaxHP:     def.BaseHP/guildBossHPDivisor + int64(rng.Intn(1000)),
+		ExpiresAt: time.Now().Add(guildBossDuration),
+	}
+}
+
+// GET /api/guild/boss — the guild's boss and its damage table
+func (h *Handler) GetGuildBoss(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.UserContext()
+
+	m, err := h.guildRepo.Membership(ctx, userID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	boss, err := h.currentGuildBoss(ctx, m.GuildID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load guild boss"})
+	}
+	table, err := h.guildBossRepo.DamageTable(ctx, boss.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load guild boss"})
+	}
+
+	var myDamage int64
+	myRank := 0
+	for i, d := range table {
+		if d.UserID == userID {
+			myDamage, myRank = d.Damage, i+1
+		}
+	}
+	attackCount, _ := h.rdb.Get(ctx, guildBossAttackKey(boss.ID, userID)).Int()
+	idx := guildBossStageIdx(boss.Stage)
+
+	return c.JSON(fiber.Map{
+		"boss": fiber.Map{
+			"id":          boss.ID,
+			"name":        boss.Name,
+			"element":     boss.Element,
+			"stage":       boss.Stage,
+			"max_hp":      boss.MaxHP,
+			"current_hp":  boss.CurrentHP,
+			"expires_at":  boss.ExpiresAt,
+			"defeated":    boss.Defeated,
+			"reward_gold": stageGoldReward[idx] / 2,
+			"reward_gems": stageGemReward[idx] / 5,
+		},
+		"damage_table": table,
+		"my_damage":    myDamage,
+		"my_rank":      myRank,
+		"my_attacks":   attackCount,
+		"max_attacks":  guildBossAttacksPerDay,
+	})
+}
+
+func guildBossAttackKey(bossID int, userID string) string {
+	return fmt.Sprintf("guild_boss_attacks:%d:%s", bossID, userID)
+}
+
+// POST /api/guild/boss/attack — attack with a party of up to 5 slimes
+func (h *Handler) AttackGuildBoss(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.UserContext()
+
+	slimeIDs, err := parseBossParty(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "slime_id or slime_ids required"})
+	}
+	m, err := h.guildRepo.Membership(ctx, userID)
+	if err != nil {
+		return guildErrorResponse(c, err)
+	}
+	boss, err := h.currentGuildBoss(ctx, m.GuildID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load guild boss"})
+	}
+	if boss.Defeated || boss.CurrentHP <= 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no active boss"})
+	}
+
+	// Count the attack before fighting so concurrent requests can't all pass the limit;
+	// it's given back if the attack doesn't land
+	attackKey := guildBossAttackKey(boss.ID, userID)
+	attackCount, err := h.rdb.Incr(ctx, attackKey).Result()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "attack failed"})
+	}
+	h.rdb.ExpireAt(ctx, attackKey, time.Now().Add(24*time.Hour))
+	landed := false
+	defer func() {
+		if !landed {
+			h.rdb.Decr(context.Background(), attackKey)
+		}
+	}()
+	if attackCount > guildBossAttacksPerDay {
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error":     "daily attack limit reached",
+			"remaining": 0,
+		})
+	}
+
+	rng, seed := h.newRand()
+	target := worldBossTarget(boss.Element, boss.CurrentHP, boss.MaxHP)
+	_, phase := target.Phase()
+	totalDamage, comboMultiplier, slimeResults := h.partyBossDamage(ctx, rng, userID, slimeIDs, target)
+	if totalDamage == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "no valid slimes in party"})
+	}
+
+	newHP, err := h.guildBossRepo.Attack(ctx, boss.ID, userID, slimeIDs, totalDamage)
+	if errors.Is(err, repository.ErrGuildBossGone) {
+		// Someone else landed the final blow first
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no active boss"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "attack failed"})
+	}
+
+	landed = true
+
+	h.userRepo.AddCurrency(ctx, userID, guildBossParticipationGo, 0, 0, repository.ReasonGuildBoss, strconv.Itoa(boss.ID))
+
+	defeated := newHP <= 0
+	if defeated {
+		// Detached from the request so a dropped connection can't cut the payout short;
+		// SettleGuildBosses retries if this fails
+		if err := h.settleGuildBoss(context.WithoutCancel(ctx), boss.ID); err != nil {
+			log.Error().Err(err).Int("boss_id", boss.ID).Msg("Guild boss settlement after kill failed")
+		}
+	}
+
+	LogGameAction(h.gameLogRepo, userID, "guild_boss_attack", "guild", guildBossParticipationGo, 0, 0, map[string]interface{}{
+		"guild_id": m.GuildID, "boss_id": boss.ID, "damage": totalDamage, "defeated": defeated, "seed": seed,
+	})
+
+	return c.JSON(fiber.Map{
+		"damage":             totalDamage,
+		"boss_hp_remaining":  newHP,
+		"defeated":           defeated,
+		"participation_gold": guildBossParticipationGo,
+		"slime_results":      slimeResults,
+		"combo_multiplier":   comboMultiplier,
+		"phase":              phase.Name,
+		"remaining_attacks":  guildBossAttacksPerDay - int(attackCount),
+	})
+}
+
+// settleGuildBoss pays out a defeated boss exactly once: every member on the damage
+// table gets the stage reward, the top 3 get bonus gems, the guild gets XP, and the
+// next stage spawns. It is a no-op for a boss that is still up or already settled.
+func (h *Handler) settleGuildBoss(ctx context.Context, bossID int) error {
+	rng, seed := h.newRand()
+	var guildID string
+	var stage int
+	s, err := h.guildBossRepo.Settle(ctx, bossID, func(b *repository.GuildBoss, table []repository.GuildBossDamage) *repository.GuildBossSettlement {
+		guildID, stage = b.GuildID, b.Stage
+		idx := guildBossStageIdx(b.Stage)
+		gold := int64(stageGoldReward[idx] / 2)
+		gems := stageGemReward[idx] / 5
+
+		s := &repository.GuildBossSettlement{GuildXP: int64(guildBossXPPerStage * b.Stage)}
+		for i, d := range table {
+			bonus := 0
+			if i < len(guildBossRankGems) {
+				bonus = guildBossRankGems[i]
+			}
+			s.Rewards = append(s.Rewards, repository.GuildBossReward{
+				UserID: d.UserID,
+				Rank:   i + 1,
+				Damage: d.Damage,
+				Gold:   gold,
+				Gems:   gems + bonus,
+			})
+		}
+		next := newGuildBoss(rng, b.Stage+1)
+		s.Next = &next
+		return s
+	})
+	if err != nil || s == nil {
+		return err
+	}
+	log.Info().Str("guild_id", guildID).Int("boss_id", bossID).Int("stage", stage).
+		Int("participants", len(s.Rewards)).Int64("seed", seed).Msg("Guild boss defeated")
+	return nil
+}
+
+// SettleGuildBosses pays out defeated guild bosses whose settlement didn't finish
+// when they fell. Run by the scheduler.
+func (h *Handler) SettleGuildBosses(ctx context.Context) error {
+	ids, err := h.guildBossRepo.Unsettled(ctx)
+	if err != nil {
+		return fmt.Errorf("list unsettled guild bosses: %w", err)
+	}
+	var errs []error
+	for _, id := range ids {
+		if err := h.settleGuildBoss(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("settle guild boss %d: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -35,25 +34,102 @@ type ExplorationDestination struct {
 }
 
 type Handler struct {
-	slimeRepo       *repository.SlimeRepository
-	userRepo        *repository.UserRepository
-	explorationRepo *repository.ExplorationRepository
-	missionRepo     *repository.MissionRepository
-	villageRepo     *repository.VillageRepository
-	gameDataRepo    *repository.GameDataRepository
+	slimeRepo       repository.SlimeStore
+	userRepo        repository.UserStore
+	explorationRepo repository.ExplorationStore
+	missionRepo     repository.MissionStore
+	villageRepo     repository.VillageStore
+	gameDataRepo    repository.GameDataStore
+	friendRepo      repository.FriendStore
+	guildRepo       repository.GuildStore
+	communityRepo   repository.CommunityStore
+	achievementRepo repository.AchievementStore
+	accessoryRepo   repository.AccessoryStore
+	arenaRepo       repository.ArenaStore
+	breedingRepo    repository.BreedingStore
+	gameLogRepo     repository.GameLogStore
+	mailboxRepo     repository.MailboxStore
+	shortsRepo      repository.ShortsStore
+	botRepo         repository.BotStore
+	collectionRepo  repository.CollectionStore
+	trainingRepo    repository.TrainingStore
+	raceRepo        repository.RaceStore
+	leaderboardRepo repository.LeaderboardStore
+	supportRepo     repository.SupportStore
+	idleRepo        repository.IdleStore
+	giftRepo        repository.GiftStore
+	gachaRepo       repository.GachaStore
+	guildBossRepo   repository.GuildBossStore
+	worldBossRepo   repository.WorldBossStore
 	rdb             *redis.Client
+	tokenSecret     []byte
 	destinations    []ExplorationDestination
+	seeds           Seeder
 }
 
-func NewHandler(slimeRepo *repository.SlimeRepository, userRepo *repository.UserRepository, explorationRepo *repository.ExplorationRepository, missionRepo *repository.MissionRepository, villageRepo *repository.VillageRepository, gameDataRepo *repository.GameDataRepository, rdb *redis.Client) *Handler {
+// Stores are the repositories a Handler reads and writes through.
+type Stores struct {
+	Slimes       repository.SlimeStore
+	Users        repository.UserStore
+	Explorations repository.ExplorationStore
+	Missions     repository.MissionStore
+	Villages     repository.VillageStore
+	GameData     repository.GameDataStore
+	Friends      repository.FriendStore
+	Guilds       repository.GuildStore
+	Community    repository.CommunityStore
+	Achievements repository.AchievementStore
+	Accessories  repository.AccessoryStore
+	Arena        repository.ArenaStore
+	Breeding     repository.BreedingStore
+	GameLogs     repository.GameLogStore
+	Mailbox      repository.MailboxStore
+	Shorts       repository.ShortsStore
+	Bots         repository.BotStore
+	Collections  repository.CollectionStore
+	Training     repository.TrainingStore
+	Races        repository.RaceStore
+	Leaderboards repository.LeaderboardStore
+	Support      repository.SupportStore
+	Idle         repository.IdleStore
+	Gifts        repository.GiftStore
+	Gacha        repository.GachaStore
+	GuildBosses  repository.GuildBossStore
+	WorldBosses  repository.WorldBossStore
+}
+
+func NewHandler(stores Stores, rdb *redis.Client, tokenSecret string) *Handler {
 	h := &Handler{
-		slimeRepo:       slimeRepo,
-		userRepo:        userRepo,
-		explorationRepo: explorationRepo,
-		missionRepo:     missionRepo,
-		villageRepo:     villageRepo,
-		gameDataRepo:    gameDataRepo,
+		slimeRepo:       stores.Slimes,
+		userRepo:        stores.Users,
+		explorationRepo: stores.Explorations,
+		missionRepo:     stores.Missions,
+		villageRepo:     stores.Villages,
+		gameDataRepo:    stores.GameData,
+		friendRepo:      stores.Friends,
+		guildRepo:       stores.Guilds,
+		communityRepo:   stores.Community,
+		achievementRepo: stores.Achievements,
+		accessoryRepo:   stores.Accessories,
+		arenaRepo:       stores.Arena,
+		breedingRepo:    stores.Breeding,
+		gameLogRepo:     stores.GameLogs,
+		mailboxRepo:     stores.Mailbox,
+		shortsRepo:      stores.Shorts,
+		botRepo:         stores.Bots,
+		collectionRepo:  stores.Collections,
+		trainingRepo:    stores.Training,
+		raceRepo:        stores.Races,
+		leaderboardRepo: stores.Leaderboards,
+		supportRepo:     stores.Support,
+		idleRepo:        stores.Idle,
+		giftRepo:        stores.Gifts,
+		gachaRepo:       stores.Gacha,
+		guildBossRepo:   stores.GuildBosses,
+		worldBossRepo:   stores.WorldBosses,
 		rdb:             rdb,
+		tokenSecret:     []byte(tokenSecret),
+		seeds:           cryptoSeeder{},
 	}
 	h.loadDestinationsFromDB()
 	return h
@@ -88,7 +164,15 @@ func (h *Handler) loadDestinationsFromDB() {
 	log.Info().Int("count", len(h.destinations)).Msg("Loaded exploration destinations from DB")
 }
 
-func RegisterRoutes(router fiber.Router, h *Handler) {
+// RegisterPublicRoutes mounts game endpoints that need no login.
+func RegisterPublicRoutes(router fiber.Router, h *Handler) {
+	// Probability disclosure
+	router.Get("/shop/rates/:egg_type", h.GetGachaRates)
+}
+
+// RegisterRoutes mounts the game API. idempotent guards reward-granting and purchasing
+// endpoints so a retried request replays the first response instead of granting twice.
+func RegisterRoutes(router fiber.Router, h *Handler, idempotent fiber.Handler) {
 	slimes := router.Group("/slimes")
 	slimes.Get("/", h.ListSlimes)
 	slimes.Post("/merge", h.MergeSlimes)
@@ -107,11 +191,11 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	explorations.Get("/", h.ListExplorations)
 	explorations.Get("/destinations", h.ListDestinations)
 	explorations.Post("/start", h.StartExploration)
-	explorations.Post("/:id/claim", h.ClaimExploration)
+	explorations.Post("/:id/claim", idempotent, h.ClaimExploration)
 
 	shop := router.Group("/shop")
 	shop.Get("/items", h.GetShopItems)
-	shop.Post("/buy", h.BuyItem)
+	shop.Post("/buy", idempotent, h.BuyItem)
 	shop.Get("/capacity", h.GetCapacityInfo)
 	shop.Post("/expand-capacity", h.ExpandCapacity)
 	shop.Get("/gems", h.GetGemPackages)
@@ -131,15 +215,16 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 
 	missions := router.Group("/missions")
 	missions.Get("/daily", h.GetDailyMissions)
-	missions.Post("/:id/claim", h.ClaimMission)
+	missions.Post("/:id/claim", idempotent, h.ClaimMission)
 
 	attendance := router.Group("/attendance")
 	attendance.Get("/", h.GetAttendance)
-	attendance.Post("/claim", h.ClaimAttendance)
+	attendance.Post("/claim", idempotent, h.ClaimAttendance)
 
 	village := router.Group("/village")
 	village.Get("/", h.GetMyVillage)
 	village.Get("/visit", h.GetRandomVillages)
+	village.Get("/friends", h.GetFriendVillages)
 	village.Get("/:id", h.VisitVillage)
 	village.Post("/:id/like", h.LikeVillage)
 	village.Get("/:id/guestbook", h.GetGuestbook)
@@ -166,7 +251,7 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	// Revenue: Daily wheel
 	wheel := router.Group("/wheel")
 	wheel.Get("/", h.GetWheel)
-	wheel.Post("/spin", h.SpinWheel)
+	wheel.Post("/spin", idempotent, h.SpinWheel)
 
 	// Revenue: Boosters
 	router.Get("/boosters", h.GetActiveBoosters)
@@ -188,7 +273,7 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	mailbox := router.Group("/mailbox")
 	mailbox.Get("/", h.GetMailbox)
 	mailbox.Post("/:id/read", h.ReadMail)
-	mailbox.Post("/:id/claim", h.ClaimMail)
+	mailbox.Post("/:id/claim", idempotent, h.ClaimMail)
 
 	// Collection
 	router.Get("/collection/count", h.GetCollectionCount)
@@ -201,14 +286,48 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	// Idle/Offline rewards
 	idle := router.Group("/idle")
 	idle.Get("/status", h.GetIdleStatus)
-	idle.Post("/collect", h.CollectIdleReward)
+	idle.Post("/collect", idempotent, h.CollectIdleReward)
 
 	// Crafting
 	crafting := router.Group("/crafting")
 	crafting.Get("/recipes", h.GetCraftingRecipes)
 	crafting.Post("/craft", h.CraftItem)
 
-	// Gifting
+	// Friends & presence
+	friends := router.Group("/friends")
+	friends.Get("/", h.GetFriends)
+	friends.Get("/requests", h.GetFriendRequests)
+	friends.Post("/requests", h.SendFriendRequest)
+	friends.Post("/requests/:id/accept", h.AcceptFriendRequest)
+	friends.Post("/requests/:id/decline", h.DeclineFriendRequest)
+	friends.Delete("/requests/:id", h.CancelFriendRequest)
+	friends.Delete("/:id", h.RemoveFriend)
+	router.Post("/presence/heartbeat", h.Heartbeat)
+
+	// Guilds (/:id routes last so they don't shadow the fixed paths)
+	guild := router.Group("/guild")
+	guild.Get("/", h.GetMyGuild)
+	guild.Post("/", h.CreateGuild)
+	guild.Patch("/", h.UpdateGuild)
+	guild.Delete("/", h.DisbandGuild)
+	guild.Get("/search", h.SearchGuilds)
+	guild.Post("/leave", h.LeaveGuild)
+	guild.Post("/invites", h.InviteToGuild)
+	guild.Post("/invites/:id/accept", h.AcceptGuildInvite)
+	guild.Post("/invites/:id/decline", h.DeclineGuildInvite)
+	guild.Post("/applications/:id/accept", h.AcceptGuildApplication)
+	guild.Post("/applications/:id/decline", h.DeclineGuildApplication)
+	guild.Delete("/applications/:id", h.WithdrawGuildApplication)
+	guild.Post("/members/:id/role", h.SetGuildRole)
+	guild.Delete("/members/:id", h.KickGuildMember)
+	guild.Get("/chat", h.GetGuildChat)
+	guild.Post("/chat", h.PostGuildChat)
+	guild.Get("/boss", h.GetGuildBoss)
+	guild.Post("/boss/attack", h.AttackGuildBoss)
+	guild.Get("/:id", h.GetGuild)
+	guild.Post("/:id/join", h.JoinGuild)
+
+	// Gifting (friends only)
 	gift := router.Group("/gift")
 	gift.Post("/send", h.SendGift)
 	gift.Get("/history", h.GetGiftHistory)
@@ -238,6 +357,16 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	boss.Get("/", h.GetWorldBoss)
 	boss.Post("/attack", h.AttackWorldBoss)
 
+	// PvP Arena
+	arena := router.Group("/arena")
+	arena.Get("/", h.GetArena)
+	arena.Post("/defense", h.SetArenaDefense)
+	arena.Get("/opponents", h.GetArenaOpponents)
+	arena.Post("/attack", h.AttackArena)
+	arena.Get("/battles", h.GetArenaBattles)
+	arena.Get("/battles/:id", h.GetArenaBattle)
+	arena.Get("/ranking", h.GetArenaRanking)
+
 	// Training Grounds
 	training := router.Group("/training")
 	training.Get("/", h.GetTrainingSlots)
@@ -251,6 +380,13 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	slimes.Get("/:id/awakening-cost", h.GetAwakeningCost)
 	slimes.Post("/:id/awaken", h.AwakenSlime)
 	slimes.Post("/merge-forecast", h.GetMergeForecast)
+	slimes.Get("/:id/family-tree", h.GetFamilyTree)
+
+	// Breeding
+	breeding := router.Group("/breeding")
+	breeding.Post("/", h.BreedSlimes)
+	breeding.Get("/eggs", h.GetBreedingEggs)
+	breeding.Post("/eggs/:id/hatch", idempotent, h.HatchBredEgg)
 
 	// Pity status
 	shop.Get("/pity", h.GetPityStatus)
@@ -297,7 +433,6 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	shorts.Get("/:id/comments", h.GetShortComments)
 	shorts.Post("/:id/comments", h.CreateShortComment)
 	shorts.Post("/:id/tip", h.TipShort)
-	shorts.Post("/seed", h.SeedShorts)
 
 	// Profile
 	profile := router.Group("/profile")
@@ -311,16 +446,6 @@ func RegisterRoutes(router fiber.Router, h *Handler) {
 	support.Get("/tickets", h.ListSupportTickets)
 	support.Get("/tickets/:id", h.GetSupportTicketDetail)
 	support.Post("/tickets/:id/reply", h.ReplySupportTicket)
-
-	// Admin: Bot seeding
-	bots := router.Group("/admin/bots")
-	bots.Post("/seed", h.SeedBots)
-	bots.Delete("/", h.DeleteBots)
-
-	// Admin: Activity bot seeding
-	actBots := router.Group("/admin/activity-bots")
-	actBots.Post("/seed", h.SeedActivityBots)
-	actBots.Delete("/", h.DeleteActivityBots)
 }
 
 func (h *Handler) ListSlimes(c *fiber.Ctx) error {
@@ -336,7 +461,7 @@ func (h *Handler) ListSlimes(c *fiber.Ctx) error {
 	result := make([]fiber.Map, 0, len(slimes))
 	for _, s := range slimes {
 		// Apply lazy decay
-		newHunger, newCondition, newAffection, shouldSick := applyLazyDecay(s.Hunger, s.Condition, s.Affection, s.UpdatedAt)
+		newHunger, newCondition, newAffection, shouldSick := applyLazyDecay(s.Hunger, s.Condition, s.Affection, s.UpdatedAt, time.Now())
 		if newHunger != s.Hunger || newCondition != s.Condition || newAffection != s.Affection {
 			_ = h.slimeRepo.UpdateStats(c.Context(), uuidToString(s.ID), newAffection, newHunger, newCondition)
 			s.Hunger = newHunger
@@ -378,7 +503,7 @@ func (h *Handler) handleNurtureAction(c *fiber.Ctx, action string) error {
 	}
 
 	// 3. Apply lazy decay
-	newHunger, newCondition, decayedAffection, shouldSick := applyLazyDecay(slime.Hunger, slime.Condition, slime.Affection, slime.UpdatedAt)
+	newHunger, newCondition, decayedAffection, shouldSick := applyLazyDecay(slime.Hunger, slime.Condition, slime.Affection, slime.UpdatedAt, time.Now())
 	slime.Hunger = newHunger
 	slime.Condition = newCondition
 	slime.Affection = decayedAffection
@@ -693,6 +818,8 @@ func (h *Handler) ClaimExploration(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "destination data missing"})
 	}
 
+	rng, seed := h.newRand()
+
 	// Calculate rewards
 	var goldReward int64
 	var gemsReward int
@@ -700,13 +827,13 @@ func (h *Handler) ClaimExploration(c *fiber.Ctx) error {
 	if goldData, ok := dest.Rewards["gold"].(map[string]interface{}); ok {
 		minG := int64(goldData["min"].(float64))
 		maxG := int64(goldData["max"].(float64))
-		goldReward = minG + int64(rand.Intn(int(maxG-minG+1)))
+		goldReward = minG + int64(rng.Intn(int(maxG-minG+1)))
 	}
 
 	if gemsData, ok := dest.Rewards["gems"].(map[string]interface{}); ok {
 		minG := int(gemsData["min"].(float64))
 		maxG := int(gemsData["max"].(float64))
-		gemsReward = minG + rand.Intn(maxG-minG+1)
+		gemsReward = minG + rng.Intn(maxG-minG+1)
 	}
 
 	// Check recommended element bonus (+50%)
@@ -729,7 +856,7 @@ func (h *Handler) ClaimExploration(c *fiber.Ctx) error {
 	}
 
 	// Grant rewards
-	if err := h.userRepo.AddCurrency(ctx, userID, goldReward, gemsReward, 0); err != nil {
+	if err := h.userRepo.AddCurrency(ctx, userID, goldReward, gemsReward, 0, repository.ReasonExploration, explorationID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to grant rewards"})
 	}
 
@@ -755,7 +882,6 @@ func (h *Handler) ClaimExploration(c *fiber.Ctx) error {
 		Icon       string `json:"icon"`
 	}
 	var droppedMaterials []DroppedMaterial
-	pool := h.slimeRepo.Pool()
 
 	if len(dest.MaterialDrops) > 0 {
 		dropMultiplier := 1.0
@@ -764,12 +890,12 @@ func (h *Handler) ClaimExploration(c *fiber.Ctx) error {
 		}
 		for _, md := range dest.MaterialDrops {
 			adjustedChance := md.Chance * dropMultiplier
-			if rand.Float64() < adjustedChance {
+			if rng.Float64() < adjustedChance {
 				qty := md.MinQty
 				if md.MaxQty > md.MinQty {
-					qty += rand.Intn(md.MaxQty - md.MinQty + 1)
+					qty += rng.Intn(md.MaxQty - md.MinQty + 1)
 				}
-				AddMaterial(ctx, pool, userID, md.MaterialID, qty)
+				h.userRepo.AddMaterial(ctx, userID, md.MaterialID, qty)
 				mat := h.FindMaterial(md.MaterialID)
 				name := ""
 				icon := ""
@@ -797,8 +923,8 @@ func (h *Handler) ClaimExploration(c *fiber.Ctx) error {
 	for _, dm := range droppedMaterials {
 		matIDs = append(matIDs, dm.MaterialID)
 	}
-	LogGameAction(pool, userID, "exploration_claim", "explore", goldReward, gemsReward, 0, map[string]interface{}{
-		"destination_id": exp.DestinationID, "gold": goldReward, "gems": gemsReward, "materials": matIDs,
+	LogGameAction(h.gameLogRepo, userID, "exploration_claim", "explore", goldReward, gemsReward, 0, map[string]interface{}{
+		"destination_id": exp.DestinationID, "gold": goldReward, "gems": gemsReward, "materials": matIDs, "seed": seed,
 	})
 
 	user, _ := h.userRepo.FindByID(ctx, userID)
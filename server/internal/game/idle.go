@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 const (
@@ -17,17 +18,8 @@ const (
 func (h *Handler) GetIdleStatus(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.userRepo.Pool()
 
-	// Ensure idle_progress row exists
-	pool.Exec(ctx, `INSERT INTO idle_progress (user_id) VALUES ($1) ON CONFLICT DO NOTHING`, userID)
-
-	var lastCollected time.Time
-	var goldRate int
-	err := pool.QueryRow(ctx,
-		`SELECT last_collected_at, gold_rate FROM idle_progress WHERE user_id = $1`,
-		userID,
-	).Scan(&lastCollected, &goldRate)
+	lastCollected, goldRate, err := h.idleRepo.Progress(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch idle status"})
 	}
@@ -40,8 +32,7 @@ func (h *Handler) GetIdleStatus(c *fiber.Ctx) error {
 	elapsedInt := int(math.Floor(elapsed))
 
 	// Count user's slimes for bonus
-	var slimeCount int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM slimes WHERE user_id = $1`, userID).Scan(&slimeCount)
+	slimeCount, _ := h.slimeRepo.CountByUser(ctx, userID)
 
 	// Calculate total reward
 	effectiveRate := goldRate + (slimeCount * slimeBonusPerMin)
@@ -60,17 +51,8 @@ func (h *Handler) GetIdleStatus(c *fiber.Ctx) error {
 func (h *Handler) CollectIdleReward(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.userRepo.Pool()
-
-	// Ensure row exists
-	pool.Exec(ctx, `INSERT INTO idle_progress (user_id) VALUES ($1) ON CONFLICT DO NOTHING`, userID)
 
-	var lastCollected time.Time
-	var goldRate int
-	err := pool.QueryRow(ctx,
-		`SELECT last_collected_at, gold_rate FROM idle_progress WHERE user_id = $1`,
-		userID,
-	).Scan(&lastCollected, &goldRate)
+	lastCollected, goldRate, err := h.idleRepo.Progress(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch idle status"})
 	}
@@ -89,8 +71,7 @@ func (h *Handler) CollectIdleReward(c *fiber.Ctx) error {
 		})
 	}
 
-	var slimeCount int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM slimes WHERE user_id = $1`, userID).Scan(&slimeCount)
+	slimeCount, _ := h.slimeRepo.CountByUser(ctx, userID)
 
 	effectiveRate := goldRate + (slimeCount * slimeBonusPerMin)
 	totalGold := int64(elapsedInt * effectiveRate)
@@ -101,17 +82,17 @@ func (h *Handler) CollectIdleReward(c *fiber.Ctx) error {
 	}
 
 	// Grant gold
-	if err := h.userRepo.AddCurrency(ctx, userID, totalGold, 0, 0); err != nil {
+	if err := h.userRepo.AddCurrency(ctx, userID, totalGold, 0, 0, repository.ReasonIdleReward, ""); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to grant reward"})
 	}
 
 	// Log idle collect
-	LogGameAction(pool, userID, "idle_collect", "economy", totalGold, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "idle_collect", "economy", totalGold, 0, 0, map[string]interface{}{
 		"gold": totalGold, "minutes": elapsedInt,
 	})
 
 	// Update last_collected_at
-	pool.Exec(ctx, `UPDATE idle_progress SET last_collected_at = NOW() WHERE user_id = $1`, userID)
+	h.idleRepo.MarkCollected(ctx, userID)
 
 	user, _ := h.userRepo.FindByID(ctx, userID)
 
@@ -2,10 +2,10 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 // POST /api/interact/tree — shake tree for item drop (8hr cooldown)
@@ -24,15 +24,15 @@ func (h *Handler) InteractTree(c *fiber.Ctx) error {
 	}
 
 	// Random gold reward 10-50
-	goldReward := int64(10 + rand.Intn(41))
+	rng, seed := h.newRand()
+	goldReward := int64(rollBetween(rng, 10, 50))
 
 	// Grant reward
-	h.userRepo.AddCurrency(ctx, userID, goldReward, 0, 0)
+	h.userRepo.AddCurrency(ctx, userID, goldReward, 0, 0, repository.ReasonInteractReward, "tree")
 
 	// Log tree interaction
-	pool := h.slimeRepo.Pool()
-	LogGameAction(pool, userID, "interact_tree", "minigame", goldReward, 0, 0, map[string]interface{}{
-		"gold": goldReward,
+	LogGameAction(h.gameLogRepo, userID, "interact_tree", "minigame", goldReward, 0, 0, map[string]interface{}{
+		"gold": goldReward, "seed": seed,
 	})
 
 	// Set cooldown (8 hours)
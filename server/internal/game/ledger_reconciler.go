@@ -0,0 +1,46 @@
+package game
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// LedgerReconciler checks that every wallet balance equals the sum of its
+// currency_ledger entries and records any drift in ledger_discrepancies.
+type LedgerReconciler struct {
+	ledgerRepo *repository.LedgerRepository
+}
+
+// NewLedgerReconciler creates a reconciler; the job scheduler runs Reconcile.
+func NewLedgerReconciler(ledgerRepo *repository.LedgerRepository) *LedgerReconciler {
+	return &LedgerReconciler{ledgerRepo: ledgerRepo}
+}
+
+// Reconcile runs one reconciliation pass and logs every mismatched wallet.
+func (r *LedgerReconciler) Reconcile(ctx context.Context) error {
+	found, err := r.ledgerRepo.Reconcile(ctx)
+	if err != nil {
+		return fmt.Errorf("reconcile ledger: %w", err)
+	}
+	for _, d := range found {
+		log.Warn().
+			Str("user_id", d.UserID).
+			Str("currency", d.Currency).
+			Int64("balance", d.Balance).
+			Int64("ledger_sum", d.LedgerSum).
+			Msg("[Ledger] balance does not match ledger")
+	}
+
+	unbalanced, err := r.ledgerRepo.UnbalancedTransactions(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("[Ledger] unbalanced transaction check failed")
+	} else if unbalanced > 0 {
+		log.Error().Int("count", unbalanced).Msg("[Ledger] transactions whose legs do not sum to zero")
+	}
+
+	log.Info().Int("discrepancies", len(found)).Msg("[Ledger] reconciliation complete")
+	return nil
+}
@@ -32,29 +32,20 @@ func (h *Handler) GetMaterials(c *fiber.Ctx) error {
 func (h *Handler) GetMaterialInventory(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
-	rows, err := pool.Query(ctx, `
-		SELECT material_id, quantity FROM user_materials
-		WHERE user_id = $1 AND quantity > 0
-		ORDER BY material_id
-	`, userID)
+	stacks, err := h.userRepo.Materials(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch inventory"})
 	}
-	defer rows.Close()
 
 	type InventoryItem struct {
 		MaterialID int `json:"material_id"`
 		Quantity   int `json:"quantity"`
 	}
 
-	items := make([]InventoryItem, 0)
-	for rows.Next() {
-		var item InventoryItem
-		if rows.Scan(&item.MaterialID, &item.Quantity) == nil {
-			items = append(items, item)
-		}
+	items := make([]InventoryItem, 0, len(stacks))
+	for _, m := range stacks {
+		items = append(items, InventoryItem{MaterialID: m.MaterialID, Quantity: m.Quantity})
 	}
 
 	return c.JSON(fiber.Map{"inventory": items})
@@ -64,9 +55,8 @@ func (h *Handler) GetMaterialInventory(c *fiber.Ctx) error {
 func (h *Handler) GetCollectionScore(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
-	speciesPoints, setBonus, firstDiscoveryBonus, total := h.CalculateCollectionScore(ctx, pool, userID)
+	speciesPoints, setBonus, firstDiscoveryBonus, total := h.CalculateCollectionScore(ctx, userID)
 
 	return c.JSON(fiber.Map{
 		"species_points":        speciesPoints,
@@ -80,7 +70,6 @@ func (h *Handler) GetCollectionScore(c *fiber.Ctx) error {
 func (h *Handler) GetCodexSets(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	type SetProgress struct {
 		ID          int     `json:"id"`
@@ -100,6 +89,15 @@ func (h *Handler) GetCodexSets(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch sets"})
 	}
 
+	collected, err := h.collectionRepo.SpeciesIDs(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch collection"})
+	}
+	inCollection := make(map[int]bool, len(collected))
+	for _, sid := range collected {
+		inCollection[sid] = true
+	}
+
 	sets := make([]SetProgress, 0, len(dbSets))
 	for _, s := range dbSets {
 		sp := SetProgress{
@@ -117,9 +115,7 @@ func (h *Handler) GetCodexSets(c *fiber.Ctx) error {
 			},
 		}
 		for _, sid := range s.SpeciesIDs {
-			var exists bool
-			pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM collection_entries WHERE user_id = $1 AND species_id = $2)`, userID, sid).Scan(&exists)
-			if exists {
+			if inCollection[sid] {
 				sp.Completed++
 			}
 		}
@@ -133,29 +129,20 @@ func (h *Handler) GetCodexSets(c *fiber.Ctx) error {
 // GET /api/codex/first-discoveries — first discoverers
 func (h *Handler) GetFirstDiscoveries(c *fiber.Ctx) error {
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
-	rows, err := pool.Query(ctx, `
-		SELECT species_id, nickname, discovered_at FROM first_discoveries
-		ORDER BY discovered_at DESC LIMIT 100
-	`)
+	rows, err := h.slimeRepo.FirstDiscoveries(ctx, 100)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch"})
 	}
-	defer rows.Close()
 
 	type Discovery struct {
 		SpeciesID int    `json:"species_id"`
 		Nickname  string `json:"nickname"`
 	}
 
-	discoveries := make([]Discovery, 0)
-	for rows.Next() {
-		var d Discovery
-		var at interface{}
-		if rows.Scan(&d.SpeciesID, &d.Nickname, &at) == nil {
-			discoveries = append(discoveries, d)
-		}
+	discoveries := make([]Discovery, 0, len(rows))
+	for _, d := range rows {
+		discoveries = append(discoveries, Discovery{SpeciesID: d.SpeciesID, Nickname: d.Nickname})
 	}
 
 	return c.JSON(fiber.Map{"discoveries": discoveries})
@@ -74,7 +74,6 @@ func (h *Handler) MergeSlimes(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Fetch both slimes
 	slimeA, err := h.slimeRepo.FindByID(ctx, body.SlimeIDA)
@@ -101,12 +100,11 @@ func (h *Handler) MergeSlimes(c *fiber.Ctx) error {
 	}
 
 	// Check if slimes are in training
-	var trainingCount int
-	pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM training_slots WHERE user_id = $1 AND (slime_id = $2 OR slime_id = $3)`,
-		userID, body.SlimeIDA, body.SlimeIDB,
-	).Scan(&trainingCount)
-	if trainingCount > 0 {
+	inTraining, err := h.slimeRepo.IsSlimeInTraining(ctx, userID, []string{body.SlimeIDA, body.SlimeIDB})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check training status"})
+	}
+	if inTraining {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "one or more slimes are in training"})
 	}
 
@@ -117,27 +115,28 @@ func (h *Handler) MergeSlimes(c *fiber.Ctx) error {
 		if mat == nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "material not found"})
 		}
-		if !ConsumeMaterial(ctx, pool, userID, body.MaterialID) {
+		if ok, _ := h.userRepo.ConsumeMaterial(ctx, userID, body.MaterialID); !ok {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient material"})
 		}
 		material = mat
 	}
 
 	// Calculate user's collection score for mutation requirements
-	_, _, _, collectionScore := h.CalculateCollectionScore(ctx, pool, userID)
+	_, _, _, collectionScore := h.CalculateCollectionScore(ctx, userID)
 
 	// Run synthesis engine
-	result := Synthesize(ctx, h, *slimeA, *slimeB, material, collectionScore)
+	rng, seed := h.newRand()
+	result := Synthesize(ctx, h, rng, *slimeA, *slimeB, material, collectionScore)
 	if result.Error != "" {
 		// Refund material if synthesis failed
 		if material != nil {
-			AddMaterial(ctx, pool, userID, body.MaterialID, 1)
+			h.userRepo.AddMaterial(ctx, userID, body.MaterialID, 1)
 		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": result.Error})
 	}
 
 	// Inherit talents from parents
-	childTalents := InheritTalents(*slimeA, *slimeB)
+	childTalents := InheritTalents(rng, *slimeA, *slimeB)
 
 	// Delete both input slimes
 	if err := h.slimeRepo.Delete(ctx, body.SlimeIDA); err != nil {
@@ -154,7 +153,7 @@ func (h *Handler) MergeSlimes(c *fiber.Ctx) error {
 	}
 
 	// Inherit skills from parents (30% chance per skill)
-	InheritSkills(ctx, pool, body.SlimeIDA, body.SlimeIDB, uuidToString(resultSlime.ID))
+	InheritSkills(ctx, h.slimeRepo, rng, body.SlimeIDA, body.SlimeIDB, uuidToString(resultSlime.ID))
 
 	// Add to codex
 	h.slimeRepo.AddCodexEntry(ctx, userID, result.SpeciesID)
@@ -165,7 +164,7 @@ func (h *Handler) MergeSlimes(c *fiber.Ctx) error {
 	if user != nil {
 		nickname = user.Nickname
 	}
-	isFirstDiscovery := TryFirstDiscovery(ctx, pool, userID, result.SpeciesID, nickname)
+	isFirstDiscovery, _ := h.slimeRepo.RecordFirstDiscovery(ctx, userID, result.SpeciesID, nickname)
 
 	// Track mission progress
 	h.missionRepo.IncrementProgress(ctx, userID, "merge")
@@ -188,9 +187,9 @@ func (h *Handler) MergeSlimes(c *fiber.Ctx) error {
 	if material != nil {
 		materialID = material.ID
 	}
-	LogGameAction(pool, userID, "merge", "item", 0, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "merge", "item", 0, 0, 0, map[string]interface{}{
 		"species_a": slimeA.SpeciesID, "species_b": slimeB.SpeciesID, "result": result.SpeciesID,
-		"merge_type": result.MergeType, "material_id": materialID,
+		"merge_type": result.MergeType, "material_id": materialID, "seed": seed,
 	})
 
 	return c.JSON(fiber.Map{
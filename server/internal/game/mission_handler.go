@@ -1,6 +1,8 @@
 package game
 
 import (
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/slimetopia/server/internal/repository"
 )
@@ -31,21 +33,24 @@ func (h *Handler) ClaimMission(c *fiber.Ctx) error {
 
 	// Grant reward
 	if gold > 0 || gems > 0 {
-		if err := h.userRepo.AddCurrency(c.Context(), userID, gold, gems, 0); err != nil {
+		if err := h.userRepo.AddCurrency(c.Context(), userID, gold, gems, 0, repository.ReasonMissionReward, missionID); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to grant reward"})
 		}
 	}
 
-	pool := h.slimeRepo.Pool()
-	LogGameAction(pool, userID, "mission_claim", "mission", gold, gems, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "mission_claim", "mission", gold, gems, 0, map[string]interface{}{
 		"mission_id": missionID, "gold": gold, "gems": gems,
 	})
 
+	// Daily missions also feed the player's guild
+	guildXP := h.addMissionGuildXP(c.Context(), userID)
+
 	user, _ := h.userRepo.FindByID(c.Context(), userID)
 
 	return c.JSON(fiber.Map{
-		"gold":   gold,
-		"gems":   gems,
+		"gold":     gold,
+		"gems":     gems,
+		"guild_xp": guildXP,
 		"user": fiber.Map{
 			"gold": user.Gold,
 			"gems": user.Gems,
@@ -81,13 +86,12 @@ func (h *Handler) ClaimAttendance(c *fiber.Ctx) error {
 
 	// Grant reward
 	if gold > 0 || gems > 0 {
-		if err := h.userRepo.AddCurrency(c.Context(), userID, gold, gems, 0); err != nil {
+		if err := h.userRepo.AddCurrency(c.Context(), userID, gold, gems, 0, repository.ReasonAttendance, strconv.Itoa(dayNum)); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to grant reward"})
 		}
 	}
 
-	pool := h.slimeRepo.Pool()
-	LogGameAction(pool, userID, "attendance_claim", "mission", gold, int(gems), 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "attendance_claim", "mission", gold, int(gems), 0, map[string]interface{}{
 		"day": dayNum, "gold": gold, "gems": gems,
 	})
 
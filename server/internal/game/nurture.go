@@ -93,10 +93,10 @@ func checkLevelUp(level, exp int) (newLevel int, newExp int, leveledUp bool) {
 	return
 }
 
-// applyLazyDecay calculates stat decay based on time since last update.
+// applyLazyDecay calculates stat decay for the full hours between the last update and now.
 // Returns new hunger, condition, affection, and whether the slime should become sick.
-func applyLazyDecay(hunger, condition, affection int, updatedAt time.Time) (int, int, int, bool) {
-	hours := int(time.Since(updatedAt).Hours())
+func applyLazyDecay(hunger, condition, affection int, updatedAt, now time.Time) (int, int, int, bool) {
+	hours := int(now.Sub(updatedAt).Hours())
 	if hours <= 0 {
 		return hunger, condition, affection, false
 	}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyLazyDecay(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name                          string
+		hunger, condition, affection  int
+		elapsed                       time.Duration
+		wantHunger, wantCond, wantAff int
+		wantSick                      bool
+	}{
+		{"under an hour", 50, 50, 50, 59 * time.Minute, 50, 50, 50, false},
+		{"updated in the future", 50, 50, 50, -2 * time.Hour, 50, 50, 50, false},
+		{"hunger drops 5 per hour", 50, 50, 50, 3*time.Hour + 30*time.Minute, 35, 50, 50, false},
+		{"hunger floors at 0", 3, 50, 50, time.Hour, 0, 48, 49, false},
+		{"starving hours cost condition and affection", 10, 50, 50, 3 * time.Hour, 0, 46, 48, false},
+		{"condition and affection floor at 0", 0, 3, 1, 2 * time.Hour, 0, 0, 0, true},
+		{"zero condition makes sick even when fed", 100, 0, 50, time.Hour, 95, 0, 50, true},
+		{"a long absence", 100, 100, 100, 48 * time.Hour, 0, 42, 71, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, c, a, sick := applyLazyDecay(tt.hunger, tt.condition, tt.affection, now.Add(-tt.elapsed), now)
+			if h != tt.wantHunger || c != tt.wantCond || a != tt.wantAff || sick != tt.wantSick {
+				t.Errorf("got hunger %d, condition %d, affection %d, sick %v; want %d, %d, %d, %v",
+					h, c, a, sick, tt.wantHunger, tt.wantCond, tt.wantAff, tt.wantSick)
+			}
+		})
+	}
+}
+
+func TestCheckLevelUp(t *testing.T) {
+	tests := []struct {
+		name          string
+		level, exp    int
+		wantLevel     int
+		wantExp       int
+		wantLeveledUp bool
+	}{
+		{"short of the next level", 1, 99, 1, 99, false},
+		{"exactly enough", 1, 100, 2, 0, true},
+		{"carries over the rest", 1, 150, 2, 50, true},
+		{"several levels at once", 1, 350, 3, 50, true},
+		{"higher levels need more", 10, 999, 10, 999, false},
+		{"reaches max level", 29, 2900, 30, 0, true},
+		{"overflow at max level is dropped", 29, 5000, 30, 0, true},
+		{"already at max level", 30, 500, 30, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, exp, up := checkLevelUp(tt.level, tt.exp)
+			if level != tt.wantLevel || exp != tt.wantExp || up != tt.wantLeveledUp {
+				t.Errorf("checkLevelUp(%d, %d) = %d, %d, %v, want %d, %d, %v",
+					tt.level, tt.exp, level, exp, up, tt.wantLevel, tt.wantExp, tt.wantLeveledUp)
+			}
+		})
+	}
+}
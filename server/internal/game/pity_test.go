@@ -0,0 +1,174 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+var pitySpecies = []models.SlimeSpecies{
+	{ID: 1, Element: "water", Grade: "common"},
+	{ID: 2, Element: "water", Grade: "rare"},
+	{ID: 3, Element: "water", Grade: "epic"},
+	{ID: 4, Element: "water", Grade: "legendary"},
+}
+
+func TestApplyPityGuarantee(t *testing.T) {
+	full := &repository.GameGachaBanner{PityRare: 10, PityEpic: 30, PityLegendary: 90}
+	epicOnly := &repository.GameGachaBanner{PityEpic: 50}
+	tests := []struct {
+		name   string
+		banner *repository.GameGachaBanner
+		count  int
+		want   string
+	}{
+		{"fresh counter", full, 0, ""},
+		{"one short of rare", full, 9, ""},
+		{"rare threshold", full, 10, "rare"},
+		{"between rare and epic", full, 29, "rare"},
+		{"epic threshold", full, 30, "epic"},
+		{"legendary threshold", full, 90, "legendary"},
+		{"past the last threshold", full, 200, "legendary"},
+		{"disabled steps are skipped", epicOnly, 10, ""},
+		{"single step", epicOnly, 50, "epic"},
+		{"no pity", &repository.GameGachaBanner{}, 1000, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyPityGuarantee(tt.banner, tt.count); got != tt.want {
+				t.Errorf("ApplyPityGuarantee(%d) = %q, want %q", tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldResetPity(t *testing.T) {
+	full := &repository.GameGachaBanner{PityRare: 10, PityEpic: 30, PityLegendary: 90}
+	epicOnly := &repository.GameGachaBanner{PityEpic: 50}
+	tests := []struct {
+		name   string
+		banner *repository.GameGachaBanner
+		grade  string
+		want   bool
+	}{
+		{"common keeps counting", full, "common", false},
+		{"uncommon keeps counting", full, "uncommon", false},
+		{"lowest step resets", full, "rare", true},
+		{"above lowest step resets", full, "mythic", true},
+		{"below single step", epicOnly, "rare", false},
+		{"single step", epicOnly, "epic", true},
+		{"no pity never resets", &repository.GameGachaBanner{}, "mythic", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldResetPity(tt.banner, tt.grade); got != tt.want {
+				t.Errorf("ShouldResetPity(%q) = %v, want %v", tt.grade, got, tt.want)
+			}
+		})
+	}
+}
+
+// Floors above common split evenly between rare, epic and legendary; scriptedRand's
+// zero draws pick the lowest grade the odds allow.
+func TestRollBatch(t *testing.T) {
+	commonOnly := map[string]float64{"common": 1}
+	tests := []struct {
+		name       string
+		banner     repository.GameGachaBanner
+		rng        Rand
+		pity, n    int
+		wantGrades []string
+		wantFloors []string
+		wantPity   int
+		wantReset  bool
+	}{
+		{
+			name:       "pity floor on the threshold pull",
+			banner:     repository.GameGachaBanner{GradeWeights: commonOnly, PityRare: 3},
+			rng:        &scriptedRand{},
+			pity:       2,
+			n:          1,
+			wantGrades: []string{"rare"},
+			wantFloors: []string{"rare"},
+			wantPity:   0,
+			wantReset:  true,
+		},
+		{
+			name:       "pity advances and resets pull by pull",
+			banner:     repository.GameGachaBanner{GradeWeights: commonOnly, PityRare: 4},
+			rng:        &scriptedRand{},
+			n:          10,
+			wantGrades: []string{"common", "common", "common", "rare", "common", "common", "common", "rare", "common", "common"},
+			wantFloors: []string{"", "", "", "rare", "", "", "", "rare", "", ""},
+			wantPity:   2,
+			wantReset:  true,
+		},
+		{
+			name:       "multi-pull guarantee on the last pull",
+			banner:     repository.GameGachaBanner{GradeWeights: commonOnly},
+			rng:        &scriptedRand{},
+			pity:       5,
+			n:          MultiPullSize,
+			wantGrades: []string{"common", "common", "common", "common", "common", "common", "common", "common", "common", "rare"},
+			wantFloors: []string{"", "", "", "", "", "", "", "", "", "rare"},
+			wantPity:   15,
+		},
+		{
+			name:   "multi-pull guarantee already met",
+			banner: repository.GameGachaBanner{GradeWeights: map[string]float64{"common": 1, "rare": 1}},
+			// 0.9 lands in the rare half of the odds, 0 in the common half
+			rng:        &scriptedRand{floats: []float64{0.9}},
+			n:          MultiPullSize,
+			wantGrades: []string{"rare", "common", "common", "common", "common", "common", "common", "common", "common", "common"},
+			wantFloors: []string{"", "", "", "", "", "", "", "", "", ""},
+			wantPity:   10,
+		},
+		{
+			name:       "short batches get no guarantee",
+			banner:     repository.GameGachaBanner{GradeWeights: commonOnly},
+			rng:        NewRand(1),
+			n:          3,
+			wantGrades: []string{"common", "common", "common"},
+			wantFloors: []string{"", "", ""},
+			wantPity:   3,
+		},
+		{
+			name:       "floor above the pool falls back to base odds",
+			banner:     repository.GameGachaBanner{GradeWeights: commonOnly, SpeciesPool: []int{1}, PityRare: 1},
+			rng:        NewRand(1),
+			n:          2,
+			wantGrades: []string{"common", "common"},
+			wantFloors: []string{"rare", "rare"},
+			wantPity:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pulls, pity, reset := rollBatch(tt.rng, &tt.banner, pitySpecies, false, tt.pity, tt.n)
+			var grades, floors []string
+			for _, p := range pulls {
+				grades = append(grades, p.Species.Grade)
+				floors = append(floors, p.Floor)
+			}
+			if !reflect.DeepEqual(grades, tt.wantGrades) {
+				t.Errorf("grades = %v, want %v", grades, tt.wantGrades)
+			}
+			if !reflect.DeepEqual(floors, tt.wantFloors) {
+				t.Errorf("floors = %v, want %v", floors, tt.wantFloors)
+			}
+			if pity != tt.wantPity || reset != tt.wantReset {
+				t.Errorf("pity, reset = %d, %v, want %d, %v", pity, reset, tt.wantPity, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestRollBatchEmptyPool(t *testing.T) {
+	b := &repository.GameGachaBanner{GradeWeights: map[string]float64{"common": 1}, Elements: []string{"fire"}}
+	pulls, _, _ := rollBatch(NewRand(1), b, pitySpecies, false, 0, MultiPullSize)
+	if len(pulls) != 0 {
+		t.Errorf("got %d pulls from an empty pool", len(pulls))
+	}
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// presenceOnlineWindow is how recent a heartbeat must be to count as online. Clients
+	// send one every minute while the game is open.
+	presenceOnlineWindow = 2 * time.Minute
+	// presenceTTL bounds how long "last seen" is remembered.
+	presenceTTL = 30 * 24 * time.Hour
+)
+
+// Presence is a player's online status as shown to friends.
+type Presence struct {
+	Online   bool       `json:"online"`
+	LastSeen *time.Time `json:"last_seen"`
+}
+
+func presenceKey(userID string) string {
+	return "presence:" + userID
+}
+
+// touchPresence records a heartbeat for userID (presence:<id> = unix seconds).
+func (h *Handler) touchPresence(ctx context.Context, userID string) error {
+	return h.rdb.Set(ctx, presenceKey(userID), time.Now().Unix(), presenceTTL).Err()
+}
+
+// lookupPresence returns the presence of each user in one round trip. Users never seen
+// (or whose record expired) get a zero Presence.
+func (h *Handler) lookupPresence(ctx context.Context, userIDs []string) map[string]Presence {
+	out := make(map[string]Presence, len(userIDs))
+	if len(userIDs) == 0 {
+		return out
+	}
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = presenceKey(id)
+	}
+	vals, err := h.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return out
+	}
+	now := time.Now()
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		ts, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		seen := time.Unix(ts, 0)
+		out[userIDs[i]] = Presence{Online: now.Sub(seen) < presenceOnlineWindow, LastSeen: &seen}
+	}
+	return out
+}
+
+// POST /api/presence/heartbeat
+func (h *Handler) Heartbeat(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	if err := h.touchPresence(c.Context(), userID); err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "presence unavailable"})
+	}
+	return c.JSON(fiber.Map{"ok": true, "interval_seconds": 60})
+}
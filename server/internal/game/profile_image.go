@@ -53,12 +53,7 @@ func (h *Handler) UploadProfileImage(c *fiber.Ctx) error {
 	}
 
 	// Update user's profile_image_url
-	pool := h.slimeRepo.Pool()
-	_, err = pool.Exec(c.UserContext(),
-		`UPDATE users SET profile_image_url = $1 WHERE id = $2`,
-		imageURL, userID,
-	)
-	if err != nil {
+	if err := h.userRepo.SetProfileImage(c.UserContext(), userID, imageURL); err != nil {
 		os.Remove(savePath)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to update profile"})
 	}
@@ -70,24 +65,15 @@ func (h *Handler) UploadProfileImage(c *fiber.Ctx) error {
 func (h *Handler) DeleteProfileImage(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
-	pool := h.slimeRepo.Pool()
-
 	// Get current image URL to delete the file
-	var imageURL string
-	err := pool.QueryRow(c.UserContext(),
-		`SELECT COALESCE(profile_image_url, '') FROM users WHERE id = $1`,
-		userID,
-	).Scan(&imageURL)
+	user, err := h.userRepo.FindByID(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
 	}
+	imageURL := user.ProfileImageURL
 
 	// Clear profile_image_url in DB
-	_, err = pool.Exec(c.UserContext(),
-		`UPDATE users SET profile_image_url = '' WHERE id = $1`,
-		userID,
-	)
-	if err != nil {
+	if err := h.userRepo.SetProfileImage(c.UserContext(), userID, ""); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to delete profile image"})
 	}
 
@@ -104,15 +90,10 @@ func (h *Handler) DeleteProfileImage(c *fiber.Ctx) error {
 func (h *Handler) GetProfileImage(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
-	pool := h.slimeRepo.Pool()
-	var imageURL string
-	err := pool.QueryRow(c.UserContext(),
-		`SELECT COALESCE(profile_image_url, '') FROM users WHERE id = $1`,
-		userID,
-	).Scan(&imageURL)
+	user, err := h.userRepo.FindByID(c.UserContext(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
 	}
 
-	return c.JSON(fiber.Map{"profile_image_url": imageURL})
+	return c.JSON(fiber.Map{"profile_image_url": user.ProfileImageURL})
 }
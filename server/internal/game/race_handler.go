@@ -1,14 +1,66 @@
 package game
 
 import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
 )
 
-// POST /api/race/start — request to start a race (unlimited)
+const raceTokenTTL = 10 * time.Minute
+
+// raceSession is what /race/start stores in Redis for the matching /race/finish.
+type raceSession struct {
+	UserID    string `json:"user_id"`
+	SlimeID   string `json:"slime_id"`
+	Seed      int64  `json:"seed"`
+	StartedAt int64  `json:"started_at"` // unix millis
+}
+
+func raceSessionKey(nonce string) string {
+	return "race_session:" + nonce
+}
+
+// signRaceToken builds "<nonce>.<expires>.<hmac>" so finish can reject forged or stale tokens
+// before touching Redis.
+func (h *Handler) signRaceToken(nonce string, expires int64) string {
+	mac := hmac.New(sha256.New, h.tokenSecret)
+	fmt.Fprintf(mac, "race:%s:%d", nonce, expires)
+	return fmt.Sprintf("%s.%d.%s", nonce, expires, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// verifyRaceToken checks signature and expiry, returning the session nonce.
+func (h *Handler) verifyRaceToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", errors.New("malformed token")
+	}
+	expected := h.signRaceToken(parts[0], expires)
+	if !hmac.Equal([]byte(expected), []byte(token)) {
+		return "", errors.New("invalid signature")
+	}
+	if time.Now().Unix() > expires {
+		return "", errors.New("token expired")
+	}
+	return parts[0], nil
+}
+
+// POST /api/race/start — issue a signed single-use race token and the track seed
 func (h *Handler) StartRace(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
@@ -30,37 +82,100 @@ func (h *Handler) StartRace(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not your slime"})
 	}
 
+	var seedBytes [8]byte
+	nonceBytes := make([]byte, 16)
+	if _, err := crand.Read(nonceBytes); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start race"})
+	}
+	if _, err := crand.Read(seedBytes[:]); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start race"})
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	// 53 bits, so the client reads the seed back exactly from JSON
+	seed := int64(binary.BigEndian.Uint64(seedBytes[:]) >> 11)
+
+	now := time.Now()
+	session, _ := json.Marshal(raceSession{
+		UserID:    userID,
+		SlimeID:   body.SlimeID,
+		Seed:      seed,
+		StartedAt: now.UnixMilli(),
+	})
+	if err := h.rdb.Set(ctx, raceSessionKey(nonce), session, raceTokenTTL).Err(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start race"})
+	}
+
+	effects := getSlimeSkillEffects(ctx, h.slimeRepo, body.SlimeID)
+
 	return c.JSON(fiber.Map{
-		"token":    fmt.Sprintf("race_%s_%d", userID[:8], time.Now().UnixMilli()),
-		"slime_id": body.SlimeID,
+		"token":      h.signRaceToken(nonce, now.Add(raceTokenTTL).Unix()),
+		"slime_id":   body.SlimeID,
+		"seed":       seed,
+		"tick_rate":  raceTickRate,
+		"max_ticks":  raceMaxTicks,
+		"stats":      raceStatsFor(*slime, effects),
+		"expires_in": int(raceTokenTTL.Seconds()),
 	})
 }
 
-// POST /api/race/finish — submit race score
+// POST /api/race/finish — replay the client's input log and score the race server-side
 func (h *Handler) FinishRace(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
 	var body struct {
-		SlimeID string `json:"slime_id"`
-		Score   int    `json:"score"`
-		Token   string `json:"token"`
+		Token  string      `json:"token"`
+		Inputs []RaceInput `json:"inputs"`
 	}
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	if err := c.BodyParser(&body); err != nil || body.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token and inputs required"})
 	}
-	if body.Score < 0 {
-		body.Score = 0
+
+	nonce, err := h.verifyRaceToken(body.Token)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid race token"})
+	}
+	if err := validateRaceInputs(body.Inputs); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx := c.Context()
+
+	// Only the owner may consume the session, so a leaked nonce can't cancel someone
+	// else's race. Deleting it is the single-use claim: of two finishes, only one gets 1.
+	key := raceSessionKey(nonce)
+	raw, err := h.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "race token already used or expired"})
+	}
+	var session raceSession
+	if err := json.Unmarshal(raw, &session); err != nil || session.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "race token does not belong to you"})
+	}
+	if n, err := h.rdb.Del(ctx, key).Result(); err != nil || n != 1 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "race token already used or expired"})
 	}
 
+	// Inputs cannot describe more running time than has actually elapsed (+2s slack)
+	elapsedTicks := (time.Now().UnixMilli() - session.StartedAt + 2000) * raceTickRate / 1000
+	if n := len(body.Inputs); n > 0 && int64(body.Inputs[n-1].Tick) > elapsedTicks {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "input log longer than elapsed time"})
+	}
+
+	slime, err := h.slimeRepo.FindByID(ctx, session.SlimeID)
+	if err != nil || uuidToString(slime.UserID) != userID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "slime not found"})
+	}
+
+	effects := getSlimeSkillEffects(ctx, h.slimeRepo, session.SlimeID)
+	sim := SimulateRace(session.Seed, raceStatsFor(*slime, effects), body.Inputs)
+
 	// Calculate rewards: base 50 + score/100 gold, score/20 exp (capped at 100exp)
-	goldReward := 50 + body.Score/100
-	expReward := body.Score / 20
+	goldReward := 50 + sim.Score/100
+	expReward := sim.Score / 20
 	if expReward > 100 {
 		expReward = 100
 	}
 
-	ctx := c.Context()
-
 	// Daily gold cap of 2000 via Redis
 	today := time.Now().Format("2006-01-02")
 	dailyKey := fmt.Sprintf("race_gold:%s:%s", userID, today)
@@ -73,16 +188,13 @@ func (h *Handler) FinishRace(c *fiber.Ctx) error {
 		goldReward = dailyRemaining
 	}
 
-	// Record result
-	h.slimeRepo.Pool().Exec(ctx,
-		`INSERT INTO race_results (user_id, slime_id, score, gold_reward, exp_reward)
-		 VALUES ($1, $2, $3, $4, $5)`,
-		userID, body.SlimeID, body.Score, goldReward, expReward,
-	)
+	// Record result with the replay so it can be re-verified later
+	inputsJSON, _ := json.Marshal(body.Inputs)
+	h.raceRepo.Record(ctx, userID, session.SlimeID, sim.Score, goldReward, expReward, session.Seed, inputsJSON, sim.Distance)
 
 	// Grant rewards
 	if goldReward > 0 {
-		h.userRepo.AddCurrency(ctx, userID, int64(goldReward), 0, 0)
+		h.userRepo.AddCurrency(ctx, userID, int64(goldReward), 0, 0, repository.ReasonRaceReward, session.SlimeID)
 		// Track daily gold in Redis (expire at end of day)
 		h.rdb.IncrBy(c.Context(), dailyKey, int64(goldReward))
 		ttl := time.Duration(24-time.Now().Hour())*time.Hour - time.Duration(time.Now().Minute())*time.Minute
@@ -91,12 +203,9 @@ func (h *Handler) FinishRace(c *fiber.Ctx) error {
 		}
 		h.rdb.Expire(c.Context(), dailyKey, ttl)
 	}
-	if expReward > 0 && body.SlimeID != "" {
-		slime, err := h.slimeRepo.FindByID(ctx, body.SlimeID)
-		if err == nil {
-			newLevel, newExp, _ := checkLevelUp(slime.Level, slime.Exp+expReward)
-			h.slimeRepo.SetLevelAndExp(ctx, body.SlimeID, newLevel, newExp)
-		}
+	if expReward > 0 {
+		newLevel, newExp, _ := checkLevelUp(slime.Level, slime.Exp+expReward)
+		h.slimeRepo.SetLevelAndExp(ctx, session.SlimeID, newLevel, newExp)
 	}
 
 	user, _ := h.userRepo.FindByID(ctx, userID)
@@ -105,10 +214,11 @@ func (h *Handler) FinishRace(c *fiber.Ctx) error {
 		dailyGoldRemaining = 0
 	}
 
-	log.Debug().Str("user_id", userID).Int("gold_reward", goldReward).Int("daily_remaining", dailyGoldRemaining).Msg("Race finished")
+	log.Debug().Str("user_id", userID).Int("score", sim.Score).Int("gold_reward", goldReward).Int("daily_remaining", dailyGoldRemaining).Msg("Race finished")
 
 	return c.JSON(fiber.Map{
-		"score":                body.Score,
+		"score":                sim.Score,
+		"result":               sim,
 		"gold_reward":          goldReward,
 		"exp_reward":           expReward,
 		"daily_gold_remaining": dailyGoldRemaining,
@@ -122,29 +232,18 @@ func (h *Handler) FinishRace(c *fiber.Ctx) error {
 func (h *Handler) GetRaceHistory(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
-	rows, err := h.slimeRepo.Pool().Query(c.UserContext(),
-		`SELECT score, gold_reward, exp_reward, played_at
-		 FROM race_results WHERE user_id = $1
-		 ORDER BY played_at DESC LIMIT 10`,
-		userID,
-	)
+	history, err := h.raceRepo.History(c.UserContext(), userID, 10)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch history"})
 	}
-	defer rows.Close()
 
-	results := make([]fiber.Map, 0)
-	for rows.Next() {
-		var score, goldR, expR int
-		var playedAt time.Time
-		if err := rows.Scan(&score, &goldR, &expR, &playedAt); err != nil {
-			continue
-		}
+	results := make([]fiber.Map, 0, len(history))
+	for _, r := range history {
 		results = append(results, fiber.Map{
-			"score":       score,
-			"gold_reward": goldR,
-			"exp_reward":  expR,
-			"played_at":   playedAt,
+			"score":       r.Score,
+			"gold_reward": r.GoldReward,
+			"exp_reward":  r.ExpReward,
+			"played_at":   r.PlayedAt,
 		})
 	}
 
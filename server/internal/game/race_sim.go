@@ -0,0 +1,294 @@
+package game
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/slimetopia/server/internal/models"
+)
+
+// ===== Server-side Race Simulation =====
+//
+// The race is a fixed-step side-scroller. The server generates the track from
+// the seed issued by /race/start, replays the client's input log against it and
+// computes the score itself — the client never reports a score.
+//
+// The client runs the same simulation to draw the race (client/lib/raceSim.ts), so
+// anything that changes the track, the physics or the score has to change there too.
+// shared/race-fixture.json pins both sides to the same tracks and replays.
+
+const (
+	raceTickRate        = 60                 // simulation ticks per second
+	raceMaxTicks        = raceTickRate * 180 // hard cap: 3 minutes of running
+	raceMaxInputs       = 4000
+	raceGravity         = 0.65
+	raceJumpForce       = 11.5
+	raceDoubleJumpForce = 10.0
+	raceBaseSpeed       = 3.5
+	raceAccelPerTick    = 0.06 / raceTickRate // client ramps +0.06 speed per second
+	raceBaseHP          = 3
+	raceInvincibleTicks = 90 // 1.5s after a hit
+	raceSlimeHalfWidth  = 12.0
+	raceObstacleHeight  = 30.0 // ground obstacles must be cleared by this jump height
+	raceHitPenalty      = 80
+	raceClearPoints     = 10
+	raceDistancePerPt   = 50.0
+	raceCritStream      = 0x5eed // keeps crit rolls independent of the track
+)
+
+// Obstacle kinds; ground ones are jumped over, aerial ones ducked under.
+var (
+	raceGroundKinds = []string{"rock", "cactus", "fire", "spike"}
+	raceAerialKinds = []string{"saw", "laser"}
+)
+
+var (
+	ErrRaceInputOrder = errors.New("race inputs must be in tick order")
+	ErrRaceInputCount = errors.New("too many race inputs")
+	ErrRaceInputTick  = errors.New("race input tick out of range")
+	ErrRaceAction     = errors.New("unknown race action")
+)
+
+// RaceInput is one entry of the client's replayable input log.
+type RaceInput struct {
+	Tick   int    `json:"tick"`
+	Action string `json:"action"` // "jump", "duck", "release"
+}
+
+// RaceStats are the slime-derived parameters the simulation runs with.
+type RaceStats struct {
+	Speed      float64 `json:"speed"`
+	JumpForce  float64 `json:"jump_force"`
+	HP         int     `json:"hp"`
+	CritChance float64 `json:"crit_chance"`
+}
+
+// RaceResult is the authoritative outcome of a simulated race.
+type RaceResult struct {
+	Score    int  `json:"score"`
+	Distance int  `json:"distance"`
+	Cleared  int  `json:"cleared"`
+	MaxCombo int  `json:"max_combo"`
+	Crits    int  `json:"crits"`
+	Hits     int  `json:"hits"`
+	Ticks    int  `json:"ticks"`
+	TimedOut bool `json:"timed_out"`
+}
+
+type raceObstacle struct {
+	X      float64 `json:"x"`
+	Width  float64 `json:"width"`
+	Aerial bool    `json:"aerial"` // aerial obstacles must be ducked under; ground ones jumped over
+	Kind   string  `json:"kind"`
+}
+
+// raceRand is mulberry32. Unlike math/rand it is a few lines of 32-bit arithmetic, so
+// the client reproduces it exactly.
+type raceRand struct {
+	state uint32
+}
+
+// newRaceRand seeds a stream from the race seed. Seeds fit in 53 bits so they survive
+// JSON as a JavaScript number.
+func newRaceRand(seed int64, stream uint32) *raceRand {
+	return &raceRand{state: uint32(seed) ^ uint32(seed>>32) ^ stream}
+}
+
+func (r *raceRand) next() uint32 {
+	r.state += 0x6d2b79f5
+	t := r.state
+	t = (t ^ t>>15) * (t | 1)
+	t ^= t + (t^t>>7)*(t|61)
+	return t ^ t>>14
+}
+
+// Intn returns a value in [0, n).
+func (r *raceRand) Intn(n int) int {
+	return int(r.next() % uint32(n))
+}
+
+// Float64 returns a value in [0, 1).
+func (r *raceRand) Float64() float64 {
+	return float64(r.next()) / (1 << 32)
+}
+
+// raceStatsFor derives race parameters from talents, level and learned skill effects.
+// Speed talent drives base speed, strength adds jump power and, at high values, an extra life.
+func raceStatsFor(s models.Slime, effects map[string]float64) RaceStats {
+	spd := s.TalentSpd + int(effects["all_stats"])
+	str := s.TalentStr + int(effects["all_stats"])
+
+	stats := RaceStats{
+		Speed:      raceBaseSpeed + float64(spd)*0.03 + float64(s.Level)*0.01,
+		JumpForce:  raceJumpForce + float64(str)*0.05,
+		HP:         raceBaseHP,
+		CritChance: effects["race_crit"],
+	}
+	if str >= 25 {
+		stats.HP++
+	}
+	stats.Speed *= 1 + effects["race_speed"]
+	return stats
+}
+
+// generateRaceTrack lays out obstacles deterministically from the seed.
+// The track is long enough that no run can outlast it within raceMaxTicks.
+func generateRaceTrack(seed int64) []raceObstacle {
+	rng := newRaceRand(seed, 0)
+	maxSpeed := (raceBaseSpeed + 31*0.03 + 100*0.01 + raceAccelPerTick*raceMaxTicks) * 2
+	trackEnd := maxSpeed * raceMaxTicks
+
+	var track []raceObstacle
+	x := 600.0
+	for x < trackEnd {
+		ob := raceObstacle{
+			X:      x,
+			Width:  20 + float64(rng.Intn(21)),
+			Aerial: rng.Float64() < 0.3,
+		}
+		kinds := raceGroundKinds
+		if ob.Aerial {
+			kinds = raceAerialKinds
+		}
+		ob.Kind = kinds[rng.Intn(len(kinds))]
+		track = append(track, ob)
+		x += 180 + float64(rng.Intn(220))
+	}
+	return track
+}
+
+// validateRaceInputs checks the input log is well-formed before replaying it.
+func validateRaceInputs(inputs []RaceInput) error {
+	if len(inputs) > raceMaxInputs {
+		return ErrRaceInputCount
+	}
+	last := -1
+	for _, in := range inputs {
+		if in.Tick < 0 || in.Tick >= raceMaxTicks {
+			return ErrRaceInputTick
+		}
+		if in.Tick < last {
+			return ErrRaceInputOrder
+		}
+		switch in.Action {
+		case "jump", "duck", "release":
+		default:
+			return ErrRaceAction
+		}
+		last = in.Tick
+	}
+	return nil
+}
+
+// SimulateRace replays an input log on the seeded track until the slime runs out of HP
+// or the time cap is reached. The same seed, stats and inputs always yield the same result.
+func SimulateRace(seed int64, stats RaceStats, inputs []RaceInput) RaceResult {
+	track := generateRaceTrack(seed)
+	critRng := newRaceRand(seed, raceCritStream)
+
+	sort.SliceStable(inputs, func(i, j int) bool { return inputs[i].Tick < inputs[j].Tick })
+
+	var (
+		res             RaceResult
+		distance        float64
+		y, vy           float64
+		jumpCount       int
+		ducking         bool
+		hp              = stats.HP
+		invincibleUntil = -1
+		combo           int
+		points          int
+		next            int // next obstacle not yet passed
+		inputIdx        int
+	)
+	hitObstacle := make(map[int]bool)
+
+	tick := 0
+	for ; tick < raceMaxTicks && hp > 0; tick++ {
+		// 1. Apply inputs for this tick
+		for inputIdx < len(inputs) && inputs[inputIdx].Tick == tick {
+			switch inputs[inputIdx].Action {
+			case "jump":
+				if jumpCount == 0 {
+					vy = -stats.JumpForce
+					jumpCount = 1
+					ducking = false
+				} else if jumpCount == 1 {
+					vy = -raceDoubleJumpForce
+					jumpCount = 2
+				}
+			case "duck":
+				if y == 0 {
+					ducking = true
+				}
+			case "release":
+				ducking = false
+			}
+			inputIdx++
+		}
+
+		// 2. Vertical physics
+		if y > 0 || vy < 0 {
+			vy += raceGravity
+			y -= vy
+			if y <= 0 {
+				y, vy, jumpCount = 0, 0, 0
+			}
+		}
+
+		// 3. Advance along the track. The conversion keeps the compiler from fusing the
+		// multiply-add, which JavaScript can't do.
+		distance += stats.Speed + float64(raceAccelPerTick*float64(tick))
+
+		// 4. Collisions and clears
+		for i := next; i < len(track); i++ {
+			ob := track[i]
+			if ob.X > distance+raceSlimeHalfWidth {
+				break
+			}
+			if ob.X+ob.Width < distance-raceSlimeHalfWidth {
+				// Passed this obstacle
+				if !hitObstacle[i] {
+					combo++
+					if combo > res.MaxCombo {
+						res.MaxCombo = combo
+					}
+					gain := raceClearPoints * (1 + combo/10)
+					if stats.CritChance > 0 && critRng.Float64() < stats.CritChance {
+						gain *= 2
+						res.Crits++
+					}
+					points += gain
+					res.Cleared++
+				}
+				next = i + 1
+				continue
+			}
+			if hitObstacle[i] {
+				continue
+			}
+			collides := (!ob.Aerial && y < raceObstacleHeight) || (ob.Aerial && !(ducking && y == 0))
+			if !collides {
+				continue
+			}
+			hitObstacle[i] = true
+			if tick >= invincibleUntil {
+				hp--
+				res.Hits++
+				combo = 0
+				penalty := raceHitPenalty
+				if points < penalty {
+					penalty = points
+				}
+				points -= penalty
+				invincibleUntil = tick + raceInvincibleTicks
+			}
+		}
+	}
+
+	res.Ticks = tick
+	res.TimedOut = hp > 0
+	res.Distance = int(distance)
+	res.Score = points + int(distance/raceDistancePerPt)
+	return res
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateRaceFixture = flag.Bool("update", false, "rewrite shared/race-fixture.json")
+
+// raceFixturePath is shared with the client, whose own test (client/lib/raceSim.test.ts)
+// checks its port of the simulation against the same file.
+var raceFixturePath = filepath.Join("..", "..", "..", "shared", "race-fixture.json")
+
+// raceFixtureObstacles is how much of each track the fixture pins.
+const raceFixtureObstacles = 60
+
+type raceFixture struct {
+	Tracks  []raceTrackCase  `json:"tracks"`
+	Replays []raceReplayCase `json:"replays"`
+}
+
+type raceTrackCase struct {
+	Seed      int64          `json:"seed"`
+	Obstacles []raceObstacle `json:"obstacles"`
+}
+
+type raceReplayCase struct {
+	Seed   int64       `json:"seed"`
+	Stats  RaceStats   `json:"stats"`
+	Inputs []RaceInput `json:"inputs"`
+	Result RaceResult  `json:"result"`
+}
+
+// raceFixtureInputs plays a seeded track like a decent player: it jumps ground obstacles
+// and ducks aerial ones as they come up, but lets every thirteenth one through so the replay
+// has hits as well as clears, combos and crits.
+func raceFixtureInputs(seed int64, stats RaceStats, ticks int) []RaceInput {
+	track := generateRaceTrack(seed)
+	var inputs []RaceInput
+	var distance float64
+	airborneUntil, ducking := -1, false
+	next, acted := 0, false
+	for tick := 0; tick < ticks && next < len(track); tick++ {
+		speed := stats.Speed + raceAccelPerTick*float64(tick)
+		ob := track[next]
+		if ob.X+ob.Width < distance-raceSlimeHalfWidth {
+			if ducking {
+				inputs = append(inputs, RaceInput{Tick: tick, Action: "release"})
+				ducking = false
+			}
+			next, acted = next+1, false
+		} else if !acted && next%13 != 12 {
+			gap := ob.X - (distance + raceSlimeHalfWidth)
+			switch {
+			case !ob.Aerial && gap <= 3*speed && tick > airborneUntil:
+				inputs = append(inputs, RaceInput{Tick: tick, Action: "jump"})
+				airborneUntil, acted = tick+36, true
+			case ob.Aerial && gap <= 2*speed && tick > airborneUntil:
+				inputs = append(inputs, RaceInput{Tick: tick, Action: "duck"})
+				ducking, acted = true, true
+			}
+		}
+		distance += speed
+	}
+	return inputs
+}
+
+func buildRaceFixture() raceFixture {
+	var f raceFixture
+	for _, seed := range []int64{0, 1, 42, 123456789012345, 1<<53 - 1} {
+		f.Tracks = append(f.Tracks, raceTrackCase{Seed: seed, Obstacles: generateRaceTrack(seed)[:raceFixtureObstacles]})
+	}
+	replays := []struct {
+		seed  int64
+		stats RaceStats
+		ticks int
+	}{
+		{seed: 7, stats: RaceStats{Speed: 3.8, JumpForce: 11.5, HP: 3}, ticks: 3000},
+		{seed: 987654321, stats: RaceStats{Speed: 4.63, JumpForce: 12.75, HP: 4, CritChance: 0.3}, ticks: 6000},
+		{seed: 1<<53 - 1, stats: RaceStats{Speed: 5.2, JumpForce: 13, HP: 4, CritChance: 0.1}, ticks: 4000},
+	}
+	for _, r := range replays {
+		inputs := raceFixtureInputs(r.seed, r.stats, r.ticks)
+		f.Replays = append(f.Replays, raceReplayCase{
+			Seed:   r.seed,
+			Stats:  r.stats,
+			Inputs: inputs,
+			Result: SimulateRace(r.seed, r.stats, inputs),
+		})
+	}
+	return f
+}
+
+// TestRaceFixture pins the track layout and the simulation the client replays: a diff
+// here means client and server no longer agree on a race. Run with -update to accept a
+// change, then make the same change in client/lib/raceSim.ts.
+func TestRaceFixture(t *testing.T) {
+	got, err := json.MarshalIndent(buildRaceFixture(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	if *updateRaceFixture {
+		if err := os.WriteFile(raceFixturePath, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(raceFixturePath)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("simulation no longer matches %s; rerun with -update and port the change to the client", raceFixturePath)
+	}
+}
+
+func TestRaceRandRange(t *testing.T) {
+	rng := newRaceRand(1<<53-1, 0)
+	for i := 0; i < 10000; i++ {
+		if f := rng.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("Float64() = %v, want [0, 1)", f)
+		}
+		if n := rng.Intn(7); n < 0 || n >= 7 {
+			t.Fatalf("Intn(7) = %d", n)
+		}
+	}
+}
+
+func TestSimulateRaceDeterministic(t *testing.T) {
+	stats := RaceStats{Speed: 4, JumpForce: 12, HP: 3, CritChance: 0.5}
+	inputs := raceFixtureInputs(99, stats, 4000)
+	a := SimulateRace(99, stats, inputs)
+	b := SimulateRace(99, stats, inputs)
+	if a != b {
+		t.Fatalf("same seed and inputs gave %+v and %+v", a, b)
+	}
+	if a.Cleared == 0 || a.Hits == 0 {
+		t.Errorf("fixture rhythm should both clear and hit obstacles: %+v", a)
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"time"
+)
+
+// Rand is the randomness game rules draw from. *rand.Rand satisfies it, so tests and
+// simulations can run the same rules on a seeded source.
+type Rand interface {
+	Float64() float64
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
+
+// NewRand returns a Rand that replays the same sequence for the same seed.
+func NewRand(seed int64) Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// Seeder picks the seed of each request's Rand.
+type Seeder interface {
+	Seed() int64
+}
+
+// cryptoSeeder draws seeds from crypto/rand, like race seeds, falling back to the clock.
+type cryptoSeeder struct{}
+
+func (cryptoSeeder) Seed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
+
+// FixedSeeder always hands out the same seed.
+type FixedSeeder int64
+
+func (s FixedSeeder) Seed() int64 { return int64(s) }
+
+// newRand starts a request's Rand. The seed goes into the request's game log
+// ("seed" in game_logs.detail) so the outcome can be replayed.
+func (h *Handler) newRand() (Rand, int64) {
+	seed := h.seeds.Seed()
+	return NewRand(seed), seed
+}
+
+// SetSeeder replaces the source of request seeds.
+func (h *Handler) SetSeeder(s Seeder) {
+	h.seeds = s
+}
+
+// rollBetween returns a uniform int in [lo, hi].
+func rollBetween(rng Rand, lo, hi int) int {
+	return lo + rng.Intn(hi-lo+1)
+}
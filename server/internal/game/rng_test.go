@@ -0,0 +1,85 @@
+package game
+
+import "testing"
+
+// scriptedRand replays fixed draws so a test can steer every roll. Float64 and Intn
+// read separate scripts; draws past the end of a script return 0.
+type scriptedRand struct {
+	floats []float64
+	ints   []int
+}
+
+func (r *scriptedRand) Float64() float64 {
+	if len(r.floats) == 0 {
+		return 0
+	}
+	f := r.floats[0]
+	r.floats = r.floats[1:]
+	return f
+}
+
+func (r *scriptedRand) Intn(n int) int {
+	if len(r.ints) == 0 {
+		return 0
+	}
+	i := r.ints[0] % n
+	r.ints = r.ints[1:]
+	return i
+}
+
+func (r *scriptedRand) Shuffle(n int, swap func(i, j int)) {}
+
+func repeatFloat(f float64, n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = f
+	}
+	return s
+}
+
+func repeatInt(v, n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+func TestRequestRandReplaysSeed(t *testing.T) {
+	h := &Handler{seeds: FixedSeeder(7)}
+	a, seedA := h.newRand()
+	b, seedB := h.newRand()
+	if seedA != 7 || seedB != 7 {
+		t.Fatalf("seeds = %d, %d, want 7", seedA, seedB)
+	}
+	for i := 0; i < 100; i++ {
+		if x, y := a.Intn(1000), b.Intn(1000); x != y {
+			t.Fatalf("draw %d: %d != %d", i, x, y)
+		}
+	}
+}
+
+func TestCryptoSeederIsNonNegative(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if s := (cryptoSeeder{}).Seed(); s < 0 {
+			t.Fatalf("Seed() = %d", s)
+		}
+	}
+}
+
+func TestRollBetween(t *testing.T) {
+	tests := []struct {
+		lo, hi, draw, want int
+	}{
+		{10, 50, 0, 10},
+		{10, 50, 40, 50},
+		{1, 3, 1, 2},
+		{5, 5, 0, 5},
+	}
+	for _, tt := range tests {
+		rng := &scriptedRand{ints: []int{tt.draw}}
+		if got := rollBetween(rng, tt.lo, tt.hi); got != tt.want {
+			t.Errorf("rollBetween(%d, %d) with draw %d = %d, want %d", tt.lo, tt.hi, tt.draw, got, tt.want)
+		}
+	}
+}
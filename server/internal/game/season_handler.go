@@ -1,10 +1,13 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 )
 
 type SeasonShopItem struct {
@@ -78,3 +81,17 @@ func (h *Handler) GetActiveSeason(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// RolloverSeasons closes seasons that have ended. A season an admin scheduled ahead
+// of time with is_active set opens on its start date by itself, so only the closing
+// side needs a job.
+func (h *Handler) RolloverSeasons(ctx context.Context) error {
+	closed, err := h.gameDataRepo.CloseEndedSeasons(ctx)
+	if err != nil {
+		return fmt.Errorf("close ended seasons: %w", err)
+	}
+	if closed > 0 {
+		log.Info().Int64("closed", closed).Msg("[Season] ended seasons closed")
+	}
+	return nil
+}
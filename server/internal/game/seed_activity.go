@@ -3,15 +3,16 @@ package game
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// actBotEmailLike matches the email of every activity bot.
+const actBotEmailLike = "actbot%@slimetopia.bot"
+
 // ── 30 unique Korean nicknames (distinct from botNicknames in bot_seed.go) ──
 
 var actBotNicknames = []string{
@@ -398,13 +399,9 @@ func pickSecondaryReplies(postType string) []string {
 // SeedActivityBots creates 30 activity bots with rich community activity.
 func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Idempotency: check if activity bots already exist
-	var actBotCount int
-	err := pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM users WHERE email LIKE 'actbot%@slimetopia.bot'`,
-	).Scan(&actBotCount)
+	actBotCount, err := h.botRepo.Count(ctx, actBotEmailLike)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check existing activity bots"})
 	}
@@ -439,6 +436,8 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 		speciesElementMap[sp.ID] = sp.Element
 	}
 
+	rng, seed := h.newRand()
+
 	// Counters
 	createdBots := 0
 	totalSlimes := 0
@@ -473,32 +472,20 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 		nickname := actBotNicknames[i]
 
 		// Skip if already exists
-		var exists bool
-		pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, email).Scan(&exists)
-		if exists {
-			var uid string
-			pool.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, email).Scan(&uid)
-			if uid != "" {
-				botUserIDs = append(botUserIDs, uid)
-			}
+		if uid, _ := h.botRepo.ID(ctx, email); uid != "" {
+			botUserIDs = append(botUserIDs, uid)
 			continue
 		}
 
 		tier := getActBotTier(i)
 
-		gold := randBetween(tier.minGold, tier.maxGold)
-		gems := randBetween(tier.minGems, tier.maxGems)
-		level := randBetween(tier.minLevel, tier.maxLevel)
-		stardust := randBetween(0, level*10)
-
-		var userID string
-		err := pool.QueryRow(ctx,
-			`INSERT INTO users (nickname, provider, provider_id, gold, gems, stardust, level, email, password_hash)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-			 RETURNING id`,
-			nickname, "bot", fmt.Sprintf("actbot_%d", i+1),
-			int64(gold), gems, stardust, level, email, string(passwordHash),
-		).Scan(&userID)
+		gold := randBetween(rng, tier.minGold, tier.maxGold)
+		gems := randBetween(rng, tier.minGems, tier.maxGems)
+		level := randBetween(rng, tier.minLevel, tier.maxLevel)
+		stardust := randBetween(rng, 0, level*10)
+
+		userID, err := h.botRepo.Create(ctx, nickname, fmt.Sprintf("actbot_%d", i+1), email, string(passwordHash),
+			level, int64(gold), gems, stardust)
 		if err != nil {
 			log.Error().Err(err).Str("nickname", nickname).Msg("Failed to create activity bot user")
 			continue
@@ -507,55 +494,38 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 		createdBots++
 
 		// Village
-		seedBotVillage(ctx, pool, userID, nickname)
+		h.seedBotVillage(ctx, rng, userID, nickname)
 
 		// Slimes (3-10 based on tier)
-		slimeCount := randBetween(tier.minSlimes, tier.maxSlimes)
+		slimeCount := randBetween(rng, tier.minSlimes, tier.maxSlimes)
 		var createdSlimeIDs []string
 		for j := 0; j < slimeCount; j++ {
-			speciesID, element := pickBotSpecies(tier.botTier, speciesByGrade, speciesElementMap)
-			personality := allBotPersonalities[rand.Intn(len(allBotPersonalities))]
-			slimeLevel := randBetween(1, level)
+			speciesID, element := pickBotSpecies(rng, tier.botTier, speciesByGrade, speciesElementMap)
+			personality := allBotPersonalities[rng.Intn(len(allBotPersonalities))]
+			slimeLevel := randBetween(rng, 1, level)
 			if slimeLevel < 1 {
 				slimeLevel = 1
 			}
 
-			var slimeID string
-			err := pool.QueryRow(ctx,
-				`INSERT INTO slimes (user_id, species_id, element, personality, level, exp, affection, hunger, condition)
-				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-				 RETURNING id`,
-				userID, speciesID, element, personality,
-				slimeLevel, rand.Intn(50), randBetween(20, 90), randBetween(30, 100), randBetween(40, 100),
-			).Scan(&slimeID)
+			slimeID, err := h.botRepo.CreateSlime(ctx, userID, speciesID, element, personality,
+				slimeLevel, rng.Intn(50), randBetween(rng, 20, 90), randBetween(rng, 30, 100), randBetween(rng, 40, 100))
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to create activity bot slime")
 				continue
 			}
 			createdSlimeIDs = append(createdSlimeIDs, slimeID)
 			totalSlimes++
-
-			pool.Exec(ctx,
-				`INSERT INTO codex_entries (user_id, species_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-				userID, speciesID,
-			)
 		}
 
 		// Community posts (5-15 based on tier)
-		postCount := randBetween(tier.minPosts, tier.maxPosts)
+		postCount := randBetween(rng, tier.minPosts, tier.maxPosts)
 		for j := 0; j < postCount; j++ {
-			post := actPostContents[rand.Intn(len(actPostContents))]
-			createdAt := time.Now().Add(-time.Duration(rand.Intn(60*24)) * time.Hour) // 60 days
-			likes := randBetween(0, 50)
-			viewCount := likes + randBetween(5, 100)
-
-			var postID string
-			err := pool.QueryRow(ctx,
-				`INSERT INTO community_posts (user_id, content, post_type, likes, view_count, created_at)
-				 VALUES ($1, $2, $3, $4, $5, $6)
-				 RETURNING id`,
-				userID, post.Content, post.PostType, likes, viewCount, createdAt,
-			).Scan(&postID)
+			post := actPostContents[rng.Intn(len(actPostContents))]
+			createdAt := time.Now().Add(-time.Duration(rng.Intn(60*24)) * time.Hour) // 60 days
+			likes := randBetween(rng, 0, 50)
+			viewCount := likes + randBetween(rng, 5, 100)
+
+			postID, err := h.botRepo.CreatePost(ctx, userID, post.Content, post.PostType, likes, viewCount, createdAt)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to create activity bot post")
 				continue
@@ -565,38 +535,29 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 		}
 
 		// Collection entries
-		collectionCount := randBetween(tier.minCollection, tier.maxCollection)
+		collectionCount := randBetween(rng, tier.minCollection, tier.maxCollection)
 		submittedPairs := make(map[string]bool)
 		for j := 0; j < collectionCount; j++ {
-			speciesID, _ := pickBotSpecies(tier.botTier, speciesByGrade, speciesElementMap)
-			personality := allBotPersonalities[rand.Intn(len(allBotPersonalities))]
+			speciesID, _ := pickBotSpecies(rng, tier.botTier, speciesByGrade, speciesElementMap)
+			personality := allBotPersonalities[rng.Intn(len(allBotPersonalities))]
 			pairKey := fmt.Sprintf("%d_%s", speciesID, personality)
 			if submittedPairs[pairKey] {
 				continue
 			}
 			submittedPairs[pairKey] = true
-			_, err := pool.Exec(ctx,
-				`INSERT INTO collection_entries (user_id, species_id, personality) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
-				userID, speciesID, personality,
-			)
-			if err == nil {
+			if h.botRepo.AddCollectionEntry(ctx, userID, speciesID, personality) == nil {
 				totalCollections++
 			}
 		}
 
 		// Race results
-		if rand.Float64() < 0.6 && len(createdSlimeIDs) > 0 {
-			raceCount := randBetween(1, 5)
+		if rng.Float64() < 0.6 && len(createdSlimeIDs) > 0 {
+			raceCount := randBetween(rng, 1, 5)
 			for r := 0; r < raceCount; r++ {
-				score := randBetween(50, 300+level*10)
-				slimeID := createdSlimeIDs[rand.Intn(len(createdSlimeIDs))]
-				playedAt := time.Now().Add(-time.Duration(rand.Intn(14*24)) * time.Hour)
-				_, err := pool.Exec(ctx,
-					`INSERT INTO race_results (user_id, slime_id, score, gold_reward, exp_reward, played_at)
-					 VALUES ($1, $2, $3, $4, $5, $6)`,
-					userID, slimeID, score, randBetween(10, 100), randBetween(5, 30), playedAt,
-				)
-				if err == nil {
+				score := randBetween(rng, 50, 300+level*10)
+				slimeID := createdSlimeIDs[rng.Intn(len(createdSlimeIDs))]
+				playedAt := time.Now().Add(-time.Duration(rng.Intn(14*24)) * time.Hour)
+				if h.botRepo.AddRaceResult(ctx, userID, slimeID, score, randBetween(rng, 10, 100), randBetween(rng, 5, 30), playedAt) == nil {
 					totalRaceResults++
 				}
 			}
@@ -618,7 +579,7 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 	if len(botUserIDs) > 1 && len(allPosts) > 0 {
 		// For each post, generate 2-8 replies
 		for _, post := range allPosts {
-			replyCount := randBetween(2, 8)
+			replyCount := randBetween(rng, 2, 8)
 			pool := pickRepliesForPostType(post.postType)
 			usedBots := map[string]bool{post.authorID: true} // prevent self-reply
 
@@ -626,7 +587,7 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 				// Pick a bot that hasn't commented on this post yet
 				var replyUserID string
 				for attempts := 0; attempts < 10; attempts++ {
-					candidate := botUserIDs[rand.Intn(len(botUserIDs))]
+					candidate := botUserIDs[rng.Intn(len(botUserIDs))]
 					if !usedBots[candidate] {
 						replyUserID = candidate
 						break
@@ -637,20 +598,14 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 				}
 				usedBots[replyUserID] = true
 
-				content := pool[rand.Intn(len(pool))]
+				content := pool[rng.Intn(len(pool))]
 				// Reply time: post time + 1h ~ 48h
-				replyAt := post.created.Add(time.Duration(randBetween(1, 48)) * time.Hour)
+				replyAt := post.created.Add(time.Duration(randBetween(rng, 1, 48)) * time.Hour)
 				if replyAt.After(time.Now()) {
-					replyAt = time.Now().Add(-time.Duration(rand.Intn(24)) * time.Hour)
+					replyAt = time.Now().Add(-time.Duration(rng.Intn(24)) * time.Hour)
 				}
 
-				var replyID string
-				err := h.slimeRepo.Pool().QueryRow(ctx,
-					`INSERT INTO community_replies (post_id, user_id, content, created_at)
-					 VALUES ($1, $2, $3, $4)
-					 RETURNING id`,
-					post.id, replyUserID, content, replyAt,
-				).Scan(&replyID)
+				replyID, err := h.botRepo.CreateReply(ctx, post.id, replyUserID, content, "", replyAt)
 				if err == nil {
 					allReplies = append(allReplies, replyInfo{id: replyID, postID: post.id, userID: replyUserID, time: replyAt})
 					totalReplies++
@@ -660,7 +615,7 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 
 		// Nested replies (parent_id): ~30% of replies get 1-2 sub-replies
 		for _, reply := range allReplies {
-			if rand.Float64() > 0.3 {
+			if rng.Float64() > 0.3 {
 				continue
 			}
 			// Find the post type for this reply
@@ -672,44 +627,23 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 				}
 			}
 			secondaryPool := pickSecondaryReplies(postType)
-			subCount := randBetween(1, 2)
+			subCount := randBetween(rng, 1, 2)
 			for s := 0; s < subCount; s++ {
-				subUserID := botUserIDs[rand.Intn(len(botUserIDs))]
+				subUserID := botUserIDs[rng.Intn(len(botUserIDs))]
 				if subUserID == reply.userID {
 					continue // avoid self-reply chain
 				}
-				content := secondaryPool[rand.Intn(len(secondaryPool))]
-				subTime := reply.time.Add(time.Duration(randBetween(1, 24)) * time.Hour)
+				content := secondaryPool[rng.Intn(len(secondaryPool))]
+				subTime := reply.time.Add(time.Duration(randBetween(rng, 1, 24)) * time.Hour)
 				if subTime.After(time.Now()) {
-					subTime = time.Now().Add(-time.Duration(rand.Intn(12)) * time.Hour)
+					subTime = time.Now().Add(-time.Duration(rng.Intn(12)) * time.Hour)
 				}
 
-				_, err := h.slimeRepo.Pool().Exec(ctx,
-					`INSERT INTO community_replies (post_id, user_id, content, parent_id, created_at)
-					 VALUES ($1, $2, $3, $4, $5)`,
-					reply.postID, subUserID, content, reply.id, subTime,
-				)
-				if err == nil {
+				if _, err := h.botRepo.CreateReply(ctx, reply.postID, subUserID, content, reply.id, subTime); err == nil {
 					totalNestedReplies++
 				}
 			}
 		}
-
-		// Update reply_count on all posts
-		for _, post := range allPosts {
-			h.slimeRepo.Pool().Exec(ctx,
-				`UPDATE community_posts SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = $1) WHERE id = $1`,
-				post.id,
-			)
-		}
-
-		// Update reply_count on parent replies
-		for _, reply := range allReplies {
-			h.slimeRepo.Pool().Exec(ctx,
-				`UPDATE community_replies SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE parent_id = $1) WHERE id = $1`,
-				reply.id,
-			)
-		}
 	}
 
 	// ════════════════════════════════════════════════════════
@@ -719,59 +653,50 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 	if len(botUserIDs) > 1 {
 		// Post likes: each post gets 5-20 likes
 		for _, post := range allPosts {
-			likeCount := randBetween(5, 20)
+			likeCount := randBetween(rng, 5, 20)
 			shuffled := make([]string, len(botUserIDs))
 			copy(shuffled, botUserIDs)
-			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
 			if likeCount > len(shuffled) {
 				likeCount = len(shuffled)
 			}
 			for _, likerID := range shuffled[:likeCount] {
-				_, err := h.slimeRepo.Pool().Exec(ctx,
-					`INSERT INTO community_post_likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-					post.id, likerID,
-				)
-				if err == nil {
+				if h.botRepo.LikePost(ctx, post.id, likerID) == nil {
 					totalPostLikes++
 				}
 			}
 		}
 
-		// Update like counts on posts
-		for _, post := range allPosts {
-			h.slimeRepo.Pool().Exec(ctx,
-				`UPDATE community_posts SET likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = $1) WHERE id = $1`,
-				post.id,
-			)
-		}
-
 		// Reply likes: ~50% of replies get 1-10 likes
 		for _, reply := range allReplies {
-			if rand.Float64() > 0.5 {
+			if rng.Float64() > 0.5 {
 				continue
 			}
-			likeCount := randBetween(1, 10)
+			likeCount := randBetween(rng, 1, 10)
 			shuffled := make([]string, len(botUserIDs))
 			copy(shuffled, botUserIDs)
-			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
 			if likeCount > len(shuffled) {
 				likeCount = len(shuffled)
 			}
 			for _, likerID := range shuffled[:likeCount] {
-				_, err := h.slimeRepo.Pool().Exec(ctx,
-					`INSERT INTO community_reply_likes (reply_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-					reply.id, likerID,
-				)
-				if err == nil {
+				if h.botRepo.LikeReply(ctx, reply.id, likerID) == nil {
 					totalReplyLikes++
 				}
 			}
-			// Update reply likes count
-			h.slimeRepo.Pool().Exec(ctx,
-				`UPDATE community_replies SET likes = (SELECT COUNT(*) FROM community_reply_likes WHERE reply_id = $1) WHERE id = $1`,
-				reply.id,
-			)
 		}
+
+		// Update reply and like counts on posts and replies
+		postIDs := make([]string, len(allPosts))
+		for i, post := range allPosts {
+			postIDs[i] = post.id
+		}
+		replyIDs := make([]string, len(allReplies))
+		for i, reply := range allReplies {
+			replyIDs[i] = reply.id
+		}
+		h.botRepo.RecountPosts(ctx, postIDs)
+		h.botRepo.RecountReplies(ctx, replyIDs)
 	}
 
 	// ════════════════════════════════════════════════════════
@@ -808,7 +733,7 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 		// Each bot creates 1-3 shorts
 		shortIdx := 0
 		for _, botUID := range botUserIDs {
-			shortsCount := randBetween(1, 3)
+			shortsCount := randBetween(rng, 1, 3)
 			for s := 0; s < shortsCount; s++ {
 				if shortIdx >= len(allShortsPool) {
 					shortIdx = 0 // wrap around
@@ -820,17 +745,11 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 				if tags == nil {
 					tags = []string{}
 				}
-				createdAt := time.Now().Add(-time.Duration(rand.Intn(30*24)) * time.Hour)
-				views := randBetween(10, 500)
-				likes := randBetween(1, 50)
-
-				var shortID string
-				err := h.slimeRepo.Pool().QueryRow(ctx,
-					`INSERT INTO shorts (user_id, title, description, video_url, thumbnail_url, tags, category, visibility, views, likes, comment_count, status, created_at)
-					 VALUES ($1, $2, $3, $4, '', $5, $6, 'public', $7, $8, 0, 'active', $9)
-					 RETURNING id`,
-					botUID, sc.Title, sc.Description, "", tags, sc.Category, views, likes, createdAt,
-				).Scan(&shortID)
+				createdAt := time.Now().Add(-time.Duration(rng.Intn(30*24)) * time.Hour)
+				views := randBetween(rng, 10, 500)
+				likes := randBetween(rng, 1, 50)
+
+				shortID, err := h.botRepo.CreateShort(ctx, botUID, sc.Title, sc.Description, tags, sc.Category, views, likes, createdAt)
 				if err != nil {
 					log.Error().Err(err).Msg("Failed to create activity bot short")
 					continue
@@ -842,12 +761,12 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 
 		// Shorts comments: each short gets 3-10 comments
 		for _, shortID := range allShortIDs {
-			commentCount := randBetween(3, 10)
+			commentCount := randBetween(rng, 3, 10)
 			usedBots := map[string]bool{}
 			for c := 0; c < commentCount; c++ {
 				var commentUserID string
 				for attempts := 0; attempts < 10; attempts++ {
-					candidate := botUserIDs[rand.Intn(len(botUserIDs))]
+					candidate := botUserIDs[rng.Intn(len(botUserIDs))]
 					if !usedBots[candidate] {
 						commentUserID = candidate
 						break
@@ -855,61 +774,42 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 				}
 				if commentUserID == "" {
 					// Allow duplicate if all bots used
-					commentUserID = botUserIDs[rand.Intn(len(botUserIDs))]
+					commentUserID = botUserIDs[rng.Intn(len(botUserIDs))]
 				}
 				usedBots[commentUserID] = true
 
-				content := actShortsComments[rand.Intn(len(actShortsComments))]
-				createdAt := time.Now().Add(-time.Duration(rand.Intn(29*24)) * time.Hour)
+				content := actShortsComments[rng.Intn(len(actShortsComments))]
+				createdAt := time.Now().Add(-time.Duration(rng.Intn(29*24)) * time.Hour)
 
-				_, err := h.slimeRepo.Pool().Exec(ctx,
-					`INSERT INTO shorts_comments (short_id, user_id, content, created_at)
-					 VALUES ($1, $2, $3, $4)`,
-					shortID, commentUserID, content, createdAt,
-				)
-				if err == nil {
+				if h.botRepo.CommentShort(ctx, shortID, commentUserID, content, createdAt) == nil {
 					totalShortsComments++
 				}
 			}
-
-			// Update comment_count
-			h.slimeRepo.Pool().Exec(ctx,
-				`UPDATE shorts SET comment_count = (SELECT COUNT(*) FROM shorts_comments WHERE short_id = $1) WHERE id = $1`,
-				shortID,
-			)
 		}
 
 		// Shorts likes: each short gets 5-20 likes
 		for _, shortID := range allShortIDs {
-			likeCount := randBetween(5, 20)
+			likeCount := randBetween(rng, 5, 20)
 			shuffled := make([]string, len(botUserIDs))
 			copy(shuffled, botUserIDs)
-			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
 			if likeCount > len(shuffled) {
 				likeCount = len(shuffled)
 			}
 			for _, likerID := range shuffled[:likeCount] {
-				_, err := h.slimeRepo.Pool().Exec(ctx,
-					`INSERT INTO shorts_likes (short_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-					shortID, likerID,
-				)
-				if err == nil {
+				if h.botRepo.LikeShort(ctx, shortID, likerID) == nil {
 					totalShortsLikes++
 				}
 			}
-
-			// Update like count
-			h.slimeRepo.Pool().Exec(ctx,
-				`UPDATE shorts SET likes = (SELECT COUNT(*) FROM shorts_likes WHERE short_id = $1) WHERE id = $1`,
-				shortID,
-			)
 		}
 	}
 
 	// Shorts comment likes
 	if len(allShortIDs) > 0 && len(botUserIDs) > 1 {
-		seedActivityShortCommentLikes(ctx, h.slimeRepo.Pool(), allShortIDs, botUserIDs)
+		h.seedActivityShortCommentLikes(ctx, rng, allShortIDs, botUserIDs)
 	}
+	// Update comment and like counts on shorts and their comments
+	h.botRepo.RecountShorts(ctx, allShortIDs)
 
 	// ════════════════════════════════════════════════════════
 	// Done
@@ -928,6 +828,7 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 		Int("shorts", totalShorts).
 		Int("shorts_comments", totalShortsComments).
 		Int("shorts_likes", totalShortsLikes).
+		Int64("seed", seed).
 		Msg("Activity bot seeding completed")
 
 	return c.JSON(fiber.Map{
@@ -950,16 +851,12 @@ func (h *Handler) SeedActivityBots(c *fiber.Ctx) error {
 // DeleteActivityBots removes all activity bot users (actbot*@slimetopia.bot) and cascading data.
 func (h *Handler) DeleteActivityBots(c *fiber.Ctx) error {
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
-	tag, err := pool.Exec(ctx,
-		`DELETE FROM users WHERE email LIKE 'actbot%@slimetopia.bot'`,
-	)
+	deleted, err := h.botRepo.Delete(ctx, actBotEmailLike)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to delete activity bots"})
 	}
 
-	deleted := tag.RowsAffected()
 	log.Info().Int64("deleted", deleted).Msg("Activity bot users deleted")
 
 	return c.JSON(fiber.Map{
@@ -969,52 +866,32 @@ func (h *Handler) DeleteActivityBots(c *fiber.Ctx) error {
 }
 
 // seedActivityShortCommentLikes adds likes to shorts comments from bots.
-func seedActivityShortCommentLikes(ctx context.Context, pool *pgxpool.Pool, shortIDs, botUserIDs []string) int {
+func (h *Handler) seedActivityShortCommentLikes(ctx context.Context, rng Rand, shortIDs, botUserIDs []string) int {
 	total := 0
 	for _, shortID := range shortIDs {
 		// Get comments for this short
-		rows, err := pool.Query(ctx,
-			`SELECT id FROM shorts_comments WHERE short_id = $1`, shortID,
-		)
+		commentIDs, err := h.botRepo.ShortCommentIDs(ctx, shortID)
 		if err != nil {
 			continue
 		}
 
-		var commentIDs []string
-		for rows.Next() {
-			var cid string
-			if rows.Scan(&cid) == nil {
-				commentIDs = append(commentIDs, cid)
-			}
-		}
-		rows.Close()
-
 		// Like ~50% of comments
 		for _, cid := range commentIDs {
-			if rand.Float64() > 0.5 {
+			if rng.Float64() > 0.5 {
 				continue
 			}
-			likeCount := randBetween(1, 5)
+			likeCount := randBetween(rng, 1, 5)
 			shuffled := make([]string, len(botUserIDs))
 			copy(shuffled, botUserIDs)
-			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+			rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
 			if likeCount > len(shuffled) {
 				likeCount = len(shuffled)
 			}
 			for _, likerID := range shuffled[:likeCount] {
-				_, err := pool.Exec(ctx,
-					`INSERT INTO shorts_comment_likes (comment_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-					cid, likerID,
-				)
-				if err == nil {
+				if h.botRepo.LikeShortComment(ctx, cid, likerID) == nil {
 					total++
 				}
 			}
-			// Update comment likes count
-			pool.Exec(ctx,
-				`UPDATE shorts_comments SET likes = (SELECT COUNT(*) FROM shorts_comment_likes WHERE comment_id = $1) WHERE id = $1`,
-				cid,
-			)
 		}
 	}
 	return total
@@ -1,10 +1,11 @@
 package game
 
 import (
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 var shortsTitles = []struct {
@@ -35,19 +36,27 @@ var shortsTitles = []struct {
 	{"이번 시즌 보상 어떤가요?", "다들 어디까지 진행했나요?", []string{"시즌", "보상", "진행"}, "question"},
 }
 
-// SeedShorts creates 20 dummy shorts for testing the shorts feed
+// SeedShorts creates 20 dummy shorts for testing the shorts feed.
+// Admin-only: the shorts are owned by the user_id form value, or a seeded bot when omitted.
 func (h *Handler) SeedShorts(c *fiber.Ctx) error {
-	userID := c.Locals("user_id").(string)
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
+	userID := c.FormValue("user_id")
+	if userID == "" {
+		var err error
+		userID, err = h.botRepo.First(ctx, botEmailLike)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "user_id required (no bot users to own the shorts)"})
+		}
+	}
+
 	// Check if shorts already exist
-	var count int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM shorts WHERE status = 'active'`).Scan(&count)
+	count, _ := h.shortsRepo.ActiveCount(ctx)
 	if count >= 10 {
 		return c.JSON(fiber.Map{"message": "shorts already seeded", "count": count})
 	}
 
+	rng, seed := h.newRand()
 	created := 0
 	for i, s := range shortsTitles {
 		// Alternate between current user and mock
@@ -60,25 +69,26 @@ func (h *Handler) SeedShorts(c *fiber.Ctx) error {
 		hoursAgo := i * 8
 		createdAt := time.Now().Add(-time.Duration(hoursAgo) * time.Hour)
 
-		_, err := pool.Exec(ctx,
-			`INSERT INTO shorts (user_id, title, description, video_url, thumbnail_url, tags, category, visibility, views, likes, comment_count, status, created_at)
-			 VALUES ($1, $2, $3, $4, '', $5, $6, 'public', $7, $8, $9, 'draft', $10)`,
-			userID,
-			s.Title,
-			s.Description,
-			"", // empty video_url - handled by client as card
-			tags,
-			s.Category,
-			rand.Intn(500)+10,       // views
-			rand.Intn(50)+1,         // likes
-			rand.Intn(10),           // comment_count
-			createdAt,
-		)
+		err := h.shortsRepo.Seed(ctx, &repository.Short{
+			UserID:       userID,
+			Title:        s.Title,
+			Description:  s.Description,
+			VideoURL:     "", // empty video_url - handled by client as card
+			Tags:         tags,
+			Category:     s.Category,
+			Visibility:   "public",
+			Views:        rng.Intn(500) + 10,
+			Likes:        rng.Intn(50) + 1,
+			CommentCount: rng.Intn(10),
+			Status:       "draft",
+			CreatedAt:    createdAt,
+		})
 		if err != nil {
 			continue
 		}
 		created++
 	}
+	log.Info().Int("created", created).Int64("seed", seed).Msg("Shorts seeded")
 
 	return c.JSON(fiber.Map{"created": created, "total": len(shortsTitles)})
 }
@@ -2,7 +2,8 @@ package game
 
 import (
 	"context"
-	"math/rand"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -42,235 +43,15 @@ func (h *Handler) findShopItem(id int) *ShopItem {
 	}
 }
 
-// hatchEggFromDB: weighted random species selection using DB data
-func (h *Handler) hatchEggFromDB(ctx context.Context, eggType string, luckBoost ...bool) (speciesID int, element string) {
+// hatchEggFromDB: weighted random species selection from the egg type's banner
+func (h *Handler) hatchEggFromDB(ctx context.Context, rng Rand, eggType string, luckBoost ...bool) (speciesID int, element string) {
 	hasLuck := len(luckBoost) > 0 && luckBoost[0]
-	_ = hasLuck
-	allSpecies, err := h.slimeRepo.GetAllSpecies(ctx)
-	if err != nil || len(allSpecies) == 0 {
+	banner, err := h.bannerForEgg(ctx, eggType)
+	if err != nil {
 		// Fallback
 		return 1, "water"
 	}
-
-	// Group by grade
-	gradeMap := map[string][]models.SlimeSpecies{}
-	for _, sp := range allSpecies {
-		gradeMap[sp.Grade] = append(gradeMap[sp.Grade], sp)
-	}
-
-	var selectedGrade string
-	r := rand.Float64() * 100
-
-	switch eggType {
-	case "premium":
-		// Premium: rare 60%, epic 30%, legendary 8%, mythic 2%
-		if r < 60 {
-			selectedGrade = "rare"
-		} else if r < 90 {
-			selectedGrade = "epic"
-		} else if r < 98 {
-			selectedGrade = "legendary"
-		} else {
-			selectedGrade = "mythic"
-		}
-	case "fire_egg":
-		selectedGrade = pickGradeNormal(r)
-		// Filter to fire element only
-		candidates := filterByElement(gradeMap[selectedGrade], "fire")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "fire")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 2, "fire"
-	case "water_egg":
-		selectedGrade = pickGradeNormal(r)
-		candidates := filterByElement(gradeMap[selectedGrade], "water")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "water")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 1, "water"
-	case "grass_egg":
-		selectedGrade = pickGradeNormal(r)
-		candidates := filterByElement(gradeMap[selectedGrade], "grass")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "grass")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 3, "grass"
-	case "dark_egg":
-		selectedGrade = pickGradeNormal(r)
-		candidates := filterByElement(gradeMap[selectedGrade], "dark")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "dark")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 5, "dark"
-	case "ice_egg":
-		selectedGrade = pickGradeNormal(r)
-		candidates := filterByElement(gradeMap[selectedGrade], "ice")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "ice")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 6, "ice"
-	case "electric_egg":
-		selectedGrade = pickGradeNormal(r)
-		candidates := filterByElement(gradeMap[selectedGrade], "electric")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "electric")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 7, "electric"
-	case "earth_egg":
-		selectedGrade = pickGradeNormal(r)
-		candidates := filterByElement(gradeMap[selectedGrade], "earth")
-		if len(candidates) == 0 {
-			candidates = filterByElement(allSpecies, "earth")
-		}
-		if len(candidates) > 0 {
-			chosen := candidates[rand.Intn(len(candidates))]
-			return chosen.ID, chosen.Element
-		}
-		return 9, "earth"
-	case "legendary_egg":
-		// Only epic/legendary/mythic
-		if r < 50 {
-			selectedGrade = "epic"
-		} else if r < 85 {
-			selectedGrade = "legendary"
-		} else {
-			selectedGrade = "mythic"
-		}
-	default:
-		// Normal egg: common 45%, uncommon 30%, rare 15%, epic 7%, legendary 2.5%, mythic 0.5%
-		if hasLuck {
-			selectedGrade = pickGradeLucky(r)
-		} else {
-			selectedGrade = pickGradeNormal(r)
-		}
-	}
-
-	candidates := gradeMap[selectedGrade]
-	if len(candidates) == 0 {
-		// Fallback to nearest lower grade
-		for _, g := range []string{"epic", "rare", "uncommon", "common"} {
-			if len(gradeMap[g]) > 0 {
-				candidates = gradeMap[g]
-				break
-			}
-		}
-	}
-	if len(candidates) == 0 {
-		return 1, "water"
-	}
-
-	chosen := candidates[rand.Intn(len(candidates))]
-	return chosen.ID, chosen.Element
-}
-
-// hatchEggWithMinGrade: force a minimum grade (for pity system)
-func (h *Handler) hatchEggWithMinGrade(ctx context.Context, eggType string, minGrade string) (int, string) {
-	allSpecies, err := h.slimeRepo.GetAllSpecies(ctx)
-	if err != nil || len(allSpecies) == 0 {
-		return 1, "water"
-	}
-
-	// Filter to species at or above minGrade
-	minRank := gradeRank[minGrade]
-	var candidates []models.SlimeSpecies
-	for _, sp := range allSpecies {
-		if gradeRank[sp.Grade] >= minRank {
-			candidates = append(candidates, sp)
-		}
-	}
-	if len(candidates) == 0 {
-		return 1, "water"
-	}
-
-	// For element eggs, filter further
-	switch eggType {
-	case "fire_egg":
-		candidates = filterByElement(candidates, "fire")
-	case "water_egg":
-		candidates = filterByElement(candidates, "water")
-	case "grass_egg":
-		candidates = filterByElement(candidates, "grass")
-	case "dark_egg":
-		candidates = filterByElement(candidates, "dark")
-	case "ice_egg":
-		candidates = filterByElement(candidates, "ice")
-	case "electric_egg":
-		candidates = filterByElement(candidates, "electric")
-	case "earth_egg":
-		candidates = filterByElement(candidates, "earth")
-	}
-
-	if len(candidates) == 0 {
-		return 1, "water"
-	}
-	chosen := candidates[rand.Intn(len(candidates))]
-	return chosen.ID, chosen.Element
-}
-
-func pickGradeNormal(r float64) string {
-	if r < 45 {
-		return "common"
-	} else if r < 75 {
-		return "uncommon"
-	} else if r < 90 {
-		return "rare"
-	} else if r < 97 {
-		return "epic"
-	} else if r < 99.5 {
-		return "legendary"
-	}
-	return "mythic"
-}
-
-// pickGradeLucky: boosted rates - shifts common/uncommon chance to higher grades
-func pickGradeLucky(r float64) string {
-	// common 25%, uncommon 30%, rare 25%, epic 12%, legendary 6%, mythic 2%
-	if r < 25 {
-		return "common"
-	} else if r < 55 {
-		return "uncommon"
-	} else if r < 80 {
-		return "rare"
-	} else if r < 92 {
-		return "epic"
-	} else if r < 98 {
-		return "legendary"
-	}
-	return "mythic"
-}
-
-func filterByElement(species []models.SlimeSpecies, element string) []models.SlimeSpecies {
-	var result []models.SlimeSpecies
-	for _, sp := range species {
-		if sp.Element == element {
-			result = append(result, sp)
-		}
-	}
-	return result
+	return h.hatchFromBanner(ctx, rng, banner, hasLuck, "")
 }
 
 // GetShopItems returns the shop item list
@@ -296,29 +77,18 @@ func (h *Handler) GetShopItems(c *fiber.Ctx) error {
 // GetPityStatus returns pity counters for all egg types
 func (h *Handler) GetPityStatus(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
-	rows, err := pool.Query(ctx,
-		`SELECT egg_type, pull_count FROM gacha_pity WHERE user_id = $1`,
-		userID,
-	)
+	counts, err := h.gachaRepo.Pity(ctx, userID)
 	if err != nil {
 		return c.JSON(fiber.Map{"pity": fiber.Map{}})
 	}
-	defer rows.Close()
 
+	banners, _ := h.gameDataRepo.GetAllGachaBanners(ctx)
 	pity := fiber.Map{}
-	for rows.Next() {
-		var eggType string
-		var count int
-		if rows.Scan(&eggType, &count) == nil {
-			pity[eggType] = fiber.Map{
-				"count":          count,
-				"next_rare":      pityRare,
-				"next_epic":      pityEpic,
-				"next_legendary": pityLegendary,
-			}
+	for _, b := range banners {
+		if count, ok := counts[b.ID]; ok {
+			pity[b.ID] = pityStatus(&b, count)
 		}
 	}
 
@@ -342,9 +112,20 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 	if item == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "item not found"})
 	}
+	itemRef := strconv.Itoa(item.ID)
+
+	// Eggs pull from a banner, which may be disabled or outside its window. They charge,
+	// hatch and record pity in one transaction of their own.
+	if item.Type == "egg" || item.Type == "multi_egg" {
+		b, err := h.bannerForEgg(c.Context(), eggBannerID(item))
+		if err != nil || !bannerAvailable(b, time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "banner not available"})
+		}
+		return h.buyEggs(c, userID, item, b, body.Quantity)
+	}
 
 	// Spend currency
-	if err := h.userRepo.SpendCurrency(c.Context(), userID, item.Cost.Gold, item.Cost.Gems, 0); err != nil {
+	if err := h.userRepo.SpendCurrency(c.Context(), userID, item.Cost.Gold, item.Cost.Gems, 0, repository.ReasonShopPurchase, itemRef); err != nil {
 		if err == repository.ErrInsufficientFunds {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient funds"})
 		}
@@ -356,170 +137,7 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 	// Track mission progress
 	h.missionRepo.IncrementProgress(ctx, userID, "buy")
 
-	maxSlimes, _ := h.userRepo.GetCapacity(ctx, userID)
-	if maxSlimes <= 0 {
-		maxSlimes = 30
-	}
-
 	switch item.Type {
-	case "egg":
-		eggType := getEggType(item.ID)
-		luckActive := h.IsBoosterActive(userID, BoosterLuck)
-		pool := h.slimeRepo.Pool()
-
-		// Multi-pull: if quantity > 1, batch hatch
-		qty := body.Quantity
-		if qty > 1 {
-			if qty > 18 {
-				qty = 18
-			}
-
-			// Enforce slime count cap
-			currentCount, err := h.slimeRepo.CountByUser(ctx, userID)
-			if err != nil {
-				h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check slime count"})
-			}
-			available := maxSlimes - currentCount
-			if available <= 0 {
-				h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "슬라임 보유 한도에 도달했습니다"})
-			}
-			if qty > available {
-				qty = available
-			}
-
-			// Charge additional cost for qty-1 more pulls (first already paid above)
-			extraGold := item.Cost.Gold * int64(qty-1)
-			extraGems := item.Cost.Gems * (qty - 1)
-			if extraGold > 0 || extraGems > 0 {
-				if err := h.userRepo.SpendCurrency(ctx, userID, extraGold, extraGems, 0); err != nil {
-					// Refund the first pull cost
-					h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
-					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient funds for multi-pull"})
-				}
-			}
-
-			type HatchResult struct {
-				Slime   fiber.Map `json:"slime"`
-				Species fiber.Map `json:"species"`
-			}
-			results := make([]HatchResult, 0, qty)
-			personalities := []string{
-				models.PersonalityEnergetic, models.PersonalityChill,
-				models.PersonalityFoodie, models.PersonalityCurious,
-				models.PersonalityTsundere, models.PersonalityGentle,
-			}
-			for i := 0; i < qty; i++ {
-				pityCount := IncrementPity(ctx, pool, userID, eggType)
-				speciesID, element := h.hatchEggFromDB(ctx, eggType, luckActive)
-				species, _ := h.slimeRepo.GetSpecies(ctx, speciesID)
-				if species != nil {
-					minGrade := ApplyPityGuarantee(pityCount)
-					if minGrade != "" && gradeRank[species.Grade] < gradeRank[minGrade] {
-						speciesID, element = h.hatchEggWithMinGrade(ctx, eggType, minGrade)
-						species, _ = h.slimeRepo.GetSpecies(ctx, speciesID)
-					}
-				}
-				if species != nil && ShouldResetPity(species.Grade) {
-					ResetPity(ctx, pool, userID, eggType)
-				}
-				personality := personalities[rand.Intn(len(personalities))]
-				newSlime, err := h.slimeRepo.Create(ctx, userID, speciesID, element, personality)
-				if err != nil {
-					log.Error().Err(err).Str("user_id", userID).Int("species_id", speciesID).Msg("multi-pull: failed to create slime")
-					continue
-				}
-				h.slimeRepo.AddCodexEntry(ctx, userID, speciesID)
-				spMap := fiber.Map{}
-				if species != nil {
-					spMap = fiber.Map{
-						"id": species.ID, "name": species.Name, "name_en": species.NameEN,
-						"element": species.Element, "grade": species.Grade, "description": species.Description,
-					}
-				}
-				results = append(results, HatchResult{Slime: slimeToMap(*newSlime), Species: spMap})
-			}
-			user, _ := h.userRepo.FindByID(ctx, userID)
-			LogGameAction(pool, userID, "gacha_multi", "gacha", -(item.Cost.Gold * int64(qty)), -(item.Cost.Gems * qty), 0, map[string]interface{}{
-				"egg_type": eggType, "count": len(results),
-			})
-			return c.JSON(fiber.Map{
-				"type": "multi_egg",
-				"result": fiber.Map{"results": results, "count": len(results)},
-				"user":   fiber.Map{"gold": user.Gold, "gems": user.Gems},
-			})
-		}
-
-		// Single pull — enforce slime cap
-		currentCount, err := h.slimeRepo.CountByUser(ctx, userID)
-		if err == nil && currentCount >= maxSlimes {
-			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "슬라임 보유 한도에 도달했습니다"})
-		}
-
-		pityCount := IncrementPity(ctx, pool, userID, eggType)
-		speciesID, element := h.hatchEggFromDB(ctx, eggType, luckActive)
-
-		// Check pity guarantee — upgrade grade if needed
-		species, _ := h.slimeRepo.GetSpecies(ctx, speciesID)
-		if species != nil {
-			minGrade := ApplyPityGuarantee(pityCount)
-			if minGrade != "" && gradeRank[species.Grade] < gradeRank[minGrade] {
-				speciesID, element = h.hatchEggWithMinGrade(ctx, eggType, minGrade)
-				species, _ = h.slimeRepo.GetSpecies(ctx, speciesID)
-			}
-		}
-
-		if species != nil && ShouldResetPity(species.Grade) {
-			ResetPity(ctx, pool, userID, eggType)
-		}
-
-		personalities := []string{
-			models.PersonalityEnergetic, models.PersonalityChill,
-			models.PersonalityFoodie, models.PersonalityCurious,
-			models.PersonalityTsundere, models.PersonalityGentle,
-		}
-		personality := personalities[rand.Intn(len(personalities))]
-
-		newSlime, err := h.slimeRepo.Create(ctx, userID, speciesID, element, personality)
-		if err != nil {
-			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to hatch egg"})
-		}
-
-		h.slimeRepo.AddCodexEntry(ctx, userID, speciesID)
-
-		user, _ := h.userRepo.FindByID(ctx, userID)
-		newPityCount := GetPityCount(ctx, pool, userID, eggType)
-
-		// Log gacha
-		gradeName := ""
-		if species != nil {
-			gradeName = species.Grade
-		}
-		LogGameAction(pool, userID, "gacha_single", "gacha", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
-			"egg_type": eggType, "species_id": speciesID, "grade": gradeName, "personality": personality,
-		})
-
-		return c.JSON(fiber.Map{
-			"type": "egg",
-			"result": fiber.Map{
-				"slime":   slimeToMap(*newSlime),
-				"species": species,
-			},
-			"user": fiber.Map{
-				"gold": user.Gold,
-				"gems": user.Gems,
-			},
-			"pity": fiber.Map{
-				"count":           newPityCount,
-				"next_rare":       pityRare,
-				"next_epic":       pityEpic,
-				"next_legendary":  pityLegendary,
-			},
-		})
-
 	case "food":
 		if body.SlimeID == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "slime_id required for food"})
@@ -527,11 +145,11 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 
 		slime, err := h.slimeRepo.FindByID(ctx, body.SlimeID)
 		if err != nil {
-			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
+			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0, repository.ReasonShopRefund, itemRef)
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "slime not found"})
 		}
 		if uuidToString(slime.UserID) != userID {
-			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
+			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0, repository.ReasonShopRefund, itemRef)
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not your slime"})
 		}
 
@@ -541,7 +159,7 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 		newAffection := clamp(slime.Affection+affectionBonus, 0, 100)
 
 		if err := h.slimeRepo.UpdateStats(ctx, body.SlimeID, newAffection, newHunger, slime.Condition); err != nil {
-			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
+			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0, repository.ReasonShopRefund, itemRef)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to apply food"})
 		}
 
@@ -552,7 +170,7 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 
 		user, _ := h.userRepo.FindByID(ctx, userID)
 
-		LogGameAction(h.slimeRepo.Pool(), userID, "shop_buy_food", "shop", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "shop_buy_food", "shop", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
 			"item_id": item.ID, "slime_id": body.SlimeID, "hunger_bonus": hungerBonus, "affection_bonus": affectionBonus, "exp_gained": expBonus,
 		})
 
@@ -574,87 +192,6 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 			},
 		})
 
-	case "multi_egg":
-		qty := item.Quantity
-		if qty <= 0 {
-			qty = 10
-		}
-		eggType := item.EggType
-		if eggType == "" {
-			eggType = "normal"
-		}
-		luckActive := h.IsBoosterActive(userID, BoosterLuck)
-
-		// Enforce slime count cap
-		currentCount, err := h.slimeRepo.CountByUser(ctx, userID)
-		if err == nil {
-			available := maxSlimes - currentCount
-			if available <= 0 {
-				h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
-				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "슬라임 보유 한도에 도달했습니다"})
-			}
-			if qty > available {
-				qty = available
-			}
-		}
-
-		type HatchResult struct {
-			Slime   fiber.Map `json:"slime"`
-			Species fiber.Map `json:"species"`
-		}
-		results := make([]HatchResult, 0, qty)
-
-		personalities := []string{
-			models.PersonalityEnergetic, models.PersonalityChill,
-			models.PersonalityFoodie, models.PersonalityCurious,
-			models.PersonalityTsundere, models.PersonalityGentle,
-		}
-
-		for i := 0; i < qty; i++ {
-			speciesID, element := h.hatchEggFromDB(ctx, eggType, luckActive)
-			personality := personalities[rand.Intn(len(personalities))]
-			newSlime, err := h.slimeRepo.Create(ctx, userID, speciesID, element, personality)
-			if err != nil {
-				log.Error().Err(err).Str("user_id", userID).Int("species_id", speciesID).Msg("multi_egg: failed to create slime")
-				continue
-			}
-			h.slimeRepo.AddCodexEntry(ctx, userID, speciesID)
-			sp, _ := h.slimeRepo.GetSpecies(ctx, speciesID)
-
-			spMap := fiber.Map{}
-			if sp != nil {
-				spMap = fiber.Map{
-					"id":          sp.ID,
-					"name":        sp.Name,
-					"name_en":     sp.NameEN,
-					"element":     sp.Element,
-					"grade":       sp.Grade,
-					"description": sp.Description,
-				}
-			}
-
-			results = append(results, HatchResult{
-				Slime:   slimeToMap(*newSlime),
-				Species: spMap,
-			})
-		}
-
-		user, _ := h.userRepo.FindByID(ctx, userID)
-		LogGameAction(h.slimeRepo.Pool(), userID, "gacha_multi", "gacha", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
-			"egg_type": eggType, "count": len(results),
-		})
-		return c.JSON(fiber.Map{
-			"type": "multi_egg",
-			"result": fiber.Map{
-				"results": results,
-				"count":   len(results),
-			},
-			"user": fiber.Map{
-				"gold": user.Gold,
-				"gems": user.Gems,
-			},
-		})
-
 	case "booster":
 		bt := getBoosterTypeForItem(item.ID)
 		if bt == "" {
@@ -669,13 +206,13 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 		// Check if already active
 		if h.IsBoosterActive(userID, bt) {
 			// Refund — can't stack
-			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0)
+			h.userRepo.AddCurrency(ctx, userID, item.Cost.Gold, item.Cost.Gems, 0, repository.ReasonShopRefund, itemRef)
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "booster already active"})
 		}
 
 		h.ActivateBooster(userID, bt)
 		user, _ := h.userRepo.FindByID(ctx, userID)
-		LogGameAction(h.slimeRepo.Pool(), userID, "shop_buy_booster", "shop", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "shop_buy_booster", "shop", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
 			"item_id": item.ID, "booster_type": string(bt),
 		})
 		return c.JSON(fiber.Map{
@@ -690,7 +227,7 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 
 	case "decoration":
 		user, _ := h.userRepo.FindByID(ctx, userID)
-		LogGameAction(h.slimeRepo.Pool(), userID, "shop_buy_deco", "shop", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "shop_buy_deco", "shop", -item.Cost.Gold, -item.Cost.Gems, 0, map[string]interface{}{
 			"item_id": item.ID,
 		})
 		return c.JSON(fiber.Map{
@@ -703,31 +240,6 @@ func (h *Handler) BuyItem(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "unknown item type"})
 }
 
-func getEggType(itemID int) string {
-	switch itemID {
-	case 2:
-		return "premium"
-	case 5:
-		return "fire_egg"
-	case 9:
-		return "water_egg"
-	case 10:
-		return "grass_egg"
-	case 20:
-		return "dark_egg"
-	case 21:
-		return "ice_egg"
-	case 22:
-		return "electric_egg"
-	case 23:
-		return "earth_egg"
-	case 6:
-		return "legendary_egg"
-	default:
-		return "normal"
-	}
-}
-
 // ─── Capacity Expansion ─────────────────────────────────────────────────────
 
 type capacityTier struct {
@@ -767,7 +279,7 @@ func (h *Handler) ExpandCapacity(c *fiber.Ctx) error {
 	}
 
 	// Spend currency
-	if err := h.userRepo.SpendCurrency(ctx, userID, nextTier.GoldCost, nextTier.GemsCost, 0); err != nil {
+	if err := h.userRepo.SpendCurrency(ctx, userID, nextTier.GoldCost, nextTier.GemsCost, 0, repository.ReasonCapacityExpand, strconv.Itoa(nextTier.To)); err != nil {
 		if err == repository.ErrInsufficientFunds {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "재화가 부족합니다"})
 		}
@@ -777,14 +289,13 @@ func (h *Handler) ExpandCapacity(c *fiber.Ctx) error {
 	// Update capacity
 	if err := h.userRepo.UpdateCapacity(ctx, userID, nextTier.To); err != nil {
 		// Refund on failure
-		h.userRepo.AddCurrency(ctx, userID, nextTier.GoldCost, nextTier.GemsCost, 0)
+		h.userRepo.AddCurrency(ctx, userID, nextTier.GoldCost, nextTier.GemsCost, 0, repository.ReasonCapacityRefund, strconv.Itoa(nextTier.To))
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to update capacity"})
 	}
 
 	user, _ := h.userRepo.FindByID(ctx, userID)
 
-	pool := h.slimeRepo.Pool()
-	LogGameAction(pool, userID, "capacity_expand", "shop", -nextTier.GoldCost, -nextTier.GemsCost, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "capacity_expand", "shop", -nextTier.GoldCost, -nextTier.GemsCost, 0, map[string]interface{}{
 		"from": nextTier.From, "to": nextTier.To,
 	})
 
@@ -900,10 +411,11 @@ func (h *Handler) BuyGems(c *fiber.Ctx) error {
 		addGems = pkg.Amount
 	}
 
-	h.userRepo.AddCurrency(c.Context(), userID, addGold, addGems, addStardust)
+	if err := h.userRepo.AddCurrency(c.Context(), userID, addGold, addGems, addStardust, repository.ReasonCurrencyPackage, strconv.Itoa(pkg.ID)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to add currency"})
+	}
 
-	pool := h.slimeRepo.Pool()
-	LogGameAction(pool, userID, "buy_currency", "shop", addGold, addGems, addStardust, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "buy_currency", "shop", addGold, addGems, addStardust, map[string]interface{}{
 		"package_id": pkg.ID, "type": pkg.Type, "amount": pkg.Amount,
 	})
 
@@ -936,25 +448,19 @@ func getFoodBonuses(itemID int) (hunger, affection, exp int) {
 
 func (h *Handler) GetFoodInventory(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	pool := h.slimeRepo.Pool()
 
-	rows, err := pool.Query(c.Context(),
-		`SELECT item_id, quantity FROM food_inventory WHERE user_id = $1 AND quantity > 0`, userID)
+	stacks, err := h.userRepo.Foods(c.Context(), userID)
 	if err != nil {
 		return c.JSON(fiber.Map{"items": []fiber.Map{}})
 	}
-	defer rows.Close()
 
 	type FoodEntry struct {
 		ItemID   int `json:"item_id"`
 		Quantity int `json:"quantity"`
 	}
-	items := []FoodEntry{}
-	for rows.Next() {
-		var e FoodEntry
-		if rows.Scan(&e.ItemID, &e.Quantity) == nil {
-			items = append(items, e)
-		}
+	items := make([]FoodEntry, 0, len(stacks))
+	for _, f := range stacks {
+		items = append(items, FoodEntry{ItemID: f.ItemID, Quantity: f.Quantity})
 	}
 
 	return c.JSON(fiber.Map{"items": items})
@@ -983,20 +489,16 @@ func (h *Handler) BuyFoodToInventory(c *fiber.Ctx) error {
 	totalGold := item.Cost.Gold * int64(body.Quantity)
 	totalGems := item.Cost.Gems * body.Quantity
 
-	if err := h.userRepo.SpendCurrency(c.Context(), userID, totalGold, totalGems, 0); err != nil {
+	if err := h.userRepo.SpendCurrency(c.Context(), userID, totalGold, totalGems, 0, repository.ReasonShopPurchase, strconv.Itoa(item.ID)); err != nil {
 		if err == repository.ErrInsufficientFunds {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient funds"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "payment failed"})
 	}
 
-	pool := h.slimeRepo.Pool()
-	pool.Exec(c.Context(),
-		`INSERT INTO food_inventory (user_id, item_id, quantity) VALUES ($1, $2, $3)
-		 ON CONFLICT (user_id, item_id) DO UPDATE SET quantity = food_inventory.quantity + $3`,
-		userID, body.ItemID, body.Quantity)
+	h.userRepo.AddFood(c.Context(), userID, body.ItemID, body.Quantity)
 
-	LogGameAction(pool, userID, "buy_food_inv", "shop", -totalGold, -totalGems, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "buy_food_inv", "shop", -totalGold, -totalGems, 0, map[string]interface{}{
 		"item_id": body.ItemID, "quantity": body.Quantity,
 	})
 
@@ -1020,13 +522,9 @@ func (h *Handler) ApplyFood(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Check inventory
-	var qty int
-	err := pool.QueryRow(ctx,
-		`SELECT quantity FROM food_inventory WHERE user_id = $1 AND item_id = $2`,
-		userID, body.ItemID).Scan(&qty)
+	qty, err := h.userRepo.FoodQuantity(ctx, userID, body.ItemID)
 	if err != nil || qty <= 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "먹이가 없습니다"})
 	}
@@ -1058,11 +556,9 @@ func (h *Handler) ApplyFood(c *fiber.Ctx) error {
 	}
 
 	// Decrement inventory
-	pool.Exec(ctx,
-		`UPDATE food_inventory SET quantity = quantity - 1 WHERE user_id = $1 AND item_id = $2`,
-		userID, body.ItemID)
+	h.userRepo.ConsumeFood(ctx, userID, body.ItemID, 1)
 
-	LogGameAction(pool, userID, "apply_food", "item", 0, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "apply_food", "item", 0, 0, 0, map[string]interface{}{
 		"item_id": body.ItemID, "slime_id": body.SlimeID, "exp_gained": expBonus,
 	})
 
@@ -1095,13 +591,9 @@ func (h *Handler) ApplyFoodBatch(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Check inventory
-	var qty int
-	err := pool.QueryRow(ctx,
-		`SELECT quantity FROM food_inventory WHERE user_id = $1 AND item_id = $2`,
-		userID, body.ItemID).Scan(&qty)
+	qty, err := h.userRepo.FoodQuantity(ctx, userID, body.ItemID)
 	if err != nil || qty <= 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "먹이가 없습니다"})
 	}
@@ -1140,11 +632,9 @@ func (h *Handler) ApplyFoodBatch(c *fiber.Ctx) error {
 	}
 
 	// Decrement inventory
-	pool.Exec(ctx,
-		`UPDATE food_inventory SET quantity = quantity - $3 WHERE user_id = $1 AND item_id = $2`,
-		userID, body.ItemID, useQty)
+	h.userRepo.ConsumeFood(ctx, userID, body.ItemID, useQty)
 
-	LogGameAction(pool, userID, "apply_food_batch", "item", 0, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "apply_food_batch", "item", 0, 0, 0, map[string]interface{}{
 		"item_id": body.ItemID, "slime_id": body.SlimeID, "quantity": useQty, "exp_gained": totalExp,
 	})
 
@@ -1173,13 +663,9 @@ func (h *Handler) ApplyFoodToAll(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	// Check inventory
-	var qty int
-	err := pool.QueryRow(ctx,
-		`SELECT quantity FROM food_inventory WHERE user_id = $1 AND item_id = $2`,
-		userID, body.ItemID).Scan(&qty)
+	qty, err := h.userRepo.FoodQuantity(ctx, userID, body.ItemID)
 	if err != nil || qty <= 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "먹이가 없습니다"})
 	}
@@ -1257,11 +743,9 @@ func (h *Handler) ApplyFoodToAll(c *fiber.Ctx) error {
 	}
 
 	// Decrement inventory
-	pool.Exec(ctx,
-		`UPDATE food_inventory SET quantity = quantity - $3 WHERE user_id = $1 AND item_id = $2`,
-		userID, body.ItemID, len(results))
+	h.userRepo.ConsumeFood(ctx, userID, body.ItemID, len(results))
 
-	LogGameAction(pool, userID, "apply_food_all", "item", 0, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "apply_food_all", "item", 0, 0, 0, map[string]interface{}{
 		"item_id": body.ItemID, "fed_count": len(results),
 	})
 
@@ -1,15 +1,17 @@
 package game
 
 import (
-	"fmt"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 const (
@@ -73,7 +75,14 @@ func (h *Handler) UploadShort(c *fiber.Ctx) error {
 		}
 	}
 
-	linkedSpeciesID := c.FormValue("linked_species_id")
+	var linkedSpeciesID *int
+	if v := c.FormValue("linked_species_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid linked_species_id"})
+		}
+		linkedSpeciesID = &id
+	}
 
 	// Get video file (required)
 	videoID := uuid.New().String()
@@ -140,23 +149,17 @@ func (h *Handler) UploadShort(c *fiber.Ctx) error {
 	}
 
 	// Insert into DB
-	pool := h.slimeRepo.Pool()
-	var shortID string
-
-	linkedQuery := "NULL"
-	args := []interface{}{userID, title, description, videoURL, thumbnailURL, tags, category, visibility}
-	argIdx := 9
-
-	if linkedSpeciesID != "" {
-		linkedQuery = fmt.Sprintf("$%d", argIdx)
-		args = append(args, linkedSpeciesID)
-	}
-
-	err = pool.QueryRow(c.UserContext(),
-		fmt.Sprintf(`INSERT INTO shorts (user_id, title, description, video_url, thumbnail_url, tags, category, visibility, linked_species_id)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, %s) RETURNING id`, linkedQuery),
-		args...,
-	).Scan(&shortID)
+	shortID, err := h.shortsRepo.Create(c.UserContext(), &repository.Short{
+		UserID:          userID,
+		Title:           title,
+		Description:     description,
+		VideoURL:        videoURL,
+		ThumbnailURL:    thumbnailURL,
+		Tags:            tags,
+		Category:        category,
+		Visibility:      visibility,
+		LinkedSpeciesID: linkedSpeciesID,
+	})
 	if err != nil {
 		// Clean up files on error
 		if videoPath != "" {
@@ -180,52 +183,16 @@ func (h *Handler) GetShortsFeed(c *fiber.Ctx) error {
 		limit = 20
 	}
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	var shorts []fiber.Map
-
-	if cursor != "" {
-		// Get created_at for cursor
-		var cursorTime time.Time
-		err := pool.QueryRow(ctx, `SELECT created_at FROM shorts WHERE id = $1`, cursor).Scan(&cursorTime)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid cursor"})
-		}
-
-		rows, err := pool.Query(ctx,
-			`SELECT s.id, s.user_id, u.nickname, s.title, s.description, s.video_url, s.thumbnail_url,
-			        s.duration_ms, s.tags, s.category, s.linked_species_id, s.views, s.likes, s.comment_count, s.created_at,
-			        EXISTS(SELECT 1 FROM shorts_likes l WHERE l.short_id = s.id AND l.user_id = $1) as liked
-			 FROM shorts s
-			 JOIN users u ON u.id = s.user_id
-			 WHERE s.status = 'active' AND s.visibility = 'public' AND s.created_at < $2
-			 ORDER BY s.created_at DESC
-			 LIMIT $3`,
-			userID, cursorTime, limit,
-		)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch feed"})
-		}
-		defer rows.Close()
-		shorts = scanShorts(rows, userID)
-	} else {
-		rows, err := pool.Query(ctx,
-			`SELECT s.id, s.user_id, u.nickname, s.title, s.description, s.video_url, s.thumbnail_url,
-			        s.duration_ms, s.tags, s.category, s.linked_species_id, s.views, s.likes, s.comment_count, s.created_at,
-			        EXISTS(SELECT 1 FROM shorts_likes l WHERE l.short_id = s.id AND l.user_id = $1) as liked
-			 FROM shorts s
-			 JOIN users u ON u.id = s.user_id
-			 WHERE s.status = 'active' AND s.visibility = 'public'
-			 ORDER BY s.created_at DESC
-			 LIMIT $2`,
-			userID, limit,
-		)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch feed"})
-		}
-		defer rows.Close()
-		shorts = scanShorts(rows, userID)
+	feed, err := h.shortsRepo.Feed(c.UserContext(), userID, cursor, limit)
+	if errors.Is(err, repository.ErrShortNotFound) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid cursor"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch feed"})
+	}
+	shorts := make([]fiber.Map, 0, len(feed))
+	for i := range feed {
+		shorts = append(shorts, shortJSON(&feed[i], userID))
 	}
 
 	var nextCursor string
@@ -236,51 +203,30 @@ func (h *Handler) GetShortsFeed(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"shorts": shorts, "next_cursor": nextCursor})
 }
 
-type scannable2 interface {
-	Next() bool
-	Scan(...interface{}) error
-}
-
-func scanShorts(rows scannable2, userID string) []fiber.Map {
-	shorts := make([]fiber.Map, 0)
-	for rows.Next() {
-		var id, uid, nickname, title, description, videoURL, thumbnailURL, category string
-		var durationMs, views, likes, commentCount int
-		var tags []string
-		var linkedSpeciesID *int
-		var createdAt time.Time
-		var liked bool
-
-		err := rows.Scan(&id, &uid, &nickname, &title, &description, &videoURL, &thumbnailURL,
-			&durationMs, &tags, &category, &linkedSpeciesID, &views, &likes, &commentCount, &createdAt, &liked)
-		if err != nil {
-			continue
-		}
-
-		entry := fiber.Map{
-			"id":            id,
-			"user_id":       uid,
-			"nickname":      nickname,
-			"title":         title,
-			"description":   description,
-			"video_url":     videoURL,
-			"thumbnail_url": thumbnailURL,
-			"duration_ms":   durationMs,
-			"tags":          tags,
-			"category":      category,
-			"views":         views,
-			"likes":         likes,
-			"comment_count": commentCount,
-			"created_at":    createdAt,
-			"liked":         liked,
-			"is_mine":       uid == userID,
-		}
-		if linkedSpeciesID != nil {
-			entry["linked_species_id"] = *linkedSpeciesID
-		}
-		shorts = append(shorts, entry)
-	}
-	return shorts
+// shortJSON is a short as viewerID sees it.
+func shortJSON(s *repository.Short, viewerID string) fiber.Map {
+	entry := fiber.Map{
+		"id":            s.ID,
+		"user_id":       s.UserID,
+		"nickname":      s.Nickname,
+		"title":         s.Title,
+		"description":   s.Description,
+		"video_url":     s.VideoURL,
+		"thumbnail_url": s.ThumbnailURL,
+		"duration_ms":   s.DurationMs,
+		"tags":          s.Tags,
+		"category":      s.Category,
+		"views":         s.Views,
+		"likes":         s.Likes,
+		"comment_count": s.CommentCount,
+		"created_at":    s.CreatedAt,
+		"liked":         s.Liked,
+		"is_mine":       s.UserID == viewerID,
+	}
+	if s.LinkedSpeciesID != nil {
+		entry["linked_species_id"] = *s.LinkedSpeciesID
+	}
+	return entry
 }
 
 // GET /api/shorts/:id
@@ -288,69 +234,25 @@ func (h *Handler) GetShortDetail(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	shortID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
-	var id, uid, nickname, title, description, videoURL, thumbnailURL, category, status string
-	var durationMs, views, likes, commentCount int
-	var tags []string
-	var linkedSpeciesID *int
-	var createdAt time.Time
-	var liked bool
-
-	err := pool.QueryRow(ctx,
-		`SELECT s.id, s.user_id, u.nickname, s.title, s.description, s.video_url, s.thumbnail_url,
-		        s.duration_ms, s.tags, s.category, s.linked_species_id, s.views, s.likes, s.comment_count, s.status, s.created_at,
-		        EXISTS(SELECT 1 FROM shorts_likes l WHERE l.short_id = s.id AND l.user_id = $1) as liked
-		 FROM shorts s
-		 JOIN users u ON u.id = s.user_id
-		 WHERE s.id = $2`,
-		userID, shortID,
-	).Scan(&id, &uid, &nickname, &title, &description, &videoURL, &thumbnailURL,
-		&durationMs, &tags, &category, &linkedSpeciesID, &views, &likes, &commentCount, &status, &createdAt, &liked)
+	short, err := h.shortsRepo.Get(ctx, userID, shortID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short not found"})
 	}
 
-	if status != "active" && uid != userID {
+	if short.Status != "active" && short.UserID != userID {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short not found"})
 	}
 
-	// Record view (UPSERT — one view per user)
-	pool.Exec(ctx,
-		`INSERT INTO shorts_views (short_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		shortID, userID,
-	)
-	// Update view count
-	pool.Exec(ctx,
-		`UPDATE shorts SET views = (SELECT COUNT(*) FROM shorts_views WHERE short_id = $1) WHERE id = $1`,
-		shortID,
-	)
+	// Record view (one view per user)
+	h.shortsRepo.RecordView(ctx, shortID, userID)
 
 	// Track mission progress
 	h.missionRepo.IncrementProgress(c.Context(), userID, "watch_short")
 
-	entry := fiber.Map{
-		"id":            id,
-		"user_id":       uid,
-		"nickname":      nickname,
-		"title":         title,
-		"description":   description,
-		"video_url":     videoURL,
-		"thumbnail_url": thumbnailURL,
-		"duration_ms":   durationMs,
-		"tags":          tags,
-		"category":      category,
-		"views":         views + 1,
-		"likes":         likes,
-		"comment_count": commentCount,
-		"created_at":    createdAt,
-		"liked":         liked,
-		"is_mine":       uid == userID,
-	}
-	if linkedSpeciesID != nil {
-		entry["linked_species_id"] = *linkedSpeciesID
-	}
+	entry := shortJSON(short, userID)
+	entry["views"] = short.Views + 1
 
 	return c.JSON(entry)
 }
@@ -360,22 +262,10 @@ func (h *Handler) LikeShort(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	shortID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	_, err := pool.Exec(ctx,
-		`INSERT INTO shorts_likes (short_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
-		shortID, userID,
-	)
-	if err != nil {
+	if err := h.shortsRepo.Like(c.UserContext(), shortID, userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to like"})
 	}
 
-	pool.Exec(ctx,
-		`UPDATE shorts SET likes = (SELECT COUNT(*) FROM shorts_likes WHERE short_id = $1) WHERE id = $1`,
-		shortID,
-	)
-
 	// Track mission
 	h.missionRepo.IncrementProgress(c.Context(), userID, "like_short")
 
@@ -387,14 +277,7 @@ func (h *Handler) UnlikeShort(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	shortID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.UserContext()
-
-	pool.Exec(ctx, `DELETE FROM shorts_likes WHERE short_id = $1 AND user_id = $2`, shortID, userID)
-	pool.Exec(ctx,
-		`UPDATE shorts SET likes = (SELECT COUNT(*) FROM shorts_likes WHERE short_id = $1) WHERE id = $1`,
-		shortID,
-	)
+	h.shortsRepo.Unlike(c.UserContext(), shortID, userID)
 
 	return c.JSON(fiber.Map{"success": true})
 }
@@ -414,12 +297,7 @@ func (h *Handler) ReactShort(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid emoji"})
 	}
 
-	pool := h.slimeRepo.Pool()
-	pool.Exec(c.UserContext(),
-		`INSERT INTO shorts_reactions (short_id, user_id, emoji) VALUES ($1, $2, $3)
-		 ON CONFLICT (short_id, user_id) DO UPDATE SET emoji = $3`,
-		shortID, userID, body.Emoji,
-	)
+	h.shortsRepo.React(c.UserContext(), shortID, userID, body.Emoji)
 
 	return c.JSON(fiber.Map{"success": true, "emoji": body.Emoji})
 }
@@ -429,40 +307,22 @@ func (h *Handler) GetShortComments(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	shortID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT sc.id, sc.user_id, u.nickname, sc.content, sc.likes, sc.created_at,
-		        EXISTS(SELECT 1 FROM shorts_comment_likes l WHERE l.comment_id = sc.id AND l.user_id = $1) as liked
-		 FROM shorts_comments sc
-		 JOIN users u ON u.id = sc.user_id
-		 WHERE sc.short_id = $2
-		 ORDER BY sc.created_at DESC
-		 LIMIT $3`,
-		userID, shortID, commentsPageLimit,
-	)
+	list, err := h.shortsRepo.Comments(c.UserContext(), userID, shortID, commentsPageLimit)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch comments"})
 	}
-	defer rows.Close()
 
-	comments := make([]fiber.Map, 0)
-	for rows.Next() {
-		var id, uid, nickname, content string
-		var likes int
-		var createdAt time.Time
-		var liked bool
-		if err := rows.Scan(&id, &uid, &nickname, &content, &likes, &createdAt, &liked); err != nil {
-			continue
-		}
+	comments := make([]fiber.Map, 0, len(list))
+	for _, cm := range list {
 		comments = append(comments, fiber.Map{
-			"id":         id,
-			"user_id":    uid,
-			"nickname":   nickname,
-			"content":    content,
-			"likes":      likes,
-			"created_at": createdAt,
-			"liked":      liked,
-			"is_mine":    uid == userID,
+			"id":         cm.ID,
+			"user_id":    cm.UserID,
+			"nickname":   cm.Nickname,
+			"content":    cm.Content,
+			"likes":      cm.Likes,
+			"created_at": cm.CreatedAt,
+			"liked":      cm.Liked,
+			"is_mine":    cm.UserID == userID,
 		})
 	}
 
@@ -493,22 +353,11 @@ func (h *Handler) CreateShortComment(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "daily comment limit reached"})
 	}
 
-	pool := h.slimeRepo.Pool()
-	var commentID string
-	err := pool.QueryRow(c.UserContext(),
-		`INSERT INTO shorts_comments (short_id, user_id, content) VALUES ($1, $2, $3) RETURNING id`,
-		shortID, userID, body.Content,
-	).Scan(&commentID)
+	commentID, err := h.shortsRepo.CreateComment(c.UserContext(), shortID, userID, body.Content)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to create comment"})
 	}
 
-	// Update comment count
-	pool.Exec(c.UserContext(),
-		`UPDATE shorts SET comment_count = (SELECT COUNT(*) FROM shorts_comments WHERE short_id = $1) WHERE id = $1`,
-		shortID,
-	)
-
 	h.rdb.Incr(ctx, key)
 	h.rdb.ExpireAt(ctx, key, time.Now().Add(24*time.Hour))
 
@@ -520,12 +369,8 @@ func (h *Handler) DeleteShort(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	shortID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
-	tag, err := pool.Exec(c.UserContext(),
-		`UPDATE shorts SET status = 'deleted' WHERE id = $1 AND user_id = $2 AND status = 'active'`,
-		shortID, userID,
-	)
-	if err != nil || tag.RowsAffected() == 0 {
+	deleted, err := h.shortsRepo.Delete(c.UserContext(), shortID, userID)
+	if err != nil || !deleted {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "cannot delete"})
 	}
 
@@ -536,7 +381,6 @@ func (h *Handler) DeleteShort(c *fiber.Ctx) error {
 func (h *Handler) TipShort(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	shortID := c.Params("id")
 
@@ -556,11 +400,7 @@ func (h *Handler) TipShort(c *fiber.Ctx) error {
 	}
 
 	// Find the short's owner
-	var receiverID string
-	err := pool.QueryRow(c.UserContext(),
-		`SELECT user_id FROM shorts WHERE id = $1 AND status = 'active'`,
-		shortID,
-	).Scan(&receiverID)
+	receiverID, err := h.shortsRepo.ActiveOwner(c.UserContext(), shortID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "short not found"})
 	}
@@ -571,12 +411,7 @@ func (h *Handler) TipShort(c *fiber.Ctx) error {
 
 	// Check daily limit
 	today := time.Now().Truncate(24 * time.Hour)
-	var dailyTotal int
-	pool.QueryRow(c.UserContext(),
-		`SELECT COALESCE(SUM(amount), 0) FROM shorts_tips
-		 WHERE sender_id = $1 AND tip_type = $2 AND created_at >= $3`,
-		userID, body.Type, today,
-	).Scan(&dailyTotal)
+	dailyTotal, _ := h.shortsRepo.TipsSentSince(c.UserContext(), userID, body.Type, today)
 
 	limit := dailyTipGoldLimit
 	if body.Type == "gems" {
@@ -592,16 +427,18 @@ func (h *Handler) TipShort(c *fiber.Ctx) error {
 	}
 
 	// Deduct from sender, give to receiver
+	var tipGold int64
+	var tipGems int
 	if body.Type == "gold" {
-		if err := h.userRepo.SpendCurrency(ctx, userID, int64(body.Amount), 0, 0); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_funds"})
-		}
-		h.userRepo.AddCurrency(ctx, receiverID, int64(body.Amount), 0, 0)
+		tipGold = int64(body.Amount)
 	} else {
-		if err := h.userRepo.SpendCurrency(ctx, userID, 0, body.Amount, 0); err != nil {
+		tipGems = body.Amount
+	}
+	if err := h.userRepo.TransferCurrency(ctx, userID, receiverID, tipGold, tipGems, repository.ReasonShortsTip, shortID); err != nil {
+		if errors.Is(err, repository.ErrInsufficientFunds) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_funds"})
 		}
-		h.userRepo.AddCurrency(ctx, receiverID, 0, body.Amount, 0)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to send tip"})
 	}
 
 	// Log the tip
@@ -609,25 +446,21 @@ func (h *Handler) TipShort(c *fiber.Ctx) error {
 	if len(msg) > 200 {
 		msg = msg[:200]
 	}
-	pool.Exec(c.UserContext(),
-		`INSERT INTO shorts_tips (short_id, sender_id, receiver_id, tip_type, amount, message)
-		 VALUES ($1, $2, $3, $4, $5, $6)`,
-		shortID, userID, receiverID, body.Type, body.Amount, msg,
-	)
+	h.shortsRepo.RecordTip(c.UserContext(), shortID, userID, receiverID, body.Type, body.Amount, msg)
 
 	// Log shorts tip
 	if body.Type == "gold" {
-		LogGameAction(pool, userID, "shorts_tip_send", "shorts", -int64(body.Amount), 0, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "shorts_tip_send", "shorts", -int64(body.Amount), 0, 0, map[string]interface{}{
 			"short_id": shortID, "type": body.Type, "amount": body.Amount,
 		})
-		LogGameAction(pool, receiverID, "shorts_tip_receive", "shorts", int64(body.Amount), 0, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, receiverID, "shorts_tip_receive", "shorts", int64(body.Amount), 0, 0, map[string]interface{}{
 			"short_id": shortID, "type": body.Type, "amount": body.Amount,
 		})
 	} else {
-		LogGameAction(pool, userID, "shorts_tip_send", "shorts", 0, -body.Amount, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, userID, "shorts_tip_send", "shorts", 0, -body.Amount, 0, map[string]interface{}{
 			"short_id": shortID, "type": body.Type, "amount": body.Amount,
 		})
-		LogGameAction(pool, receiverID, "shorts_tip_receive", "shorts", 0, body.Amount, 0, map[string]interface{}{
+		LogGameAction(h.gameLogRepo, receiverID, "shorts_tip_receive", "shorts", 0, body.Amount, 0, map[string]interface{}{
 			"short_id": shortID, "type": body.Type, "amount": body.Amount,
 		})
 	}
@@ -650,11 +483,7 @@ func (h *Handler) TipShort(c *fiber.Ctx) error {
 		mailBody += "\n메시지: " + msg
 	}
 
-	pool.Exec(c.UserContext(), `
-		INSERT INTO mailbox (user_id, title, body, mail_type, reward_gold, reward_gems)
-		VALUES ($1, $2, $3, 'gift', 0, 0)`,
-		receiverID, title, mailBody,
-	)
+	h.mailboxRepo.Send(c.UserContext(), receiverID, title, mailBody, "gift", 0, 0, nil)
 
 	return c.JSON(fiber.Map{"ok": true})
 }
@@ -663,53 +492,32 @@ func (h *Handler) TipShort(c *fiber.Ctx) error {
 func (h *Handler) GetMyShorts(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 
-	pool := h.slimeRepo.Pool()
-	rows, err := pool.Query(c.UserContext(),
-		`SELECT id, title, video_url, thumbnail_url, views, likes, comment_count, status, created_at
-		 FROM shorts
-		 WHERE user_id = $1 AND status != 'deleted'
-		 ORDER BY created_at DESC
-		 LIMIT 50`,
-		userID,
-	)
+	mine, err := h.shortsRepo.ByUser(c.UserContext(), userID, 50)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch shorts"})
 	}
-	defer rows.Close()
 
-	shorts := make([]fiber.Map, 0)
+	shorts := make([]fiber.Map, 0, len(mine))
 	var totalViews, totalLikes int
 
-	for rows.Next() {
-		var id, title, videoURL, thumbnailURL, status string
-		var views, likes, commentCount int
-		var createdAt time.Time
-		if err := rows.Scan(&id, &title, &videoURL, &thumbnailURL, &views, &likes, &commentCount, &status, &createdAt); err != nil {
-			continue
-		}
-		totalViews += views
-		totalLikes += likes
+	for _, sh := range mine {
+		totalViews += sh.Views
+		totalLikes += sh.Likes
 		shorts = append(shorts, fiber.Map{
-			"id":            id,
-			"title":         title,
-			"video_url":     videoURL,
-			"thumbnail_url": thumbnailURL,
-			"views":         views,
-			"likes":         likes,
-			"comment_count": commentCount,
-			"status":        status,
-			"created_at":    createdAt,
+			"id":            sh.ID,
+			"title":         sh.Title,
+			"video_url":     sh.VideoURL,
+			"thumbnail_url": sh.ThumbnailURL,
+			"views":         sh.Views,
+			"likes":         sh.Likes,
+			"comment_count": sh.CommentCount,
+			"status":        sh.Status,
+			"created_at":    sh.CreatedAt,
 		})
 	}
 
 	// Total tips received
-	var totalTipsGold, totalTipsGems int
-	pool.QueryRow(c.UserContext(),
-		`SELECT COALESCE(SUM(CASE WHEN tip_type='gold' THEN amount ELSE 0 END), 0),
-		        COALESCE(SUM(CASE WHEN tip_type='gems' THEN amount ELSE 0 END), 0)
-		 FROM shorts_tips WHERE receiver_id = $1`,
-		userID,
-	).Scan(&totalTipsGold, &totalTipsGems)
+	totalTipsGold, totalTipsGems, _ := h.shortsRepo.TipsReceived(c.UserContext(), userID)
 
 	return c.JSON(fiber.Map{
 		"shorts":          shorts,
@@ -2,7 +2,6 @@ package game
 
 import (
 	"context"
-	"math/rand"
 
 	"github.com/rs/zerolog/log"
 	"github.com/slimetopia/server/internal/models"
@@ -26,7 +25,7 @@ var personalities = []string{
 }
 
 // GrantStarterPack gives a new user their first slimes, currency, and food
-func GrantStarterPack(ctx context.Context, slimeRepo *repository.SlimeRepository, userRepo *repository.UserRepository, userID string) {
+func GrantStarterPack(ctx context.Context, slimeRepo repository.SlimeStore, userRepo repository.UserStore, userID string) {
 	// Check if user already has slimes
 	count, err := slimeRepo.CountByUser(ctx, userID)
 	if err != nil || count > 0 {
@@ -47,16 +46,12 @@ func GrantStarterPack(ctx context.Context, slimeRepo *repository.SlimeRepository
 	}
 
 	// Grant starting currency: 3000 gold + 50 gems
-	if err := userRepo.AddCurrency(ctx, userID, 3000, 50, 0); err != nil {
+	if err := userRepo.AddCurrency(ctx, userID, 3000, 50, 0, repository.ReasonStarterPack, ""); err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to grant starter currency")
 	}
 
-	// Grant 10x Tasty Food (food_id=3)
-	_, err = slimeRepo.Pool().Exec(ctx,
-		"INSERT INTO food_inventory (user_id, food_id, quantity) VALUES ($1, 3, 10) ON CONFLICT (user_id, food_id) DO UPDATE SET quantity = food_inventory.quantity + 10",
-		userID,
-	)
-	if err != nil {
+	// Grant 10x Tasty Food (shop item 3)
+	if err := userRepo.AddFood(ctx, userID, 3, 10); err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Failed to grant starter food")
 	}
 
@@ -64,9 +59,9 @@ func GrantStarterPack(ctx context.Context, slimeRepo *repository.SlimeRepository
 }
 
 // GrantRandomSlime gives a random common slime (for testing)
-func GrantRandomSlime(ctx context.Context, slimeRepo *repository.SlimeRepository, userID string) (*models.Slime, error) {
-	starter := starterSlimes[rand.Intn(len(starterSlimes))]
-	personality := personalities[rand.Intn(len(personalities))]
+func GrantRandomSlime(ctx context.Context, rng Rand, slimeRepo repository.SlimeStore, userID string) (*models.Slime, error) {
+	starter := starterSlimes[rng.Intn(len(starterSlimes))]
+	personality := personalities[rng.Intn(len(personalities))]
 
 	slime, err := slimeRepo.Create(ctx, userID, starter.SpeciesID, starter.Element, personality)
 	if err != nil {
@@ -1,9 +1,11 @@
 package game
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 // POST /api/support/tickets — create a support ticket
@@ -28,45 +30,23 @@ func (h *Handler) CreateSupportTicket(c *fiber.Ctx) error {
 		body.Message = string([]rune(body.Message)[:2000])
 	}
 
-	pool := h.slimeRepo.Pool()
-	ctx := c.Context()
-
-	var ticketID int64
-	err := pool.QueryRow(ctx,
-		`INSERT INTO support_tickets (user_id, subject, category) VALUES ($1, $2, $3) RETURNING id`,
-		userID, body.Subject, body.Category,
-	).Scan(&ticketID)
+	// The initial message is saved as the first reply
+	ticketID, err := h.supportRepo.CreateTicket(c.Context(), userID, body.Subject, body.Category, body.Message)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to create ticket"})
 	}
 
-	// Add the initial message as a reply
-	_, err = pool.Exec(ctx,
-		`INSERT INTO support_replies (ticket_id, sender_type, sender_id, message) VALUES ($1, 'user', $2, $3)`,
-		ticketID, userID, body.Message,
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "ticket created but failed to save message"})
-	}
-
 	return c.JSON(fiber.Map{"ticket_id": ticketID})
 }
 
 // GET /api/support/tickets — list my tickets
 func (h *Handler) ListSupportTickets(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	pool := h.slimeRepo.Pool()
-	ctx := c.Context()
 
-	rows, err := pool.Query(ctx,
-		`SELECT id, subject, category, status, priority, created_at, updated_at
-		 FROM support_tickets WHERE user_id = $1 ORDER BY updated_at DESC LIMIT 20`,
-		userID,
-	)
+	rows, err := h.supportRepo.Tickets(c.Context(), userID, 20)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list tickets"})
 	}
-	defer rows.Close()
 
 	type Ticket struct {
 		ID        int64  `json:"id"`
@@ -78,15 +58,17 @@ func (h *Handler) ListSupportTickets(c *fiber.Ctx) error {
 		UpdatedAt string `json:"updated_at"`
 	}
 
-	tickets := make([]Ticket, 0)
-	for rows.Next() {
-		var t Ticket
-		var ca, ua time.Time
-		if rows.Scan(&t.ID, &t.Subject, &t.Category, &t.Status, &t.Priority, &ca, &ua) == nil {
-			t.CreatedAt = ca.Format(time.RFC3339)
-			t.UpdatedAt = ua.Format(time.RFC3339)
-			tickets = append(tickets, t)
-		}
+	tickets := make([]Ticket, 0, len(rows))
+	for _, t := range rows {
+		tickets = append(tickets, Ticket{
+			ID:        t.ID,
+			Subject:   t.Subject,
+			Category:  t.Category,
+			Status:    t.Status,
+			Priority:  t.Priority,
+			CreatedAt: t.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: t.UpdatedAt.Format(time.RFC3339),
+		})
 	}
 
 	return c.JSON(fiber.Map{"tickets": tickets})
@@ -96,28 +78,24 @@ func (h *Handler) ListSupportTickets(c *fiber.Ctx) error {
 func (h *Handler) GetSupportTicketDetail(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ticketID := c.Params("id")
-	pool := h.slimeRepo.Pool()
 	ctx := c.Context()
 
 	// Verify ownership
-	var ownerID string
-	err := pool.QueryRow(ctx, `SELECT user_id FROM support_tickets WHERE id = $1`, ticketID).Scan(&ownerID)
-	if err != nil {
+	ownerID, err := h.supportRepo.Owner(ctx, ticketID)
+	if errors.Is(err, repository.ErrTicketNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ticket not found"})
 	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch ticket"})
+	}
 	if ownerID != userID {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not your ticket"})
 	}
 
-	// Get replies
-	rows, err := pool.Query(ctx,
-		`SELECT sender_type, message, created_at FROM support_replies WHERE ticket_id = $1 ORDER BY created_at ASC`,
-		ticketID,
-	)
+	rows, err := h.supportRepo.Replies(ctx, ticketID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch replies"})
 	}
-	defer rows.Close()
 
 	type Reply struct {
 		SenderType string `json:"sender_type"`
@@ -125,14 +103,13 @@ func (h *Handler) GetSupportTicketDetail(c *fiber.Ctx) error {
 		CreatedAt  string `json:"created_at"`
 	}
 
-	replies := make([]Reply, 0)
-	for rows.Next() {
-		var r Reply
-		var ca time.Time
-		if rows.Scan(&r.SenderType, &r.Message, &ca) == nil {
-			r.CreatedAt = ca.Format(time.RFC3339)
-			replies = append(replies, r)
-		}
+	replies := make([]Reply, 0, len(rows))
+	for _, r := range rows {
+		replies = append(replies, Reply{
+			SenderType: r.SenderType,
+			Message:    r.Message,
+			CreatedAt:  r.CreatedAt.Format(time.RFC3339),
+		})
 	}
 
 	return c.JSON(fiber.Map{"replies": replies})
@@ -142,7 +119,6 @@ func (h *Handler) GetSupportTicketDetail(c *fiber.Ctx) error {
 func (h *Handler) ReplySupportTicket(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	ticketID := c.Params("id")
-	pool := h.slimeRepo.Pool()
 	ctx := c.Context()
 
 	var body struct {
@@ -156,26 +132,20 @@ func (h *Handler) ReplySupportTicket(c *fiber.Ctx) error {
 	}
 
 	// Verify ownership
-	var ownerID string
-	err := pool.QueryRow(ctx, `SELECT user_id FROM support_tickets WHERE id = $1`, ticketID).Scan(&ownerID)
-	if err != nil {
+	ownerID, err := h.supportRepo.Owner(ctx, ticketID)
+	if errors.Is(err, repository.ErrTicketNotFound) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ticket not found"})
 	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch ticket"})
+	}
 	if ownerID != userID {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not your ticket"})
 	}
 
-	_, err = pool.Exec(ctx,
-		`INSERT INTO support_replies (ticket_id, sender_type, sender_id, message) VALUES ($1, 'user', $2, $3)`,
-		ticketID, userID, body.Message,
-	)
-	if err != nil {
+	if err := h.supportRepo.Reply(ctx, ticketID, userID, body.Message); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to save reply"})
 	}
-	pool.Exec(ctx,
-		`UPDATE support_tickets SET updated_at = NOW() WHERE id = $1`,
-		ticketID,
-	)
 
 	return c.JSON(fiber.Map{"ok": true})
 }
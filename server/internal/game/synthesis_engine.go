@@ -3,10 +3,9 @@ package game
 import (
 	"context"
 	"encoding/json"
-	"math/rand"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 // ===== Material Data Structures =====
@@ -91,6 +90,19 @@ var gradeRank = map[string]int{
 	"common": 0, "uncommon": 1, "rare": 2, "epic": 3, "legendary": 4, "mythic": 5,
 }
 
+// GradeRank orders grades from common (0) to mythic (5).
+func GradeRank(grade string) int {
+	return gradeRank[grade]
+}
+
+// HiddenSpeciesFrom is the first hidden species ID. Hidden species only come from
+// mutation recipes, never from random picks.
+const HiddenSpeciesFrom = 777
+
+// BaseMutationChance is the chance a matching mutation recipe fires, before the
+// material's mutation boost.
+const BaseMutationChance = 0.3
+
 var gradeByRank = []string{"common", "uncommon", "rare", "epic", "legendary", "mythic"}
 
 // FindMaterial looks up a material by ID from the database.
@@ -99,6 +111,11 @@ func (h *Handler) FindMaterial(id int) *Material {
 	if err != nil {
 		return nil
 	}
+	return NewMaterial(m)
+}
+
+// NewMaterial converts a stored material, decoding its effects.
+func NewMaterial(m *repository.GameMaterial) *Material {
 	var effects MaterialEffect
 	json.Unmarshal(m.Effects, &effects)
 	return &Material{
@@ -108,21 +125,102 @@ func (h *Handler) FindMaterial(id int) *Material {
 	}
 }
 
+// ===== Synthesis Data =====
+
+// SynthesisData is the game data the synthesis rules read: the species table, the
+// combination recipes and the mutation recipes (checked in order).
+type SynthesisData struct {
+	Species   []models.SlimeSpecies
+	Recipes   []Recipe
+	Mutations []MutationRecipe
+
+	byID map[int]models.SlimeSpecies
+}
+
+// NewSynthesisData builds synthesis data from stored recipes.
+func NewSynthesisData(species []models.SlimeSpecies, recipes []repository.GameRecipe, mutations []repository.GameMutationRecipe) *SynthesisData {
+	d := &SynthesisData{Species: species, Mutations: mutationRecipes(mutations)}
+	for _, rec := range recipes {
+		d.Recipes = append(d.Recipes, Recipe{
+			ID: rec.ID, InputA: rec.InputA, InputB: rec.InputB,
+			Output: rec.Output, OutputName: rec.OutputName,
+			Type: rec.Type, Hint: rec.Hint, Hidden: rec.Hidden,
+		})
+	}
+	return d
+}
+
+func mutationRecipes(dbMutations []repository.GameMutationRecipe) []MutationRecipe {
+	mutations := make([]MutationRecipe, 0, len(dbMutations))
+	for _, dbMR := range dbMutations {
+		mutations = append(mutations, MutationRecipe{
+			RequiredElementA:   dbMR.RequiredElementA,
+			RequiredElementB:   dbMR.RequiredElementB,
+			RequiredMaterial:   dbMR.RequiredMaterial,
+			ResultSpeciesID:    dbMR.ResultSpeciesID,
+			MinCollectionScore: dbMR.MinCollectionScore,
+		})
+	}
+	return mutations
+}
+
+// speciesByID indexes the species table on first use.
+func (d *SynthesisData) speciesByID() map[int]models.SlimeSpecies {
+	if d.byID == nil {
+		d.byID = make(map[int]models.SlimeSpecies, len(d.Species))
+		for _, sp := range d.Species {
+			d.byID[sp.ID] = sp
+		}
+	}
+	return d.byID
+}
+
+// recipeFor finds the combination recipe for two species in either order.
+func (d *SynthesisData) recipeFor(speciesA, speciesB int) *Recipe {
+	for i, r := range d.Recipes {
+		if (r.InputA == speciesA && r.InputB == speciesB) || (r.InputA == speciesB && r.InputB == speciesA) {
+			return &d.Recipes[i]
+		}
+	}
+	return nil
+}
+
 // ===== Core Synthesis Engine =====
 
+// Synthesize merges two slimes against live game data.
 func Synthesize(
 	ctx context.Context,
 	h *Handler,
+	rng Rand,
 	slimeA, slimeB models.Slime,
 	material *Material,
 	collectionScore int,
 ) SynthesisResult {
 	allSpecies, _ := h.slimeRepo.GetAllSpecies(ctx)
-	speciesMap := make(map[int]models.SlimeSpecies)
-	for _, sp := range allSpecies {
-		speciesMap[sp.ID] = sp
+	data := &SynthesisData{Species: allSpecies}
+
+	// Mutation recipes only matter when a material is used
+	if material != nil {
+		dbMutations, _ := h.gameDataRepo.GetAllMutationRecipes(ctx)
+		data.Mutations = mutationRecipes(dbMutations)
+	}
+	if recipe := h.findRecipe(slimeA.SpeciesID, slimeB.SpeciesID); recipe != nil {
+		data.Recipes = []Recipe{*recipe}
 	}
 
+	return SynthesizeWith(rng, data, slimeA, slimeB, material, collectionScore)
+}
+
+// SynthesizeWith runs the synthesis rules on the given data and randomness.
+func SynthesizeWith(
+	rng Rand,
+	data *SynthesisData,
+	slimeA, slimeB models.Slime,
+	material *Material,
+	collectionScore int,
+) SynthesisResult {
+	speciesMap := data.speciesByID()
+
 	spA := speciesMap[slimeA.SpeciesID]
 	spB := speciesMap[slimeB.SpeciesID]
 
@@ -132,19 +230,11 @@ func Synthesize(
 		models.PersonalityFoodie, models.PersonalityCurious,
 		models.PersonalityTsundere, models.PersonalityGentle,
 	}
-	personality := personalities[rand.Intn(len(personalities))]
+	personality := personalities[rng.Intn(len(personalities))]
 
-	// 1. Check mutation recipes from DB (only if material provided)
+	// 1. Check mutation recipes (only if material provided)
 	if material != nil {
-		dbMutations, _ := h.gameDataRepo.GetAllMutationRecipes(ctx)
-		for _, dbMR := range dbMutations {
-			mr := MutationRecipe{
-				RequiredElementA:   dbMR.RequiredElementA,
-				RequiredElementB:   dbMR.RequiredElementB,
-				RequiredMaterial:   dbMR.RequiredMaterial,
-				ResultSpeciesID:    dbMR.ResultSpeciesID,
-				MinCollectionScore: dbMR.MinCollectionScore,
-			}
+		for _, mr := range data.Mutations {
 			if !matchesMutation(slimeA, slimeB, material.ID, mr) {
 				continue
 			}
@@ -152,8 +242,8 @@ func Synthesize(
 				continue
 			}
 			// Roll for mutation
-			mutChance := 0.3 + material.Effects.MutationBoost
-			if rand.Float64() < mutChance {
+			mutChance := BaseMutationChance + material.Effects.MutationBoost
+			if rng.Float64() < mutChance {
 				sp, ok := speciesMap[mr.ResultSpeciesID]
 				if ok {
 					return SynthesisResult{
@@ -169,7 +259,7 @@ func Synthesize(
 	}
 
 	// 2. Check regular recipes
-	recipe := h.findRecipe(slimeA.SpeciesID, slimeB.SpeciesID)
+	recipe := data.recipeFor(slimeA.SpeciesID, slimeB.SpeciesID)
 	if recipe != nil {
 		result := SynthesisResult{
 			SpeciesID:   recipe.Output,
@@ -183,12 +273,12 @@ func Synthesize(
 
 		// Material can cause "great success" → upgrade grade of result
 		if material != nil && material.Effects.GreatSuccess > 0 {
-			if rand.Float64() < material.Effects.GreatSuccess {
+			if rng.Float64() < material.Effects.GreatSuccess {
 				result.IsGreatSuccess = true
 			}
 		}
 		if material != nil && material.Effects.GradeBoost > 0 {
-			if rand.Float64() < material.Effects.GradeBoost {
+			if rng.Float64() < material.Effects.GradeBoost {
 				result.IsGreatSuccess = true
 			}
 		}
@@ -209,7 +299,7 @@ func Synthesize(
 		}
 		// Material grade boost can cause great success
 		if material != nil && material.Effects.GradeBoost > 0 {
-			if rand.Float64() < material.Effects.GradeBoost {
+			if rng.Float64() < material.Effects.GradeBoost {
 				result.IsGreatSuccess = true
 			}
 		}
@@ -218,7 +308,7 @@ func Synthesize(
 
 	// 4. Catalyzed synthesis (material required, different species without recipe)
 	if material != nil {
-		return catalyzedSynthesis(spA, spB, material, allSpecies, personality)
+		return catalyzedSynthesis(rng, spA, spB, material, data.Species, personality)
 	}
 
 	// 5. No valid merge
@@ -236,16 +326,17 @@ func matchesMutation(a, b models.Slime, materialID int, mr MutationRecipe) bool
 
 // catalyzedSynthesis: free-form synthesis using material to influence result
 func catalyzedSynthesis(
+	rng Rand,
 	spA, spB models.SlimeSpecies,
 	material *Material,
 	allSpecies []models.SlimeSpecies,
 	personality string,
 ) SynthesisResult {
 	// Determine target element
-	targetElement := pickTargetElement(spA, spB, material)
+	targetElement := pickTargetElement(rng, spA, spB, material)
 
 	// Determine target grade
-	targetGrade := pickTargetGrade(spA, spB, material)
+	targetGrade := pickTargetGrade(rng, spA, spB, material)
 
 	// Find candidates matching element + grade
 	candidates := filterSpecies(allSpecies, targetElement, targetGrade)
@@ -283,7 +374,7 @@ func catalyzedSynthesis(
 		candidates = allSpecies
 	}
 
-	chosen := candidates[rand.Intn(len(candidates))]
+	chosen := candidates[rng.Intn(len(candidates))]
 
 	result := SynthesisResult{
 		SpeciesID:   chosen.ID,
@@ -293,31 +384,31 @@ func catalyzedSynthesis(
 	}
 
 	// Great success check
-	if material.Effects.GreatSuccess > 0 && rand.Float64() < material.Effects.GreatSuccess {
+	if material.Effects.GreatSuccess > 0 && rng.Float64() < material.Effects.GreatSuccess {
 		result.IsGreatSuccess = true
 	}
-	if material.Effects.GradeBoost > 0 && rand.Float64() < material.Effects.GradeBoost {
+	if material.Effects.GradeBoost > 0 && rng.Float64() < material.Effects.GradeBoost {
 		result.IsGreatSuccess = true
 	}
 
 	return result
 }
 
-func pickTargetElement(spA, spB models.SlimeSpecies, material *Material) string {
+func pickTargetElement(rng Rand, spA, spB models.SlimeSpecies, material *Material) string {
 	// If material has element boost, bias towards that element
 	if material.Effects.ElementBoost != nil {
-		if rand.Float64() < material.Effects.ElementBoost.Chance {
+		if rng.Float64() < material.Effects.ElementBoost.Chance {
 			return material.Effects.ElementBoost.Element
 		}
 	}
 	// Otherwise random between parent elements
-	if rand.Float64() < 0.5 {
+	if rng.Float64() < 0.5 {
 		return spA.Element
 	}
 	return spB.Element
 }
 
-func pickTargetGrade(spA, spB models.SlimeSpecies, material *Material) string {
+func pickTargetGrade(rng Rand, spA, spB models.SlimeSpecies, material *Material) string {
 	// Base grade = lower of two parents
 	grA := gradeRank[spA.Grade]
 	grB := gradeRank[spB.Grade]
@@ -328,7 +419,7 @@ func pickTargetGrade(spA, spB models.SlimeSpecies, material *Material) string {
 
 	// Material grade boost can push up
 	if material.Effects.GradeBoost > 0 {
-		if rand.Float64() < material.Effects.GradeBoost {
+		if rng.Float64() < material.Effects.GradeBoost {
 			baseGR++
 		}
 	}
@@ -343,7 +434,7 @@ func filterSpecies(all []models.SlimeSpecies, element, grade string) []models.Sl
 	var result []models.SlimeSpecies
 	for _, sp := range all {
 		// Exclude hidden slimes from random selection
-		if sp.ID >= 777 {
+		if sp.ID >= HiddenSpeciesFrom {
 			continue
 		}
 		if sp.Element == element && sp.Grade == grade {
@@ -355,31 +446,24 @@ func filterSpecies(all []models.SlimeSpecies, element, grade string) []models.Sl
 
 // ===== Collection Score Calculation =====
 
-func (h *Handler) CalculateCollectionScore(ctx context.Context, pool *pgxpool.Pool, userID string) (speciesPoints int, setBonus int, firstDiscoveryBonus int, total int) {
+func (h *Handler) CalculateCollectionScore(ctx context.Context, userID string) (speciesPoints int, setBonus int, firstDiscoveryBonus int, total int) {
 	// 1. Species grade points
-	rows, err := pool.Query(ctx, `
-		SELECT ss.grade FROM codex_entries ce
-		JOIN slime_species ss ON ss.id = ce.species_id
-		WHERE ce.user_id = $1
-	`, userID)
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var grade string
-			if rows.Scan(&grade) == nil {
-				speciesPoints += gradeScore[grade]
-			}
-		}
+	grades, _ := h.slimeRepo.CodexGrades(ctx, userID)
+	for _, grade := range grades {
+		speciesPoints += gradeScore[grade]
 	}
 
 	// 2. Set bonuses (loaded from DB)
+	codex, _ := h.slimeRepo.GetCodex(ctx, userID)
+	inCodex := make(map[int]bool, len(codex))
+	for _, sid := range codex {
+		inCodex[sid] = true
+	}
 	dbSets, _ := h.gameDataRepo.GetAllSets(ctx)
 	for _, set := range dbSets {
 		completed := 0
 		for _, sid := range set.SpeciesIDs {
-			var exists bool
-			pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM codex_entries WHERE user_id = $1 AND species_id = $2)`, userID, sid).Scan(&exists)
-			if exists {
+			if inCodex[sid] {
 				completed++
 			}
 		}
@@ -389,44 +473,9 @@ func (h *Handler) CalculateCollectionScore(ctx context.Context, pool *pgxpool.Po
 	}
 
 	// 3. First discoverer bonuses (50 points each)
-	var fdCount int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM first_discoveries WHERE user_id = $1`, userID).Scan(&fdCount)
+	fdCount, _ := h.slimeRepo.CountFirstDiscoveries(ctx, userID)
 	firstDiscoveryBonus = fdCount * 50
 
 	total = speciesPoints + setBonus + firstDiscoveryBonus
 	return
 }
-
-// TryFirstDiscovery attempts to record a first discovery. Returns discoverer nickname if newly recorded.
-func TryFirstDiscovery(ctx context.Context, pool *pgxpool.Pool, userID string, speciesID int, nickname string) bool {
-	tag, err := pool.Exec(ctx, `
-		INSERT INTO first_discoveries (species_id, user_id, nickname)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (species_id) DO NOTHING
-	`, speciesID, userID, nickname)
-	if err != nil {
-		return false
-	}
-	return tag.RowsAffected() > 0
-}
-
-// ConsumeMaterial checks and consumes 1 unit of a material from user inventory
-func ConsumeMaterial(ctx context.Context, pool *pgxpool.Pool, userID string, materialID int) bool {
-	tag, err := pool.Exec(ctx, `
-		UPDATE user_materials SET quantity = quantity - 1
-		WHERE user_id = $1 AND material_id = $2 AND quantity > 0
-	`, userID, materialID)
-	if err != nil {
-		return false
-	}
-	return tag.RowsAffected() > 0
-}
-
-// AddMaterial adds quantity of a material to user inventory
-func AddMaterial(ctx context.Context, pool *pgxpool.Pool, userID string, materialID, qty int) {
-	pool.Exec(ctx, `
-		INSERT INTO user_materials (user_id, material_id, quantity)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (user_id, material_id) DO UPDATE SET quantity = user_materials.quantity + $3
-	`, userID, materialID, qty)
-}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+var testSpecies = []models.SlimeSpecies{
+	{ID: 1, Element: "water", Grade: "common"},
+	{ID: 2, Element: "fire", Grade: "common"},
+	{ID: 3, Element: "water", Grade: "uncommon"},
+	{ID: 4, Element: "fire", Grade: "uncommon"},
+	{ID: 5, Element: "water", Grade: "mythic"},
+	{ID: 7, Element: "grass", Grade: "uncommon"},
+	{ID: 8, Element: "grass", Grade: "common"},
+	{ID: 800, Element: "grass", Grade: "uncommon"}, // hidden: mutation only
+}
+
+// mutagen mutates water+fire into species 800 with a 50% chance.
+var mutagen = &Material{ID: 1, Effects: MaterialEffect{MutationBoost: 0.2, GreatSuccess: 0.25}}
+
+// seedling pulls catalyzed merges towards grass and one grade up.
+var seedling = &Material{ID: 2, Effects: MaterialEffect{
+	ElementBoost: &ElementBoost{Element: "grass", Chance: 1},
+	GradeBoost:   0.5,
+}}
+
+func testSynthesisData() *SynthesisData {
+	return NewSynthesisData(testSpecies,
+		[]repository.GameRecipe{{ID: 1, InputA: 1, InputB: 2, Output: 3}},
+		[]repository.GameMutationRecipe{
+			{RequiredElementA: "water", RequiredElementB: "fire", RequiredMaterial: 1, ResultSpeciesID: 800, MinCollectionScore: 100},
+		},
+	)
+}
+
+func slimeOf(speciesID int) models.Slime {
+	for _, sp := range testSpecies {
+		if sp.ID == speciesID {
+			return models.Slime{SpeciesID: sp.ID, Element: sp.Element}
+		}
+	}
+	return models.Slime{SpeciesID: speciesID}
+}
+
+func TestSynthesizeWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     int
+		material *Material
+		score    int
+		floats   []float64
+		want     SynthesisResult
+	}{
+		{
+			name: "combination",
+			a:    1, b: 2,
+			want: SynthesisResult{SpeciesID: 3, Element: "water", MergeType: "combination"},
+		},
+		{
+			name: "combination in either order",
+			a:    2, b: 1,
+			want: SynthesisResult{SpeciesID: 3, Element: "water", MergeType: "combination"},
+		},
+		{
+			name: "combination great success after missed mutation",
+			a:    1, b: 2, material: mutagen, score: 100,
+			floats: []float64{0.9, 0.1},
+			want:   SynthesisResult{SpeciesID: 3, Element: "water", MergeType: "combination", IsGreatSuccess: true},
+		},
+		{
+			name: "upgrade",
+			a:    1, b: 1,
+			want: SynthesisResult{SpeciesID: 1, Element: "water", MergeType: "upgrade"},
+		},
+		{
+			name: "upgrade at max grade",
+			a:    5, b: 5,
+			want: SynthesisResult{Error: "already at maximum grade"},
+		},
+		{
+			name: "no recipe and no material",
+			a:    1, b: 4,
+			want: SynthesisResult{Error: "no valid merge: same species for upgrade or valid recipe for combination required"},
+		},
+		{
+			name: "mutation hit",
+			a:    1, b: 4, material: mutagen, score: 100,
+			floats: []float64{0.49},
+			want:   SynthesisResult{SpeciesID: 800, Element: "grass", MergeType: "mutation", IsMutation: true},
+		},
+		{
+			name: "mutation miss falls back to catalyzed",
+			a:    1, b: 4, material: mutagen, score: 100,
+			floats: []float64{0.5, 0.1, 0.9},
+			want:   SynthesisResult{SpeciesID: 1, Element: "water", MergeType: "catalyzed"},
+		},
+		{
+			name: "mutation locked below collection score",
+			a:    1, b: 4, material: mutagen, score: 99,
+			floats: []float64{0.9, 0.9},
+			want:   SynthesisResult{SpeciesID: 2, Element: "fire", MergeType: "catalyzed"},
+		},
+		{
+			name: "catalyzed element and grade boost skip hidden species",
+			a:    1, b: 4, material: seedling,
+			floats: []float64{0, 0.2, 0.9},
+			want:   SynthesisResult{SpeciesID: 7, Element: "grass", MergeType: "catalyzed"},
+		},
+		{
+			name: "catalyzed great success from grade boost",
+			a:    1, b: 4, material: seedling,
+			floats: []float64{0, 0.2, 0.1},
+			want:   SynthesisResult{SpeciesID: 7, Element: "grass", MergeType: "catalyzed", IsGreatSuccess: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := &scriptedRand{floats: tt.floats}
+			got := SynthesizeWith(rng, testSynthesisData(), slimeOf(tt.a), slimeOf(tt.b), tt.material, tt.score)
+			if tt.want.Error == "" {
+				tt.want.Personality = models.PersonalityEnergetic
+			}
+			if got != tt.want {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSynthesizeWithIsReproducible(t *testing.T) {
+	data := testSynthesisData()
+	for seed := int64(1); seed <= 20; seed++ {
+		first := SynthesizeWith(NewRand(seed), data, slimeOf(1), slimeOf(4), mutagen, 100)
+		again := SynthesizeWith(NewRand(seed), data, slimeOf(1), slimeOf(4), mutagen, 100)
+		if first != again {
+			t.Fatalf("seed %d: %+v != %+v", seed, first, again)
+		}
+	}
+}
@@ -2,31 +2,32 @@ package game
 
 import (
 	"context"
-	"math/rand"
+	"encoding/json"
+	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/slimetopia/server/internal/models"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 // ===== Talent (IV) System =====
 
 // InheritTalents creates child talents from two parent slimes.
 // Each stat: 50% chance from parent A, 50% from parent B, then ±3 mutation.
-func InheritTalents(a, b models.Slime) [6]int {
+func InheritTalents(rng Rand, a, b models.Slime) [6]int {
 	parentA := [6]int{a.TalentStr, a.TalentVit, a.TalentSpd, a.TalentInt, a.TalentCha, a.TalentLck}
 	parentB := [6]int{b.TalentStr, b.TalentVit, b.TalentSpd, b.TalentInt, b.TalentCha, b.TalentLck}
 
 	var child [6]int
 	for i := 0; i < 6; i++ {
 		// Pick from one parent
-		if rand.Float64() < 0.5 {
+		if rng.Float64() < 0.5 {
 			child[i] = parentA[i]
 		} else {
 			child[i] = parentB[i]
 		}
 		// Mutation: ±3 random
-		child[i] += rand.Intn(7) - 3
+		child[i] += rng.Intn(7) - 3
 		if child[i] < 0 {
 			child[i] = 0
 		}
@@ -80,15 +81,7 @@ func (h *Handler) GetAwakeningCost(c *fiber.Ctx) error {
 	}
 
 	nextStar := slime.StarLevel + 1
-	pool := h.slimeRepo.Pool()
-
-	var goldCost, stardustCost, materialQty int
-	var materialID *int
-	err = pool.QueryRow(ctx,
-		`SELECT gold_cost, stardust_cost, material_id, material_qty
-		 FROM game_awakening_costs WHERE grade = $1 AND star_level = $2`,
-		species.Grade, nextStar,
-	).Scan(&goldCost, &stardustCost, &materialID, &materialQty)
+	cost, err := h.gameDataRepo.GetAwakeningCost(ctx, species.Grade, nextStar)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "cost data not found"})
 	}
@@ -96,10 +89,10 @@ func (h *Handler) GetAwakeningCost(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"star_level":    slime.StarLevel,
 		"next_star":     nextStar,
-		"gold_cost":     goldCost,
-		"stardust_cost": stardustCost,
-		"material_id":   materialID,
-		"material_qty":  materialQty,
+		"gold_cost":     cost.GoldCost,
+		"stardust_cost": cost.StardustCost,
+		"material_id":   cost.MaterialID,
+		"material_qty":  cost.MaterialQty,
 	})
 }
 
@@ -108,7 +101,6 @@ func (h *Handler) AwakenSlime(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	slimeID := c.Params("id")
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	slime, err := h.slimeRepo.FindByID(ctx, slimeID)
 	if err != nil {
@@ -127,16 +119,11 @@ func (h *Handler) AwakenSlime(c *fiber.Ctx) error {
 	}
 
 	nextStar := slime.StarLevel + 1
-	var goldCost, stardustCost, materialQty int
-	var materialID *int
-	err = pool.QueryRow(ctx,
-		`SELECT gold_cost, stardust_cost, material_id, material_qty
-		 FROM game_awakening_costs WHERE grade = $1 AND star_level = $2`,
-		species.Grade, nextStar,
-	).Scan(&goldCost, &stardustCost, &materialID, &materialQty)
+	cost, err := h.gameDataRepo.GetAwakeningCost(ctx, species.Grade, nextStar)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cost data not found"})
 	}
+	goldCost, stardustCost := cost.GoldCost, cost.StardustCost
 
 	// Check user currency
 	user, err := h.userRepo.FindByID(ctx, userID)
@@ -148,47 +135,35 @@ func (h *Handler) AwakenSlime(c *fiber.Ctx) error {
 	}
 
 	// Check stardust
-	var stardust int
-	pool.QueryRow(ctx, `SELECT COALESCE(stardust, 0) FROM users WHERE id = $1`, userID).Scan(&stardust)
-	if stardust < stardustCost {
+	if user.Stardust < stardustCost {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_stardust"})
 	}
 
-	// Check material if needed
-	if materialID != nil && materialQty > 0 {
-		var matQty int
-		pool.QueryRow(ctx,
-			`SELECT COALESCE(quantity, 0) FROM user_materials WHERE user_id = $1 AND material_id = $2`,
-			userID, *materialID,
-		).Scan(&matQty)
-		if matQty < materialQty {
+	// Consume material if needed
+	if cost.MaterialID != nil && cost.MaterialQty > 0 {
+		err := h.userRepo.ConsumeMaterials(ctx, userID, []repository.MaterialStack{{MaterialID: *cost.MaterialID, Quantity: cost.MaterialQty}})
+		if errors.Is(err, repository.ErrNotEnoughMaterials) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_material"})
 		}
-		// Consume material
-		pool.Exec(ctx,
-			`UPDATE user_materials SET quantity = quantity - $1 WHERE user_id = $2 AND material_id = $3`,
-			materialQty, userID, *materialID,
-		)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to use material"})
+		}
 	}
 
 	// Deduct gold + stardust
-	pool.Exec(ctx, `UPDATE users SET gold = gold - $1 WHERE id = $2`, goldCost, userID)
-	pool.Exec(ctx, `UPDATE users SET stardust = COALESCE(stardust, 0) - $1 WHERE id = $2`, stardustCost, userID)
+	if err := h.userRepo.SpendCurrency(ctx, userID, int64(goldCost), 0, stardustCost, repository.ReasonAwakening, slimeID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "insufficient_gold"})
+	}
 
 	// Apply awakening: star_level++ and bonus talent growth
-	talentBoost := rand.Intn(3) + 1 // 1~3 bonus to random talent
-	talentStats := []string{"talent_str", "talent_vit", "talent_spd", "talent_int", "talent_cha", "talent_lck"}
-	boostedStat := talentStats[rand.Intn(6)]
-
-	pool.Exec(ctx,
-		`UPDATE slimes SET star_level = star_level + 1,
-		 `+boostedStat+` = LEAST(`+boostedStat+` + $1, 31),
-		 updated_at = NOW() WHERE id = $2`,
-		talentBoost, slimeID,
-	)
-
-	LogGameAction(pool, userID, "awaken", "slime", int64(goldCost), 0, 0, map[string]interface{}{
-		"slime_id": slimeID, "star_level": nextStar, "talent_boost": boostedStat, "boost_amount": talentBoost,
+	rng, seed := h.newRand()
+	talentBoost := rng.Intn(3) + 1 // 1~3 bonus to random talent
+	boostedStat := repository.TalentColumns[rng.Intn(6)]
+
+	h.slimeRepo.Awaken(ctx, slimeID, boostedStat, talentBoost)
+
+	LogGameAction(h.gameLogRepo, userID, "awaken", "slime", int64(goldCost), 0, 0, map[string]interface{}{
+		"slime_id": slimeID, "star_level": nextStar, "talent_boost": boostedStat, "boost_amount": talentBoost, "seed": seed,
 	})
 
 	return c.JSON(fiber.Map{
@@ -207,49 +182,18 @@ func (h *Handler) AwakenSlime(c *fiber.Ctx) error {
 func (h *Handler) GetSlimeSkills(c *fiber.Ctx) error {
 	slimeID := c.Params("id")
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
-
-	rows, err := pool.Query(ctx,
-		`SELECT ss.slot, gs.id, gs.name, gs.name_en, gs.description, gs.icon,
-		        gs.skill_type, gs.element_affinity, gs.grade_req, gs.level_req, gs.effect,
-		        ss.inherited
-		 FROM slime_skills ss
-		 JOIN game_skills gs ON gs.id = ss.skill_id
-		 WHERE ss.slime_id = $1
-		 ORDER BY ss.slot`,
-		slimeID,
-	)
+
+	learned, err := h.slimeRepo.LearnedSkills(ctx, slimeID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch skills"})
 	}
-	defer rows.Close()
 
-	skills := make([]fiber.Map, 0)
-	for rows.Next() {
-		var slot, skillID, levelReq int
-		var name, nameEN, desc, icon, skillType, gradeReq string
-		var elementAffinity *string
-		var effect []byte
-		var inherited bool
-
-		if err := rows.Scan(&slot, &skillID, &name, &nameEN, &desc, &icon, &skillType,
-			&elementAffinity, &gradeReq, &levelReq, &effect, &inherited); err != nil {
-			continue
-		}
-		skills = append(skills, fiber.Map{
-			"slot":             slot,
-			"skill_id":         skillID,
-			"name":             name,
-			"name_en":          nameEN,
-			"description":      desc,
-			"icon":             icon,
-			"skill_type":       skillType,
-			"element_affinity": elementAffinity,
-			"grade_req":        gradeReq,
-			"level_req":        levelReq,
-			"effect":           string(effect),
-			"inherited":        inherited,
-		})
+	skills := make([]fiber.Map, 0, len(learned))
+	for _, l := range learned {
+		m := skillJSON(&l.Skill)
+		m["slot"] = l.Slot
+		m["inherited"] = l.Inherited
+		skills = append(skills, m)
 	}
 
 	// Also get available (learnable) skills for this slime's species
@@ -258,51 +202,39 @@ func (h *Handler) GetSlimeSkills(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"skills": skills, "available": []fiber.Map{}})
 	}
 
-	availableRows, err := pool.Query(ctx,
-		`SELECT gss.slot, gss.learn_level, gs.id, gs.name, gs.name_en, gs.description, gs.icon,
-		        gs.skill_type, gs.element_affinity, gs.grade_req, gs.level_req, gs.effect
-		 FROM game_species_skills gss
-		 JOIN game_skills gs ON gs.id = gss.skill_id
-		 WHERE gss.species_id = $1
-		 ORDER BY gss.slot`,
-		slime.SpeciesID,
-	)
+	learnable, err := h.slimeRepo.SpeciesSkills(ctx, slime.SpeciesID)
 	if err != nil {
 		return c.JSON(fiber.Map{"skills": skills, "available": []fiber.Map{}})
 	}
-	defer availableRows.Close()
-
-	available := make([]fiber.Map, 0)
-	for availableRows.Next() {
-		var slot, learnLevel, skillID, levelReq int
-		var name, nameEN, desc, icon, skillType, gradeReq string
-		var elementAffinity *string
-		var effect []byte
 
-		if err := availableRows.Scan(&slot, &learnLevel, &skillID, &name, &nameEN, &desc, &icon,
-			&skillType, &elementAffinity, &gradeReq, &levelReq, &effect); err != nil {
-			continue
-		}
-		available = append(available, fiber.Map{
-			"slot":             slot,
-			"learn_level":      learnLevel,
-			"skill_id":         skillID,
-			"name":             name,
-			"name_en":          nameEN,
-			"description":      desc,
-			"icon":             icon,
-			"skill_type":       skillType,
-			"element_affinity": elementAffinity,
-			"grade_req":        gradeReq,
-			"level_req":        levelReq,
-			"effect":           string(effect),
-			"can_learn":        slime.Level >= learnLevel,
-		})
+	available := make([]fiber.Map, 0, len(learnable))
+	for _, s := range learnable {
+		m := skillJSON(&s.Skill)
+		m["slot"] = s.Slot
+		m["learn_level"] = s.LearnLevel
+		m["can_learn"] = slime.Level >= s.LearnLevel
+		available = append(available, m)
 	}
 
 	return c.JSON(fiber.Map{"skills": skills, "available": available})
 }
 
+// skillJSON is the part of a skill's JSON that learned and learnable skills share.
+func skillJSON(s *repository.GameSkill) fiber.Map {
+	return fiber.Map{
+		"skill_id":         s.ID,
+		"name":             s.Name,
+		"name_en":          s.NameEN,
+		"description":      s.Description,
+		"icon":             s.Icon,
+		"skill_type":       s.SkillType,
+		"element_affinity": s.ElementAffinity,
+		"grade_req":        s.GradeReq,
+		"level_req":        s.LevelReq,
+		"effect":           string(s.Effect),
+	}
+}
+
 // LearnSkill learns a skill for a slime (from species skill pool)
 func (h *Handler) LearnSkill(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
@@ -317,7 +249,6 @@ func (h *Handler) LearnSkill(c *fiber.Ctx) error {
 	}
 
 	ctx := c.Context()
-	pool := h.slimeRepo.Pool()
 
 	slime, err := h.slimeRepo.FindByID(ctx, slimeID)
 	if err != nil {
@@ -328,11 +259,7 @@ func (h *Handler) LearnSkill(c *fiber.Ctx) error {
 	}
 
 	// Check if species can learn this skill
-	var learnLevel int
-	err = pool.QueryRow(ctx,
-		`SELECT learn_level FROM game_species_skills WHERE species_id = $1 AND skill_id = $2`,
-		slime.SpeciesID, body.SkillID,
-	).Scan(&learnLevel)
+	learnLevel, err := h.slimeRepo.SkillLearnLevel(ctx, slime.SpeciesID, body.SkillID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "skill not available for this species"})
 	}
@@ -341,13 +268,7 @@ func (h *Handler) LearnSkill(c *fiber.Ctx) error {
 	}
 
 	// Upsert: replace existing skill in that slot
-	_, err = pool.Exec(ctx,
-		`INSERT INTO slime_skills (slime_id, skill_id, slot, inherited)
-		 VALUES ($1, $2, $3, false)
-		 ON CONFLICT (slime_id, slot) DO UPDATE SET skill_id = $2, inherited = false`,
-		slimeID, body.SkillID, body.Slot,
-	)
-	if err != nil {
+	if err := h.slimeRepo.LearnSkill(ctx, slimeID, body.SkillID, body.Slot); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to learn skill"})
 	}
 
@@ -384,16 +305,16 @@ func (h *Handler) GetMergeForecast(c *fiber.Ctx) error {
 	}
 
 	// Predict talent range
-	childTalents := InheritTalents(*slimeA, *slimeB)
+	rng, _ := h.newRand()
+	childTalents := InheritTalents(rng, *slimeA, *slimeB)
 	talentTotal := 0
 	for _, v := range childTalents {
 		talentTotal += v
 	}
 
 	// Get parent skills for inheritance preview
-	pool := h.slimeRepo.Pool()
-	parentSkillsA := getSlimeSkillIDs(ctx, pool, body.SlimeIDA)
-	parentSkillsB := getSlimeSkillIDs(ctx, pool, body.SlimeIDB)
+	parentSkillsA, _ := h.slimeRepo.SkillIDs(ctx, body.SlimeIDA)
+	parentSkillsB, _ := h.slimeRepo.SkillIDs(ctx, body.SlimeIDB)
 
 	// Determine merge type
 	mergeType := "unknown"
@@ -438,61 +359,49 @@ func safeGrade(sp *models.SlimeSpecies) string {
 	return sp.Grade
 }
 
-func getSlimeSkillIDs(ctx context.Context, pool *pgxpool.Pool, slimeID string) []int {
-	rows, err := pool.Query(ctx,
-		`SELECT skill_id FROM slime_skills WHERE slime_id = $1 ORDER BY slot`, slimeID)
+// getSlimeSkillEffects sums the numeric effect values of a slime's learned skills,
+// keyed by effect name (e.g. "race_speed", "boss_damage"). Boolean effects count as 1.
+func getSlimeSkillEffects(ctx context.Context, slimes repository.SlimeStore, slimeID string) map[string]float64 {
+	effects := make(map[string]float64)
+	rows, err := slimes.EffectsOf(ctx, []string{slimeID})
 	if err != nil {
-		return nil
-	}
-	defer rows.Close()
-	var ids []int
-	for rows.Next() {
-		var id int
-		if rows.Scan(&id) == nil {
-			ids = append(ids, id)
+		return effects
+	}
+	for _, row := range rows {
+		if row.Source != "skill" {
+			continue
+		}
+		var effect map[string]interface{}
+		if json.Unmarshal(row.Effect, &effect) != nil {
+			continue
+		}
+		for k, v := range effect {
+			switch val := v.(type) {
+			case float64:
+				effects[k] += val
+			case bool:
+				if val {
+					effects[k] += 1
+				}
+			}
 		}
 	}
-	return ids
+	return effects
 }
 
 // InheritSkills copies one random skill from parents to the child slime
-func InheritSkills(ctx context.Context, pool *pgxpool.Pool, parentIDA, parentIDB, childID string) {
-	// Gather all parent skills
-	type parentSkill struct {
-		skillID int
-		slot    int
-	}
-	var allSkills []parentSkill
-
-	rows, err := pool.Query(ctx,
-		`SELECT skill_id, slot FROM slime_skills WHERE slime_id IN ($1, $2)`,
-		parentIDA, parentIDB)
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var ps parentSkill
-		if rows.Scan(&ps.skillID, &ps.slot) == nil {
-			allSkills = append(allSkills, ps)
-		}
-	}
-
-	if len(allSkills) == 0 {
+func InheritSkills(ctx context.Context, slimes repository.SlimeStore, rng Rand, parentIDA, parentIDB, childID string) {
+	allSkills, err := slimes.SkillsOf(ctx, []string{parentIDA, parentIDB})
+	if err != nil || len(allSkills) == 0 {
 		return
 	}
 
 	// 30% chance to inherit one skill
-	if rand.Float64() > 0.3 {
+	if rng.Float64() > 0.3 {
 		return
 	}
 
 	// Pick a random skill from parents
-	picked := allSkills[rand.Intn(len(allSkills))]
-	pool.Exec(ctx,
-		`INSERT INTO slime_skills (slime_id, skill_id, slot, inherited)
-		 VALUES ($1, $2, $3, true)
-		 ON CONFLICT (slime_id, slot) DO NOTHING`,
-		childID, picked.skillID, picked.slot,
-	)
+	picked := allSkills[rng.Intn(len(allSkills))]
+	slimes.AddInheritedSkill(ctx, childID, picked.SkillID, picked.Slot)
 }
@@ -0,0 +1,78 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/slimetopia/server/internal/models"
+)
+
+func talentSlime(v [6]int) models.Slime {
+	return models.Slime{TalentStr: v[0], TalentVit: v[1], TalentSpd: v[2], TalentInt: v[3], TalentCha: v[4], TalentLck: v[5]}
+}
+
+func TestInheritTalents(t *testing.T) {
+	a := talentSlime([6]int{10, 11, 12, 13, 14, 15})
+	b := talentSlime([6]int{20, 21, 22, 23, 24, 25})
+	tests := []struct {
+		name   string
+		a, b   models.Slime
+		floats []float64 // < 0.5 inherits from a
+		ints   []int     // mutation is draw - 3
+		want   [6]int
+	}{
+		{"all from a", a, b, repeatFloat(0.1, 6), repeatInt(3, 6), [6]int{10, 11, 12, 13, 14, 15}},
+		{"all from b", a, b, repeatFloat(0.9, 6), repeatInt(3, 6), [6]int{20, 21, 22, 23, 24, 25}},
+		{"alternating parents", a, b, []float64{0.1, 0.9, 0.1, 0.9, 0.1, 0.9}, repeatInt(3, 6), [6]int{10, 21, 12, 23, 14, 25}},
+		{"mutation range", a, b, repeatFloat(0.1, 6), []int{0, 1, 2, 4, 5, 6}, [6]int{7, 9, 11, 14, 16, 18}},
+		{
+			"clamped to 0..31",
+			talentSlime([6]int{31, 31, 31, 1, 1, 1}), b,
+			repeatFloat(0.1, 6), []int{6, 6, 6, 0, 0, 0},
+			[6]int{31, 31, 31, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InheritTalents(&scriptedRand{floats: tt.floats, ints: tt.ints}, tt.a, tt.b)
+			if got != tt.want {
+				t.Errorf("InheritTalents = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInheritTalentsStaysInRange(t *testing.T) {
+	a := talentSlime([6]int{0, 31, 0, 31, 0, 31})
+	b := talentSlime([6]int{31, 0, 31, 0, 31, 0})
+	rng := NewRand(99)
+	for i := 0; i < 1000; i++ {
+		for _, v := range InheritTalents(rng, a, b) {
+			if v < 0 || v > 31 {
+				t.Fatalf("talent %d out of range", v)
+			}
+		}
+	}
+}
+
+func TestTalentGrade(t *testing.T) {
+	tests := []struct {
+		total int
+		want  string
+	}{
+		{186, "S"},
+		{160, "S"},
+		{159, "A"},
+		{130, "A"},
+		{129, "B"},
+		{100, "B"},
+		{99, "C"},
+		{70, "C"},
+		{69, "D"},
+		{0, "D"},
+	}
+	for _, tt := range tests {
+		if got := TalentGrade(tt.total); got != tt.want {
+			t.Errorf("TalentGrade(%d) = %q, want %q", tt.total, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package game
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 const (
@@ -53,69 +55,47 @@ func (h *Handler) GetTrainingModes(c *fiber.Ctx) error {
 // GetTrainingSlots returns the current training slots for a user
 func (h *Handler) GetTrainingSlots(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
-	rows, err := pool.Query(ctx,
-		`SELECT t.id::text, t.slime_id::text, t.slot_number, t.started_at, t.training_mode,
-		        s.species_id, s.level, s.exp, s.element, s.personality, s.name,
-		        sp.name as species_name, sp.grade
-		 FROM training_slots t
-		 JOIN slimes s ON s.id = t.slime_id
-		 JOIN slime_species sp ON sp.id = s.species_id
-		 WHERE t.user_id = $1
-		 ORDER BY t.slot_number`,
-		userID,
-	)
+	rows, err := h.trainingRepo.Slots(ctx, userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch training"})
 	}
-	defer rows.Close()
-
-	slots := make([]fiber.Map, 0)
-	for rows.Next() {
-		var id, slimeID, element, personality, trainingMode string
-		var speciesName, grade string
-		var slotNumber, speciesID, level, exp int
-		var startedAt time.Time
-		var name *string
-
-		if err := rows.Scan(&id, &slimeID, &slotNumber, &startedAt, &trainingMode, &speciesID, &level, &exp, &element, &personality, &name, &speciesName, &grade); err != nil {
-			continue
-		}
 
-		elapsed := time.Since(startedAt)
+	slots := make([]fiber.Map, 0, len(rows))
+	for _, t := range rows {
+		elapsed := time.Since(t.StartedAt)
 		elapsedMins := int(elapsed.Minutes())
 		if elapsedMins > maxTrainingMins {
 			elapsedMins = maxTrainingMins
 		}
-		mult := gradeTrainingMultiplier[grade]
+		mult := gradeTrainingMultiplier[t.Grade]
 		if mult == 0 {
 			mult = 1.0
 		}
 		pendingExp := int(float64(elapsedMins*trainingExpPerMin) * mult)
 
-		displayName := speciesName
-		if name != nil && *name != "" {
-			displayName = *name
+		displayName := t.SpeciesName
+		if t.Name != nil && *t.Name != "" {
+			displayName = *t.Name
 		}
 
-		modeInfo := trainingModes[trainingMode]
+		modeInfo := trainingModes[t.Mode]
 
 		slots = append(slots, fiber.Map{
-			"id":             id,
-			"slime_id":       slimeID,
-			"slot_number":    slotNumber,
-			"started_at":     startedAt,
-			"training_mode":  trainingMode,
+			"id":             t.ID,
+			"slime_id":       t.SlimeID,
+			"slot_number":    t.SlotNumber,
+			"started_at":     t.StartedAt,
+			"training_mode":  t.Mode,
 			"mode_label":     modeInfo.Label,
-			"species_id":     speciesID,
-			"level":          level,
-			"exp":            exp,
-			"element":        element,
-			"personality":    personality,
+			"species_id":     t.SpeciesID,
+			"level":          t.Level,
+			"exp":            t.Exp,
+			"element":        t.Element,
+			"personality":    t.Personality,
 			"name":           displayName,
-			"grade":          grade,
+			"grade":          t.Grade,
 			"elapsed_mins":   elapsedMins,
 			"pending_exp":    pendingExp,
 			"multiplier":     mult,
@@ -146,7 +126,6 @@ func (h *Handler) StartTraining(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid training mode"})
 	}
 
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
 	// Verify slime ownership
@@ -159,9 +138,7 @@ func (h *Handler) StartTraining(c *fiber.Ctx) error {
 	}
 
 	// Check if slime is already training
-	var existing int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM training_slots WHERE user_id = $1 AND slime_id = $2`, userID, body.SlimeID).Scan(&existing)
-	if existing > 0 {
+	if training, _ := h.slimeRepo.IsSlimeInTraining(ctx, userID, []string{body.SlimeID}); training {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "already_training"})
 	}
 
@@ -171,20 +148,11 @@ func (h *Handler) StartTraining(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "on_exploration"})
 	}
 
-	// Check slot count
-	var slotCount int
-	pool.QueryRow(ctx, `SELECT COUNT(*) FROM training_slots WHERE user_id = $1`, userID).Scan(&slotCount)
-	if slotCount >= maxTrainingSlots {
+	// Take the next free slot
+	nextSlot, err := h.trainingRepo.Start(ctx, userID, body.SlimeID, body.TrainingMode, maxTrainingSlots)
+	if errors.Is(err, repository.ErrTrainingSlotsFull) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "slots_full"})
 	}
-
-	// Find next available slot number
-	nextSlot := slotCount + 1
-
-	_, err = pool.Exec(ctx,
-		`INSERT INTO training_slots (user_id, slime_id, slot_number, training_mode) VALUES ($1, $2, $3, $4)`,
-		userID, body.SlimeID, nextSlot, body.TrainingMode,
-	)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to start training"})
 	}
@@ -197,22 +165,16 @@ func (h *Handler) CollectTraining(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	slotID := c.Params("id")
 
-	pool := h.slimeRepo.Pool()
 	ctx := c.UserContext()
 
-	// Atomically delete the training slot and return its data to prevent double-collection
-	var slimeID string
-	var startedAt time.Time
-	var trainingMode string
-	err := pool.QueryRow(ctx,
-		`DELETE FROM training_slots WHERE id = $1 AND user_id = $2 RETURNING slime_id::text, started_at, training_mode`,
-		slotID, userID,
-	).Scan(&slimeID, &startedAt, &trainingMode)
+	// Atomically empty the training slot to prevent double-collection
+	slot, err := h.trainingRepo.Collect(ctx, userID, slotID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "training slot not found"})
 	}
+	slimeID, trainingMode := slot.SlimeID, slot.Mode
 
-	elapsed := time.Since(startedAt)
+	elapsed := time.Since(slot.StartedAt)
 	elapsedMins := int(elapsed.Minutes())
 
 	// Cap at 8 hours
@@ -228,7 +190,9 @@ func (h *Handler) CollectTraining(c *fiber.Ctx) error {
 
 	// Look up grade
 	var grade string
-	pool.QueryRow(ctx, `SELECT grade FROM slime_species WHERE id = $1`, slime.SpeciesID).Scan(&grade)
+	if species, err := h.slimeRepo.GetSpecies(ctx, slime.SpeciesID); err == nil {
+		grade = species.Grade
+	}
 	mult := gradeTrainingMultiplier[grade]
 	if mult == 0 {
 		mult = 1.0
@@ -251,39 +215,22 @@ func (h *Handler) CollectTraining(c *fiber.Ctx) error {
 	var grownStat string
 	if mode, ok := trainingModes[trainingMode]; ok && mode.Primary != "" && talentGrowth > 0 {
 		// Primary gets full growth, secondary gets half
-		pool.Exec(ctx,
-			`UPDATE slimes SET `+mode.Primary+` = LEAST(`+mode.Primary+` + $1, 31) WHERE id = $2`,
-			talentGrowth, slimeID,
-		)
+		h.slimeRepo.GrowTalent(ctx, slimeID, mode.Primary, talentGrowth)
 		grownStat = mode.Primary
 		if mode.Secondary != "" {
 			secondaryGrowth := talentGrowth / 2
 			if secondaryGrowth < 1 {
 				secondaryGrowth = 1
 			}
-			pool.Exec(ctx,
-				`UPDATE slimes SET `+mode.Secondary+` = LEAST(`+mode.Secondary+` + $1, 31) WHERE id = $2`,
-				secondaryGrowth, slimeID,
-			)
+			h.slimeRepo.GrowTalent(ctx, slimeID, mode.Secondary, secondaryGrowth)
 		}
 	}
 
 	// Log training collect
-	LogGameAction(pool, userID, "training_collect", "training", 0, 0, 0, map[string]interface{}{
+	LogGameAction(h.gameLogRepo, userID, "training_collect", "training", 0, 0, 0, map[string]interface{}{
 		"slime_id": slimeID, "exp_gained": earnedExp, "training_mode": trainingMode, "talent_growth": talentGrowth,
 	})
 
-	// Reorder remaining slots
-	pool.Exec(ctx,
-		`WITH numbered AS (
-			SELECT id, ROW_NUMBER() OVER (ORDER BY slot_number) as new_slot
-			FROM training_slots WHERE user_id = $1
-		)
-		UPDATE training_slots t SET slot_number = n.new_slot
-		FROM numbered n WHERE t.id = n.id`,
-		userID,
-	)
-
 	return c.JSON(fiber.Map{
 		"success":        true,
 		"exp_gained":     earnedExp,
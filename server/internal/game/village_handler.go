@@ -65,8 +65,60 @@ func (h *Handler) GetRandomVillages(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"villages": result})
 }
 
+// GET /api/village/friends — villages of the current user's friends
+func (h *Handler) GetFriendVillages(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	ctx := c.Context()
+
+	friends, err := h.friendRepo.ListFriends(ctx, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to fetch villages",
+		})
+	}
+	if len(friends) == 0 {
+		return c.JSON(fiber.Map{"villages": []fiber.Map{}})
+	}
+	ids := make([]string, len(friends))
+	nicknames := make(map[string]string, len(friends))
+	for i, f := range friends {
+		ids[i] = f.UserID
+		nicknames[f.UserID] = f.Nickname
+	}
+
+	villages, err := h.villageRepo.GetByUsers(ctx, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to fetch villages",
+		})
+	}
+	presence := h.lookupPresence(ctx, ids)
+
+	result := make([]fiber.Map, 0, len(villages))
+	for _, v := range villages {
+		ownerID := uuidToString(v.UserID)
+		p := presence[ownerID]
+		result = append(result, fiber.Map{
+			"id":          uuidToString(v.ID),
+			"name":        v.Name,
+			"visit_count": v.VisitCount,
+			"likes":       v.Likes,
+			"updated_at":  v.UpdatedAt,
+			"owner": fiber.Map{
+				"id":        ownerID,
+				"nickname":  nicknames[ownerID],
+				"online":    p.Online,
+				"last_seen": p.LastSeen,
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{"villages": result})
+}
+
 // GET /api/village/:id — visit a specific village
 func (h *Handler) VisitVillage(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
 	villageID := c.Params("id")
 	ctx := c.Context()
 
@@ -104,6 +156,18 @@ func (h *Handler) VisitVillage(c *fiber.Ctx) error {
 		entries = nil
 	}
 
+	// Online status is only shown to friends
+	ownerInfo := fiber.Map{
+		"id":       ownerID,
+		"nickname": nickname,
+	}
+	isFriend, _ := h.friendRepo.AreFriends(ctx, userID, ownerID)
+	if isFriend {
+		p := h.lookupPresence(ctx, []string{ownerID})[ownerID]
+		ownerInfo["online"] = p.Online
+		ownerInfo["last_seen"] = p.LastSeen
+	}
+
 	return c.JSON(fiber.Map{
 		"village": fiber.Map{
 			"id":          uuidToString(village.ID),
@@ -116,10 +180,8 @@ func (h *Handler) VisitVillage(c *fiber.Ctx) error {
 			"created_at":  village.CreatedAt,
 			"updated_at":  village.UpdatedAt,
 		},
-		"owner": fiber.Map{
-			"id":       ownerID,
-			"nickname": nickname,
-		},
+		"owner":     ownerInfo,
+		"is_friend": isFriend,
 		"slimes":    slimeList,
 		"guestbook": entries,
 	})
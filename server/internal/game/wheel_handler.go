@@ -2,10 +2,10 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/slimetopia/server/internal/repository"
 )
 
 type WheelReward struct {
@@ -70,22 +70,18 @@ func (h *Handler) SpinWheel(c *fiber.Ctx) error {
 
 	// If using gems for extra spin, deduct gems
 	if used >= 1 && body.UseGems {
-		user, err := h.userRepo.FindByID(ctx, userID)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "user not found"})
-		}
-		if user.Gems < extraSpinCostGems {
+		if err := h.userRepo.SpendCurrency(ctx, userID, 0, extraSpinCostGems, 0, repository.ReasonWheelSpin, today); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "not enough gems"})
 		}
-		h.userRepo.AddCurrency(ctx, userID, 0, -extraSpinCostGems, 0)
 	}
 
 	// Weighted random selection
+	rng, seed := h.newRand()
 	totalWeight := 0
 	for _, r := range wheelRewards {
 		totalWeight += r.Weight
 	}
-	roll := rand.Intn(totalWeight)
+	roll := rng.Intn(totalWeight)
 	var selected WheelReward
 	cumulative := 0
 	for _, r := range wheelRewards {
@@ -99,11 +95,11 @@ func (h *Handler) SpinWheel(c *fiber.Ctx) error {
 	// Grant reward
 	switch selected.Type {
 	case "gold":
-		h.userRepo.AddCurrency(ctx, userID, int64(selected.Amount), 0, 0)
+		h.userRepo.AddCurrency(ctx, userID, int64(selected.Amount), 0, 0, repository.ReasonWheelReward, today)
 	case "gems":
-		h.userRepo.AddCurrency(ctx, userID, 0, selected.Amount, 0)
+		h.userRepo.AddCurrency(ctx, userID, 0, selected.Amount, 0, repository.ReasonWheelReward, today)
 	case "stardust":
-		h.userRepo.AddCurrency(ctx, userID, 0, 0, selected.Amount)
+		h.userRepo.AddCurrency(ctx, userID, 0, 0, selected.Amount, repository.ReasonWheelReward, today)
 	case "egg_ticket":
 		// Store ticket count in Redis
 		ticketKey := fmt.Sprintf("egg_tickets:%s", userID)
@@ -111,7 +107,6 @@ func (h *Handler) SpinWheel(c *fiber.Ctx) error {
 	}
 
 	// Log wheel spin with accurate currency deltas
-	pool := h.slimeRepo.Pool()
 	usedGemsLog := 0
 	if used >= 1 && body.UseGems {
 		usedGemsLog = extraSpinCostGems
@@ -127,8 +122,8 @@ func (h *Handler) SpinWheel(c *fiber.Ctx) error {
 		logStardust = selected.Amount
 	}
 	logGems -= usedGemsLog // net gems = reward - cost
-	LogGameAction(pool, userID, "wheel_spin", "minigame", logGold, logGems, logStardust, map[string]interface{}{
-		"reward_type": selected.Type, "amount": selected.Amount, "used_gems": usedGemsLog,
+	LogGameAction(h.gameLogRepo, userID, "wheel_spin", "minigame", logGold, logGems, logStardust, map[string]interface{}{
+		"reward_type": selected.Type, "amount": selected.Amount, "used_gems": usedGemsLog, "seed": seed,
 	})
 
 	// Mark free spin used
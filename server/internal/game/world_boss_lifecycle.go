@@ -0,0 +1,200 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/slimetopia/server/internal/repository"
+)
+
+// ===== Boss Lifecycle =====
+//
+// A boss starts active. It becomes defeated when its HP reaches zero or expired when
+// its time runs out, and settled once rankings are final, reward mail is sent and the
+// next boss is up. Settlement runs in one transaction with the boss row locked, so it
+// happens exactly once however often it is attempted.
+
+const (
+	bossActive   = repository.WorldBossActive
+	bossDefeated = repository.WorldBossDefeated
+	bossExpired  = repository.WorldBossExpired
+	bossSettled  = repository.WorldBossSettled
+)
+
+// bossRewardMailTTL is how long reward mail waits in the mailbox to be claimed.
+const bossRewardMailTTL = 14 * 24 * time.Hour
+
+// bossTransition returns the state a boss is in at now, given its stored state.
+// Defeat wins over expiry, so a boss killed in its last second still pays out in full.
+func bossTransition(status string, currentHP int64, expiresAt, now time.Time) string {
+	if status != bossActive {
+		return status
+	}
+	if currentHP <= 0 {
+		return bossDefeated
+	}
+	if !now.Before(expiresAt) {
+		return bossExpired
+	}
+	return bossActive
+}
+
+// nextBossStage returns the stage to spawn after a boss ends, or 0 when none should
+// spawn right away: after an expiry or a cleared final stage, the next cycle starts
+// at stage 1 once the ended boss's time is up.
+func nextBossStage(stage int, defeated bool) int {
+	if !defeated || stage >= len(stageBosses) {
+		return 0
+	}
+	return stage + 1
+}
+
+// bossRewardTier is one band of the final ranking. Rewards are a percentage of the
+// boss's reward_gold and reward_gems.
+type bossRewardTier struct {
+	Name    string
+	MaxRank int // 0 = everyone else who dealt damage
+	GoldPct int
+	GemPct  int
+}
+
+var bossRewardTiers = []bossRewardTier{
+	{Name: "top1", MaxRank: 1, GoldPct: 300, GemPct: 300},
+	{Name: "top10", MaxRank: 10, GoldPct: 200, GemPct: 200},
+	{Name: "top100", MaxRank: 100, GoldPct: 100, GemPct: 100},
+	{Name: "participation", GoldPct: 50, GemPct: 20},
+}
+
+// bossReward returns the tier and reward for a final rank. A boss that expired pays
+// half of what killing it would have.
+func bossReward(rank int, baseGold int64, baseGems int, defeated bool) (string, int64, int) {
+	tier := bossRewardTiers[len(bossRewardTiers)-1]
+	for _, t := range bossRewardTiers {
+		if t.MaxRank > 0 && rank <= t.MaxRank {
+			tier = t
+			break
+		}
+	}
+	gold := baseGold * int64(tier.GoldPct) / 100
+	gems := baseGems * tier.GemPct / 100
+	if !defeated {
+		gold /= 2
+		gems /= 2
+	}
+	return tier.Name, gold, gems
+}
+
+// newWorldBoss rolls the boss for stage (1-based), up for 24 hours.
+func newWorldBoss(rng Rand, stage int) repository.WorldBoss {
+	idx := stage - 1
+	def := stageBosses[idx]
+	// Later stages vary a little more around their base HP
+	jitter := 20000
+	if stage == 1 {
+		jitter = 10000
+	}
+	return repository.WorldBoss{
+		Stage:      stage,
+		Name:       def.Name,
+		Element:    def.Element,
+		MaxHP:      def.BaseHP + int64(rng.Intn(jitter)),
+		RewardGold: int64(stageGoldReward[idx]),
+		RewardGems: stageGemReward[idx],
+		ExpiresAt:  time.Now().Add(24 * time.Hour),
+	}
+}
+
+// ensureWorldBoss starts a new cycle at stage 1 when no boss is up. It returns nil
+// when a boss already is.
+func (h *Handler) ensureWorldBoss(ctx context.Context, rng Rand) (*repository.WorldBoss, error) {
+	return h.worldBossRepo.EnsureSpawned(ctx, func() repository.WorldBoss {
+		return newWorldBoss(rng, 1)
+	})
+}
+
+// settleWorldBoss pays out a boss that has ended and spawns the next stage. It is a
+// no-op for a boss that is still active or already settled, so both the killing blow
+// and the lifecycle job may call it.
+func (h *Handler) settleWorldBoss(ctx context.Context, bossID int, rng Rand) error {
+	var state string
+	var stage int
+	s, err := h.worldBossRepo.Settle(ctx, bossID, func(b *repository.WorldBoss, rankings []repository.WorldBossRanking) *repository.WorldBossSettlement {
+		state, stage = bossTransition(b.Status, b.CurrentHP, b.ExpiresAt, time.Now()), b.Stage
+		if state == bossActive || state == bossSettled {
+			return nil
+		}
+		defeated := state == bossDefeated
+
+		title := b.Name + " 토벌 보상"
+		if !defeated {
+			title = b.Name + " 토벌전 참여 보상"
+		}
+		s := &repository.WorldBossSettlement{
+			Defeated:    defeated,
+			MailExpires: time.Now().Add(bossRewardMailTTL),
+		}
+		for i, r := range rankings {
+			rank := i + 1
+			tier, gold, gems := bossReward(rank, b.RewardGold, b.RewardGems, defeated)
+			s.Rewards = append(s.Rewards, repository.WorldBossReward{
+				UserID: r.UserID,
+				Rank:   rank,
+				Damage: r.Damage,
+				Tier:   tier,
+				Gold:   gold,
+				Gems:   gems,
+				Title:  title,
+				Body:   fmt.Sprintf("%d단계 %s 토벌전이 종료되었습니다. 최종 순위 %d위 (피해량 %d)", b.Stage, b.Name, rank, r.Damage),
+			})
+		}
+
+		if nextStage := nextBossStage(b.Stage, defeated); nextStage > 0 {
+			next := newWorldBoss(rng, nextStage)
+			s.Next = &next
+		}
+		return s
+	})
+	if err != nil || s == nil {
+		return err
+	}
+
+	ev := log.Info().Int("boss_id", bossID).Int("stage", stage).Str("outcome", state).Int("rewarded", len(s.Rewards))
+	if s.Next != nil {
+		ev = ev.Int("next_boss_id", s.Next.ID).Int("next_stage", s.Next.Stage)
+	}
+	ev.Msg("[WorldBoss] boss settled")
+	return nil
+}
+
+// RotateWorldBoss drives the boss lifecycle as a scheduled job: it settles every boss
+// that has been defeated or has expired, then starts a new cycle if no boss is up.
+// A failed settlement is retried on the next run.
+func (h *Handler) RotateWorldBoss(ctx context.Context) error {
+	bosses, err := h.worldBossRepo.Unsettled(ctx)
+	if err != nil {
+		return fmt.Errorf("list unsettled bosses: %w", err)
+	}
+
+	rng, seed := h.newRand()
+	var errs []error
+	now := time.Now()
+	for _, b := range bosses {
+		if bossTransition(b.Status, b.CurrentHP, b.ExpiresAt, now) == bossActive {
+			continue
+		}
+		if err := h.settleWorldBoss(ctx, b.ID, rng); err != nil {
+			errs = append(errs, fmt.Errorf("settle boss %d: %w", b.ID, err))
+		}
+	}
+
+	boss, err := h.ensureWorldBoss(ctx, rng)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("spawn boss: %w", err))
+	} else if boss != nil {
+		log.Info().Int("boss_id", boss.ID).Int64("hp", boss.MaxHP).Int64("seed", seed).Msg("[WorldBoss] new boss cycle started")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBossTransition(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	tests := []struct {
+		name      string
+		status    string
+		hp        int64
+		expiresAt time.Time
+		want      string
+	}{
+		{"alive and in time", bossActive, 100, later, bossActive},
+		{"killed", bossActive, 0, later, bossDefeated},
+		{"timed out", bossActive, 100, earlier, bossExpired},
+		{"expires this instant", bossActive, 100, now, bossExpired},
+		{"killed and timed out counts as killed", bossActive, 0, earlier, bossDefeated},
+		{"already marked defeated", bossDefeated, 0, earlier, bossDefeated},
+		{"settled stays settled", bossSettled, 0, earlier, bossSettled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bossTransition(tt.status, tt.hp, tt.expiresAt, now); got != tt.want {
+				t.Errorf("bossTransition = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextBossStage(t *testing.T) {
+	tests := []struct {
+		stage    int
+		defeated bool
+		want     int
+	}{
+		{1, true, 2},
+		{9, true, 10},
+		{10, true, 0},
+		{1, false, 0},
+		{5, false, 0},
+	}
+	for _, tt := range tests {
+		if got := nextBossStage(tt.stage, tt.defeated); got != tt.want {
+			t.Errorf("nextBossStage(%d, %v) = %d, want %d", tt.stage, tt.defeated, got, tt.want)
+		}
+	}
+}
+
+func TestBossReward(t *testing.T) {
+	tests := []struct {
+		rank     int
+		defeated bool
+		wantTier string
+		wantGold int64
+		wantGems int
+	}{
+		{1, true, "top1", 3000, 30},
+		{2, true, "top10", 2000, 20},
+		{10, true, "top10", 2000, 20},
+		{11, true, "top100", 1000, 10},
+		{100, true, "top100", 1000, 10},
+		{101, true, "participation", 500, 2},
+		{5000, true, "participation", 500, 2},
+		{1, false, "top1", 1500, 15},
+		{101, false, "participation", 250, 1},
+	}
+	for _, tt := range tests {
+		tier, gold, gems := bossReward(tt.rank, 1000, 10, tt.defeated)
+		if tier != tt.wantTier || gold != tt.wantGold || gems != tt.wantGems {
+			t.Errorf("bossReward(%d, defeated=%v) = %s, %d, %d, want %s, %d, %d",
+				tt.rank, tt.defeated, tier, gold, gems, tt.wantTier, tt.wantGold, tt.wantGems)
+		}
+	}
+}
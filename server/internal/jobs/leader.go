@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// Leader elects one scheduler among the running replicas. Lead is called every few
+// seconds; it acquires leadership when it is free and keeps it alive while held.
+type Leader interface {
+	// Lead reports whether this instance is the leader after trying to acquire or
+	// renew leadership.
+	Lead(ctx context.Context) (bool, error)
+	// Resign gives leadership up so another replica can take over immediately.
+	Resign(ctx context.Context) error
+}
+
+// PGLock holds leadership through a session-level Postgres advisory lock. The lock
+// lives on one pooled connection kept out of the pool for as long as it is held, so a
+// replica that dies or loses its connection releases it automatically.
+type PGLock struct {
+	pool *pgxpool.Pool
+	key  int64
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+// NewPGLock creates a leader that contends for the advisory lock key.
+func NewPGLock(pool *pgxpool.Pool, key int64) *PGLock {
+	return &PGLock{pool: pool, key: key}
+}
+
+func (l *PGLock) Lead(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err == nil {
+			return true, nil
+		}
+		// The session, and the lock with it, is gone
+		l.conn.Conn().Close(ctx)
+		l.conn.Release()
+		l.conn = nil
+	}
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&locked); err != nil {
+		conn.Release()
+		return false, err
+	}
+	if !locked {
+		conn.Release()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *PGLock) Resign(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.key)
+	if err != nil {
+		// Closing the session drops the lock just the same
+		l.conn.Conn().Close(ctx)
+	}
+	l.conn.Release()
+	l.conn = nil
+	return err
+}
+
+// RedisLease holds leadership through a Redis key that expires after ttl unless the
+// holder renews it. Lead must be called well within ttl to keep the lease.
+type RedisLease struct {
+	rdb *redis.Client
+	key string
+	id  string
+	ttl time.Duration
+}
+
+// NewRedisLease creates a leader that contends for key, identifying itself as
+// instance.
+func NewRedisLease(rdb *redis.Client, key, instance string, ttl time.Duration) *RedisLease {
+	return &RedisLease{rdb: rdb, key: key, id: instance, ttl: ttl}
+}
+
+// Both scripts only touch the key while this instance still owns it, so a lease that
+// expired and was taken over is never extended or deleted by the previous holder.
+var (
+	renewLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLease = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+func (l *RedisLease) Lead(ctx context.Context) (bool, error) {
+	renewed, err := renewLease.Run(ctx, l.rdb, []string{l.key}, l.id, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("renew lease: %w", err)
+	}
+	if renewed == 1 {
+		return true, nil
+	}
+	ok, err := l.rdb.SetNX(ctx, l.key, l.id, l.ttl).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("acquire lease: %w", err)
+	}
+	return ok, nil
+}
+
+func (l *RedisLease) Resign(ctx context.Context) error {
+	return releaseLease.Run(ctx, l.rdb, []string{l.key}, l.id).Err()
+}
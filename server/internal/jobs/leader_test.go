@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/slimetopia/server/internal/testenv"
+)
+
+func TestRedisLease(t *testing.T) {
+	rdb := testenv.Redis(t)
+	ctx := context.Background()
+	a := NewRedisLease(rdb, "jobs:leader", "a", 10*time.Second)
+	b := NewRedisLease(rdb, "jobs:leader", "b", 10*time.Second)
+
+	lead := func(l *RedisLease) bool {
+		t.Helper()
+		ok, err := l.Lead(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	if !lead(a) {
+		t.Fatal("a did not acquire a free lease")
+	}
+	if lead(b) {
+		t.Fatal("b acquired a held lease")
+	}
+	if !lead(a) {
+		t.Fatal("a could not renew its own lease")
+	}
+
+	// Resigning from a lease b does not hold leaves it alone
+	if err := b.Resign(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if lead(b) {
+		t.Fatal("b's resign released a's lease")
+	}
+
+	if err := a.Resign(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !lead(b) {
+		t.Fatal("b did not take over a resigned lease")
+	}
+	if lead(a) {
+		t.Fatal("a took the lease back from b")
+	}
+}
+
+func TestRedisLeaseExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	ctx := context.Background()
+	a := NewRedisLease(rdb, "jobs:leader", "a", 10*time.Second)
+	b := NewRedisLease(rdb, "jobs:leader", "b", 10*time.Second)
+
+	if ok, _ := a.Lead(ctx); !ok {
+		t.Fatal("a did not acquire a free lease")
+	}
+	mr.FastForward(11 * time.Second)
+	if ok, _ := b.Lead(ctx); !ok {
+		t.Fatal("b did not take over an expired lease")
+	}
+	if ok, _ := a.Lead(ctx); ok {
+		t.Fatal("a renewed a lease it lost")
+	}
+}
@@ -0,0 +1,266 @@
+// Package jobs runs background work on exactly one replica. Every API process runs a
+// Scheduler, but only the one holding leadership (a Postgres advisory lock or a Redis
+// lease) starts jobs; the others stand by and take over when the leader goes away.
+// Each run is recorded in job_runs, which also lets a new leader pick up a schedule
+// where the old one left off.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Job is a named unit of background work.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Timeout bounds a single run; zero means the run is only cancelled when
+	// leadership is lost or the scheduler stops.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// History records job runs. *repository.JobRunRepository implements it.
+type History interface {
+	Started(ctx context.Context, job, instance string, at time.Time) (int64, error)
+	Finished(ctx context.Context, id int64, at time.Time, runErr error) error
+	LastStarted(ctx context.Context, job string) (time.Time, error)
+}
+
+const (
+	tickInterval = time.Second
+	leadInterval = 5 * time.Second
+)
+
+// Scheduler starts jobs on schedule while its Leader holds leadership. A job never
+// overlaps itself; a run that is still going when the next one is due delays it.
+type Scheduler struct {
+	leader   Leader
+	history  History
+	instance string
+	now      func() time.Time
+
+	mu         sync.Mutex
+	jobs       []*entry
+	leading    bool
+	nextLead   time.Time
+	leadCtx    context.Context
+	leadCancel context.CancelFunc
+
+	wg     sync.WaitGroup
+	stopCh chan struct{}
+	done   chan struct{}
+}
+
+type entry struct {
+	Job
+	next    time.Time
+	running bool
+}
+
+// New creates a scheduler. Add jobs before calling Start.
+func New(leader Leader, history History) *Scheduler {
+	return &Scheduler{
+		leader:   leader,
+		history:  history,
+		instance: InstanceID(),
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Add registers a job. Names must be unique; they key the run history.
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.jobs {
+		if e.Name == job.Name {
+			panic(fmt.Sprintf("jobs: duplicate job %q", job.Name))
+		}
+	}
+	s.jobs = append(s.jobs, &entry{Job: job})
+}
+
+// Start launches the scheduling loop. Call Stop() to terminate it.
+func (s *Scheduler) Start() {
+	go s.loop()
+	log.Info().Str("instance", s.instance).Int("jobs", len(s.jobs)).Msg("Scheduler started")
+}
+
+// Stop ends the loop, cancels and waits for running jobs and resigns leadership.
+func (s *Scheduler) Stop() {
+	close(s.stopCh)
+	<-s.done
+
+	s.mu.Lock()
+	s.demote()
+	s.mu.Unlock()
+	s.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.leader.Resign(ctx); err != nil {
+		log.Warn().Err(err).Msg("[Jobs] failed to resign leadership")
+	}
+	log.Info().Msg("Scheduler stopped")
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	s.step(s.now())
+	for {
+		select {
+		case <-ticker.C:
+			s.step(s.now())
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// step renews leadership when due and starts every job whose time has come.
+func (s *Scheduler) step(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !now.Before(s.nextLead) {
+		s.nextLead = now.Add(leadInterval)
+		s.checkLeadership(now)
+	}
+	if !s.leading {
+		return
+	}
+	for _, e := range s.jobs {
+		if !e.running && !now.Before(e.next) {
+			s.run(e, now)
+		}
+	}
+}
+
+func (s *Scheduler) checkLeadership(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), leadInterval)
+	defer cancel()
+
+	leading, err := s.leader.Lead(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("[Jobs] leadership check failed")
+		leading = false
+	}
+	switch {
+	case leading && !s.leading:
+		s.leading = true
+		s.leadCtx, s.leadCancel = context.WithCancel(context.Background())
+		s.resume(ctx, now)
+		log.Info().Str("instance", s.instance).Msg("[Jobs] became leader")
+	case !leading && s.leading:
+		s.demote()
+		log.Warn().Str("instance", s.instance).Msg("[Jobs] lost leadership")
+	}
+}
+
+// resume schedules every job from its last recorded run, so a run the previous
+// leader missed happens once right away rather than being skipped. Jobs that never
+// ran wait for their first scheduled time.
+func (s *Scheduler) resume(ctx context.Context, now time.Time) {
+	for _, e := range s.jobs {
+		if e.running {
+			continue
+		}
+		last, err := s.history.LastStarted(ctx, e.Name)
+		if err != nil {
+			log.Warn().Err(err).Str("job", e.Name).Msg("[Jobs] failed to load last run")
+		}
+		if last.IsZero() {
+			last = now
+		}
+		e.next = e.Schedule.Next(last)
+	}
+}
+
+// demote cancels running jobs; the caller holds s.mu.
+func (s *Scheduler) demote() {
+	s.leading = false
+	if s.leadCancel != nil {
+		s.leadCancel()
+		s.leadCancel = nil
+	}
+}
+
+// run starts one run of e in the background; the caller holds s.mu.
+func (s *Scheduler) run(e *entry, now time.Time) {
+	e.running = true
+	ctx, cancel := s.leadCtx, context.CancelFunc(func() {})
+	if e.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		id, err := s.history.Started(ctx, e.Name, s.instance, now)
+		if err != nil {
+			log.Warn().Err(err).Str("job", e.Name).Msg("[Jobs] failed to record run start")
+		}
+
+		runErr := safeRun(ctx, e.Run)
+		finished := s.now()
+		if runErr != nil {
+			log.Error().Err(runErr).Str("job", e.Name).Dur("took", finished.Sub(now)).Msg("[Jobs] run failed")
+		} else {
+			log.Debug().Str("job", e.Name).Dur("took", finished.Sub(now)).Msg("[Jobs] run finished")
+		}
+		if id != 0 {
+			// The run's own context may be cancelled by now; the record still matters
+			fctx, fcancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.history.Finished(fctx, id, finished, runErr); err != nil {
+				log.Warn().Err(err).Str("job", e.Name).Msg("[Jobs] failed to record run end")
+			}
+			fcancel()
+		}
+
+		s.mu.Lock()
+		e.running = false
+		e.next = e.Schedule.Next(now)
+		s.mu.Unlock()
+	}()
+}
+
+// safeRun keeps a panicking job from taking the process down with it.
+func safeRun(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+var (
+	instanceOnce sync.Once
+	instanceID   string
+)
+
+// InstanceID identifies this process in job_runs and Redis leases: the hostname, the
+// pid and a random suffix, since containers often share both of the former.
+func InstanceID() string {
+	instanceOnce.Do(func() {
+		host, _ := os.Hostname()
+		b := make([]byte, 4)
+		rand.Read(b)
+		instanceID = fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+	})
+	return instanceID
+}
@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLeader struct {
+	mu      sync.Mutex
+	leading bool
+}
+
+func (l *fakeLeader) set(v bool) {
+	l.mu.Lock()
+	l.leading = v
+	l.mu.Unlock()
+}
+
+func (l *fakeLeader) Lead(context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leading, nil
+}
+
+func (l *fakeLeader) Resign(context.Context) error {
+	l.set(false)
+	return nil
+}
+
+type fakeRun struct {
+	job      string
+	started  time.Time
+	finished bool
+	err      error
+}
+
+type fakeHistory struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+	runs []*fakeRun
+}
+
+func (h *fakeHistory) Started(_ context.Context, job, _ string, at time.Time) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.runs = append(h.runs, &fakeRun{job: job, started: at})
+	return int64(len(h.runs)), nil
+}
+
+func (h *fakeHistory) Finished(_ context.Context, id int64, _ time.Time, runErr error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.runs[id-1].finished = true
+	h.runs[id-1].err = runErr
+	return nil
+}
+
+func (h *fakeHistory) LastStarted(_ context.Context, job string) (time.Time, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last[job], nil
+}
+
+func (h *fakeHistory) count(job string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := 0
+	for _, r := range h.runs {
+		if r.job == job {
+			n++
+		}
+	}
+	return n
+}
+
+var t0 = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func noop(context.Context) error { return nil }
+
+// stepAt runs one scheduler step at t0+offset and waits for the runs it started.
+func stepAt(s *Scheduler, offset time.Duration) {
+	s.step(t0.Add(offset))
+	s.wg.Wait()
+}
+
+func TestSchedulerRunsOnlyWhileLeading(t *testing.T) {
+	leader := &fakeLeader{}
+	history := &fakeHistory{}
+	s := New(leader, history)
+	s.Add(Job{Name: "tick", Schedule: Every(time.Minute), Run: noop})
+
+	stepAt(s, 0)
+	stepAt(s, 2*time.Minute)
+	if n := history.count("tick"); n != 0 {
+		t.Fatalf("follower ran %d times", n)
+	}
+
+	leader.set(true)
+	stepAt(s, 3*time.Minute) // becomes leader; a job that never ran waits a full interval
+	stepAt(s, 3*time.Minute+30*time.Second)
+	if n := history.count("tick"); n != 0 {
+		t.Fatalf("ran %d times before its first interval", n)
+	}
+	stepAt(s, 4*time.Minute)
+	stepAt(s, 4*time.Minute+30*time.Second)
+	if n := history.count("tick"); n != 1 {
+		t.Fatalf("ran %d times, want 1", n)
+	}
+	stepAt(s, 5*time.Minute)
+	if n := history.count("tick"); n != 2 {
+		t.Fatalf("ran %d times, want 2", n)
+	}
+
+	leader.set(false)
+	stepAt(s, 10*time.Minute)
+	if n := history.count("tick"); n != 2 {
+		t.Fatalf("ran %d times after losing leadership, want 2", n)
+	}
+}
+
+func TestSchedulerCatchesUpMissedRunOnce(t *testing.T) {
+	leader := &fakeLeader{leading: true}
+	history := &fakeHistory{last: map[string]time.Time{"daily": t0.Add(-3 * 24 * time.Hour)}}
+	s := New(leader, history)
+	s.Add(Job{Name: "daily", Schedule: MustParseCron("0 0 * * *"), Run: noop})
+
+	stepAt(s, 0)
+	stepAt(s, time.Minute)
+	stepAt(s, 2*time.Minute)
+	if n := history.count("daily"); n != 1 {
+		t.Fatalf("ran %d times, want one catch-up run", n)
+	}
+	stepAt(s, 12*time.Hour)
+	if n := history.count("daily"); n != 2 {
+		t.Fatalf("ran %d times, want the next midnight run", n)
+	}
+}
+
+func TestSchedulerDoesNotOverlapRuns(t *testing.T) {
+	leader := &fakeLeader{leading: true}
+	history := &fakeHistory{last: map[string]time.Time{"slow": t0.Add(-time.Hour)}}
+	s := New(leader, history)
+	release := make(chan struct{})
+	s.Add(Job{Name: "slow", Schedule: Every(time.Second), Run: func(context.Context) error {
+		<-release
+		return nil
+	}})
+
+	s.step(t0)
+	s.step(t0.Add(5 * time.Second))
+	s.step(t0.Add(10 * time.Second))
+	close(release)
+	s.wg.Wait()
+	if n := history.count("slow"); n != 1 {
+		t.Fatalf("started %d overlapping runs", n)
+	}
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	leader := &fakeLeader{leading: true}
+	history := &fakeHistory{last: map[string]time.Time{"bad": t0.Add(-time.Hour), "worse": t0.Add(-time.Hour)}}
+	s := New(leader, history)
+	boom := errors.New("boom")
+	s.Add(Job{Name: "bad", Schedule: Every(time.Minute), Run: func(context.Context) error { return boom }})
+	s.Add(Job{Name: "worse", Schedule: Every(time.Minute), Run: func(context.Context) error { panic("oops") }})
+
+	stepAt(s, 0)
+	if len(history.runs) != 2 {
+		t.Fatalf("recorded %d runs, want 2", len(history.runs))
+	}
+	for _, r := range history.runs {
+		if !r.finished || r.err == nil {
+			t.Errorf("%s: finished %v, err %v", r.job, r.finished, r.err)
+		}
+	}
+}
+
+func TestSchedulerCancelsRunsOnLostLeadership(t *testing.T) {
+	leader := &fakeLeader{leading: true}
+	history := &fakeHistory{last: map[string]time.Time{"long": t0.Add(-time.Hour)}}
+	s := New(leader, history)
+	started := make(chan struct{})
+	s.Add(Job{Name: "long", Schedule: Every(time.Minute), Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	s.step(t0)
+	<-started
+	leader.set(false)
+	s.step(t0.Add(leadInterval))
+	s.wg.Wait()
+	if r := history.runs[0]; !errors.Is(r.err, context.Canceled) {
+		t.Errorf("run ended with %v, want context.Canceled", r.err)
+	}
+}
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 	"github.com/slimetopia/server/internal/auth"
+	"github.com/slimetopia/server/internal/repository"
 )
 
-func AuthRequired(jwtManager *auth.JWTManager) fiber.Handler {
+// AuthRequired validates the bearer token, then rejects tokens that were revoked
+// (by jti or user-wide) and users under an active ban.
+func AuthRequired(jwtManager *auth.JWTManager, denylist *auth.Denylist) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		header := c.Get("Authorization")
 		if header == "" {
@@ -24,6 +29,9 @@ func AuthRequired(jwtManager *auth.JWTManager) fiber.Handler {
 		}
 
 		claims, err := jwtManager.ValidateToken(parts[1])
+		if err == nil && claims.TokenType() != auth.TokenTypeAccess {
+			err = auth.ErrInvalidToken
+		}
 		if err != nil {
 			status := fiber.StatusUnauthorized
 			msg := "invalid token"
@@ -33,9 +41,30 @@ func AuthRequired(jwtManager *auth.JWTManager) fiber.Handler {
 			return c.Status(status).JSON(fiber.Map{"error": msg})
 		}
 
+		ctx := c.Context()
+		revoked, err := denylist.IsRevoked(ctx, claims)
+		if err != nil {
+			log.Warn().Err(err).Msg("Token denylist unavailable")
+		} else if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "token revoked"})
+		}
+
+		ban, err := denylist.ActiveBan(ctx, claims.UserID)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			log.Warn().Err(err).Str("user_id", claims.UserID).Msg("Ban check failed")
+		}
+		if ban != nil {
+			return auth.BanResponse(c, ban)
+		}
+
 		// Store user info in context
 		c.Locals("user_id", claims.UserID)
 		c.Locals("nickname", claims.Nickname)
+		c.Locals("jti", claims.ID)
+		c.Locals("session_id", claims.SessionID)
+		if claims.ExpiresAt != nil {
+			c.Locals("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		return c.Next()
 	}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	idempotencyHeader    = "Idempotency-Key"
+	idempotencyMaxKeyLen = 128
+	// How long an in-flight request holds the key before a crashed request frees it.
+	idempotencyLockTTL = 60 * time.Second
+)
+
+// idempotencyRecord is what is stored in Redis per (user, key).
+// While the first request is running only Fingerprint is set and Pending is true.
+type idempotencyRecord struct {
+	Pending     bool   `json:"pending"`
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency makes a handler safe to retry. When the client sends an Idempotency-Key
+// header the first response for that user and key is stored and replayed on duplicates;
+// a duplicate arriving while the first is still running gets 409. Reusing a key for a
+// different request (method, path or body) gets 422. Requests without the header pass through.
+// Must run after AuthRequired.
+func Idempotency(rdb *redis.Client, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(idempotencyHeader)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > idempotencyMaxKeyLen {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "idempotency key too long"})
+		}
+		userID, _ := c.Locals("user_id").(string)
+		if userID == "" {
+			return c.Next()
+		}
+
+		ctx := c.Context()
+		redisKey := fmt.Sprintf("idem:%s:%s", userID, key)
+		sum := sha256.Sum256(append([]byte(c.Method()+" "+c.Path()+"\n"), c.Body()...))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		pending, _ := json.Marshal(idempotencyRecord{Pending: true, Fingerprint: fingerprint})
+		acquired, err := rdb.SetNX(ctx, redisKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			// Redis down: don't block gameplay, just lose the protection
+			log.Warn().Err(err).Msg("Idempotency store unavailable")
+			return c.Next()
+		}
+
+		if !acquired {
+			raw, err := rdb.Get(ctx, redisKey).Bytes()
+			if err != nil {
+				// Expired between SETNX and GET — treat as still in flight and let the client retry
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "request with this idempotency key is in progress"})
+			}
+			var rec idempotencyRecord
+			if err := json.Unmarshal(raw, &rec); err != nil {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "request with this idempotency key is in progress"})
+			}
+			if rec.Fingerprint != fingerprint {
+				return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "idempotency key reused with a different request"})
+			}
+			if rec.Pending {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "request with this idempotency key is in progress"})
+			}
+			c.Set("Idempotent-Replayed", "true")
+			if rec.ContentType != "" {
+				c.Set(fiber.HeaderContentType, rec.ContentType)
+			}
+			return c.Status(rec.Status).Send(rec.Body)
+		}
+
+		if err := c.Next(); err != nil {
+			rdb.Del(ctx, redisKey)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			// Server-side failures are not final — release the key so the retry runs again
+			rdb.Del(ctx, redisKey)
+			return nil
+		}
+
+		done, _ := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		})
+		if err := rdb.Set(ctx, redisKey, done, ttl).Err(); err != nil {
+			log.Warn().Err(err).Str("user_id", userID).Msg("Failed to store idempotent response")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,373 @@
+// Package migrate applies and reverts the SQL migrations in server/migrations.
+//
+// Migrations are NNNNNN_name.up.sql files with an optional NNNNNN_name.down.sql. Each
+// one runs in its own transaction while the migrator holds a Postgres advisory lock,
+// so replicas starting together apply it exactly once. schema_migrations records the
+// SHA-256 of every applied up file; a file edited after it was applied leaves the
+// schema dirty until the change is reverted or the migration rolled back.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey identifies the advisory lock held while migrating ("slimemig").
+const lockKey int64 = 0x736c696d656d6967
+
+// renamed maps versions recorded before a migration file was renumbered to its
+// current version.
+var renamed = map[string]string{
+	"000022_shorts_optional_video": "000042_shorts_optional_video",
+}
+
+var fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrIrreversible is returned by Down for a migration without a .down.sql file.
+var ErrIrreversible = errors.New("migration has no down file")
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string // empty if irreversible
+	Checksum string // SHA-256 of Up, hex encoded
+}
+
+// ID is the migration's key in schema_migrations, e.g. "000001_init_schema".
+func (m Migration) ID() string {
+	return fmt.Sprintf("%06d_%s", m.Version, m.Name)
+}
+
+// Load reads every migration in dir, ordered by version. Duplicate versions, down
+// files without a matching up file and misnamed .sql files are errors.
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ups := map[int]*Migration{}
+	downs := map[string]string{} // ID -> SQL
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		match := fileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%s: expected NNNNNN_name.up.sql or NNNNNN_name.down.sql", e.Name())
+		}
+		body, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		version, _ := strconv.Atoi(match[1])
+		mig := Migration{Version: version, Name: match[2]}
+		if match[3] == "down" {
+			downs[mig.ID()] = string(body)
+			continue
+		}
+		if prev := ups[version]; prev != nil {
+			return nil, fmt.Errorf("%s: version %d is already used by %s", e.Name(), version, prev.ID())
+		}
+		sum := sha256.Sum256(body)
+		mig.Up = string(body)
+		mig.Checksum = hex.EncodeToString(sum[:])
+		ups[version] = &mig
+	}
+
+	migrations := make([]Migration, 0, len(ups))
+	for _, mig := range ups {
+		if down, ok := downs[mig.ID()]; ok {
+			mig.Down = down
+			delete(downs, mig.ID())
+		}
+		migrations = append(migrations, *mig)
+	}
+	for id := range downs {
+		return nil, fmt.Errorf("%s.down.sql has no matching up file", id)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// FindDir returns the first of the usual migration directories that exists,
+// relative to the working directory or the container's /app.
+func FindDir() (string, error) {
+	for _, dir := range []string{"migrations", "server/migrations", "/app/migrations"} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", errors.New("no migrations directory found")
+}
+
+// State is a migration as the database sees it.
+type State struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // applied from a file whose contents have changed since
+	Missing   bool // applied, but no longer on disk (only Version, Name and AppliedAt are set)
+}
+
+// DirtyError lists what makes the recorded schema disagree with the migration files.
+type DirtyError struct {
+	Modified []string
+	Missing  []string
+}
+
+func (e *DirtyError) Error() string {
+	var parts []string
+	if len(e.Modified) > 0 {
+		parts = append(parts, "modified after being applied: "+strings.Join(e.Modified, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "applied but missing from disk: "+strings.Join(e.Missing, ", "))
+	}
+	return "dirty schema: " + strings.Join(parts, "; ")
+}
+
+// Migrator runs a set of migrations against one database.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+func New(pool *pgxpool.Pool, migrations []Migration) *Migrator {
+	return &Migrator{pool: pool, migrations: migrations}
+}
+
+type appliedRow struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock runs fn on a single connection holding the migration lock, after
+// bringing schema_migrations up to date.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, applied map[string]appliedRow) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	if err := m.prepare(ctx, conn); err != nil {
+		return err
+	}
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, applied)
+}
+
+// prepare creates schema_migrations, or upgrades the one written by the API's old
+// migration runner: versions lose their .up.sql suffix, renumbered files get their
+// new version, and rows applied before checksums existed adopt the current file's.
+func (m *Migrator) prepare(ctx context.Context, conn *pgxpool.Conn) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`); err != nil {
+			return fmt.Errorf("create schema_migrations: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64)`); err != nil {
+			return fmt.Errorf("upgrade schema_migrations: %w", err)
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE schema_migrations SET version = left(version, -7) WHERE version LIKE '%.up.sql'`,
+		); err != nil {
+			return fmt.Errorf("upgrade schema_migrations: %w", err)
+		}
+		for from, to := range renamed {
+			if _, err := tx.Exec(ctx, `UPDATE schema_migrations SET version = $2 WHERE version = $1`, from, to); err != nil {
+				return fmt.Errorf("rename %s: %w", from, err)
+			}
+		}
+		for _, mig := range m.migrations {
+			if _, err := tx.Exec(ctx,
+				`UPDATE schema_migrations SET checksum = $2 WHERE version = $1 AND checksum IS NULL`,
+				mig.ID(), mig.Checksum,
+			); err != nil {
+				return fmt.Errorf("record checksum of %s: %w", mig.ID(), err)
+			}
+		}
+		return nil
+	})
+}
+
+func loadApplied(ctx context.Context, conn *pgxpool.Conn) (map[string]appliedRow, error) {
+	rows, err := conn.Query(ctx, `SELECT version, COALESCE(checksum, ''), applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := map[string]appliedRow{}
+	for rows.Next() {
+		var version string
+		var row appliedRow
+		if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = row
+	}
+	return applied, rows.Err()
+}
+
+// states merges the files with what was applied: every file in order, then applied
+// versions that have no file, oldest first.
+func (m *Migrator) states(applied map[string]appliedRow) []State {
+	states := make([]State, 0, len(m.migrations))
+	known := map[string]bool{}
+	for _, mig := range m.migrations {
+		known[mig.ID()] = true
+		s := State{Migration: mig}
+		if row, ok := applied[mig.ID()]; ok {
+			s.Applied = true
+			s.AppliedAt = row.appliedAt
+			s.Modified = row.checksum != mig.Checksum
+		}
+		states = append(states, s)
+	}
+	var missing []State
+	for id, row := range applied {
+		if !known[id] {
+			missing = append(missing, State{Migration: parseID(id), Applied: true, AppliedAt: row.appliedAt, Missing: true})
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].ID() < missing[j].ID() })
+	return append(states, missing...)
+}
+
+// parseID splits a recorded version like "000001_init_schema" back into its parts.
+func parseID(id string) Migration {
+	num, name, _ := strings.Cut(id, "_")
+	version, _ := strconv.Atoi(num)
+	return Migration{Version: version, Name: name}
+}
+
+func dirty(states []State) error {
+	var e DirtyError
+	for _, s := range states {
+		switch {
+		case s.Missing:
+			e.Missing = append(e.Missing, s.ID())
+		case s.Modified:
+			e.Modified = append(e.Modified, s.ID())
+		}
+	}
+	if len(e.Modified) == 0 && len(e.Missing) == 0 {
+		return nil
+	}
+	return &e
+}
+
+// Status reports every migration and applied version.
+func (m *Migrator) Status(ctx context.Context) ([]State, error) {
+	var states []State
+	err := m.withLock(ctx, func(_ *pgxpool.Conn, applied map[string]appliedRow) error {
+		states = m.states(applied)
+		return nil
+	})
+	return states, err
+}
+
+// Verify returns a *DirtyError if an applied migration was edited or removed, and
+// nil otherwise. Pending migrations don't make the schema dirty.
+func (m *Migrator) Verify(ctx context.Context) error {
+	return m.withLock(ctx, func(_ *pgxpool.Conn, applied map[string]appliedRow) error {
+		return dirty(m.states(applied))
+	})
+}
+
+// Up applies every pending migration in version order and returns the ones it
+// applied. It refuses to run on a dirty schema, and stops at the first failure,
+// whose transaction is rolled back.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[string]appliedRow) error {
+		states := m.states(applied)
+		if err := dirty(states); err != nil {
+			return err
+		}
+		for _, s := range states {
+			if s.Applied {
+				continue
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, s.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)`,
+					s.ID(), s.Checksum,
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("apply %s: %w", s.ID(), err)
+			}
+			done = append(done, s.Migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the n most recently numbered applied migrations, newest first, and
+// returns the ones it reverted. Every one of them must have a down file.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[string]appliedRow) error {
+		states := m.states(applied)
+		if err := dirty(states); err != nil {
+			return err
+		}
+		var targets []Migration
+		for i := len(states) - 1; i >= 0 && len(targets) < n; i-- {
+			if states[i].Applied {
+				targets = append(targets, states[i].Migration)
+			}
+		}
+		for _, mig := range targets {
+			if mig.Down == "" {
+				return fmt.Errorf("revert %s: %w", mig.ID(), ErrIrreversible)
+			}
+		}
+		for _, mig := range targets {
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.ID())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("revert %s: %w", mig.ID(), err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
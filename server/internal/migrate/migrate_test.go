@@ -0,0 +1,174 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slimetopia/server/internal/migrate"
+	"github.com/slimetopia/server/internal/testenv"
+)
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"000002_items.up.sql":     "CREATE TABLE items (id INT);",
+		"000001_users.up.sql":     "CREATE TABLE users (id INT);",
+		"000001_users.down.sql":   "DROP TABLE users;",
+		"README.md":               "not a migration",
+		"000010_seed_data.up.sql": "INSERT INTO users VALUES (1);",
+	})
+	migrations, err := migrate.Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var ids []string
+	for _, m := range migrations {
+		ids = append(ids, m.ID())
+	}
+	if got := strings.Join(ids, " "); got != "000001_users 000002_items 000010_seed_data" {
+		t.Errorf("order = %s", got)
+	}
+	if migrations[0].Down != "DROP TABLE users;" || migrations[1].Down != "" {
+		t.Errorf("down files not attached: %q, %q", migrations[0].Down, migrations[1].Down)
+	}
+	if len(migrations[0].Checksum) != 64 || migrations[0].Checksum == migrations[1].Checksum {
+		t.Errorf("checksums = %q, %q", migrations[0].Checksum, migrations[1].Checksum)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"duplicate version", map[string]string{"000001_a.up.sql": "", "000001_b.up.sql": ""}},
+		{"down without up", map[string]string{"000001_a.up.sql": "", "000002_b.down.sql": ""}},
+		{"down with another name", map[string]string{"000001_a.up.sql": "", "000001_b.down.sql": ""}},
+		{"misnamed file", map[string]string{"1-init.sql": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := migrate.Load(writeMigrations(t, tt.files)); err == nil {
+				t.Error("Load succeeded")
+			}
+		})
+	}
+}
+
+func TestRepoMigrationsLoad(t *testing.T) {
+	if _, err := migrate.Load("../../migrations"); err != nil {
+		t.Fatalf("server/migrations: %v", err)
+	}
+}
+
+func TestUpDownVerify(t *testing.T) {
+	pool := testenv.EmptySchema(t)
+	ctx := context.Background()
+	files := map[string]string{
+		"000001_users.up.sql":   "CREATE TABLE users (id INT);",
+		"000001_users.down.sql": "DROP TABLE users;",
+		"000002_items.up.sql":   "CREATE TABLE items (id INT); CREATE INDEX ON items (id);",
+		"000003_broken.up.sql":  "CREATE TABLE broken (id INT); SELECT * FROM nowhere;",
+	}
+	dir := writeMigrations(t, files)
+	migrations, err := migrate.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := migrate.New(pool, migrations)
+
+	done, err := m.Up(ctx)
+	if err == nil || len(done) != 2 {
+		t.Fatalf("Up = %d applied, %v; want 2 applied and the third failing", len(done), err)
+	}
+	var exists bool
+	pool.QueryRow(ctx, `SELECT to_regclass('broken') IS NOT NULL`).Scan(&exists)
+	if exists {
+		t.Error("failed migration was not rolled back")
+	}
+
+	// Drop the broken migration; everything else is applied
+	migrations = migrations[:2]
+	m = migrate.New(pool, migrations)
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Fatalf("second Up = %d applied, %v", len(done), err)
+	}
+	if err := m.Verify(ctx); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if _, err := m.Down(ctx, 1); !errors.Is(err, migrate.ErrIrreversible) {
+		t.Errorf("Down over an irreversible migration: %v", err)
+	}
+
+	edited := append([]migrate.Migration(nil), migrations...)
+	edited[0].Checksum = strings.Repeat("0", 64)
+	var dirty *migrate.DirtyError
+	if err := migrate.New(pool, edited).Verify(ctx); !errors.As(err, &dirty) || len(dirty.Modified) != 1 {
+		t.Errorf("Verify after an edit: %v", err)
+	}
+	if err := migrate.New(pool, migrations[:1]).Verify(ctx); !errors.As(err, &dirty) || len(dirty.Missing) != 1 {
+		t.Errorf("Verify with a file removed: %v", err)
+	}
+
+	// Make the newest migration reversible and roll both back
+	migrations[1].Down = "DROP TABLE items;"
+	m = migrate.New(pool, migrations)
+	done, err = m.Down(ctx, 2)
+	if err != nil || len(done) != 2 || done[0].ID() != "000002_items" {
+		t.Fatalf("Down = %v, %v", done, err)
+	}
+	states, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range states {
+		if s.Applied {
+			t.Errorf("%s still applied", s.ID())
+		}
+	}
+}
+
+func TestLegacyVersionsAreAdopted(t *testing.T) {
+	pool := testenv.EmptySchema(t)
+	ctx := context.Background()
+
+	// The table as the API's old runner left it
+	if _, err := pool.Exec(ctx, `CREATE TABLE schema_migrations (
+		version VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	);
+	INSERT INTO schema_migrations (version) VALUES
+		('000001_users.up.sql'), ('000022_shorts_optional_video.up.sql')`); err != nil {
+		t.Fatal(err)
+	}
+	dir := writeMigrations(t, map[string]string{
+		"000001_users.up.sql":                 "CREATE TABLE users (id INT);",
+		"000042_shorts_optional_video.up.sql": "SELECT 1;",
+	})
+	migrations, err := migrate.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := migrate.New(pool, migrations)
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Fatalf("Up = %d applied, %v; want both adopted", len(done), err)
+	}
+	if err := m.Verify(ctx); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+}
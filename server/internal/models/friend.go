@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Friend is an entry in a user's friend list.
+type Friend struct {
+	UserID          string    `json:"user_id"`
+	Nickname        string    `json:"nickname"`
+	Level           int       `json:"level"`
+	ProfileImageURL string    `json:"profile_image_url"`
+	Since           time.Time `json:"since"`
+}
+
+// FriendRequest is a pending request, seen from either side. UserID and Nickname
+// describe the other player.
+type FriendRequest struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Nickname  string    `json:"nickname"`
+	Level     int       `json:"level"`
+	Incoming  bool      `json:"incoming"`
+	CreatedAt time.Time `json:"created_at"`
+}
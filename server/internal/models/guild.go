@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Guild roles, from most to least privileged.
+const (
+	GuildRoleLeader  = "leader"
+	GuildRoleOfficer = "officer"
+	GuildRoleMember  = "member"
+)
+
+// Guild join policies.
+const (
+	GuildJoinOpen   = "open"   // anyone can join
+	GuildJoinApply  = "apply"  // applications need an officer's approval
+	GuildJoinInvite = "invite" // invitation only
+)
+
+type Guild struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	JoinPolicy  string    `json:"join_policy"`
+	Level       int       `json:"level"`
+	XP          int64     `json:"xp"`
+	MemberCount int       `json:"member_count"`
+	MaxMembers  int       `json:"max_members"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type GuildMember struct {
+	UserID        string    `json:"user_id"`
+	GuildID       string    `json:"-"`
+	Nickname      string    `json:"nickname"`
+	Level         int       `json:"level"`
+	Role          string    `json:"role"`
+	ContributedXP int64     `json:"contributed_xp"`
+	JoinedAt      time.Time `json:"joined_at"`
+}
+
+// GuildRequest is a pending invite (sent by an officer) or application (sent by the
+// player). UserID and Nickname describe the player being invited or applying.
+type GuildRequest struct {
+	ID        string    `json:"id"`
+	GuildID   string    `json:"guild_id"`
+	GuildName string    `json:"guild_name"`
+	UserID    string    `json:"user_id"`
+	Nickname  string    `json:"nickname"`
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GuildMessage struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id"`
+	Nickname  string    `json:"nickname"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Session is one refresh token family: a login on a single device.
+type Session struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	CurrentJTI  string     `json:"-"`
+	DeviceLabel string     `json:"device_label"`
+	UserAgent   string     `json:"user_agent"`
+	IP          string     `json:"ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastUsedAt  time.Time  `json:"last_used_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"-"`
+}
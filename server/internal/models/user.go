@@ -16,8 +16,27 @@ type User struct {
 	Stardust        int         `json:"stardust"`
 	Level           int         `json:"level"`
 	Email           string      `json:"email"`
+	EmailVerified   bool        `json:"email_verified"`
 	PasswordHash    string      `json:"-"`
 	ProfileImageURL string      `json:"profile_image_url"`
 	CreatedAt       time.Time   `json:"created_at"`
 	UpdatedAt       time.Time   `json:"updated_at"`
+
+	// DeletionScheduledAt is set while the account is in its deletion grace period.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// BanInfo describes an active account ban. ExpiresAt is nil for permanent bans.
+type BanInfo struct {
+	Reason    string     `json:"reason"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Permanent bool       `json:"permanent"`
+}
+
+// Identity is a login method linked to an account.
+type Identity struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email,omitempty"`
+	LinkedAt time.Time `json:"linked_at"`
 }
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EquippedAccessory is an accessory worn in one of a slime's slots.
+type EquippedAccessory struct {
+	SlimeID     string
+	Slot        string
+	AccessoryID int
+}
+
+// AccessoryRepository reads and writes the accessories players own and equip; the
+// accessory definitions are game data.
+type AccessoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAccessoryRepository(pool *pgxpool.Pool) *AccessoryRepository {
+	return &AccessoryRepository{pool: pool}
+}
+
+func (r *AccessoryRepository) Owned(ctx context.Context, userID string) ([]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT accessory_id FROM slime_accessories WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+func (r *AccessoryRepository) Owns(ctx context.Context, userID string, accessoryID int) (bool, error) {
+	var owned bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM slime_accessories WHERE user_id = $1 AND accessory_id = $2)`,
+		userID, accessoryID,
+	).Scan(&owned)
+	return owned, err
+}
+
+func (r *AccessoryRepository) Grant(ctx context.Context, userID string, accessoryID int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO slime_accessories (user_id, accessory_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		userID, accessoryID)
+	return err
+}
+
+// Equip puts an accessory in a slime's slot, replacing whatever was there.
+func (r *AccessoryRepository) Equip(ctx context.Context, slimeID, slot string, accessoryID int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO equipped_accessories (slime_id, slot, accessory_id)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (slime_id, slot) DO UPDATE SET accessory_id = EXCLUDED.accessory_id`,
+		slimeID, slot, accessoryID)
+	return err
+}
+
+// Unequip empties a slot of one of userID's slimes.
+func (r *AccessoryRepository) Unequip(ctx context.Context, userID, slimeID, slot string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM equipped_accessories ea USING slimes s
+		 WHERE ea.slime_id = s.id AND s.id = $1 AND s.user_id = $2 AND ea.slot = $3`,
+		slimeID, userID, slot)
+	return err
+}
+
+// EquippedByUser lists what every one of userID's slimes is wearing.
+func (r *AccessoryRepository) EquippedByUser(ctx context.Context, userID string) ([]EquippedAccessory, error) {
+	return collectEquipped(r.pool.Query(ctx,
+		`SELECT ea.slime_id::text, ea.slot, ea.accessory_id
+		 FROM equipped_accessories ea
+		 JOIN slimes s ON s.id = ea.slime_id
+		 WHERE s.user_id = $1`, userID))
+}
+
+func (r *AccessoryRepository) EquippedOn(ctx context.Context, slimeID string) ([]EquippedAccessory, error) {
+	return collectEquipped(r.pool.Query(ctx,
+		`SELECT slime_id::text, slot, accessory_id FROM equipped_accessories WHERE slime_id = $1`, slimeID))
+}
+
+func collectEquipped(rows pgx.Rows, err error) ([]EquippedAccessory, error) {
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (EquippedAccessory, error) {
+		var e EquippedAccessory
+		err := row.Scan(&e.SlimeID, &e.Slot, &e.AccessoryID)
+		return e, err
+	})
+}
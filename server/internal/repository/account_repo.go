@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrNoDeletionScheduled = errors.New("no deletion scheduled")
+
+// DeletedUserNickname replaces the name on rows that outlive a deleted account
+// (first discoveries, the other side of gifts and tips).
+const DeletedUserNickname = "탈퇴한 사용자"
+
+// ExportSection is one named part of a personal data export, already encoded as JSON.
+type ExportSection struct {
+	Name string
+	Data json.RawMessage
+}
+
+// exportQueries each select a single JSON value for user $1. Secrets (password hash)
+// and other players' identifiers are left out.
+var exportQueries = []struct {
+	name  string
+	query string
+}{
+	{"profile", `SELECT to_jsonb(u) - 'password_hash' - 'banned_by' FROM users u WHERE id = $1`},
+	{"identities", `SELECT COALESCE(json_agg(t ORDER BY t.linked_at), '[]') FROM (
+		SELECT provider, COALESCE(email, '') AS email, linked_at FROM user_identities WHERE user_id = $1) t`},
+	{"slimes", `SELECT COALESCE(json_agg(s ORDER BY s.created_at), '[]') FROM slimes s WHERE user_id = $1`},
+	{"slime_eggs", `SELECT COALESCE(json_agg(e ORDER BY e.created_at), '[]') FROM slime_eggs e WHERE user_id = $1`},
+	{"slime_lineage", `SELECT COALESCE(json_agg(l ORDER BY l.created_at), '[]') FROM slime_lineage l WHERE user_id = $1`},
+	{"codex", `SELECT COALESCE(json_agg(c ORDER BY c.species_id), '[]') FROM codex_entries c WHERE user_id = $1`},
+	{"collection", `SELECT COALESCE(json_agg(c ORDER BY c.species_id, c.personality), '[]') FROM collection_entries c WHERE user_id = $1`},
+	{"mailbox", `SELECT COALESCE(json_agg(m ORDER BY m.created_at), '[]') FROM mailbox m WHERE user_id = $1`},
+	{"mailbox_claims", `SELECT COALESCE(json_agg(t ORDER BY t.read_at), '[]') FROM (
+		SELECT mc.mail_id, m.title, mc.read_at, mc.claimed
+		FROM mailbox_claims mc JOIN mailbox m ON m.id = mc.mail_id WHERE mc.user_id = $1) t`},
+	{"community_posts", `SELECT COALESCE(json_agg(p ORDER BY p.created_at), '[]') FROM community_posts p WHERE user_id = $1`},
+	{"community_replies", `SELECT COALESCE(json_agg(r ORDER BY r.created_at), '[]') FROM community_replies r WHERE user_id = $1`},
+	{"shorts", `SELECT COALESCE(json_agg(s ORDER BY s.created_at), '[]') FROM shorts s WHERE user_id = $1`},
+	{"shorts_comments", `SELECT COALESCE(json_agg(sc ORDER BY sc.created_at), '[]') FROM shorts_comments sc WHERE user_id = $1`},
+	{"friends", `SELECT COALESCE(json_agg(t ORDER BY t.since), '[]') FROM (
+		SELECT u.nickname, f.created_at AS since
+		FROM friendships f JOIN users u ON u.id = f.friend_id WHERE f.user_id = $1) t`},
+	{"guild", `SELECT COALESCE(json_agg(t), '[]') FROM (
+		SELECT g.name, gm.role, gm.contributed_xp, gm.joined_at
+		FROM guild_members gm JOIN guilds g ON g.id = gm.guild_id WHERE gm.user_id = $1) t`},
+	{"guild_messages", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT g.name AS guild, m.message, m.created_at
+		FROM guild_messages m JOIN guilds g ON g.id = m.guild_id WHERE m.user_id = $1) t`},
+	{"arena_defense", `SELECT COALESCE(json_agg(t), '[]') FROM (
+		SELECT slime_ids, updated_at FROM arena_defense WHERE user_id = $1) t`},
+	{"arena_ratings", `SELECT COALESCE(json_agg(t ORDER BY t.season_id), '[]') FROM (
+		SELECT season_id, rating, wins, losses, updated_at FROM arena_ratings WHERE user_id = $1) t`},
+	{"arena_battles", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT b.season_id, CASE WHEN b.attacker_id = $1 THEN 'attack' ELSE 'defense' END AS side,
+		       u.nickname AS opponent, b.attacker_won = (b.attacker_id = $1) AS won,
+		       CASE WHEN b.attacker_id = $1 THEN b.attacker_rating ELSE b.defender_rating END AS rating,
+		       CASE WHEN b.attacker_id = $1 THEN b.attacker_delta ELSE b.defender_delta END AS rating_delta,
+		       b.created_at
+		FROM arena_battles b
+		JOIN users u ON u.id = CASE WHEN b.attacker_id = $1 THEN b.defender_id ELSE b.attacker_id END
+		WHERE b.attacker_id = $1 OR b.defender_id = $1) t`},
+	{"arena_rewards", `SELECT COALESCE(json_agg(t ORDER BY t.season_id), '[]') FROM (
+		SELECT season_id, rank, rating, tier, reward_gold, reward_gems, created_at FROM arena_rewards WHERE user_id = $1) t`},
+	{"world_boss_rewards", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT boss_id, rank, damage, tier, reward_gold, reward_gems, created_at FROM world_boss_rewards WHERE user_id = $1) t`},
+	{"guild_boss_rewards", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT boss_id, rank, damage, reward_gold, reward_gems, created_at FROM guild_boss_rewards WHERE user_id = $1) t`},
+	{"gift_logs", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT CASE WHEN sender_id = $1 THEN 'sent' ELSE 'received' END AS direction,
+		       gift_type, amount, COALESCE(message, '') AS message, created_at
+		FROM gift_logs WHERE sender_id = $1 OR receiver_id = $1) t`},
+	{"shorts_tips", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT CASE WHEN sender_id = $1 THEN 'sent' ELSE 'received' END AS direction,
+		       short_id, tip_type, amount, COALESCE(message, '') AS message, created_at
+		FROM shorts_tips WHERE sender_id = $1 OR receiver_id = $1) t`},
+	{"support_tickets", `SELECT COALESCE(json_agg(t ORDER BY t.created_at), '[]') FROM (
+		SELECT st.id, st.subject, st.category, st.status, st.created_at, st.updated_at,
+		       (SELECT COALESCE(json_agg(json_build_object(
+		            'sender_type', sr.sender_type, 'message', sr.message, 'created_at', sr.created_at
+		        ) ORDER BY sr.created_at), '[]') FROM support_replies sr WHERE sr.ticket_id = st.id) AS replies
+		FROM support_tickets st WHERE st.user_id = $1) t`},
+	{"game_logs", `SELECT COALESCE(json_agg(g ORDER BY g.created_at), '[]') FROM game_logs g WHERE user_id = $1`},
+	{"currency_ledger", `SELECT COALESCE(json_agg(t ORDER BY t.created_at, t.id), '[]') FROM (
+		SELECT id, txn_id, currency, amount, balance_after, reason, ref_id, created_at
+		FROM currency_ledger WHERE user_id = $1 AND account = 'user') t`},
+}
+
+type AccountRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAccountRepository(pool *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{pool: pool}
+}
+
+// Export collects everything stored about a user, read from a single snapshot.
+func (r *AccountRepository) Export(ctx context.Context, userID string) ([]ExportSection, error) {
+	sections := make([]ExportSection, 0, len(exportQueries))
+	err := pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		for _, q := range exportQueries {
+			var data []byte
+			if err := tx.QueryRow(ctx, q.query, userID).Scan(&data); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return ErrUserNotFound
+				}
+				return fmt.Errorf("export %s: %w", q.name, err)
+			}
+			sections = append(sections, ExportSection{Name: q.name, Data: data})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// UploadedFiles lists the /uploads/... URLs of files the user uploaded: profile image,
+// shorts videos and thumbnails, and community post images.
+func (r *AccountRepository) UploadedFiles(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT url FROM (
+			SELECT profile_image_url AS url FROM users WHERE id = $1
+			UNION SELECT video_url FROM shorts WHERE user_id = $1
+			UNION SELECT thumbnail_url FROM shorts WHERE user_id = $1
+			UNION SELECT image_url FROM community_posts WHERE user_id = $1
+			UNION SELECT unnest(image_urls) FROM community_posts WHERE user_id = $1
+		) f WHERE url LIKE '/uploads/%'`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ScheduleDeletion marks the account to be purged at the given time. Scheduling again
+// keeps the original date.
+func (r *AccountRepository) ScheduleDeletion(ctx context.Context, userID string, at time.Time) (time.Time, error) {
+	var scheduled time.Time
+	err := r.pool.QueryRow(ctx,
+		`UPDATE users SET deletion_scheduled_at = COALESCE(deletion_scheduled_at, $2), updated_at = NOW()
+		 WHERE id = $1 RETURNING deletion_scheduled_at`,
+		userID, at,
+	).Scan(&scheduled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, ErrUserNotFound
+	}
+	return scheduled, err
+}
+
+func (r *AccountRepository) CancelDeletion(ctx context.Context, userID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users SET deletion_scheduled_at = NULL, updated_at = NOW()
+		 WHERE id = $1 AND deletion_scheduled_at IS NOT NULL`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoDeletionScheduled
+	}
+	return nil
+}
+
+// DueDeletions returns up to limit accounts whose grace period has ended.
+func (r *AccountRepository) DueDeletions(ctx context.Context, limit int) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id::text FROM users
+		 WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= NOW()
+		 ORDER BY deletion_scheduled_at LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// Purge permanently removes an account whose deletion is due. Rows owned by the user
+// are deleted (most through ON DELETE CASCADE); rows other players still see are
+// anonymized. The caller removes uploaded files afterwards.
+func (r *AccountRepository) Purge(ctx context.Context, userID string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var due bool
+		err := tx.QueryRow(ctx,
+			`SELECT deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= NOW()
+			 FROM users WHERE id = $1 FOR UPDATE`,
+			userID,
+		).Scan(&due)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if !due {
+			// Cancelled between DueDeletions and now
+			return ErrNoDeletionScheduled
+		}
+
+		// Remember which of other players' posts and shorts lose replies or likes so
+		// their counters can be recomputed after the cascade.
+		var postIDs, shortIDs []string
+		err = tx.QueryRow(ctx,
+			`SELECT COALESCE(array_agg(DISTINCT post_id::text), '{}') FROM (
+				SELECT post_id FROM community_replies WHERE user_id = $1
+				UNION SELECT post_id FROM community_post_likes WHERE user_id = $1
+			) t`,
+			userID,
+		).Scan(&postIDs)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRow(ctx,
+			`SELECT COALESCE(array_agg(DISTINCT short_id::text), '{}') FROM (
+				SELECT short_id FROM shorts_comments WHERE user_id = $1
+				UNION SELECT short_id FROM shorts_likes WHERE user_id = $1
+			) t`,
+			userID,
+		).Scan(&shortIDs)
+		if err != nil {
+			return err
+		}
+
+		// Transfers to or from other players keep their other leg; re-home this user's
+		// leg onto the counterparty as a system leg so every transaction still sums to 0
+		// and the counterparty's wallet still reconciles.
+		_, err = tx.Exec(ctx,
+			`UPDATE currency_ledger l SET account = 'system', balance_after = NULL,
+			        user_id = (SELECT o.user_id FROM currency_ledger o
+			                   WHERE o.txn_id = l.txn_id AND o.user_id <> $1 LIMIT 1)
+			 WHERE l.user_id = $1
+			   AND EXISTS (SELECT 1 FROM currency_ledger o WHERE o.txn_id = l.txn_id AND o.user_id <> $1)`,
+			userID,
+		)
+		if err != nil {
+			return fmt.Errorf("rehome ledger legs: %w", err)
+		}
+
+		// A leaving guild leader hands over before the membership row cascades away
+		guildID, role, err := guildActorTx(ctx, tx, userID)
+		if err == nil {
+			_, err = leaveTx(ctx, tx, guildID, userID, role)
+		}
+		if err != nil && !errors.Is(err, ErrNotInGuild) {
+			return fmt.Errorf("leave guild: %w", err)
+		}
+
+		// Tables without a foreign key to users
+		if _, err := tx.Exec(ctx, `DELETE FROM game_logs WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM support_tickets WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE first_discoveries SET nickname = $2 WHERE user_id = $1`, userID, DeletedUserNickname)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+
+		if len(postIDs) > 0 {
+			_, err = tx.Exec(ctx,
+				`UPDATE community_posts p SET
+					reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = p.id),
+					likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = p.id)
+				 WHERE p.id = ANY($1::uuid[])`,
+				postIDs,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if len(shortIDs) > 0 {
+			_, err = tx.Exec(ctx,
+				`UPDATE shorts s SET
+					comment_count = (SELECT COUNT(*) FROM shorts_comments WHERE short_id = s.id),
+					likes = (SELECT COUNT(*) FROM shorts_likes WHERE short_id = s.id)
+				 WHERE s.id = ANY($1::uuid[])`,
+				shortIDs,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
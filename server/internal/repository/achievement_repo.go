@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AchievementProgress holds the counters achievement conditions are checked against.
+type AchievementProgress struct {
+	Slimes          int
+	CodexEntries    int
+	MythicSlimes    int
+	MaxSlimeLevel   int
+	BestRaceScore   int
+	AttendanceDays  int
+	ExplorationsRun int // claimed explorations
+}
+
+type AchievementRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementRepository(pool *pgxpool.Pool) *AchievementRepository {
+	return &AchievementRepository{pool: pool}
+}
+
+// Unlocked maps the keys of userID's achievements to when they were unlocked.
+func (r *AchievementRepository) Unlocked(ctx context.Context, userID string) (map[string]time.Time, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT achievement_key, COALESCE(unlocked_at, NOW()) FROM achievements WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked := make(map[string]time.Time)
+	var key string
+	var at time.Time
+	_, err = pgx.ForEachRow(rows, []any{&key, &at}, func() error {
+		unlocked[key] = at
+		return nil
+	})
+	return unlocked, err
+}
+
+// Unlock records an achievement; unlocked is false if userID already had it.
+func (r *AchievementRepository) Unlock(ctx context.Context, userID, key string) (unlocked bool, err error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO achievements (user_id, achievement_key) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		userID, key)
+	return tag.RowsAffected() > 0, err
+}
+
+func (r *AchievementRepository) Progress(ctx context.Context, userID string) (*AchievementProgress, error) {
+	var p AchievementProgress
+	err := r.pool.QueryRow(ctx,
+		`SELECT
+			(SELECT COUNT(*) FROM slimes WHERE user_id = $1),
+			(SELECT COUNT(*) FROM codex_entries WHERE user_id = $1),
+			(SELECT COUNT(*) FROM slimes s JOIN slime_species sp ON s.species_id = sp.id
+			 WHERE s.user_id = $1 AND sp.grade = 'mythic'),
+			(SELECT COALESCE(MAX(level), 0) FROM slimes WHERE user_id = $1),
+			(SELECT COALESCE(MAX(score), 0) FROM race_results WHERE user_id = $1),
+			(SELECT COALESCE(MAX(day_number), 0) FROM attendance WHERE user_id = $1),
+			(SELECT COUNT(*) FROM explorations WHERE user_id = $1 AND claimed = true)`,
+		userID,
+	).Scan(&p.Slimes, &p.CodexEntries, &p.MythicSlimes, &p.MaxSlimeLevel, &p.BestRaceScore, &p.AttendanceDays, &p.ExplorationsRun)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrArenaUnrated   = errors.New("no arena rating this season")
+	ErrNoArenaDefense = errors.New("no arena defense team")
+)
+
+// arenaSeasonLockKey serializes season starts so two callers never open two.
+const arenaSeasonLockKey = 0x736c696d65617265 // "slimeare"
+
+// ArenaSeason is a rating season; battles are fought in the one still running.
+type ArenaSeason struct {
+	ID     int
+	EndsAt time.Time
+}
+
+// ArenaStanding is a player's record in a season.
+type ArenaStanding struct {
+	UserID   string
+	Nickname string
+	Rating   int
+	Wins     int
+	Losses   int
+}
+
+// ArenaOpponent is a matchmaking candidate; Team summarizes their defense slimes.
+type ArenaOpponent struct {
+	UserID   string          `json:"user_id"`
+	Nickname string          `json:"nickname"`
+	Rating   int             `json:"rating"`
+	Team     json.RawMessage `json:"team"`
+}
+
+// ArenaBattle is one fought battle. RecordBattle fills in ID, Ratings and Deltas.
+type ArenaBattle struct {
+	ID          int64
+	SeasonID    int
+	AttackerID  string
+	DefenderID  string
+	Seed        int64
+	AttackerWon bool
+	Replay      json.RawMessage
+	Ratings     [2]int // before the battle, attacker first
+	Deltas      [2]int
+	CreatedAt   time.Time
+}
+
+// ArenaBattleSummary is a battle as one of its two players sees it.
+type ArenaBattleSummary struct {
+	ID          int64
+	Attacking   bool
+	Opponent    string
+	AttackerWon bool
+	Delta       int
+	CreatedAt   time.Time
+}
+
+// ArenaSeasonReward is what a player gets for their final rank, and the mail it comes in.
+type ArenaSeasonReward struct {
+	Tier      string
+	Gold      int64
+	Gems      int
+	MailTitle string
+	MailBody  string
+}
+
+type ArenaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewArenaRepository(pool *pgxpool.Pool) *ArenaRepository {
+	return &ArenaRepository{pool: pool}
+}
+
+// CurrentSeason returns the running season, starting one that lasts length if
+// there is none; started reports whether it did.
+func (r *ArenaRepository) CurrentSeason(ctx context.Context, length time.Duration) (s *ArenaSeason, started bool, err error) {
+	s = &ArenaSeason{}
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		const running = `SELECT id, ends_at FROM arena_seasons WHERE ends_at > NOW() ORDER BY id DESC LIMIT 1`
+		err := tx.QueryRow(ctx, running).Scan(&s.ID, &s.EndsAt)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(arenaSeasonLockKey)); err != nil {
+			return err
+		}
+		// Someone else may have started it while we waited for the lock
+		err = tx.QueryRow(ctx, running).Scan(&s.ID, &s.EndsAt)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		started = true
+		return tx.QueryRow(ctx,
+			`INSERT INTO arena_seasons (ends_at) VALUES ($1) RETURNING id, ends_at`,
+			time.Now().Add(length),
+		).Scan(&s.ID, &s.EndsAt)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return s, started, nil
+}
+
+// EndedSeasons lists the seasons that are over but haven't been settled, oldest first.
+func (r *ArenaRepository) EndedSeasons(ctx context.Context) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id FROM arena_seasons WHERE settled_at IS NULL AND ends_at <= NOW() ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// SettleSeason mails every player of an ended season the reward for their final rank
+// and marks it settled. settled is false for a season that is still running or was
+// already settled.
+func (r *ArenaRepository) SettleSeason(ctx context.Context, seasonID int, mailExpires time.Time, reward func(rank, rating int) ArenaSeasonReward) (settled bool, rewarded int, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var endsAt time.Time
+		var settledAt *time.Time
+		err := tx.QueryRow(ctx,
+			`SELECT ends_at, settled_at FROM arena_seasons WHERE id = $1 FOR UPDATE`, seasonID,
+		).Scan(&endsAt, &settledAt)
+		if err != nil {
+			return err
+		}
+		if settledAt != nil || time.Now().Before(endsAt) {
+			return nil
+		}
+
+		rows, err := tx.Query(ctx,
+			`SELECT user_id::text, rating FROM arena_ratings
+			 WHERE season_id = $1 AND wins + losses > 0
+			 ORDER BY rating DESC, updated_at`, seasonID)
+		if err != nil {
+			return fmt.Errorf("rank players: %w", err)
+		}
+		standings, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ArenaStanding, error) {
+			var s ArenaStanding
+			err := row.Scan(&s.UserID, &s.Rating)
+			return s, err
+		})
+		if err != nil {
+			return fmt.Errorf("rank players: %w", err)
+		}
+
+		batch := &pgx.Batch{}
+		for i, s := range standings {
+			rank := i + 1
+			rw := reward(rank, s.Rating)
+			// The reward row and its mail go in together; a user already rewarded gets neither
+			batch.Queue(
+				`WITH reward AS (
+					INSERT INTO arena_rewards (season_id, user_id, rank, rating, tier, reward_gold, reward_gems)
+					VALUES ($1, $2, $3, $4, $5, $6, $7)
+					ON CONFLICT DO NOTHING
+					RETURNING user_id
+				)
+				INSERT INTO mailbox (user_id, title, body, mail_type, reward_gold, reward_gems, expires_at)
+				SELECT user_id, $8, $9, 'reward', $6, $7, $10 FROM reward`,
+				seasonID, s.UserID, rank, s.Rating, rw.Tier, rw.Gold, rw.Gems, rw.MailTitle, rw.MailBody, mailExpires,
+			)
+		}
+		if batch.Len() > 0 {
+			if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+				return fmt.Errorf("send rewards: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE arena_seasons SET settled_at = NOW() WHERE id = $1`, seasonID); err != nil {
+			return err
+		}
+		settled, rewarded = true, len(standings)
+		return nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return settled, rewarded, nil
+}
+
+// Standing returns userID's record in a season, or ErrArenaUnrated before their
+// first battle in it.
+func (r *ArenaRepository) Standing(ctx context.Context, seasonID int, userID string) (*ArenaStanding, error) {
+	s := ArenaStanding{UserID: userID}
+	err := r.pool.QueryRow(ctx,
+		`SELECT rating, wins, losses FROM arena_ratings WHERE season_id = $1 AND user_id = $2`,
+		seasonID, userID,
+	).Scan(&s.Rating, &s.Wins, &s.Losses)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrArenaUnrated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Rank is the place a rating holds among the season's players.
+func (r *ArenaRepository) Rank(ctx context.Context, seasonID, rating int) (int, error) {
+	var rank int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) + 1 FROM arena_ratings WHERE season_id = $1 AND rating > $2 AND wins + losses > 0`,
+		seasonID, rating,
+	).Scan(&rank)
+	return rank, err
+}
+
+// Ranking lists the season's top players by rating.
+func (r *ArenaRepository) Ranking(ctx context.Context, seasonID, limit int) ([]ArenaStanding, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT r.user_id::text, u.nickname, r.rating, r.wins, r.losses
+		 FROM arena_ratings r JOIN users u ON u.id = r.user_id
+		 WHERE r.season_id = $1 AND r.wins + r.losses > 0
+		 ORDER BY r.rating DESC, r.updated_at
+		 LIMIT $2`, seasonID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ArenaStanding, error) {
+		var s ArenaStanding
+		err := row.Scan(&s.UserID, &s.Nickname, &s.Rating, &s.Wins, &s.Losses)
+		return s, err
+	})
+}
+
+// Defense returns the slime ids of userID's registered defense team, or
+// ErrNoArenaDefense.
+func (r *ArenaRepository) Defense(ctx context.Context, userID string) ([]string, error) {
+	var ids []string
+	err := r.pool.QueryRow(ctx, `SELECT slime_ids::text[] FROM arena_defense WHERE user_id = $1`, userID).Scan(&ids)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoArenaDefense
+	}
+	return ids, err
+}
+
+func (r *ArenaRepository) SetDefense(ctx context.Context, userID string, slimeIDs []string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO arena_defense (user_id, slime_ids) VALUES ($1, $2::uuid[])
+		 ON CONFLICT (user_id) DO UPDATE SET slime_ids = EXCLUDED.slime_ids, updated_at = NOW()`,
+		userID, slimeIDs,
+	)
+	return err
+}
+
+// Opponents picks up to limit random players with a defense team whose rating is
+// within band of rating (any rating when band is 0). Unrated players count as
+// startRating.
+func (r *ArenaRepository) Opponents(ctx context.Context, seasonID int, userID string, rating, startRating, band, limit int) ([]ArenaOpponent, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT d.user_id::text, u.nickname, COALESCE(r.rating, $4),
+		        COALESCE((SELECT json_agg(json_build_object('species_id', s.species_id, 'element', s.element,
+		                  'level', s.level, 'star_level', s.star_level))
+		                  FROM slimes s WHERE s.id = ANY(d.slime_ids)), '[]')
+		 FROM arena_defense d
+		 JOIN users u ON u.id = d.user_id
+		 LEFT JOIN arena_ratings r ON r.season_id = $1 AND r.user_id = d.user_id
+		 WHERE d.user_id <> $2
+		   AND ($5 = 0 OR COALESCE(r.rating, $4) BETWEEN $3 - $5 AND $3 + $5)
+		 ORDER BY random()
+		 LIMIT $6`,
+		seasonID, userID, rating, startRating, band, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ArenaOpponent, error) {
+		var o ArenaOpponent
+		err := row.Scan(&o.UserID, &o.Nickname, &o.Rating, &o.Team)
+		return o, err
+	})
+}
+
+// RecordBattle applies a battle's rating changes to both players and stores it. Both
+// ratings are locked while rate works out the deltas from them and the games each
+// player has fought, attacker first; players start at startRating.
+func (r *ArenaRepository) RecordBattle(ctx context.Context, b *ArenaBattle, startRating int, rate func(ratings, games [2]int) [2]int) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO arena_ratings (season_id, user_id, rating) VALUES ($1, $2, $4), ($1, $3, $4) ON CONFLICT DO NOTHING`,
+			b.SeasonID, b.AttackerID, b.DefenderID, startRating)
+		if err != nil {
+			return err
+		}
+		// Locked in user_id order so two battles between the same pair can't deadlock
+		rows, err := tx.Query(ctx,
+			`SELECT user_id::text, rating, wins + losses FROM arena_ratings
+			 WHERE season_id = $1 AND user_id IN ($2, $3) ORDER BY user_id FOR UPDATE`,
+			b.SeasonID, b.AttackerID, b.DefenderID)
+		if err != nil {
+			return err
+		}
+		var games [2]int
+		var id string
+		var rating, played int
+		_, err = pgx.ForEachRow(rows, []any{&id, &rating, &played}, func() error {
+			i := 0
+			if id != b.AttackerID {
+				i = 1
+			}
+			b.Ratings[i], games[i] = rating, played
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		b.Deltas = rate(b.Ratings, games)
+		for i, id := range []string{b.AttackerID, b.DefenderID} {
+			wins, losses := 0, 1
+			if b.AttackerWon == (i == 0) {
+				wins, losses = 1, 0
+			}
+			_, err := tx.Exec(ctx,
+				`UPDATE arena_ratings
+				 SET rating = rating + $3, wins = wins + $4, losses = losses + $5, updated_at = NOW()
+				 WHERE season_id = $1 AND user_id = $2`,
+				b.SeasonID, id, b.Deltas[i], wins, losses)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.QueryRow(ctx,
+			`INSERT INTO arena_battles (season_id, attacker_id, defender_id, seed, attacker_won,
+			                            attacker_rating, defender_rating, attacker_delta, defender_delta, replay)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`,
+			b.SeasonID, b.AttackerID, b.DefenderID, b.Seed, b.AttackerWon,
+			b.Ratings[0], b.Ratings[1], b.Deltas[0], b.Deltas[1], b.Replay,
+		).Scan(&b.ID, &b.CreatedAt)
+	})
+}
+
+// Battles lists userID's most recent battles, attacking and defending.
+func (r *ArenaRepository) Battles(ctx context.Context, userID string, limit int) ([]ArenaBattleSummary, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT b.id, b.attacker_id = $1, o.nickname, b.attacker_won,
+		        CASE WHEN b.attacker_id = $1 THEN b.attacker_delta ELSE b.defender_delta END,
+		        b.created_at
+		 FROM arena_battles b
+		 JOIN users o ON o.id = CASE WHEN b.attacker_id = $1 THEN b.defender_id ELSE b.attacker_id END
+		 WHERE b.attacker_id = $1 OR b.defender_id = $1
+		 ORDER BY b.created_at DESC
+		 LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ArenaBattleSummary, error) {
+		var s ArenaBattleSummary
+		err := row.Scan(&s.ID, &s.Attacking, &s.Opponent, &s.AttackerWon, &s.Delta, &s.CreatedAt)
+		return s, err
+	})
+}
+
+// Battle loads a battle with its replay.
+func (r *ArenaRepository) Battle(ctx context.Context, battleID int64) (*ArenaBattle, error) {
+	b := ArenaBattle{ID: battleID}
+	err := r.pool.QueryRow(ctx,
+		`SELECT season_id, attacker_id::text, defender_id::text, seed, attacker_won, replay, created_at
+		 FROM arena_battles WHERE id = $1`,
+		battleID,
+	).Scan(&b.SeasonID, &b.AttackerID, &b.DefenderID, &b.Seed, &b.AttackerWon, &b.Replay, &b.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BotRepository seeds bot accounts and their activity. Seeded rows carry the
+// counters and timestamps they are given; call the Recount methods once the
+// likes and replies are in.
+type BotRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBotRepository(pool *pgxpool.Pool) *BotRepository {
+	return &BotRepository{pool: pool}
+}
+
+// Count counts the users whose email matches the LIKE pattern emailLike.
+func (r *BotRepository) Count(ctx context.Context, emailLike string) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email LIKE $1`, emailLike).Scan(&n)
+	return n, err
+}
+
+// First returns the earliest user whose email matches emailLike.
+func (r *BotRepository) First(ctx context.Context, emailLike string) (string, error) {
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`SELECT id FROM users WHERE email LIKE $1 ORDER BY created_at LIMIT 1`, emailLike,
+	).Scan(&id)
+	return id, err
+}
+
+// IDs lists the users whose email matches emailLike.
+func (r *BotRepository) IDs(ctx context.Context, emailLike string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id::text FROM users WHERE email LIKE $1`, emailLike)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// ID returns the id of the user with email, or "" if there is none.
+func (r *BotRepository) ID(ctx context.Context, email string) (string, error) {
+	var id string
+	err := r.pool.QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, email).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+// Create creates a bot user and grants its starting balances through the ledger.
+func (r *BotRepository) Create(ctx context.Context, nickname, providerID, email, passwordHash string, level int, gold int64, gems, stardust int) (string, error) {
+	var userID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO users (nickname, provider, provider_id, level, email, password_hash)
+			 VALUES ($1, 'bot', $2, $3, $4, $5)
+			 RETURNING id`,
+			nickname, providerID, level, email, passwordHash,
+		).Scan(&userID)
+		if err != nil {
+			return err
+		}
+		return ApplyCurrencyTx(ctx, tx, userID, gold, gems, stardust, ReasonBotActivity, "seed", false)
+	})
+	return userID, err
+}
+
+// Delete deletes the users whose email matches emailLike; their data cascades.
+func (r *BotRepository) Delete(ctx context.Context, emailLike string) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM users WHERE email LIKE $1`, emailLike)
+	return tag.RowsAffected(), err
+}
+
+// CreateVillage gives a bot its village unless it has one.
+func (r *BotRepository) CreateVillage(ctx context.Context, userID, name, terrain string, visits, likes int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO villages (user_id, name, terrain, visit_count, likes)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (user_id) DO NOTHING`,
+		userID, name, terrain, visits, likes,
+	)
+	return err
+}
+
+// CreateSlime gives a bot a slime and adds its species to the bot's codex.
+func (r *BotRepository) CreateSlime(ctx context.Context, userID string, speciesID int, element, personality string, level, exp, affection, hunger, condition int) (string, error) {
+	var slimeID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO slimes (user_id, species_id, element, personality, level, exp, affection, hunger, condition)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 RETURNING id`,
+			userID, speciesID, element, personality, level, exp, affection, hunger, condition,
+		).Scan(&slimeID)
+		if err != nil {
+			return err
+		}
+		return AddCodexEntryTx(ctx, tx, userID, speciesID)
+	})
+	return slimeID, err
+}
+
+// RandomSpecies picks a random species of element.
+func (r *BotRepository) RandomSpecies(ctx context.Context, element string) (int, error) {
+	var id int
+	err := r.pool.QueryRow(ctx,
+		`SELECT id FROM slime_species WHERE element = $1 ORDER BY RANDOM() LIMIT 1`, element,
+	).Scan(&id)
+	return id, err
+}
+
+// LevelUpSlime raises one of the bot's slimes, picked at random, a level (up to 50).
+func (r *BotRepository) LevelUpSlime(ctx context.Context, userID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE slimes SET level = LEAST(level + 1, 50), exp = 0, updated_at = NOW()
+		 WHERE id = (SELECT id FROM slimes WHERE user_id = $1 ORDER BY RANDOM() LIMIT 1)`,
+		userID,
+	)
+	if err == nil && tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return err
+}
+
+// LevelUp raises the bot a level (up to 50) and grants it stardust.
+func (r *BotRepository) LevelUp(ctx context.Context, userID string, stardust int) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `UPDATE users SET level = LEAST(level + 1, 50) WHERE id = $1`, userID); err != nil {
+			return err
+		}
+		return ApplyCurrencyTx(ctx, tx, userID, 0, 0, stardust, ReasonBotActivity, "", false)
+	})
+}
+
+// AddCollectionEntry submits a species and personality to a bot's collection.
+func (r *BotRepository) AddCollectionEntry(ctx context.Context, userID string, speciesID int, personality string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO collection_entries (user_id, species_id, personality) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		userID, speciesID, personality,
+	)
+	return err
+}
+
+// AddRaceResult records a race a bot played.
+func (r *BotRepository) AddRaceResult(ctx context.Context, userID, slimeID string, score, gold, exp int, playedAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO race_results (user_id, slime_id, score, gold_reward, exp_reward, played_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		userID, slimeID, score, gold, exp, playedAt,
+	)
+	return err
+}
+
+// CreatePost writes a community post as a bot.
+func (r *BotRepository) CreatePost(ctx context.Context, userID, content, postType string, likes, views int, createdAt time.Time) (string, error) {
+	var postID string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO community_posts (user_id, content, post_type, likes, view_count, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id`,
+		userID, content, postType, likes, views, createdAt,
+	).Scan(&postID)
+	return postID, err
+}
+
+// RecentPost picks a random post written in the last week.
+func (r *BotRepository) RecentPost(ctx context.Context) (string, error) {
+	var postID string
+	err := r.pool.QueryRow(ctx,
+		`SELECT id FROM community_posts
+		 WHERE created_at > NOW() - INTERVAL '7 days'
+		 ORDER BY RANDOM() LIMIT 1`,
+	).Scan(&postID)
+	return postID, err
+}
+
+// CreateReply replies to a post as a bot, under the reply parentID when it is set.
+func (r *BotRepository) CreateReply(ctx context.Context, postID, userID, content, parentID string, createdAt time.Time) (string, error) {
+	var replyID string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO community_replies (post_id, user_id, content, parent_id, created_at)
+		 VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5)
+		 RETURNING id`,
+		postID, userID, content, parentID, createdAt,
+	).Scan(&replyID)
+	return replyID, err
+}
+
+// LikePost records a bot's like on a post.
+func (r *BotRepository) LikePost(ctx context.Context, postID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_post_likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		postID, userID,
+	)
+	return err
+}
+
+// LikeReply records a bot's like on a reply.
+func (r *BotRepository) LikeReply(ctx context.Context, replyID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_reply_likes (reply_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		replyID, userID,
+	)
+	return err
+}
+
+// RecountPosts sets the reply and like counts of posts from their rows.
+func (r *BotRepository) RecountPosts(ctx context.Context, postIDs []string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE community_posts p SET
+			reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = p.id),
+			likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = p.id)
+		 WHERE p.id = ANY($1::uuid[])`,
+		postIDs,
+	)
+	return err
+}
+
+// RecountReplies sets the nested reply and like counts of replies from their rows.
+func (r *BotRepository) RecountReplies(ctx context.Context, replyIDs []string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE community_replies r SET
+			reply_count = (SELECT COUNT(*) FROM community_replies WHERE parent_id = r.id),
+			likes = (SELECT COUNT(*) FROM community_reply_likes WHERE reply_id = r.id)
+		 WHERE r.id = ANY($1::uuid[])`,
+		replyIDs,
+	)
+	return err
+}
+
+// CreateShort posts a public short with no video as a bot.
+func (r *BotRepository) CreateShort(ctx context.Context, userID, title, description string, tags []string, category string, views, likes int, createdAt time.Time) (string, error) {
+	var shortID string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO shorts (user_id, title, description, video_url, thumbnail_url, tags, category, visibility, views, likes, comment_count, status, created_at)
+		 VALUES ($1, $2, $3, '', '', $4, $5, 'public', $6, $7, 0, 'active', $8)
+		 RETURNING id`,
+		userID, title, description, tags, category, views, likes, createdAt,
+	).Scan(&shortID)
+	return shortID, err
+}
+
+// CommentShort comments on a short as a bot.
+func (r *BotRepository) CommentShort(ctx context.Context, shortID, userID, content string, createdAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_comments (short_id, user_id, content, created_at)
+		 VALUES ($1, $2, $3, $4)`,
+		shortID, userID, content, createdAt,
+	)
+	return err
+}
+
+// LikeShort records a bot's like on a short.
+func (r *BotRepository) LikeShort(ctx context.Context, shortID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_likes (short_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		shortID, userID,
+	)
+	return err
+}
+
+// ShortCommentIDs lists the comments on a short.
+func (r *BotRepository) ShortCommentIDs(ctx context.Context, shortID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id::text FROM shorts_comments WHERE short_id = $1`, shortID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// LikeShortComment records a bot's like on a shorts comment.
+func (r *BotRepository) LikeShortComment(ctx context.Context, commentID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_comment_likes (comment_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		commentID, userID,
+	)
+	return err
+}
+
+// RecountShorts sets the comment and like counts of shorts, and the like counts
+// of their comments, from their rows.
+func (r *BotRepository) RecountShorts(ctx context.Context, shortIDs []string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE shorts s SET
+			comment_count = (SELECT COUNT(*) FROM shorts_comments WHERE short_id = s.id),
+			likes = (SELECT COUNT(*) FROM shorts_likes WHERE short_id = s.id)
+		 WHERE s.id = ANY($1::uuid[])`,
+		shortIDs,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx,
+		`UPDATE shorts_comments c SET likes = (SELECT COUNT(*) FROM shorts_comment_likes WHERE comment_id = c.id)
+		 WHERE c.short_id = ANY($1::uuid[])`,
+		shortIDs,
+	)
+	return err
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/slimetopia/server/internal/models"
+)
+
+var (
+	ErrBreedNotOwned = errors.New("not your slimes")
+	ErrEggNotFound   = errors.New("egg not found")
+	ErrEggNotReady   = errors.New("egg not ready")
+	ErrSlimeBoxFull  = errors.New("slime box is full")
+)
+
+// BreedLineage is what breeding has recorded of a slime.
+type BreedLineage struct {
+	ParentIDs  []string        // its own parents; empty for a founder
+	Genes      json.RawMessage // nil for a founder
+	Generation int             // 0 for a founder
+	LastBred   time.Time       // zero if it never has
+}
+
+// SlimeEgg is a bred egg; it carries everything the slime that hatches from it gets.
+type SlimeEgg struct {
+	ID             int64
+	ParentA        string
+	ParentB        string
+	ParentASpecies int
+	ParentBSpecies int
+	SpeciesID      int
+	Element        string
+	Personality    string
+	Talents        []int
+	Genes          json.RawMessage
+	Generation     int
+	HatchesAt      time.Time
+	CreatedAt      time.Time
+}
+
+// SlimeLineage is a slime_lineage row: a bred slime and its parents.
+type SlimeLineage struct {
+	SlimeID        string
+	ParentA        string
+	ParentB        string
+	ParentASpecies int
+	ParentBSpecies int
+	SpeciesID      int
+	Generation     int
+	Genes          json.RawMessage
+}
+
+// FamilySlime is a living slime as a family tree shows it.
+type FamilySlime struct {
+	ID          string
+	SpeciesID   int
+	Name        *string
+	Element     string
+	Personality string
+	Level       int
+}
+
+type BreedingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBreedingRepository(pool *pgxpool.Pool) *BreedingRepository {
+	return &BreedingRepository{pool: pool}
+}
+
+// LayEgg locks two of userID's slimes, so two requests can't both pass the cooldown,
+// and lays the egg lay makes from their lineage. A nil egg from lay lays nothing.
+// It returns ErrBreedNotOwned unless userID owns both.
+func (r *BreedingRepository) LayEgg(ctx context.Context, userID, parentA, parentB string, lay func(a, b *BreedLineage) (*SlimeEgg, error)) (*SlimeEgg, error) {
+	var egg *SlimeEgg
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var owned int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM (SELECT 1 FROM slimes WHERE id IN ($1, $2) AND user_id = $3 ORDER BY id FOR UPDATE) s`,
+			parentA, parentB, userID,
+		).Scan(&owned); err != nil {
+			return err
+		}
+		if owned != 2 {
+			return ErrBreedNotOwned
+		}
+
+		a, err := breedLineageTx(ctx, tx, parentA)
+		if err != nil {
+			return err
+		}
+		b, err := breedLineageTx(ctx, tx, parentB)
+		if err != nil {
+			return err
+		}
+		egg, err = lay(a, b)
+		if err != nil || egg == nil {
+			return err
+		}
+		egg.ParentA, egg.ParentB = parentA, parentB
+		return tx.QueryRow(ctx,
+			`INSERT INTO slime_eggs (user_id, parent_a, parent_b, parent_a_species, parent_b_species,
+			                         species_id, element, personality, talents, genes, generation, hatches_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			 RETURNING id, hatches_at, created_at`,
+			userID, parentA, parentB, egg.ParentASpecies, egg.ParentBSpecies,
+			egg.SpeciesID, egg.Element, egg.Personality, egg.Talents, egg.Genes,
+			egg.Generation, egg.HatchesAt,
+		).Scan(&egg.ID, &egg.HatchesAt, &egg.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return egg, nil
+}
+
+// breedLineageTx loads a slime's parents and genes, and when it last bred.
+func breedLineageTx(ctx context.Context, tx pgx.Tx, slimeID string) (*BreedLineage, error) {
+	var l BreedLineage
+	var parentA, parentB string
+	err := tx.QueryRow(ctx,
+		`SELECT parent_a::text, parent_b::text, genes, generation FROM slime_lineage WHERE slime_id = $1`, slimeID,
+	).Scan(&parentA, &parentB, &l.Genes, &l.Generation)
+	switch {
+	case err == nil:
+		l.ParentIDs = []string{parentA, parentB}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+
+	var lastBred *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT MAX(created_at) FROM slime_eggs WHERE parent_a = $1 OR parent_b = $1`, slimeID,
+	).Scan(&lastBred)
+	if err != nil {
+		return nil, err
+	}
+	if lastBred != nil {
+		l.LastBred = *lastBred
+	}
+	return &l, nil
+}
+
+// Eggs lists userID's eggs that haven't hatched yet, soonest first.
+func (r *BreedingRepository) Eggs(ctx context.Context, userID string) ([]SlimeEgg, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, species_id, parent_a::text, parent_b::text, hatches_at, created_at
+		 FROM slime_eggs WHERE user_id = $1 AND hatched_slime_id IS NULL
+		 ORDER BY hatches_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (SlimeEgg, error) {
+		var e SlimeEgg
+		err := row.Scan(&e.ID, &e.SpeciesID, &e.ParentA, &e.ParentB, &e.HatchesAt, &e.CreatedAt)
+		return e, err
+	})
+}
+
+// HatchEgg hatches one of userID's eggs into a slime, records its lineage and adds
+// it to the codex. It returns ErrEggNotFound, ErrSlimeBoxFull, or ErrEggNotReady
+// along with the egg.
+func (r *BreedingRepository) HatchEgg(ctx context.Context, userID string, eggID int64) (*SlimeEgg, *models.Slime, error) {
+	var egg SlimeEgg
+	var child *models.Slime
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`SELECT parent_a::text, parent_b::text, parent_a_species, parent_b_species, species_id,
+			        element, personality, talents, genes, generation, hatches_at, created_at
+			 FROM slime_eggs WHERE id = $1 AND user_id = $2 AND hatched_slime_id IS NULL FOR UPDATE`,
+			eggID, userID,
+		).Scan(&egg.ParentA, &egg.ParentB, &egg.ParentASpecies, &egg.ParentBSpecies, &egg.SpeciesID,
+			&egg.Element, &egg.Personality, &egg.Talents, &egg.Genes, &egg.Generation, &egg.HatchesAt, &egg.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrEggNotFound
+		}
+		if err != nil {
+			return err
+		}
+		egg.ID = eggID
+		if time.Now().Before(egg.HatchesAt) {
+			return ErrEggNotReady
+		}
+		free, err := FreeSlotsTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if free <= 0 {
+			return ErrSlimeBoxFull
+		}
+
+		var t [6]int
+		copy(t[:], egg.Talents)
+		child, err = CreateSlimeWithTalentsTx(ctx, tx, userID, egg.SpeciesID, egg.Element, egg.Personality, t)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO slime_lineage (slime_id, user_id, parent_a, parent_b, parent_a_species, parent_b_species,
+			                            species_id, generation, genes)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			child.ID, userID, egg.ParentA, egg.ParentB, egg.ParentASpecies, egg.ParentBSpecies,
+			egg.SpeciesID, egg.Generation, egg.Genes,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE slime_eggs SET hatched_slime_id = $2 WHERE id = $1`, eggID, child.ID); err != nil {
+			return err
+		}
+		return AddCodexEntryTx(ctx, tx, userID, egg.SpeciesID)
+	})
+	if errors.Is(err, ErrEggNotReady) {
+		return &egg, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &egg, child, nil
+}
+
+// Lineage returns the lineage rows of slimeID and of its bred ancestors, down to
+// depth generations counting the slime itself.
+func (r *BreedingRepository) Lineage(ctx context.Context, slimeID string, depth int) ([]SlimeLineage, error) {
+	// Every lineage row whose parents are still within depth
+	rows, err := r.pool.Query(ctx,
+		`WITH RECURSIVE tree AS (
+			SELECT slime_id, parent_a, parent_b, 1 AS depth FROM slime_lineage WHERE slime_id = $1
+			UNION
+			SELECT l.slime_id, l.parent_a, l.parent_b, t.depth + 1
+			FROM slime_lineage l JOIN tree t ON l.slime_id IN (t.parent_a, t.parent_b)
+			WHERE t.depth + 1 < $2
+		)
+		SELECT DISTINCT l.slime_id::text, l.parent_a::text, l.parent_b::text, l.parent_a_species, l.parent_b_species,
+		       l.species_id, l.generation, l.genes
+		FROM tree t JOIN slime_lineage l ON l.slime_id = t.slime_id`,
+		slimeID, depth)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (SlimeLineage, error) {
+		var l SlimeLineage
+		err := row.Scan(&l.SlimeID, &l.ParentA, &l.ParentB, &l.ParentASpecies, &l.ParentBSpecies,
+			&l.SpeciesID, &l.Generation, &l.Genes)
+		return l, err
+	})
+}
+
+// FamilySlimes returns those of ids that are slimes userID still has.
+func (r *BreedingRepository) FamilySlimes(ctx context.Context, userID string, ids []string) ([]FamilySlime, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id::text, species_id, name, element, personality, level FROM slimes
+		 WHERE id = ANY($1::uuid[]) AND user_id = $2`, ids, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (FamilySlime, error) {
+		var s FamilySlime
+		err := row.Scan(&s.ID, &s.SpeciesID, &s.Name, &s.Element, &s.Personality, &s.Level)
+		return s, err
+	})
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CollectionEntry is one species and personality a user has submitted.
+type CollectionEntry struct {
+	SpeciesID   int
+	Personality string
+}
+
+type CollectionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCollectionRepository(pool *pgxpool.Pool) *CollectionRepository {
+	return &CollectionRepository{pool: pool}
+}
+
+// Count counts userID's collection entries.
+func (r *CollectionRepository) Count(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM collection_entries WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+// Entries lists userID's collection entries by species, then personality.
+func (r *CollectionRepository) Entries(ctx context.Context, userID string) ([]CollectionEntry, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT species_id, personality FROM collection_entries WHERE user_id = $1 ORDER BY species_id, personality`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (CollectionEntry, error) {
+		var e CollectionEntry
+		err := row.Scan(&e.SpeciesID, &e.Personality)
+		return e, err
+	})
+}
+
+// Has reports whether userID has submitted a species and personality.
+func (r *CollectionRepository) Has(ctx context.Context, userID string, speciesID int, personality string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM collection_entries WHERE user_id = $1 AND species_id = $2 AND personality = $3)`,
+		userID, speciesID, personality,
+	).Scan(&exists)
+	return exists, err
+}
+
+// SpeciesCount counts userID's entries of one species.
+func (r *CollectionRepository) SpeciesCount(ctx context.Context, userID string, speciesID int) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM collection_entries WHERE user_id = $1 AND species_id = $2`,
+		userID, speciesID,
+	).Scan(&n)
+	return n, err
+}
+
+// SpeciesIDs lists the species userID has submitted at least once.
+func (r *CollectionRepository) SpeciesIDs(ctx context.Context, userID string) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT DISTINCT species_id FROM collection_entries WHERE user_id = $1 ORDER BY species_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// Submit adds a species and personality to userID's collection. Returns false if
+// it is already there.
+func (r *CollectionRepository) Submit(ctx context.Context, userID string, speciesID int, personality string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO collection_entries (user_id, species_id, personality) VALUES ($1, $2, $3)
+		 ON CONFLICT DO NOTHING`,
+		userID, speciesID, personality,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimedMilestones lists the collection milestones userID has claimed.
+func (r *CollectionRepository) ClaimedMilestones(ctx context.Context, userID string) ([]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT milestone FROM collection_milestones WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// ClaimMilestone records that userID claimed a milestone. Returns false if they
+// already had.
+func (r *CollectionRepository) ClaimMilestone(ctx context.Context, userID string, milestone int) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO collection_milestones (user_id, milestone) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		userID, milestone,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,586 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrReplyParentNotFound = errors.New("parent reply not found")
+	ErrReplyTooDeep        = errors.New("cannot nest replies deeper than 1 level")
+	ErrAlreadyReported     = errors.New("already reported")
+	ErrPollNotFound        = errors.New("poll not found")
+)
+
+// CommunityPost is a post as one viewer sees it: Liked, Bookmarked and MyReaction
+// are that viewer's.
+type CommunityPost struct {
+	ID              string
+	UserID          string
+	Nickname        string
+	ProfileImageURL string
+	Content         string
+	PostType        string
+	ImageURLs       []string
+	Likes           int
+	ReplyCount      int
+	ViewCount       int
+	BookmarkCount   int
+	ReactionCounts  map[string]int
+	CreatedAt       time.Time
+	Liked           bool
+	Bookmarked      bool
+	MyReaction      *string
+	Score           float64 // trending score; only set by TrendingPosts
+}
+
+// CommunityReply is a reply as one viewer sees it. ParentID is set on nested replies.
+type CommunityReply struct {
+	ID              string
+	UserID          string
+	Nickname        string
+	ProfileImageURL string
+	Content         string
+	ParentID        *string
+	Likes           int
+	ReplyCount      int
+	CreatedAt       time.Time
+	Liked           bool
+}
+
+type CommunityPoll struct {
+	ID        string
+	Options   []string
+	ExpiresAt time.Time
+}
+
+type BlockedUser struct {
+	UserID   string
+	Nickname string
+}
+
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+type CommunityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCommunityRepository(pool *pgxpool.Pool) *CommunityRepository {
+	return &CommunityRepository{pool: pool}
+}
+
+// communityPostColumns selects a CommunityPost for the viewer in $1 from posts p joined
+// with their authors u.
+const communityPostColumns = `p.id, p.user_id, u.nickname, COALESCE(u.profile_image_url, ''), p.content, p.post_type,
+	p.image_urls, p.likes, p.reply_count, p.view_count, p.bookmark_count, p.reaction_counts, p.created_at,
+	EXISTS(SELECT 1 FROM community_post_likes l WHERE l.post_id = p.id AND l.user_id = $1),
+	EXISTS(SELECT 1 FROM community_bookmarks b WHERE b.post_id = p.id AND b.user_id = $1),
+	(SELECT emoji FROM community_post_reactions WHERE post_id = p.id AND user_id = $1)`
+
+// notBlocked hides the posts and replies of users the viewer in $1 blocked.
+const notBlocked = `NOT IN (SELECT blocked_id FROM community_blocks WHERE blocker_id = $1::uuid)`
+
+func scanCommunityPost(row pgx.Row, extra ...any) (CommunityPost, error) {
+	var p CommunityPost
+	var reactions []byte
+	dest := append([]any{&p.ID, &p.UserID, &p.Nickname, &p.ProfileImageURL, &p.Content, &p.PostType,
+		&p.ImageURLs, &p.Likes, &p.ReplyCount, &p.ViewCount, &p.BookmarkCount, &reactions, &p.CreatedAt,
+		&p.Liked, &p.Bookmarked, &p.MyReaction}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return p, err
+	}
+	if p.ImageURLs == nil {
+		p.ImageURLs = []string{}
+	}
+	json.Unmarshal(reactions, &p.ReactionCounts)
+	if p.ReactionCounts == nil {
+		p.ReactionCounts = map[string]int{}
+	}
+	return p, nil
+}
+
+func collectCommunityPosts(rows pgx.Rows, err error) ([]CommunityPost, error) {
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (CommunityPost, error) {
+		return scanCommunityPost(row)
+	})
+}
+
+// Posts lists the newest posts for viewerID, skipping blocked authors. A search query
+// matches the full-text index or a substring and takes precedence over postType;
+// an empty postType lists every type.
+func (r *CommunityRepository) Posts(ctx context.Context, viewerID, postType, search string, limit, offset int) ([]CommunityPost, error) {
+	args := []any{viewerID, limit, offset}
+	filter := ""
+	switch {
+	case search != "":
+		filter = `AND (p.search_vector @@ plainto_tsquery('simple', $4) OR p.content ILIKE '%' || $4 || '%')`
+		args = append(args, search)
+	case postType != "":
+		filter = `AND p.post_type = $4`
+		args = append(args, postType)
+	}
+	return collectCommunityPosts(r.pool.Query(ctx, fmt.Sprintf(
+		`SELECT `+communityPostColumns+`
+		 FROM community_posts p
+		 JOIN users u ON u.id = p.user_id
+		 WHERE p.user_id `+notBlocked+` %s
+		 ORDER BY p.created_at DESC
+		 LIMIT $2 OFFSET $3`, filter),
+		args...,
+	))
+}
+
+// Bookmarks lists the posts userID bookmarked, most recently bookmarked first.
+func (r *CommunityRepository) Bookmarks(ctx context.Context, userID string, limit, offset int) ([]CommunityPost, error) {
+	return collectCommunityPosts(r.pool.Query(ctx,
+		`SELECT `+communityPostColumns+`
+		 FROM community_bookmarks bm
+		 JOIN community_posts p ON p.id = bm.post_id
+		 JOIN users u ON u.id = p.user_id
+		 WHERE bm.user_id = $1
+		 ORDER BY bm.created_at DESC
+		 LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	))
+}
+
+// TrendingPosts ranks the past week's posts by engagement, decayed with age.
+func (r *CommunityRepository) TrendingPosts(ctx context.Context, viewerID string, limit int) ([]CommunityPost, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+communityPostColumns+`,
+		        (
+		          (SELECT COALESCE(COUNT(*), 0) FROM community_post_reactions WHERE post_id = p.id) * 3
+		          + p.reply_count * 2
+		          + p.view_count * 0.5
+		          + p.bookmark_count * 2
+		        ) / SQRT(EXTRACT(EPOCH FROM (now() - p.created_at)) / 3600 + 2) AS score
+		 FROM community_posts p
+		 JOIN users u ON u.id = p.user_id
+		 WHERE p.created_at > now() - INTERVAL '7 days'
+		   AND p.user_id `+notBlocked+`
+		 ORDER BY score DESC
+		 LIMIT $2`,
+		viewerID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (CommunityPost, error) {
+		var score float64
+		p, err := scanCommunityPost(row, &score)
+		p.Score = score
+		return p, err
+	})
+}
+
+func (r *CommunityRepository) CreatePost(ctx context.Context, userID, content, postType string, imageURLs []string) (string, error) {
+	var postID string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO community_posts (user_id, content, post_type, image_urls) VALUES ($1, $2, $3, $4) RETURNING id`,
+		userID, content, postType, imageURLs,
+	).Scan(&postID)
+	return postID, err
+}
+
+// CreatePoll attaches a poll to a post.
+func (r *CommunityRepository) CreatePoll(ctx context.Context, postID string, options []string, expiresAt time.Time) error {
+	optionsJSON, _ := json.Marshal(options)
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_polls (post_id, options, expires_at) VALUES ($1, $2, $3)`,
+		postID, optionsJSON, expiresAt,
+	)
+	return err
+}
+
+// DeletePost deletes one of userID's posts; deleted is false if it isn't theirs.
+func (r *CommunityRepository) DeletePost(ctx context.Context, postID, userID string) (deleted bool, err error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM community_posts WHERE id = $1 AND user_id = $2`, postID, userID)
+	return tag.RowsAffected() > 0, err
+}
+
+// LikePost records userID's like and returns the post's new like count.
+func (r *CommunityRepository) LikePost(ctx context.Context, postID, userID string) (int, error) {
+	return r.setPostLike(ctx,
+		`INSERT INTO community_post_likes (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, postID, userID)
+}
+
+// UnlikePost removes userID's like and returns the post's new like count.
+func (r *CommunityRepository) UnlikePost(ctx context.Context, postID, userID string) (int, error) {
+	return r.setPostLike(ctx,
+		`DELETE FROM community_post_likes WHERE post_id = $1 AND user_id = $2`, postID, userID)
+}
+
+func (r *CommunityRepository) setPostLike(ctx context.Context, stmt, postID, userID string) (int, error) {
+	var likes int
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, stmt, postID, userID); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx,
+			`UPDATE community_posts SET likes = (SELECT COUNT(*) FROM community_post_likes WHERE post_id = $1) WHERE id = $1 RETURNING likes`,
+			postID,
+		).Scan(&likes)
+	})
+	return likes, err
+}
+
+// RecordView counts userID's view of a post once.
+func (r *CommunityRepository) RecordView(ctx context.Context, postID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_post_views (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		postID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx,
+		`UPDATE community_posts SET view_count = (SELECT COUNT(*) FROM community_post_views WHERE post_id = $1) WHERE id = $1`,
+		postID,
+	)
+	return err
+}
+
+// React sets userID's emoji on a post, replacing any earlier one, and returns the
+// post's reaction counts.
+func (r *CommunityRepository) React(ctx context.Context, postID, userID, emoji string) (map[string]int, error) {
+	return r.setReaction(ctx, postID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO community_post_reactions (post_id, user_id, emoji)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT (post_id, user_id)
+			 DO UPDATE SET emoji = $3`,
+			postID, userID, emoji,
+		)
+		return err
+	})
+}
+
+// Unreact removes userID's emoji from a post and returns the post's reaction counts.
+func (r *CommunityRepository) Unreact(ctx context.Context, postID, userID string) (map[string]int, error) {
+	return r.setReaction(ctx, postID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `DELETE FROM community_post_reactions WHERE post_id = $1 AND user_id = $2`, postID, userID)
+		return err
+	})
+}
+
+func (r *CommunityRepository) setReaction(ctx context.Context, postID string, change func(pgx.Tx) error) (map[string]int, error) {
+	var raw []byte
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := change(tx); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx,
+			`UPDATE community_posts SET reaction_counts = (
+				SELECT COALESCE(jsonb_object_agg(emoji, cnt), '{}')
+				FROM (SELECT emoji, COUNT(*)::int as cnt FROM community_post_reactions WHERE post_id = $1 GROUP BY emoji) sub
+			 ) WHERE id = $1 RETURNING reaction_counts`,
+			postID,
+		).Scan(&raw)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts map[string]int
+	json.Unmarshal(raw, &counts)
+	return counts, nil
+}
+
+// Bookmark saves a post to userID's bookmarks.
+func (r *CommunityRepository) Bookmark(ctx context.Context, postID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_bookmarks (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		postID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return r.recountBookmarks(ctx, postID)
+}
+
+// Unbookmark removes a post from userID's bookmarks.
+func (r *CommunityRepository) Unbookmark(ctx context.Context, postID, userID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM community_bookmarks WHERE post_id = $1 AND user_id = $2`, postID, userID)
+	if err != nil {
+		return err
+	}
+	return r.recountBookmarks(ctx, postID)
+}
+
+func (r *CommunityRepository) recountBookmarks(ctx context.Context, postID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE community_posts SET bookmark_count = (SELECT COUNT(*) FROM community_bookmarks WHERE post_id = $1) WHERE id = $1`,
+		postID,
+	)
+	return err
+}
+
+// Replies lists a post's replies in order for viewerID, skipping blocked authors.
+func (r *CommunityRepository) Replies(ctx context.Context, viewerID, postID string) ([]CommunityReply, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT r.id, r.user_id, u.nickname, COALESCE(u.profile_image_url, ''), r.content, r.parent_id,
+		        r.likes, r.reply_count, r.created_at,
+		        EXISTS(SELECT 1 FROM community_reply_likes l WHERE l.reply_id = r.id AND l.user_id = $1)
+		 FROM community_replies r
+		 JOIN users u ON u.id = r.user_id
+		 WHERE r.post_id = $2
+		   AND r.user_id `+notBlocked+`
+		 ORDER BY r.created_at ASC
+		 LIMIT 200`,
+		viewerID, postID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (CommunityReply, error) {
+		var r CommunityReply
+		err := row.Scan(&r.ID, &r.UserID, &r.Nickname, &r.ProfileImageURL, &r.Content, &r.ParentID,
+			&r.Likes, &r.ReplyCount, &r.CreatedAt, &r.Liked)
+		return r, err
+	})
+}
+
+// CreateReply replies to a post, or to a top-level reply when parentID is set.
+// Replies nest one level deep.
+func (r *CommunityRepository) CreateReply(ctx context.Context, postID, userID, content, parentID string) (string, error) {
+	var replyID string
+	if parentID != "" {
+		var grandparentID *string
+		err := r.pool.QueryRow(ctx,
+			`SELECT parent_id FROM community_replies WHERE id = $1 AND post_id = $2`,
+			parentID, postID,
+		).Scan(&grandparentID)
+		if err != nil {
+			return "", ErrReplyParentNotFound
+		}
+		if grandparentID != nil {
+			return "", ErrReplyTooDeep
+		}
+		err = r.pool.QueryRow(ctx,
+			`INSERT INTO community_replies (post_id, user_id, content, parent_id) VALUES ($1, $2, $3, $4) RETURNING id`,
+			postID, userID, content, parentID,
+		).Scan(&replyID)
+		if err != nil {
+			return "", err
+		}
+		r.recountNestedReplies(ctx, parentID)
+	} else {
+		err := r.pool.QueryRow(ctx,
+			`INSERT INTO community_replies (post_id, user_id, content) VALUES ($1, $2, $3) RETURNING id`,
+			postID, userID, content,
+		).Scan(&replyID)
+		if err != nil {
+			return "", err
+		}
+	}
+	r.recountReplies(ctx, postID)
+	return replyID, nil
+}
+
+// DeleteReply deletes one of userID's replies; deleted is false if it isn't theirs.
+func (r *CommunityRepository) DeleteReply(ctx context.Context, replyID, userID string) (deleted bool, err error) {
+	var postID string
+	var parentID *string
+	err = r.pool.QueryRow(ctx,
+		`DELETE FROM community_replies WHERE id = $1 AND user_id = $2 RETURNING post_id, parent_id`,
+		replyID, userID,
+	).Scan(&postID, &parentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if parentID != nil {
+		r.recountNestedReplies(ctx, *parentID)
+	}
+	r.recountReplies(ctx, postID)
+	return true, nil
+}
+
+func (r *CommunityRepository) recountReplies(ctx context.Context, postID string) {
+	r.pool.Exec(ctx,
+		`UPDATE community_posts SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE post_id = $1) WHERE id = $1`,
+		postID,
+	)
+}
+
+func (r *CommunityRepository) recountNestedReplies(ctx context.Context, parentID string) {
+	r.pool.Exec(ctx,
+		`UPDATE community_replies SET reply_count = (SELECT COUNT(*) FROM community_replies WHERE parent_id = $1) WHERE id = $1`,
+		parentID,
+	)
+}
+
+// LikeReply records userID's like on a reply.
+func (r *CommunityRepository) LikeReply(ctx context.Context, replyID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_reply_likes (reply_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		replyID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return r.recountReplyLikes(ctx, replyID)
+}
+
+// UnlikeReply removes userID's like from a reply.
+func (r *CommunityRepository) UnlikeReply(ctx context.Context, replyID, userID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM community_reply_likes WHERE reply_id = $1 AND user_id = $2`, replyID, userID)
+	if err != nil {
+		return err
+	}
+	return r.recountReplyLikes(ctx, replyID)
+}
+
+func (r *CommunityRepository) recountReplyLikes(ctx context.Context, replyID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE community_replies SET likes = (SELECT COUNT(*) FROM community_reply_likes WHERE reply_id = $1) WHERE id = $1`,
+		replyID,
+	)
+	return err
+}
+
+// Report files a report on a post, reply or user. Each reporter reports a target once.
+func (r *CommunityRepository) Report(ctx context.Context, reporterID, targetType, targetID, reason, detail string) error {
+	var exists bool
+	r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM community_reports WHERE reporter_id = $1 AND target_type = $2 AND target_id = $3)`,
+		reporterID, targetType, targetID,
+	).Scan(&exists)
+	if exists {
+		return ErrAlreadyReported
+	}
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_reports (reporter_id, target_type, target_id, reason, detail) VALUES ($1, $2, $3, $4, $5)`,
+		reporterID, targetType, targetID, reason, detail,
+	)
+	return err
+}
+
+// Block hides blockedID's posts and replies from blockerID.
+func (r *CommunityRepository) Block(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_blocks (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		blockerID, blockedID,
+	)
+	return err
+}
+
+func (r *CommunityRepository) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM community_blocks WHERE blocker_id = $1 AND blocked_id = $2`,
+		blockerID, blockedID,
+	)
+	return err
+}
+
+// BlockedUsers lists who userID blocked, most recent first.
+func (r *CommunityRepository) BlockedUsers(ctx context.Context, userID string) ([]BlockedUser, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT b.blocked_id, u.nickname FROM community_blocks b
+		 JOIN users u ON u.id = b.blocked_id
+		 WHERE b.blocker_id = $1
+		 ORDER BY b.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (BlockedUser, error) {
+		var b BlockedUser
+		err := row.Scan(&b.UserID, &b.Nickname)
+		return b, err
+	})
+}
+
+// TrendingTags counts the hashtags of the past week's posts, once per post.
+func (r *CommunityRepository) TrendingTags(ctx context.Context, limit int) ([]TagCount, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT tag, COUNT(*) as cnt FROM (
+		   SELECT DISTINCT unnest(regexp_matches(content, '#([^\s#]+)', 'g')) as tag, id
+		   FROM community_posts
+		   WHERE created_at > now() - INTERVAL '7 days'
+		 ) sub
+		 GROUP BY tag ORDER BY cnt DESC LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (TagCount, error) {
+		var t TagCount
+		err := row.Scan(&t.Tag, &t.Count)
+		return t, err
+	})
+}
+
+// Poll returns a post's poll, or ErrPollNotFound.
+func (r *CommunityRepository) Poll(ctx context.Context, postID string) (*CommunityPoll, error) {
+	var p CommunityPoll
+	var options []byte
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, options, expires_at FROM community_polls WHERE post_id = $1`,
+		postID,
+	).Scan(&p.ID, &options, &p.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrPollNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(options, &p.Options)
+	return &p, nil
+}
+
+// PollVotes counts a poll's votes per option; myVote is userID's option, if they voted.
+func (r *CommunityRepository) PollVotes(ctx context.Context, pollID, userID string) (counts map[int]int, myVote *int, err error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT option_index, COUNT(*) FROM community_poll_votes WHERE poll_id = $1 GROUP BY option_index`,
+		pollID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts = make(map[int]int)
+	var idx, cnt int
+	_, err = pgx.ForEachRow(rows, []any{&idx, &cnt}, func() error {
+		counts[idx] = cnt
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mine int
+	if r.pool.QueryRow(ctx,
+		`SELECT option_index FROM community_poll_votes WHERE poll_id = $1 AND user_id = $2`,
+		pollID, userID,
+	).Scan(&mine) == nil {
+		myVote = &mine
+	}
+	return counts, myVote, nil
+}
+
+// Vote records userID's choice on a poll, replacing an earlier one.
+func (r *CommunityRepository) Vote(ctx context.Context, pollID, userID string, option int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO community_poll_votes (poll_id, user_id, option_index)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (poll_id, user_id)
+		 DO UPDATE SET option_index = $3`,
+		pollID, userID, option,
+	)
+	return err
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/slimetopia/server/internal/models"
+)
+
+// MaxFriends is the friend cap per player. Pending outgoing requests count against it
+// so a player can't queue up more friends than they could hold.
+const MaxFriends = 50
+
+var (
+	ErrAlreadyFriends        = errors.New("already friends")
+	ErrNotFriends            = errors.New("not friends")
+	ErrFriendRequestExists   = errors.New("friend request already sent")
+	ErrFriendRequestNotFound = errors.New("friend request not found")
+	ErrFriendLimit           = errors.New("friend limit reached")
+	ErrFriendLimitOther      = errors.New("other player's friend limit reached")
+	ErrFriendBlocked         = errors.New("friend request blocked")
+)
+
+type FriendRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFriendRepository(pool *pgxpool.Pool) *FriendRepository {
+	return &FriendRepository{pool: pool}
+}
+
+// FindByFriendCode resolves a friend code (case-insensitive) to a user ID.
+func (r *FriendRepository) FindByFriendCode(ctx context.Context, code string) (string, error) {
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`SELECT id::text FROM users WHERE friend_code = $1`,
+		strings.ToUpper(strings.TrimSpace(code)),
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	return id, err
+}
+
+func (r *FriendRepository) FriendCode(ctx context.Context, userID string) (string, error) {
+	var code string
+	err := r.pool.QueryRow(ctx, `SELECT friend_code FROM users WHERE id = $1`, userID).Scan(&code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUserNotFound
+	}
+	return code, err
+}
+
+// SendRequest asks receiverID to be friends. If receiverID already asked senderID,
+// the two become friends immediately and accepted is true.
+func (r *FriendRepository) SendRequest(ctx context.Context, senderID, receiverID string) (accepted bool, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := lockUsers(ctx, tx, senderID, receiverID); err != nil {
+			return err
+		}
+
+		var blocked, friends bool
+		err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM community_blocks
+			                WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)),
+			        EXISTS (SELECT 1 FROM friendships WHERE user_id = $1 AND friend_id = $2)`,
+			senderID, receiverID,
+		).Scan(&blocked, &friends)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return ErrFriendBlocked
+		}
+		if friends {
+			return ErrAlreadyFriends
+		}
+
+		// They already asked us: treat this as accepting their request
+		var reverseID string
+		err = tx.QueryRow(ctx,
+			`SELECT id::text FROM friend_requests
+			 WHERE sender_id = $1 AND receiver_id = $2 AND status = 'pending'`,
+			receiverID, senderID,
+		).Scan(&reverseID)
+		if err == nil {
+			accepted = true
+			return acceptTx(ctx, tx, reverseID, receiverID, senderID)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		used, err := friendSlotsUsed(ctx, tx, senderID)
+		if err != nil {
+			return err
+		}
+		if used >= MaxFriends {
+			return ErrFriendLimit
+		}
+
+		tag, err := tx.Exec(ctx,
+			`INSERT INTO friend_requests (sender_id, receiver_id) VALUES ($1, $2)
+			 ON CONFLICT (sender_id, receiver_id) WHERE status = 'pending' DO NOTHING`,
+			senderID, receiverID,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrFriendRequestExists
+		}
+		return nil
+	})
+	return accepted, err
+}
+
+// Accept accepts a pending request addressed to userID and returns the new friend's ID.
+func (r *FriendRepository) Accept(ctx context.Context, userID, requestID string) (string, error) {
+	var senderID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`SELECT sender_id::text FROM friend_requests
+			 WHERE id = $1 AND receiver_id = $2 AND status = 'pending'`,
+			requestID, userID,
+		).Scan(&senderID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrFriendRequestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := lockUsers(ctx, tx, senderID, userID); err != nil {
+			return err
+		}
+		return acceptTx(ctx, tx, requestID, senderID, userID)
+	})
+	return senderID, err
+}
+
+// acceptTx turns a pending request into a friendship. Both users must already be locked.
+func acceptTx(ctx context.Context, tx pgx.Tx, requestID, senderID, receiverID string) error {
+	// The sender's slot is already held by the pending request
+	senderFriends, err := friendCount(ctx, tx, senderID)
+	if err != nil {
+		return err
+	}
+	receiverSlots, err := friendSlotsUsed(ctx, tx, receiverID)
+	if err != nil {
+		return err
+	}
+	if receiverSlots >= MaxFriends {
+		return ErrFriendLimit
+	}
+	if senderFriends >= MaxFriends {
+		return ErrFriendLimitOther
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE friend_requests SET status = 'accepted', responded_at = NOW()
+		 WHERE id = $1 AND status = 'pending'`,
+		requestID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFriendRequestNotFound
+	}
+	// A crossed request in the other direction is settled too
+	_, err = tx.Exec(ctx,
+		`UPDATE friend_requests SET status = 'accepted', responded_at = NOW()
+		 WHERE sender_id = $1 AND receiver_id = $2 AND status = 'pending'`,
+		receiverID, senderID,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO friendships (user_id, friend_id) VALUES ($1, $2), ($2, $1)
+		 ON CONFLICT DO NOTHING`,
+		senderID, receiverID,
+	)
+	return err
+}
+
+// Decline declines a pending request addressed to userID.
+func (r *FriendRepository) Decline(ctx context.Context, userID, requestID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE friend_requests SET status = 'declined', responded_at = NOW()
+		 WHERE id = $1 AND receiver_id = $2 AND status = 'pending'`,
+		requestID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// Cancel withdraws a pending request userID sent.
+func (r *FriendRepository) Cancel(ctx context.Context, userID, requestID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM friend_requests WHERE id = $1 AND sender_id = $2 AND status = 'pending'`,
+		requestID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFriendRequestNotFound
+	}
+	return nil
+}
+
+// Remove ends a friendship in both directions and drops any pending requests between
+// the two players.
+func (r *FriendRepository) Remove(ctx context.Context, userID, friendID string) error {
+	var removed int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`DELETE FROM friendships
+			 WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`,
+			userID, friendID,
+		)
+		if err != nil {
+			return err
+		}
+		removed = tag.RowsAffected()
+		_, err = tx.Exec(ctx,
+			`DELETE FROM friend_requests WHERE status = 'pending'
+			   AND ((sender_id = $1 AND receiver_id = $2) OR (sender_id = $2 AND receiver_id = $1))`,
+			userID, friendID,
+		)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrNotFriends
+	}
+	return nil
+}
+
+func (r *FriendRepository) AreFriends(ctx context.Context, userID, otherID string) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM friendships WHERE user_id = $1 AND friend_id = $2)`,
+		userID, otherID,
+	).Scan(&ok)
+	return ok, err
+}
+
+func (r *FriendRepository) FriendIDs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT friend_id::text FROM friendships WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *FriendRepository) ListFriends(ctx context.Context, userID string) ([]models.Friend, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT u.id::text, u.nickname, u.level, COALESCE(u.profile_image_url, ''), f.created_at
+		 FROM friendships f JOIN users u ON u.id = f.friend_id
+		 WHERE f.user_id = $1
+		 ORDER BY u.nickname`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	friends := make([]models.Friend, 0)
+	for rows.Next() {
+		var f models.Friend
+		if err := rows.Scan(&f.UserID, &f.Nickname, &f.Level, &f.ProfileImageURL, &f.Since); err != nil {
+			return nil, err
+		}
+		friends = append(friends, f)
+	}
+	return friends, rows.Err()
+}
+
+// ListRequests returns pending requests sent to and by userID, newest first.
+func (r *FriendRepository) ListRequests(ctx context.Context, userID string) ([]models.FriendRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT fr.id::text, u.id::text, u.nickname, u.level, fr.receiver_id = $1, fr.created_at
+		 FROM friend_requests fr
+		 JOIN users u ON u.id = CASE WHEN fr.receiver_id = $1 THEN fr.sender_id ELSE fr.receiver_id END
+		 WHERE (fr.receiver_id = $1 OR fr.sender_id = $1) AND fr.status = 'pending'
+		 ORDER BY fr.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]models.FriendRequest, 0)
+	for rows.Next() {
+		var fr models.FriendRequest
+		if err := rows.Scan(&fr.ID, &fr.UserID, &fr.Nickname, &fr.Level, &fr.Incoming, &fr.CreatedAt); err != nil {
+			return nil, err
+		}
+		requests = append(requests, fr)
+	}
+	return requests, rows.Err()
+}
+
+// lockUsers serializes friend changes between two players. Rows are locked in a fixed
+// order so two requests crossing each other can't deadlock.
+func lockUsers(ctx context.Context, tx pgx.Tx, a, b string) error {
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM users WHERE id IN ($1, $2) ORDER BY id FOR UPDATE`,
+		a, b,
+	)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if n < 2 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func friendCount(ctx context.Context, tx pgx.Tx, userID string) (int, error) {
+	var n int
+	err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM friendships WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+// friendSlotsUsed counts friends plus pending requests the user has sent.
+func friendSlotsUsed(ctx context.Context, tx pgx.Tx, userID string) (int, error) {
+	var n int
+	err := tx.QueryRow(ctx,
+		`SELECT (SELECT COUNT(*) FROM friendships WHERE user_id = $1)
+		      + (SELECT COUNT(*) FROM friend_requests WHERE sender_id = $1 AND status = 'pending')`,
+		userID,
+	).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/slimetopia/server/internal/models"
+)
+
+// GachaHatch is a slime rolled from a banner, before it is written.
+type GachaHatch struct {
+	SpeciesID   int
+	Element     string
+	Personality string
+}
+
+// GachaPull is everything a committed egg purchase produced, in roll order.
+type GachaPull struct {
+	Slimes         []models.Slime
+	FirstDiscovery []bool
+}
+
+type GachaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGachaRepository(pool *pgxpool.Pool) *GachaRepository {
+	return &GachaRepository{pool: pool}
+}
+
+// Pity returns userID's pull counter on each banner they have pulled from.
+func (r *GachaRepository) Pity(ctx context.Context, userID string) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT egg_type, pull_count FROM gacha_pity WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	var eggType string
+	var count int
+	_, err = pgx.ForEachRow(rows, []any{&eggType, &count}, func() error {
+		counts[eggType] = count
+		return nil
+	})
+	return counts, err
+}
+
+// Pull charges gold and gems for n eggs from bannerID and writes the slimes, codex
+// entries, first discoveries and pull counter in one transaction. roll gets the
+// counter and returns the hatches, the new counter and whether a high grade reset
+// it. Capacity is checked before roll is called; on ErrSlimeBoxFull, available
+// reports the free slots.
+func (r *GachaRepository) Pull(ctx context.Context, userID, nickname, bannerID string, n int, gold int64, gems int, refID string, roll func(pity int) ([]GachaHatch, int, bool, error)) (res *GachaPull, available int, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		if available, err = FreeSlotsTx(ctx, tx, userID); err != nil {
+			return err
+		}
+		if available < n {
+			return ErrSlimeBoxFull
+		}
+
+		if err := ApplyCurrencyTx(ctx, tx, userID, -gold, -gems, 0, ReasonShopPurchase, refID, true); err != nil {
+			return err
+		}
+
+		pity, err := GachaPityTx(ctx, tx, userID, bannerID)
+		if err != nil {
+			return err
+		}
+		hatches, newPity, reset, err := roll(pity)
+		if err != nil {
+			return err
+		}
+
+		res = &GachaPull{}
+		for _, h := range hatches {
+			slime, err := CreateSlimeTx(ctx, tx, userID, h.SpeciesID, h.Element, h.Personality)
+			if err != nil {
+				return err
+			}
+			if err := AddCodexEntryTx(ctx, tx, userID, h.SpeciesID); err != nil {
+				return err
+			}
+			first, err := RecordFirstDiscoveryTx(ctx, tx, userID, h.SpeciesID, nickname)
+			if err != nil {
+				return err
+			}
+			res.Slimes = append(res.Slimes, *slime)
+			res.FirstDiscovery = append(res.FirstDiscovery, first)
+		}
+
+		return SetGachaPityTx(ctx, tx, userID, bannerID, newPity, reset)
+	})
+	if err != nil {
+		return nil, available, err
+	}
+	return res, available, nil
+}
+
+// GachaPityTx returns the user's pull counter on a banner inside tx, creating it at
+// zero on the first pull. The row stays locked until tx ends.
+func GachaPityTx(ctx context.Context, tx pgx.Tx, userID, bannerID string) (int, error) {
+	var pity int
+	err := tx.QueryRow(ctx,
+		`INSERT INTO gacha_pity (user_id, egg_type, pull_count) VALUES ($1, $2, 0)
+		 ON CONFLICT (user_id, egg_type) DO UPDATE SET pull_count = gacha_pity.pull_count
+		 RETURNING pull_count`,
+		userID, bannerID,
+	).Scan(&pity)
+	return pity, err
+}
+
+// SetGachaPityTx stores the pull counter after a purchase. highGrade stamps the time
+// of the pull that reset it.
+func SetGachaPityTx(ctx context.Context, tx pgx.Tx, userID, bannerID string, pity int, highGrade bool) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE gacha_pity SET pull_count = $3,
+		        last_high_grade_at = CASE WHEN $4 THEN NOW() ELSE last_high_grade_at END
+		 WHERE user_id = $1 AND egg_type = $2`,
+		userID, bannerID, pity, highGrade,
+	)
+	return err
+}
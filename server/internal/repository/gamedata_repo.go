@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -78,15 +79,16 @@ type GameAchievement struct {
 }
 
 type GameAccessory struct {
-	ID         int    `json:"id"`
-	Name       string `json:"name"`
-	NameEN     string `json:"name_en"`
-	Slot       string `json:"slot"`
-	Icon       string `json:"icon"`
-	CostGold   int    `json:"cost_gold"`
-	CostGems   int    `json:"cost_gems"`
-	SvgOverlay string `json:"svg_overlay"`
-	IsActive   bool   `json:"is_active"`
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	NameEN     string          `json:"name_en"`
+	Slot       string          `json:"slot"`
+	Icon       string          `json:"icon"`
+	CostGold   int             `json:"cost_gold"`
+	CostGems   int             `json:"cost_gems"`
+	SvgOverlay string          `json:"svg_overlay"`
+	Effect     json.RawMessage `json:"effect"` // combat bonuses, see combat.Effects
+	IsActive   bool            `json:"is_active"`
 }
 
 type GameMission struct {
@@ -143,6 +145,26 @@ type GameSlimeSet struct {
 	IsActive    bool   `json:"is_active"`
 }
 
+type GameGachaBanner struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	NameEN           string             `json:"name_en"`
+	Description      string             `json:"description"`
+	GradeWeights     map[string]float64 `json:"grade_weights"`
+	LuckGradeWeights map[string]float64 `json:"luck_grade_weights"`
+	Elements         []string           `json:"elements"`
+	SpeciesPool      []int              `json:"species_pool"`
+	RateUpSpecies    []int              `json:"rate_up_species"`
+	RateUpShare      float64            `json:"rate_up_share"`
+	StartsAt         *time.Time         `json:"starts_at"`
+	EndsAt           *time.Time         `json:"ends_at"`
+	PityRare         int                `json:"pity_rare"`
+	PityEpic         int                `json:"pity_epic"`
+	PityLegendary    int                `json:"pity_legendary"`
+	IsActive         bool               `json:"is_active"`
+	SortOrder        int                `json:"sort_order"`
+}
+
 type GameMutationRecipe struct {
 	ID                 int    `json:"id"`
 	RequiredElementA   string `json:"required_element_a"`
@@ -153,6 +175,27 @@ type GameMutationRecipe struct {
 	IsActive           bool   `json:"is_active"`
 }
 
+type GameCraftingIngredient struct {
+	MaterialID int `json:"material_id"`
+	Quantity   int `json:"quantity"`
+}
+
+type GameCraftingRecipe struct {
+	ID          int                      `json:"id"`
+	Name        string                   `json:"name"`
+	ResultType  string                   `json:"result_type"`
+	ResultID    int                      `json:"result_id"`
+	ResultQty   int                      `json:"result_qty"`
+	Ingredients []GameCraftingIngredient `json:"ingredients"`
+}
+
+type GameAwakeningCost struct {
+	GoldCost     int  `json:"gold_cost"`
+	StardustCost int  `json:"stardust_cost"`
+	MaterialID   *int `json:"material_id"`
+	MaterialQty  int  `json:"material_qty"`
+}
+
 // ===== Repository =====
 
 type GameDataRepository struct {
@@ -494,7 +537,7 @@ func (r *GameDataRepository) DeleteAchievement(ctx context.Context, key string)
 
 func (r *GameDataRepository) GetAllAccessories(ctx context.Context) ([]GameAccessory, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, is_active
+		`SELECT id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, effect, is_active
 		 FROM game_accessories WHERE is_active = true ORDER BY id`)
 	if err != nil {
 		return nil, err
@@ -502,14 +545,14 @@ func (r *GameDataRepository) GetAllAccessories(ctx context.Context) ([]GameAcces
 	defer rows.Close()
 	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (GameAccessory, error) {
 		var a GameAccessory
-		err := row.Scan(&a.ID, &a.Name, &a.NameEN, &a.Slot, &a.Icon, &a.CostGold, &a.CostGems, &a.SvgOverlay, &a.IsActive)
+		err := row.Scan(&a.ID, &a.Name, &a.NameEN, &a.Slot, &a.Icon, &a.CostGold, &a.CostGems, &a.SvgOverlay, &a.Effect, &a.IsActive)
 		return a, err
 	})
 }
 
 func (r *GameDataRepository) GetAllAccessoriesIncludeInactive(ctx context.Context) ([]GameAccessory, error) {
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, is_active
+		`SELECT id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, effect, is_active
 		 FROM game_accessories ORDER BY id`)
 	if err != nil {
 		return nil, err
@@ -517,7 +560,7 @@ func (r *GameDataRepository) GetAllAccessoriesIncludeInactive(ctx context.Contex
 	defer rows.Close()
 	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (GameAccessory, error) {
 		var a GameAccessory
-		err := row.Scan(&a.ID, &a.Name, &a.NameEN, &a.Slot, &a.Icon, &a.CostGold, &a.CostGems, &a.SvgOverlay, &a.IsActive)
+		err := row.Scan(&a.ID, &a.Name, &a.NameEN, &a.Slot, &a.Icon, &a.CostGold, &a.CostGems, &a.SvgOverlay, &a.Effect, &a.IsActive)
 		return a, err
 	})
 }
@@ -525,9 +568,9 @@ func (r *GameDataRepository) GetAllAccessoriesIncludeInactive(ctx context.Contex
 func (r *GameDataRepository) GetAccessoryByID(ctx context.Context, id int) (*GameAccessory, error) {
 	var a GameAccessory
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, is_active
+		`SELECT id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, effect, is_active
 		 FROM game_accessories WHERE id = $1`, id).Scan(
-		&a.ID, &a.Name, &a.NameEN, &a.Slot, &a.Icon, &a.CostGold, &a.CostGems, &a.SvgOverlay, &a.IsActive)
+		&a.ID, &a.Name, &a.NameEN, &a.Slot, &a.Icon, &a.CostGold, &a.CostGems, &a.SvgOverlay, &a.Effect, &a.IsActive)
 	if err != nil {
 		return nil, err
 	}
@@ -536,17 +579,17 @@ func (r *GameDataRepository) GetAccessoryByID(ctx context.Context, id int) (*Gam
 
 func (r *GameDataRepository) CreateAccessory(ctx context.Context, a *GameAccessory) error {
 	_, err := r.pool.Exec(ctx,
-		`INSERT INTO game_accessories (id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, is_active)
-		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
-		a.ID, a.Name, a.NameEN, a.Slot, a.Icon, a.CostGold, a.CostGems, a.SvgOverlay, a.IsActive)
+		`INSERT INTO game_accessories (id, name, name_en, slot, icon, cost_gold, cost_gems, svg_overlay, is_active, effect)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		a.ID, a.Name, a.NameEN, a.Slot, a.Icon, a.CostGold, a.CostGems, a.SvgOverlay, a.IsActive, a.Effect)
 	return err
 }
 
 func (r *GameDataRepository) UpdateAccessory(ctx context.Context, a *GameAccessory) error {
 	_, err := r.pool.Exec(ctx,
-		`UPDATE game_accessories SET name=$2, name_en=$3, slot=$4, icon=$5, cost_gold=$6, cost_gems=$7, svg_overlay=$8, is_active=$9
+		`UPDATE game_accessories SET name=$2, name_en=$3, slot=$4, icon=$5, cost_gold=$6, cost_gems=$7, svg_overlay=$8, is_active=$9, effect=$10
 		 WHERE id=$1`,
-		a.ID, a.Name, a.NameEN, a.Slot, a.Icon, a.CostGold, a.CostGems, a.SvgOverlay, a.IsActive)
+		a.ID, a.Name, a.NameEN, a.Slot, a.Icon, a.CostGold, a.CostGems, a.SvgOverlay, a.IsActive, a.Effect)
 	return err
 }
 
@@ -744,6 +787,17 @@ func (r *GameDataRepository) DeleteSeason(ctx context.Context, id int) error {
 	return err
 }
 
+// CloseEndedSeasons deactivates seasons whose end date has passed and returns how
+// many it closed.
+func (r *GameDataRepository) CloseEndedSeasons(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE game_seasons SET is_active = false WHERE is_active = true AND end_date < CURRENT_DATE`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 // ===== Slime Sets =====
 
 func (r *GameDataRepository) GetAllSets(ctx context.Context) ([]GameSlimeSet, error) {
@@ -852,6 +906,133 @@ func (r *GameDataRepository) DeleteMutationRecipe(ctx context.Context, id int) e
 	return err
 }
 
+// ===== Gacha Banners =====
+
+const gachaBannerColumns = `id, name, name_en, description, grade_weights, luck_grade_weights, elements, species_pool,
+	rate_up_species, rate_up_share, starts_at, ends_at, pity_rare, pity_epic, pity_legendary, is_active, sort_order`
+
+func scanGachaBanner(row pgx.Row) (GameGachaBanner, error) {
+	var b GameGachaBanner
+	err := row.Scan(&b.ID, &b.Name, &b.NameEN, &b.Description, &b.GradeWeights, &b.LuckGradeWeights, &b.Elements, &b.SpeciesPool,
+		&b.RateUpSpecies, &b.RateUpShare, &b.StartsAt, &b.EndsAt, &b.PityRare, &b.PityEpic, &b.PityLegendary, &b.IsActive, &b.SortOrder)
+	return b, err
+}
+
+func (r *GameDataRepository) GetAllGachaBanners(ctx context.Context) ([]GameGachaBanner, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+gachaBannerColumns+` FROM game_gacha_banners ORDER BY sort_order, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (GameGachaBanner, error) {
+		return scanGachaBanner(row)
+	})
+}
+
+func (r *GameDataRepository) GetGachaBanner(ctx context.Context, id string) (*GameGachaBanner, error) {
+	b, err := scanGachaBanner(r.pool.QueryRow(ctx, `SELECT `+gachaBannerColumns+` FROM game_gacha_banners WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *GameDataRepository) CreateGachaBanner(ctx context.Context, b *GameGachaBanner) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO game_gacha_banners (id, name, name_en, description, grade_weights, luck_grade_weights, elements, species_pool,
+		 rate_up_species, rate_up_share, starts_at, ends_at, pity_rare, pity_epic, pity_legendary, is_active, sort_order)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)`,
+		b.ID, b.Name, b.NameEN, b.Description, b.GradeWeights, b.LuckGradeWeights, b.Elements, intSliceToPostgresArray(b.SpeciesPool),
+		intSliceToPostgresArray(b.RateUpSpecies), b.RateUpShare, b.StartsAt, b.EndsAt, b.PityRare, b.PityEpic, b.PityLegendary, b.IsActive, b.SortOrder)
+	return err
+}
+
+func (r *GameDataRepository) UpdateGachaBanner(ctx context.Context, b *GameGachaBanner) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE game_gacha_banners SET name=$2, name_en=$3, description=$4, grade_weights=$5, luck_grade_weights=$6, elements=$7, species_pool=$8,
+		 rate_up_species=$9, rate_up_share=$10, starts_at=$11, ends_at=$12, pity_rare=$13, pity_epic=$14, pity_legendary=$15, is_active=$16, sort_order=$17,
+		 updated_at=NOW()
+		 WHERE id=$1`,
+		b.ID, b.Name, b.NameEN, b.Description, b.GradeWeights, b.LuckGradeWeights, b.Elements, intSliceToPostgresArray(b.SpeciesPool),
+		intSliceToPostgresArray(b.RateUpSpecies), b.RateUpShare, b.StartsAt, b.EndsAt, b.PityRare, b.PityEpic, b.PityLegendary, b.IsActive, b.SortOrder)
+	return err
+}
+
+func (r *GameDataRepository) DeleteGachaBanner(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM game_gacha_banners WHERE id=$1`, id)
+	return err
+}
+
+// ===== Crafting Recipes =====
+
+func (r *GameDataRepository) GetAllCraftingRecipes(ctx context.Context) ([]GameCraftingRecipe, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, name, result_type, result_id, result_qty FROM crafting_recipes ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	recipes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (GameCraftingRecipe, error) {
+		var c GameCraftingRecipe
+		err := row.Scan(&c.ID, &c.Name, &c.ResultType, &c.ResultID, &c.ResultQty)
+		return c, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*GameCraftingRecipe, len(recipes))
+	for i := range recipes {
+		byID[recipes[i].ID] = &recipes[i]
+	}
+	rows, err = r.pool.Query(ctx, `SELECT recipe_id, material_id, quantity FROM crafting_ingredients ORDER BY recipe_id, material_id`)
+	if err != nil {
+		return nil, err
+	}
+	var recipeID int
+	var ing GameCraftingIngredient
+	_, err = pgx.ForEachRow(rows, []any{&recipeID, &ing.MaterialID, &ing.Quantity}, func() error {
+		if c := byID[recipeID]; c != nil {
+			c.Ingredients = append(c.Ingredients, ing)
+		}
+		return nil
+	})
+	return recipes, err
+}
+
+func (r *GameDataRepository) GetCraftingRecipe(ctx context.Context, id int) (*GameCraftingRecipe, error) {
+	var c GameCraftingRecipe
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, name, result_type, result_id, result_qty FROM crafting_recipes WHERE id = $1`, id,
+	).Scan(&c.ID, &c.Name, &c.ResultType, &c.ResultID, &c.ResultQty)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT material_id, quantity FROM crafting_ingredients WHERE recipe_id = $1 ORDER BY material_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	c.Ingredients, err = pgx.CollectRows(rows, pgx.RowToStructByPos[GameCraftingIngredient])
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ===== Awakening Costs =====
+
+func (r *GameDataRepository) GetAwakeningCost(ctx context.Context, grade string, starLevel int) (*GameAwakeningCost, error) {
+	var a GameAwakeningCost
+	err := r.pool.QueryRow(ctx,
+		`SELECT gold_cost, stardust_cost, material_id, material_qty
+		 FROM game_awakening_costs WHERE grade = $1 AND star_level = $2`,
+		grade, starLevel,
+	).Scan(&a.GoldCost, &a.StardustCost, &a.MaterialID, &a.MaterialQty)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
 // ===== Helpers =====
 
 func intSliceToPostgresArray(ids []int) string {
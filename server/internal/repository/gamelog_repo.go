@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GameLogRepository appends to game_logs, the per-action audit trail the admin
+// dashboards and gacha audit read.
+type GameLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGameLogRepository(pool *pgxpool.Pool) *GameLogRepository {
+	return &GameLogRepository{pool: pool}
+}
+
+// Log records one game action and the currency it moved; detail is a JSON object.
+func (r *GameLogRepository) Log(ctx context.Context, userID, action, category string, goldDelta int64, gemsDelta, stardustDelta int, detail []byte) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO game_logs (user_id, action, category, detail, gold_delta, gems_delta, stardust_delta)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		userID, action, category, detail, goldDelta, gemsDelta, stardustDelta,
+	)
+	return err
+}
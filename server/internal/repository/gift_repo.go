@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Gift is a logged gift as one of its two parties sees it. Direction is "sent"
+// or "received".
+type Gift struct {
+	ID               string
+	SenderNickname   string
+	ReceiverNickname string
+	GiftType         string
+	Amount           int
+	Message          string
+	CreatedAt        time.Time
+	Direction        string
+}
+
+type GiftRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGiftRepository(pool *pgxpool.Pool) *GiftRepository {
+	return &GiftRepository{pool: pool}
+}
+
+// SentSince sums the gifts of giftType senderID has sent since a time.
+func (r *GiftRepository) SentSince(ctx context.Context, senderID, giftType string, since time.Time) (int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM gift_logs
+		 WHERE sender_id = $1 AND gift_type = $2 AND created_at >= $3`,
+		senderID, giftType, since,
+	).Scan(&total)
+	return total, err
+}
+
+// Log records a gift. The currency itself moves through the ledger.
+func (r *GiftRepository) Log(ctx context.Context, senderID, receiverID, giftType string, amount int, message string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO gift_logs (sender_id, receiver_id, gift_type, amount, message)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		senderID, receiverID, giftType, amount, message,
+	)
+	return err
+}
+
+// History lists the latest gifts userID sent or received, newest first.
+func (r *GiftRepository) History(ctx context.Context, userID string, limit int) ([]Gift, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT gl.id::text, COALESCE(su.nickname, $2), COALESCE(ru.nickname, $2), gl.gift_type, gl.amount, COALESCE(gl.message, ''), gl.created_at,
+			CASE WHEN gl.sender_id = $1 THEN 'sent' ELSE 'received' END as direction
+		FROM gift_logs gl
+		LEFT JOIN users su ON su.id = gl.sender_id
+		LEFT JOIN users ru ON ru.id = gl.receiver_id
+		WHERE gl.sender_id = $1 OR gl.receiver_id = $1
+		ORDER BY gl.created_at DESC
+		LIMIT $3
+	`, userID, DeletedUserNickname, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Gift])
+}
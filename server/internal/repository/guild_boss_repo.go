@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrGuildBossGone = errors.New("guild boss is defeated or expired")
+
+// GuildBoss is a guild's raid boss. It is Defeated once its HP hits 0 and Settled
+// once the rewards for that are paid.
+type GuildBoss struct {
+	ID        int
+	GuildID   string
+	Stage     int
+	Name      string
+	Element   string
+	MaxHP     int64
+	CurrentHP int64
+	ExpiresAt time.Time
+	Defeated  bool
+	Settled   bool
+}
+
+// GuildBossDamage is the total damage one member dealt to a guild boss.
+type GuildBossDamage struct {
+	UserID   string `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Damage   int64  `json:"damage"`
+}
+
+// GuildBossReward is one member's payout for a defeated guild boss.
+type GuildBossReward struct {
+	UserID string
+	Rank   int
+	Damage int64
+	Gold   int64
+	Gems   int
+}
+
+// GuildBossSettlement is what a defeated guild boss pays out: member rewards, XP
+// for the guild, and the boss that replaces it.
+type GuildBossSettlement struct {
+	Rewards []GuildBossReward
+	GuildXP int64
+	Next    *GuildBoss
+}
+
+type GuildBossRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGuildBossRepository(pool *pgxpool.Pool) *GuildBossRepository {
+	return &GuildBossRepository{pool: pool}
+}
+
+// Current returns the guild's latest boss. When there is none, or the last one
+// was settled or ran out of time, it stores the boss spawn returns for the next
+// stage instead. A defeated boss still awaiting settlement is returned as is.
+// The guild row lock keeps concurrent callers from spawning twice.
+func (r *GuildBossRepository) Current(ctx context.Context, guildID string, spawn func(stage int) GuildBoss) (*GuildBoss, error) {
+	var b GuildBoss
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM guilds WHERE id = $1 FOR UPDATE`, guildID); err != nil {
+			return err
+		}
+		err := tx.QueryRow(ctx,
+			`SELECT id, guild_id::text, stage, name, element, max_hp, current_hp, expires_at,
+			        defeated_at IS NOT NULL, settled_at IS NOT NULL
+			 FROM guild_boss WHERE guild_id = $1 ORDER BY id DESC LIMIT 1`,
+			guildID,
+		).Scan(&b.ID, &b.GuildID, &b.Stage, &b.Name, &b.Element, &b.MaxHP, &b.CurrentHP, &b.ExpiresAt, &b.Defeated, &b.Settled)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			b.Stage = 1
+		case err != nil:
+			return err
+		case b.Defeated && b.Settled:
+			// Settlement spawns the next stage; only reached for bosses from before that
+			b.Stage++
+		case b.Defeated, b.ExpiresAt.After(time.Now()):
+			return nil
+		}
+		b = spawn(b.Stage)
+		return insertGuildBossTx(ctx, tx, guildID, &b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func insertGuildBossTx(ctx context.Context, tx pgx.Tx, guildID string, b *GuildBoss) error {
+	b.GuildID = guildID
+	b.CurrentHP = b.MaxHP
+	return tx.QueryRow(ctx,
+		`INSERT INTO guild_boss (guild_id, stage, name, element, max_hp, current_hp, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $5, $6) RETURNING id`,
+		guildID, b.Stage, b.Name, b.Element, b.MaxHP, b.ExpiresAt,
+	).Scan(&b.ID)
+}
+
+// guildBossDamageSQL ranks everyone who hit boss $1, highest damage first.
+const guildBossDamageSQL = `SELECT a.user_id::text, u.nickname, SUM(a.damage)::bigint AS total_dmg
+	FROM guild_boss_attacks a JOIN users u ON u.id = a.user_id
+	WHERE a.boss_id = $1
+	GROUP BY a.user_id, u.nickname
+	ORDER BY total_dmg DESC, MIN(a.created_at)`
+
+// DamageTable ranks everyone who hit the boss, highest damage first.
+func (r *GuildBossRepository) DamageTable(ctx context.Context, bossID int) ([]GuildBossDamage, error) {
+	rows, err := r.pool.Query(ctx, guildBossDamageSQL, bossID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[GuildBossDamage])
+}
+
+// Attack deals damage to a live boss and records the attack, returning the HP
+// left. Returns ErrGuildBossGone if the boss is already down or expired.
+func (r *GuildBossRepository) Attack(ctx context.Context, bossID int, userID string, slimeIDs []string, damage int) (int64, error) {
+	var newHP int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// The killing blow stamps defeated_at with the damage, so a boss at 0 HP is
+		// always left for settlement even if the request dies right after
+		err := tx.QueryRow(ctx,
+			`UPDATE guild_boss
+			 SET current_hp = GREATEST(0, current_hp - $1),
+			     defeated_at = CASE WHEN current_hp <= $1 THEN NOW() END
+			 WHERE id = $2 AND current_hp > 0 AND expires_at > NOW() RETURNING current_hp`,
+			damage, bossID,
+		).Scan(&newHP)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrGuildBossGone
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO guild_boss_attacks (boss_id, user_id, damage, slime_ids) VALUES ($1, $2, $3, $4)`,
+			bossID, userID, damage, strings.Join(slimeIDs, ","),
+		)
+		return err
+	})
+	return newHP, err
+}
+
+// Unsettled lists defeated bosses whose rewards haven't been paid yet.
+func (r *GuildBossRepository) Unsettled(ctx context.Context) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id FROM guild_boss WHERE defeated_at IS NOT NULL AND settled_at IS NULL ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// Settle pays out a defeated boss in one transaction with its row locked. plan
+// sees the boss and its damage table and says what to pay, or returns nil to leave
+// it alone. Settle returns nil, without calling plan, for a boss that is still up
+// or already settled. A member
+// already rewarded for the boss is not paid again.
+func (r *GuildBossRepository) Settle(ctx context.Context, bossID int, plan func(b *GuildBoss, table []GuildBossDamage) *GuildBossSettlement) (*GuildBossSettlement, error) {
+	var s *GuildBossSettlement
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		b := &GuildBoss{ID: bossID}
+		err := tx.QueryRow(ctx,
+			`SELECT guild_id::text, stage, name, element, max_hp, current_hp, expires_at,
+			        defeated_at IS NOT NULL, settled_at IS NOT NULL
+			 FROM guild_boss WHERE id = $1 FOR UPDATE`, bossID,
+		).Scan(&b.GuildID, &b.Stage, &b.Name, &b.Element, &b.MaxHP, &b.CurrentHP, &b.ExpiresAt, &b.Defeated, &b.Settled)
+		if err != nil {
+			return err
+		}
+		if !b.Defeated || b.Settled {
+			return nil
+		}
+
+		rows, err := tx.Query(ctx, guildBossDamageSQL, bossID)
+		if err != nil {
+			return err
+		}
+		table, err := pgx.CollectRows(rows, pgx.RowToStructByPos[GuildBossDamage])
+		if err != nil {
+			return fmt.Errorf("damage table: %w", err)
+		}
+		if s = plan(b, table); s == nil {
+			return nil
+		}
+
+		ref := strconv.Itoa(bossID)
+		for _, rw := range s.Rewards {
+			tag, err := tx.Exec(ctx,
+				`INSERT INTO guild_boss_rewards (boss_id, user_id, rank, damage, reward_gold, reward_gems)
+				 VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`,
+				bossID, rw.UserID, rw.Rank, rw.Damage, rw.Gold, rw.Gems,
+			)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				continue
+			}
+			if err := ApplyCurrencyTx(ctx, tx, rw.UserID, rw.Gold, rw.Gems, 0, ReasonGuildBoss, ref, false); err != nil {
+				return fmt.Errorf("reward %s: %w", rw.UserID, err)
+			}
+		}
+
+		if s.GuildXP > 0 {
+			if _, _, err := addGuildXPTx(ctx, tx, b.GuildID, s.GuildXP); err != nil {
+				return fmt.Errorf("guild xp: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `UPDATE guild_boss SET settled_at = NOW() WHERE id = $1`, bossID); err != nil {
+			return err
+		}
+
+		if s.Next != nil {
+			if err := insertGuildBossTx(ctx, tx, b.GuildID, s.Next); err != nil {
+				return fmt.Errorf("spawn stage %d: %w", s.Next.Stage, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
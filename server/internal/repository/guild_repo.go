@@ -0,0 +1,688 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/slimetopia/server/internal/models"
+)
+
+const (
+	// GuildMaxLevel is the highest guild level; guildLevelXP has one entry per level.
+	GuildMaxLevel = 10
+
+	guildBaseMembers     = 20
+	guildMembersPerLevel = 2
+)
+
+// guildLevelXP[i] is the total XP a guild needs to reach level i+1.
+var guildLevelXP = []int64{0, 500, 1500, 3500, 7000, 12000, 20000, 32000, 50000, 75000}
+
+var (
+	ErrGuildNotFound        = errors.New("guild not found")
+	ErrGuildNameTaken       = errors.New("guild name taken")
+	ErrAlreadyInGuild       = errors.New("already in a guild")
+	ErrNotInGuild           = errors.New("not in a guild")
+	ErrGuildFull            = errors.New("guild is full")
+	ErrGuildForbidden       = errors.New("guild role does not allow this")
+	ErrGuildInviteOnly      = errors.New("guild is invite only")
+	ErrGuildRequestExists   = errors.New("guild request already pending")
+	ErrGuildRequestNotFound = errors.New("guild request not found")
+)
+
+// GuildMaxMembers is the member cap at a guild level.
+func GuildMaxMembers(level int) int {
+	return guildBaseMembers + (level-1)*guildMembersPerLevel
+}
+
+// GuildNextLevelXP is the XP total needed for the level after level, or 0 at max level.
+func GuildNextLevelXP(level int) int64 {
+	if level < 1 || level >= GuildMaxLevel {
+		return 0
+	}
+	return guildLevelXP[level]
+}
+
+func guildLevelFor(xp int64) int {
+	level := 1
+	for i, need := range guildLevelXP {
+		if xp >= need {
+			level = i + 1
+		}
+	}
+	return level
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique_violation (23505).
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+type GuildRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGuildRepository(pool *pgxpool.Pool) *GuildRepository {
+	return &GuildRepository{pool: pool}
+}
+
+// Membership returns the user's guild membership, or ErrNotInGuild.
+func (r *GuildRepository) Membership(ctx context.Context, userID string) (*models.GuildMember, error) {
+	m := &models.GuildMember{UserID: userID}
+	err := r.pool.QueryRow(ctx,
+		`SELECT guild_id::text, role, contributed_xp, joined_at FROM guild_members WHERE user_id = $1`,
+		userID,
+	).Scan(&m.GuildID, &m.Role, &m.ContributedXP, &m.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotInGuild
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Create founds a guild with userID as leader, charging goldCost.
+func (r *GuildRepository) Create(ctx context.Context, userID, name, description, joinPolicy string, goldCost int64) (string, error) {
+	var guildID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var inGuild bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM guild_members WHERE user_id = $1)`, userID).Scan(&inGuild)
+		if err != nil {
+			return err
+		}
+		if inGuild {
+			return ErrAlreadyInGuild
+		}
+		if err := ApplyCurrencyTx(ctx, tx, userID, -goldCost, 0, 0, ReasonGuildCreate, name, true); err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(ctx,
+			`INSERT INTO guilds (name, description, join_policy) VALUES ($1, $2, $3) RETURNING id::text`,
+			name, description, joinPolicy,
+		).Scan(&guildID)
+		if IsUniqueViolation(err) {
+			return ErrGuildNameTaken
+		}
+		if err != nil {
+			return err
+		}
+		return joinTx(ctx, tx, guildID, userID, models.GuildRoleLeader)
+	})
+	return guildID, err
+}
+
+const guildSelect = `SELECT g.id::text, g.name, g.description, g.join_policy, g.level, g.xp, g.created_at,
+	        (SELECT COUNT(*) FROM guild_members m WHERE m.guild_id = g.id)::int
+	 FROM guilds g`
+
+func scanGuild(row pgx.Row) (*models.Guild, error) {
+	g := &models.Guild{}
+	err := row.Scan(&g.ID, &g.Name, &g.Description, &g.JoinPolicy, &g.Level, &g.XP, &g.CreatedAt, &g.MemberCount)
+	if err != nil {
+		return nil, err
+	}
+	g.MaxMembers = GuildMaxMembers(g.Level)
+	return g, nil
+}
+
+func (r *GuildRepository) Get(ctx context.Context, guildID string) (*models.Guild, error) {
+	g, err := scanGuild(r.pool.QueryRow(ctx, guildSelect+` WHERE g.id = $1`, guildID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrGuildNotFound
+	}
+	return g, err
+}
+
+// Search lists guilds whose name contains query (all guilds when empty), highest level first.
+func (r *GuildRepository) Search(ctx context.Context, query string, limit int) ([]models.Guild, error) {
+	rows, err := r.pool.Query(ctx,
+		guildSelect+` WHERE $1 = '' OR g.name ILIKE '%' || $1 || '%'
+		 ORDER BY g.level DESC, g.xp DESC, g.created_at
+		 LIMIT $2`,
+		query, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	guilds := make([]models.Guild, 0)
+	for rows.Next() {
+		g, err := scanGuild(rows)
+		if err != nil {
+			return nil, err
+		}
+		guilds = append(guilds, *g)
+	}
+	return guilds, rows.Err()
+}
+
+// Members lists a guild's members, leader and officers first.
+func (r *GuildRepository) Members(ctx context.Context, guildID string) ([]models.GuildMember, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT gm.user_id::text, gm.guild_id::text, u.nickname, u.level, gm.role, gm.contributed_xp, gm.joined_at
+		 FROM guild_members gm JOIN users u ON u.id = gm.user_id
+		 WHERE gm.guild_id = $1
+		 ORDER BY CASE gm.role WHEN 'leader' THEN 0 WHEN 'officer' THEN 1 ELSE 2 END, gm.contributed_xp DESC, gm.joined_at`,
+		guildID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]models.GuildMember, 0)
+	for rows.Next() {
+		var m models.GuildMember
+		if err := rows.Scan(&m.UserID, &m.GuildID, &m.Nickname, &m.Level, &m.Role, &m.ContributedXP, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// Update changes the guild's description and join policy. Leader only.
+func (r *GuildRepository) Update(ctx context.Context, userID, description, joinPolicy string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		guildID, role, err := guildActorTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if role != models.GuildRoleLeader {
+			return ErrGuildForbidden
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE guilds SET description = $2, join_policy = $3, updated_at = NOW() WHERE id = $1`,
+			guildID, description, joinPolicy,
+		)
+		return err
+	})
+}
+
+// Disband deletes the user's guild. Leader only.
+func (r *GuildRepository) Disband(ctx context.Context, userID string) (string, error) {
+	var guildID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var role string
+		var err error
+		guildID, role, err = guildActorTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if role != models.GuildRoleLeader {
+			return ErrGuildForbidden
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM guilds WHERE id = $1`, guildID)
+		return err
+	})
+	return guildID, err
+}
+
+// Join joins an open guild, accepts a pending invite from it, or applies to a guild that
+// reviews applications. joined reports whether the user is now a member.
+func (r *GuildRepository) Join(ctx context.Context, userID, guildID string) (joined bool, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var policy string
+		err := tx.QueryRow(ctx, `SELECT join_policy FROM guilds WHERE id = $1 FOR UPDATE`, guildID).Scan(&policy)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrGuildNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var inGuild, invited bool
+		err = tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM guild_members WHERE user_id = $1),
+			        EXISTS (SELECT 1 FROM guild_requests
+			                WHERE guild_id = $2 AND user_id = $1 AND kind = 'invite' AND status = 'pending')`,
+			userID, guildID,
+		).Scan(&inGuild, &invited)
+		if err != nil {
+			return err
+		}
+		if inGuild {
+			return ErrAlreadyInGuild
+		}
+
+		if invited || policy == models.GuildJoinOpen {
+			joined = true
+			return joinTx(ctx, tx, guildID, userID, models.GuildRoleMember)
+		}
+		if policy == models.GuildJoinInvite {
+			return ErrGuildInviteOnly
+		}
+		return insertGuildRequestTx(ctx, tx, guildID, userID, "apply", nil)
+	})
+	return joined, err
+}
+
+// Leave removes the user from their guild. A leaving leader hands over to the longest
+// serving officer (or member); the last member leaving disbands the guild.
+func (r *GuildRepository) Leave(ctx context.Context, userID string) (disbanded bool, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		guildID, role, err := guildActorTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		disbanded, err = leaveTx(ctx, tx, guildID, userID, role)
+		return err
+	})
+	return disbanded, err
+}
+
+// Invite asks targetID to join the actor's guild. Officers and the leader can invite.
+// If the target already applied, they join straight away and joined is true.
+func (r *GuildRepository) Invite(ctx context.Context, actorID, targetID string) (joined bool, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		guildID, role, err := guildActorTx(ctx, tx, actorID)
+		if err != nil {
+			return err
+		}
+		if role == models.GuildRoleMember {
+			return ErrGuildForbidden
+		}
+		var inGuild, applied bool
+		err = tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM guild_members WHERE user_id = $1),
+			        EXISTS (SELECT 1 FROM guild_requests
+			                WHERE guild_id = $2 AND user_id = $1 AND kind = 'apply' AND status = 'pending')`,
+			targetID, guildID,
+		).Scan(&inGuild, &applied)
+		if err != nil {
+			return err
+		}
+		if inGuild {
+			return ErrAlreadyInGuild
+		}
+		if applied {
+			joined = true
+			return joinTx(ctx, tx, guildID, targetID, models.GuildRoleMember)
+		}
+		return insertGuildRequestTx(ctx, tx, guildID, targetID, "invite", &actorID)
+	})
+	return joined, err
+}
+
+// PendingRequests lists a guild's pending invites and applications, newest first.
+func (r *GuildRepository) PendingRequests(ctx context.Context, guildID string) ([]models.GuildRequest, error) {
+	return r.listRequests(ctx, `gr.guild_id = $1`, guildID)
+}
+
+// InvitesFor lists the pending invites addressed to userID.
+func (r *GuildRepository) InvitesFor(ctx context.Context, userID string) ([]models.GuildRequest, error) {
+	return r.listRequests(ctx, `gr.user_id = $1 AND gr.kind = 'invite'`, userID)
+}
+
+func (r *GuildRepository) listRequests(ctx context.Context, where string, arg string) ([]models.GuildRequest, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT gr.id::text, g.id::text, g.name, u.id::text, u.nickname, gr.kind, gr.created_at
+		 FROM guild_requests gr
+		 JOIN guilds g ON g.id = gr.guild_id
+		 JOIN users u ON u.id = gr.user_id
+		 WHERE `+where+` AND gr.status = 'pending'
+		 ORDER BY gr.created_at DESC`,
+		arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]models.GuildRequest, 0)
+	for rows.Next() {
+		var gr models.GuildRequest
+		if err := rows.Scan(&gr.ID, &gr.GuildID, &gr.GuildName, &gr.UserID, &gr.Nickname, &gr.Kind, &gr.CreatedAt); err != nil {
+			return nil, err
+		}
+		requests = append(requests, gr)
+	}
+	return requests, rows.Err()
+}
+
+// RespondApplication accepts or declines an application to the actor's guild and returns
+// the applicant's ID. Officers and the leader can respond.
+func (r *GuildRepository) RespondApplication(ctx context.Context, actorID, requestID string, accept bool) (string, error) {
+	var applicantID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		guildID, role, err := guildActorTx(ctx, tx, actorID)
+		if err != nil {
+			return err
+		}
+		if role == models.GuildRoleMember {
+			return ErrGuildForbidden
+		}
+		err = tx.QueryRow(ctx,
+			`SELECT user_id::text FROM guild_requests
+			 WHERE id = $1 AND guild_id = $2 AND kind = 'apply' AND status = 'pending' FOR UPDATE`,
+			requestID, guildID,
+		).Scan(&applicantID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrGuildRequestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if accept {
+			return joinTx(ctx, tx, guildID, applicantID, models.GuildRoleMember)
+		}
+		return declineGuildRequestTx(ctx, tx, requestID)
+	})
+	return applicantID, err
+}
+
+// RespondInvite accepts or declines an invite addressed to userID and returns the guild ID.
+func (r *GuildRepository) RespondInvite(ctx context.Context, userID, requestID string, accept bool) (string, error) {
+	var guildID string
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`SELECT guild_id::text FROM guild_requests
+			 WHERE id = $1 AND user_id = $2 AND kind = 'invite' AND status = 'pending'`,
+			requestID, userID,
+		).Scan(&guildID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrGuildRequestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !accept {
+			return declineGuildRequestTx(ctx, tx, requestID)
+		}
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM guilds WHERE id = $1 FOR UPDATE`, guildID); err != nil {
+			return err
+		}
+		return joinTx(ctx, tx, guildID, userID, models.GuildRoleMember)
+	})
+	return guildID, err
+}
+
+// WithdrawApplication deletes a pending application the user sent.
+func (r *GuildRepository) WithdrawApplication(ctx context.Context, userID, requestID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM guild_requests WHERE id = $1 AND user_id = $2 AND kind = 'apply' AND status = 'pending'`,
+		requestID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGuildRequestNotFound
+	}
+	return nil
+}
+
+// SetRole changes a member's role. Leader only; giving someone the leader role hands
+// leadership over and makes the old leader an officer.
+func (r *GuildRepository) SetRole(ctx context.Context, actorID, targetID, newRole string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		guildID, role, err := guildActorTx(ctx, tx, actorID)
+		if err != nil {
+			return err
+		}
+		if role != models.GuildRoleLeader || actorID == targetID {
+			return ErrGuildForbidden
+		}
+		if _, err := guildMemberRoleTx(ctx, tx, guildID, targetID); err != nil {
+			return err
+		}
+		if newRole == models.GuildRoleLeader {
+			// Demote first: only one leader row may exist at a time
+			_, err = tx.Exec(ctx, `UPDATE guild_members SET role = 'officer' WHERE user_id = $1`, actorID)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(ctx, `UPDATE guild_members SET role = $2 WHERE user_id = $1`, targetID, newRole)
+		return err
+	})
+}
+
+// Kick removes targetID from the actor's guild. The leader can kick anyone; officers can
+// kick members.
+func (r *GuildRepository) Kick(ctx context.Context, actorID, targetID string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		guildID, role, err := guildActorTx(ctx, tx, actorID)
+		if err != nil {
+			return err
+		}
+		targetRole, err := guildMemberRoleTx(ctx, tx, guildID, targetID)
+		if err != nil {
+			return err
+		}
+		if actorID == targetID || guildRoleRank(role) <= guildRoleRank(targetRole) {
+			return ErrGuildForbidden
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM guild_members WHERE user_id = $1`, targetID)
+		return err
+	})
+}
+
+// AddXP credits guild XP earned by userID to their guild and to their contribution.
+// Returns ErrNotInGuild for players without a guild.
+func (r *GuildRepository) AddXP(ctx context.Context, userID string, xp int64) (level int, leveledUp bool, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var guildID string
+		err := tx.QueryRow(ctx,
+			`UPDATE guild_members SET contributed_xp = contributed_xp + $2 WHERE user_id = $1 RETURNING guild_id::text`,
+			userID, xp,
+		).Scan(&guildID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotInGuild
+		}
+		if err != nil {
+			return err
+		}
+		level, leveledUp, err = addGuildXPTx(ctx, tx, guildID, xp)
+		return err
+	})
+	return level, leveledUp, err
+}
+
+// AddGuildXP credits XP earned by the guild as a whole (e.g. a boss kill).
+func (r *GuildRepository) AddGuildXP(ctx context.Context, guildID string, xp int64) (level int, leveledUp bool, err error) {
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		level, leveledUp, err = addGuildXPTx(ctx, tx, guildID, xp)
+		return err
+	})
+	return level, leveledUp, err
+}
+
+func addGuildXPTx(ctx context.Context, tx pgx.Tx, guildID string, xp int64) (int, bool, error) {
+	var total int64
+	var level int
+	err := tx.QueryRow(ctx,
+		`UPDATE guilds SET xp = xp + $2, updated_at = NOW() WHERE id = $1 RETURNING xp, level`,
+		guildID, xp,
+	).Scan(&total, &level)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, ErrGuildNotFound
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	newLevel := guildLevelFor(total)
+	if newLevel <= level {
+		return level, false, nil
+	}
+	_, err = tx.Exec(ctx, `UPDATE guilds SET level = $2 WHERE id = $1`, guildID, newLevel)
+	return newLevel, true, err
+}
+
+// PostMessage appends a chat message to the guild.
+func (r *GuildRepository) PostMessage(ctx context.Context, guildID, userID, message string) (*models.GuildMessage, error) {
+	m := &models.GuildMessage{UserID: userID, Message: message}
+	err := r.pool.QueryRow(ctx,
+		`WITH ins AS (
+			INSERT INTO guild_messages (guild_id, user_id, message) VALUES ($1, $2, $3)
+			RETURNING id, created_at
+		)
+		SELECT ins.id, ins.created_at, u.nickname FROM ins, users u WHERE u.id = $2`,
+		guildID, userID, message,
+	).Scan(&m.ID, &m.CreatedAt, &m.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Messages returns chat messages after afterID in order, or the latest limit messages
+// when afterID is 0.
+func (r *GuildRepository) Messages(ctx context.Context, guildID string, afterID int64, limit int) ([]models.GuildMessage, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT * FROM (
+			SELECT m.id, m.user_id::text, u.nickname, m.message, m.created_at
+			FROM guild_messages m JOIN users u ON u.id = m.user_id
+			WHERE m.guild_id = $1 AND m.id > $2
+			ORDER BY CASE WHEN $2 = 0 THEN -m.id ELSE m.id END
+			LIMIT $3
+		) t ORDER BY 1`,
+		guildID, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]models.GuildMessage, 0)
+	for rows.Next() {
+		var m models.GuildMessage
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Nickname, &m.Message, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// guildActorTx locks the user's guild and membership rows and returns the guild and the
+// user's role. Every membership change takes the guild row lock first, so member counts
+// and roles read afterwards in the transaction are stable.
+func guildActorTx(ctx context.Context, tx pgx.Tx, userID string) (guildID, role string, err error) {
+	err = tx.QueryRow(ctx,
+		`SELECT gm.guild_id::text, gm.role
+		 FROM guild_members gm JOIN guilds g ON g.id = gm.guild_id
+		 WHERE gm.user_id = $1
+		 FOR UPDATE OF g, gm`,
+		userID,
+	).Scan(&guildID, &role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrNotInGuild
+	}
+	return guildID, role, err
+}
+
+func guildMemberRoleTx(ctx context.Context, tx pgx.Tx, guildID, userID string) (string, error) {
+	var role string
+	err := tx.QueryRow(ctx,
+		`SELECT role FROM guild_members WHERE user_id = $1 AND guild_id = $2 FOR UPDATE`,
+		userID, guildID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotInGuild
+	}
+	return role, err
+}
+
+func guildRoleRank(role string) int {
+	switch role {
+	case models.GuildRoleLeader:
+		return 2
+	case models.GuildRoleOfficer:
+		return 1
+	}
+	return 0
+}
+
+// joinTx adds userID to a guild whose row is already locked, enforcing the member cap,
+// and settles the player's other pending invites and applications.
+func joinTx(ctx context.Context, tx pgx.Tx, guildID, userID, role string) error {
+	var level, members int
+	err := tx.QueryRow(ctx,
+		`SELECT level, (SELECT COUNT(*) FROM guild_members WHERE guild_id = $1)::int FROM guilds WHERE id = $1`,
+		guildID,
+	).Scan(&level, &members)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrGuildNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if members >= GuildMaxMembers(level) {
+		return ErrGuildFull
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO guild_members (user_id, guild_id, role) VALUES ($1, $2, $3)`,
+		userID, guildID, role,
+	)
+	if IsUniqueViolation(err) {
+		return ErrAlreadyInGuild
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE guild_requests
+		 SET status = CASE WHEN guild_id = $2 THEN 'accepted' ELSE 'declined' END, responded_at = NOW()
+		 WHERE user_id = $1 AND status = 'pending'`,
+		userID, guildID,
+	)
+	return err
+}
+
+// leaveTx removes a member from a locked guild, handing over leadership or disbanding
+// the guild as needed.
+func leaveTx(ctx context.Context, tx pgx.Tx, guildID, userID, role string) (disbanded bool, err error) {
+	if _, err := tx.Exec(ctx, `DELETE FROM guild_members WHERE user_id = $1`, userID); err != nil {
+		return false, err
+	}
+	if role != models.GuildRoleLeader {
+		return false, nil
+	}
+
+	var successor string
+	err = tx.QueryRow(ctx,
+		`UPDATE guild_members SET role = 'leader'
+		 WHERE user_id = (
+			SELECT user_id FROM guild_members WHERE guild_id = $1
+			ORDER BY CASE role WHEN 'officer' THEN 0 ELSE 1 END, joined_at
+			LIMIT 1)
+		 RETURNING user_id::text`,
+		guildID,
+	).Scan(&successor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = tx.Exec(ctx, `DELETE FROM guilds WHERE id = $1`, guildID)
+		return true, err
+	}
+	return false, err
+}
+
+func insertGuildRequestTx(ctx context.Context, tx pgx.Tx, guildID, userID, kind string, inviterID *string) error {
+	tag, err := tx.Exec(ctx,
+		`INSERT INTO guild_requests (guild_id, user_id, kind, inviter_id) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (guild_id, user_id) WHERE status = 'pending' DO NOTHING`,
+		guildID, userID, kind, inviterID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGuildRequestExists
+	}
+	return nil
+}
+
+func declineGuildRequestTx(ctx context.Context, tx pgx.Tx, requestID string) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE guild_requests SET status = 'declined', responded_at = NOW() WHERE id = $1`,
+		requestID,
+	)
+	return err
+}
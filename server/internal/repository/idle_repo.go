@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdleRepository(pool *pgxpool.Pool) *IdleRepository {
+	return &IdleRepository{pool: pool}
+}
+
+// Progress returns when userID last collected idle rewards and their base gold
+// rate, starting their idle progress if they have none.
+func (r *IdleRepository) Progress(ctx context.Context, userID string) (time.Time, int, error) {
+	_, err := r.pool.Exec(ctx, `INSERT INTO idle_progress (user_id) VALUES ($1) ON CONFLICT DO NOTHING`, userID)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	var lastCollected time.Time
+	var goldRate int
+	err = r.pool.QueryRow(ctx,
+		`SELECT last_collected_at, gold_rate FROM idle_progress WHERE user_id = $1`,
+		userID,
+	).Scan(&lastCollected, &goldRate)
+	return lastCollected, goldRate, err
+}
+
+// MarkCollected restarts userID's idle timer.
+func (r *IdleRepository) MarkCollected(ctx context.Context, userID string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE idle_progress SET last_collected_at = NOW() WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// JobRunRepository records the scheduler's run history in job_runs.
+type JobRunRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRunRepository(pool *pgxpool.Pool) *JobRunRepository {
+	return &JobRunRepository{pool: pool}
+}
+
+// Started records the start of a run and returns its ID.
+func (r *JobRunRepository) Started(ctx context.Context, job, instance string, at time.Time) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO job_runs (job, instance, started_at) VALUES ($1, $2, $3) RETURNING id`,
+		job, instance, at,
+	).Scan(&id)
+	return id, err
+}
+
+// Finished completes a run; a nil runErr marks it ok.
+func (r *JobRunRepository) Finished(ctx context.Context, id int64, at time.Time, runErr error) error {
+	status, msg := "ok", ""
+	if runErr != nil {
+		status, msg = "failed", runErr.Error()
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE job_runs SET status = $2, error = $3, finished_at = $4 WHERE id = $1`,
+		id, status, msg, at,
+	)
+	return err
+}
+
+// LastStarted returns when the job last started, or the zero time if it never ran.
+func (r *JobRunRepository) LastStarted(ctx context.Context, job string) (time.Time, error) {
+	var at time.Time
+	err := r.pool.QueryRow(ctx,
+		`SELECT started_at FROM job_runs WHERE job = $1 ORDER BY started_at DESC LIMIT 1`, job,
+	).Scan(&at)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return at, err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrUnknownLeaderboard = errors.New("unknown leaderboard")
+
+// LeaderboardEntry is a player's score on a leaderboard.
+type LeaderboardEntry struct {
+	Nickname string
+	Score    int
+}
+
+// collectionScoreSQL scores a user (u) by the grades of their codex entries
+// (ss) plus a bonus per first discovery.
+const collectionScoreSQL = `
+	COALESCE(SUM(CASE ss.grade
+		WHEN 'common' THEN 1
+		WHEN 'uncommon' THEN 3
+		WHEN 'rare' THEN 10
+		WHEN 'epic' THEN 30
+		WHEN 'legendary' THEN 100
+		WHEN 'mythic' THEN 500
+		ELSE 0
+	END), 0)::int
+	+ COALESCE((SELECT COUNT(*) * 50 FROM first_discoveries fd WHERE fd.user_id = u.id), 0)::int`
+
+// leaderboardQueries take the WHERE clause and the LIMIT.
+var leaderboardQueries = map[string]string{
+	"collection": `
+		SELECT u.nickname, ` + collectionScoreSQL + ` as score
+		FROM users u
+		LEFT JOIN codex_entries ce ON ce.user_id = u.id
+		LEFT JOIN slime_species ss ON ss.id = ce.species_id
+		%s
+		GROUP BY u.id, u.nickname
+		ORDER BY score DESC
+		LIMIT %d`,
+	"level": `
+		SELECT u.nickname, COALESCE(MAX(s.level), 0) as score
+		FROM users u
+		LEFT JOIN slimes s ON s.user_id = u.id
+		%s
+		GROUP BY u.id, u.nickname
+		ORDER BY score DESC
+		LIMIT %d`,
+	// Only server-simulated results count; legacy client-reported scores are excluded
+	"race": `
+		SELECT u.nickname, COALESCE(MAX(rr.score), 0) as score
+		FROM users u
+		LEFT JOIN race_results rr ON rr.user_id = u.id AND rr.verified
+		%s
+		GROUP BY u.id, u.nickname
+		ORDER BY score DESC
+		LIMIT %d`,
+	"gold": `
+		SELECT u.nickname, u.gold::int as score
+		FROM users u
+		%s
+		ORDER BY u.gold DESC
+		LIMIT %d`,
+}
+
+type LeaderboardRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLeaderboardRepository(pool *pgxpool.Pool) *LeaderboardRepository {
+	return &LeaderboardRepository{pool: pool}
+}
+
+// Top returns the best limit scores on the leaderboard lbType (collection, level,
+// race or gold), among userIDs only when it is non-nil.
+func (r *LeaderboardRepository) Top(ctx context.Context, lbType string, userIDs []string, limit int) ([]LeaderboardEntry, error) {
+	query, ok := leaderboardQueries[lbType]
+	if !ok {
+		return nil, ErrUnknownLeaderboard
+	}
+
+	where := ""
+	var args []interface{}
+	if userIDs != nil {
+		where = "WHERE u.id = ANY($1::uuid[])"
+		args = append(args, userIDs)
+	}
+
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(query, where, limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[LeaderboardEntry])
+}
+
+// CollectionRank returns userID's place on the global collection leaderboard.
+func (r *LeaderboardRepository) CollectionRank(ctx context.Context, userID string) (int, error) {
+	var rank int
+	err := r.pool.QueryRow(ctx, `
+		WITH ranked AS (
+			SELECT u.id,
+				ROW_NUMBER() OVER (ORDER BY `+collectionScoreSQL+` DESC) as rank
+			FROM users u
+			LEFT JOIN codex_entries ce ON ce.user_id = u.id
+			LEFT JOIN slime_species ss ON ss.id = ce.species_id
+			GROUP BY u.id
+		)
+		SELECT rank FROM ranked WHERE id = $1
+	`, userID).Scan(&rank)
+	return rank, err
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ledger reason codes. Every balance change carries one of these plus a reference ID
+// (mail id, item id, boss stage, ...) so the journal can be traced back to its source.
+const (
+	ReasonOpeningBalance  = "opening_balance"
+	ReasonStarterPack     = "starter_pack"
+	ReasonShopPurchase    = "shop_purchase"
+	ReasonShopRefund      = "shop_refund"
+	ReasonCapacityExpand  = "capacity_expand"
+	ReasonCapacityRefund  = "capacity_refund"
+	ReasonCurrencyPackage = "currency_package"
+	ReasonMissionReward   = "mission_reward"
+	ReasonAttendance      = "attendance_reward"
+	ReasonAchievement     = "achievement_reward"
+	ReasonCollection      = "collection_reward"
+	ReasonExploration     = "exploration_reward"
+	ReasonMailClaim       = "mail_claim"
+	ReasonIdleReward      = "idle_reward"
+	ReasonWheelSpin       = "wheel_spin"
+	ReasonWheelReward     = "wheel_reward"
+	ReasonRaceReward      = "race_reward"
+	ReasonFishingReward   = "fishing_reward"
+	ReasonInteractReward  = "interact_reward"
+	ReasonBossReward      = "boss_reward"
+	ReasonEvolution       = "evolution"
+	ReasonEvolutionRefund = "evolution_refund"
+	ReasonAwakening       = "awakening"
+	ReasonAccessory       = "accessory_purchase"
+	ReasonAccessoryRefund = "accessory_refund"
+	ReasonNicknameChange  = "nickname_change"
+	ReasonGift            = "gift"
+	ReasonShortsTip       = "shorts_tip"
+	ReasonAdminGrant      = "admin_grant"
+	ReasonBotActivity     = "bot_activity"
+	ReasonGuildCreate     = "guild_create"
+	ReasonGuildBoss       = "guild_boss_reward"
+)
+
+// LedgerDiscrepancy is a wallet whose stored balance disagrees with its ledger sum.
+type LedgerDiscrepancy struct {
+	UserID    string `json:"user_id"`
+	Currency  string `json:"currency"`
+	Balance   int64  `json:"balance"`
+	LedgerSum int64  `json:"ledger_sum"`
+}
+
+type LedgerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool) *LedgerRepository {
+	return &LedgerRepository{pool: pool}
+}
+
+// ApplyCurrencyTx changes a user's balances and journals the movement inside tx.
+// Positive amounts credit the wallet from the system account; negative amounts debit it.
+// With requireFunds set the update only applies if no balance would go negative,
+// otherwise ErrInsufficientFunds is returned and nothing is written.
+func ApplyCurrencyTx(ctx context.Context, tx pgx.Tx, userID string, gold int64, gems, stardust int, reason, refID string, requireFunds bool) error {
+	if gold == 0 && gems == 0 && stardust == 0 {
+		return nil
+	}
+
+	var balGold, balGems, balStardust int64
+	err := tx.QueryRow(ctx,
+		`UPDATE users SET gold = gold + $1, gems = gems + $2, stardust = stardust + $3, updated_at = NOW()
+		 WHERE id = $4 AND (NOT $5 OR (gold + $1 >= 0 AND gems + $2 >= 0 AND stardust + $3 >= 0))
+		 RETURNING gold, gems, stardust`,
+		gold, gems, stardust, userID, requireFunds,
+	).Scan(&balGold, &balGems, &balStardust)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if requireFunds {
+				return ErrInsufficientFunds
+			}
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	legs := []struct {
+		currency string
+		amount   int64
+		balance  int64
+	}{
+		{"gold", gold, balGold},
+		{"gems", int64(gems), balGems},
+		{"stardust", int64(stardust), balStardust},
+	}
+	for _, l := range legs {
+		if l.amount == 0 {
+			continue
+		}
+		_, err := tx.Exec(ctx,
+			`WITH t AS (SELECT uuid_generate_v4() AS txn_id)
+			 INSERT INTO currency_ledger (txn_id, account, user_id, currency, amount, balance_after, reason, ref_id)
+			 SELECT txn_id, 'user', $1::UUID, $2::VARCHAR, $3::BIGINT, $4::BIGINT, $5::VARCHAR, $6::VARCHAR FROM t
+			 UNION ALL
+			 SELECT txn_id, 'system', $1::UUID, $2::VARCHAR, -$3::BIGINT, NULL, $5::VARCHAR, $6::VARCHAR FROM t`,
+			userID, l.currency, l.amount, l.balance, reason, refID,
+		)
+		if err != nil {
+			return fmt.Errorf("ledger %s: %w", l.currency, err)
+		}
+	}
+	return nil
+}
+
+// TransferCurrencyTx moves gold/gems from one wallet to another as a single balanced
+// transaction: the sender's debit leg is offset by the receiver's credit leg.
+func TransferCurrencyTx(ctx context.Context, tx pgx.Tx, fromID, toID string, gold int64, gems int, reason, refID string) error {
+	legs := []struct {
+		currency string
+		amount   int64
+	}{
+		{"gold", gold},
+		{"gems", int64(gems)},
+	}
+	for _, l := range legs {
+		if l.amount == 0 {
+			continue
+		}
+		if l.amount < 0 {
+			return fmt.Errorf("transfer amount must be positive")
+		}
+
+		var fromBal int64
+		err := tx.QueryRow(ctx,
+			fmt.Sprintf(`UPDATE users SET %[1]s = %[1]s - $1, updated_at = NOW()
+			 WHERE id = $2 AND %[1]s >= $1 RETURNING %[1]s`, l.currency),
+			l.amount, fromID,
+		).Scan(&fromBal)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInsufficientFunds
+			}
+			return err
+		}
+
+		var toBal int64
+		err = tx.QueryRow(ctx,
+			fmt.Sprintf(`UPDATE users SET %[1]s = %[1]s + $1, updated_at = NOW()
+			 WHERE id = $2 RETURNING %[1]s`, l.currency),
+			l.amount, toID,
+		).Scan(&toBal)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			`WITH t AS (SELECT uuid_generate_v4() AS txn_id)
+			 INSERT INTO currency_ledger (txn_id, account, user_id, currency, amount, balance_after, reason, ref_id)
+			 SELECT txn_id, 'user', $1::UUID, $3::VARCHAR, -$4::BIGINT, $5::BIGINT, $7::VARCHAR, $8::VARCHAR FROM t
+			 UNION ALL
+			 SELECT txn_id, 'user', $2::UUID, $3::VARCHAR, $4::BIGINT, $6::BIGINT, $7::VARCHAR, $8::VARCHAR FROM t`,
+			fromID, toID, l.currency, l.amount, fromBal, toBal, reason, refID,
+		)
+		if err != nil {
+			return fmt.Errorf("ledger %s: %w", l.currency, err)
+		}
+	}
+	return nil
+}
+
+// Reconcile compares every wallet against the sum of its ledger legs. Mismatches are
+// recorded as open discrepancies; previously open ones that now match are resolved.
+func (r *LedgerRepository) Reconcile(ctx context.Context) ([]LedgerDiscrepancy, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH sums AS (
+			SELECT user_id, currency, SUM(amount) AS total
+			FROM currency_ledger WHERE account = 'user'
+			GROUP BY user_id, currency
+		), wallets AS (
+			SELECT u.id, c.currency,
+				CASE c.currency WHEN 'gold' THEN u.gold WHEN 'gems' THEN u.gems::BIGINT ELSE u.stardust::BIGINT END AS balance
+			FROM users u CROSS JOIN (VALUES ('gold'), ('gems'), ('stardust')) AS c(currency)
+		)
+		SELECT w.id, w.currency, w.balance, COALESCE(s.total, 0)::BIGINT
+		FROM wallets w
+		LEFT JOIN sums s ON s.user_id = w.id AND s.currency = w.currency
+		WHERE w.balance <> COALESCE(s.total, 0)`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []LedgerDiscrepancy
+	for rows.Next() {
+		var d LedgerDiscrepancy
+		if err := rows.Scan(&d.UserID, &d.Currency, &d.Balance, &d.LedgerSum); err != nil {
+			return nil, err
+		}
+		found = append(found, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	userIDs := make([]string, len(found))
+	currencies := make([]string, len(found))
+	for i, d := range found {
+		userIDs[i] = d.UserID
+		currencies[i] = d.Currency
+		_, err := tx.Exec(ctx,
+			`INSERT INTO ledger_discrepancies (user_id, currency, balance, ledger_sum)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (user_id, currency) WHERE resolved_at IS NULL
+			 DO UPDATE SET balance = EXCLUDED.balance, ledger_sum = EXCLUDED.ledger_sum, detected_at = NOW()`,
+			d.UserID, d.Currency, d.Balance, d.LedgerSum,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE ledger_discrepancies SET resolved_at = NOW()
+		 WHERE resolved_at IS NULL
+		   AND (user_id::TEXT, currency) NOT IN (SELECT * FROM UNNEST($1::TEXT[], $2::TEXT[]))`,
+		userIDs, currencies,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// UnbalancedTransactions returns ledger transactions whose legs do not sum to zero.
+// A healthy ledger always returns none.
+func (r *LedgerRepository) UnbalancedTransactions(ctx context.Context) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM (
+			SELECT txn_id FROM currency_ledger GROUP BY txn_id, currency HAVING SUM(amount) <> 0
+		) t`,
+	).Scan(&n)
+	return n, err
+}
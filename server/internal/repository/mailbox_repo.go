@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Mail is a mailbox entry as its reader sees it. Global mail and announcements
+// keep each reader's read and claimed state in mailbox_claims.
+type Mail struct {
+	ID         string
+	Title      string
+	Body       string
+	MailType   string
+	RewardGold int64
+	RewardGems int
+	Read       bool
+	Claimed    bool
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+}
+
+// Announcement is a notice shown to every player until it expires.
+type Announcement struct {
+	ID        int
+	Title     string
+	Content   string
+	Priority  string
+	CreatedAt time.Time
+}
+
+type MailboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMailboxRepository(pool *pgxpool.Pool) *MailboxRepository {
+	return &MailboxRepository{pool: pool}
+}
+
+// Send mails userID; expiresAt nil keeps it until it is claimed.
+func (r *MailboxRepository) Send(ctx context.Context, userID, title, body, mailType string, gold int64, gems int, expiresAt *time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO mailbox (user_id, title, body, mail_type, reward_gold, reward_gems, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		userID, title, body, mailType, gold, gems, expiresAt)
+	return err
+}
+
+// Inbox lists the latest unexpired mail of userID: personal mail, global mail and
+// active announcements, newest first.
+func (r *MailboxRepository) Inbox(ctx context.Context, userID string, limit int) ([]Mail, error) {
+	rows, err := r.pool.Query(ctx, `
+		(SELECT m.id::text, m.title, m.body, m.mail_type, m.reward_gold, m.reward_gems,
+			COALESCE(mc.read_at IS NOT NULL, m.read) as is_read,
+			COALESCE(mc.claimed, m.claimed) as is_claimed,
+			m.created_at, m.expires_at
+		FROM mailbox m
+		LEFT JOIN mailbox_claims mc ON mc.mail_id = m.id AND mc.user_id = $1
+		WHERE (m.user_id = $1 OR m.user_id IS NULL)
+			AND (m.expires_at IS NULL OR m.expires_at > NOW()))
+		UNION ALL
+		(SELECT 'ann_' || a.id::text as id, a.title, a.content as body,
+			'announcement' as mail_type, 0::bigint as reward_gold, 0 as reward_gems,
+			COALESCE(mc.read_at IS NOT NULL, false) as is_read,
+			true as is_claimed,
+			a.created_at, a.expires_at
+		FROM announcements a
+		LEFT JOIN mailbox_claims mc ON mc.mail_id = 'ann_' || a.id::text AND mc.user_id = $1
+		WHERE a.active = true
+			AND (a.expires_at IS NULL OR a.expires_at > NOW()))
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Mail, error) {
+		var m Mail
+		err := row.Scan(&m.ID, &m.Title, &m.Body, &m.MailType, &m.RewardGold, &m.RewardGems,
+			&m.Read, &m.Claimed, &m.CreatedAt, &m.ExpiresAt)
+		return m, err
+	})
+}
+
+// Announcements lists up to limit active announcements, most urgent first, then
+// newest first.
+func (r *MailboxRepository) Announcements(ctx context.Context, limit int) ([]Announcement, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, title, content, priority, created_at
+		 FROM announcements
+		 WHERE active = true AND (expires_at IS NULL OR expires_at > NOW())
+		 ORDER BY
+		   CASE priority WHEN 'urgent' THEN 0 WHEN 'important' THEN 1 ELSE 2 END,
+		   created_at DESC
+		 LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Announcement])
+}
+
+// MarkRead marks a personal, global or announcement mail read for userID.
+func (r *MailboxRepository) MarkRead(ctx context.Context, mailID, userID string) error {
+	// For personal mails, update directly
+	if _, err := r.pool.Exec(ctx, `UPDATE mailbox SET read = true WHERE id = $1 AND user_id = $2`, mailID, userID); err != nil {
+		return err
+	}
+	// Upsert into claims for global mails
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO mailbox_claims (mail_id, user_id, read_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (mail_id, user_id) DO UPDATE SET read_at = NOW()
+	`, mailID, userID)
+	return err
+}
+
+// Claimed reports whether userID has claimed a mail's rewards.
+func (r *MailboxRepository) Claimed(ctx context.Context, mailID, userID string) (bool, error) {
+	var claimed bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(
+			(SELECT claimed FROM mailbox_claims WHERE mail_id = $1 AND user_id = $2),
+			(SELECT claimed FROM mailbox WHERE id = $1 AND user_id = $2),
+			false
+		)
+	`, mailID, userID).Scan(&claimed)
+	return claimed, err
+}
+
+// Rewards returns the gold and gems attached to a mail.
+func (r *MailboxRepository) Rewards(ctx context.Context, mailID string) (gold int64, gems int, err error) {
+	err = r.pool.QueryRow(ctx, `SELECT reward_gold, reward_gems FROM mailbox WHERE id = $1`, mailID).Scan(&gold, &gems)
+	return gold, gems, err
+}
+
+// MarkClaimed records that userID claimed a mail's rewards.
+func (r *MailboxRepository) MarkClaimed(ctx context.Context, mailID, userID string) error {
+	// Personal mail
+	if _, err := r.pool.Exec(ctx, `UPDATE mailbox SET claimed = true, read = true WHERE id = $1 AND user_id = $2`, mailID, userID); err != nil {
+		return err
+	}
+	// Upsert claims for global mails
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO mailbox_claims (mail_id, user_id, read_at, claimed)
+		VALUES ($1, $2, NOW(), true)
+		ON CONFLICT (mail_id, user_id) DO UPDATE SET claimed = true
+	`, mailID, userID)
+	return err
+}
+
+// DeleteExpiredBefore deletes mail that expired before cutoff, along with its claims.
+func (r *MailboxRepository) DeleteExpiredBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM mailbox WHERE expires_at < $1`, cutoff)
+	return tag.RowsAffected(), err
+}
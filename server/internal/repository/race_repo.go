@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RaceResult is a finished race and what it paid out.
+type RaceResult struct {
+	Score      int
+	GoldReward int
+	ExpReward  int
+	PlayedAt   time.Time
+}
+
+type RaceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRaceRepository(pool *pgxpool.Pool) *RaceRepository {
+	return &RaceRepository{pool: pool}
+}
+
+// Record stores a verified race with its seed and input log so it can be
+// replayed later.
+func (r *RaceRepository) Record(ctx context.Context, userID, slimeID string, score, gold, exp int, seed int64, inputs []byte, distance int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO race_results (user_id, slime_id, score, gold_reward, exp_reward, seed, inputs, distance, verified)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)`,
+		userID, slimeID, score, gold, exp, seed, inputs, distance,
+	)
+	return err
+}
+
+// History lists userID's latest races, newest first.
+func (r *RaceRepository) History(ctx context.Context, userID string, limit int) ([]RaceResult, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT score, gold_reward, exp_reward, played_at
+		 FROM race_results WHERE user_id = $1
+		 ORDER BY played_at DESC LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[RaceResult])
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/slimetopia/server/internal/models"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	ErrRefreshTokenStale  = errors.New("refresh token already rotated")
+)
+
+// Session revocation reasons stored in user_sessions.revoked_reason.
+const (
+	SessionRevokedLogout        = "logout"
+	SessionRevokedByUser        = "revoked_by_user"
+	SessionRevokedReuse         = "reuse_detected"
+	SessionRevokedPasswordReset = "password_reset"
+)
+
+// refreshReuseGrace is how long the token a session was just rotated away from is
+// tolerated. Two tabs refreshing at the same moment present the same token; the loser
+// gets ErrRefreshTokenStale instead of taking the whole session down.
+const refreshReuseGrace = 30 * time.Second
+
+type SessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{pool: pool}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, s *models.Session) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO user_sessions (id, user_id, current_jti, device_label, user_agent, ip, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		s.ID, s.UserID, s.CurrentJTI, s.DeviceLabel, s.UserAgent, s.IP, s.ExpiresAt,
+	)
+	return err
+}
+
+// Rotate swaps the session's current refresh jti for newJTI. Presenting anything other
+// than the current jti revokes the session and returns ErrRefreshTokenReused, except for
+// the immediately previous jti within refreshReuseGrace (ErrRefreshTokenStale).
+func (r *SessionRepository) Rotate(ctx context.Context, sessionID, oldJTI, newJTI, ip, userAgent string, expiresAt time.Time) (*models.Session, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	s := &models.Session{}
+	var previousJTI *string
+	var rotatedAt *time.Time
+	err = tx.QueryRow(ctx,
+		`SELECT id::TEXT, user_id::TEXT, current_jti::TEXT, previous_jti::TEXT, rotated_at, expires_at, revoked_at
+		 FROM user_sessions WHERE id = $1 FOR UPDATE`,
+		sessionID,
+	).Scan(&s.ID, &s.UserID, &s.CurrentJTI, &previousJTI, &rotatedAt, &s.ExpiresAt, &s.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	if s.RevokedAt != nil || s.ExpiresAt.Before(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+
+	if s.CurrentJTI != oldJTI {
+		if previousJTI != nil && *previousJTI == oldJTI && rotatedAt != nil && time.Since(*rotatedAt) < refreshReuseGrace {
+			return nil, ErrRefreshTokenStale
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $2 WHERE id = $1`,
+			sessionID, SessionRevokedReuse,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return s, ErrRefreshTokenReused
+	}
+
+	err = tx.QueryRow(ctx,
+		`UPDATE user_sessions
+		 SET previous_jti = current_jti, current_jti = $2, rotated_at = NOW(), last_used_at = NOW(),
+		     ip = $3, user_agent = $4, expires_at = $5
+		 WHERE id = $1
+		 RETURNING device_label, user_agent, ip, created_at, last_used_at, expires_at`,
+		sessionID, newJTI, ip, userAgent, expiresAt,
+	).Scan(&s.DeviceLabel, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	s.CurrentJTI = newJTI
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListActive returns the user's unrevoked, unexpired sessions, most recently used first.
+func (r *SessionRepository) ListActive(ctx context.Context, userID string) ([]models.Session, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id::TEXT, user_id::TEXT, device_label, user_agent, ip, created_at, last_used_at, expires_at
+		 FROM user_sessions
+		 WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		 ORDER BY last_used_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.UserID, &s.DeviceLabel, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// Revoke ends one of the user's sessions. Returns ErrSessionNotFound if it is not theirs
+// or already revoked.
+func (r *SessionRepository) Revoke(ctx context.Context, userID, sessionID, reason string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID, reason,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAll ends every active session of the user except exceptID (may be empty)
+// and returns the IDs it revoked.
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID, exceptID, reason string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		 WHERE user_id = $1 AND revoked_at IS NULL AND id::TEXT <> $2
+		 RETURNING id::TEXT`,
+		userID, exceptID, reason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrShortNotFound = errors.New("short not found")
+
+// Short is a shorts video. Nickname and Liked are filled in for a viewer when
+// reading; Views, Likes, CommentCount, Status and CreatedAt are only written
+// when seeding.
+type Short struct {
+	ID              string
+	UserID          string
+	Nickname        string
+	Title           string
+	Description     string
+	VideoURL        string
+	ThumbnailURL    string
+	DurationMs      int
+	Tags            []string
+	Category        string
+	Visibility      string
+	LinkedSpeciesID *int
+	Views           int
+	Likes           int
+	CommentCount    int
+	Status          string
+	CreatedAt       time.Time
+	Liked           bool
+}
+
+// ShortComment is a comment on a short, with whether the viewer liked it.
+type ShortComment struct {
+	ID        string
+	UserID    string
+	Nickname  string
+	Content   string
+	Likes     int
+	CreatedAt time.Time
+	Liked     bool
+}
+
+type ShortsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewShortsRepository(pool *pgxpool.Pool) *ShortsRepository {
+	return &ShortsRepository{pool: pool}
+}
+
+// Create publishes an uploaded short and returns its id.
+func (r *ShortsRepository) Create(ctx context.Context, s *Short) (string, error) {
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO shorts (user_id, title, description, video_url, thumbnail_url, tags, category, visibility, linked_species_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		s.UserID, s.Title, s.Description, s.VideoURL, s.ThumbnailURL, s.Tags, s.Category, s.Visibility, s.LinkedSpeciesID,
+	).Scan(&id)
+	return id, err
+}
+
+// Seed inserts a short as given, counters, status and timestamp included.
+func (r *ShortsRepository) Seed(ctx context.Context, s *Short) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts (user_id, title, description, video_url, thumbnail_url, tags, category, visibility,
+		                     views, likes, comment_count, status, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		s.UserID, s.Title, s.Description, s.VideoURL, s.ThumbnailURL, s.Tags, s.Category, s.Visibility,
+		s.Views, s.Likes, s.CommentCount, s.Status, s.CreatedAt,
+	)
+	return err
+}
+
+// ActiveCount counts the shorts that are live.
+func (r *ShortsRepository) ActiveCount(ctx context.Context) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM shorts WHERE status = 'active'`).Scan(&n)
+	return n, err
+}
+
+const shortColumns = `s.id, s.user_id, u.nickname, s.title, s.description, s.video_url, s.thumbnail_url,
+	s.duration_ms, s.tags, s.category, s.visibility, s.linked_species_id, s.views, s.likes, s.comment_count,
+	s.status, s.created_at,
+	EXISTS(SELECT 1 FROM shorts_likes l WHERE l.short_id = s.id AND l.user_id = $1) AS liked`
+
+func scanShort(row pgx.Row) (Short, error) {
+	var s Short
+	err := row.Scan(&s.ID, &s.UserID, &s.Nickname, &s.Title, &s.Description, &s.VideoURL, &s.ThumbnailURL,
+		&s.DurationMs, &s.Tags, &s.Category, &s.Visibility, &s.LinkedSpeciesID, &s.Views, &s.Likes, &s.CommentCount,
+		&s.Status, &s.CreatedAt, &s.Liked)
+	return s, err
+}
+
+// Feed lists public shorts newest first, after the short cursorID when it is set.
+// It returns ErrShortNotFound for an unknown cursor.
+func (r *ShortsRepository) Feed(ctx context.Context, viewerID, cursorID string, limit int) ([]Short, error) {
+	var before *time.Time
+	if cursorID != "" {
+		err := r.pool.QueryRow(ctx, `SELECT created_at FROM shorts WHERE id = $1`, cursorID).Scan(&before)
+		if err != nil {
+			return nil, ErrShortNotFound
+		}
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+shortColumns+`
+		 FROM shorts s
+		 JOIN users u ON u.id = s.user_id
+		 WHERE s.status = 'active' AND s.visibility = 'public' AND ($2::timestamptz IS NULL OR s.created_at < $2)
+		 ORDER BY s.created_at DESC
+		 LIMIT $3`,
+		viewerID, before, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Short, error) { return scanShort(row) })
+}
+
+// Get loads a short whatever its status, as viewerID sees it.
+func (r *ShortsRepository) Get(ctx context.Context, viewerID, shortID string) (*Short, error) {
+	s, err := scanShort(r.pool.QueryRow(ctx,
+		`SELECT `+shortColumns+`
+		 FROM shorts s
+		 JOIN users u ON u.id = s.user_id
+		 WHERE s.id = $2`,
+		viewerID, shortID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrShortNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ByUser lists userID's shorts that aren't deleted, newest first.
+func (r *ShortsRepository) ByUser(ctx context.Context, userID string, limit int) ([]Short, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, title, video_url, thumbnail_url, views, likes, comment_count, status, created_at
+		 FROM shorts
+		 WHERE user_id = $1 AND status != 'deleted'
+		 ORDER BY created_at DESC
+		 LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Short, error) {
+		s := Short{UserID: userID}
+		err := row.Scan(&s.ID, &s.Title, &s.VideoURL, &s.ThumbnailURL, &s.Views, &s.Likes, &s.CommentCount, &s.Status, &s.CreatedAt)
+		return s, err
+	})
+}
+
+// Delete takes down one of userID's live shorts; it reports false if there was none.
+func (r *ShortsRepository) Delete(ctx context.Context, shortID, userID string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE shorts SET status = 'deleted' WHERE id = $1 AND user_id = $2 AND status = 'active'`,
+		shortID, userID,
+	)
+	return tag.RowsAffected() > 0, err
+}
+
+// RecordView counts userID's view of a short, once per user.
+func (r *ShortsRepository) RecordView(ctx context.Context, shortID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_views (short_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		shortID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx,
+		`UPDATE shorts SET views = (SELECT COUNT(*) FROM shorts_views WHERE short_id = $1) WHERE id = $1`,
+		shortID,
+	)
+	return err
+}
+
+func (r *ShortsRepository) Like(ctx context.Context, shortID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_likes (short_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		shortID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return r.recountLikes(ctx, shortID)
+}
+
+func (r *ShortsRepository) Unlike(ctx context.Context, shortID, userID string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM shorts_likes WHERE short_id = $1 AND user_id = $2`, shortID, userID)
+	if err != nil {
+		return err
+	}
+	return r.recountLikes(ctx, shortID)
+}
+
+func (r *ShortsRepository) recountLikes(ctx context.Context, shortID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE shorts SET likes = (SELECT COUNT(*) FROM shorts_likes WHERE short_id = $1) WHERE id = $1`,
+		shortID,
+	)
+	return err
+}
+
+// React sets userID's emoji reaction to a short, replacing any earlier one.
+func (r *ShortsRepository) React(ctx context.Context, shortID, userID, emoji string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_reactions (short_id, user_id, emoji) VALUES ($1, $2, $3)
+		 ON CONFLICT (short_id, user_id) DO UPDATE SET emoji = $3`,
+		shortID, userID, emoji,
+	)
+	return err
+}
+
+// Comments lists a short's latest comments as viewerID sees them.
+func (r *ShortsRepository) Comments(ctx context.Context, viewerID, shortID string, limit int) ([]ShortComment, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT sc.id, sc.user_id, u.nickname, sc.content, sc.likes, sc.created_at,
+		        EXISTS(SELECT 1 FROM shorts_comment_likes l WHERE l.comment_id = sc.id AND l.user_id = $1) as liked
+		 FROM shorts_comments sc
+		 JOIN users u ON u.id = sc.user_id
+		 WHERE sc.short_id = $2
+		 ORDER BY sc.created_at DESC
+		 LIMIT $3`,
+		viewerID, shortID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ShortComment, error) {
+		var c ShortComment
+		err := row.Scan(&c.ID, &c.UserID, &c.Nickname, &c.Content, &c.Likes, &c.CreatedAt, &c.Liked)
+		return c, err
+	})
+}
+
+// CreateComment comments on a short and returns the comment's id.
+func (r *ShortsRepository) CreateComment(ctx context.Context, shortID, userID, content string) (string, error) {
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO shorts_comments (short_id, user_id, content) VALUES ($1, $2, $3) RETURNING id`,
+		shortID, userID, content,
+	).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+	_, err = r.pool.Exec(ctx,
+		`UPDATE shorts SET comment_count = (SELECT COUNT(*) FROM shorts_comments WHERE short_id = $1) WHERE id = $1`,
+		shortID,
+	)
+	return id, err
+}
+
+// ActiveOwner returns who posted a live short, or ErrShortNotFound.
+func (r *ShortsRepository) ActiveOwner(ctx context.Context, shortID string) (string, error) {
+	var userID string
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id FROM shorts WHERE id = $1 AND status = 'active'`, shortID,
+	).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrShortNotFound
+	}
+	return userID, err
+}
+
+// TipsSentSince sums the tips of one type senderID has sent since a time.
+func (r *ShortsRepository) TipsSentSince(ctx context.Context, senderID, tipType string, since time.Time) (int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(amount), 0) FROM shorts_tips
+		 WHERE sender_id = $1 AND tip_type = $2 AND created_at >= $3`,
+		senderID, tipType, since,
+	).Scan(&total)
+	return total, err
+}
+
+// RecordTip records a tip; the currency moves separately.
+func (r *ShortsRepository) RecordTip(ctx context.Context, shortID, senderID, receiverID, tipType string, amount int, message string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO shorts_tips (short_id, sender_id, receiver_id, tip_type, amount, message)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		shortID, senderID, receiverID, tipType, amount, message,
+	)
+	return err
+}
+
+// TipsReceived sums the gold and gem tips userID's shorts have earned.
+func (r *ShortsRepository) TipsReceived(ctx context.Context, userID string) (gold, gems int, err error) {
+	err = r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(CASE WHEN tip_type='gold' THEN amount ELSE 0 END), 0),
+		        COALESCE(SUM(CASE WHEN tip_type='gems' THEN amount ELSE 0 END), 0)
+		 FROM shorts_tips WHERE receiver_id = $1`,
+		userID,
+	).Scan(&gold, &gems)
+	return gold, gems, err
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -79,20 +81,19 @@ func (r *SlimeRepository) FindByID(ctx context.Context, id string) (*models.Slim
 	return s, nil
 }
 
-func (r *SlimeRepository) Create(ctx context.Context, userID string, speciesID int, element, personality string) (*models.Slime, error) {
+const createSlimeSQL = `INSERT INTO slimes (user_id, species_id, element, personality,
+	                     talent_str, talent_vit, talent_spd, talent_int, talent_cha, talent_lck)
+	 VALUES ($1, $2, $3, $4,
+	         floor(random()*32), floor(random()*32), floor(random()*32),
+	         floor(random()*32), floor(random()*32), floor(random()*32))
+	 RETURNING id, user_id, species_id, name, level, exp, element, personality,
+	           affection, hunger, condition, position_x, position_y, accessories, is_sick,
+	           talent_str, talent_vit, talent_spd, talent_int, talent_cha, talent_lck, star_level,
+	           created_at, updated_at`
+
+func scanCreatedSlime(row pgx.Row) (*models.Slime, error) {
 	s := &models.Slime{}
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO slimes (user_id, species_id, element, personality,
-		                     talent_str, talent_vit, talent_spd, talent_int, talent_cha, talent_lck)
-		 VALUES ($1, $2, $3, $4,
-		         floor(random()*32), floor(random()*32), floor(random()*32),
-		         floor(random()*32), floor(random()*32), floor(random()*32))
-		 RETURNING id, user_id, species_id, name, level, exp, element, personality,
-		           affection, hunger, condition, position_x, position_y, accessories, is_sick,
-		           talent_str, talent_vit, talent_spd, talent_int, talent_cha, talent_lck, star_level,
-		           created_at, updated_at`,
-		userID, speciesID, element, personality,
-	).Scan(
+	err := row.Scan(
 		&s.ID, &s.UserID, &s.SpeciesID, &s.Name, &s.Level, &s.Exp,
 		&s.Element, &s.Personality, &s.Affection, &s.Hunger, &s.Condition,
 		&s.PositionX, &s.PositionY, &s.Accessories, &s.IsSick,
@@ -105,30 +106,32 @@ func (r *SlimeRepository) Create(ctx context.Context, userID string, speciesID i
 	return s, nil
 }
 
-// CreateWithTalents creates a slime with specific talent values (used for merge inheritance)
-func (r *SlimeRepository) CreateWithTalents(ctx context.Context, userID string, speciesID int, element, personality string, talents [6]int) (*models.Slime, error) {
-	s := &models.Slime{}
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO slimes (user_id, species_id, element, personality,
+func (r *SlimeRepository) Create(ctx context.Context, userID string, speciesID int, element, personality string) (*models.Slime, error) {
+	return scanCreatedSlime(r.pool.QueryRow(ctx, createSlimeSQL, userID, speciesID, element, personality))
+}
+
+// CreateSlimeTx inserts a slime with random talents inside tx.
+func CreateSlimeTx(ctx context.Context, tx pgx.Tx, userID string, speciesID int, element, personality string) (*models.Slime, error) {
+	return scanCreatedSlime(tx.QueryRow(ctx, createSlimeSQL, userID, speciesID, element, personality))
+}
+
+const createSlimeWithTalentsSQL = `INSERT INTO slimes (user_id, species_id, element, personality,
 		                     talent_str, talent_vit, talent_spd, talent_int, talent_cha, talent_lck)
 		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		 RETURNING id, user_id, species_id, name, level, exp, element, personality,
 		           affection, hunger, condition, position_x, position_y, accessories, is_sick,
 		           talent_str, talent_vit, talent_spd, talent_int, talent_cha, talent_lck, star_level,
-		           created_at, updated_at`,
-		userID, speciesID, element, personality,
-		talents[0], talents[1], talents[2], talents[3], talents[4], talents[5],
-	).Scan(
-		&s.ID, &s.UserID, &s.SpeciesID, &s.Name, &s.Level, &s.Exp,
-		&s.Element, &s.Personality, &s.Affection, &s.Hunger, &s.Condition,
-		&s.PositionX, &s.PositionY, &s.Accessories, &s.IsSick,
-		&s.TalentStr, &s.TalentVit, &s.TalentSpd, &s.TalentInt, &s.TalentCha, &s.TalentLck, &s.StarLevel,
-		&s.CreatedAt, &s.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	return s, nil
+		           created_at, updated_at`
+
+func (r *SlimeRepository) CreateWithTalents(ctx context.Context, userID string, speciesID int, element, personality string, talents [6]int) (*models.Slime, error) {
+	return scanCreatedSlime(r.pool.QueryRow(ctx, createSlimeWithTalentsSQL, userID, speciesID, element, personality,
+		talents[0], talents[1], talents[2], talents[3], talents[4], talents[5]))
+}
+
+// CreateSlimeWithTalentsTx inserts a slime with the given talents inside tx.
+func CreateSlimeWithTalentsTx(ctx context.Context, tx pgx.Tx, userID string, speciesID int, element, personality string, talents [6]int) (*models.Slime, error) {
+	return scanCreatedSlime(tx.QueryRow(ctx, createSlimeWithTalentsSQL, userID, speciesID, element, personality,
+		talents[0], talents[1], talents[2], talents[3], talents[4], talents[5]))
 }
 
 func (r *SlimeRepository) Delete(ctx context.Context, id string) error {
@@ -182,6 +185,45 @@ func (r *SlimeRepository) SetLevelAndExp(ctx context.Context, id string, level,
 	return err
 }
 
+// TalentColumns names the six talents in the order slimes carry them.
+var TalentColumns = [6]string{"talent_str", "talent_vit", "talent_spd", "talent_int", "talent_cha", "talent_lck"}
+
+func checkTalentColumn(talent string) error {
+	for _, col := range TalentColumns {
+		if col == talent {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown talent %q", talent)
+}
+
+// Awaken raises the slime's star level and grows one of its TalentColumns by
+// boost, up to 31.
+func (r *SlimeRepository) Awaken(ctx context.Context, id, talent string, boost int) error {
+	if err := checkTalentColumn(talent); err != nil {
+		return err
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE slimes SET star_level = star_level + 1,
+		 `+talent+` = LEAST(`+talent+` + $1, 31),
+		 updated_at = NOW() WHERE id = $2`,
+		boost, id,
+	)
+	return err
+}
+
+// GrowTalent grows one of the slime's TalentColumns by amount, up to 31.
+func (r *SlimeRepository) GrowTalent(ctx context.Context, id, talent string, amount int) error {
+	if err := checkTalentColumn(talent); err != nil {
+		return err
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE slimes SET `+talent+` = LEAST(`+talent+` + $1, 31) WHERE id = $2`,
+		amount, id,
+	)
+	return err
+}
+
 func (r *SlimeRepository) GetSpecies(ctx context.Context, speciesID int) (*models.SlimeSpecies, error) {
 	sp := &models.SlimeSpecies{}
 	err := r.pool.QueryRow(ctx,
@@ -222,6 +264,15 @@ func (r *SlimeRepository) AddCodexEntry(ctx context.Context, userID string, spec
 	return err
 }
 
+// AddCodexEntryTx records a species in the user's codex inside tx.
+func AddCodexEntryTx(ctx context.Context, tx pgx.Tx, userID string, speciesID int) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO codex_entries (user_id, species_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		userID, speciesID,
+	)
+	return err
+}
+
 func (r *SlimeRepository) GetCodex(ctx context.Context, userID string) ([]int, error) {
 	rows, err := r.pool.Query(ctx,
 		`SELECT species_id FROM codex_entries WHERE user_id = $1 ORDER BY species_id`,
@@ -243,6 +294,41 @@ func (r *SlimeRepository) GetCodex(ctx context.Context, userID string) ([]int, e
 	return ids, nil
 }
 
+// CodexGrades returns the grade of every species in the user's codex.
+func (r *SlimeRepository) CodexGrades(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ss.grade FROM codex_entries ce
+		 JOIN slime_species ss ON ss.id = ce.species_id
+		 WHERE ce.user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// EvolutionUnlocks lists the evolution tree nodes userID has unlocked for a species.
+func (r *SlimeRepository) EvolutionUnlocks(ctx context.Context, userID string, speciesID int) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT node_id FROM evolution_unlocks WHERE user_id = $1 AND species_id = $2`,
+		userID, speciesID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// UnlockEvolution records that userID unlocked an evolution tree node.
+func (r *SlimeRepository) UnlockEvolution(ctx context.Context, userID string, speciesID, nodeID int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO evolution_unlocks (user_id, species_id, node_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		userID, speciesID, nodeID,
+	)
+	return err
+}
+
 // ===== Recipe Discovery =====
 
 func (r *SlimeRepository) AddRecipeDiscovery(ctx context.Context, userID string, recipeID int) error {
@@ -282,3 +368,270 @@ func (r *SlimeRepository) CountByUser(ctx context.Context, userID string) (int,
 	).Scan(&count)
 	return count, err
 }
+
+// IsSlimeInTraining checks if any of the given slime IDs occupy a training slot
+func (r *SlimeRepository) IsSlimeInTraining(ctx context.Context, userID string, slimeIDs []string) (bool, error) {
+	var count int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM training_slots WHERE user_id = $1 AND slime_id = ANY($2)`,
+		userID, slimeIDs,
+	).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FreeSlotsTx locks the user row and returns how many more slimes fit in their
+// box, so concurrent purchases can't both pass the capacity check.
+func FreeSlotsTx(ctx context.Context, tx pgx.Tx, userID string) (int, error) {
+	var capacity, owned int
+	if err := tx.QueryRow(ctx,
+		`SELECT slime_capacity FROM users WHERE id = $1 FOR UPDATE`, userID,
+	).Scan(&capacity); err != nil {
+		return 0, err
+	}
+	if capacity <= 0 {
+		capacity = 30
+	}
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM slimes WHERE user_id = $1`, userID).Scan(&owned); err != nil {
+		return 0, err
+	}
+	return capacity - owned, nil
+}
+
+// ===== First Discovery =====
+
+// RecordFirstDiscovery credits the user with the server-wide first discovery of a
+// species. Returns false if someone else got there first.
+func (r *SlimeRepository) RecordFirstDiscovery(ctx context.Context, userID string, speciesID int, nickname string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, firstDiscoverySQL, speciesID, userID, nickname)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RecordFirstDiscoveryTx is RecordFirstDiscovery inside tx.
+func RecordFirstDiscoveryTx(ctx context.Context, tx pgx.Tx, userID string, speciesID int, nickname string) (bool, error) {
+	tag, err := tx.Exec(ctx, firstDiscoverySQL, speciesID, userID, nickname)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const firstDiscoverySQL = `INSERT INTO first_discoveries (species_id, user_id, nickname) VALUES ($1, $2, $3)
+	ON CONFLICT (species_id) DO NOTHING`
+
+// FirstDiscovery is the first player to discover a species.
+type FirstDiscovery struct {
+	SpeciesID int
+	Nickname  string
+}
+
+// FirstDiscoveries lists the latest first discoveries, newest first.
+func (r *SlimeRepository) FirstDiscoveries(ctx context.Context, limit int) ([]FirstDiscovery, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT species_id, nickname FROM first_discoveries ORDER BY discovered_at DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (FirstDiscovery, error) {
+		var d FirstDiscovery
+		err := row.Scan(&d.SpeciesID, &d.Nickname)
+		return d, err
+	})
+}
+
+// CountFirstDiscoveries counts the species the user discovered first.
+func (r *SlimeRepository) CountFirstDiscoveries(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM first_discoveries WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+// ===== Skills =====
+
+// SlimeSkill is a learned skill in one of a slime's slots.
+type SlimeSkill struct {
+	SkillID int
+	Slot    int
+}
+
+// SkillIDs returns the slime's learned skill IDs in slot order.
+func (r *SlimeRepository) SkillIDs(ctx context.Context, slimeID string) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT skill_id FROM slime_skills WHERE slime_id = $1 ORDER BY slot`, slimeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SkillsOf returns every learned skill of the given slimes.
+func (r *SlimeRepository) SkillsOf(ctx context.Context, slimeIDs []string) ([]SlimeSkill, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT skill_id, slot FROM slime_skills WHERE slime_id = ANY($1)`, slimeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var skills []SlimeSkill
+	for rows.Next() {
+		var s SlimeSkill
+		if err := rows.Scan(&s.SkillID, &s.Slot); err != nil {
+			return nil, err
+		}
+		skills = append(skills, s)
+	}
+	return skills, rows.Err()
+}
+
+// SlimeEffect is the effect JSON of one learned skill or equipped accessory.
+type SlimeEffect struct {
+	SlimeID string
+	Source  string // "skill" or "accessory"
+	Effect  json.RawMessage
+}
+
+// EffectsOf returns the effects of every learned skill and equipped accessory of the
+// given slimes.
+func (r *SlimeRepository) EffectsOf(ctx context.Context, slimeIDs []string) ([]SlimeEffect, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ss.slime_id::text, 'skill', gs.effect
+		 FROM slime_skills ss JOIN game_skills gs ON gs.id = ss.skill_id
+		 WHERE ss.slime_id = ANY($1)
+		 UNION ALL
+		 SELECT ea.slime_id::text, 'accessory', ga.effect
+		 FROM equipped_accessories ea JOIN game_accessories ga ON ga.id = ea.accessory_id
+		 WHERE ea.slime_id = ANY($1)`, slimeIDs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (SlimeEffect, error) {
+		var e SlimeEffect
+		err := row.Scan(&e.SlimeID, &e.Source, &e.Effect)
+		return e, err
+	})
+}
+
+// GameSkill is a skill a slime can learn.
+type GameSkill struct {
+	ID              int
+	Name            string
+	NameEN          string
+	Description     string
+	Icon            string
+	SkillType       string
+	ElementAffinity *string
+	GradeReq        string
+	LevelReq        int
+	Effect          json.RawMessage
+}
+
+// LearnedSkill is a skill in one of a slime's slots.
+type LearnedSkill struct {
+	Slot      int
+	Inherited bool
+	Skill     GameSkill
+}
+
+// SpeciesSkill is a skill a species can learn into a slot from a level on.
+type SpeciesSkill struct {
+	Slot       int
+	LearnLevel int
+	Skill      GameSkill
+}
+
+const gameSkillColumns = `gs.id, gs.name, gs.name_en, gs.description, gs.icon,
+	gs.skill_type, gs.element_affinity, gs.grade_req, gs.level_req, gs.effect`
+
+func gameSkillDest(s *GameSkill) []any {
+	return []any{&s.ID, &s.Name, &s.NameEN, &s.Description, &s.Icon,
+		&s.SkillType, &s.ElementAffinity, &s.GradeReq, &s.LevelReq, &s.Effect}
+}
+
+// LearnedSkills returns the slime's skills in slot order.
+func (r *SlimeRepository) LearnedSkills(ctx context.Context, slimeID string) ([]LearnedSkill, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT ss.slot, ss.inherited, `+gameSkillColumns+`
+		 FROM slime_skills ss
+		 JOIN game_skills gs ON gs.id = ss.skill_id
+		 WHERE ss.slime_id = $1
+		 ORDER BY ss.slot`,
+		slimeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (LearnedSkill, error) {
+		var l LearnedSkill
+		err := row.Scan(append([]any{&l.Slot, &l.Inherited}, gameSkillDest(&l.Skill)...)...)
+		return l, err
+	})
+}
+
+// SpeciesSkills returns the skills a species can learn, in slot order.
+func (r *SlimeRepository) SpeciesSkills(ctx context.Context, speciesID int) ([]SpeciesSkill, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT gss.slot, gss.learn_level, `+gameSkillColumns+`
+		 FROM game_species_skills gss
+		 JOIN game_skills gs ON gs.id = gss.skill_id
+		 WHERE gss.species_id = $1
+		 ORDER BY gss.slot`,
+		speciesID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (SpeciesSkill, error) {
+		var s SpeciesSkill
+		err := row.Scan(append([]any{&s.Slot, &s.LearnLevel}, gameSkillDest(&s.Skill)...)...)
+		return s, err
+	})
+}
+
+// SkillLearnLevel returns the level from which a species can learn a skill.
+func (r *SlimeRepository) SkillLearnLevel(ctx context.Context, speciesID, skillID int) (int, error) {
+	var level int
+	err := r.pool.QueryRow(ctx,
+		`SELECT learn_level FROM game_species_skills WHERE species_id = $1 AND skill_id = $2`,
+		speciesID, skillID,
+	).Scan(&level)
+	return level, err
+}
+
+// LearnSkill puts a skill in one of the slime's slots, replacing whatever was there.
+func (r *SlimeRepository) LearnSkill(ctx context.Context, slimeID string, skillID, slot int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO slime_skills (slime_id, skill_id, slot, inherited)
+		 VALUES ($1, $2, $3, false)
+		 ON CONFLICT (slime_id, slot) DO UPDATE SET skill_id = $2, inherited = false`,
+		slimeID, skillID, slot,
+	)
+	return err
+}
+
+// AddInheritedSkill gives the slime a skill passed down from a parent, unless the
+// slot is already taken.
+func (r *SlimeRepository) AddInheritedSkill(ctx context.Context, slimeID string, skillID, slot int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO slime_skills (slime_id, skill_id, slot, inherited)
+		 VALUES ($1, $2, $3, true)
+		 ON CONFLICT (slime_id, slot) DO NOTHING`,
+		slimeID, skillID, slot,
+	)
+	return err
+}
@@ -0,0 +1,489 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/slimetopia/server/internal/models"
+)
+
+// SlimeStore reads and writes slimes, species, codex, first discoveries and skills.
+type SlimeStore interface {
+	FindByUser(ctx context.Context, userID string) ([]models.Slime, error)
+	FindByID(ctx context.Context, id string) (*models.Slime, error)
+	Create(ctx context.Context, userID string, speciesID int, element, personality string) (*models.Slime, error)
+	CreateWithTalents(ctx context.Context, userID string, speciesID int, element, personality string, talents [6]int) (*models.Slime, error)
+	Delete(ctx context.Context, id string) error
+	UpdateName(ctx context.Context, id, name string) error
+	UpdateStats(ctx context.Context, id string, affection, hunger, condition int) error
+	UpdateSick(ctx context.Context, id string, isSick bool) error
+	UpdatePersonality(ctx context.Context, id, personality string) error
+	AddExp(ctx context.Context, id string, exp int) error
+	SetLevelAndExp(ctx context.Context, id string, level, exp int) error
+	Awaken(ctx context.Context, id, talent string, boost int) error
+	GrowTalent(ctx context.Context, id, talent string, amount int) error
+	GetSpecies(ctx context.Context, speciesID int) (*models.SlimeSpecies, error)
+	GetAllSpecies(ctx context.Context) ([]models.SlimeSpecies, error)
+	AddCodexEntry(ctx context.Context, userID string, speciesID int) error
+	GetCodex(ctx context.Context, userID string) ([]int, error)
+	CodexGrades(ctx context.Context, userID string) ([]string, error)
+	EvolutionUnlocks(ctx context.Context, userID string, speciesID int) ([]int, error)
+	UnlockEvolution(ctx context.Context, userID string, speciesID, nodeID int) error
+	AddRecipeDiscovery(ctx context.Context, userID string, recipeID int) error
+	GetDiscoveredRecipes(ctx context.Context, userID string) ([]int, error)
+	CountByUser(ctx context.Context, userID string) (int, error)
+	IsSlimeInTraining(ctx context.Context, userID string, slimeIDs []string) (bool, error)
+	RecordFirstDiscovery(ctx context.Context, userID string, speciesID int, nickname string) (bool, error)
+	FirstDiscoveries(ctx context.Context, limit int) ([]FirstDiscovery, error)
+	CountFirstDiscoveries(ctx context.Context, userID string) (int, error)
+	SkillIDs(ctx context.Context, slimeID string) ([]int, error)
+	SkillsOf(ctx context.Context, slimeIDs []string) ([]SlimeSkill, error)
+	EffectsOf(ctx context.Context, slimeIDs []string) ([]SlimeEffect, error)
+	AddInheritedSkill(ctx context.Context, slimeID string, skillID, slot int) error
+	LearnedSkills(ctx context.Context, slimeID string) ([]LearnedSkill, error)
+	SpeciesSkills(ctx context.Context, speciesID int) ([]SpeciesSkill, error)
+	SkillLearnLevel(ctx context.Context, speciesID, skillID int) (int, error)
+	LearnSkill(ctx context.Context, slimeID string, skillID, slot int) error
+}
+
+// UserStore reads and writes accounts, wallets and inventory.
+type UserStore interface {
+	FindByProviderID(ctx context.Context, provider, providerID string) (*models.User, error)
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	Create(ctx context.Context, nickname, provider, providerID string) (*models.User, error)
+	UpdateNickname(ctx context.Context, id, nickname string) error
+	IDByNickname(ctx context.Context, nickname string) (string, error)
+	SetProfileImage(ctx context.Context, id, imageURL string) error
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	SetEmailPassword(ctx context.Context, userID, email, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, userID, email string) error
+	AddCurrency(ctx context.Context, userID string, gold int64, gems, stardust int, reason, refID string) error
+	TransferCurrency(ctx context.Context, fromID, toID string, gold int64, gems int, reason, refID string) error
+	GetCommunityStats(ctx context.Context, userID string) (map[string]int, error)
+	UpdateNicknameWithCost(ctx context.Context, userID, nickname string, goldCost int64) error
+	GetPasswordHash(ctx context.Context, userID string) (string, error)
+	ChangePassword(ctx context.Context, userID, newHash string) error
+	GetCapacity(ctx context.Context, userID string) (int, error)
+	UpdateCapacity(ctx context.Context, userID string, newCapacity int) error
+	SpendCurrency(ctx context.Context, userID string, gold int64, gems, stardust int, reason, refID string) error
+	GetActiveBan(ctx context.Context, userID string) (*models.BanInfo, error)
+	ListIdentities(ctx context.Context, userID string) ([]models.Identity, error)
+	LinkIdentity(ctx context.Context, userID, provider, providerID, email string) error
+	LinkEmail(ctx context.Context, userID, email, passwordHash string) error
+	AddMaterial(ctx context.Context, userID string, materialID, qty int) error
+	Materials(ctx context.Context, userID string) ([]MaterialStack, error)
+	ConsumeMaterials(ctx context.Context, userID string, costs []MaterialStack) error
+	ConsumeMaterial(ctx context.Context, userID string, materialID int) (bool, error)
+	AddFood(ctx context.Context, userID string, itemID, qty int) error
+	Foods(ctx context.Context, userID string) ([]FoodStack, error)
+	FoodQuantity(ctx context.Context, userID string, itemID int) (int, error)
+	ConsumeFood(ctx context.Context, userID string, itemID, qty int) (bool, error)
+}
+
+// ExplorationStore reads and writes explorations.
+type ExplorationStore interface {
+	Create(ctx context.Context, userID string, destID int, slimeIDs []string, endsAt time.Time) (*Exploration, error)
+	FindActiveByUser(ctx context.Context, userID string) ([]Exploration, error)
+	FindByID(ctx context.Context, id string) (*Exploration, error)
+	MarkClaimed(ctx context.Context, id string) error
+	IsSlimeOnExploration(ctx context.Context, userID string, slimeIDs []string) (bool, error)
+}
+
+// MissionStore reads and writes daily missions and attendance.
+type MissionStore interface {
+	GetOrCreateDaily(ctx context.Context, userID string) ([]DailyMission, error)
+	IncrementProgress(ctx context.Context, userID, action string)
+	ClaimReward(ctx context.Context, userID, missionDBID string) (gold int64, gems int, err error)
+	CheckAttendance(ctx context.Context, userID string) (*AttendanceInfo, error)
+	ClaimAttendance(ctx context.Context, userID string) (gold int64, gems int, dayNum int, err error)
+	GetAttendanceRewards() []AttendanceReward
+}
+
+// VillageStore reads and writes villages and guestbooks.
+type VillageStore interface {
+	GetOrCreate(ctx context.Context, userID string) (*models.Village, error)
+	GetByID(ctx context.Context, id string) (*models.Village, error)
+	GetRandom(ctx context.Context, excludeUserID string, limit int) ([]models.Village, error)
+	GetByUsers(ctx context.Context, userIDs []string) ([]models.Village, error)
+	IncrementVisit(ctx context.Context, villageID string) error
+	AddLike(ctx context.Context, villageID string) error
+	GetGuestbook(ctx context.Context, villageID string, limit int) ([]GuestbookEntry, error)
+	AddGuestbookEntry(ctx context.Context, villageID, authorID, message string) error
+}
+
+// GameDataStore reads and writes admin-editable game data.
+type GameDataStore interface {
+	GetAllRecipes(ctx context.Context) ([]GameRecipe, error)
+	GetAllRecipesIncludeInactive(ctx context.Context) ([]GameRecipe, error)
+	FindRecipe(ctx context.Context, speciesA, speciesB int) (*GameRecipe, error)
+	GetRecipeByID(ctx context.Context, id int) (*GameRecipe, error)
+	CreateRecipe(ctx context.Context, rec *GameRecipe) error
+	UpdateRecipe(ctx context.Context, rec *GameRecipe) error
+	DeleteRecipe(ctx context.Context, id int) error
+	GetAllShopItems(ctx context.Context) ([]GameShopItem, error)
+	GetAllShopItemsIncludeInactive(ctx context.Context) ([]GameShopItem, error)
+	GetShopItemByID(ctx context.Context, id int) (*GameShopItem, error)
+	CreateShopItem(ctx context.Context, item *GameShopItem) error
+	UpdateShopItem(ctx context.Context, item *GameShopItem) error
+	DeleteShopItem(ctx context.Context, id int) error
+	GetAllMaterials(ctx context.Context) ([]GameMaterial, error)
+	GetAllMaterialsIncludeInactive(ctx context.Context) ([]GameMaterial, error)
+	GetMaterialByID(ctx context.Context, id int) (*GameMaterial, error)
+	CreateMaterial(ctx context.Context, m *GameMaterial) error
+	UpdateMaterial(ctx context.Context, m *GameMaterial) error
+	DeleteMaterial(ctx context.Context, id int) error
+	GetAllDestinations(ctx context.Context) ([]GameExploration, error)
+	GetAllDestinationsIncludeInactive(ctx context.Context) ([]GameExploration, error)
+	GetDestinationByID(ctx context.Context, id int) (*GameExploration, error)
+	CreateExploration(ctx context.Context, e *GameExploration) error
+	UpdateExploration(ctx context.Context, e *GameExploration) error
+	DeleteExploration(ctx context.Context, id int) error
+	GetAllAchievements(ctx context.Context) ([]GameAchievement, error)
+	GetAllAchievementsIncludeInactive(ctx context.Context) ([]GameAchievement, error)
+	CreateAchievement(ctx context.Context, a *GameAchievement) error
+	UpdateAchievement(ctx context.Context, a *GameAchievement) error
+	DeleteAchievement(ctx context.Context, key string) error
+	GetAllAccessories(ctx context.Context) ([]GameAccessory, error)
+	GetAllAccessoriesIncludeInactive(ctx context.Context) ([]GameAccessory, error)
+	GetAccessoryByID(ctx context.Context, id int) (*GameAccessory, error)
+	CreateAccessory(ctx context.Context, a *GameAccessory) error
+	UpdateAccessory(ctx context.Context, a *GameAccessory) error
+	DeleteAccessory(ctx context.Context, id int) error
+	GetAllMissions(ctx context.Context) ([]GameMission, error)
+	GetAllMissionsIncludeInactive(ctx context.Context) ([]GameMission, error)
+	CreateMission(ctx context.Context, m *GameMission) error
+	UpdateMission(ctx context.Context, m *GameMission) error
+	DeleteMission(ctx context.Context, id int) error
+	GetAttendanceRewards(ctx context.Context) ([]GameAttendanceReward, error)
+	UpsertAttendanceReward(ctx context.Context, a *GameAttendanceReward) error
+	GetEvolutionTree(ctx context.Context, speciesID int) ([]GameEvolutionNode, error)
+	GetAllEvolutionSpecies(ctx context.Context) ([]int, error)
+	UpsertEvolutionNode(ctx context.Context, n *GameEvolutionNode) error
+	DeleteEvolutionNode(ctx context.Context, speciesID, nodeID int) error
+	GetAllSeasons(ctx context.Context) ([]GameSeason, error)
+	GetActiveSeason(ctx context.Context) (*GameSeason, error)
+	GetSeasonByID(ctx context.Context, id int) (*GameSeason, error)
+	CreateSeason(ctx context.Context, s *GameSeason) error
+	UpdateSeason(ctx context.Context, s *GameSeason) error
+	DeleteSeason(ctx context.Context, id int) error
+	CloseEndedSeasons(ctx context.Context) (int64, error)
+	GetAllSets(ctx context.Context) ([]GameSlimeSet, error)
+	GetAllSetsIncludeInactive(ctx context.Context) ([]GameSlimeSet, error)
+	CreateSet(ctx context.Context, s *GameSlimeSet) error
+	UpdateSet(ctx context.Context, s *GameSlimeSet) error
+	DeleteSet(ctx context.Context, id int) error
+	GetAllMutationRecipes(ctx context.Context) ([]GameMutationRecipe, error)
+	GetAllMutationRecipesIncludeInactive(ctx context.Context) ([]GameMutationRecipe, error)
+	CreateMutationRecipe(ctx context.Context, m *GameMutationRecipe) error
+	UpdateMutationRecipe(ctx context.Context, m *GameMutationRecipe) error
+	DeleteMutationRecipe(ctx context.Context, id int) error
+	GetAllGachaBanners(ctx context.Context) ([]GameGachaBanner, error)
+	GetGachaBanner(ctx context.Context, id string) (*GameGachaBanner, error)
+	CreateGachaBanner(ctx context.Context, b *GameGachaBanner) error
+	UpdateGachaBanner(ctx context.Context, b *GameGachaBanner) error
+	DeleteGachaBanner(ctx context.Context, id string) error
+	GetAllCraftingRecipes(ctx context.Context) ([]GameCraftingRecipe, error)
+	GetCraftingRecipe(ctx context.Context, id int) (*GameCraftingRecipe, error)
+	GetAwakeningCost(ctx context.Context, grade string, starLevel int) (*GameAwakeningCost, error)
+}
+
+// FriendStore reads and writes friends and friend requests.
+type FriendStore interface {
+	FindByFriendCode(ctx context.Context, code string) (string, error)
+	FriendCode(ctx context.Context, userID string) (string, error)
+	SendRequest(ctx context.Context, senderID, receiverID string) (accepted bool, err error)
+	Accept(ctx context.Context, userID, requestID string) (string, error)
+	Decline(ctx context.Context, userID, requestID string) error
+	Cancel(ctx context.Context, userID, requestID string) error
+	Remove(ctx context.Context, userID, friendID string) error
+	AreFriends(ctx context.Context, userID, otherID string) (bool, error)
+	FriendIDs(ctx context.Context, userID string) ([]string, error)
+	ListFriends(ctx context.Context, userID string) ([]models.Friend, error)
+	ListRequests(ctx context.Context, userID string) ([]models.FriendRequest, error)
+}
+
+// GuildStore reads and writes guilds, membership and guild chat.
+type GuildStore interface {
+	Membership(ctx context.Context, userID string) (*models.GuildMember, error)
+	Create(ctx context.Context, userID, name, description, joinPolicy string, goldCost int64) (string, error)
+	Get(ctx context.Context, guildID string) (*models.Guild, error)
+	Search(ctx context.Context, query string, limit int) ([]models.Guild, error)
+	Members(ctx context.Context, guildID string) ([]models.GuildMember, error)
+	Update(ctx context.Context, userID, description, joinPolicy string) error
+	Disband(ctx context.Context, userID string) (string, error)
+	Join(ctx context.Context, userID, guildID string) (joined bool, err error)
+	Leave(ctx context.Context, userID string) (disbanded bool, err error)
+	Invite(ctx context.Context, actorID, targetID string) (joined bool, err error)
+	PendingRequests(ctx context.Context, guildID string) ([]models.GuildRequest, error)
+	InvitesFor(ctx context.Context, userID string) ([]models.GuildRequest, error)
+	RespondApplication(ctx context.Context, actorID, requestID string, accept bool) (string, error)
+	RespondInvite(ctx context.Context, userID, requestID string, accept bool) (string, error)
+	WithdrawApplication(ctx context.Context, userID, requestID string) error
+	SetRole(ctx context.Context, actorID, targetID, newRole string) error
+	Kick(ctx context.Context, actorID, targetID string) error
+	AddXP(ctx context.Context, userID string, xp int64) (level int, leveledUp bool, err error)
+	AddGuildXP(ctx context.Context, guildID string, xp int64) (level int, leveledUp bool, err error)
+	PostMessage(ctx context.Context, guildID, userID, message string) (*models.GuildMessage, error)
+	Messages(ctx context.Context, guildID string, afterID int64, limit int) ([]models.GuildMessage, error)
+}
+
+// CommunityStore reads and writes community posts, replies, reactions, polls,
+// reports and blocks.
+type CommunityStore interface {
+	Posts(ctx context.Context, viewerID, postType, search string, limit, offset int) ([]CommunityPost, error)
+	Bookmarks(ctx context.Context, userID string, limit, offset int) ([]CommunityPost, error)
+	TrendingPosts(ctx context.Context, viewerID string, limit int) ([]CommunityPost, error)
+	CreatePost(ctx context.Context, userID, content, postType string, imageURLs []string) (string, error)
+	CreatePoll(ctx context.Context, postID string, options []string, expiresAt time.Time) error
+	DeletePost(ctx context.Context, postID, userID string) (bool, error)
+	LikePost(ctx context.Context, postID, userID string) (int, error)
+	UnlikePost(ctx context.Context, postID, userID string) (int, error)
+	RecordView(ctx context.Context, postID, userID string) error
+	React(ctx context.Context, postID, userID, emoji string) (map[string]int, error)
+	Unreact(ctx context.Context, postID, userID string) (map[string]int, error)
+	Bookmark(ctx context.Context, postID, userID string) error
+	Unbookmark(ctx context.Context, postID, userID string) error
+	Replies(ctx context.Context, viewerID, postID string) ([]CommunityReply, error)
+	CreateReply(ctx context.Context, postID, userID, content, parentID string) (string, error)
+	DeleteReply(ctx context.Context, replyID, userID string) (bool, error)
+	LikeReply(ctx context.Context, replyID, userID string) error
+	UnlikeReply(ctx context.Context, replyID, userID string) error
+	Report(ctx context.Context, reporterID, targetType, targetID, reason, detail string) error
+	Block(ctx context.Context, blockerID, blockedID string) error
+	Unblock(ctx context.Context, blockerID, blockedID string) error
+	BlockedUsers(ctx context.Context, userID string) ([]BlockedUser, error)
+	TrendingTags(ctx context.Context, limit int) ([]TagCount, error)
+	Poll(ctx context.Context, postID string) (*CommunityPoll, error)
+	PollVotes(ctx context.Context, pollID, userID string) (map[int]int, *int, error)
+	Vote(ctx context.Context, pollID, userID string, option int) error
+}
+
+// AchievementStore tracks unlocked achievements and the counters they are
+// judged against.
+type AchievementStore interface {
+	Unlocked(ctx context.Context, userID string) (map[string]time.Time, error)
+	Unlock(ctx context.Context, userID, key string) (bool, error)
+	Progress(ctx context.Context, userID string) (*AchievementProgress, error)
+}
+
+// AccessoryStore reads and writes owned and equipped slime accessories.
+type AccessoryStore interface {
+	Owned(ctx context.Context, userID string) ([]int, error)
+	Owns(ctx context.Context, userID string, accessoryID int) (bool, error)
+	Grant(ctx context.Context, userID string, accessoryID int) error
+	Equip(ctx context.Context, slimeID, slot string, accessoryID int) error
+	Unequip(ctx context.Context, userID, slimeID, slot string) error
+	EquippedByUser(ctx context.Context, userID string) ([]EquippedAccessory, error)
+	EquippedOn(ctx context.Context, slimeID string) ([]EquippedAccessory, error)
+}
+
+// ArenaStore holds arena seasons, ratings, defense teams and fought battles.
+type ArenaStore interface {
+	CurrentSeason(ctx context.Context, length time.Duration) (*ArenaSeason, bool, error)
+	EndedSeasons(ctx context.Context) ([]int, error)
+	SettleSeason(ctx context.Context, seasonID int, mailExpires time.Time, reward func(rank, rating int) ArenaSeasonReward) (bool, int, error)
+	Standing(ctx context.Context, seasonID int, userID string) (*ArenaStanding, error)
+	Rank(ctx context.Context, seasonID, rating int) (int, error)
+	Ranking(ctx context.Context, seasonID, limit int) ([]ArenaStanding, error)
+	Defense(ctx context.Context, userID string) ([]string, error)
+	SetDefense(ctx context.Context, userID string, slimeIDs []string) error
+	Opponents(ctx context.Context, seasonID int, userID string, rating, startRating, band, limit int) ([]ArenaOpponent, error)
+	RecordBattle(ctx context.Context, b *ArenaBattle, startRating int, rate func(ratings, games [2]int) [2]int) error
+	Battles(ctx context.Context, userID string, limit int) ([]ArenaBattleSummary, error)
+	Battle(ctx context.Context, battleID int64) (*ArenaBattle, error)
+}
+
+// BreedingStore lays and hatches bred eggs and reads the lineage family trees are
+// built from.
+type BreedingStore interface {
+	LayEgg(ctx context.Context, userID, parentA, parentB string, lay func(a, b *BreedLineage) (*SlimeEgg, error)) (*SlimeEgg, error)
+	Eggs(ctx context.Context, userID string) ([]SlimeEgg, error)
+	HatchEgg(ctx context.Context, userID string, eggID int64) (*SlimeEgg, *models.Slime, error)
+	Lineage(ctx context.Context, slimeID string, depth int) ([]SlimeLineage, error)
+	FamilySlimes(ctx context.Context, userID string, ids []string) ([]FamilySlime, error)
+}
+
+// MailboxStore sends mail and tracks what each reader has read and claimed.
+type MailboxStore interface {
+	Send(ctx context.Context, userID, title, body, mailType string, gold int64, gems int, expiresAt *time.Time) error
+	Inbox(ctx context.Context, userID string, limit int) ([]Mail, error)
+	Announcements(ctx context.Context, limit int) ([]Announcement, error)
+	MarkRead(ctx context.Context, mailID, userID string) error
+	Claimed(ctx context.Context, mailID, userID string) (bool, error)
+	Rewards(ctx context.Context, mailID string) (int64, int, error)
+	MarkClaimed(ctx context.Context, mailID, userID string) error
+	DeleteExpiredBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ShortsStore holds shorts videos and their views, likes, reactions, comments
+// and tips.
+type ShortsStore interface {
+	Create(ctx context.Context, s *Short) (string, error)
+	Seed(ctx context.Context, s *Short) error
+	ActiveCount(ctx context.Context) (int, error)
+	Feed(ctx context.Context, viewerID, cursorID string, limit int) ([]Short, error)
+	Get(ctx context.Context, viewerID, shortID string) (*Short, error)
+	ByUser(ctx context.Context, userID string, limit int) ([]Short, error)
+	Delete(ctx context.Context, shortID, userID string) (bool, error)
+	RecordView(ctx context.Context, shortID, userID string) error
+	Like(ctx context.Context, shortID, userID string) error
+	Unlike(ctx context.Context, shortID, userID string) error
+	React(ctx context.Context, shortID, userID, emoji string) error
+	Comments(ctx context.Context, viewerID, shortID string, limit int) ([]ShortComment, error)
+	CreateComment(ctx context.Context, shortID, userID, content string) (string, error)
+	ActiveOwner(ctx context.Context, shortID string) (string, error)
+	TipsSentSince(ctx context.Context, senderID, tipType string, since time.Time) (int, error)
+	RecordTip(ctx context.Context, shortID, senderID, receiverID, tipType string, amount int, message string) error
+	TipsReceived(ctx context.Context, userID string) (int, int, error)
+}
+
+// GameLogStore records game actions for auditing.
+type GameLogStore interface {
+	Log(ctx context.Context, userID, action, category string, goldDelta int64, gemsDelta, stardustDelta int, detail []byte) error
+}
+
+// Handlers depend on the Store interfaces; the Postgres repositories are the
+// production implementations.
+// BotStore seeds bot accounts and their slimes, posts, replies, shorts and likes,
+// and carries out the scheduled bot activity.
+type BotStore interface {
+	Count(ctx context.Context, emailLike string) (int, error)
+	First(ctx context.Context, emailLike string) (string, error)
+	IDs(ctx context.Context, emailLike string) ([]string, error)
+	ID(ctx context.Context, email string) (string, error)
+	Create(ctx context.Context, nickname, providerID, email, passwordHash string, level int, gold int64, gems, stardust int) (string, error)
+	Delete(ctx context.Context, emailLike string) (int64, error)
+	CreateVillage(ctx context.Context, userID, name, terrain string, visits, likes int) error
+	CreateSlime(ctx context.Context, userID string, speciesID int, element, personality string, level, exp, affection, hunger, condition int) (string, error)
+	RandomSpecies(ctx context.Context, element string) (int, error)
+	LevelUpSlime(ctx context.Context, userID string) error
+	LevelUp(ctx context.Context, userID string, stardust int) error
+	AddCollectionEntry(ctx context.Context, userID string, speciesID int, personality string) error
+	AddRaceResult(ctx context.Context, userID, slimeID string, score, gold, exp int, playedAt time.Time) error
+	CreatePost(ctx context.Context, userID, content, postType string, likes, views int, createdAt time.Time) (string, error)
+	RecentPost(ctx context.Context) (string, error)
+	CreateReply(ctx context.Context, postID, userID, content, parentID string, createdAt time.Time) (string, error)
+	LikePost(ctx context.Context, postID, userID string) error
+	LikeReply(ctx context.Context, replyID, userID string) error
+	RecountPosts(ctx context.Context, postIDs []string) error
+	RecountReplies(ctx context.Context, replyIDs []string) error
+	CreateShort(ctx context.Context, userID, title, description string, tags []string, category string, views, likes int, createdAt time.Time) (string, error)
+	CommentShort(ctx context.Context, shortID, userID, content string, createdAt time.Time) error
+	LikeShort(ctx context.Context, shortID, userID string) error
+	ShortCommentIDs(ctx context.Context, shortID string) ([]string, error)
+	LikeShortComment(ctx context.Context, commentID, userID string) error
+	RecountShorts(ctx context.Context, shortIDs []string) error
+}
+
+// CollectionStore holds collection entries and claimed collection milestones.
+type CollectionStore interface {
+	Count(ctx context.Context, userID string) (int, error)
+	Entries(ctx context.Context, userID string) ([]CollectionEntry, error)
+	Has(ctx context.Context, userID string, speciesID int, personality string) (bool, error)
+	SpeciesCount(ctx context.Context, userID string, speciesID int) (int, error)
+	SpeciesIDs(ctx context.Context, userID string) ([]int, error)
+	Submit(ctx context.Context, userID string, speciesID int, personality string) (bool, error)
+	ClaimedMilestones(ctx context.Context, userID string) ([]int, error)
+	ClaimMilestone(ctx context.Context, userID string, milestone int) (bool, error)
+}
+
+// TrainingStore holds the slimes training in users' slots.
+type TrainingStore interface {
+	Slots(ctx context.Context, userID string) ([]TrainingSlot, error)
+	Start(ctx context.Context, userID, slimeID, mode string, maxSlots int) (int, error)
+	Collect(ctx context.Context, userID, slotID string) (*TrainingSlot, error)
+}
+
+// RaceStore records race results.
+type RaceStore interface {
+	Record(ctx context.Context, userID, slimeID string, score, gold, exp int, seed int64, inputs []byte, distance int) error
+	History(ctx context.Context, userID string, limit int) ([]RaceResult, error)
+}
+
+// LeaderboardStore ranks players.
+type LeaderboardStore interface {
+	Top(ctx context.Context, lbType string, userIDs []string, limit int) ([]LeaderboardEntry, error)
+	CollectionRank(ctx context.Context, userID string) (int, error)
+}
+
+// SupportStore holds players' support tickets.
+type SupportStore interface {
+	CreateTicket(ctx context.Context, userID, subject, category, message string) (int64, error)
+	Tickets(ctx context.Context, userID string, limit int) ([]SupportTicket, error)
+	Owner(ctx context.Context, ticketID string) (string, error)
+	Replies(ctx context.Context, ticketID string) ([]SupportReply, error)
+	Reply(ctx context.Context, ticketID, userID, message string) error
+}
+
+// IdleStore tracks when players last collected their offline rewards.
+type IdleStore interface {
+	Progress(ctx context.Context, userID string) (time.Time, int, error)
+	MarkCollected(ctx context.Context, userID string) error
+}
+
+// GiftStore logs gifts between friends.
+type GiftStore interface {
+	SentSince(ctx context.Context, senderID, giftType string, since time.Time) (int, error)
+	Log(ctx context.Context, senderID, receiverID, giftType string, amount int, message string) error
+	History(ctx context.Context, userID string, limit int) ([]Gift, error)
+}
+
+// GachaStore holds egg pulls and the pity counters that guarantee high grades.
+type GachaStore interface {
+	Pity(ctx context.Context, userID string) (map[string]int, error)
+	Pull(ctx context.Context, userID, nickname, bannerID string, n int, gold int64, gems int, refID string, roll func(pity int) ([]GachaHatch, int, bool, error)) (*GachaPull, int, error)
+}
+
+// GuildBossStore holds guild raid bosses and the attacks on them.
+type GuildBossStore interface {
+	Current(ctx context.Context, guildID string, spawn func(stage int) GuildBoss) (*GuildBoss, error)
+	DamageTable(ctx context.Context, bossID int) ([]GuildBossDamage, error)
+	Attack(ctx context.Context, bossID int, userID string, slimeIDs []string, damage int) (int64, error)
+	Unsettled(ctx context.Context) ([]int, error)
+	Settle(ctx context.Context, bossID int, plan func(b *GuildBoss, table []GuildBossDamage) *GuildBossSettlement) (*GuildBossSettlement, error)
+}
+
+// WorldBossStore holds the server-wide raid boss, the attacks on it and its
+// settlement.
+type WorldBossStore interface {
+	Current(ctx context.Context) (*WorldBoss, error)
+	Attackable(ctx context.Context) (*WorldBoss, error)
+	UserDamage(ctx context.Context, bossID int, userID string) (int64, error)
+	TopAttackers(ctx context.Context, bossID, limit int) ([]WorldBossAttacker, error)
+	UserRank(ctx context.Context, bossID int, userID string) (int, error)
+	Attack(ctx context.Context, bossID int, userID string, slimeIDs []string, damage int) (int64, error)
+	MarkDefeated(ctx context.Context, bossID int) (bool, error)
+	Unsettled(ctx context.Context) ([]WorldBoss, error)
+	EnsureSpawned(ctx context.Context, spawn func() WorldBoss) (*WorldBoss, error)
+	Settle(ctx context.Context, bossID int, plan func(b *WorldBoss, rankings []WorldBossRanking) *WorldBossSettlement) (*WorldBossSettlement, error)
+}
+
+var (
+	_ SlimeStore       = (*SlimeRepository)(nil)
+	_ UserStore        = (*UserRepository)(nil)
+	_ ExplorationStore = (*ExplorationRepository)(nil)
+	_ MissionStore     = (*MissionRepository)(nil)
+	_ VillageStore     = (*VillageRepository)(nil)
+	_ GameDataStore    = (*GameDataRepository)(nil)
+	_ FriendStore      = (*FriendRepository)(nil)
+	_ GuildStore       = (*GuildRepository)(nil)
+	_ CommunityStore   = (*CommunityRepository)(nil)
+	_ AchievementStore = (*AchievementRepository)(nil)
+	_ AccessoryStore   = (*AccessoryRepository)(nil)
+	_ ArenaStore       = (*ArenaRepository)(nil)
+	_ BreedingStore    = (*BreedingRepository)(nil)
+	_ GameLogStore     = (*GameLogRepository)(nil)
+	_ MailboxStore     = (*MailboxRepository)(nil)
+	_ ShortsStore      = (*ShortsRepository)(nil)
+	_ BotStore         = (*BotRepository)(nil)
+	_ CollectionStore  = (*CollectionRepository)(nil)
+	_ TrainingStore    = (*TrainingRepository)(nil)
+	_ RaceStore        = (*RaceRepository)(nil)
+	_ LeaderboardStore = (*LeaderboardRepository)(nil)
+	_ SupportStore     = (*SupportRepository)(nil)
+	_ IdleStore        = (*IdleRepository)(nil)
+	_ GiftStore        = (*GiftRepository)(nil)
+	_ GachaStore       = (*GachaRepository)(nil)
+	_ GuildBossStore   = (*GuildBossRepository)(nil)
+	_ WorldBossStore   = (*WorldBossRepository)(nil)
+)
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrTicketNotFound = errors.New("support ticket not found")
+
+// SupportTicket is a player's support ticket.
+type SupportTicket struct {
+	ID        int64
+	Subject   string
+	Category  string
+	Status    string
+	Priority  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SupportReply is a message on a support ticket, sent by the user or by staff.
+type SupportReply struct {
+	SenderType string
+	Message    string
+	CreatedAt  time.Time
+}
+
+type SupportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSupportRepository(pool *pgxpool.Pool) *SupportRepository {
+	return &SupportRepository{pool: pool}
+}
+
+// CreateTicket opens a ticket for userID with message as its first reply.
+func (r *SupportRepository) CreateTicket(ctx context.Context, userID, subject, category, message string) (int64, error) {
+	var ticketID int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO support_tickets (user_id, subject, category) VALUES ($1, $2, $3) RETURNING id`,
+			userID, subject, category,
+		).Scan(&ticketID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO support_replies (ticket_id, sender_type, sender_id, message) VALUES ($1, 'user', $2, $3)`,
+			ticketID, userID, message,
+		)
+		return err
+	})
+	return ticketID, err
+}
+
+// Tickets lists userID's tickets, most recently updated first.
+func (r *SupportRepository) Tickets(ctx context.Context, userID string, limit int) ([]SupportTicket, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, subject, category, status, priority, created_at, updated_at
+		 FROM support_tickets WHERE user_id = $1 ORDER BY updated_at DESC LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[SupportTicket])
+}
+
+// Owner returns the id of the user who opened a ticket.
+func (r *SupportRepository) Owner(ctx context.Context, ticketID string) (string, error) {
+	var ownerID string
+	err := r.pool.QueryRow(ctx, `SELECT user_id FROM support_tickets WHERE id = $1`, ticketID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTicketNotFound
+	}
+	return ownerID, err
+}
+
+// Replies lists a ticket's messages, oldest first.
+func (r *SupportRepository) Replies(ctx context.Context, ticketID string) ([]SupportReply, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT sender_type, message, created_at FROM support_replies WHERE ticket_id = $1 ORDER BY created_at ASC`,
+		ticketID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[SupportReply])
+}
+
+// Reply adds userID's message to a ticket and bumps its updated_at.
+func (r *SupportRepository) Reply(ctx context.Context, ticketID, userID, message string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO support_replies (ticket_id, sender_type, sender_id, message) VALUES ($1, 'user', $2, $3)`,
+			ticketID, userID, message,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE support_tickets SET updated_at = NOW() WHERE id = $1`, ticketID)
+		return err
+	})
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrTrainingSlotsFull    = errors.New("training slots full")
+	ErrTrainingSlotNotFound = errors.New("training slot not found")
+)
+
+// TrainingSlot is a slime training in one of a user's slots.
+type TrainingSlot struct {
+	ID          string
+	SlimeID     string
+	SlotNumber  int
+	StartedAt   time.Time
+	Mode        string
+	SpeciesID   int
+	Level       int
+	Exp         int
+	Element     string
+	Personality string
+	Name        *string
+	SpeciesName string
+	Grade       string
+}
+
+type TrainingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTrainingRepository(pool *pgxpool.Pool) *TrainingRepository {
+	return &TrainingRepository{pool: pool}
+}
+
+// Slots lists userID's training slots with their slimes, in slot order.
+func (r *TrainingRepository) Slots(ctx context.Context, userID string) ([]TrainingSlot, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT t.id::text, t.slime_id::text, t.slot_number, t.started_at, t.training_mode,
+		        s.species_id, s.level, s.exp, s.element, s.personality, s.name,
+		        sp.name as species_name, sp.grade
+		 FROM training_slots t
+		 JOIN slimes s ON s.id = t.slime_id
+		 JOIN slime_species sp ON sp.id = s.species_id
+		 WHERE t.user_id = $1
+		 ORDER BY t.slot_number`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (TrainingSlot, error) {
+		var t TrainingSlot
+		err := row.Scan(&t.ID, &t.SlimeID, &t.SlotNumber, &t.StartedAt, &t.Mode, &t.SpeciesID, &t.Level, &t.Exp,
+			&t.Element, &t.Personality, &t.Name, &t.SpeciesName, &t.Grade)
+		return t, err
+	})
+}
+
+// Start puts a slime in userID's next free slot and returns its number. It returns
+// ErrTrainingSlotsFull once maxSlots are taken.
+func (r *TrainingRepository) Start(ctx context.Context, userID, slimeID, mode string, maxSlots int) (int, error) {
+	var slot int
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// Lock the user so two starts can't take the same slot
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, userID); err != nil {
+			return err
+		}
+		var taken int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM training_slots WHERE user_id = $1`, userID).Scan(&taken); err != nil {
+			return err
+		}
+		if taken >= maxSlots {
+			return ErrTrainingSlotsFull
+		}
+		slot = taken + 1
+		_, err := tx.Exec(ctx,
+			`INSERT INTO training_slots (user_id, slime_id, slot_number, training_mode) VALUES ($1, $2, $3, $4)`,
+			userID, slimeID, slot, mode,
+		)
+		return err
+	})
+	return slot, err
+}
+
+// Collect empties one of userID's slots, renumbers the rest, and returns the slot
+// as it was. It returns ErrTrainingSlotNotFound if there is no such slot.
+func (r *TrainingRepository) Collect(ctx context.Context, userID, slotID string) (*TrainingSlot, error) {
+	t := TrainingSlot{ID: slotID}
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// Deleting first keeps the slot from being collected twice
+		err := tx.QueryRow(ctx,
+			`DELETE FROM training_slots WHERE id = $1 AND user_id = $2 RETURNING slime_id::text, started_at, training_mode`,
+			slotID, userID,
+		).Scan(&t.SlimeID, &t.StartedAt, &t.Mode)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTrainingSlotNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`WITH numbered AS (
+				SELECT id, ROW_NUMBER() OVER (ORDER BY slot_number) as new_slot
+				FROM training_slots WHERE user_id = $1
+			)
+			UPDATE training_slots t SET slot_number = n.new_slot
+			FROM numbered n WHERE t.id = n.id`,
+			userID,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
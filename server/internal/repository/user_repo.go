@@ -3,13 +3,20 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/slimetopia/server/internal/models"
 )
 
-var ErrUserNotFound = errors.New("user not found")
+var (
+	ErrUserNotFound          = errors.New("user not found")
+	ErrIdentityInUse         = errors.New("identity belongs to another account")
+	ErrProviderAlreadyLinked = errors.New("provider already linked")
+	ErrNotEnoughMaterials    = errors.New("not enough materials")
+)
 
 type UserRepository struct {
 	pool *pgxpool.Pool
@@ -19,15 +26,15 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 	return &UserRepository{pool: pool}
 }
 
-func (r *UserRepository) Pool() *pgxpool.Pool {
-	return r.pool
-}
-
+// FindByProviderID resolves a login identity to its account, whether it is the
+// account's primary identity or one linked later.
 func (r *UserRepository) FindByProviderID(ctx context.Context, provider, providerID string) (*models.User, error) {
 	user := &models.User{}
 	err := r.pool.QueryRow(ctx,
 		`SELECT id, nickname, provider, provider_id, gold, gems, stardust, level, COALESCE(profile_image_url, ''), created_at, updated_at
-		 FROM users WHERE provider = $1 AND provider_id = $2`,
+		 FROM users WHERE id = COALESCE(
+			(SELECT user_id FROM user_identities WHERE provider = $1 AND provider_id = $2),
+			(SELECT id FROM users WHERE provider = $1 AND provider_id = $2))`,
 		provider, providerID,
 	).Scan(
 		&user.ID, &user.Nickname, &user.Provider, &user.ProviderID,
@@ -46,13 +53,14 @@ func (r *UserRepository) FindByProviderID(ctx context.Context, provider, provide
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
 	user := &models.User{}
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, nickname, provider, provider_id, gold, gems, stardust, level, COALESCE(profile_image_url, ''), created_at, updated_at
+		`SELECT id, nickname, provider, provider_id, gold, gems, stardust, level, COALESCE(profile_image_url, ''),
+		        COALESCE(email, ''), email_verified_at IS NOT NULL, deletion_scheduled_at, created_at, updated_at
 		 FROM users WHERE id = $1`,
 		id,
 	).Scan(
 		&user.ID, &user.Nickname, &user.Provider, &user.ProviderID,
 		&user.Gold, &user.Gems, &user.Stardust, &user.Level, &user.ProfileImageURL,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.Email, &user.EmailVerified, &user.DeletionScheduledAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -65,16 +73,26 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.User,
 
 func (r *UserRepository) Create(ctx context.Context, nickname, provider, providerID string) (*models.User, error) {
 	user := &models.User{}
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO users (nickname, provider, provider_id)
-		 VALUES ($1, $2, $3)
-		 RETURNING id, nickname, provider, provider_id, gold, gems, stardust, level, created_at, updated_at`,
-		nickname, provider, providerID,
-	).Scan(
-		&user.ID, &user.Nickname, &user.Provider, &user.ProviderID,
-		&user.Gold, &user.Gems, &user.Stardust, &user.Level,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO users (nickname, provider, provider_id)
+			 VALUES ($1, $2, $3)
+			 RETURNING id, nickname, provider, provider_id, gold, gems, stardust, level, created_at, updated_at`,
+			nickname, provider, providerID,
+		).Scan(
+			&user.ID, &user.Nickname, &user.Provider, &user.ProviderID,
+			&user.Gold, &user.Gems, &user.Stardust, &user.Level,
+			&user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO user_identities (user_id, provider, provider_id) VALUES ($1, $2, $3)`,
+			user.ID, provider, providerID,
+		)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -89,17 +107,31 @@ func (r *UserRepository) UpdateNickname(ctx context.Context, id, nickname string
 	return err
 }
 
+// IDByNickname returns the id of the user named nickname.
+func (r *UserRepository) IDByNickname(ctx context.Context, nickname string) (string, error) {
+	var id string
+	err := r.pool.QueryRow(ctx, `SELECT id::text FROM users WHERE nickname = $1`, nickname).Scan(&id)
+	return id, err
+}
+
+// SetProfileImage sets the profile image URL of a user; "" clears it.
+func (r *UserRepository) SetProfileImage(ctx context.Context, id, imageURL string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE users SET profile_image_url = $1 WHERE id = $2`, imageURL, id)
+	return err
+}
+
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
 	var emailVal, pwVal *string
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, nickname, provider, provider_id, gold, gems, stardust, level, email, password_hash, created_at, updated_at
+		`SELECT id, nickname, provider, provider_id, gold, gems, stardust, level, email, password_hash,
+		        email_verified_at IS NOT NULL, created_at, updated_at
 		 FROM users WHERE email = $1`,
 		email,
 	).Scan(
 		&user.ID, &user.Nickname, &user.Provider, &user.ProviderID,
 		&user.Gold, &user.Gems, &user.Stardust, &user.Level,
-		&emailVal, &pwVal,
+		&emailVal, &pwVal, &user.EmailVerified,
 		&user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
@@ -119,20 +151,44 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models
 
 func (r *UserRepository) SetEmailPassword(ctx context.Context, userID, email, passwordHash string) error {
 	_, err := r.pool.Exec(ctx,
-		`UPDATE users SET email = $1, password_hash = $2, updated_at = NOW() WHERE id = $3`,
+		`UPDATE users SET email = $1, password_hash = $2, email_verified_at = NULL, updated_at = NOW() WHERE id = $3`,
 		email, passwordHash, userID,
 	)
 	return err
 }
 
+// MarkEmailVerified records that the user proved ownership of email. It fails with
+// ErrUserNotFound if the account's email has changed since the token was issued.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID, email string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		 WHERE id = $1 AND email = $2`,
+		userID, email,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
 var ErrInsufficientFunds = errors.New("insufficient funds")
 
-func (r *UserRepository) AddCurrency(ctx context.Context, userID string, gold int64, gems, stardust int) error {
-	_, err := r.pool.Exec(ctx,
-		`UPDATE users SET gold = gold + $1, gems = gems + $2, stardust = stardust + $3, updated_at = NOW() WHERE id = $4`,
-		gold, gems, stardust, userID,
-	)
-	return err
+// AddCurrency credits (or, with negative amounts, debits without a funds check) a wallet
+// and journals the movement in the same transaction.
+func (r *UserRepository) AddCurrency(ctx context.Context, userID string, gold int64, gems, stardust int, reason, refID string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return ApplyCurrencyTx(ctx, tx, userID, gold, gems, stardust, reason, refID, false)
+	})
+}
+
+// TransferCurrency moves gold/gems between two users atomically.
+func (r *UserRepository) TransferCurrency(ctx context.Context, fromID, toID string, gold int64, gems int, reason, refID string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return TransferCurrencyTx(ctx, tx, fromID, toID, gold, gems, reason, refID)
+	})
 }
 
 func (r *UserRepository) GetCommunityStats(ctx context.Context, userID string) (map[string]int, error) {
@@ -159,18 +215,16 @@ func (r *UserRepository) GetCommunityStats(ctx context.Context, userID string) (
 }
 
 func (r *UserRepository) UpdateNicknameWithCost(ctx context.Context, userID, nickname string, goldCost int64) error {
-	tag, err := r.pool.Exec(ctx,
-		`UPDATE users SET nickname = $1, gold = gold - $2, updated_at = NOW()
-		 WHERE id = $3 AND gold >= $2`,
-		nickname, goldCost, userID,
-	)
-	if err != nil {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if err := ApplyCurrencyTx(ctx, tx, userID, -goldCost, 0, 0, ReasonNicknameChange, nickname, true); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE users SET nickname = $1, updated_at = NOW() WHERE id = $2`,
+			nickname, userID,
+		)
 		return err
-	}
-	if tag.RowsAffected() == 0 {
-		return ErrInsufficientFunds
-	}
-	return nil
+	})
 }
 
 func (r *UserRepository) GetPasswordHash(ctx context.Context, userID string) (string, error) {
@@ -214,17 +268,265 @@ func (r *UserRepository) UpdateCapacity(ctx context.Context, userID string, newC
 	return err
 }
 
-func (r *UserRepository) SpendCurrency(ctx context.Context, userID string, gold int64, gems, stardust int) error {
-	tag, err := r.pool.Exec(ctx,
-		`UPDATE users SET gold = gold - $1, gems = gems - $2, stardust = stardust - $3, updated_at = NOW()
-		 WHERE id = $4 AND gold >= $1 AND gems >= $2 AND stardust >= $3`,
-		gold, gems, stardust, userID,
+// SpendCurrency debits a wallet only if every balance covers its cost, journaling the
+// debit in the same transaction. Returns ErrInsufficientFunds otherwise.
+func (r *UserRepository) SpendCurrency(ctx context.Context, userID string, gold int64, gems, stardust int, reason, refID string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return ApplyCurrencyTx(ctx, tx, userID, -gold, -gems, -stardust, reason, refID, true)
+	})
+}
+
+// GetActiveBan returns the user's ban if one is in force, or nil. Timed bans whose
+// ban_expires_at has passed are treated as lifted.
+func (r *UserRepository) GetActiveBan(ctx context.Context, userID string) (*models.BanInfo, error) {
+	var banned bool
+	var reason *string
+	var bannedAt, expiresAt *time.Time
+	err := r.pool.QueryRow(ctx,
+		`SELECT banned, banned_reason, banned_at, ban_expires_at FROM users WHERE id = $1`, userID,
+	).Scan(&banned, &reason, &bannedAt, &expiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !banned || (expiresAt != nil && expiresAt.Before(time.Now())) {
+		return nil, nil
+	}
+
+	ban := &models.BanInfo{BannedAt: bannedAt, ExpiresAt: expiresAt, Permanent: expiresAt == nil}
+	if reason != nil {
+		ban.Reason = *reason
+	}
+	return ban, nil
+}
+
+// ListIdentities returns every login identity linked to the user.
+func (r *UserRepository) ListIdentities(ctx context.Context, userID string) ([]models.Identity, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT provider, COALESCE(email, ''), linked_at FROM user_identities WHERE user_id = $1 ORDER BY linked_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := make([]models.Identity, 0)
+	for rows.Next() {
+		var i models.Identity
+		if err := rows.Scan(&i.Provider, &i.Email, &i.LinkedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+	return identities, rows.Err()
+}
+
+// LinkIdentity attaches a provider identity to an existing account, keeping its users.id.
+// A guest account is upgraded in place: the linked identity becomes its primary one.
+// Returns ErrIdentityInUse if another account owns the identity and
+// ErrProviderAlreadyLinked if the user already has a different identity for the provider.
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID, provider, providerID, email string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return linkIdentityTx(ctx, tx, userID, provider, providerID, email)
+	})
+}
+
+// LinkEmail attaches email/password credentials to an existing account.
+func (r *UserRepository) LinkEmail(ctx context.Context, userID, email, passwordHash string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var owner *string
+		err := tx.QueryRow(ctx, `SELECT id::TEXT FROM users WHERE email = $1`, email).Scan(&owner)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if owner != nil && *owner != userID {
+			return ErrIdentityInUse
+		}
+
+		if err := linkIdentityTx(ctx, tx, userID, "email", "email_"+email, email); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE users SET email = $1, password_hash = $2, email_verified_at = NULL, updated_at = NOW() WHERE id = $3`,
+			email, passwordHash, userID,
+		)
+		return err
+	})
+}
+
+func linkIdentityTx(ctx context.Context, tx pgx.Tx, userID, provider, providerID, email string) error {
+	var owner string
+	err := tx.QueryRow(ctx,
+		`SELECT user_id::TEXT FROM user_identities WHERE provider = $1 AND provider_id = $2
+		 UNION ALL
+		 SELECT id::TEXT FROM users WHERE provider = $1 AND provider_id = $2
+		 LIMIT 1`,
+		provider, providerID,
+	).Scan(&owner)
+	switch {
+	case err == nil && owner != userID:
+		return ErrIdentityInUse
+	case err == nil:
+		return nil // already linked to this account
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	var existing int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM user_identities WHERE user_id = $1 AND provider = $2`,
+		userID, provider,
+	).Scan(&existing); err != nil {
+		return err
+	}
+	if existing > 0 {
+		return ErrProviderAlreadyLinked
+	}
+
+	var emailVal *string
+	if email != "" {
+		emailVal = &email
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO user_identities (user_id, provider, provider_id, email) VALUES ($1, $2, $3, $4)`,
+		userID, provider, providerID, emailVal,
 	)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrIdentityInUse
+		}
 		return err
 	}
-	if tag.RowsAffected() == 0 {
-		return ErrInsufficientFunds
+
+	_, err = tx.Exec(ctx,
+		`UPDATE users SET provider = $2, provider_id = $3, updated_at = NOW() WHERE id = $1 AND provider = 'guest'`,
+		userID, provider, providerID,
+	)
+	return err
+}
+
+// ===== Inventory =====
+
+// AddMaterial adds quantity of a material to the user's inventory.
+func (r *UserRepository) AddMaterial(ctx context.Context, userID string, materialID, qty int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO user_materials (user_id, material_id, quantity)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, material_id) DO UPDATE SET quantity = user_materials.quantity + $3`,
+		userID, materialID, qty,
+	)
+	return err
+}
+
+// MaterialStack is a quantity of one material in a user's inventory.
+type MaterialStack struct {
+	MaterialID int
+	Quantity   int
+}
+
+// Materials lists the materials the user has, by material ID.
+func (r *UserRepository) Materials(ctx context.Context, userID string) ([]MaterialStack, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT material_id, quantity FROM user_materials
+		 WHERE user_id = $1 AND quantity > 0
+		 ORDER BY material_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (MaterialStack, error) {
+		var m MaterialStack
+		err := row.Scan(&m.MaterialID, &m.Quantity)
+		return m, err
+	})
+}
+
+// ConsumeMaterial takes one unit of a material from the user's inventory. Returns
+// false if they have none.
+func (r *UserRepository) ConsumeMaterial(ctx context.Context, userID string, materialID int) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE user_materials SET quantity = quantity - 1
+		 WHERE user_id = $1 AND material_id = $2 AND quantity > 0`,
+		userID, materialID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ConsumeMaterials takes every cost from the user's inventory, or nothing. It
+// returns ErrNotEnoughMaterials if they are short of any.
+func (r *UserRepository) ConsumeMaterials(ctx context.Context, userID string, costs []MaterialStack) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, m := range costs {
+			tag, err := tx.Exec(ctx,
+				`UPDATE user_materials SET quantity = quantity - $3
+				 WHERE user_id = $1 AND material_id = $2 AND quantity >= $3`,
+				userID, m.MaterialID, m.Quantity,
+			)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return ErrNotEnoughMaterials
+			}
+		}
+		return nil
+	})
+}
+
+// AddFood adds quantity of a shop food item to the user's food inventory.
+func (r *UserRepository) AddFood(ctx context.Context, userID string, itemID, qty int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO food_inventory (user_id, item_id, quantity) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, item_id) DO UPDATE SET quantity = food_inventory.quantity + $3`,
+		userID, itemID, qty,
+	)
+	return err
+}
+
+// FoodStack is a quantity of one shop food item in a user's food inventory.
+type FoodStack struct {
+	ItemID   int
+	Quantity int
+}
+
+// Foods lists the food items the user has.
+func (r *UserRepository) Foods(ctx context.Context, userID string) ([]FoodStack, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT item_id, quantity FROM food_inventory WHERE user_id = $1 AND quantity > 0`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[FoodStack])
+}
+
+// FoodQuantity returns how many of a food item the user has.
+func (r *UserRepository) FoodQuantity(ctx context.Context, userID string, itemID int) (int, error) {
+	var qty int
+	err := r.pool.QueryRow(ctx,
+		`SELECT quantity FROM food_inventory WHERE user_id = $1 AND item_id = $2`,
+		userID, itemID,
+	).Scan(&qty)
+	return qty, err
+}
+
+// ConsumeFood takes qty of a food item from the user's food inventory. Returns
+// false if they have fewer.
+func (r *UserRepository) ConsumeFood(ctx context.Context, userID string, itemID, qty int) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE food_inventory SET quantity = quantity - $3
+		 WHERE user_id = $1 AND item_id = $2 AND quantity >= $3`,
+		userID, itemID, qty,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
 }
@@ -100,6 +100,34 @@ func (r *VillageRepository) GetRandom(ctx context.Context, excludeUserID string,
 	return villages, nil
 }
 
+// GetByUsers returns the villages owned by the given users, most recently updated first.
+// Users who haven't opened their village yet have none.
+func (r *VillageRepository) GetByUsers(ctx context.Context, userIDs []string) ([]models.Village, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, user_id, name, grid_size, terrain, layout, visit_count, likes, created_at, updated_at
+		 FROM villages WHERE user_id = ANY($1::uuid[])
+		 ORDER BY updated_at DESC`,
+		userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var villages []models.Village
+	for rows.Next() {
+		var v models.Village
+		if err := rows.Scan(
+			&v.ID, &v.UserID, &v.Name, &v.GridSize, &v.Terrain,
+			&v.Layout, &v.VisitCount, &v.Likes, &v.CreatedAt, &v.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		villages = append(villages, v)
+	}
+	return villages, nil
+}
+
 func (r *VillageRepository) IncrementVisit(ctx context.Context, villageID string) error {
 	_, err := r.pool.Exec(ctx,
 		`UPDATE villages SET visit_count = visit_count + 1, updated_at = NOW() WHERE id = $1`,
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// World boss states. A boss starts active, becomes defeated or expired when it
+// ends, and settled once its rewards are out.
+const (
+	WorldBossActive   = "active"
+	WorldBossDefeated = "defeated"
+	WorldBossExpired  = "expired"
+	WorldBossSettled  = "settled"
+)
+
+// worldBossSpawnLockKey serializes spawning so two callers never start a cycle twice.
+const worldBossSpawnLockKey = 0x736c696d65626f73 // "slimebos"
+
+// WorldBoss is a server-wide raid boss.
+type WorldBoss struct {
+	ID         int
+	Stage      int
+	Name       string
+	Element    string
+	MaxHP      int64
+	CurrentHP  int64
+	RewardGold int64
+	RewardGems int
+	Status     string
+	ExpiresAt  time.Time
+}
+
+// WorldBossAttacker is one of a boss's top attackers.
+type WorldBossAttacker struct {
+	Nickname string `json:"nickname"`
+	Damage   int64  `json:"damage"`
+}
+
+// WorldBossRanking is one attacker's total damage against a boss.
+type WorldBossRanking struct {
+	UserID string
+	Damage int64
+}
+
+// WorldBossReward is the reward mail for one ranked attacker.
+type WorldBossReward struct {
+	UserID string
+	Rank   int
+	Damage int64
+	Tier   string
+	Gold   int64
+	Gems   int
+	Title  string
+	Body   string
+}
+
+// WorldBossSettlement is how an ended boss pays out. Next, when set, is spawned in
+// the same transaction and gets its ID.
+type WorldBossSettlement struct {
+	Defeated    bool
+	Rewards     []WorldBossReward
+	MailExpires time.Time
+	Next        *WorldBoss
+}
+
+type WorldBossRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWorldBossRepository(pool *pgxpool.Pool) *WorldBossRepository {
+	return &WorldBossRepository{pool: pool}
+}
+
+// Current returns the latest boss that has not run out of time.
+func (r *WorldBossRepository) Current(ctx context.Context) (*WorldBoss, error) {
+	b := &WorldBoss{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, name, element, max_hp, current_hp, reward_gold, reward_gems, expires_at, COALESCE(stage, 1), status
+		 FROM world_boss WHERE expires_at > NOW() ORDER BY id DESC LIMIT 1`,
+	).Scan(&b.ID, &b.Name, &b.Element, &b.MaxHP, &b.CurrentHP, &b.RewardGold, &b.RewardGems, &b.ExpiresAt, &b.Stage, &b.Status)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Attackable returns the latest boss that is active, alive and not expired.
+func (r *WorldBossRepository) Attackable(ctx context.Context) (*WorldBoss, error) {
+	b := &WorldBoss{}
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, name, element, max_hp, current_hp, reward_gold, reward_gems, expires_at, COALESCE(stage, 1), status
+		 FROM world_boss WHERE expires_at > NOW() AND current_hp > 0 AND status = $1 ORDER BY id DESC LIMIT 1`,
+		WorldBossActive,
+	).Scan(&b.ID, &b.Name, &b.Element, &b.MaxHP, &b.CurrentHP, &b.RewardGold, &b.RewardGems, &b.ExpiresAt, &b.Stage, &b.Status)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UserDamage sums the damage userID dealt to a boss.
+func (r *WorldBossRepository) UserDamage(ctx context.Context, bossID int, userID string) (int64, error) {
+	var total int64
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(damage), 0) FROM world_boss_attacks WHERE boss_id = $1 AND user_id = $2`,
+		bossID, userID,
+	).Scan(&total)
+	return total, err
+}
+
+// TopAttackers lists the limit highest damage dealers of a boss.
+func (r *WorldBossRepository) TopAttackers(ctx context.Context, bossID, limit int) ([]WorldBossAttacker, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT u.nickname, SUM(a.damage) as total_dmg
+		 FROM world_boss_attacks a
+		 JOIN users u ON u.id = a.user_id
+		 WHERE a.boss_id = $1
+		 GROUP BY u.nickname
+		 ORDER BY total_dmg DESC
+		 LIMIT $2`,
+		bossID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[WorldBossAttacker])
+}
+
+// UserRank returns userID's place by damage against a boss.
+func (r *WorldBossRepository) UserRank(ctx context.Context, bossID int, userID string) (int, error) {
+	var rank int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) + 1 FROM (
+			SELECT user_id, SUM(damage) as total_dmg FROM world_boss_attacks
+			WHERE boss_id = $1 GROUP BY user_id
+		) sub WHERE sub.total_dmg > (
+			SELECT COALESCE(SUM(damage), 0) FROM world_boss_attacks WHERE boss_id = $1 AND user_id = $2
+		)`,
+		bossID, userID,
+	).Scan(&rank)
+	return rank, err
+}
+
+// Attack records userID's attack with a party and takes the damage off the boss,
+// returning the HP left.
+func (r *WorldBossRepository) Attack(ctx context.Context, bossID int, userID string, slimeIDs []string, damage int) (int64, error) {
+	var newHP int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO world_boss_attacks (boss_id, user_id, slime_id, damage, slime_ids) VALUES ($1, $2, $3, $4, $5)`,
+			bossID, userID, slimeIDs[0], damage, strings.Join(slimeIDs, ","),
+		)
+		if err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx,
+			`UPDATE world_boss SET current_hp = GREATEST(0, current_hp - $1) WHERE id = $2 RETURNING current_hp`,
+			damage, bossID,
+		).Scan(&newHP)
+	})
+	return newHP, err
+}
+
+// MarkDefeated moves an active boss to defeated. Returns false if it was not
+// active, so only one attack lands the killing blow.
+func (r *WorldBossRepository) MarkDefeated(ctx context.Context, bossID int) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE world_boss SET status = $2, defeated_at = NOW() WHERE id = $1 AND status = $3`,
+		bossID, WorldBossDefeated, WorldBossActive,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Unsettled lists the bosses whose rewards are not out yet, oldest first.
+func (r *WorldBossRepository) Unsettled(ctx context.Context) ([]WorldBoss, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, status, current_hp, expires_at FROM world_boss WHERE status <> $1 ORDER BY id`, WorldBossSettled)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (WorldBoss, error) {
+		var b WorldBoss
+		err := row.Scan(&b.ID, &b.Status, &b.CurrentHP, &b.ExpiresAt)
+		return b, err
+	})
+}
+
+// EnsureSpawned stores the boss spawn returns when no boss is up, and returns it.
+// It returns nil when a boss already is.
+func (r *WorldBossRepository) EnsureSpawned(ctx context.Context, spawn func() WorldBoss) (*WorldBoss, error) {
+	var boss *WorldBoss
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(worldBossSpawnLockKey)); err != nil {
+			return err
+		}
+		var up bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM world_boss WHERE expires_at > NOW())`).Scan(&up); err != nil {
+			return err
+		}
+		if up {
+			return nil
+		}
+		b := spawn()
+		if err := insertWorldBossTx(ctx, tx, &b); err != nil {
+			return err
+		}
+		boss = &b
+		return nil
+	})
+	return boss, err
+}
+
+func insertWorldBossTx(ctx context.Context, tx pgx.Tx, b *WorldBoss) error {
+	b.CurrentHP = b.MaxHP
+	b.Status = WorldBossActive
+	return tx.QueryRow(ctx,
+		`INSERT INTO world_boss (name, element, max_hp, current_hp, reward_gold, reward_gems, expires_at, stage)
+		 VALUES ($1, $2, $3, $3, $4, $5, $6, $7) RETURNING id`,
+		b.Name, b.Element, b.MaxHP, b.RewardGold, b.RewardGems, b.ExpiresAt, b.Stage,
+	).Scan(&b.ID)
+}
+
+// Settle pays out a boss in one transaction with its row locked. plan sees the boss
+// and its attackers, best first, and returns nil to leave the boss alone; Settle
+// then returns nil too. Equal damage ranks whoever started attacking earlier higher.
+// A user already rewarded for the boss gets no second reward or mail.
+func (r *WorldBossRepository) Settle(ctx context.Context, bossID int, plan func(b *WorldBoss, rankings []WorldBossRanking) *WorldBossSettlement) (*WorldBossSettlement, error) {
+	var s *WorldBossSettlement
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		b := &WorldBoss{ID: bossID}
+		err := tx.QueryRow(ctx,
+			`SELECT name, status, COALESCE(stage, 1), current_hp, reward_gold, reward_gems, expires_at
+			 FROM world_boss WHERE id = $1 FOR UPDATE`, bossID,
+		).Scan(&b.Name, &b.Status, &b.Stage, &b.CurrentHP, &b.RewardGold, &b.RewardGems, &b.ExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		rankings, err := worldBossRankingsTx(ctx, tx, bossID)
+		if err != nil {
+			return fmt.Errorf("rank attackers: %w", err)
+		}
+		if s = plan(b, rankings); s == nil {
+			return nil
+		}
+
+		batch := &pgx.Batch{}
+		for _, rw := range s.Rewards {
+			// The reward row and its mail go in together
+			batch.Queue(
+				`WITH reward AS (
+					INSERT INTO world_boss_rewards (boss_id, user_id, rank, damage, tier, reward_gold, reward_gems)
+					VALUES ($1, $2, $3, $4, $5, $6, $7)
+					ON CONFLICT DO NOTHING
+					RETURNING user_id
+				)
+				INSERT INTO mailbox (user_id, title, body, mail_type, reward_gold, reward_gems, expires_at)
+				SELECT user_id, $8, $9, 'reward', $6, $7, $10 FROM reward`,
+				bossID, rw.UserID, rw.Rank, rw.Damage, rw.Tier, rw.Gold, rw.Gems, rw.Title, rw.Body, s.MailExpires,
+			)
+		}
+		if batch.Len() > 0 {
+			if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+				return fmt.Errorf("send rewards: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(ctx,
+			`UPDATE world_boss
+			 SET status = $2, defeated_at = CASE WHEN $3 THEN COALESCE(defeated_at, NOW()) END, settled_at = NOW()
+			 WHERE id = $1`,
+			bossID, WorldBossSettled, s.Defeated,
+		)
+		if err != nil {
+			return err
+		}
+
+		if s.Next != nil {
+			if err := insertWorldBossTx(ctx, tx, s.Next); err != nil {
+				return fmt.Errorf("spawn stage %d: %w", s.Next.Stage, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func worldBossRankingsTx(ctx context.Context, tx pgx.Tx, bossID int) ([]WorldBossRanking, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT user_id::text, SUM(damage)::bigint AS total
+		 FROM world_boss_attacks WHERE boss_id = $1
+		 GROUP BY user_id
+		 HAVING SUM(damage) > 0
+		 ORDER BY total DESC, MIN(created_at)`, bossID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[WorldBossRanking])
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_race_results_verified_score;
+ALTER TABLE race_results DROP COLUMN IF EXISTS verified;
+ALTER TABLE race_results DROP COLUMN IF EXISTS distance;
+ALTER TABLE race_results DROP COLUMN IF EXISTS inputs;
+ALTER TABLE race_results DROP COLUMN IF EXISTS seed;
@@ -0,0 +1,8 @@
+-- Server-authoritative races: store the seed and input log so every result can be replayed
+ALTER TABLE race_results ADD COLUMN IF NOT EXISTS seed BIGINT;
+ALTER TABLE race_results ADD COLUMN IF NOT EXISTS inputs JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE race_results ADD COLUMN IF NOT EXISTS distance INT NOT NULL DEFAULT 0;
+ALTER TABLE race_results ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Leaderboard only ranks server-simulated results
+CREATE INDEX IF NOT EXISTS idx_race_results_verified_score ON race_results(score DESC) WHERE verified;
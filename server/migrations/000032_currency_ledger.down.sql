@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ledger_discrepancies;
+DROP TABLE IF EXISTS currency_ledger;
@@ -0,0 +1,46 @@
+-- currency_ledger: double-entry journal for every gold / gems / stardust movement.
+-- Each movement is one txn_id with a 'user' leg (the wallet) and a balancing leg
+-- ('system' for faucets/sinks, or another user's wallet for transfers).
+-- SUM(amount) per (txn_id, currency) is always 0.
+CREATE TABLE IF NOT EXISTS currency_ledger (
+    id            BIGSERIAL PRIMARY KEY,
+    txn_id        UUID NOT NULL,
+    account       VARCHAR(10) NOT NULL CHECK (account IN ('user', 'system')),
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency      VARCHAR(10) NOT NULL CHECK (currency IN ('gold', 'gems', 'stardust')),
+    amount        BIGINT NOT NULL,
+    balance_after BIGINT,
+    reason        VARCHAR(40) NOT NULL,
+    ref_id        VARCHAR(100) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_currency_ledger_user ON currency_ledger (user_id, currency) WHERE account = 'user';
+CREATE INDEX IF NOT EXISTS idx_currency_ledger_txn ON currency_ledger (txn_id);
+CREATE INDEX IF NOT EXISTS idx_currency_ledger_reason ON currency_ledger (reason, created_at DESC);
+
+-- Opening balances so existing wallets reconcile against the ledger from day one
+WITH opening AS MATERIALIZED (
+    SELECT uuid_generate_v4() AS txn_id, id AS user_id, 'gold' AS currency, gold::BIGINT AS amount FROM users WHERE gold <> 0
+    UNION ALL
+    SELECT uuid_generate_v4(), id, 'gems', gems::BIGINT FROM users WHERE gems <> 0
+    UNION ALL
+    SELECT uuid_generate_v4(), id, 'stardust', stardust::BIGINT FROM users WHERE stardust <> 0
+)
+INSERT INTO currency_ledger (txn_id, account, user_id, currency, amount, balance_after, reason)
+SELECT txn_id, 'user', user_id, currency, amount, amount, 'opening_balance' FROM opening
+UNION ALL
+SELECT txn_id, 'system', user_id, currency, -amount, NULL, 'opening_balance' FROM opening;
+
+-- ledger_discrepancies: wallets whose balance drifted from their ledger sum
+CREATE TABLE IF NOT EXISTS ledger_discrepancies (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency    VARCHAR(10) NOT NULL,
+    balance     BIGINT NOT NULL,
+    ledger_sum  BIGINT NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_discrepancies_open ON ledger_discrepancies (user_id, currency) WHERE resolved_at IS NULL;
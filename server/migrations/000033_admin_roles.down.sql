@@ -0,0 +1,2 @@
+ALTER TABLE admin_users DROP CONSTRAINT IF EXISTS admin_users_role_check;
+ALTER TABLE admin_users ALTER COLUMN role SET DEFAULT 'admin';
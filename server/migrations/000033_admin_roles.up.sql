@@ -0,0 +1,7 @@
+-- Admin role model: superadmin / operator / moderator / cs.
+-- Accounts on the legacy catch-all 'admin' role become operators.
+UPDATE admin_users SET role = 'operator' WHERE role NOT IN ('superadmin', 'operator', 'moderator', 'cs');
+
+ALTER TABLE admin_users ALTER COLUMN role SET DEFAULT 'operator';
+ALTER TABLE admin_users ADD CONSTRAINT admin_users_role_check
+  CHECK (role IN ('superadmin', 'operator', 'moderator', 'cs'));
@@ -0,0 +1,20 @@
+-- Server-side refresh token sessions. Each login creates one session (a token family);
+-- every refresh rotates current_jti. Presenting any older jti revokes the session.
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id             UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    current_jti    UUID NOT NULL,
+    previous_jti   UUID,
+    rotated_at     TIMESTAMPTZ,
+    device_label   VARCHAR(100) NOT NULL DEFAULT '',
+    user_agent     TEXT NOT NULL DEFAULT '',
+    ip             VARCHAR(64) NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ NOT NULL,
+    revoked_at     TIMESTAMPTZ,
+    revoked_reason VARCHAR(30)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, last_used_at DESC)
+    WHERE revoked_at IS NULL;
@@ -0,0 +1,19 @@
+-- Login identities linked to an account. users.provider/provider_id keep the primary
+-- identity; this table lets one account sign in through several providers.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          SERIAL PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider    VARCHAR(20) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    email       VARCHAR(255),
+    linked_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, provider_id),
+    UNIQUE (user_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities(user_id);
+
+INSERT INTO user_identities (user_id, provider, provider_id, email, linked_at)
+SELECT id, provider, provider_id, CASE WHEN provider = 'email' THEN email END, created_at
+FROM users
+ON CONFLICT DO NOTHING;
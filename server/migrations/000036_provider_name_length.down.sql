@@ -0,0 +1 @@
+ALTER TABLE users ALTER COLUMN provider TYPE VARCHAR(10);
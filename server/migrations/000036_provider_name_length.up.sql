@@ -0,0 +1,3 @@
+-- OIDC providers are named in config (apple, naver, line, ...); give them the same room
+-- user_identities has.
+ALTER TABLE users ALTER COLUMN provider TYPE VARCHAR(20);
@@ -0,0 +1,27 @@
+-- Anonymized rows can't be restored to NOT NULL; drop them first
+DELETE FROM shorts_tips WHERE sender_id IS NULL OR receiver_id IS NULL;
+ALTER TABLE shorts_tips DROP CONSTRAINT IF EXISTS shorts_tips_sender_id_fkey;
+ALTER TABLE shorts_tips DROP CONSTRAINT IF EXISTS shorts_tips_receiver_id_fkey;
+ALTER TABLE shorts_tips ADD CONSTRAINT shorts_tips_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users(id);
+ALTER TABLE shorts_tips ADD CONSTRAINT shorts_tips_receiver_id_fkey FOREIGN KEY (receiver_id) REFERENCES users(id);
+ALTER TABLE shorts_tips ALTER COLUMN sender_id SET NOT NULL;
+ALTER TABLE shorts_tips ALTER COLUMN receiver_id SET NOT NULL;
+
+DELETE FROM gift_logs WHERE sender_id IS NULL OR receiver_id IS NULL;
+ALTER TABLE gift_logs DROP CONSTRAINT IF EXISTS gift_logs_sender_id_fkey;
+ALTER TABLE gift_logs DROP CONSTRAINT IF EXISTS gift_logs_receiver_id_fkey;
+ALTER TABLE gift_logs ADD CONSTRAINT gift_logs_sender_id_fkey FOREIGN KEY (sender_id) REFERENCES users(id);
+ALTER TABLE gift_logs ADD CONSTRAINT gift_logs_receiver_id_fkey FOREIGN KEY (receiver_id) REFERENCES users(id);
+ALTER TABLE gift_logs ALTER COLUMN sender_id SET NOT NULL;
+ALTER TABLE gift_logs ALTER COLUMN receiver_id SET NOT NULL;
+
+DELETE FROM first_discoveries WHERE user_id IS NULL;
+ALTER TABLE first_discoveries DROP CONSTRAINT IF EXISTS first_discoveries_user_id_fkey;
+ALTER TABLE first_discoveries ADD CONSTRAINT first_discoveries_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+ALTER TABLE first_discoveries ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE idle_progress DROP CONSTRAINT IF EXISTS idle_progress_user_id_fkey;
+ALTER TABLE idle_progress ADD CONSTRAINT idle_progress_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+DROP INDEX IF EXISTS idx_users_deletion_scheduled;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,34 @@
+-- Account deletion: users.deletion_scheduled_at marks an account for purge once its
+-- grace period ends. Cancelling clears it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled ON users (deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Rows that belong to the user alone go with the account
+ALTER TABLE idle_progress DROP CONSTRAINT IF EXISTS idle_progress_user_id_fkey;
+ALTER TABLE idle_progress ADD CONSTRAINT idle_progress_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Rows that other players still see are kept and anonymized
+ALTER TABLE first_discoveries ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE first_discoveries DROP CONSTRAINT IF EXISTS first_discoveries_user_id_fkey;
+ALTER TABLE first_discoveries ADD CONSTRAINT first_discoveries_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE gift_logs ALTER COLUMN sender_id DROP NOT NULL;
+ALTER TABLE gift_logs ALTER COLUMN receiver_id DROP NOT NULL;
+ALTER TABLE gift_logs DROP CONSTRAINT IF EXISTS gift_logs_sender_id_fkey;
+ALTER TABLE gift_logs DROP CONSTRAINT IF EXISTS gift_logs_receiver_id_fkey;
+ALTER TABLE gift_logs ADD CONSTRAINT gift_logs_sender_id_fkey
+    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE gift_logs ADD CONSTRAINT gift_logs_receiver_id_fkey
+    FOREIGN KEY (receiver_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE shorts_tips ALTER COLUMN sender_id DROP NOT NULL;
+ALTER TABLE shorts_tips ALTER COLUMN receiver_id DROP NOT NULL;
+ALTER TABLE shorts_tips DROP CONSTRAINT IF EXISTS shorts_tips_sender_id_fkey;
+ALTER TABLE shorts_tips DROP CONSTRAINT IF EXISTS shorts_tips_receiver_id_fkey;
+ALTER TABLE shorts_tips ADD CONSTRAINT shorts_tips_sender_id_fkey
+    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE shorts_tips ADD CONSTRAINT shorts_tips_receiver_id_fkey
+    FOREIGN KEY (receiver_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS friendships;
+DROP TABLE IF EXISTS friend_requests;
+DROP INDEX IF EXISTS idx_users_friend_code;
+ALTER TABLE users DROP COLUMN IF EXISTS friend_code;
@@ -0,0 +1,29 @@
+-- Friend codes: short shareable handle so players don't have to type nicknames
+ALTER TABLE users ADD COLUMN IF NOT EXISTS friend_code VARCHAR(8);
+UPDATE users SET friend_code = upper(substr(md5(random()::text || id::text), 1, 8)) WHERE friend_code IS NULL;
+ALTER TABLE users ALTER COLUMN friend_code SET DEFAULT upper(substr(md5(random()::text || clock_timestamp()::text), 1, 8));
+ALTER TABLE users ALTER COLUMN friend_code SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_friend_code ON users (friend_code);
+
+-- friend_requests: pending until the receiver accepts or declines
+CREATE TABLE IF NOT EXISTS friend_requests (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sender_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    receiver_id  UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status       VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ,
+    CHECK (sender_id <> receiver_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_friend_requests_pending ON friend_requests (sender_id, receiver_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_friend_requests_receiver ON friend_requests (receiver_id, created_at DESC) WHERE status = 'pending';
+
+-- friendships: one row per direction so "my friends" is a single index scan
+CREATE TABLE IF NOT EXISTS friendships (
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    friend_id  UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, friend_id),
+    CHECK (user_id <> friend_id)
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS guild_boss_attacks;
+DROP TABLE IF EXISTS guild_boss;
+DROP TABLE IF EXISTS guild_messages;
+DROP TABLE IF EXISTS guild_requests;
+DROP TABLE IF EXISTS guild_members;
+DROP TABLE IF EXISTS guilds;
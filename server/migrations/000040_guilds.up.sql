@@ -0,0 +1,78 @@
+-- guilds: player-run groups with a shared level and boss
+CREATE TABLE IF NOT EXISTS guilds (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(20) NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    join_policy VARCHAR(10) NOT NULL DEFAULT 'apply' CHECK (join_policy IN ('open', 'apply', 'invite')),
+    level       INT NOT NULL DEFAULT 1,
+    xp          BIGINT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guilds_name ON guilds (lower(name));
+
+-- guild_members: a player belongs to at most one guild
+CREATE TABLE IF NOT EXISTS guild_members (
+    user_id        UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    guild_id       UUID NOT NULL REFERENCES guilds(id) ON DELETE CASCADE,
+    role           VARCHAR(10) NOT NULL DEFAULT 'member' CHECK (role IN ('leader', 'officer', 'member')),
+    contributed_xp BIGINT NOT NULL DEFAULT 0,
+    joined_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_members_guild ON guild_members (guild_id, role);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guild_members_leader ON guild_members (guild_id) WHERE role = 'leader';
+
+-- guild_requests: officer invites (kind=invite) and player applications (kind=apply)
+CREATE TABLE IF NOT EXISTS guild_requests (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    guild_id     UUID NOT NULL REFERENCES guilds(id) ON DELETE CASCADE,
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind         VARCHAR(10) NOT NULL CHECK (kind IN ('invite', 'apply')),
+    inviter_id   UUID REFERENCES users(id) ON DELETE SET NULL,
+    status       VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guild_requests_pending ON guild_requests (guild_id, user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_guild_requests_user ON guild_requests (user_id) WHERE status = 'pending';
+
+-- guild_messages: guild chat, polled by id
+CREATE TABLE IF NOT EXISTS guild_messages (
+    id         BIGSERIAL PRIMARY KEY,
+    guild_id   UUID NOT NULL REFERENCES guilds(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message    VARCHAR(300) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_messages_guild ON guild_messages (guild_id, id DESC);
+
+-- guild_boss: one active boss per guild, advancing through the world boss stages
+CREATE TABLE IF NOT EXISTS guild_boss (
+    id          SERIAL PRIMARY KEY,
+    guild_id    UUID NOT NULL REFERENCES guilds(id) ON DELETE CASCADE,
+    stage       INT NOT NULL DEFAULT 1,
+    name        VARCHAR(50) NOT NULL,
+    element     VARCHAR(20) NOT NULL,
+    max_hp      BIGINT NOT NULL,
+    current_hp  BIGINT NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    defeated_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_boss_guild ON guild_boss (guild_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS guild_boss_attacks (
+    id         BIGSERIAL PRIMARY KEY,
+    boss_id    INT NOT NULL REFERENCES guild_boss(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    damage     BIGINT NOT NULL,
+    slime_ids  TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_boss_attacks_boss ON guild_boss_attacks (boss_id, user_id);
@@ -0,0 +1,2 @@
+UPDATE game_shop_items SET egg_type = '' WHERE type = 'egg';
+DROP TABLE IF EXISTS game_gacha_banners;
@@ -0,0 +1,59 @@
+-- Gacha banners: grade odds, species pool, rate-up and pity per egg type.
+-- id doubles as the egg_type used by game_shop_items and gacha_pity.
+CREATE TABLE IF NOT EXISTS game_gacha_banners (
+    id                 VARCHAR(30) PRIMARY KEY,
+    name               VARCHAR(100) NOT NULL,
+    name_en            VARCHAR(100) DEFAULT '',
+    description        TEXT DEFAULT '',
+    grade_weights      JSONB NOT NULL DEFAULT '{}',  -- {"common":45,"rare":15,...}; relative weights
+    luck_grade_weights JSONB NOT NULL DEFAULT '{}',  -- used while the luck booster is active; empty = generic boost
+    elements           TEXT[] DEFAULT '{}',          -- element filter; empty = all elements
+    species_pool       INT[] DEFAULT '{}',           -- explicit species; overrides elements when set
+    rate_up_species    INT[] DEFAULT '{}',
+    rate_up_share      REAL DEFAULT 0.5,             -- chance a pull lands on a rate-up species of the rolled grade
+    starts_at          TIMESTAMPTZ,
+    ends_at            TIMESTAMPTZ,
+    pity_rare          INT DEFAULT 0,                -- 0 disables that pity step
+    pity_epic          INT DEFAULT 0,
+    pity_legendary     INT DEFAULT 0,
+    is_active          BOOLEAN DEFAULT TRUE,
+    sort_order         INT DEFAULT 0,
+    updated_at         TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Same odds and pity the egg switch in shop_handler.go used to hardcode
+INSERT INTO game_gacha_banners (id, name, name_en, description, grade_weights, luck_grade_weights, elements, pity_rare, pity_epic, pity_legendary, sort_order) VALUES
+('normal',        '슬라임 알',   'Slime Egg',     '랜덤 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}',
+  '{"common":25,"uncommon":30,"rare":25,"epic":12,"legendary":6,"mythic":2}', '{}', 15, 50, 120, 1),
+('premium',       '프리미엄 알', 'Premium Egg',   '레어 이상 확정',
+  '{"rare":60,"epic":30,"legendary":8,"mythic":2}', '{}', '{}', 15, 50, 120, 2),
+('fire_egg',      '불꽃 알',     'Fire Egg',      '불 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{fire}', 15, 50, 120, 3),
+('water_egg',     '물방울 알',   'Water Egg',     '물 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{water}', 15, 50, 120, 4),
+('grass_egg',     '풀잎 알',     'Grass Egg',     '풀 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{grass}', 15, 50, 120, 5),
+('legendary_egg', '전설의 알',   'Legendary Egg', '에픽 이상 확정!',
+  '{"epic":50,"legendary":35,"mythic":15}', '{}', '{}', 15, 50, 120, 6),
+('dark_egg',      '어둠 알',     'Dark Egg',      '어둠 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{dark}', 15, 50, 120, 7),
+('ice_egg',       '얼음 알',     'Ice Egg',       '얼음 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{ice}', 15, 50, 120, 8),
+('electric_egg',  '번개 알',     'Electric Egg',  '전기 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{electric}', 15, 50, 120, 9),
+('earth_egg',     '대지 알',     'Earth Egg',     '대지 원소 슬라임이 부화합니다',
+  '{"common":45,"uncommon":30,"rare":15,"epic":7,"legendary":2.5,"mythic":0.5}', '{}', '{earth}', 15, 50, 120, 10)
+ON CONFLICT (id) DO NOTHING;
+
+-- Single-egg shop items now name their banner instead of relying on a hardcoded item id switch
+UPDATE game_shop_items SET egg_type = 'normal'        WHERE id = 1  AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'premium'       WHERE id = 2  AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'fire_egg'      WHERE id = 5  AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'legendary_egg' WHERE id = 6  AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'water_egg'     WHERE id = 9  AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'grass_egg'     WHERE id = 10 AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'dark_egg'      WHERE id = 20 AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'ice_egg'       WHERE id = 21 AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'electric_egg'  WHERE id = 22 AND egg_type = '';
+UPDATE game_shop_items SET egg_type = 'earth_egg'     WHERE id = 23 AND egg_type = '';
@@ -0,0 +1,13 @@
+-- Run history of the background job scheduler (internal/jobs).
+-- A row is written when a run starts and completed when it ends; rows left in
+-- 'running' belong to a process that died mid-run.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id          BIGSERIAL PRIMARY KEY,
+    job         VARCHAR(64) NOT NULL,
+    instance    VARCHAR(128) NOT NULL,            -- scheduler that held leadership
+    status      VARCHAR(10) NOT NULL DEFAULT 'running',  -- running, ok, failed
+    error       TEXT DEFAULT '',
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job, started_at DESC);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS world_boss_rewards;
+DROP INDEX IF EXISTS idx_world_boss_unsettled;
+ALTER TABLE world_boss DROP COLUMN IF EXISTS settled_at;
+ALTER TABLE world_boss DROP COLUMN IF EXISTS defeated_at;
+ALTER TABLE world_boss DROP COLUMN IF EXISTS status;
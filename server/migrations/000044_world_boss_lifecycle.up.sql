@@ -0,0 +1,23 @@
+-- World boss lifecycle: active -> defeated | expired -> settled
+ALTER TABLE world_boss ADD COLUMN IF NOT EXISTS status VARCHAR(10) NOT NULL DEFAULT 'active';
+ALTER TABLE world_boss ADD COLUMN IF NOT EXISTS defeated_at TIMESTAMPTZ;
+ALTER TABLE world_boss ADD COLUMN IF NOT EXISTS settled_at TIMESTAMPTZ;
+
+-- Bosses that ended before the lifecycle existed were already paid out inline
+UPDATE world_boss SET status = 'settled', settled_at = NOW()
+WHERE current_hp <= 0 OR expires_at <= NOW();
+
+CREATE INDEX IF NOT EXISTS idx_world_boss_unsettled ON world_boss(id) WHERE status <> 'settled';
+
+-- One ranking reward per user per boss; the primary key keeps settlement idempotent
+CREATE TABLE IF NOT EXISTS world_boss_rewards (
+    boss_id INT NOT NULL REFERENCES world_boss(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INT NOT NULL,
+    damage BIGINT NOT NULL,
+    tier VARCHAR(20) NOT NULL,
+    reward_gold BIGINT NOT NULL DEFAULT 0,
+    reward_gems INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (boss_id, user_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE game_accessories DROP COLUMN IF EXISTS effect;
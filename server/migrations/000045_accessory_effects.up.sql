@@ -0,0 +1,11 @@
+-- Combat bonuses for accessories, in the same shape as game_skills.effect:
+-- attack / boss_damage (+0.05 = 5% more damage), crit_chance, all_stats (talent points)
+ALTER TABLE game_accessories ADD COLUMN IF NOT EXISTS effect JSONB NOT NULL DEFAULT '{}';
+
+-- Gem accessories get a small edge; the gold ones stay cosmetic
+UPDATE game_accessories SET effect = '{"attack": 0.05}' WHERE id = 2;        -- Crown
+UPDATE game_accessories SET effect = '{"all_stats": 1}' WHERE id = 5;        -- Santa Hat
+UPDATE game_accessories SET effect = '{"boss_damage": 0.05}' WHERE id = 12;  -- Cape
+UPDATE game_accessories SET effect = '{"crit_chance": 0.05}' WHERE id = 13;  -- Angel Wings
+UPDATE game_accessories SET effect = '{"attack": 0.03}' WHERE id = 14;       -- Devil Horns
+UPDATE game_accessories SET effect = '{"attack": 0.03, "crit_chance": 0.03}' WHERE id = 15; -- Rainbow Halo
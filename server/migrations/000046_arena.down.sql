@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS arena_rewards;
+DROP TABLE IF EXISTS arena_battles;
+DROP TABLE IF EXISTS arena_ratings;
+DROP TABLE IF EXISTS arena_defense;
+DROP TABLE IF EXISTS arena_seasons;
@@ -0,0 +1,57 @@
+-- Asynchronous PvP arena: defense teams, per-season Elo ratings and battle replays
+CREATE TABLE IF NOT EXISTS arena_seasons (
+    id SERIAL PRIMARY KEY,
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMPTZ NOT NULL,
+    settled_at TIMESTAMPTZ
+);
+
+-- The team other players fight against; slimes are looked up again at battle time
+CREATE TABLE IF NOT EXISTS arena_defense (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    slime_ids UUID[] NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS arena_ratings (
+    season_id INT NOT NULL REFERENCES arena_seasons(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating INT NOT NULL DEFAULT 1000,
+    wins INT NOT NULL DEFAULT 0,
+    losses INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (season_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_arena_ratings_rank ON arena_ratings(season_id, rating DESC);
+
+CREATE TABLE IF NOT EXISTS arena_battles (
+    id BIGSERIAL PRIMARY KEY,
+    season_id INT NOT NULL REFERENCES arena_seasons(id) ON DELETE CASCADE,
+    attacker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    defender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seed BIGINT NOT NULL,
+    attacker_won BOOLEAN NOT NULL,
+    attacker_rating INT NOT NULL,
+    defender_rating INT NOT NULL,
+    attacker_delta INT NOT NULL,
+    defender_delta INT NOT NULL,
+    replay JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_arena_battles_attacker ON arena_battles(attacker_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_arena_battles_defender ON arena_battles(defender_id, created_at DESC);
+
+-- One season reward per user; the primary key keeps settlement idempotent
+CREATE TABLE IF NOT EXISTS arena_rewards (
+    season_id INT NOT NULL REFERENCES arena_seasons(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INT NOT NULL,
+    rating INT NOT NULL,
+    tier VARCHAR(20) NOT NULL,
+    reward_gold BIGINT NOT NULL DEFAULT 0,
+    reward_gems INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (season_id, user_id)
+);
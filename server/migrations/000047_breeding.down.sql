@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS slime_lineage;
+DROP TABLE IF EXISTS slime_eggs;
@@ -0,0 +1,43 @@
+-- Breeding: two parents lay an egg and both survive
+
+-- Bred eggs wait here until they hatch. Parents are kept without foreign keys so
+-- cooldowns survive a parent being merged or released.
+CREATE TABLE IF NOT EXISTS slime_eggs (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_a UUID NOT NULL,
+    parent_b UUID NOT NULL,
+    parent_a_species INT NOT NULL,
+    parent_b_species INT NOT NULL,
+    species_id INT NOT NULL REFERENCES slime_species(id),
+    element VARCHAR(20) NOT NULL,
+    personality VARCHAR(20) NOT NULL,
+    talents INT[] NOT NULL,
+    genes JSONB NOT NULL,
+    generation INT NOT NULL,
+    hatches_at TIMESTAMPTZ NOT NULL,
+    hatched_slime_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_slime_eggs_unhatched ON slime_eggs(user_id, hatches_at) WHERE hatched_slime_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_slime_eggs_parent_a ON slime_eggs(parent_a, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_slime_eggs_parent_b ON slime_eggs(parent_b, created_at DESC);
+
+-- One row per bred slime. Rows outlive the slime so family trees keep ancestors that
+-- were later merged or released; slimes without a row are founders (generation 0).
+CREATE TABLE IF NOT EXISTS slime_lineage (
+    slime_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_a UUID NOT NULL,
+    parent_b UUID NOT NULL,
+    parent_a_species INT NOT NULL,
+    parent_b_species INT NOT NULL,
+    species_id INT NOT NULL,
+    generation INT NOT NULL,
+    genes JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_slime_lineage_parent_a ON slime_lineage(parent_a);
+CREATE INDEX IF NOT EXISTS idx_slime_lineage_parent_b ON slime_lineage(parent_b);
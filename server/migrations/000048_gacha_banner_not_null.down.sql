@@ -0,0 +1,9 @@
+ALTER TABLE game_gacha_banners
+    ALTER COLUMN name_en        DROP NOT NULL,
+    ALTER COLUMN description    DROP NOT NULL,
+    ALTER COLUMN rate_up_share  DROP NOT NULL,
+    ALTER COLUMN pity_rare      DROP NOT NULL,
+    ALTER COLUMN pity_epic      DROP NOT NULL,
+    ALTER COLUMN pity_legendary DROP NOT NULL,
+    ALTER COLUMN is_active      DROP NOT NULL,
+    ALTER COLUMN sort_order     DROP NOT NULL;
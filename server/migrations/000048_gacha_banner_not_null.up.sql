@@ -0,0 +1,21 @@
+-- The banner columns the repository scans into plain Go values can't be NULL;
+-- a row inserted without them made every banner read fail.
+UPDATE game_gacha_banners SET
+    name_en        = COALESCE(name_en, ''),
+    description    = COALESCE(description, ''),
+    rate_up_share  = COALESCE(rate_up_share, 0.5),
+    pity_rare      = COALESCE(pity_rare, 0),
+    pity_epic      = COALESCE(pity_epic, 0),
+    pity_legendary = COALESCE(pity_legendary, 0),
+    is_active      = COALESCE(is_active, TRUE),
+    sort_order     = COALESCE(sort_order, 0);
+
+ALTER TABLE game_gacha_banners
+    ALTER COLUMN name_en        SET NOT NULL,
+    ALTER COLUMN description    SET NOT NULL,
+    ALTER COLUMN rate_up_share  SET NOT NULL,
+    ALTER COLUMN pity_rare      SET NOT NULL,
+    ALTER COLUMN pity_epic      SET NOT NULL,
+    ALTER COLUMN pity_legendary SET NOT NULL,
+    ALTER COLUMN is_active      SET NOT NULL,
+    ALTER COLUMN sort_order     SET NOT NULL;
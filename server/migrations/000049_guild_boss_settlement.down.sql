@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS guild_boss_rewards;
+DROP INDEX IF EXISTS idx_guild_boss_unsettled;
+ALTER TABLE guild_boss DROP COLUMN IF EXISTS settled_at;
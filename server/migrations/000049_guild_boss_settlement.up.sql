@@ -0,0 +1,24 @@
+-- Guild boss settlement: the killing blow stamps defeated_at, settlement pays out
+-- and stamps settled_at. The scheduler retries bosses left in between.
+ALTER TABLE guild_boss ADD COLUMN IF NOT EXISTS settled_at TIMESTAMPTZ;
+
+-- Bosses marked defeated before this were already paid out inline
+UPDATE guild_boss SET settled_at = defeated_at WHERE defeated_at IS NOT NULL;
+
+-- Bosses driven to 0 HP whose settlement never ran are owed their rewards
+UPDATE guild_boss SET defeated_at = NOW() WHERE current_hp <= 0 AND defeated_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_guild_boss_unsettled ON guild_boss(id)
+    WHERE defeated_at IS NOT NULL AND settled_at IS NULL;
+
+-- One reward per member per boss; the primary key keeps settlement idempotent
+CREATE TABLE IF NOT EXISTS guild_boss_rewards (
+    boss_id     INT NOT NULL REFERENCES guild_boss(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank        INT NOT NULL,
+    damage      BIGINT NOT NULL,
+    reward_gold BIGINT NOT NULL DEFAULT 0,
+    reward_gems INT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (boss_id, user_id)
+);
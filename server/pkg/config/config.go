@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 type Config struct {
 	Port      string
@@ -21,6 +24,38 @@ type Config struct {
 	KakaoClientID     string
 	KakaoSecret       string
 	OAuthRedirectBase string
+
+	OIDCProviders []OIDCProviderConfig
+
+	// Mail: SMTP when SMTPHost is set, otherwise messages are logged (and written to MailDir if set)
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	MailDir      string
+
+	// JobLeader picks how replicas elect the one that runs background jobs:
+	// "postgres" (advisory lock) or "redis" (lease)
+	JobLeader string
+}
+
+// OIDCProviderConfig is one OpenID Connect login provider. Providers are listed in
+// OIDC_PROVIDERS (comma separated names) and configured with OIDC_<NAME>_* variables:
+//
+//	OIDC_PROVIDERS=apple,line
+//	OIDC_LINE_ISSUER=https://access.line.me
+//	OIDC_LINE_CLIENT_ID=...
+//	OIDC_LINE_CLIENT_SECRET=...
+//	OIDC_LINE_SCOPES=openid profile email   (optional)
+//	OIDC_APPLE_RESPONSE_MODE=form_post      (optional)
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	ResponseMode string
 }
 
 func Load() *Config {
@@ -43,7 +78,38 @@ func Load() *Config {
 		KakaoClientID:     getEnv("KAKAO_CLIENT_ID", ""),
 		KakaoSecret:       getEnv("KAKAO_CLIENT_SECRET", ""),
 		OAuthRedirectBase: getEnv("OAUTH_REDIRECT_BASE", "http://localhost:8080"),
+
+		OIDCProviders: loadOIDCProviders(),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "SlimeTopia <no-reply@slimetopia.app>"),
+		MailDir:      getEnv("MAIL_DIR", ""),
+
+		JobLeader: getEnv("JOB_LEADER", "postgres"),
+	}
+}
+
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			ResponseMode: os.Getenv(prefix + "RESPONSE_MODE"),
+		})
 	}
+	return providers
 }
 
 // DatabaseURL returns the connection string. If DATABASE_URL is set (Railway), use it directly.